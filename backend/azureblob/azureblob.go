@@ -6,7 +6,9 @@ package azureblob
 
 import (
 	"context"
+	"crypto/md5"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -1630,6 +1632,143 @@ func (pw *poolWrapper) Run(f func()) {
 func (pw *poolWrapper) Close() {
 }
 
+// getHTTPHeaders returns the headers to upload src with
+func (o *Object) getHTTPHeaders(ctx context.Context, src fs.ObjectInfo) azblob.BlobHTTPHeaders {
+	httpHeaders := azblob.BlobHTTPHeaders{}
+	httpHeaders.ContentType = fs.MimeType(ctx, src)
+
+	// Compute the Content-MD5 of the file. As we stream all uploads it
+	// will be set in PutBlockList API call using the 'x-ms-blob-content-md5' header
+	if !o.fs.opt.DisableCheckSum {
+		if sourceMD5, _ := src.Hash(ctx, hash.MD5); sourceMD5 != "" {
+			sourceMD5bytes, err := hex.DecodeString(sourceMD5)
+			if err == nil {
+				httpHeaders.ContentMD5 = sourceMD5bytes
+			} else {
+				fs.Debugf(o, "Failed to decode %q as MD5: %v", sourceMD5, err)
+			}
+		}
+	}
+	return httpHeaders
+}
+
+// azChunkWriter uploads the blocks of a block blob
+type azChunkWriter struct {
+	f           *Fs
+	o           *Object
+	blob        azblob.BlockBlobURL
+	httpHeaders azblob.BlobHTTPHeaders
+	blockIDsMu  sync.Mutex // to protect blockIDs
+	blockIDs    []string   // base64 block IDs indexed by chunk number
+}
+
+// OpenChunkWriter returns the chunk size and a ChunkWriter
+//
+// Pass in the remote and the src object
+func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	// Temporary Object under construction
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	container, containerPath := o.split()
+	if container == "" || containerPath == "" {
+		return info, nil, fmt.Errorf("can't upload to root - need a container")
+	}
+	err = f.makeContainer(ctx, container)
+	if err != nil {
+		return info, nil, err
+	}
+	o.updateMetadataWithModTime(src.ModTime(ctx))
+
+	// calculate size of parts/blocks
+	partSize := chunksize.Calculator(o, src.Size(), maxUploadParts, f.opt.ChunkSize)
+
+	w := &azChunkWriter{
+		f:           f,
+		o:           o,
+		blob:        o.getBlobReference().ToBlockBlobURL(),
+		httpHeaders: o.getHTTPHeaders(ctx, src),
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize:   int64(partSize),
+		Concurrency: f.opt.UploadConcurrency,
+	}
+	return info, w, nil
+}
+
+// WriteChunk stages chunk chunkNumber, counting from 0, as a block
+// reading it from reader
+func (w *azChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	if chunkNumber < 0 {
+		return -1, fmt.Errorf("invalid chunk number %d", chunkNumber)
+	}
+
+	// Calculate the MD5 of the block for the service to check
+	m := md5.New()
+	size, err := io.Copy(m, reader)
+	if err != nil {
+		return -1, fmt.Errorf("failed to read block: %w", err)
+	}
+	transactionalMD5 := m.Sum(nil)
+
+	// The block ID is the chunk number as LSB first binary which
+	// makes all the IDs the same length as Azure requires
+	var binaryBlockID [8]byte
+	binary.LittleEndian.PutUint64(binaryBlockID[:], uint64(chunkNumber))
+	blockID := base64.StdEncoding.EncodeToString(binaryBlockID[:])
+
+	err = w.f.pacer.Call(func() (bool, error) {
+		// rewind the reader after the checksum and on retry
+		_, err := reader.Seek(0, io.SeekStart)
+		if err != nil {
+			return false, err
+		}
+		_, err = w.blob.StageBlock(ctx, blockID, reader, azblob.LeaseAccessConditions{}, transactionalMD5, azblob.ClientProvidedKeyOptions{})
+		return w.f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return -1, fmt.Errorf("failed to upload block %d: %w", chunkNumber+1, err)
+	}
+
+	w.blockIDsMu.Lock()
+	if extend := chunkNumber + 1 - len(w.blockIDs); extend > 0 {
+		w.blockIDs = append(w.blockIDs, make([]string, extend)...)
+	}
+	w.blockIDs[chunkNumber] = blockID
+	w.blockIDsMu.Unlock()
+	return size, nil
+}
+
+// Abort the upload
+//
+// Azure has no way of deleting staged blocks - they are garbage
+// collected by the service if they aren't committed within a week.
+func (w *azChunkWriter) Abort(ctx context.Context) error {
+	w.blockIDsMu.Lock()
+	defer w.blockIDsMu.Unlock()
+	fs.Debugf(w.o, "Abandoning %d uncommitted blocks", len(w.blockIDs))
+	return nil
+}
+
+// Close commits the staged blocks making the blob
+func (w *azChunkWriter) Close(ctx context.Context) error {
+	for i, blockID := range w.blockIDs {
+		if blockID == "" {
+			return fmt.Errorf("can't commit block list: block %d is missing", i+1)
+		}
+	}
+	tier := azblob.AccessTierType(w.f.opt.AccessTier)
+	err := w.f.pacer.Call(func() (bool, error) {
+		_, err := w.blob.CommitBlockList(ctx, w.blockIDs, w.httpHeaders, w.o.meta, azblob.BlobAccessConditions{}, tier, nil, azblob.ClientProvidedKeyOptions{}, azblob.ImmutabilityPolicyOptions{})
+		return w.f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit block list: %w", err)
+	}
+	return nil
+}
+
 // Update the object with the contents of the io.Reader, modTime and size
 //
 // The new object may have been created if an error is returned
@@ -1661,21 +1800,7 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	}
 
 	blob := o.getBlobReference()
-	httpHeaders := azblob.BlobHTTPHeaders{}
-	httpHeaders.ContentType = fs.MimeType(ctx, src)
-
-	// Compute the Content-MD5 of the file. As we stream all uploads it
-	// will be set in PutBlockList API call using the 'x-ms-blob-content-md5' header
-	if !o.fs.opt.DisableCheckSum {
-		if sourceMD5, _ := src.Hash(ctx, hash.MD5); sourceMD5 != "" {
-			sourceMD5bytes, err := hex.DecodeString(sourceMD5)
-			if err == nil {
-				httpHeaders.ContentMD5 = sourceMD5bytes
-			} else {
-				fs.Debugf(o, "Failed to decode %q as MD5: %v", sourceMD5, err)
-			}
-		}
-	}
+	httpHeaders := o.getHTTPHeaders(ctx, src)
 
 	uploadParts := maxUploadParts
 	if uploadParts < 1 {
@@ -1778,13 +1903,14 @@ func (o *Object) GetTier() string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Copier      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.Purger      = &Fs{}
-	_ fs.ListRer     = &Fs{}
-	_ fs.Object      = &Object{}
-	_ fs.MimeTyper   = &Object{}
-	_ fs.GetTierer   = &Object{}
-	_ fs.SetTierer   = &Object{}
+	_ fs.Fs              = &Fs{}
+	_ fs.Copier          = &Fs{}
+	_ fs.PutStreamer     = &Fs{}
+	_ fs.Purger          = &Fs{}
+	_ fs.ListRer         = &Fs{}
+	_ fs.OpenChunkWriter = &Fs{}
+	_ fs.Object          = &Object{}
+	_ fs.MimeTyper       = &Object{}
+	_ fs.GetTierer       = &Object{}
+	_ fs.SetTierer       = &Object{}
 )
@@ -1842,6 +1842,71 @@ func urlEncode(in string) string {
 	return out.String()
 }
 
+// OpenChunkWriter returns the chunk size and a ChunkWriter
+//
+// Pass in the remote and the src object
+func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	if f.opt.Versions {
+		return info, nil, errNotWithVersions
+	}
+	if f.opt.VersionAt.IsSet() {
+		return info, nil, errNotWithVersionAt
+	}
+	size := src.Size()
+	if size < 0 {
+		return info, nil, errors.New("can't upload file of unknown size in chunks")
+	}
+
+	// Large files need at least two parts so use smaller chunks
+	// for files which would fit in one
+	chunkSize := f.opt.ChunkSize
+	if size <= int64(chunkSize) {
+		chunkSize = fs.SizeSuffix((size + 1) / 2)
+		if chunkSize < minChunkSize {
+			return info, nil, fmt.Errorf("file of size %v is too small to upload in chunks", fs.SizeSuffix(size))
+		}
+	}
+
+	// Temporary Object under construction
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	bucket, _ := o.split()
+	err = f.makeBucket(ctx, bucket)
+	if err != nil {
+		return info, nil, err
+	}
+	up, err := f.newLargeUpload(ctx, o, nil, src, chunkSize, false, nil)
+	if err != nil {
+		return info, nil, err
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize:   up.chunkSize,
+		Concurrency: fs.GetConfig(ctx).Transfers,
+	}
+	return info, up, nil
+}
+
+// ResumeChunkWriter carries on with an upload started by
+// OpenChunkWriter using the state returned by the ChunkWriter
+func (f *Fs) ResumeChunkWriter(ctx context.Context, remote string, state []byte) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	// Temporary Object under construction
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	up, err := f.resumeLargeUpload(ctx, o, state)
+	if err != nil {
+		return info, nil, err
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize:   up.chunkSize,
+		Concurrency: fs.GetConfig(ctx).Transfers,
+	}
+	return info, up, nil
+}
+
 // Update the object with the contents of the io.Reader, modTime and size
 //
 // The new object may have been created if an error is returned
@@ -2028,14 +2093,17 @@ func (o *Object) ID() string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs           = &Fs{}
-	_ fs.Purger       = &Fs{}
-	_ fs.Copier       = &Fs{}
-	_ fs.PutStreamer  = &Fs{}
-	_ fs.CleanUpper   = &Fs{}
-	_ fs.ListRer      = &Fs{}
-	_ fs.PublicLinker = &Fs{}
-	_ fs.Object       = &Object{}
-	_ fs.MimeTyper    = &Object{}
-	_ fs.IDer         = &Object{}
+	_ fs.Fs                   = &Fs{}
+	_ fs.Purger               = &Fs{}
+	_ fs.Copier               = &Fs{}
+	_ fs.PutStreamer          = &Fs{}
+	_ fs.CleanUpper           = &Fs{}
+	_ fs.ListRer              = &Fs{}
+	_ fs.OpenChunkWriter      = &Fs{}
+	_ fs.ResumeChunkWriter    = &Fs{}
+	_ fs.ResumableChunkWriter = &largeUpload{}
+	_ fs.PublicLinker         = &Fs{}
+	_ fs.Object               = &Object{}
+	_ fs.MimeTyper            = &Object{}
+	_ fs.IDer                 = &Object{}
 )
@@ -9,6 +9,7 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	gohash "hash"
 	"io"
@@ -80,6 +81,7 @@ type largeUpload struct {
 	size      int64                           // total size
 	parts     int64                           // calculated number of parts, if known
 	sha1s     []string                        // slice of SHA1s for each part
+	sha1sMu   sync.Mutex                      // lock for sha1s when reading the resume state
 	uploadMu  sync.Mutex                      // lock for upload variable
 	uploads   []*api.GetUploadPartURLResponse // result of get upload URL calls
 	chunkSize int64                           // chunk size to use
@@ -201,10 +203,16 @@ func (up *largeUpload) returnUploadURL(upload *api.GetUploadPartURLResponse) {
 	up.uploadMu.Unlock()
 }
 
-// Transfer a chunk
-func (up *largeUpload) transferChunk(ctx context.Context, part int64, body []byte) error {
+// Transfer a chunk of length bytes read from body
+func (up *largeUpload) transferChunk(ctx context.Context, part int64, body io.ReadSeeker, length int64) error {
 	err := up.f.pacer.Call(func() (bool, error) {
-		fs.Debugf(up.o, "Sending chunk %d length %d", part, len(body))
+		fs.Debugf(up.o, "Sending chunk %d length %d", part, length)
+
+		// rewind the body on retry
+		_, err := body.Seek(0, io.SeekStart)
+		if err != nil {
+			return false, err
+		}
 
 		// Get upload URL
 		upload, err := up.getUploadURL(ctx)
@@ -212,8 +220,8 @@ func (up *largeUpload) transferChunk(ctx context.Context, part int64, body []byt
 			return false, err
 		}
 
-		in := newHashAppendingReader(bytes.NewReader(body), sha1.New())
-		size := int64(len(body)) + int64(in.AdditionalLength())
+		in := newHashAppendingReader(body, sha1.New())
+		size := length + int64(in.AdditionalLength())
 
 		// Authorization
 		//
@@ -262,7 +270,9 @@ func (up *largeUpload) transferChunk(ctx context.Context, part int64, body []byt
 			upload = nil
 		}
 		up.returnUploadURL(upload)
+		up.sha1sMu.Lock()
 		up.sha1s[part-1] = in.HexSum()
+		up.sha1sMu.Unlock()
 		return retry, err
 	})
 	if err != nil {
@@ -348,6 +358,89 @@ func (up *largeUpload) cancel(ctx context.Context) error {
 	return err
 }
 
+// WriteChunk uploads chunk chunkNumber, counting from 0, reading it
+// from reader
+func (up *largeUpload) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	if chunkNumber < 0 || int64(chunkNumber) >= up.parts {
+		return -1, fmt.Errorf("invalid chunk number %d for upload of %d parts", chunkNumber, up.parts)
+	}
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1, err
+	}
+	err = up.transferChunk(ctx, int64(chunkNumber)+1, reader, size)
+	if err != nil {
+		return -1, err
+	}
+	return size, nil
+}
+
+// largeUploadResumeState is the state persisted to resume a large
+// file upload
+type largeUploadResumeState struct {
+	ID        string   `json:"id"`
+	Size      int64    `json:"size"`
+	Parts     int64    `json:"parts"`
+	ChunkSize int64    `json:"chunkSize"`
+	SHA1s     []string `json:"sha1s"`
+}
+
+// ResumeState returns the state needed to resume the upload with
+// ResumeChunkWriter
+func (up *largeUpload) ResumeState() ([]byte, error) {
+	up.sha1sMu.Lock()
+	defer up.sha1sMu.Unlock()
+	return json.Marshal(&largeUploadResumeState{
+		ID:        up.id,
+		Size:      up.size,
+		Parts:     up.parts,
+		ChunkSize: up.chunkSize,
+		SHA1s:     up.sha1s,
+	})
+}
+
+// resumeLargeUpload carries on with an upload of object o using the
+// state returned by ResumeState
+func (f *Fs) resumeLargeUpload(ctx context.Context, o *Object, state []byte) (up *largeUpload, err error) {
+	var resumeState largeUploadResumeState
+	err = json.Unmarshal(state, &resumeState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode large file upload state: %w", err)
+	}
+	if int64(len(resumeState.SHA1s)) != resumeState.Parts {
+		return nil, fmt.Errorf("large file upload state has %d SHA1s for %d parts", len(resumeState.SHA1s), resumeState.Parts)
+	}
+	up = &largeUpload{
+		f:         f,
+		o:         o,
+		what:      "upload",
+		id:        resumeState.ID,
+		size:      resumeState.Size,
+		parts:     resumeState.Parts,
+		sha1s:     resumeState.SHA1s,
+		chunkSize: resumeState.ChunkSize,
+	}
+	up.in, up.wrap = accounting.UnWrap(nil)
+	// Check the upload still exists by getting an upload URL for
+	// it which is cached for uploading the parts
+	upload, err := up.getUploadURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	up.returnUploadURL(upload)
+	return up, nil
+}
+
+// Close finishes the upload started by OpenChunkWriter
+func (up *largeUpload) Close(ctx context.Context) error {
+	return up.finish(ctx)
+}
+
+// Abort cancels the upload started by OpenChunkWriter
+func (up *largeUpload) Abort(ctx context.Context) error {
+	return up.cancel(ctx)
+}
+
 // Stream uploads the chunks from the input, starting with a required initial
 // chunk. Assumes the file size is unknown and will upload until the input
 // reaches EOF.
@@ -410,7 +503,7 @@ func (up *largeUpload) Stream(ctx context.Context, initialUploadBlock []byte) (e
 			part := part // for the closure
 			g.Go(func() (err error) {
 				defer up.f.putBuf(buf, false)
-				return up.transferChunk(gCtx, part, buf)
+				return up.transferChunk(gCtx, part, bytes.NewReader(buf), int64(len(buf)))
 			})
 		}
 		return nil
@@ -462,7 +555,7 @@ func (up *largeUpload) Upload(ctx context.Context) (err error) {
 			g.Go(func() (err error) {
 				defer up.f.putBuf(buf, up.doCopy)
 				if !up.doCopy {
-					err = up.transferChunk(gCtx, part, buf)
+					err = up.transferChunk(gCtx, part, bytes.NewReader(buf), int64(len(buf)))
 				} else {
 					err = up.copyChunk(gCtx, part, reqSize)
 				}
@@ -40,6 +40,8 @@ func TestIntegration(t *testing.T) {
 		UnimplementableFsMethods: []string{
 			"PublicLink",
 			"OpenWriterAt",
+			"OpenChunkWriter",
+			"ResumeChunkWriter",
 			"MergeDirs",
 			"DirCacheFlush",
 			"UserInfo",
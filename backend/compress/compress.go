@@ -43,6 +43,7 @@ const (
 	minCompressionRatio = 1.1
 
 	gzFileExt           = ".gz"
+	zstdFileExt         = ".zst"
 	metaFileExt         = ".json"
 	uncompressedFileExt = ".bin"
 )
@@ -51,6 +52,7 @@ const (
 const (
 	Uncompressed = 0
 	Gzip         = 2
+	Zstd         = 3
 )
 
 var nameRegexp = regexp.MustCompile(`^(.+?)\.([A-Za-z0-9-_]{11})$`)
@@ -62,6 +64,9 @@ func init() {
 		{ // Default compression mode options {
 			Value: "gzip",
 			Help:  "Standard gzip compression with fastest parameters.",
+		}, {
+			Value: "zstd",
+			Help:  "Zstandard compression - faster and stronger than gzip.",
 		},
 	}
 
@@ -84,7 +89,7 @@ func init() {
 			Examples: compressionModeOptions,
 		}, {
 			Name: "level",
-			Help: `GZIP compression level (-2 to 9).
+			Help: `GZIP (-2 to 9) or zstd (1 to 22) compression level.
 
 Generally -1 (default, equivalent to 5) is recommended.
 Levels 1 to 9 increase compression at the cost of speed. Going past 6 
@@ -92,9 +97,19 @@ generally offers very little return.
 
 Level -2 uses Huffman encoding only. Only use if you know what you
 are doing.
-Level 0 turns off compression.`,
+Level 0 turns off compression.
+
+For zstd, levels of 0 or less use the default level (3).`,
 			Default:  sgzip.DefaultCompression,
 			Advanced: true,
+		}, {
+			Name: "zstd_dictionary",
+			Help: `Path to a zstd dictionary to compress with.
+
+A dictionary trained on samples of the files (with "zstd --train")
+improves the compression of small files considerably. The same
+dictionary is needed to read the files back, so don't lose it.`,
+			Advanced: true,
 		}, {
 			Name: "ram_cache_limit",
 			Help: `Some remotes don't allow the upload of files with unknown size.
@@ -115,6 +130,7 @@ type Options struct {
 	CompressionMode  string        `config:"mode"`
 	CompressionLevel int           `config:"level"`
 	RAMCacheLimit    fs.SizeSuffix `config:"ram_cache_limit"`
+	ZstdDictionary   string        `config:"zstd_dictionary"`
 }
 
 /*** FILESYSTEM FUNCTIONS ***/
@@ -126,8 +142,9 @@ type Fs struct {
 	name     string
 	root     string
 	opt      Options
-	mode     int          // compression mode id
-	features *fs.Features // optional features
+	mode     int             // compression mode id
+	zstdDict *zstdDictionary // dictionary for zstd if set
+	features *fs.Features    // optional features
 }
 
 // NewFs constructs an Fs from the path, container:path
@@ -172,6 +189,12 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 		opt:  *opt,
 		mode: compressionModeFromName(opt.CompressionMode),
 	}
+	if opt.ZstdDictionary != "" {
+		f.zstdDict, err = loadZstdDictionary(opt.ZstdDictionary)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// the features here are ones we could support, and they are
 	// ANDed with the ones from wrappedFs
 	f.features = (&fs.Features{
@@ -201,6 +224,8 @@ func compressionModeFromName(name string) int {
 	switch name {
 	case "gzip":
 		return Gzip
+	case "zstd":
+		return Zstd
 	default:
 		return Uncompressed
 	}
@@ -243,7 +268,7 @@ func processFileName(compressedFileName string) (origFileName string, extension
 	if err != nil {
 		return "", "", 0, errors.New("could not decode size")
 	}
-	return match[1], gzFileExt, size, nil
+	return match[1], extension, size, nil
 }
 
 // Generates the file name for a metadata file
@@ -258,10 +283,13 @@ func isMetadataFile(filename string) bool {
 
 // makeDataName generates the file name for a data file with specified compression mode
 func makeDataName(remote string, size int64, mode int) (newRemote string) {
-	if mode != Uncompressed {
-		newRemote = remote + "." + int64ToBase64(size) + gzFileExt
-	} else {
+	switch mode {
+	case Uncompressed:
 		newRemote = remote + uncompressedFileExt
+	case Zstd:
+		newRemote = remote + "." + int64ToBase64(size) + zstdFileExt
+	default:
+		newRemote = remote + "." + int64ToBase64(size) + gzFileExt
 	}
 	return newRemote
 }
@@ -371,8 +399,12 @@ func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error decoding metadata: %w", err)
 	}
-	// Create our Object
-	o, err := f.Fs.NewObject(ctx, makeDataName(remote, meta.CompressionMetadata.Size, meta.Mode))
+	// Create our Object - only gzip keeps the size in its compression metadata
+	size := meta.CompressionMetadata.Size
+	if meta.Mode == Zstd {
+		size = meta.Size
+	}
+	o, err := f.Fs.NewObject(ctx, makeDataName(remote, size, meta.Mode))
 	if err != nil {
 		return nil, err
 	}
@@ -439,8 +471,18 @@ func (f *Fs) verifyObjectHash(ctx context.Context, o fs.Object, hasher *hash.Mul
 type putFn func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
 
 type compressionResult struct {
-	err  error
-	meta sgzip.GzipMetadata
+	err   error
+	size  int64
+	meta  sgzip.GzipMetadata
+	zmeta ZstdMetadata
+}
+
+// newCompressor makes a writer which compresses to out in f.mode
+func (f *Fs) newCompressor(out io.Writer) (io.WriteCloser, error) {
+	if f.mode == Zstd {
+		return f.newZstdWriter(out)
+	}
+	return sgzip.NewWriterLevel(out, f.opt.CompressionLevel)
 }
 
 // replicating some of operations.Rcat functionality because we want to support remotes without streaming
@@ -503,17 +545,18 @@ func (f *Fs) putCompress(ctx context.Context, in io.Reader, src fs.ObjectInfo, o
 	pipeReader, pipeWriter := io.Pipe()
 	results := make(chan compressionResult)
 	go func() {
-		gz, err := sgzip.NewWriterLevel(pipeWriter, f.opt.CompressionLevel)
+		cw, err := f.newCompressor(pipeWriter)
 		if err != nil {
-			results <- compressionResult{err: err, meta: sgzip.GzipMetadata{}}
+			_ = pipeWriter.CloseWithError(err)
+			results <- compressionResult{err: err}
 			return
 		}
-		_, err = io.Copy(gz, in)
-		gzErr := gz.Close()
-		if gzErr != nil {
-			fs.Errorf(nil, "Failed to close compress: %v", gzErr)
+		_, err = io.Copy(cw, in)
+		cwErr := cw.Close()
+		if cwErr != nil {
+			fs.Errorf(nil, "Failed to close compress: %v", cwErr)
 			if err == nil {
-				err = gzErr
+				err = cwErr
 			}
 		}
 		closeErr := pipeWriter.Close()
@@ -523,7 +566,16 @@ func (f *Fs) putCompress(ctx context.Context, in io.Reader, src fs.ObjectInfo, o
 				err = closeErr
 			}
 		}
-		results <- compressionResult{err: err, meta: gz.MetaData()}
+		result := compressionResult{err: err}
+		switch x := cw.(type) {
+		case *zstdWriter:
+			result.zmeta = x.MetaData()
+			result.size = result.zmeta.Size
+		case *sgzip.Writer:
+			result.meta = x.MetaData()
+			result.size = result.meta.Size
+		}
+		results <- result
 	}()
 	wrappedIn := wrap(bufio.NewReaderSize(pipeReader, bufferSize)) // Probably no longer needed as sgzip has it's own buffering
 
@@ -570,7 +622,10 @@ func (f *Fs) putCompress(ctx context.Context, in io.Reader, src fs.ObjectInfo, o
 	}
 
 	// Generate metadata
-	meta := newMetadata(result.meta.Size, f.mode, result.meta, hex.EncodeToString(metaHasher.Sum(nil)), mimeType)
+	meta := newMetadata(result.size, f.mode, result.meta, hex.EncodeToString(metaHasher.Sum(nil)), mimeType)
+	if f.mode == Zstd {
+		meta.ZstdMetadata = &result.zmeta
+	}
 
 	// Check the hashes of the compressed data if we were comparing them
 	if ht != hash.None && hasher != nil {
@@ -1019,6 +1074,7 @@ type ObjectMetadata struct {
 	MD5                 string // MD5 hash of the file.
 	MimeType            string // Mime type of the file
 	CompressionMetadata sgzip.GzipMetadata
+	ZstdMetadata        *ZstdMetadata `json:",omitempty"` // Frame index if Mode is Zstd
 }
 
 // Object with external metadata
@@ -1306,6 +1362,9 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (rc io.Read
 			openOptions = append(openOptions, option)
 		}
 	}
+	if o.meta.Mode == Zstd {
+		return o.openZstd(ctx, offset, limit)
+	}
 	// Get a chunkedreader for the wrapped object
 	chunkedReader := chunkedreader.New(ctx, o.Object, initialChunkSize, maxChunkSize)
 	// Get file handle
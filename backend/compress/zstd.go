@@ -0,0 +1,213 @@
+package compress
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/rclone/rclone/fs/chunkedreader"
+	"github.com/rclone/rclone/lib/env"
+)
+
+// Size of the uncompressed data in each zstd frame. Each frame is
+// compressed independently so reads can start at any frame.
+const zstdFrameSize = 1 << 20
+
+// Magic number at the start of a zstd dictionary
+const zstdDictMagic = 0xEC30A437
+
+// ZstdMetadata describes the seekable frames of a zstd compressed file.
+type ZstdMetadata struct {
+	Size      int64   // Uncompressed size of the file
+	FrameSize int64   // Uncompressed size of every frame but the last
+	Frames    []int64 // Compressed size of each frame
+	DictID    uint32  `json:",omitempty"` // ID of the dictionary used, if any
+}
+
+// zstdDictionary holds a dictionary loaded from zstd_dictionary
+type zstdDictionary struct {
+	data []byte
+	id   uint32
+}
+
+// loadZstdDictionary reads and checks the dictionary at path
+func loadZstdDictionary(path string) (*zstdDictionary, error) {
+	data, err := os.ReadFile(env.ShellExpand(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zstd dictionary: %w", err)
+	}
+	if len(data) < 8 || binary.LittleEndian.Uint32(data) != zstdDictMagic {
+		return nil, fmt.Errorf("%q is not a zstd dictionary - create one with zstd --train", path)
+	}
+	return &zstdDictionary{
+		data: data,
+		id:   binary.LittleEndian.Uint32(data[4:]),
+	}, nil
+}
+
+// zstdLevel converts the level option to a zstd encoder level.
+//
+// Levels up to 0 give the default, otherwise it is interpreted as a
+// zstd level from 1 to 22.
+func zstdLevel(level int) zstd.EncoderLevel {
+	if level <= 0 {
+		return zstd.SpeedDefault
+	}
+	return zstd.EncoderLevelFromZstd(level)
+}
+
+// zstdWriter compresses data written to it as a series of independent
+// zstd frames, recording the compressed size of each one.
+type zstdWriter struct {
+	out     io.Writer
+	enc     *zstd.Encoder
+	buf     []byte
+	scratch []byte
+	meta    ZstdMetadata
+}
+
+// newZstdWriter makes a zstdWriter writing to out
+func (f *Fs) newZstdWriter(out io.Writer) (*zstdWriter, error) {
+	opts := []zstd.EOption{
+		zstd.WithEncoderLevel(zstdLevel(f.opt.CompressionLevel)),
+	}
+	w := &zstdWriter{
+		out: out,
+		buf: make([]byte, 0, zstdFrameSize),
+		meta: ZstdMetadata{
+			FrameSize: zstdFrameSize,
+		},
+	}
+	if f.zstdDict != nil {
+		opts = append(opts, zstd.WithEncoderDict(f.zstdDict.data))
+		w.meta.DictID = f.zstdDict.id
+	}
+	enc, err := zstd.NewWriter(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	w.enc = enc
+	return w, nil
+}
+
+// writeFrame compresses p as a single frame
+func (w *zstdWriter) writeFrame(p []byte) error {
+	w.scratch = w.enc.EncodeAll(p, w.scratch[:0])
+	if _, err := w.out.Write(w.scratch); err != nil {
+		return err
+	}
+	w.meta.Frames = append(w.meta.Frames, int64(len(w.scratch)))
+	w.meta.Size += int64(len(p))
+	return nil
+}
+
+// Write p to the frames
+func (w *zstdWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := zstdFrameSize - len(w.buf)
+		if chunk > len(p) {
+			chunk = len(p)
+		}
+		w.buf = append(w.buf, p[:chunk]...)
+		p = p[chunk:]
+		n += chunk
+		if len(w.buf) == zstdFrameSize {
+			if err = w.writeFrame(w.buf); err != nil {
+				return n, err
+			}
+			w.buf = w.buf[:0]
+		}
+	}
+	return n, nil
+}
+
+// Close writes the final frame. An empty file gets one empty frame
+// so it is still valid zstd.
+func (w *zstdWriter) Close() error {
+	var err error
+	if len(w.buf) > 0 || len(w.meta.Frames) == 0 {
+		err = w.writeFrame(w.buf)
+	}
+	closeErr := w.enc.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// MetaData returns the frame index of the data written
+func (w *zstdWriter) MetaData() ZstdMetadata {
+	return w.meta
+}
+
+// zstdReadCloser closes the decoder and the underlying reader
+type zstdReadCloser struct {
+	io.Reader
+	dec *zstd.Decoder
+	in  io.Closer
+}
+
+// Close the decoder and the underlying reader
+func (r *zstdReadCloser) Close() error {
+	r.dec.Close()
+	return r.in.Close()
+}
+
+// openZstd opens the zstd compressed object for reading from offset,
+// starting at the frame containing offset so earlier frames don't
+// need to be read or decompressed.
+func (o *Object) openZstd(ctx context.Context, offset, limit int64) (io.ReadCloser, error) {
+	meta := o.meta.ZstdMetadata
+	if meta == nil {
+		return nil, errors.New("zstd metadata missing")
+	}
+	var opts []zstd.DOption
+	if meta.DictID != 0 {
+		dict := o.f.zstdDict
+		if dict == nil || dict.id != meta.DictID {
+			return nil, fmt.Errorf("file was compressed with zstd dictionary ID %d - set zstd_dictionary to it to read", meta.DictID)
+		}
+		opts = append(opts, zstd.WithDecoderDicts(dict.data))
+	}
+
+	// Find the frame to start reading from
+	var frame, compressedOffset int64
+	if meta.FrameSize > 0 {
+		frame = offset / meta.FrameSize
+	}
+	if frame > int64(len(meta.Frames)) {
+		frame = int64(len(meta.Frames))
+	}
+	for _, size := range meta.Frames[:frame] {
+		compressedOffset += size
+	}
+
+	chunkedReader := chunkedreader.New(ctx, o.Object, initialChunkSize, maxChunkSize)
+	if _, err := chunkedReader.Seek(compressedOffset, io.SeekStart); err != nil {
+		_ = chunkedReader.Close()
+		return nil, err
+	}
+	dec, err := zstd.NewReader(chunkedReader, opts...)
+	if err != nil {
+		_ = chunkedReader.Close()
+		return nil, err
+	}
+	rc := &zstdReadCloser{Reader: dec, dec: dec, in: chunkedReader}
+
+	// Skip to offset within the frame
+	if skip := offset - frame*meta.FrameSize; skip > 0 {
+		if _, err = io.CopyN(io.Discard, dec, skip); err != nil && err != io.EOF {
+			_ = rc.Close()
+			return nil, err
+		}
+	}
+	if limit != -1 {
+		rc.Reader = io.LimitReader(dec, limit)
+	}
+	return rc, nil
+}
@@ -0,0 +1,76 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZstdWriterFrames(t *testing.T) {
+	data := bytes.Repeat([]byte("rclone compress zstd frames "), 5*zstdFrameSize/2/28+1)
+	f := &Fs{opt: Options{CompressionLevel: -1}, mode: Zstd}
+
+	var out bytes.Buffer
+	w, err := f.newZstdWriter(&out)
+	require.NoError(t, err)
+	// write in odd sized pieces to cross frame boundaries
+	for p := data; len(p) > 0; {
+		n := 12345
+		if n > len(p) {
+			n = len(p)
+		}
+		_, err = w.Write(p[:n])
+		require.NoError(t, err)
+		p = p[n:]
+	}
+	require.NoError(t, w.Close())
+
+	meta := w.MetaData()
+	assert.Equal(t, int64(len(data)), meta.Size)
+	assert.Equal(t, int64(zstdFrameSize), meta.FrameSize)
+	require.Len(t, meta.Frames, 3)
+	var total int64
+	for _, size := range meta.Frames {
+		total += size
+	}
+	assert.Equal(t, int64(out.Len()), total)
+
+	// The whole stream decodes as standard zstd
+	dec, err := zstd.NewReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	got, err := io.ReadAll(dec)
+	dec.Close()
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// Decoding can start at any frame
+	offset := meta.Frames[0] + meta.Frames[1]
+	dec, err = zstd.NewReader(bytes.NewReader(out.Bytes()[offset:]))
+	require.NoError(t, err)
+	got, err = io.ReadAll(dec)
+	dec.Close()
+	require.NoError(t, err)
+	assert.Equal(t, data[2*zstdFrameSize:], got)
+}
+
+func TestZstdWriterEmpty(t *testing.T) {
+	f := &Fs{mode: Zstd}
+	var out bytes.Buffer
+	w, err := f.newZstdWriter(&out)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	meta := w.MetaData()
+	assert.Equal(t, int64(0), meta.Size)
+	assert.Len(t, meta.Frames, 1)
+
+	dec, err := zstd.NewReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	got, err := io.ReadAll(dec)
+	dec.Close()
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -58,6 +58,9 @@ const (
 	metaMtimeGsutil             = "goog-reserved-file-mtime" // key used by GSUtil to store mtime in metadata
 	listChunks                  = 1000                       // chunk size to read directory listings
 	minSleep                    = 10 * time.Millisecond
+	defaultChunkSize            = 16 * fs.Mebi // default size of multi-thread upload chunks
+	minChunkSize                = 5 * fs.Mebi  // minimum size of multipart upload parts
+	maxUploadParts              = 10000        // maximum number of parts in a multipart upload
 )
 
 var (
@@ -319,6 +322,30 @@ can't check the size and hash but the file contents will be decompressed.
 `,
 			Advanced: true,
 			Default:  false,
+		}, {
+			Name: "chunk_size",
+			Help: `Chunk size to use for multi-thread uploads.
+
+When rclone copies a large file with a multi-thread copy (see
+--multi-thread-cutoff) it uploads it in chunks of this size using the
+XML API multipart upload, with --gcs-upload-concurrency chunks being
+uploaded at once.
+
+Objects uploaded like this have no MD5 hash, only a CRC32C, so rclone
+can't check their hash afterwards.
+
+The minimum is 5 MiB. The chunk size is increased if needed to keep
+the number of chunks below 10,000.`,
+			Default:  defaultChunkSize,
+			Advanced: true,
+		}, {
+			Name: "upload_concurrency",
+			Help: `Concurrency for multi-thread uploads.
+
+This is the number of chunks of the same file that are uploaded
+concurrently.`,
+			Default:  4,
+			Advanced: true,
 		}, {
 			Name:     "endpoint",
 			Help:     "Endpoint for the service.\n\nLeave blank normally.",
@@ -347,6 +374,8 @@ type Options struct {
 	StorageClass              string               `config:"storage_class"`
 	NoCheckBucket             bool                 `config:"no_check_bucket"`
 	Decompress                bool                 `config:"decompress"`
+	ChunkSize                 fs.SizeSuffix        `config:"chunk_size"`
+	UploadConcurrency         int                  `config:"upload_concurrency"`
 	Endpoint                  string               `config:"endpoint"`
 	Enc                       encoder.MultiEncoder `config:"encoding"`
 }
@@ -484,6 +513,9 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	if opt.BucketACL == "" {
 		opt.BucketACL = "private"
 	}
+	if opt.ChunkSize < minChunkSize {
+		return nil, fmt.Errorf("chunk size: %s is less than %s", opt.ChunkSize, minChunkSize)
+	}
 
 	// try loading service account credentials from env variable, then from a file
 	if opt.ServiceAccountCredentials == "" && opt.ServiceAccountFile != "" {
@@ -1262,10 +1294,11 @@ func (o *Object) MimeType(ctx context.Context) string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Copier      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.ListRer     = &Fs{}
-	_ fs.Object      = &Object{}
-	_ fs.MimeTyper   = &Object{}
+	_ fs.Fs              = &Fs{}
+	_ fs.Copier          = &Fs{}
+	_ fs.PutStreamer     = &Fs{}
+	_ fs.ListRer         = &Fs{}
+	_ fs.OpenChunkWriter = &Fs{}
+	_ fs.Object          = &Object{}
+	_ fs.MimeTyper       = &Object{}
 )
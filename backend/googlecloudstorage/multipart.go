@@ -0,0 +1,314 @@
+// Multipart uploads for multi-thread copies
+//
+// The JSON API has no way of uploading the parts of an object in
+// parallel so this uses the multipart upload of the XML API.
+//
+// Docs - https://cloud.google.com/storage/docs/multipart-uploads
+
+package googlecloudstorage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/chunksize"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/lib/rest"
+)
+
+// xmlRootURL is the root of the XML API
+const xmlRootURL = "https://storage.googleapis.com"
+
+// predefinedACLs maps the JSON API names of the predefined ACLs used
+// in the config to the canned ACLs of the XML API
+var predefinedACLs = map[string]string{
+	"authenticatedRead":      "authenticated-read",
+	"bucketOwnerFullControl": "bucket-owner-full-control",
+	"bucketOwnerRead":        "bucket-owner-read",
+	"private":                "private",
+	"projectPrivate":         "project-private",
+	"publicRead":             "public-read",
+	"publicReadWrite":        "public-read-write",
+}
+
+// initiateMultipartUploadResult is returned when starting an upload
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// completedPart is a part which has been uploaded
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// completeMultipartUpload is sent to finish the upload
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// xmlError is an error returned by the XML API
+type xmlError struct {
+	XMLName    xml.Name `xml:"Error"`
+	Code       string   `xml:"Code"`
+	Message    string   `xml:"Message"`
+	StatusCode int      `xml:"-"`
+}
+
+// Error satisfies the error interface
+func (e *xmlError) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// xmlErrorHandler parses a non 2xx error response into an error
+func xmlErrorHandler(resp *http.Response) error {
+	body, err := rest.ReadBody(resp)
+	if err != nil {
+		return fmt.Errorf("error reading error out of body: %w", err)
+	}
+	e := &xmlError{
+		StatusCode: resp.StatusCode,
+	}
+	if xml.Unmarshal(body, e) != nil || e.Message == "" {
+		e.Code = resp.Status
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// shouldRetryXML returns a boolean as to whether this resp and err
+// from the XML API deserve to be retried. It returns the err as a
+// convenience
+func shouldRetryXML(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if fserrors.ContextError(ctx, &err) {
+		return false, err
+	}
+	if fserrors.ShouldRetry(err) {
+		return true, err
+	}
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) {
+		return true, err
+	}
+	return false, err
+}
+
+// newXMLClient makes a client for the XML API using the same
+// credentials as the JSON API
+func (f *Fs) newXMLClient() (*rest.Client, error) {
+	rootURL := xmlRootURL
+	if f.opt.Endpoint != "" {
+		u, err := url.Parse(f.opt.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse endpoint: %w", err)
+		}
+		rootURL = u.Scheme + "://" + u.Host
+	}
+	return rest.NewClient(f.client).SetRoot(rootURL).SetErrorHandler(xmlErrorHandler), nil
+}
+
+// gcsChunkWriter uploads the parts of a multipart upload
+type gcsChunkWriter struct {
+	f        *Fs
+	o        *Object
+	srv      *rest.Client // client for the XML API
+	path     string       // path of the object relative to the root URL
+	uploadID string
+	partsMu  sync.Mutex // to protect parts
+	parts    []completedPart
+}
+
+// OpenChunkWriter returns the chunk size and a ChunkWriter
+//
+// Pass in the remote and the src object
+func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	// Temporary Object under construction
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	bucket, bucketPath := o.split()
+	err = f.checkBucket(ctx, bucket)
+	if err != nil {
+		return info, nil, err
+	}
+	srv, err := f.newXMLClient()
+	if err != nil {
+		return info, nil, err
+	}
+
+	contentType := fs.MimeType(ctx, src)
+	headers := map[string]string{}
+	for k, v := range metadataFromModTime(src.ModTime(ctx)) {
+		headers["x-goog-meta-"+k] = v
+	}
+	if !f.opt.BucketPolicyOnly {
+		acl, ok := predefinedACLs[f.opt.ObjectACL]
+		if !ok {
+			return info, nil, fmt.Errorf("object ACL %q not supported for multipart uploads", f.opt.ObjectACL)
+		}
+		headers["x-goog-acl"] = acl
+	}
+	// Apply upload options
+	for _, option := range options {
+		key, value := option.Header()
+		lowerKey := strings.ToLower(key)
+		switch {
+		case lowerKey == "":
+			// ignore
+		case lowerKey == "content-type":
+			contentType = value
+		case lowerKey == "cache-control", lowerKey == "content-disposition", lowerKey == "content-encoding",
+			lowerKey == "content-language", lowerKey == "x-goog-storage-class",
+			strings.HasPrefix(lowerKey, "x-goog-meta-"):
+			headers[lowerKey] = value
+		default:
+			fs.Errorf(o, "Don't know how to set key %q on upload", key)
+		}
+	}
+
+	w := &gcsChunkWriter{
+		f:    f,
+		o:    o,
+		srv:  srv,
+		path: "/" + bucket + "/" + rest.URLPathEscape(bucketPath),
+	}
+	opts := rest.Opts{
+		Method:       "POST",
+		Path:         w.path + "?uploads",
+		ContentType:  contentType,
+		ExtraHeaders: headers,
+	}
+	var result initiateMultipartUploadResult
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err := srv.CallXML(ctx, &opts, nil, &result)
+		return shouldRetryXML(ctx, resp, err)
+	})
+	if err != nil {
+		return info, nil, fmt.Errorf("multipart upload failed to initialise: %w", err)
+	}
+	if result.UploadID == "" {
+		return info, nil, fmt.Errorf("multipart upload failed to initialise: no upload ID returned")
+	}
+	w.uploadID = result.UploadID
+
+	chunkSize := chunksize.Calculator(o, src.Size(), maxUploadParts, f.opt.ChunkSize)
+	info = fs.ChunkWriterInfo{
+		ChunkSize:   int64(chunkSize),
+		Concurrency: f.opt.UploadConcurrency,
+	}
+	return info, w, nil
+}
+
+// WriteChunk uploads chunk chunkNumber, counting from 0, reading it
+// from reader
+func (w *gcsChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	if chunkNumber < 0 || chunkNumber >= maxUploadParts {
+		return -1, fmt.Errorf("invalid chunk number %d", chunkNumber)
+	}
+
+	// create checksum of the chunk for integrity checking
+	m := md5.New()
+	size, err := io.Copy(m, reader)
+	if err != nil {
+		return -1, fmt.Errorf("multipart upload failed to read part: %w", err)
+	}
+	md5sum := base64.StdEncoding.EncodeToString(m.Sum(nil))
+
+	// Part numbers start at 1
+	partNumber := chunkNumber + 1
+	var resp *http.Response
+	err = w.f.pacer.Call(func() (bool, error) {
+		// rewind the reader after the checksum and on retry
+		_, err := reader.Seek(0, io.SeekStart)
+		if err != nil {
+			return false, err
+		}
+		opts := rest.Opts{
+			Method:        "PUT",
+			Path:          w.path,
+			Body:          reader,
+			ContentLength: &size,
+			ExtraHeaders: map[string]string{
+				"Content-MD5": md5sum,
+			},
+			Parameters: url.Values{
+				"partNumber": {fmt.Sprint(partNumber)},
+				"uploadId":   {w.uploadID},
+			},
+			NoResponse: true,
+		}
+		resp, err = w.srv.Call(ctx, &opts)
+		return shouldRetryXML(ctx, resp, err)
+	})
+	if err != nil {
+		return -1, fmt.Errorf("multipart upload failed to upload part %d: %w", partNumber, err)
+	}
+	w.partsMu.Lock()
+	w.parts = append(w.parts, completedPart{
+		PartNumber: partNumber,
+		ETag:       resp.Header.Get("ETag"),
+	})
+	w.partsMu.Unlock()
+	return size, nil
+}
+
+// Abort the multipart upload deleting any parts uploaded so far
+func (w *gcsChunkWriter) Abort(ctx context.Context) error {
+	opts := rest.Opts{
+		Method: "DELETE",
+		Path:   w.path,
+		Parameters: url.Values{
+			"uploadId": {w.uploadID},
+		},
+		NoResponse: true,
+	}
+	err := w.f.pacer.Call(func() (bool, error) {
+		resp, err := w.srv.Call(ctx, &opts)
+		return shouldRetryXML(ctx, resp, err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel multipart upload: %w", err)
+	}
+	return nil
+}
+
+// Close completes the multipart upload
+func (w *gcsChunkWriter) Close(ctx context.Context) error {
+	// sort the completed parts by part number
+	sort.Slice(w.parts, func(i, j int) bool {
+		return w.parts[i].PartNumber < w.parts[j].PartNumber
+	})
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   w.path,
+		Parameters: url.Values{
+			"uploadId": {w.uploadID},
+		},
+		NoResponse: true,
+	}
+	request := completeMultipartUpload{
+		Parts: w.parts,
+	}
+	err := w.f.pacer.Call(func() (bool, error) {
+		resp, err := w.srv.CallXML(ctx, &opts, &request, nil)
+		return shouldRetryXML(ctx, resp, err)
+	})
+	if err != nil {
+		return fmt.Errorf("multipart upload failed to finalise: %w", err)
+	}
+	return nil
+}
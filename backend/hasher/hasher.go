@@ -39,7 +39,10 @@ func init() {
 			Name:     "hashes",
 			Default:  fs.CommaSepList{"md5", "sha1"},
 			Advanced: false,
-			Help:     "Comma separated list of supported checksum types.",
+			Help: `Comma separated list of supported checksum types.
+
+Any hash type supported by rclone can be used. The blake3, xxh3 and
+xxh128 hashes are much faster to calculate than md5 or sha1.`,
 		}, {
 			Name:     "max_age",
 			Advanced: false,
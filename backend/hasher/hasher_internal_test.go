@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
 	"github.com/rclone/rclone/fstest/fstests"
@@ -76,3 +78,32 @@ func (f *Fs) InternalTest(t *testing.T) {
 }
 
 var _ fstests.InternalTester = (*Fs)(nil)
+
+// TestFastHashes checks that the fast hashes can be stored for a
+// remote which doesn't support them
+func TestFastHashes(t *testing.T) {
+	if !kv.Supported() {
+		t.Skip("hasher is not supported on this OS")
+	}
+	ctx := context.Background()
+	pass := obscure.MustObscure("crypt")
+	remote := fmt.Sprintf(`:hasher,hashes="blake3,xxh3,xxh128",remote=":crypt,remote='%s',password='%s':":`, t.TempDir(), pass)
+	f, err := fs.NewFs(ctx, remote)
+	require.NoError(t, err)
+
+	want := hash.NewHashSet(hash.BLAKE3, hash.XXH3, hash.XXH128)
+	assert.Equal(t, want, f.Hashes())
+
+	const data = "doggy froggy"
+	o := putFile(ctx, t, f, "file", data)
+	sums, err := hash.StreamTypes(strings.NewReader(data), want)
+	require.NoError(t, err)
+	for _, ht := range want.Array() {
+		sum, err := f.(*Fs).getRawHash(ctx, ht, "file", anyFingerprint, fs.ModTimeNotSupported)
+		require.NoError(t, err, ht)
+		assert.Equal(t, sums[ht], sum, ht)
+		sum, err = o.Hash(ctx, ht)
+		require.NoError(t, err, ht)
+		assert.Equal(t, sums[ht], sum, ht)
+	}
+}
@@ -4,8 +4,6 @@ package memory
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"path"
@@ -22,7 +20,7 @@ import (
 )
 
 var (
-	hashType = hash.MD5
+	hashTypes = hash.NewHashSet(hash.MD5, hash.BLAKE3, hash.XXH3, hash.XXH128)
 	// the object storage is persistent
 	buckets = newBucketsInfo()
 )
@@ -162,7 +160,8 @@ func (bi *bucketInfo) isEmpty() (empty bool) {
 // the object data and metadata
 type objectData struct {
 	modTime  time.Time
-	hash     string
+	hashMu   sync.Mutex           // protects hashes
+	hashes   map[hash.Type]string // hashes calculated so far
 	mimeType string
 	data     []byte
 }
@@ -488,7 +487,7 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
-	return hash.Set(hashType)
+	return hashTypes
 }
 
 // ------------------------------------------------------------
@@ -513,14 +512,23 @@ func (o *Object) Remote() string {
 
 // Hash returns the hash of an object returning a lowercase hex string
 func (o *Object) Hash(ctx context.Context, t hash.Type) (string, error) {
-	if t != hashType {
+	if !hashTypes.Contains(t) {
 		return "", hash.ErrUnsupported
 	}
-	if o.od.hash == "" {
-		sum := md5.Sum(o.od.data)
-		o.od.hash = hex.EncodeToString(sum[:])
+	o.od.hashMu.Lock()
+	defer o.od.hashMu.Unlock()
+	if sum, ok := o.od.hashes[t]; ok {
+		return sum, nil
 	}
-	return o.od.hash, nil
+	sums, err := hash.StreamTypes(bytes.NewReader(o.od.data), hash.NewHashSet(t))
+	if err != nil {
+		return "", err
+	}
+	if o.od.hashes == nil {
+		o.od.hashes = make(map[hash.Type]string, 1)
+	}
+	o.od.hashes[t] = sums[t]
+	return sums[t], nil
 }
 
 // Size returns the size of an object in bytes
@@ -588,7 +596,6 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	}
 	o.od = &objectData{
 		data:     data,
-		hash:     "",
 		modTime:  src.ModTime(ctx),
 		mimeType: fs.MimeType(ctx, src),
 	}
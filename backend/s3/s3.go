@@ -2279,6 +2279,21 @@ var systemMetadataInfo = map[string]fs.MetadataHelp{
 		Example:  "2006-01-02T15:04:05.999999999Z07:00",
 		ReadOnly: true,
 	},
+	"object-lock-mode": {
+		Help:    "Object Lock mode to upload with - GOVERNANCE or COMPLIANCE - not read back",
+		Type:    "string",
+		Example: "GOVERNANCE",
+	},
+	"object-lock-retain-until-date": {
+		Help:    "Time the Object Lock retention of the upload ends - not read back",
+		Type:    "RFC 3339",
+		Example: "2006-01-02T15:04:05.999999999Z07:00",
+	},
+	"object-lock-legal-hold-status": {
+		Help:    "Object Lock legal hold to upload with - ON or OFF - not read back",
+		Type:    "string",
+		Example: "ON",
+	},
 }
 
 // Options defines the configuration for this backend
@@ -4126,6 +4141,18 @@ supplied.
 It may return "Enabled", "Suspended" or "Unversioned". Note that once versioning
 has been enabled the status can't be set back to "Unversioned".
 `,
+}, {
+	Name:  "object-lock",
+	Short: "Get Object Lock support for a bucket.",
+	Long: `This command returns whether Object Lock is enabled on the bucket
+supplied.
+
+    rclone backend object-lock s3:bucket
+
+It returns "Enabled" or "Disabled". Object Lock can only be enabled
+when a bucket is created. Uploads need it to use the object-lock-*
+metadata.
+`,
 }}
 
 // Command the backend to run a named command
@@ -4220,6 +4247,8 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 		return nil, f.CleanUpHidden(ctx)
 	case "versioning":
 		return f.setGetVersioning(ctx, arg...)
+	case "object-lock":
+		return f.getObjectLock(ctx)
 	default:
 		return nil, fs.ErrorCommandNotFound
 	}
@@ -4399,6 +4428,31 @@ func (f *Fs) setGetVersioning(ctx context.Context, arg ...string) (status string
 	return *resp.Status, err
 }
 
+// getObjectLock reads whether Object Lock is enabled on the bucket
+func (f *Fs) getObjectLock(ctx context.Context) (status string, err error) {
+	if f.rootBucket == "" {
+		return "", errors.New("need a bucket")
+	}
+	req := s3.GetObjectLockConfigurationInput{
+		Bucket: &f.rootBucket,
+	}
+	var resp *s3.GetObjectLockConfigurationOutput
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.c.GetObjectLockConfigurationWithContext(ctx, &req)
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ObjectLockConfigurationNotFoundError" {
+			return "Disabled", nil
+		}
+		return "", err
+	}
+	if resp.ObjectLockConfiguration == nil || aws.StringValue(resp.ObjectLockConfiguration.ObjectLockEnabled) != s3.ObjectLockEnabledEnabled {
+		return "Disabled", nil
+	}
+	return "Enabled", nil
+}
+
 // CleanUp removes all pending multipart uploads older than 24 hours
 func (f *Fs) CleanUp(ctx context.Context) (err error) {
 	return f.cleanUp(ctx, 24*time.Hour)
@@ -4913,15 +4967,30 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.Read
 
 var warnStreamUpload sync.Once
 
-func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, size int64, in io.Reader) (etag string, versionID *string, err error) {
+// s3ChunkWriter uploads the parts of a multipart upload
+type s3ChunkWriter struct {
+	f           *Fs
+	o           *Object
+	req         *s3.PutObjectInput // request the upload was made from
+	uploadID    *string
+	chunkSize   int64
+	concurrency int
+	partsMu     sync.Mutex // to protect parts
+	parts       []*s3.CompletedPart
+	md5sMu      sync.Mutex // to protect md5s
+	md5s        []byte
+	versionID   *string // set by Close
+}
+
+// newChunkWriter starts a multipart upload of req for an object of
+// size bytes, which may be -1 if it isn't known
+func (o *Object) newChunkWriter(ctx context.Context, req *s3.PutObjectInput, size int64) (w *s3ChunkWriter, err error) {
 	f := o.fs
 
-	// make concurrency machinery
 	concurrency := f.opt.UploadConcurrency
 	if concurrency < 1 {
 		concurrency = 1
 	}
-	tokens := pacer.NewTokenDispenser(concurrency)
 
 	uploadParts := f.opt.MaxUploadParts
 	if uploadParts < 1 {
@@ -4945,8 +5014,6 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 		partSize = chunksize.Calculator(o, size, uploadParts, f.opt.ChunkSize)
 	}
 
-	memPool := f.getMemoryPool(int64(partSize))
-
 	var mReq s3.CreateMultipartUploadInput
 	//structs.SetFrom(&mReq, req)
 	setFrom_s3CreateMultipartUploadInput_s3PutObjectInput(&mReq, req)
@@ -4954,27 +5021,310 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 	err = f.pacer.Call(func() (bool, error) {
 		var err error
 		cout, err = f.c.CreateMultipartUploadWithContext(ctx, &mReq)
+		if err == nil && (cout == nil || cout.UploadId == nil) {
+			err = fserrors.RetryErrorf("internal error: no UploadId from multipart upload")
+		}
 		return f.shouldRetry(ctx, err)
 	})
 	if err != nil {
-		return etag, nil, fmt.Errorf("multipart upload failed to initialise: %w", err)
+		return nil, fmt.Errorf("multipart upload failed to initialise: %w", err)
+	}
+	return &s3ChunkWriter{
+		f:           f,
+		o:           o,
+		req:         req,
+		uploadID:    cout.UploadId,
+		chunkSize:   int64(partSize),
+		concurrency: concurrency,
+	}, nil
+}
+
+// addMd5 stores the md5 of chunk chunkNumber
+func (w *s3ChunkWriter) addMd5(md5binary *[md5.Size]byte, chunkNumber int) {
+	w.md5sMu.Lock()
+	defer w.md5sMu.Unlock()
+	start := chunkNumber * md5.Size
+	end := start + md5.Size
+	if extend := end - len(w.md5s); extend > 0 {
+		w.md5s = append(w.md5s, make([]byte, extend)...)
 	}
-	uid := cout.UploadId
+	copy(w.md5s[start:end], (*md5binary)[:])
+}
+
+// addCompletedPart records the Etag of part partNum replacing any
+// previous upload of the part
+func (w *s3ChunkWriter) addCompletedPart(partNum int64, eTag *string) {
+	w.partsMu.Lock()
+	defer w.partsMu.Unlock()
+	for _, part := range w.parts {
+		if *part.PartNumber == partNum {
+			part.ETag = eTag
+			return
+		}
+	}
+	w.parts = append(w.parts, &s3.CompletedPart{
+		PartNumber: &partNum,
+		ETag:       eTag,
+	})
+}
+
+// s3ResumeState is the state persisted to resume a multipart upload
+type s3ResumeState struct {
+	UploadID  string           `json:"uploadId"`
+	ChunkSize int64            `json:"chunkSize"`
+	Parts     map[int64]string `json:"parts"` // Etags of the parts uploaded by part number
+	MD5s      []byte           `json:"md5s"`
+}
+
+// ResumeState returns the state needed to resume the upload with
+// ResumeChunkWriter
+func (w *s3ChunkWriter) ResumeState() ([]byte, error) {
+	state := s3ResumeState{
+		UploadID:  *w.uploadID,
+		ChunkSize: w.chunkSize,
+		Parts:     make(map[int64]string),
+	}
+	w.partsMu.Lock()
+	for _, part := range w.parts {
+		state.Parts[*part.PartNumber] = aws.StringValue(part.ETag)
+	}
+	w.partsMu.Unlock()
+	w.md5sMu.Lock()
+	state.MD5s = append([]byte(nil), w.md5s...)
+	w.md5sMu.Unlock()
+	return json.Marshal(&state)
+}
+
+// ResumeChunkWriter carries on with an upload started by
+// OpenChunkWriter using the state returned by ResumeState
+func (f *Fs) ResumeChunkWriter(ctx context.Context, remote string, state []byte) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	var resumeState s3ResumeState
+	err = json.Unmarshal(state, &resumeState)
+	if err != nil {
+		return info, nil, fmt.Errorf("failed to decode multipart upload state: %w", err)
+	}
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	bucket, bucketPath := o.split()
+
+	// Only the parts of the request used after the upload has
+	// been created are needed
+	req := &s3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    &bucketPath,
+	}
+	if f.opt.RequesterPays {
+		req.RequestPayer = aws.String(s3.RequestPayerRequester)
+	}
+	if f.opt.SSECustomerAlgorithm != "" {
+		req.SSECustomerAlgorithm = &f.opt.SSECustomerAlgorithm
+	}
+	if f.opt.SSECustomerKey != "" {
+		req.SSECustomerKey = &f.opt.SSECustomerKey
+	}
+	if f.opt.SSECustomerKeyMD5 != "" {
+		req.SSECustomerKeyMD5 = &f.opt.SSECustomerKeyMD5
+	}
+
+	// Check the upload still exists
+	err = f.pacer.Call(func() (bool, error) {
+		_, err := f.c.ListPartsWithContext(ctx, &s3.ListPartsInput{
+			Bucket:       req.Bucket,
+			Key:          req.Key,
+			UploadId:     &resumeState.UploadID,
+			MaxParts:     aws.Int64(1),
+			RequestPayer: req.RequestPayer,
+		})
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return info, nil, fmt.Errorf("failed to find multipart upload %q: %w", resumeState.UploadID, err)
+	}
+
+	concurrency := f.opt.UploadConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	w := &s3ChunkWriter{
+		f:           f,
+		o:           o,
+		req:         req,
+		uploadID:    &resumeState.UploadID,
+		chunkSize:   resumeState.ChunkSize,
+		concurrency: concurrency,
+		md5s:        resumeState.MD5s,
+	}
+	for partNum, eTag := range resumeState.Parts {
+		w.addCompletedPart(partNum, aws.String(eTag))
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize:         w.chunkSize,
+		Concurrency:       w.concurrency,
+		LeavePartsOnError: f.opt.LeavePartsOnError,
+	}
+	return info, w, nil
+}
+
+// WriteChunk uploads chunk chunkNumber, counting from 0, reading
+// it from reader
+func (w *s3ChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	if chunkNumber < 0 {
+		return -1, fmt.Errorf("invalid chunk number %d", chunkNumber)
+	}
+
+	// create checksum of the chunk for integrity checking
+	m := md5.New()
+	partLength, err := io.Copy(m, reader)
+	if err != nil {
+		return -1, fmt.Errorf("multipart upload failed to read part: %w", err)
+	}
+	var md5sumBinary [md5.Size]byte
+	copy(md5sumBinary[:], m.Sum(nil))
+	w.addMd5(&md5sumBinary, chunkNumber)
+	md5sum := base64.StdEncoding.EncodeToString(md5sumBinary[:])
+
+	// S3 part numbers start at 1
+	partNum := int64(chunkNumber + 1)
+	req := w.req
+	err = w.f.pacer.Call(func() (bool, error) {
+		// rewind the reader after the checksum and on retry
+		_, err := reader.Seek(0, io.SeekStart)
+		if err != nil {
+			return false, err
+		}
+		uploadPartReq := &s3.UploadPartInput{
+			Body:                 reader,
+			Bucket:               req.Bucket,
+			Key:                  req.Key,
+			PartNumber:           &partNum,
+			UploadId:             w.uploadID,
+			ContentMD5:           &md5sum,
+			ContentLength:        &partLength,
+			RequestPayer:         req.RequestPayer,
+			SSECustomerAlgorithm: req.SSECustomerAlgorithm,
+			SSECustomerKey:       req.SSECustomerKey,
+			SSECustomerKeyMD5:    req.SSECustomerKeyMD5,
+		}
+		uout, err := w.f.c.UploadPartWithContext(ctx, uploadPartReq)
+		if err != nil {
+			if partNum <= int64(w.concurrency) {
+				return w.f.shouldRetry(ctx, err)
+			}
+			// retry all chunks once have done the first batch
+			return true, err
+		}
+		w.addCompletedPart(partNum, uout.ETag)
+		return false, nil
+	})
+	if err != nil {
+		return -1, fmt.Errorf("multipart upload failed to upload part: %w", err)
+	}
+	return partLength, nil
+}
+
+// Abort the multipart upload deleting any parts uploaded so far
+func (w *s3ChunkWriter) Abort(ctx context.Context) error {
+	err := w.f.pacer.Call(func() (bool, error) {
+		_, err := w.f.c.AbortMultipartUploadWithContext(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:       w.req.Bucket,
+			Key:          w.req.Key,
+			UploadId:     w.uploadID,
+			RequestPayer: w.req.RequestPayer,
+		})
+		return w.f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel multipart upload: %w", err)
+	}
+	return nil
+}
+
+// Close completes the multipart upload
+func (w *s3ChunkWriter) Close(ctx context.Context) (err error) {
+	// sort the completed parts by part number
+	sort.Slice(w.parts, func(i, j int) bool {
+		return *w.parts[i].PartNumber < *w.parts[j].PartNumber
+	})
+
+	var resp *s3.CompleteMultipartUploadOutput
+	err = w.f.pacer.Call(func() (bool, error) {
+		resp, err = w.f.c.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket: w.req.Bucket,
+			Key:    w.req.Key,
+			MultipartUpload: &s3.CompletedMultipartUpload{
+				Parts: w.parts,
+			},
+			RequestPayer: w.req.RequestPayer,
+			UploadId:     w.uploadID,
+		})
+		return w.f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return fmt.Errorf("multipart upload failed to finalise: %w", err)
+	}
+	if resp != nil {
+		w.versionID = resp.VersionId
+	}
+	return nil
+}
+
+// etag returns the Etag S3 should give the completed upload
+func (w *s3ChunkWriter) etag() string {
+	hashOfHashes := md5.Sum(w.md5s)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(hashOfHashes[:]), len(w.parts))
+}
+
+// OpenChunkWriter returns the chunk size and a ChunkWriter
+//
+// Pass in the remote and the src object
+func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	if f.opt.VersionAt.IsSet() {
+		return info, nil, errNotWithVersionAt
+	}
+	// Temporary Object under construction
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	bucket, _ := o.split()
+	err = f.makeBucket(ctx, bucket)
+	if err != nil {
+		return info, nil, err
+	}
+	req, _, err := o.buildPutObjectInput(ctx, src, options, true)
+	if err != nil {
+		return info, nil, err
+	}
+	w, err := o.newChunkWriter(ctx, req, src.Size())
+	if err != nil {
+		return info, nil, err
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize:         w.chunkSize,
+		Concurrency:       w.concurrency,
+		LeavePartsOnError: f.opt.LeavePartsOnError,
+	}
+	return info, w, nil
+}
+
+func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, size int64, in io.Reader) (etag string, versionID *string, err error) {
+	w, err := o.newChunkWriter(ctx, req, size)
+	if err != nil {
+		return etag, nil, err
+	}
+
+	// make concurrency machinery
+	tokens := pacer.NewTokenDispenser(w.concurrency)
+	memPool := o.fs.getMemoryPool(w.chunkSize)
 
 	defer atexit.OnError(&err, func() {
 		if o.fs.opt.LeavePartsOnError {
 			return
 		}
 		fs.Debugf(o, "Cancelling multipart upload")
-		errCancel := f.pacer.Call(func() (bool, error) {
-			_, err := f.c.AbortMultipartUploadWithContext(context.Background(), &s3.AbortMultipartUploadInput{
-				Bucket:       req.Bucket,
-				Key:          req.Key,
-				UploadId:     uid,
-				RequestPayer: req.RequestPayer,
-			})
-			return f.shouldRetry(ctx, err)
-		})
+		errCancel := w.Abort(ctx)
 		if errCancel != nil {
 			fs.Debugf(o, "Failed to cancel multipart upload: %v", errCancel)
 		}
@@ -4983,25 +5333,10 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 	var (
 		g, gCtx  = errgroup.WithContext(ctx)
 		finished = false
-		partsMu  sync.Mutex // to protect parts
-		parts    []*s3.CompletedPart
 		off      int64
-		md5sMu   sync.Mutex
-		md5s     []byte
 	)
 
-	addMd5 := func(md5binary *[md5.Size]byte, partNum int64) {
-		md5sMu.Lock()
-		defer md5sMu.Unlock()
-		start := partNum * md5.Size
-		end := start + md5.Size
-		if extend := end - int64(len(md5s)); extend > 0 {
-			md5s = append(md5s, make([]byte, extend)...)
-		}
-		copy(md5s[start:end], (*md5binary)[:])
-	}
-
-	for partNum := int64(1); !finished; partNum++ {
+	for chunkNumber := 0; !finished; chunkNumber++ {
 		// Get a block of memory from the pool and token which limits concurrency.
 		tokens.Get()
 		buf := memPool.Get()
@@ -5023,7 +5358,7 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 		var n int
 		n, err = readers.ReadFill(in, buf) // this can never return 0, nil
 		if err == io.EOF {
-			if n == 0 && partNum != 1 { // end if no data and if not first chunk
+			if n == 0 && chunkNumber != 0 { // end if no data and if not first chunk
 				free()
 				break
 			}
@@ -5034,53 +5369,13 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 		}
 		buf = buf[:n]
 
-		partNum := partNum
-		fs.Debugf(o, "multipart upload starting chunk %d size %v offset %v/%v", partNum, fs.SizeSuffix(n), fs.SizeSuffix(off), fs.SizeSuffix(size))
+		chunkNumber := chunkNumber
+		fs.Debugf(o, "multipart upload starting chunk %d size %v offset %v/%v", chunkNumber+1, fs.SizeSuffix(n), fs.SizeSuffix(off), fs.SizeSuffix(size))
 		off += int64(n)
 		g.Go(func() (err error) {
 			defer free()
-			partLength := int64(len(buf))
-
-			// create checksum of buffer for integrity checking
-			md5sumBinary := md5.Sum(buf)
-			addMd5(&md5sumBinary, partNum-1)
-			md5sum := base64.StdEncoding.EncodeToString(md5sumBinary[:])
-
-			err = f.pacer.Call(func() (bool, error) {
-				uploadPartReq := &s3.UploadPartInput{
-					Body:                 bytes.NewReader(buf),
-					Bucket:               req.Bucket,
-					Key:                  req.Key,
-					PartNumber:           &partNum,
-					UploadId:             uid,
-					ContentMD5:           &md5sum,
-					ContentLength:        &partLength,
-					RequestPayer:         req.RequestPayer,
-					SSECustomerAlgorithm: req.SSECustomerAlgorithm,
-					SSECustomerKey:       req.SSECustomerKey,
-					SSECustomerKeyMD5:    req.SSECustomerKeyMD5,
-				}
-				uout, err := f.c.UploadPartWithContext(gCtx, uploadPartReq)
-				if err != nil {
-					if partNum <= int64(concurrency) {
-						return f.shouldRetry(ctx, err)
-					}
-					// retry all chunks once have done the first batch
-					return true, err
-				}
-				partsMu.Lock()
-				parts = append(parts, &s3.CompletedPart{
-					PartNumber: &partNum,
-					ETag:       uout.ETag,
-				})
-				partsMu.Unlock()
-
-				return false, nil
-			})
-			if err != nil {
-				return fmt.Errorf("multipart upload failed to upload part: %w", err)
-			}
-			return nil
+			_, err = w.WriteChunk(gCtx, chunkNumber, bytes.NewReader(buf))
+			return err
 		})
 	}
 	err = g.Wait()
@@ -5088,33 +5383,11 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 		return etag, nil, err
 	}
 
-	// sort the completed parts by part number
-	sort.Slice(parts, func(i, j int) bool {
-		return *parts[i].PartNumber < *parts[j].PartNumber
-	})
-
-	var resp *s3.CompleteMultipartUploadOutput
-	err = f.pacer.Call(func() (bool, error) {
-		resp, err = f.c.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
-			Bucket: req.Bucket,
-			Key:    req.Key,
-			MultipartUpload: &s3.CompletedMultipartUpload{
-				Parts: parts,
-			},
-			RequestPayer: req.RequestPayer,
-			UploadId:     uid,
-		})
-		return f.shouldRetry(ctx, err)
-	})
+	err = w.Close(ctx)
 	if err != nil {
-		return etag, nil, fmt.Errorf("multipart upload failed to finalise: %w", err)
-	}
-	hashOfHashes := md5.Sum(md5s)
-	etag = fmt.Sprintf("%s-%d", hex.EncodeToString(hashOfHashes[:]), len(parts))
-	if resp != nil {
-		versionID = resp.VersionId
+		return etag, nil, err
 	}
-	return etag, versionID, nil
+	return w.etag(), w.versionID, nil
 }
 
 // unWrapAwsError unwraps AWS errors, looking for a non AWS error
@@ -5244,22 +5517,17 @@ func (o *Object) uploadSinglepartPresignedRequest(ctx context.Context, req *s3.P
 	return etag, lastModified, versionID, nil
 }
 
-// Update the Object from in with modTime and size
-func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
-	if o.fs.opt.VersionAt.IsSet() {
-		return errNotWithVersionAt
-	}
+// buildPutObjectInput makes the request to upload src to o
+//
+// multipart should be set if the object is going to be uploaded
+// using a multipart upload. The md5sum of the source is returned in
+// hex if it was read.
+func (o *Object) buildPutObjectInput(ctx context.Context, src fs.ObjectInfo, options []fs.OpenOption, multipart bool) (req *s3.PutObjectInput, md5sumHex string, err error) {
 	bucket, bucketPath := o.split()
-	err := o.fs.makeBucket(ctx, bucket)
-	if err != nil {
-		return err
-	}
 	modTime := src.ModTime(ctx)
 	size := src.Size()
 
-	multipart := size < 0 || size >= int64(o.fs.opt.UploadCutoff)
-
-	req := s3.PutObjectInput{
+	req = &s3.PutObjectInput{
 		Bucket: &bucket,
 		ACL:    stringPointerOrNil(o.fs.opt.ACL),
 		Key:    &bucketPath,
@@ -5268,7 +5536,7 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	// Fetch metadata if --metadata is in use
 	meta, err := fs.GetMetadataOptions(ctx, src, options)
 	if err != nil {
-		return fmt.Errorf("failed to read metadata from source object: %w", err)
+		return nil, "", fmt.Errorf("failed to read metadata from source object: %w", err)
 	}
 	req.Metadata = make(map[string]*string, len(meta)+2)
 	// merge metadata into request and user metadata
@@ -5305,6 +5573,17 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		case "btime":
 			// write as metadata since we can't set it
 			req.Metadata[k] = pv
+		case "object-lock-mode":
+			req.ObjectLockMode = pv
+		case "object-lock-retain-until-date":
+			// don't ignore this as the object would be unprotected
+			retainUntil, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to parse metadata %s: %q: %w", k, v, err)
+			}
+			req.ObjectLockRetainUntilDate = &retainUntil
+		case "object-lock-legal-hold-status":
+			req.ObjectLockLegalHoldStatus = pv
 		default:
 			req.Metadata[k] = pv
 		}
@@ -5320,7 +5599,6 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	// - for multipart provided checksums aren't disabled
 	//    - so we can add the md5sum in the metadata as metaMD5Hash
 	var md5sumBase64 string
-	var md5sumHex string
 	if !multipart || !o.fs.opt.DisableChecksum {
 		md5sumHex, err = src.Hash(ctx, hash.MD5)
 		if err == nil && matchMd5.MatchString(md5sumHex) {
@@ -5412,18 +5690,43 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 			delete(req.Metadata, key)
 		}
 	}
+	return req, md5sumHex, nil
+}
+
+// Update the Object from in with modTime and size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	if o.fs.opt.VersionAt.IsSet() {
+		return errNotWithVersionAt
+	}
+	bucket, _ := o.split()
+	err := o.fs.makeBucket(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	size := src.Size()
+	multipart := size < 0 || size >= int64(o.fs.opt.UploadCutoff)
+	req, md5sumHex, err := o.buildPutObjectInput(ctx, src, options, multipart)
+	if err != nil {
+		return err
+	}
+	if !multipart && req.ContentMD5 == nil && (req.ObjectLockMode != nil || req.ObjectLockLegalHoldStatus != nil) {
+		// S3 refuses Object Lock settings without an MD5 which we
+		// only have for single part uploads if the source has one,
+		// but always send for each part of a multipart upload.
+		multipart = true
+	}
 
 	var wantETag string        // Multipart upload Etag to check
 	var gotEtag string         // Etag we got from the upload
 	var lastModified time.Time // Time we got from the upload
 	var versionID *string      // versionID we got from the upload
 	if multipart {
-		wantETag, versionID, err = o.uploadMultipart(ctx, &req, size, in)
+		wantETag, versionID, err = o.uploadMultipart(ctx, req, size, in)
 	} else {
 		if o.fs.opt.UsePresignedRequest {
-			gotEtag, lastModified, versionID, err = o.uploadSinglepartPresignedRequest(ctx, &req, size, in)
+			gotEtag, lastModified, versionID, err = o.uploadSinglepartPresignedRequest(ctx, req, size, in)
 		} else {
-			gotEtag, lastModified, versionID, err = o.uploadSinglepartPutObject(ctx, &req, size, in)
+			gotEtag, lastModified, versionID, err = o.uploadSinglepartPutObject(ctx, req, size, in)
 		}
 	}
 	if err != nil {
@@ -5437,7 +5740,7 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	if o.fs.opt.NoHead && size >= 0 {
 		var head s3.HeadObjectOutput
 		//structs.SetFrom(&head, &req)
-		setFrom_s3HeadObjectOutput_s3PutObjectInput(&head, &req)
+		setFrom_s3HeadObjectOutput_s3PutObjectInput(&head, req)
 		head.ETag = &md5sumHex // doesn't matter quotes are missing
 		head.ContentLength = &size
 		// If we have done a single part PUT request then we can read these
@@ -5576,16 +5879,19 @@ func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error)
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Purger      = &Fs{}
-	_ fs.Copier      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.ListRer     = &Fs{}
-	_ fs.Commander   = &Fs{}
-	_ fs.CleanUpper  = &Fs{}
-	_ fs.Object      = &Object{}
-	_ fs.MimeTyper   = &Object{}
-	_ fs.GetTierer   = &Object{}
-	_ fs.SetTierer   = &Object{}
-	_ fs.Metadataer  = &Object{}
+	_ fs.Fs                   = &Fs{}
+	_ fs.Purger               = &Fs{}
+	_ fs.Copier               = &Fs{}
+	_ fs.PutStreamer          = &Fs{}
+	_ fs.ListRer              = &Fs{}
+	_ fs.Commander            = &Fs{}
+	_ fs.CleanUpper           = &Fs{}
+	_ fs.OpenChunkWriter      = &Fs{}
+	_ fs.ResumeChunkWriter    = &Fs{}
+	_ fs.ResumableChunkWriter = &s3ChunkWriter{}
+	_ fs.Object               = &Object{}
+	_ fs.MimeTyper            = &Object{}
+	_ fs.GetTierer            = &Object{}
+	_ fs.SetTierer            = &Object{}
+	_ fs.Metadataer           = &Object{}
 )
@@ -47,7 +47,9 @@ func CopyFileIfExists(srcFile, dstFile string) error {
 	return CopyFile(srcFile, dstFile)
 }
 
-// CopyFile copies a local file
+// CopyFile copies a local file.
+// The copy is made under a temporary name and renamed into place, so
+// dst is never left half written.
 func CopyFile(src, dst string) (err error) {
 	var (
 		rd   io.ReadCloser
@@ -63,19 +65,26 @@ func CopyFile(src, dst string) (err error) {
 	defer func() {
 		_ = rd.Close()
 	}()
-	if wr, err = os.Create(dst); err != nil {
+	tmp := dst + ".tmp"
+	if wr, err = os.Create(tmp); err != nil {
 		return
 	}
 	_, err = io.Copy(wr, rd)
 	if e := wr.Close(); err == nil {
 		err = e
 	}
-	if e := os.Chmod(dst, info.Mode()); err == nil {
+	if e := os.Chmod(tmp, info.Mode()); err == nil {
 		err = e
 	}
-	if e := os.Chtimes(dst, info.ModTime(), info.ModTime()); err == nil {
+	if e := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err == nil {
 		err = e
 	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
 	return
 }
 
@@ -67,6 +67,8 @@ var logReplacements = []string{
 	`^NOTICE: .*?: Replacing invalid UTF-8 characters in "[^"]*"$`, dropMe,
 	// ignore rclone debug messages
 	`^DEBUG : .*$`, dropMe,
+	// ignore the age of reclaimed lock files
+	`^(NOTICE: Reclaiming expired lock file .*) which was last renewed .* ago$`, "$1",
 	// ignore dropbox info messages
 	`^NOTICE: too_many_(requests|write_operations)/\.*: Too many requests or write operations.*$`, dropMe,
 	`^NOTICE: Dropbox root .*?: Forced to upload files to set modification times on this backend.$`, dropMe,
@@ -631,6 +633,32 @@ func (b *bisyncTest) runBisync(ctx context.Context, args []string) (err error) {
 			require.NoError(b.t, err, "parsing max-delete=%q", val)
 		case "size-only":
 			ci.SizeOnly = true
+		case "conflict-resolve":
+			err = opt.ConflictResolve.Set(val)
+			require.NoError(b.t, err, "parsing conflict-resolve=%q", val)
+		case "conflict-loser":
+			err = opt.ConflictLoser.Set(val)
+			require.NoError(b.t, err, "parsing conflict-loser=%q", val)
+		case "conflict-suffix":
+			opt.ConflictSuffix = val
+		case "resilient":
+			opt.Resilient = true
+		case "recover":
+			opt.Recover = true
+		case "compare":
+			err = opt.Compare.Set(val)
+			require.NoError(b.t, err, "parsing compare=%q", val)
+		case "download-hash":
+			opt.DownloadHash = true
+		case "max-lock":
+			opt.MaxLock, err = time.ParseDuration(val)
+			require.NoError(b.t, err, "parsing max-lock=%q", val)
+		case "interrupt-after":
+			bisync.SetFailAfter(val, bisync.ErrInterrupted)
+			defer bisync.SetFailAfter("", nil)
+		case "fail-after":
+			bisync.SetFailAfter(val, errors.New("simulated failure"))
+			defer bisync.SetFailAfter("", nil)
 		case "subdir":
 			fs1 = addSubdir(b.path1, val)
 			fs2 = addSubdir(b.path2, val)
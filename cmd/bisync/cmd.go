@@ -39,14 +39,27 @@ type Options struct {
 	DryRun          bool
 	NoCleanup       bool
 	SaveQueues      bool // save extra debugging files (test only flag)
+	ConflictResolve ConflictResolve
+	ConflictLoser   ConflictLoser
+	ConflictSuffix  string // suffix or "suffix1,suffix2" for renamed conflict losers
+	Resilient       bool
+	Recover         bool
+	MaxLock         time.Duration // age after which a lock file is considered stale, 0 for never
+	Compare         CompareOpt    // attributes which define a changed file, default size,modtime
+	DownloadHash    bool
 }
 
 // Default values
 const (
-	DefaultMaxDelete     int    = 50
-	DefaultCheckFilename string = "RCLONE_TEST"
+	DefaultMaxDelete      int    = 50
+	DefaultCheckFilename  string = "RCLONE_TEST"
+	DefaultConflictSuffix string = "path"
 )
 
+// MinMaxLock is the smallest --max-lock allowed, as the lock is renewed
+// every half of it
+const MinMaxLock = 2 * time.Minute
+
 // DefaultWorkdir is default working directory
 var DefaultWorkdir = filepath.Join(config.GetCacheDir(), "bisync")
 
@@ -92,6 +105,89 @@ func (x *CheckSyncMode) Type() string {
 	return "string"
 }
 
+// ConflictResolve controls which version wins when a file has changed
+// on both paths
+type ConflictResolve int
+
+// ConflictResolve modes
+const (
+	ConflictResolveNone    ConflictResolve = iota // Keep both versions, renaming them (default)
+	ConflictResolveNewer                          // Keep the version with the newer modtime
+	ConflictResolveOlder                          // Keep the version with the older modtime
+	ConflictResolveLarger                         // Keep the larger version
+	ConflictResolveSmaller                        // Keep the smaller version
+	ConflictResolvePath1                          // Always keep the Path1 version
+	ConflictResolvePath2                          // Always keep the Path2 version
+)
+
+var conflictResolveNames = []string{"none", "newer", "older", "larger", "smaller", "path1", "path2"}
+
+func (x ConflictResolve) String() string {
+	if x >= 0 && int(x) < len(conflictResolveNames) {
+		return conflictResolveNames[x]
+	}
+	return "unknown"
+}
+
+// Set a ConflictResolve mode from a string
+func (x *ConflictResolve) Set(s string) error {
+	for i, name := range conflictResolveNames {
+		if strings.ToLower(s) == name {
+			*x = ConflictResolve(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown conflict-resolve mode for bisync: %q", s)
+}
+
+// Type of the ConflictResolve value
+func (x *ConflictResolve) Type() string {
+	return "string"
+}
+
+// ConflictLoser controls what happens to the version which loses a
+// resolved conflict
+type ConflictLoser int
+
+// ConflictLoser modes
+const (
+	ConflictLoserRename ConflictLoser = iota // Rename the loser with the conflict suffix (default)
+	ConflictLoserNum                         // Rename the loser with the conflict suffix and a unique number
+	ConflictLoserDelete                      // Overwrite the loser with the winner
+)
+
+func (x ConflictLoser) String() string {
+	switch x {
+	case ConflictLoserRename:
+		return "rename"
+	case ConflictLoserNum:
+		return "num"
+	case ConflictLoserDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// Set a ConflictLoser mode from a string
+func (x *ConflictLoser) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "rename":
+		*x = ConflictLoserRename
+	case "num":
+		*x = ConflictLoserNum
+	case "delete":
+		*x = ConflictLoserDelete
+	default:
+		return fmt.Errorf("unknown conflict-loser mode for bisync: %q", s)
+	}
+	return nil
+}
+
+// Type of the ConflictLoser value
+func (x *ConflictLoser) Type() string {
+	return "string"
+}
+
 // Opt keeps command line options
 var Opt Options
 
@@ -108,6 +204,14 @@ func init() {
 	flags.StringVarP(cmdFlags, &Opt.Workdir, "workdir", "", Opt.Workdir, makeHelp("Use custom working dir - useful for testing. (default: {WORKDIR})"))
 	flags.BoolVarP(cmdFlags, &tzLocal, "localtime", "", tzLocal, "Use local time in listings (default: UTC)")
 	flags.BoolVarP(cmdFlags, &Opt.NoCleanup, "no-cleanup", "", Opt.NoCleanup, "Retain working files (useful for troubleshooting and testing).")
+	flags.FVarP(cmdFlags, &Opt.ConflictResolve, "conflict-resolve", "", "Automatically resolve conflicts by keeping the version which is: none|newer|older|larger|smaller|path1|path2 (default: none)")
+	flags.FVarP(cmdFlags, &Opt.ConflictLoser, "conflict-loser", "", "Action to take on the loser of a resolved conflict: rename|num|delete (default: rename)")
+	flags.StringVarP(cmdFlags, &Opt.ConflictSuffix, "conflict-suffix", "", Opt.ConflictSuffix, makeHelp("Suffix for renamed conflicts, or 'suffix1,suffix2' to use different suffixes on Path1 and Path2 (default: {CONFLICTSUFFIX})"))
+	flags.BoolVarP(cmdFlags, &Opt.Resilient, "resilient", "", Opt.Resilient, "Allow future runs to retry after certain less-serious errors, instead of requiring --resync.")
+	flags.BoolVarP(cmdFlags, &Opt.Recover, "recover", "", Opt.Recover, "Automatically recover from interruptions without requiring --resync.")
+	flags.DurationVarP(cmdFlags, &Opt.MaxLock, "max-lock", "", Opt.MaxLock, "Consider lock files older than this to be expired (default: 0 (never expire)) (minimum: 2m)")
+	flags.FVarP(cmdFlags, &Opt.Compare, "compare", "", "Comma-separated list of attributes which define a changed file: size,modtime,checksum (default: size,modtime)")
+	flags.BoolVarP(cmdFlags, &Opt.DownloadHash, "download-hash", "", Opt.DownloadHash, "Compute hashes by downloading files when a path doesn't support them. (warning: may be slow)")
 }
 
 // bisync command definition
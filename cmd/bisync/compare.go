@@ -0,0 +1,222 @@
+package bisync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/lib/kv"
+)
+
+// CompareOpt selects the attributes which define a changed file
+type CompareOpt struct {
+	Size     bool
+	Modtime  bool
+	Checksum bool
+}
+
+// DefaultCompare is used if --compare isn't set
+var DefaultCompare = CompareOpt{Size: true, Modtime: true}
+
+func (x CompareOpt) String() string {
+	var names []string
+	if x.Size {
+		names = append(names, "size")
+	}
+	if x.Modtime {
+		names = append(names, "modtime")
+	}
+	if x.Checksum {
+		names = append(names, "checksum")
+	}
+	return strings.Join(names, ",")
+}
+
+// Set the compare options from a comma separated list
+func (x *CompareOpt) Set(s string) error {
+	var c CompareOpt
+	for _, name := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "size":
+			c.Size = true
+		case "modtime":
+			c.Modtime = true
+		case "checksum":
+			c.Checksum = true
+		default:
+			return fmt.Errorf("unknown compare option for bisync: %q", name)
+		}
+	}
+	*x = c
+	return nil
+}
+
+// Type of the CompareOpt value
+func (x *CompareOpt) Type() string {
+	return "string"
+}
+
+// setCompare fills in the default --compare and checks it makes sense
+func (opt *Options) setCompare(ctx context.Context) error {
+	if opt.Compare == (CompareOpt{}) {
+		opt.Compare = DefaultCompare
+	}
+	if opt.Compare.Checksum && fs.GetConfig(ctx).IgnoreChecksum {
+		return errors.New("--compare checksum can't be used with --ignore-checksum")
+	}
+	return nil
+}
+
+// setHashTypes picks the type of hash kept in the listings of each
+// path. A hash supported by both paths is preferred so the versions
+// on either side can be compared with each other. With
+// --download-hash a path with no hashes of its own has them computed
+// by reading the files.
+func (b *bisyncRun) setHashTypes() {
+	common := b.fs1.Hashes().Overlap(b.fs2.Hashes())
+	if common.Count() > 0 {
+		b.hashType1 = common.GetOne()
+		b.hashType2 = b.hashType1
+		return
+	}
+	b.hashType1 = b.fs1.Hashes().GetOne()
+	b.hashType2 = b.fs2.Hashes().GetOne()
+	if !b.opt.DownloadHash {
+		return
+	}
+	switch {
+	case b.hashType1 == hash.None && b.hashType2 == hash.None:
+		b.hashType1, b.hashType2 = hash.MD5, hash.MD5
+	case b.hashType1 == hash.None:
+		b.hashType1 = b.hashType2
+	case b.hashType2 == hash.None:
+		b.hashType2 = b.hashType1
+	}
+}
+
+// hashType returns the type of hash kept in the listings of f
+func (b *bisyncRun) hashType(f fs.Fs) hash.Type {
+	if f == b.fs2 {
+		return b.hashType2
+	}
+	return b.hashType1
+}
+
+// openHashCaches opens the caches of the hashes computed by
+// --download-hash for the paths which need them.
+//
+// Without a cache every file would be downloaded on every run. If a
+// cache can't be opened the hashes are computed without one.
+func (b *bisyncRun) openHashCaches(ctx context.Context) {
+	if !b.opt.DownloadHash {
+		return
+	}
+	open := func(f fs.Fs) *kv.Store {
+		ht := b.hashType(f)
+		if ht == hash.None || f.Hashes().Contains(ht) {
+			return nil
+		}
+		store, err := kv.NewStore(ctx, "bisync", f, kv.StoreDisk, "--download-hash", "hash cache")
+		if err != nil {
+			fs.Errorf(f, "Computing hashes without a cache: %v", err)
+			return nil
+		}
+		return store
+	}
+	b.hashCache1 = open(b.fs1)
+	b.hashCache2 = open(b.fs2)
+}
+
+// closeHashCaches closes the caches opened by openHashCaches
+func (b *bisyncRun) closeHashCaches() {
+	for _, store := range []*kv.Store{b.hashCache1, b.hashCache2} {
+		if store == nil {
+			continue
+		}
+		if err := store.Close(); err != nil {
+			fs.Errorf(nil, "Failed to close hash cache: %v", err)
+		}
+	}
+}
+
+// hashRecord is a hash computed by --download-hash as saved in the
+// hash cache
+type hashRecord struct {
+	Fingerprint string // size and modification time when the hash was computed
+	Hash        string
+}
+
+// objectHash returns the hash of o on f, reading the object to compute
+// it if its backend doesn't support ht.
+//
+// The computed hashes are cached, like the hasher backend does, and
+// reused while the size and modification time of the object are
+// unchanged so it isn't downloaded again on every run.
+func (b *bisyncRun) objectHash(ctx context.Context, f fs.Fs, o fs.Object, ht hash.Type) (sum string, err error) {
+	if f.Hashes().Contains(ht) {
+		return o.Hash(ctx, ht)
+	}
+	if o.Size() < 0 {
+		// can't download files of unknown size reliably
+		return "", nil
+	}
+	store := b.hashCache1
+	if f == b.fs2 {
+		store = b.hashCache2
+	}
+	// Only cache if the modification time can tell changed files
+	// apart from ones with the same size
+	var key, fingerprint string
+	if store != nil && f.Precision() != fs.ModTimeNotSupported {
+		key = o.Remote() + "\x00" + ht.String()
+		fingerprint = fmt.Sprintf("%d,%v", o.Size(), o.ModTime(ctx).UTC())
+		var r hashRecord
+		data, err := store.Get(key)
+		if err == nil && data != nil && json.Unmarshal(data, &r) == nil && r.Fingerprint == fingerprint {
+			return r.Hash, nil
+		}
+	}
+	in, err := o.Open(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to open object to compute hash: %w", err)
+	}
+	defer fs.CheckClose(in, &err)
+	sums, err := hash.StreamTypes(in, hash.NewHashSet(ht))
+	if err != nil {
+		return "", fmt.Errorf("failed to compute hash: %w", err)
+	}
+	sum = sums[ht]
+	if key != "" {
+		data, _ := json.Marshal(hashRecord{Fingerprint: fingerprint, Hash: sum})
+		if err := store.Put(kv.Item{Key: key, Value: data}); err != nil {
+			fs.Debugf(o, "Failed to cache hash: %v", err)
+		}
+	}
+	return sum, nil
+}
+
+// changed reports how file has changed between the old and now
+// listings according to --compare, along with a description
+func (b *bisyncRun) changed(old, now *fileList, file string) (d delta, msg string) {
+	opt := b.opt
+	if opt.Compare.Modtime && old.getTime(file) != now.getTime(file) {
+		if old.beforeOther(now, file) {
+			return deltaNewer, "File is newer"
+		}
+		// Current version is older than prior sync.
+		return deltaOlder, "File is OLDER"
+	}
+	prior, fi := old.get(file), now.get(file)
+	if opt.Compare.Size && prior.size >= 0 && fi.size >= 0 && prior.size != fi.size {
+		return deltaSize, "File size changed"
+	}
+	if opt.Compare.Checksum && old.hash != hash.None && old.hash == now.hash &&
+		prior.hash != "" && fi.hash != "" && prior.hash != fi.hash {
+		return deltaHash, "File checksum changed"
+	}
+	return deltaZero, ""
+}
@@ -0,0 +1,73 @@
+package bisync
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/rclone/rclone/lib/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingObject counts how many times it is opened
+type countingObject struct {
+	*mockobject.ContentMockObject
+	modTime time.Time
+	opens   int
+}
+
+func (o *countingObject) ModTime(ctx context.Context) time.Time {
+	return o.modTime
+}
+
+func (o *countingObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	o.opens++
+	return o.ContentMockObject.Open(ctx, options...)
+}
+
+func TestObjectHashCache(t *testing.T) {
+	ctx := context.Background()
+	f := mockfs.NewFs(ctx, "mock", "root")
+	store, err := kv.NewStore(ctx, "bisync", f, kv.StoreMemory, "--download-hash", "hash cache")
+	require.NoError(t, err)
+	b := &bisyncRun{fs1: f, hashCache1: store}
+	t1 := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	newObject := func(content string) *countingObject {
+		return &countingObject{
+			ContentMockObject: mockobject.New("file").WithContent([]byte(content), mockobject.SeekModeNone),
+			modTime:           t1,
+		}
+	}
+
+	check := func(o *countingObject, wantSum string, wantOpens int) {
+		t.Helper()
+		sum, err := b.objectHash(ctx, f, o, hash.MD5)
+		require.NoError(t, err)
+		assert.Equal(t, wantSum, sum)
+		assert.Equal(t, wantOpens, o.opens)
+	}
+
+	// The hash is computed once then read from the cache
+	o := newObject("hello")
+	check(o, "5d41402abc4b2a76b9719d911017c592", 1)
+	check(o, "5d41402abc4b2a76b9719d911017c592", 1)
+
+	// It is computed again if the modification time changes
+	o.modTime = t1.Add(time.Second)
+	check(o, "5d41402abc4b2a76b9719d911017c592", 2)
+
+	// or the size changes
+	o = newObject("hello!")
+	o.modTime = t1.Add(time.Second)
+	check(o, "5a8dd3ad0756a93ded72b823b19dd877", 1)
+
+	// Without a cache it is computed every time
+	b.hashCache1 = nil
+	check(o, "5a8dd3ad0756a93ded72b823b19dd877", 2)
+}
@@ -0,0 +1,212 @@
+package bisync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rclone/rclone/cmd/bisync/bilib"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// conflictSuffixes returns the suffixes used to rename the Path1 and
+// Path2 versions of a conflict
+func (opt *Options) conflictSuffixes() (suffix1, suffix2 string) {
+	suffix := opt.ConflictSuffix
+	if suffix == "" {
+		suffix = DefaultConflictSuffix
+	}
+	if pos := strings.IndexByte(suffix, ','); pos >= 0 {
+		return suffix[:pos], suffix[pos+1:]
+	}
+	if opt.ConflictLoser == ConflictLoserNum {
+		// the numbers keep the versions apart
+		return suffix, suffix
+	}
+	return suffix + "1", suffix + "2"
+}
+
+// checkConflictSuffix validates --conflict-suffix
+func (opt *Options) checkConflictSuffix() error {
+	suffix1, suffix2 := opt.conflictSuffixes()
+	for _, suffix := range []string{suffix1, suffix2} {
+		if suffix == "" || strings.ContainsAny(suffix, ",/\\") {
+			return fmt.Errorf("invalid conflict suffix %q", opt.ConflictSuffix)
+		}
+	}
+	if suffix1 == suffix2 && opt.ConflictLoser != ConflictLoserNum {
+		return errors.New("conflict suffixes must be different unless --conflict-loser is num")
+	}
+	return nil
+}
+
+// sameFile returns true if the current versions of file on both paths
+// are known to be identical.
+//
+// If the paths keep different types of hash, --download-hash reads the
+// Path2 version to compute the Path1 type of hash for it.
+func (b *bisyncRun) sameFile(ctx context.Context, ds1, ds2 *deltaSet, file string) bool {
+	info1, info2 := ds1.info[file], ds2.info[file]
+	if info1.size != info2.size || ds1.hash == hash.None || info1.hash == "" {
+		return false
+	}
+	if ds1.hash == ds2.hash {
+		return info1.hash == info2.hash
+	}
+	if !b.opt.DownloadHash {
+		return false
+	}
+	o, err := b.fs2.NewObject(ctx, file)
+	if err != nil {
+		fs.Debugf(file, "Can't compare Path1 and Path2 versions: %v", err)
+		return false
+	}
+	sum, err := b.objectHash(ctx, b.fs2, o, ds1.hash)
+	if err != nil {
+		fs.Debugf(file, "Can't compare Path1 and Path2 versions: %v", err)
+		return false
+	}
+	return sum == info1.hash
+}
+
+// conflictWinner picks the version of file to keep according to
+// --conflict-resolve. It returns the number of the winning path and
+// why it won, or 0 if there is no winner.
+func (b *bisyncRun) conflictWinner(ctx context.Context, ds1, ds2 *deltaSet, file string) (winner int, reason string) {
+	info1, info2 := ds1.info[file], ds2.info[file]
+	switch b.opt.ConflictResolve {
+	case ConflictResolvePath1:
+		return 1, "preferred"
+	case ConflictResolvePath2:
+		return 2, "preferred"
+	case ConflictResolveNewer, ConflictResolveOlder:
+		dt := info1.time.Sub(info2.time)
+		if dt < 0 {
+			dt = -dt
+		}
+		if dt <= fs.GetModifyWindow(ctx, b.fs1, b.fs2) {
+			return 0, ""
+		}
+		newer := 1
+		if info1.time.Before(info2.time) {
+			newer = 2
+		}
+		if b.opt.ConflictResolve == ConflictResolveNewer {
+			return newer, "newer"
+		}
+		return 3 - newer, "older"
+	case ConflictResolveLarger, ConflictResolveSmaller:
+		if info1.size < 0 || info2.size < 0 || info1.size == info2.size {
+			return 0, ""
+		}
+		larger := 1
+		if info1.size < info2.size {
+			larger = 2
+		}
+		if b.opt.ConflictResolve == ConflictResolveLarger {
+			return larger, "larger"
+		}
+		return 3 - larger, "smaller"
+	}
+	return 0, ""
+}
+
+// conflictName returns the name to rename the Path<n> version of file
+// to, avoiding the name taken by the other version if set
+func (b *bisyncRun) conflictName(ctx context.Context, file string, n int, taken string) string {
+	suffix1, suffix2 := b.opt.conflictSuffixes()
+	suffix := suffix1
+	if n == 2 {
+		suffix = suffix2
+	}
+	if b.opt.ConflictLoser != ConflictLoserNum {
+		return file + ".." + suffix
+	}
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s..%s%d", file, suffix, i)
+		if name == taken {
+			continue
+		}
+		_, err1 := b.fs1.NewObject(ctx, name)
+		_, err2 := b.fs2.NewObject(ctx, name)
+		if err1 != nil && err2 != nil {
+			return name
+		}
+	}
+}
+
+// renameConflict renames the Path<n> version of file to name and
+// queues it for copying to the other path
+func (b *bisyncRun) renameConflict(ctxMove context.Context, n int, file, name string, queue bilib.Names) error {
+	f, other := b.fs1, b.fs2
+	if n == 2 {
+		f, other = b.fs2, b.fs1
+	}
+	tag := fmt.Sprintf("!Path%d", n)
+	b.indentf(tag, bilib.FsPath(f)+name, "Renaming Path%d copy", n)
+	if err := operations.MoveFile(ctxMove, f, f, name, file); err != nil {
+		if n == 1 {
+			b.critical = true
+		}
+		return fmt.Errorf("path%d rename failed for %s: %w", n, bilib.FsPath(f)+file, err)
+	}
+	b.indentf(tag, bilib.FsPath(other)+name, "Queue copy to Path%d", 3-n)
+	queue.Add(name)
+	return nil
+}
+
+// resolveConflict handles a file which is new or changed on both paths.
+//
+// If --conflict-resolve picks a winner it is copied over the other
+// path and the loser is dealt with according to --conflict-loser,
+// otherwise both versions are renamed and copied to the other path.
+func (b *bisyncRun) resolveConflict(ctx, ctxMove context.Context, ds1, ds2 *deltaSet, file string, copy1to2, copy2to1 bilib.Names) (err error) {
+	opt := b.opt
+	// Identical versions, e.g. copied by an interrupted run, are no
+	// conflict, but only settled silently if asked to
+	settle := opt.ConflictResolve != ConflictResolveNone || opt.Recover || opt.Compare.Checksum
+	if settle && b.sameFile(ctx, ds1, ds2, file) {
+		b.indent("Both", file, "Identical in both paths")
+		return nil
+	}
+	b.indent("!WARNING", file, "New or changed in both paths")
+
+	winner, reason := 0, ""
+	if opt.ConflictResolve != ConflictResolveNone {
+		winner, reason = b.conflictWinner(ctx, ds1, ds2, file)
+		if winner == 0 {
+			b.indentf("!WARNING", file, "Can't pick %s version", opt.ConflictResolve)
+		}
+	}
+	if winner == 0 {
+		name1 := b.conflictName(ctx, file, 1, "")
+		name2 := b.conflictName(ctx, file, 2, name1)
+		if err = b.renameConflict(ctxMove, 1, file, name1, copy1to2); err != nil {
+			return err
+		}
+		return b.renameConflict(ctxMove, 2, file, name2, copy2to1)
+	}
+
+	loser := 3 - winner
+	winnerFs, loserFs := b.fs1, b.fs2
+	toLoser, toWinner := copy1to2, copy2to1
+	if winner == 2 {
+		winnerFs, loserFs = b.fs2, b.fs1
+		toLoser, toWinner = copy2to1, copy1to2
+	}
+	b.indentf(fmt.Sprintf("!Path%d", winner), bilib.FsPath(winnerFs)+file, "Keeping %s Path%d copy", reason, winner)
+	if opt.ConflictLoser == ConflictLoserDelete {
+		b.indentf(fmt.Sprintf("!Path%d", loser), bilib.FsPath(loserFs)+file, "Replacing Path%d copy", loser)
+	} else {
+		name := b.conflictName(ctx, file, loser, "")
+		if err = b.renameConflict(ctxMove, loser, file, name, toWinner); err != nil {
+			return err
+		}
+	}
+	b.indentf(fmt.Sprintf("!Path%d", winner), bilib.FsPath(loserFs)+file, "Queue copy to Path%d", loser)
+	toLoser.Add(file)
+	return nil
+}
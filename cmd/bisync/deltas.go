@@ -4,13 +4,12 @@ package bisync
 
 import (
 	"context"
-	"fmt"
 	"path/filepath"
 	"sort"
 
 	"github.com/rclone/rclone/cmd/bisync/bilib"
 	"github.com/rclone/rclone/fs"
-	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/hash"
 )
 
 // delta
@@ -28,7 +27,7 @@ const (
 
 const (
 	deltaModified delta = deltaNewer | deltaOlder | deltaSize | deltaHash | deltaDeleted
-	deltaOther    delta = deltaNew | deltaNewer | deltaOlder
+	deltaOther    delta = deltaNew | deltaNewer | deltaOlder | deltaSize | deltaHash
 )
 
 func (d delta) is(cond delta) bool {
@@ -45,6 +44,10 @@ type deltaSet struct {
 	deleted    int    // number of deleted files (for "excess deletes" check)
 	foundSame  bool   // true if found at least one unchanged file
 	checkFiles bilib.Names
+	info       map[string]*fileInfo // current info of new and changed files
+	hash       hash.Type            // type of the hashes in info
+	old        *fileList            // prior listing, updated by checkpoints
+	oldListing string               // file the prior listing is kept in
 }
 
 func (ds *deltaSet) empty() bool {
@@ -119,6 +122,10 @@ func (b *bisyncRun) findDeltas(fctx context.Context, f fs.Fs, oldListing, newLis
 		oldCount:   len(old.list),
 		opt:        b.opt,
 		checkFiles: bilib.Names{},
+		info:       map[string]*fileInfo{},
+		hash:       now.hash,
+		old:        old,
+		oldListing: oldListing,
 	}
 
 	for _, file := range old.list {
@@ -127,21 +134,16 @@ func (b *bisyncRun) findDeltas(fctx context.Context, f fs.Fs, oldListing, newLis
 			b.indent(msg, file, "File was deleted")
 			ds.deleted++
 			d |= deltaDeleted
-		} else {
-			if old.getTime(file) != now.getTime(file) {
-				if old.beforeOther(now, file) {
-					b.indent(msg, file, "File is newer")
-					d |= deltaNewer
-				} else { // Current version is older than prior sync.
-					b.indent(msg, file, "File is OLDER")
-					d |= deltaOlder
-				}
-			}
-			// TODO Compare sizes and hashes
+		} else if changed, reason := b.changed(old, now, file); changed != deltaZero {
+			b.indent(msg, file, reason)
+			d |= changed
 		}
 
 		if d.is(deltaModified) {
 			ds.deltas[file] = d
+			if !d.is(deltaDeleted) {
+				ds.info[file] = now.get(file)
+			}
 		} else {
 			// Once we've found at least one unchanged file,
 			// we know that not everything has changed,
@@ -154,6 +156,7 @@ func (b *bisyncRun) findDeltas(fctx context.Context, f fs.Fs, oldListing, newLis
 		if !old.has(file) {
 			b.indent(msg, file, "File is new")
 			ds.deltas[file] = deltaNew
+			ds.info[file] = now.get(file)
 		}
 	}
 
@@ -198,23 +201,9 @@ func (b *bisyncRun) applyDeltas(ctx context.Context, ds1, ds2 *deltaSet) (change
 				copy1to2.Add(file)
 				handled.Add(file)
 			} else if d2.is(deltaOther) {
-				b.indent("!WARNING", file, "New or changed in both paths")
-				b.indent("!Path1", p1+"..path1", "Renaming Path1 copy")
-				if err = operations.MoveFile(ctxMove, b.fs1, b.fs1, file+"..path1", file); err != nil {
-					err = fmt.Errorf("path1 rename failed for %s: %w", p1, err)
-					b.critical = true
+				if err = b.resolveConflict(ctx, ctxMove, ds1, ds2, file, copy1to2, copy2to1); err != nil {
 					return
 				}
-				b.indent("!Path1", p2+"..path1", "Queue copy to Path2")
-				copy1to2.Add(file + "..path1")
-
-				b.indent("!Path2", p2+"..path2", "Renaming Path2 copy")
-				if err = operations.MoveFile(ctxMove, b.fs2, b.fs2, file+"..path2", file); err != nil {
-					err = fmt.Errorf("path2 rename failed for %s: %w", file, err)
-					return
-				}
-				b.indent("!Path2", p1+"..path2", "Queue copy to Path1")
-				copy2to1.Add(file + "..path2")
 				handled.Add(file)
 			}
 		} else {
@@ -258,6 +247,9 @@ func (b *bisyncRun) applyDeltas(ctx context.Context, ds1, ds2 *deltaSet) (change
 		if err != nil {
 			return
 		}
+		if err = b.checkpoint(ctx, ds1, ds2, copy2to1, ds2, "copy2to1"); err != nil {
+			return
+		}
 	}
 
 	if copy1to2.NotEmpty() {
@@ -267,6 +259,9 @@ func (b *bisyncRun) applyDeltas(ctx context.Context, ds1, ds2 *deltaSet) (change
 		if err != nil {
 			return
 		}
+		if err = b.checkpoint(ctx, ds1, ds2, copy1to2, ds1, "copy1to2"); err != nil {
+			return
+		}
 	}
 
 	if delete1.NotEmpty() {
@@ -276,6 +271,9 @@ func (b *bisyncRun) applyDeltas(ctx context.Context, ds1, ds2 *deltaSet) (change
 		if err != nil {
 			return
 		}
+		if err = b.checkpoint(ctx, ds1, ds2, delete1, nil, "delete1"); err != nil {
+			return
+		}
 	}
 
 	if delete2.NotEmpty() {
@@ -285,6 +283,9 @@ func (b *bisyncRun) applyDeltas(ctx context.Context, ds1, ds2 *deltaSet) (change
 		if err != nil {
 			return
 		}
+		if err = b.checkpoint(ctx, ds1, ds2, delete2, nil, "delete2"); err != nil {
+			return
+		}
 	}
 
 	return
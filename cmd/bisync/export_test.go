@@ -0,0 +1,11 @@
+package bisync
+
+// ErrInterrupted makes SetFailAfter stop runs as if interrupted
+var ErrInterrupted = errInterrupted
+
+// SetFailAfter makes bisync runs fail with err just after the queued
+// operations named, such as "copy1to2", before they are checkpointed.
+// An empty queue turns this off.
+func SetFailAfter(queue string, err error) {
+	failAfter, failErr = queue, err
+}
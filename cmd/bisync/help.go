@@ -11,6 +11,7 @@ func makeHelp(help string) string {
 		"{MAXDELETE}", strconv.Itoa(DefaultMaxDelete),
 		"{CHECKFILE}", DefaultCheckFilename,
 		"{WORKDIR}", DefaultWorkdir,
+		"{CONFLICTSUFFIX}", DefaultConflictSuffix,
 	)
 	return replacer.Replace(help)
 }
@@ -34,6 +35,22 @@ var rcHelp = makeHelp(`This takes the following parameters
 - filtersFile - read filtering patterns from a file
 - workdir - server directory for history files (default: {WORKDIR})
 - noCleanup - retain working files
+- conflictResolve - automatically resolve conflicts by keeping the version
+  which is |none|, |newer|, |older|, |larger|, |smaller|, |path1| or |path2|
+  (default: |none|)
+- conflictLoser - action to take on the loser of a resolved conflict:
+  |rename|, |num| or |delete| (default: |rename|)
+- conflictSuffix - suffix for renamed conflicts, or |suffix1,suffix2|
+  (default: |{CONFLICTSUFFIX}|)
+- resilient - allow future runs to retry after certain less-serious errors,
+  instead of requiring resync
+- recover - automatically recover from interruptions without requiring resync
+- maxLock - consider lock files older than this to be expired,
+  e.g. |"1h"| (default: never expire)
+- compare - comma separated list of attributes which define a changed file:
+  |size|, |modtime| and |checksum| (default: |size,modtime|)
+- downloadHash - compute hashes by downloading files when a path doesn't
+  support them
 
 See [bisync command help](https://rclone.org/commands/rclone_bisync/)
 and [full bisync description](https://rclone.org/bisync/)
@@ -14,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/rclone/rclone/cmd/bisync/bilib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/walk"
@@ -81,6 +82,8 @@ func (ls *fileList) put(file string, size int64, time time.Time, hash, id string
 	if fi != nil {
 		fi.size = size
 		fi.time = time
+		fi.hash = hash
+		fi.id = id
 	} else {
 		fi = &fileInfo{
 			size: size,
@@ -93,6 +96,19 @@ func (ls *fileList) put(file string, size int64, time time.Time, hash, id string
 	}
 }
 
+// remove removes files from the listing
+func (ls *fileList) remove(files bilib.Names) {
+	list := ls.list[:0]
+	for _, file := range ls.list {
+		if files.Has(file) {
+			delete(ls.info, file)
+		} else {
+			list = append(list, file)
+		}
+	}
+	ls.list = list
+}
+
 func (ls *fileList) getTime(file string) time.Time {
 	fi := ls.get(file)
 	if fi == nil {
@@ -119,21 +135,40 @@ func (ls *fileList) afterTime(file string, time time.Time) bool {
 }
 
 // save will save listing to a file.
+//
+// The listing is written to a temporary file which then replaces the
+// old listing, so an interrupted run never leaves a truncated one.
 func (ls *fileList) save(ctx context.Context, listing string) error {
-	file, err := os.Create(listing)
+	tmpListing := listing + ".tmp"
+	file, err := os.Create(tmpListing)
 	if err != nil {
 		return err
 	}
+	err = ls.write(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpListing, listing)
+	}
+	if err != nil {
+		_ = os.Remove(tmpListing)
+	}
+	return err
+}
 
+// write writes the listing header and lines to out
+func (ls *fileList) write(out io.Writer) error {
 	hashName := ""
 	if ls.hash != hash.None {
 		hashName = ls.hash.String()
 	}
 
-	_, err = fmt.Fprintf(file, "%s %s\n", ListingHeader, time.Now().In(TZ).Format(timeFormat))
+	_, err := fmt.Fprintf(out, "%s %s\n", ListingHeader, time.Now().In(TZ).Format(timeFormat))
 	if err != nil {
-		_ = file.Close()
-		_ = os.Remove(listing)
 		return err
 	}
 
@@ -153,15 +188,12 @@ func (ls *fileList) save(ctx context.Context, listing string) error {
 		}
 
 		flags := "-"
-		_, err = fmt.Fprintf(file, lineFormat, flags, fi.size, hash, id, time, remote)
+		_, err = fmt.Fprintf(out, lineFormat, flags, fi.size, hash, id, time, remote)
 		if err != nil {
-			_ = file.Close()
-			_ = os.Remove(listing)
 			return err
 		}
 	}
-
-	return file.Close()
+	return nil
 }
 
 // loadListing will load listing from a file.
@@ -254,9 +286,7 @@ func (b *bisyncRun) makeListing(ctx context.Context, f fs.Fs, listing string) (l
 	depth := ci.MaxDepth
 	hashType := hash.None
 	if !ci.IgnoreChecksum {
-		// Currently bisync just honors --ignore-checksum
-		// TODO add full support for checksums and related flags
-		hashType = f.Hashes().GetOne()
+		hashType = b.hashType(f)
 	}
 	ls = newFileList()
 	ls.hash = hashType
@@ -270,7 +300,7 @@ func (b *bisyncRun) makeListing(ctx context.Context, f fs.Fs, listing string) (l
 				hashErr error
 			)
 			if hashType != hash.None {
-				hashVal, hashErr = o.Hash(ctx, hashType)
+				hashVal, hashErr = b.objectHash(ctx, f, o, hashType)
 				if firstErr == nil {
 					firstErr = hashErr
 				}
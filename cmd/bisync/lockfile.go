@@ -0,0 +1,144 @@
+package bisync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/cmd/bisync/bilib"
+	"github.com/rclone/rclone/fs"
+)
+
+// lockFile prevents simultaneous runs on the same pair of paths.
+//
+// With --max-lock the lock expires if it isn't renewed in time, so the
+// lock of a bisync which died is reclaimed by a later run. A running
+// bisync renews its lock at half that interval.
+type lockFile struct {
+	path     string
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// lock takes the lock file at path, reclaiming it if it has expired
+func (b *bisyncRun) lock(path string) (*lockFile, error) {
+	maxLock := b.opt.MaxLock
+	if maxLock > 0 && maxLock < MinMaxLock {
+		fs.Logf(nil, "--max-lock %v is too short, using %v", maxLock, MinMaxLock)
+		maxLock = MinMaxLock
+	}
+
+	err := createLockFile(path)
+	if errors.Is(err, os.ErrExist) && maxLock > 0 {
+		err = reclaimLockFile(path, maxLock)
+	}
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("prior lock file found: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fs.Debugf(nil, "Lock file created: %s", path)
+
+	l := &lockFile{
+		path: path,
+		stop: make(chan struct{}),
+	}
+	if maxLock > 0 {
+		l.wg.Add(1)
+		go l.renew(maxLock / 2)
+	}
+	return l, nil
+}
+
+// createLockFile creates the lock file at path holding our PID. It
+// fails with os.ErrExist if the file is there already.
+func createLockFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, bilib.PermSecure)
+	if errors.Is(err, os.ErrExist) {
+		return os.ErrExist
+	}
+	if err == nil {
+		_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create lock file: %s: %w", path, err)
+	}
+	return nil
+}
+
+// reclaimLockFile replaces the lock file at path if it hasn't been
+// renewed for maxLock. It fails with os.ErrExist if the lock is still
+// in use.
+//
+// The lock is checked and replaced holding path+".reclaim", so two
+// runs can't both find it expired and one replace the lock the other
+// has just taken.
+func reclaimLockFile(path string, maxLock time.Duration) error {
+	guard := path + ".reclaim"
+	if err := createLockFile(guard); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			fs.Logf(nil, "Lock file %s is being reclaimed by another run - remove %s if there is none", path, guard)
+		}
+		return err
+	}
+	defer func() {
+		if err := os.Remove(guard); err != nil {
+			fs.Errorf(nil, "cannot remove %s: %v", guard, err)
+		}
+	}()
+	info, err := os.Stat(path)
+	if err == nil {
+		age := time.Since(info.ModTime())
+		if age <= maxLock {
+			return os.ErrExist
+		}
+		fs.Logf(nil, "Reclaiming expired lock file %s which was last renewed %v ago", path, age.Truncate(time.Second))
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove expired lock file: %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	// the lock may still be taken by a run which isn't reclaiming it
+	return createLockFile(path)
+}
+
+// renew touches the lock file every interval until unlocked
+func (l *lockFile) renew(interval time.Duration) {
+	defer l.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if err := os.Chtimes(l.path, now, now); err != nil {
+				fs.Errorf(nil, "cannot renew lock file %s: %v", l.path, err)
+			}
+		}
+	}
+}
+
+// unlock stops renewing the lock file and removes it
+func (l *lockFile) unlock() error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	l.wg.Wait()
+	err := os.Remove(l.path)
+	if err == nil {
+		fs.Debugf(nil, "Lock file removed: %s", l.path)
+	}
+	return err
+}
@@ -9,15 +9,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	gosync "sync"
 
 	"github.com/rclone/rclone/cmd/bisync/bilib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/sync"
 	"github.com/rclone/rclone/lib/atexit"
+	"github.com/rclone/rclone/lib/kv"
 )
 
 // ErrBisyncAborted signals that bisync is aborted and forces exit code 2
@@ -25,13 +26,19 @@ var ErrBisyncAborted = errors.New("bisync aborted")
 
 // bisyncRun keeps bisync runtime state
 type bisyncRun struct {
-	fs1      fs.Fs
-	fs2      fs.Fs
-	abort    bool
-	critical bool
-	basePath string
-	workDir  string
-	opt      *Options
+	fs1       fs.Fs
+	fs2       fs.Fs
+	abort     bool
+	critical  bool
+	retryable bool // critical error may be retried with --resilient
+	basePath  string
+	workDir   string
+	opt       *Options
+	hashType1 hash.Type // type of hash kept in the Path1 listings
+	hashType2 hash.Type // type of hash kept in the Path2 listings
+
+	hashCache1 *kv.Store // hashes computed by --download-hash for Path1 or nil
+	hashCache2 *kv.Store // hashes computed by --download-hash for Path2 or nil
 }
 
 // Bisync handles lock file, performs bisync run and checks exit status
@@ -49,8 +56,16 @@ func Bisync(ctx context.Context, fs1, fs2 fs.Fs, optArg *Options) (err error) {
 	if opt.Workdir == "" {
 		opt.Workdir = DefaultWorkdir
 	}
+	if err = opt.checkConflictSuffix(); err != nil {
+		return err
+	}
+
+	if err = opt.setCompare(ctx); err != nil {
+		return err
+	}
+	b.setHashTypes()
 
-	if !opt.DryRun && !opt.Force {
+	if !opt.DryRun && !opt.Force && opt.Compare.Modtime {
 		if fs1.Precision() == fs.ModTimeNotSupported {
 			return errors.New("modification time support is missing on path1")
 		}
@@ -72,18 +87,11 @@ func Bisync(ctx context.Context, fs1, fs2 fs.Fs, optArg *Options) (err error) {
 	listing2 := b.basePath + ".path2.lst"
 
 	// Handle lock file
-	lockFile := ""
+	var lock *lockFile
 	if !opt.DryRun {
-		lockFile = b.basePath + ".lck"
-		if bilib.FileExists(lockFile) {
-			return fmt.Errorf("prior lock file found: %s", lockFile)
-		}
-
-		pidStr := []byte(strconv.Itoa(os.Getpid()))
-		if err = os.WriteFile(lockFile, pidStr, bilib.PermSecure); err != nil {
-			return fmt.Errorf("cannot create lock file: %s: %w", lockFile, err)
+		if lock, err = b.lock(b.basePath + ".lck"); err != nil {
+			return err
 		}
-		fs.Debugf(nil, "Lock file created: %s", lockFile)
 	}
 
 	// Handle SIGINT
@@ -95,13 +103,24 @@ func Bisync(ctx context.Context, fs1, fs2 fs.Fs, optArg *Options) (err error) {
 			_ = os.Rename(file, failFile)
 		}
 	}
+	interrupted := func() {
+		if opt.Recover {
+			// the listings are only ever replaced atomically so
+			// they still hold the last checkpoint
+			fs.Logf(nil, "Bisync interrupted. Will attempt to recover on the next run.")
+		} else {
+			fs.Logf(nil, "Bisync interrupted. Must run --resync to recover.")
+			markFailed(listing1)
+			markFailed(listing2)
+		}
+		if lock != nil {
+			_ = lock.unlock()
+		}
+	}
 	finalise := func() {
 		finaliseOnce.Do(func() {
 			if atexit.Signalled() {
-				fs.Logf(nil, "Bisync interrupted. Must run --resync to recover.")
-				markFailed(listing1)
-				markFailed(listing2)
-				_ = os.Remove(lockFile)
+				interrupted()
 			}
 		})
 	}
@@ -109,27 +128,36 @@ func Bisync(ctx context.Context, fs1, fs2 fs.Fs, optArg *Options) (err error) {
 	defer atexit.Unregister(fnHandle)
 
 	// run bisync
+	b.openHashCaches(ctx)
+	defer b.closeHashCaches()
 	err = b.runLocked(ctx, listing1, listing2)
+	if errors.Is(err, errInterrupted) {
+		finaliseOnce.Do(interrupted)
+		return err
+	}
 
-	if lockFile != "" {
-		errUnlock := os.Remove(lockFile)
-		if errUnlock == nil {
-			fs.Debugf(nil, "Lock file removed: %s", lockFile)
-		} else if err == nil {
-			err = errUnlock
-		} else {
-			fs.Errorf(nil, "cannot remove lockfile %s: %v", lockFile, errUnlock)
+	if lock != nil {
+		if errUnlock := lock.unlock(); errUnlock != nil {
+			if err == nil {
+				err = errUnlock
+			} else {
+				fs.Errorf(nil, "cannot remove lockfile %s: %v", lock.path, errUnlock)
+			}
 		}
 	}
 
 	if b.critical {
+		fs.Errorf(nil, "Bisync critical error: %v", err)
+		if b.retryable && opt.Resilient {
+			fs.Errorf(nil, "Bisync aborted. Error is retryable without --resync due to --resilient mode.")
+			return ErrBisyncAborted
+		}
 		if bilib.FileExists(listing1) {
 			_ = os.Rename(listing1, listing1+"-err")
 		}
 		if bilib.FileExists(listing2) {
 			_ = os.Rename(listing2, listing2+"-err")
 		}
-		fs.Errorf(nil, "Bisync critical error: %v", err)
 		fs.Errorf(nil, "Bisync aborted. Must run --resync to recover.")
 		return ErrBisyncAborted
 	}
@@ -191,6 +219,14 @@ func (b *bisyncRun) runLocked(octx context.Context, listing1, listing2 string) (
 		return errors.New("cannot find prior Path1 or Path2 listings, likely due to critical error on prior run")
 	}
 
+	// Rebuild the prior listings if the last run was interrupted
+	if opt.Recover && !opt.DryRun {
+		if err = b.recoverListings(fctx, listing1, listing2); err != nil {
+			b.critical = true
+			return err
+		}
+	}
+
 	// Check for Path1 deltas relative to the prior sync
 	fs.Infof(nil, "Path1 checking for diffs")
 	newListing1 := listing1 + "-new"
@@ -255,6 +291,8 @@ func (b *bisyncRun) runLocked(octx context.Context, listing1, listing2 string) (
 		changes1, changes2, err = b.applyDeltas(octx, ds1, ds2)
 		if err != nil {
 			b.critical = true
+			// the prior listings hold the last checkpoint
+			b.retryable = true
 			return err
 		}
 	}
@@ -283,6 +321,7 @@ func (b *bisyncRun) runLocked(octx context.Context, listing1, listing2 string) (
 	}
 	if err != nil {
 		b.critical = true
+		b.retryable = true
 		return err
 	}
 
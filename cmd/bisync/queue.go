@@ -17,6 +17,13 @@ func (b *bisyncRun) fastCopy(ctx context.Context, fsrc, fdst fs.Fs, files bilib.
 	}
 
 	ctxCopy, filterCopy := filter.AddConfig(b.opt.setDryRun(ctx))
+	if b.opt.Compare.Checksum || !b.opt.Compare.Modtime {
+		// the queued files have changed even if their size and
+		// modtime match the destination
+		var ci *fs.ConfigInfo
+		ctxCopy, ci = fs.AddConfig(ctxCopy)
+		ci.IgnoreTimes = true
+	}
 	for _, file := range files.ToList() {
 		if err := filterCopy.AddFile(file); err != nil {
 			return err
@@ -54,6 +54,18 @@ func rcBisync(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if opt.NoCleanup, err = in.GetBool("noCleanup"); rc.NotErrParamNotFound(err) {
 		return
 	}
+	if opt.Resilient, err = in.GetBool("resilient"); rc.NotErrParamNotFound(err) {
+		return
+	}
+	if opt.Recover, err = in.GetBool("recover"); rc.NotErrParamNotFound(err) {
+		return
+	}
+	if opt.MaxLock, err = in.GetDuration("maxLock"); rc.NotErrParamNotFound(err) {
+		return
+	}
+	if opt.DownloadHash, err = in.GetBool("downloadHash"); rc.NotErrParamNotFound(err) {
+		return
+	}
 
 	if opt.CheckFilename, err = in.GetString("checkFilename"); rc.NotErrParamNotFound(err) {
 		return
@@ -64,6 +76,35 @@ func rcBisync(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if opt.Workdir, err = in.GetString("workdir"); rc.NotErrParamNotFound(err) {
 		return
 	}
+	if opt.ConflictSuffix, err = in.GetString("conflictSuffix"); rc.NotErrParamNotFound(err) {
+		return
+	}
+
+	conflictResolve, err := in.GetString("conflictResolve")
+	if err == nil {
+		if err = opt.ConflictResolve.Set(conflictResolve); err != nil {
+			return nil, rc.NewErrParamInvalid(err)
+		}
+	} else if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	conflictLoser, err := in.GetString("conflictLoser")
+	if err == nil {
+		if err = opt.ConflictLoser.Set(conflictLoser); err != nil {
+			return nil, rc.NewErrParamInvalid(err)
+		}
+	} else if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+
+	compare, err := in.GetString("compare")
+	if err == nil {
+		if err = opt.Compare.Set(compare); err != nil {
+			return nil, rc.NewErrParamInvalid(err)
+		}
+	} else if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
 
 	checkSync, err := in.GetString("checkSync")
 	if rc.NotErrParamNotFound(err) {
@@ -0,0 +1,156 @@
+package bisync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/rclone/rclone/cmd/bisync/bilib"
+	"github.com/rclone/rclone/fs"
+)
+
+// errInterrupted stops a run as if it had been interrupted
+var errInterrupted = errors.New("interrupted")
+
+// failAfter and failErr are set by the tests to make runs stop with
+// failErr after the queue named failAfter, before it is checkpointed
+var (
+	failAfter string
+	failErr   error
+)
+
+// checkpoint records a completed batch of queued operations in the
+// prior listings, so an interrupted run only has the remaining work
+// left for the next one.
+//
+// files were copied from the path of src, or deleted if src is nil,
+// by the queue named.
+func (b *bisyncRun) checkpoint(ctx context.Context, ds1, ds2 *deltaSet, files bilib.Names, src *deltaSet, queue string) error {
+	if failAfter != "" && queue == failAfter {
+		return failErr
+	}
+	if !b.opt.Recover && !b.opt.Resilient {
+		return nil
+	}
+	for _, ds := range []*deltaSet{ds1, ds2} {
+		if src == nil {
+			ds.old.remove(files)
+		} else {
+			for file := range files {
+				fi := src.info[file]
+				if fi == nil {
+					// renamed conflicts are picked up as new files
+					continue
+				}
+				hashVal := ""
+				if ds.old.hash == src.hash {
+					hashVal = fi.hash
+				}
+				modTime := fi.time
+				if precision := ds.fs.Precision(); precision > time.Nanosecond && precision < fs.ModTimeNotSupported {
+					modTime = modTime.Truncate(precision)
+				}
+				ds.old.put(file, fi.size, modTime, hashVal, "")
+			}
+		}
+		if err := ds.old.save(ctx, ds.oldListing); err != nil {
+			return err
+		}
+	}
+	fs.Debugf(nil, "Checkpoint saved after %d files", len(files))
+	return nil
+}
+
+// isNewer returns true if the local file exists and is newer than other
+func isNewer(file, other string) bool {
+	info, err := os.Stat(file)
+	if err != nil {
+		return false
+	}
+	otherInfo, err := os.Stat(other)
+	if err != nil {
+		return false
+	}
+	return info.ModTime().After(otherInfo.ModTime())
+}
+
+// recoverListings rebuilds the prior listings after an interrupted run.
+//
+// An interrupted run leaves its .lst-new listings newer than the last
+// good listings it started from. Where both .lst-new listings agree on
+// a file the paths were in sync at the start of that run, so their
+// entries are a safe prior state for it, whatever the run did since.
+func (b *bisyncRun) recoverListings(ctx context.Context, listing1, listing2 string) error {
+	newListing1 := listing1 + "-new"
+	newListing2 := listing2 + "-new"
+	if !isNewer(newListing1, listing1) || !isNewer(newListing2, listing2) {
+		return nil
+	}
+	fs.Logf(nil, "Last run was interrupted. Recovering prior listings")
+
+	old1, err := b.loadListing(listing1)
+	if err != nil {
+		return err
+	}
+	old2, err := b.loadListing(listing2)
+	if err != nil {
+		return err
+	}
+	now1, err := b.loadListing(newListing1)
+	if err != nil {
+		return err
+	}
+	now2, err := b.loadListing(newListing2)
+	if err != nil {
+		return err
+	}
+
+	window := fs.GetModifyWindow(ctx, b.fs1, b.fs2)
+	same := func(fi1, fi2 *fileInfo) bool {
+		dt := fi1.time.Sub(fi2.time)
+		if dt < 0 {
+			dt = -dt
+		}
+		return fi1.size == fi2.size && dt <= window
+	}
+
+	recovered := 0
+	adopt := func(old, now *fileList, file string) {
+		fi, prior := now.get(file), old.get(file)
+		hashVal := ""
+		if old.hash == now.hash {
+			hashVal = fi.hash
+		}
+		if prior != nil && prior.size == fi.size && prior.time.Equal(fi.time) && prior.hash == hashVal {
+			return
+		}
+		old.put(file, fi.size, fi.time, hashVal, fi.id)
+		recovered++
+	}
+	for _, file := range now1.list {
+		if now2.has(file) && same(now1.get(file), now2.get(file)) {
+			adopt(old1, now1, file)
+			adopt(old2, now2, file)
+		}
+	}
+	gone := bilib.Names{}
+	for _, list := range [][]string{old1.list, old2.list} {
+		for _, file := range list {
+			if !now1.has(file) && !now2.has(file) {
+				gone.Add(file)
+			}
+		}
+	}
+	old1.remove(gone)
+	old2.remove(gone)
+
+	if err = old1.save(ctx, listing1); err != nil {
+		return err
+	}
+	if err = old2.save(ctx, listing2); err != nil {
+		return err
+	}
+	fs.Infof(nil, "Recovered %d listing entries, dropped %d deleted on both paths", recovered, len(gone))
+	return nil
+}
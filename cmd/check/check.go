@@ -153,7 +153,10 @@ be useful for remotes that don't support hashes or if you really want
 to check all the data.
 
 If you supply the |--checkfile HASH| flag with a valid hash name,
-the |source:path| must point to a text file in the SUM format.
+the |source:path| must point to a text file in the SUM format. If you
+supply the |--download| flag as well, the files will be downloaded and
+hashed locally so any hash may be used. The |blake3|, |xxh3| and
+|xxh128| hashes are much quicker to calculate than |md5| or |sha1|.
 `, "|", "`") + FlagsHelp,
 	RunE: func(command *cobra.Command, args []string) error {
 		cmd.CheckArgs(2, 2, command, args)
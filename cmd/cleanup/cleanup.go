@@ -19,6 +19,10 @@ var commandDefinition = &cobra.Command{
 	Long: `
 Clean up the remote if possible.  Empty the trash or delete old file
 versions. Not supported by all remotes.
+
+This also aborts any uploads to the remote which were interrupted
+while using ` + "`--multi-thread-resume`" + ` and haven't been resumed,
+deleting the parts uploaded so far.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
@@ -97,6 +97,8 @@ func translateError(err error) error {
 		return fuse.Errno(syscall.EBADF)
 	case vfs.EROFS:
 		return fuse.Errno(syscall.EROFS)
+	case vfs.ENOSPC:
+		return fuse.Errno(syscall.ENOSPC)
 	case vfs.ENOSYS, fs.ErrorNotImplemented:
 		return syscall.ENOSYS
 	case vfs.EINVAL:
@@ -124,6 +124,8 @@ func translateError(err error) syscall.Errno {
 		return syscall.EBADF
 	case vfs.EROFS:
 		return syscall.EROFS
+	case vfs.ENOSPC:
+		return syscall.ENOSPC
 	case vfs.ENOSYS, fs.ErrorNotImplemented:
 		return syscall.ENOSYS
 	case vfs.EINVAL:
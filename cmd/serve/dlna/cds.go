@@ -32,9 +32,40 @@ func (cds *contentDirectoryService) updateIDString() string {
 
 var mediaMimeTypeRegexp = regexp.MustCompile("^(video|audio|image)/")
 
+// mediaClass returns the last part of the UPnP class of media with
+// mimeType, "audioItem" say, or "" if it isn't media.
+func mediaClass(mimeType string) string {
+	mediaType := mediaMimeTypeRegexp.FindStringSubmatch(mimeType)
+	if mediaType == nil {
+		return ""
+	}
+	return mediaType[1] + "Item"
+}
+
+// Read the mime type from the fs.Object if possible,
+// otherwise fall back to working out what it is from the file path.
+func nodeMimeType(node vfs.Node) string {
+	if o, ok := node.DirEntry().(fs.Object); ok {
+		return fs.MimeType(context.TODO(), o)
+	}
+	return fs.MimeTypeFromName(node.Name())
+}
+
+// subtitleMimeTypes are the MIME types of subtitles by extension. The
+// others are served as text/srt.
+var subtitleMimeTypes = map[string]string{
+	".vtt": "text/vtt",
+	".ass": "text/x-ssa",
+	".ssa": "text/x-ssa",
+}
+
 // Turns the given entry and DMS host into a UPnP object. A nil object is
 // returned if the entry is not of interest.
-func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fileInfo vfs.Node, resources vfs.Nodes, host string) (ret interface{}, err error) {
+//
+// info is what the index has for the entry, which is used if it is
+// still current. Otherwise the entry is shown with what the listing
+// has and its directory is queued to be indexed.
+func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fileInfo vfs.Node, info *mediaInfo, resources vfs.Nodes, host string) (ret interface{}, err error) {
 	obj := upnpav.Object{
 		ID:         cdsObject.ID(),
 		Restricted: 1,
@@ -55,55 +86,97 @@ func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fi
 		return
 	}
 
-	// Read the mime type from the fs.Object if possible,
-	// otherwise fall back to working out what it is from the file path.
-	var mimeType string
-	if o, ok := fileInfo.DirEntry().(fs.Object); ok {
-		mimeType = fs.MimeType(context.TODO(), o)
-	} else {
-		mimeType = fs.MimeTypeFromName(fileInfo.Name())
+	if !info.current(fileInfo) {
+		mimeType := nodeMimeType(fileInfo)
+		if mediaClass(mimeType) == "" {
+			return
+		}
+		info = &mediaInfo{
+			Size:     fileInfo.Size(),
+			ModTime:  fileInfo.ModTime(),
+			MimeType: mimeType,
+		}
+		for _, resource := range resources {
+			info.Subtitles = append(info.Subtitles, path.Join("/", resource.Path()))
+		}
+		cds.indexLater(path.Dir(cdsObject.Path))
 	}
 
-	mediaType := mediaMimeTypeRegexp.FindStringSubmatch(mimeType)
-	if mediaType == nil {
-		return
-	}
+	return cds.mediaItem(cdsObject, info, host), nil
+}
 
-	obj.Class = "object.item." + mediaType[1] + "Item"
-	obj.Title = fileInfo.Name()
-	obj.Date = upnpav.Timestamp{Time: fileInfo.ModTime()}
+// mediaItem makes the UPnP item for the media file o from its info.
+func (cds *contentDirectoryService) mediaItem(o object, info *mediaInfo, host string) upnpav.Item {
+	makeURL := func(prefix, p string) string {
+		return (&url.URL{
+			Scheme: "http",
+			Host:   host,
+			Path:   path.Join(prefix, p),
+		}).String()
+	}
 
 	item := upnpav.Item{
-		Object: obj,
-		Res:    make([]upnpav.Resource, 0, 1),
+		Object: upnpav.Object{
+			ID:         o.ID(),
+			Restricted: 1,
+			ParentID:   o.ParentID(),
+			Class:      info.class(),
+			Title:      info.title(o.Path),
+			Date:       upnpav.Timestamp{Time: info.ModTime},
+			Artist:     info.Artist,
+			Album:      info.Album,
+			Genre:      info.Genre,
+		},
+		Res: make([]upnpav.Resource, 0, 1+len(info.Subtitles)),
 	}
 
-	item.Res = append(item.Res, upnpav.Resource{
-		URL: (&url.URL{
-			Scheme: "http",
-			Host:   host,
-			Path:   path.Join(resPath, cdsObject.Path),
-		}).String(),
-		ProtocolInfo: fmt.Sprintf("http-get:*:%s:%s", mimeType, dlna.ContentFeatures{
+	res := upnpav.Resource{
+		URL: makeURL(resPath, o.Path),
+		ProtocolInfo: fmt.Sprintf("http-get:*:%s:%s", info.MimeType, dlna.ContentFeatures{
 			SupportRange: true,
 		}.String()),
-		Size: uint64(fileInfo.Size()),
-	})
+		Size: uint64(info.Size),
+	}
+	if info.Width > 0 && info.Height > 0 {
+		res.Resolution = fmt.Sprintf("%dx%d", info.Width, info.Height)
+	}
+	item.Res = append(item.Res, res)
 
-	for _, resource := range resources {
-		subtitleURL := (&url.URL{
-			Scheme: "http",
-			Host:   host,
-			Path:   path.Join(resPath, resource.Path()),
-		}).String()
+	for _, subtitle := range info.Subtitles {
+		mimeType, found := subtitleMimeTypes[strings.ToLower(path.Ext(subtitle))]
+		if !found {
+			mimeType = "text/srt"
+		}
 		item.Res = append(item.Res, upnpav.Resource{
-			URL:          subtitleURL,
-			ProtocolInfo: fmt.Sprintf("http-get:*:%s:*", "text/srt"),
+			URL:          makeURL(resPath, subtitle),
+			ProtocolInfo: fmt.Sprintf("http-get:*:%s:*", mimeType),
 		})
 	}
 
-	ret = item
-	return
+	if info.Thumbnail {
+		thumbURL := makeURL(thumbPath, o.Path)
+		item.AlbumArtURI = thumbURL
+		item.Res = append(item.Res, upnpav.Resource{
+			URL:          thumbURL,
+			ProtocolInfo: "http-get:*:image/jpeg:DLNA.ORG_PN=JPEG_TN",
+		})
+	}
+
+	return item
+}
+
+// searchProps returns the properties of the indexed media file at p
+// which searches are matched against.
+func searchProps(p string, info *mediaInfo) map[string]string {
+	return map[string]string{
+		"upnp:class":  info.class(),
+		"dc:title":    info.title(p),
+		"dc:creator":  info.Artist,
+		"upnp:artist": info.Artist,
+		"upnp:album":  info.Album,
+		"upnp:genre":  info.Genre,
+		"dc:date":     info.ModTime.Format("2006-01-02"),
+	}
 }
 
 // Returns all the upnpav objects in a directory.
@@ -126,11 +199,12 @@ func (cds *contentDirectoryService) readContainer(o object, host string) (ret []
 	}
 
 	dirEntries, mediaResources := mediaWithResources(dirEntries)
+	infos := cds.index.getDir(o.Path)
 	for _, de := range dirEntries {
 		child := object{
 			path.Join(o.Path, de.Name()),
 		}
-		obj, err := cds.cdsObjectToUpnpavObject(child, de, mediaResources[de], host)
+		obj, err := cds.cdsObjectToUpnpavObject(child, de, infos[de.Name()], mediaResources[de], host)
 		if err != nil {
 			fs.Errorf(cds, "error with %s: %s", child.FilePath(), err)
 			continue
@@ -156,13 +230,10 @@ func mediaWithResources(nodes vfs.Nodes) (vfs.Nodes, map[vfs.Node]vfs.Nodes) {
 	// First, separate out the subtitles and media into maps, keyed by their lowercase base names.
 	mediaByName, subtitlesByName := make(map[string]vfs.Nodes), make(map[string]vfs.Node)
 	for _, node := range nodes {
-		baseName, ext := splitExt(strings.ToLower(node.Name()))
-		switch ext {
-		case ".srt", ".ass", ".ssa", ".sub", ".idx", ".sup", ".jss", ".txt", ".usf", ".cue", ".vtt", ".css":
-			// .idx should be with .sub, .css should be with vtt otherwise they should be culled,
-			// and their mimeTypes are not consistent, but anyway these negatives don't throw errors.
+		baseName, _ := splitExt(strings.ToLower(node.Name()))
+		if isSubtitle(node.Name()) {
 			subtitlesByName[baseName] = node
-		default:
+		} else {
 			mediaByName[baseName] = append(mediaByName[baseName], node)
 			media = append(media, node)
 		}
@@ -194,6 +265,19 @@ func mediaWithResources(nodes vfs.Nodes) (vfs.Nodes, map[vfs.Node]vfs.Nodes) {
 	return media, mediaResources
 }
 
+// Returns whether the file called name is a subtitle or another
+// resource which goes with a media file.
+func isSubtitle(name string) bool {
+	_, ext := splitExt(strings.ToLower(name))
+	switch ext {
+	case ".srt", ".ass", ".ssa", ".sub", ".idx", ".sup", ".jss", ".txt", ".usf", ".cue", ".vtt", ".css":
+		// .idx should be with .sub, .css should be with vtt otherwise they should be culled,
+		// and their mimeTypes are not consistent, but anyway these negatives don't throw errors.
+		return true
+	}
+	return false
+}
+
 type browse struct {
 	ObjectID       string
 	BrowseFlag     string
@@ -202,6 +286,55 @@ type browse struct {
 	RequestedCount int
 }
 
+type search struct {
+	ContainerID    string
+	SearchCriteria string
+	Filter         string
+	StartingIndex  int
+	RequestedCount int
+}
+
+// searchContainer returns the indexed media files in or below the
+// container o which match the search criteria.
+func (cds *contentDirectoryService) searchContainer(o object, criteria string, host string) (ret []interface{}, err error) {
+	match, err := parseSearch(criteria)
+	if err != nil {
+		return nil, upnp.Errorf(upnpav.InvalidSearchCriteriaErrorCode, err.Error())
+	}
+	err = cds.index.walk(o.Path, func(p string, info *mediaInfo) {
+		if match(searchProps(p, info)) {
+			ret = append(ret, cds.mediaItem(object{p}, info, host))
+		}
+	})
+	return ret, err
+}
+
+// pagedResult returns the page of objs asked for as the result of a
+// Browse or a Search.
+func (cds *contentDirectoryService) pagedResult(objs []interface{}, startingIndex, requestedCount int) (map[string]string, error) {
+	totalMatches := len(objs)
+	objs = objs[func() (low int) {
+		low = startingIndex
+		if low > len(objs) {
+			low = len(objs)
+		}
+		return
+	}():]
+	if requestedCount != 0 && requestedCount < len(objs) {
+		objs = objs[:requestedCount]
+	}
+	result, err := xml.Marshal(objs)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"TotalMatches":   fmt.Sprint(totalMatches),
+		"NumberReturned": fmt.Sprint(len(objs)),
+		"Result":         didlLite(string(result)),
+		"UpdateID":       cds.updateIDString(),
+	}, nil
+}
+
 // ContentDirectory object from ObjectID.
 func (cds *contentDirectoryService) objectFromID(id string) (o object, err error) {
 	o.Path, err = url.QueryUnescape(id)
@@ -246,34 +379,14 @@ func (cds *contentDirectoryService) Handle(action string, argsXML []byte, r *htt
 			if err != nil {
 				return nil, upnp.Errorf(upnpav.NoSuchObjectErrorCode, err.Error())
 			}
-			totalMatches := len(objs)
-			objs = objs[func() (low int) {
-				low = browse.StartingIndex
-				if low > len(objs) {
-					low = len(objs)
-				}
-				return
-			}():]
-			if browse.RequestedCount != 0 && browse.RequestedCount < len(objs) {
-				objs = objs[:browse.RequestedCount]
-			}
-			result, err := xml.Marshal(objs)
-			if err != nil {
-				return nil, err
-			}
-			return map[string]string{
-				"TotalMatches":   fmt.Sprint(totalMatches),
-				"NumberReturned": fmt.Sprint(len(objs)),
-				"Result":         didlLite(string(result)),
-				"UpdateID":       cds.updateIDString(),
-			}, nil
+			return cds.pagedResult(objs, browse.StartingIndex, browse.RequestedCount)
 		case "BrowseMetadata":
 			node, err := cds.vfs.Stat(obj.Path)
 			if err != nil {
 				return nil, err
 			}
-			// TODO: External subtitles won't appear in the metadata here, but probably should.
-			upnpObject, err := cds.cdsObjectToUpnpavObject(obj, node, vfs.Nodes{}, host)
+			// External subtitles only appear in the metadata once the file is indexed
+			upnpObject, err := cds.cdsObjectToUpnpavObject(obj, node, cds.index.get(obj.Path), vfs.Nodes{}, host)
 			if err != nil {
 				return nil, err
 			}
@@ -289,8 +402,22 @@ func (cds *contentDirectoryService) Handle(action string, argsXML []byte, r *htt
 		}
 	case "GetSearchCapabilities":
 		return map[string]string{
-			"SearchCaps": "",
+			"SearchCaps": strings.Join(searchProperties, ","),
 		}, nil
+	case "Search":
+		var search search
+		if err := xml.Unmarshal(argsXML, &search); err != nil {
+			return nil, err
+		}
+		obj, err := cds.objectFromID(search.ContainerID)
+		if err != nil {
+			return nil, upnp.Errorf(upnpav.NoSuchObjectErrorCode, err.Error())
+		}
+		objs, err := cds.searchContainer(obj, search.SearchCriteria, host)
+		if err != nil {
+			return nil, err
+		}
+		return cds.pagedResult(objs, search.StartingIndex, search.RequestedCount)
 	// Samsung Extensions
 	case "X_GetFeatureList":
 		return map[string]string{
@@ -3,14 +3,17 @@ package dlna
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	dms_dlna "github.com/anacrolix/dms/dlna"
@@ -52,7 +55,7 @@ files that they are not able to play back correctly.
 		f := cmd.NewFsSrc(args)
 
 		cmd.Run(false, false, command, func() error {
-			s, err := newServer(f, &dlnaflags.Opt)
+			s, err := newServer(context.Background(), f, &dlnaflags.Opt)
 			if err != nil {
 				return err
 			}
@@ -69,6 +72,7 @@ const (
 	serverField       = "Linux/3.4 DLNADOC/1.50 UPnP/1.0 DMS/1.0"
 	rootDescPath      = "/rootDesc.xml"
 	resPath           = "/r/"
+	thumbPath         = "/t/"
 	serviceControlURL = "/ctl"
 )
 
@@ -94,9 +98,17 @@ type server struct {
 
 	f   fs.Fs
 	vfs *vfs.VFS
+
+	// The media index and what is waiting to be added to it
+	index        *mediaIndex
+	noIndexScan  bool
+	indexQueue   chan string
+	indexMu      sync.Mutex
+	indexPending map[string]bool // directories in indexQueue
+	stopIndexer  context.CancelFunc
 }
 
-func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
+func newServer(ctx context.Context, f fs.Fs, opt *dlnaflags.Options) (*server, error) {
 	friendlyName := opt.FriendlyName
 	if friendlyName == "" {
 		friendlyName = makeDefaultFriendlyName()
@@ -124,10 +136,24 @@ func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
 		Interfaces:       interfaces,
 
 		httpListenAddr: opt.ListenAddr,
+		waitChan:       make(chan struct{}),
 
 		f:   f,
 		vfs: vfs.New(f, &vfsflags.Opt),
+
+		noIndexScan:  opt.NoIndexScan,
+		indexQueue:   make(chan string, indexQueueLength),
+		indexPending: map[string]bool{},
+	}
+
+	var err error
+	s.index, err = newMediaIndex(ctx, f, opt.IndexStore)
+	if err != nil {
+		return nil, err
 	}
+	var indexCtx context.Context
+	indexCtx, s.stopIndexer = context.WithCancel(ctx)
+	go s.runIndexer(indexCtx)
 
 	s.services = map[string]UPnPService{
 		"ContentDirectory": &contentDirectoryService{
@@ -145,6 +171,8 @@ func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
 	r := http.NewServeMux()
 	r.Handle(resPath, http.StripPrefix(resPath,
 		http.HandlerFunc(s.resourceHandler)))
+	r.Handle(thumbPath, http.StripPrefix(thumbPath,
+		http.HandlerFunc(s.thumbnailHandler)))
 	if opt.LogTrace {
 		r.Handle(rootDescPath, traceLogging(http.HandlerFunc(s.rootDescHandler)))
 		r.Handle(serviceControlURL, traceLogging(http.HandlerFunc(s.serviceControlHandler)))
@@ -274,6 +302,26 @@ func (s *server) resourceHandler(w http.ResponseWriter, r *http.Request) {
 	http.ServeContent(w, r, remotePath, node.ModTime(), in)
 }
 
+// Serves the thumbnails of media files from the index.
+func (s *server) thumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	thumb := s.index.thumbnail(path.Join("/", r.URL.Path))
+	if thumb == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Content-Length", strconv.Itoa(len(thumb)))
+	if r.Header.Get("getContentFeatures.dlna.org") != "" {
+		w.Header().Set("contentFeatures.dlna.org", "DLNA.ORG_PN=JPEG_TN")
+	}
+	w.Header().Set("transferMode.dlna.org", "Interactive")
+	if _, err := w.Write(thumb); err != nil {
+		// Network error
+		fs.Debugf(s, "Error writing thumbnail: %v", err)
+	}
+}
+
 // Serve runs the server - returns the error only if
 // the listener was not started; does not block, so
 // use s.Wait() to block on the listener indefinitely.
@@ -314,6 +362,10 @@ func (s *server) Wait() {
 }
 
 func (s *server) Close() {
+	s.stopIndexer()
+	if err := s.index.close(); err != nil {
+		fs.Errorf(s.f, "Error closing media index: %v", err)
+	}
 	err := s.HTTPConn.Close()
 	if err != nil {
 		fs.Errorf(s.f, "Error closing HTTP server: %v", err)
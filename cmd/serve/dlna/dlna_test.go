@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/anacrolix/dms/soap"
 
@@ -19,6 +20,7 @@ import (
 	_ "github.com/rclone/rclone/backend/local"
 	"github.com/rclone/rclone/cmd/serve/dlna/dlnaflags"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -35,8 +37,9 @@ const (
 func startServer(t *testing.T, f fs.Fs) {
 	opt := dlnaflags.DefaultOpt
 	opt.ListenAddr = testBindAddress
+	opt.IndexStore = kv.StoreMemory
 	var err error
-	dlnaServer, err = newServer(f, &opt)
+	dlnaServer, err = newServer(context.Background(), f, &opt)
 	assert.NoError(t, err)
 	assert.NoError(t, dlnaServer.Serve())
 	baseURL = "http://" + dlnaServer.HTTPConn.Addr().String()
@@ -207,3 +210,98 @@ func TestContentDirectoryBrowseDirectChildren(t *testing.T) {
 	require.Contains(t, string(body), "/r/subdir/video.mp4")
 	require.Contains(t, string(body), "/r/subdir/video.srt")
 }
+
+// doSearch does a ContentDirectory#Search returning the status and body
+func doSearch(t *testing.T, containerID, criteria string) (int, string) {
+	req, err := http.NewRequest("POST", baseURL+serviceControlURL, strings.NewReader(`
+<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"
+            s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">
+    <s:Body>
+        <u:Search xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1">
+            <ContainerID>`+containerID+`</ContainerID>
+            <SearchCriteria>`+html.EscapeString(criteria)+`</SearchCriteria>
+            <Filter>*</Filter>
+            <StartingIndex>0</StartingIndex>
+            <RequestedCount>0</RequestedCount>
+            <SortCriteria></SortCriteria>
+        </u:Search>
+    </s:Body>
+</s:Envelope>`))
+	require.NoError(t, err)
+	req.Header.Set("SOAPACTION", `"urn:schemas-upnp-org:service:ContentDirectory:1#Search"`)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer fs.CheckClose(resp.Body, &err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+// Check that ContentDirectory#Search finds the indexed media.
+func TestContentDirectorySearch(t *testing.T) {
+	ctx := context.Background()
+	dlnaServer.indexDir(ctx, "/")
+	dlnaServer.indexDir(ctx, "/subdir")
+
+	status, body := doSearch(t, "0", `upnp:class derivedfrom "object.item.videoItem"`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<TotalMatches>2</TotalMatches>")
+	assert.Contains(t, body, "/r/video.mp4")
+	assert.Contains(t, body, "/r/subdir/video.mp4")
+	// with the subtitles found when indexing
+	assert.Contains(t, body, "/r/video.en.srt")
+	assert.Contains(t, body, "/r/subdir/video.srt")
+
+	// only below the container
+	status, body = doSearch(t, "%2Fsubdir", "*")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<TotalMatches>1</TotalMatches>")
+	assert.Contains(t, body, "/r/subdir/video.mp4")
+
+	status, body = doSearch(t, "0", `dc:title contains "JPEG" and upnp:class derivedfrom "object.item.imageItem"`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<TotalMatches>1</TotalMatches>")
+	assert.Contains(t, body, "/r/small_jpeg.jpg")
+
+	status, body = doSearch(t, "0", `dc:title contains "potato"`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<TotalMatches>0</TotalMatches>")
+
+	status, body = doSearch(t, "0", `dc:title contains potato`)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Contains(t, body, "<errorCode>708</errorCode>")
+}
+
+// Check that thumbnails are served from the index and appear in the
+// items.
+func TestThumbnail(t *testing.T) {
+	info := &mediaInfo{
+		Size:     1,
+		ModTime:  time.Now(),
+		MimeType: "audio/mpeg",
+		Title:    "Song",
+		Artist:   "Singer",
+	}
+	require.NoError(t, dlnaServer.index.put("/thumb/song.mp3", info, []byte("THUMB")))
+
+	status, body := doSearch(t, "0", `upnp:artist = "singer"`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, html.EscapeString("<upnp:albumArtURI>"))
+	assert.Contains(t, body, "/t/thumb/song.mp3")
+	assert.Contains(t, body, "DLNA.ORG_PN=JPEG_TN")
+
+	resp, err := http.Get(baseURL + thumbPath + "thumb/song.mp3")
+	require.NoError(t, err)
+	defer fs.CheckClose(resp.Body, &err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "THUMB", string(data))
+
+	resp, err = http.Get(baseURL + thumbPath + "video.mp4")
+	require.NoError(t, err)
+	defer fs.CheckClose(resp.Body, &err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -22,6 +22,30 @@ default "rclone (hostname)".
 
 Use ` + "`--log-trace` in conjunction with `-vv`" + ` to enable additional debug
 logging of all UPNP traffic.
+
+### Media index
+
+The server keeps an index of the media files it has seen with their
+titles, artists, albums and genres (read from MP3, FLAC and MP4 tags),
+image sizes, subtitles and thumbnails. Thumbnails are made for images
+and for audio and video files with embedded cover art. The index is
+used to answer searches from clients without listing the remote, and
+to show the extra details when browsing.
+
+Subtitles are found next to the media files they are named after,
+` + "`video.en.srt`" + ` for ` + "`video.mp4`" + ` say, or in a "Subs" or "Subtitles"
+directory beside them. Subtitles in those directories which aren't
+named after anything go with the video if there is only one.
+
+On startup the whole remote is indexed in the background, then
+directories are indexed again when they are browsed and their
+contents have changed. Use ` + "`--no-index-scan`" + ` to stop the startup scan,
+which is worth doing for very large or slow remotes - only the
+directories which have been browsed will be found by searches then.
+
+Use ` + "`--index-store`" + ` to choose where the index is kept. With "disk"
+(the default) it is saved in rclone's cache directory so it survives
+restarts of the server, with "memory" it is rebuilt each time.
 `
 
 // Options is the type for DLNA serving options.
@@ -31,6 +55,8 @@ type Options struct {
 	LogTrace         bool
 	InterfaceNames   []string
 	AnnounceInterval time.Duration
+	IndexStore       string
+	NoIndexScan      bool
 }
 
 // DefaultOpt contains the defaults options for DLNA serving.
@@ -40,6 +66,8 @@ var DefaultOpt = Options{
 	LogTrace:         false,
 	InterfaceNames:   []string{},
 	AnnounceInterval: 12 * time.Minute,
+	IndexStore:       "disk",
+	NoIndexScan:      false,
 }
 
 // Opt contains the options for DLNA serving.
@@ -54,6 +82,8 @@ func addFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *Options) {
 	flags.BoolVarP(flagSet, &Opt.LogTrace, prefix+"log-trace", "", Opt.LogTrace, "Enable trace logging of SOAP traffic")
 	flags.StringArrayVarP(flagSet, &Opt.InterfaceNames, prefix+"interface", "", Opt.InterfaceNames, "The interface to use for SSDP (repeat as necessary)")
 	flags.DurationVarP(flagSet, &Opt.AnnounceInterval, prefix+"announce-interval", "", Opt.AnnounceInterval, "The interval between SSDP announcements")
+	flags.StringVarP(flagSet, &Opt.IndexStore, prefix+"index-store", "", Opt.IndexStore, "Where to keep the media index: memory or disk")
+	flags.BoolVarP(flagSet, &Opt.NoIndexScan, prefix+"no-index-scan", "", Opt.NoIndexScan, "Don't index the whole remote on startup")
 }
 
 // AddFlags add the command line flags for DLNA serving.
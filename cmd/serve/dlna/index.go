@@ -0,0 +1,418 @@
+package dlna
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+	"github.com/rclone/rclone/vfs"
+)
+
+// Prefixes of the keys in the index. They are followed by the
+// absolute path of the media file.
+const (
+	infoKeyPrefix  = "m"
+	thumbKeyPrefix = "t"
+)
+
+// mediaInfo is what is indexed about a media file
+type mediaInfo struct {
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	MimeType  string    `json:"mimeType"`
+	Title     string    `json:"title,omitempty"`
+	Artist    string    `json:"artist,omitempty"`
+	Album     string    `json:"album,omitempty"`
+	Genre     string    `json:"genre,omitempty"`
+	Width     int       `json:"width,omitempty"`
+	Height    int       `json:"height,omitempty"`
+	Thumbnail bool      `json:"thumbnail,omitempty"`
+	Subtitles []string  `json:"subtitles,omitempty"` // absolute paths of the subtitles
+}
+
+// current returns whether info is still correct for node
+func (info *mediaInfo) current(node vfs.Node) bool {
+	return info != nil && info.Size == node.Size() && info.ModTime.Equal(node.ModTime())
+}
+
+// class returns the UPnP class of the media file
+func (info *mediaInfo) class() string {
+	return "object.item." + mediaClass(info.MimeType)
+}
+
+// title returns the title of the media file at p, which is its name
+// if it hasn't got one in its tags
+func (info *mediaInfo) title(p string) string {
+	if info.Title != "" {
+		return info.Title
+	}
+	return path.Base(p)
+}
+
+// mediaIndex stores the mediaInfo and thumbnails of the media files.
+//
+// It is kept on disk so it survives a restart of the server, or in
+// memory if that isn't wanted or possible.
+type mediaIndex struct {
+	store *kv.Store
+}
+
+// newMediaIndex makes a mediaIndex for the remote f of the type given
+func newMediaIndex(ctx context.Context, f fs.Fs, storeType string) (*mediaIndex, error) {
+	store, err := kv.NewStore(ctx, "serve-dlna", f, storeType, "--index-store", "DLNA media index")
+	if err != nil {
+		return nil, err
+	}
+	return &mediaIndex{store: store}, nil
+}
+
+// close the index
+func (x *mediaIndex) close() error {
+	return x.store.Close()
+}
+
+// get the mediaInfo for the file at p or nil if it isn't indexed
+func (x *mediaIndex) get(p string) *mediaInfo {
+	value, err := x.store.Get(infoKeyPrefix + p)
+	if err != nil {
+		fs.Errorf(p, "Failed to read media index: %v", err)
+		return nil
+	}
+	return decodeInfo(p, value)
+}
+
+// getDir returns the mediaInfo of the files directly in dir by name
+func (x *mediaIndex) getDir(dir string) map[string]*mediaInfo {
+	op := kvScan{dir: dir, direct: true}
+	if err := x.store.Do(false, &op); err != nil {
+		fs.Errorf(dir, "Failed to read media index: %v", err)
+		return nil
+	}
+	infos := make(map[string]*mediaInfo, len(op.values))
+	for p, value := range op.values {
+		if info := decodeInfo(p, value); info != nil {
+			infos[path.Base(p)] = info
+		}
+	}
+	return infos
+}
+
+// put stores the mediaInfo for the file at p and its thumbnail if
+// not nil
+func (x *mediaIndex) put(p string, info *mediaInfo, thumb []byte) error {
+	info.Thumbnail = thumb != nil
+	value, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return x.store.Put(
+		kv.Item{Key: infoKeyPrefix + p, Value: value},
+		kv.Item{Key: thumbKeyPrefix + p, Value: thumb},
+	)
+}
+
+// thumbnail returns the thumbnail of the file at p or nil if it
+// hasn't got one
+func (x *mediaIndex) thumbnail(p string) []byte {
+	thumb, err := x.store.Get(thumbKeyPrefix + p)
+	if err != nil {
+		fs.Errorf(p, "Failed to read thumbnail: %v", err)
+		return nil
+	}
+	return thumb
+}
+
+// prune removes everything indexed in dir, or below it, which isn't
+// in the directory entries keep
+func (x *mediaIndex) prune(dir string, keep map[string]bool) error {
+	return x.store.Do(true, &kvPrune{dir: dir, keep: keep})
+}
+
+// walk calls fn for each file indexed in or below dir in path order
+func (x *mediaIndex) walk(dir string, fn func(p string, info *mediaInfo)) error {
+	op := kvScan{dir: dir}
+	if err := x.store.Do(false, &op); err != nil {
+		return err
+	}
+	for _, p := range op.paths {
+		if info := decodeInfo(p, op.values[p]); info != nil {
+			fn(p, info)
+		}
+	}
+	return nil
+}
+
+// indexQueueLength is how many directories can wait to be indexed
+const indexQueueLength = 64
+
+// subtitleDirNames are the names, in lower case, of the directories
+// which often have the subtitles of the media files next to them
+var subtitleDirNames = map[string]bool{
+	"subs":      true,
+	"subtitles": true,
+}
+
+// indexLater queues dir to be indexed in the background. If the queue
+// is full dir is dropped, to be queued again when it is next browsed.
+func (s *server) indexLater(dir string) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.indexPending[dir] {
+		return
+	}
+	select {
+	case s.indexQueue <- dir:
+		s.indexPending[dir] = true
+	default:
+	}
+}
+
+// nextQueued returns the next directory queued to be indexed, waiting
+// for one if wait is set. It returns "" if there isn't one.
+func (s *server) nextQueued(ctx context.Context, wait bool) string {
+	var dir string
+	if wait {
+		select {
+		case <-ctx.Done():
+			return ""
+		case dir = <-s.indexQueue:
+		}
+	} else {
+		select {
+		case dir = <-s.indexQueue:
+		default:
+			return ""
+		}
+	}
+	s.indexMu.Lock()
+	delete(s.indexPending, dir)
+	s.indexMu.Unlock()
+	return dir
+}
+
+// runIndexer indexes the whole remote, unless --no-index-scan is set,
+// then indexes the directories queued by browsing until ctx is done.
+func (s *server) runIndexer(ctx context.Context) {
+	if !s.noIndexScan {
+		start := time.Now()
+		dirs := []string{"/"}
+		for len(dirs) > 0 && ctx.Err() == nil {
+			// Do what is being browsed first
+			if dir := s.nextQueued(ctx, false); dir != "" {
+				s.indexDir(ctx, dir)
+				continue
+			}
+			dir := dirs[len(dirs)-1]
+			dirs = dirs[:len(dirs)-1]
+			dirs = append(dirs, s.indexDir(ctx, dir)...)
+		}
+		if ctx.Err() == nil {
+			fs.Infof(s, "Finished indexing media in %v", time.Since(start).Round(time.Millisecond))
+		}
+	}
+	for ctx.Err() == nil {
+		if dir := s.nextQueued(ctx, true); dir != "" {
+			s.indexDir(ctx, dir)
+		}
+	}
+}
+
+// indexDir brings the index of the media files in dir up to date,
+// returning the paths of its subdirectories.
+func (s *server) indexDir(ctx context.Context, dir string) (subdirs []string) {
+	node, err := s.vfs.Stat(dir)
+	if err != nil || !node.IsDir() {
+		// It will be pruned when its parent is indexed
+		fs.Debugf(dir, "Not indexing missing directory: %v", err)
+		return nil
+	}
+	entries, err := node.(*vfs.Dir).ReadDirAll()
+	if err != nil {
+		fs.Errorf(dir, "Failed to list directory for media index: %v", err)
+		return nil
+	}
+
+	// Subtitles may be in a subdirectory too
+	var subDirSubtitles vfs.Nodes
+	for _, entry := range entries {
+		if !entry.IsDir() || !subtitleDirNames[strings.ToLower(entry.Name())] {
+			continue
+		}
+		subEntries, err := entry.(*vfs.Dir).ReadDirAll()
+		if err != nil {
+			fs.Errorf(entry, "Failed to list subtitles for media index: %v", err)
+			continue
+		}
+		for _, subEntry := range subEntries {
+			if !subEntry.IsDir() && isSubtitle(subEntry.Name()) {
+				subDirSubtitles = append(subDirSubtitles, subEntry)
+			}
+		}
+	}
+	media, resources := mediaWithResources(append(append(vfs.Nodes{}, entries...), subDirSubtitles...))
+	if len(subDirSubtitles) > 0 {
+		// If there is only one video, the subtitles which aren't
+		// named after anything are probably for it.
+		var videos vfs.Nodes
+		for _, node := range media {
+			if !node.IsDir() && mediaClass(fs.MimeTypeFromName(node.Name())) == "videoItem" {
+				videos = append(videos, node)
+			}
+		}
+		if len(videos) == 1 {
+			matched := map[vfs.Node]bool{}
+			for _, nodes := range resources {
+				for _, node := range nodes {
+					matched[node] = true
+				}
+			}
+			for _, node := range subDirSubtitles {
+				if !matched[node] {
+					resources[videos[0]] = append(resources[videos[0]], node)
+				}
+			}
+		}
+	}
+
+	infos := s.index.getDir(dir)
+	keep := make(map[string]bool, len(media))
+	for _, node := range media {
+		if ctx.Err() != nil {
+			return nil
+		}
+		name := node.Name()
+		p := path.Join(dir, name)
+		if node.IsDir() {
+			keep[name] = true
+			subdirs = append(subdirs, p)
+			continue
+		}
+		if !node.Mode().IsRegular() {
+			continue
+		}
+		subtitles := make([]string, 0, len(resources[node]))
+		for _, resource := range resources[node] {
+			subtitles = append(subtitles, path.Join("/", resource.Path()))
+		}
+		sort.Strings(subtitles)
+		info := infos[name]
+		if info.current(node) && equalStrings(info.Subtitles, subtitles) {
+			keep[name] = true
+			continue
+		}
+		mimeType := nodeMimeType(node)
+		if mediaClass(mimeType) == "" {
+			continue
+		}
+		keep[name] = true
+		info, thumb := readMediaInfo(node, mimeType)
+		if len(subtitles) > 0 {
+			info.Subtitles = subtitles
+		}
+		if err := s.index.put(p, info, thumb); err != nil {
+			fs.Errorf(p, "Failed to update media index: %v", err)
+		}
+	}
+	if err := s.index.prune(dir, keep); err != nil {
+		fs.Errorf(dir, "Failed to prune media index: %v", err)
+	}
+	return subdirs
+}
+
+// equalStrings returns whether a and b have the same contents
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dirPrefix returns the prefix of the paths of everything in dir
+func dirPrefix(dir string) string {
+	return strings.TrimSuffix(dir, "/") + "/"
+}
+
+// decodeInfo decodes the mediaInfo for p from value
+func decodeInfo(p string, value []byte) *mediaInfo {
+	if value == nil {
+		return nil
+	}
+	info := new(mediaInfo)
+	if err := json.Unmarshal(value, info); err != nil {
+		fs.Errorf(p, "Ignoring corrupt media index entry: %v", err)
+		return nil
+	}
+	return info
+}
+
+// kvScan reads the mediaInfo of the files in or below dir, returning
+// their paths in order with the values
+type kvScan struct {
+	dir    string
+	direct bool // only read the files directly in dir
+	paths  []string
+	values map[string][]byte
+}
+
+func (op *kvScan) Do(ctx context.Context, b kv.Bucket) error {
+	op.paths = nil
+	op.values = map[string][]byte{}
+	prefix := infoKeyPrefix + dirPrefix(op.dir)
+	c := b.Cursor()
+	k, v := c.Seek([]byte(prefix))
+	for k != nil && strings.HasPrefix(string(k), prefix) {
+		rest := string(k[len(prefix):])
+		if slash := strings.IndexByte(rest, '/'); op.direct && slash >= 0 {
+			// skip the subdirectory - "0" sorts just after "/"
+			k, v = c.Seek([]byte(prefix + rest[:slash] + "0"))
+			continue
+		}
+		p := string(k[len(infoKeyPrefix):])
+		op.paths = append(op.paths, p)
+		op.values[p] = append([]byte(nil), v...)
+		k, v = c.Next()
+	}
+	return nil
+}
+
+// kvPrune removes the info and thumbnails of the files in or below
+// dir which aren't under one of the directory entries in keep
+type kvPrune struct {
+	dir  string
+	keep map[string]bool
+}
+
+func (op *kvPrune) Do(ctx context.Context, b kv.Bucket) error {
+	dir := dirPrefix(op.dir)
+	for _, keyPrefix := range []string{infoKeyPrefix, thumbKeyPrefix} {
+		prefix := keyPrefix + dir
+		var remove [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, _ = c.Next() {
+			name := string(k[len(prefix):])
+			if slash := strings.IndexByte(name, '/'); slash >= 0 {
+				name = name[:slash]
+			}
+			if !op.keep[name] {
+				remove = append(remove, append([]byte(nil), k...))
+			}
+		}
+		// delete after iterating so the cursor isn't disturbed
+		for _, k := range remove {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package dlna
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/lib/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaIndex(t *testing.T) {
+	x, err := newMediaIndex(context.Background(), nil, kv.StoreMemory)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, x.close())
+	}()
+
+	_, err = newMediaIndex(context.Background(), nil, "potato")
+	assert.ErrorContains(t, err, "unknown --index-store")
+
+	modTime := time.Date(2020, 5, 17, 10, 0, 0, 0, time.UTC)
+	info := func(title string) *mediaInfo {
+		return &mediaInfo{Size: 1, ModTime: modTime, MimeType: "audio/mpeg", Title: title}
+	}
+	for _, p := range []string{"/a.mp3", "/dir/b.mp3", "/dir/sub/c.mp3", "/dir/sub/d.mp3", "/dir2/e.mp3", "/dir.mp3"} {
+		require.NoError(t, x.put(p, info(p), nil))
+	}
+	require.NoError(t, x.put("/dir/thumb.mp3", info("thumb"), []byte("THUMB")))
+
+	got := x.get("/dir/b.mp3")
+	require.NotNil(t, got)
+	assert.Equal(t, "/dir/b.mp3", got.Title)
+	assert.True(t, got.ModTime.Equal(modTime))
+	assert.False(t, got.Thumbnail)
+	assert.Nil(t, x.get("/missing.mp3"))
+
+	assert.True(t, x.get("/dir/thumb.mp3").Thumbnail)
+	assert.Equal(t, "THUMB", string(x.thumbnail("/dir/thumb.mp3")))
+	assert.Nil(t, x.thumbnail("/dir/b.mp3"))
+
+	// getDir only returns what is directly in the directory
+	infos := x.getDir("/dir")
+	assert.Len(t, infos, 2)
+	assert.NotNil(t, infos["b.mp3"])
+	assert.NotNil(t, infos["thumb.mp3"])
+	assert.Len(t, x.getDir("/"), 2)
+
+	walked := func(dir string) (paths []string) {
+		require.NoError(t, x.walk(dir, func(p string, info *mediaInfo) {
+			paths = append(paths, p)
+		}))
+		return paths
+	}
+	assert.Equal(t, []string{"/dir/b.mp3", "/dir/sub/c.mp3", "/dir/sub/d.mp3", "/dir/thumb.mp3"}, walked("/dir"))
+	assert.Len(t, walked("/"), 7)
+
+	// Replacing the thumbnail with nil removes it
+	require.NoError(t, x.put("/dir/thumb.mp3", info("thumb"), nil))
+	assert.Nil(t, x.thumbnail("/dir/thumb.mp3"))
+
+	// prune removes files and directories which aren't kept
+	require.NoError(t, x.put("/dir/thumb.mp3", info("thumb"), []byte("THUMB")))
+	require.NoError(t, x.prune("/dir", map[string]bool{"b.mp3": true}))
+	assert.Equal(t, []string{"/dir/b.mp3"}, walked("/dir"))
+	assert.Nil(t, x.thumbnail("/dir/thumb.mp3"))
+	assert.Equal(t, []string{"/a.mp3", "/dir.mp3", "/dir/b.mp3", "/dir2/e.mp3"}, walked("/"))
+}
@@ -0,0 +1,523 @@
+package dlna
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+const (
+	// maxTagRead is the most that is read of the tags of a media file
+	maxTagRead = 4 * 1024 * 1024
+	// maxImageRead is the largest image that thumbnails are made from
+	maxImageRead = 32 * 1024 * 1024
+)
+
+// tags are the details read from the metadata in a media file
+type tags struct {
+	title   string
+	artist  string
+	album   string
+	genre   string
+	picture []byte // embedded cover art
+}
+
+// readMediaInfo reads what is indexed about the media file node,
+// returning the info and a thumbnail or nil if it hasn't got one.
+//
+// Failing to read the tags isn't an error - the file is indexed
+// without them.
+func readMediaInfo(node vfs.Node, mimeType string) (info *mediaInfo, thumb []byte) {
+	info = &mediaInfo{
+		Size:     node.Size(),
+		ModTime:  node.ModTime(),
+		MimeType: mimeType,
+	}
+	file, ok := node.(*vfs.File)
+	if !ok {
+		return info, nil
+	}
+	in, err := file.Open(os.O_RDONLY)
+	if err != nil {
+		fs.Errorf(node, "Failed to open media file for indexing: %v", err)
+		return info, nil
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			fs.Debugf(node, "Failed to close media file: %v", err)
+		}
+	}()
+
+	var picture []byte
+	switch mediaClass(mimeType) {
+	case "imageItem":
+		if info.Size > maxImageRead {
+			fs.Debugf(node, "Not making a thumbnail for image over %v", fs.SizeSuffix(maxImageRead))
+			break
+		}
+		picture = make([]byte, info.Size)
+		if _, err = in.ReadAt(picture, 0); err != nil && err != io.EOF {
+			fs.Errorf(node, "Failed to read image: %v", err)
+			return info, nil
+		}
+		if config, _, err := image.DecodeConfig(bytes.NewReader(picture)); err == nil {
+			info.Width, info.Height = config.Width, config.Height
+		}
+	case "audioItem", "videoItem":
+		t, err := readTags(in, info.Size)
+		if err != nil {
+			fs.Debugf(node, "Failed to read tags: %v", err)
+			return info, nil
+		}
+		if t == nil {
+			return info, nil
+		}
+		info.Title, info.Artist, info.Album, info.Genre = t.title, t.artist, t.album, t.genre
+		picture = t.picture
+	}
+	if picture != nil {
+		thumb, err = makeThumbnail(picture)
+		if err != nil {
+			fs.Debugf(node, "Failed to make thumbnail: %v", err)
+			thumb = nil
+		}
+	}
+	return info, thumb
+}
+
+// readTags reads the tags from the start of the media file in of
+// size given, returning nil if it isn't a format with tags that are
+// understood.
+func readTags(in io.ReaderAt, size int64) (*tags, error) {
+	magic := make([]byte, 8)
+	if _, err := in.ReadAt(magic, 0); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte("ID3")):
+		return readID3(in)
+	case bytes.HasPrefix(magic, []byte("fLaC")):
+		return readFLAC(in)
+	case string(magic[4:]) == "ftyp":
+		return readMP4(in, size)
+	}
+	return nil, nil
+}
+
+// errTagTooBig is returned if the tags are over maxTagRead
+var errTagTooBig = errors.New("tags too big")
+
+// readBlock reads n bytes at off in in
+func readBlock(in io.ReaderAt, off int64, n int64) ([]byte, error) {
+	if n < 0 || n > maxTagRead {
+		return nil, errTagTooBig
+	}
+	buf := make([]byte, n)
+	if _, err := in.ReadAt(buf, off); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// syncSafe decodes an ID3v2 synchsafe integer which has 7 bits in
+// each byte
+func syncSafe(b []byte) int64 {
+	var n int64
+	for _, c := range b {
+		n = n<<7 | int64(c&0x7f)
+	}
+	return n
+}
+
+// unsynchronise removes the 0x00 bytes ID3v2 puts after 0xFF bytes
+func unsynchronise(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff, 0x00}, []byte{0xff})
+}
+
+// readID3 reads an ID3v2.3 or ID3v2.4 tag
+func readID3(in io.ReaderAt) (*tags, error) {
+	header, err := readBlock(in, 0, 10)
+	if err != nil {
+		return nil, err
+	}
+	version, flags := header[3], header[5]
+	if version != 3 && version != 4 {
+		return nil, nil
+	}
+	data, err := readBlock(in, 10, syncSafe(header[6:10]))
+	if err != nil {
+		return nil, err
+	}
+	if version == 3 && flags&0x80 != 0 {
+		data = unsynchronise(data)
+	}
+	if flags&0x40 != 0 && len(data) >= 4 {
+		// skip the extended header
+		n := syncSafe(data[:4])
+		if version == 3 {
+			n = int64(binary.BigEndian.Uint32(data[:4])) + 4
+		}
+		if n > int64(len(data)) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		data = data[n:]
+	}
+	t := new(tags)
+	pictureType := -1
+	for len(data) >= 10 && data[0] != 0 {
+		id := string(data[:4])
+		n := int64(binary.BigEndian.Uint32(data[4:8]))
+		if version == 4 {
+			n = syncSafe(data[4:8])
+		}
+		frameFlags := data[9]
+		data = data[10:]
+		if n > int64(len(data)) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		frame := data[:n]
+		data = data[n:]
+		if version == 4 && frameFlags&0x02 != 0 {
+			frame = unsynchronise(frame)
+		}
+		if len(frame) == 0 {
+			continue
+		}
+		switch id {
+		case "TIT2":
+			t.title = id3Text(frame)
+		case "TPE1":
+			t.artist = id3Text(frame)
+		case "TALB":
+			t.album = id3Text(frame)
+		case "TCON":
+			t.genre = id3Genre(id3Text(frame))
+		case "APIC":
+			// prefer the front cover (type 3) to other pictures
+			if pictureType != 3 {
+				if picture, kind := id3Picture(frame); picture != nil && (pictureType < 0 || kind == 3) {
+					t.picture, pictureType = picture, kind
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// id3Text decodes the first string of an ID3v2 text frame
+func id3Text(frame []byte) string {
+	text, _ := id3String(frame[0], frame[1:])
+	return strings.TrimSpace(text)
+}
+
+// id3Genre tidies the numeric genres of old ID3 versions, "(17)Rock"
+// say, which are followed by the name.
+func id3Genre(genre string) string {
+	if strings.HasPrefix(genre, "(") {
+		if i := strings.IndexByte(genre, ')'); i > 0 && i < len(genre)-1 {
+			return genre[i+1:]
+		}
+	}
+	return genre
+}
+
+// id3Picture decodes an ID3v2 APIC frame returning the picture and
+// its type
+func id3Picture(frame []byte) (picture []byte, kind int) {
+	encoding := frame[0]
+	// MIME type is always ISO-8859-1
+	i := bytes.IndexByte(frame[1:], 0)
+	if i < 0 || 1+i+2 > len(frame) {
+		return nil, 0
+	}
+	rest := frame[1+i+1:]
+	kind = int(rest[0])
+	// skip the description
+	_, n := id3String(encoding, rest[1:])
+	return rest[1+n:], kind
+}
+
+// id3String decodes a terminated string in encoding from b returning
+// it and the number of bytes used including the terminator.
+func id3String(encoding byte, b []byte) (string, int) {
+	switch encoding {
+	case 1, 2:
+		// UTF-16 with a BOM or big endian UTF-16
+		end := len(b)
+		used := len(b)
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				end, used = i, i+2
+				break
+			}
+		}
+		s := b[:end]
+		order := binary.ByteOrder(binary.BigEndian)
+		if encoding == 1 && len(s) >= 2 {
+			if s[0] == 0xff && s[1] == 0xfe {
+				order = binary.LittleEndian
+			}
+			if (s[0] == 0xff && s[1] == 0xfe) || (s[0] == 0xfe && s[1] == 0xff) {
+				s = s[2:]
+			}
+		}
+		u := make([]uint16, len(s)/2)
+		for i := range u {
+			u[i] = order.Uint16(s[2*i:])
+		}
+		return string(utf16.Decode(u)), used
+	default:
+		end, used := len(b), len(b)
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			end, used = i, i+1
+		}
+		if encoding == 0 {
+			// ISO-8859-1 maps directly onto the first 256 runes
+			r := make([]rune, end)
+			for i, c := range b[:end] {
+				r[i] = rune(c)
+			}
+			return string(r), used
+		}
+		return string(b[:end]), used
+	}
+}
+
+// readFLAC reads the Vorbis comments and picture from the metadata
+// blocks of a FLAC file
+func readFLAC(in io.ReaderAt) (*tags, error) {
+	t := new(tags)
+	for off, last := int64(4), false; !last; {
+		header, err := readBlock(in, off, 4)
+		if err != nil {
+			return nil, err
+		}
+		last = header[0]&0x80 != 0
+		kind := header[0] & 0x7f
+		n := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		off += 4
+		if kind == 4 || (kind == 6 && t.picture == nil) {
+			block, err := readBlock(in, off, n)
+			if err != nil {
+				return nil, err
+			}
+			if kind == 4 {
+				vorbisComments(t, block)
+			} else {
+				t.picture = flacPicture(block)
+			}
+		}
+		off += n
+	}
+	return t, nil
+}
+
+// vorbisComments sets t from the Vorbis comments in block
+func vorbisComments(t *tags, block []byte) {
+	next := func() (string, bool) {
+		if len(block) < 4 {
+			return "", false
+		}
+		n := binary.LittleEndian.Uint32(block)
+		if uint64(n) > uint64(len(block)-4) {
+			return "", false
+		}
+		s := string(block[4 : 4+n])
+		block = block[4+n:]
+		return s, true
+	}
+	// skip the vendor string
+	if _, ok := next(); !ok || len(block) < 4 {
+		return
+	}
+	count := binary.LittleEndian.Uint32(block)
+	block = block[4:]
+	for i := uint32(0); i < count; i++ {
+		comment, ok := next()
+		if !ok {
+			return
+		}
+		i := strings.IndexByte(comment, '=')
+		if i < 0 {
+			continue
+		}
+		value := comment[i+1:]
+		switch strings.ToUpper(comment[:i]) {
+		case "TITLE":
+			t.title = value
+		case "ARTIST":
+			t.artist = value
+		case "ALBUM":
+			t.album = value
+		case "GENRE":
+			t.genre = value
+		}
+	}
+}
+
+// flacPicture returns the picture data from a FLAC PICTURE block
+func flacPicture(block []byte) []byte {
+	// type, then length prefixed MIME type and description
+	off := uint64(4)
+	for i := 0; i < 2; i++ {
+		if off+4 > uint64(len(block)) {
+			return nil
+		}
+		off += 4 + uint64(binary.BigEndian.Uint32(block[off:]))
+	}
+	// width, height, depth, colours then length prefixed data
+	off += 16
+	if off+4 > uint64(len(block)) {
+		return nil
+	}
+	n := uint64(binary.BigEndian.Uint32(block[off:]))
+	off += 4
+	if off+n > uint64(len(block)) {
+		return nil
+	}
+	return block[off : off+n]
+}
+
+// mp4Box is a box (or atom) in an MP4 file
+type mp4Box struct {
+	kind string
+	off  int64 // offset of the contents
+	size int64 // size of the contents
+}
+
+// mp4Boxes returns the boxes between off and end using read to fetch
+// the headers
+func mp4Boxes(read func(off, n int64) ([]byte, error), off, end int64) (boxes []mp4Box, err error) {
+	for off+8 <= end {
+		header, err := read(off, 8)
+		if err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			// box extends to the end
+			size = end - off
+		case 1:
+			large, err := read(off+8, 8)
+			if err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(large))
+			headerSize = 16
+		}
+		// compared this way round as off+size can overflow
+		if size < headerSize || size > end-off {
+			return nil, io.ErrUnexpectedEOF
+		}
+		boxes = append(boxes, mp4Box{
+			kind: string(header[4:8]),
+			off:  off + headerSize,
+			size: size - headerSize,
+		})
+		off += size
+	}
+	return boxes, nil
+}
+
+// findBox returns the first box of kind in boxes
+func findBox(boxes []mp4Box, kind string) (mp4Box, bool) {
+	for _, box := range boxes {
+		if box.kind == kind {
+			return box, true
+		}
+	}
+	return mp4Box{}, false
+}
+
+// readMP4 reads the iTunes style tags from moov/udta/meta/ilst in an
+// MP4 file. The moov box may be at the start or the end of the file.
+func readMP4(in io.ReaderAt, size int64) (*tags, error) {
+	top, err := mp4Boxes(func(off, n int64) ([]byte, error) {
+		return readBlock(in, off, n)
+	}, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	moov, ok := findBox(top, "moov")
+	if !ok {
+		return nil, nil
+	}
+	// The moov box has the sample tables in too, so this limits
+	// the length of the files which can be read.
+	data, err := readBlock(in, moov.off, moov.size)
+	if err != nil {
+		return nil, err
+	}
+	read := func(off, n int64) ([]byte, error) {
+		if off+n > int64(len(data)) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return data[off : off+n], nil
+	}
+	path := func(box mp4Box, kinds ...string) (mp4Box, bool) {
+		for _, kind := range kinds {
+			off := box.off
+			if box.kind == "meta" && box.size >= 8 && string(data[off+4:off+8]) != "hdlr" {
+				// meta is a full box with a version and flags
+				// in MP4 but not in QuickTime
+				off += 4
+			}
+			children, err := mp4Boxes(read, off, box.off+box.size)
+			if err != nil {
+				return mp4Box{}, false
+			}
+			if box, ok = findBox(children, kind); !ok {
+				return mp4Box{}, false
+			}
+		}
+		return box, true
+	}
+	ilst, ok := path(mp4Box{kind: "moov", size: int64(len(data))}, "udta", "meta", "ilst")
+	if !ok {
+		return nil, nil
+	}
+	items, err := mp4Boxes(read, ilst.off, ilst.off+ilst.size)
+	if err != nil {
+		return nil, err
+	}
+	t := new(tags)
+	for _, item := range items {
+		dataBox, ok := path(item, "data")
+		// data has a 4 byte type and a 4 byte locale before the value
+		if !ok || dataBox.size < 8 {
+			continue
+		}
+		value := data[dataBox.off+8 : dataBox.off+dataBox.size]
+		switch item.kind {
+		case "\xa9nam":
+			t.title = string(value)
+		case "\xa9ART":
+			t.artist = string(value)
+		case "\xa9alb":
+			t.album = string(value)
+		case "\xa9gen":
+			t.genre = string(value)
+		case "covr":
+			if t.picture == nil {
+				t.picture = value
+			}
+		}
+	}
+	return t, nil
+}
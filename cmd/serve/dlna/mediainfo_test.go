@@ -0,0 +1,195 @@
+package dlna
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// id3Frame makes an ID3v2.3 frame
+func id3Frame(id string, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	frame := append([]byte(id), 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(body)))
+	return append(frame, body...)
+}
+
+// syncSafeBytes encodes n as an ID3v2 synchsafe integer
+func syncSafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+func TestReadID3(t *testing.T) {
+	frames := bytes.Join([][]byte{
+		id3Frame("TIT2", []byte{0}, []byte("Caf\xe9\x00")),
+		// UTF-16 with a little endian BOM
+		id3Frame("TPE1", []byte{1, 0xff, 0xfe, 'A', 0, 'r', 0, 't', 0, 0, 0}),
+		id3Frame("TALB", []byte{3}, []byte("Albüm")),
+		id3Frame("TCON", []byte{0}, []byte("(17)Rock")),
+		id3Frame("APIC", []byte{0}, []byte("image/png\x00"), []byte{4}, []byte("back\x00"), []byte("BACK")),
+		id3Frame("APIC", []byte{1}, []byte("image/png\x00"), []byte{3}, []byte{0xff, 0xfe, 'f', 0, 0, 0}, []byte("FRONT")),
+		// padding
+		make([]byte, 32),
+	}, nil)
+	tag := append([]byte("ID3\x03\x00\x00"), syncSafeBytes(len(frames))...)
+	tag = append(tag, frames...)
+	tag = append(tag, []byte("audio data")...)
+
+	got, err := readTags(bytes.NewReader(tag), int64(len(tag)))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "Café", got.title)
+	assert.Equal(t, "Art", got.artist)
+	assert.Equal(t, "Albüm", got.album)
+	assert.Equal(t, "Rock", got.genre)
+	assert.Equal(t, "FRONT", string(got.picture))
+
+	// A truncated tag is an error
+	_, err = readTags(bytes.NewReader(tag[:20]), 20)
+	assert.Error(t, err)
+
+	// ID3v2.2 isn't understood
+	tag[3] = 2
+	got, err = readTags(bytes.NewReader(tag), int64(len(tag)))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+// flacBlock makes a FLAC metadata block
+func flacBlock(kind byte, last bool, data []byte) []byte {
+	if last {
+		kind |= 0x80
+	}
+	n := len(data)
+	return append([]byte{kind, byte(n >> 16), byte(n >> 8), byte(n)}, data...)
+}
+
+func TestReadFLAC(t *testing.T) {
+	le := func(s string) []byte {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, uint32(len(s)))
+		return append(b, s...)
+	}
+	be := func(n int) []byte {
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(n))
+		return b
+	}
+	comments := bytes.Join([][]byte{
+		le("vendor"),
+		{4, 0, 0, 0},
+		le("title=Song"),
+		le("ARTIST=Singer"),
+		le("Album=Record"),
+		le("nonsense"),
+	}, nil)
+	picture := bytes.Join([][]byte{
+		be(3),
+		be(9), []byte("image/png"),
+		be(0),
+		be(1), be(1), be(24), be(0),
+		be(5), []byte("IMAGE"),
+	}, nil)
+	file := bytes.Join([][]byte{
+		[]byte("fLaC"),
+		flacBlock(0, false, make([]byte, 34)),
+		flacBlock(4, false, comments),
+		flacBlock(6, true, picture),
+		[]byte("audio data"),
+	}, nil)
+
+	got, err := readTags(bytes.NewReader(file), int64(len(file)))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "Song", got.title)
+	assert.Equal(t, "Singer", got.artist)
+	assert.Equal(t, "Record", got.album)
+	assert.Equal(t, "", got.genre)
+	assert.Equal(t, "IMAGE", string(got.picture))
+}
+
+// mp4Atom makes an MP4 box
+func mp4Atom(kind string, children ...[]byte) []byte {
+	body := bytes.Join(children, nil)
+	box := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(box, uint32(8+len(body)))
+	copy(box[4:], kind)
+	return append(box, body...)
+}
+
+func TestReadMP4(t *testing.T) {
+	data := func(value string) []byte {
+		// type and locale come before the value
+		return mp4Atom("data", make([]byte, 8), []byte(value))
+	}
+	ilst := mp4Atom("ilst",
+		mp4Atom("\xa9nam", data("Title")),
+		mp4Atom("\xa9ART", data("Artist")),
+		mp4Atom("\xa9gen", data("Genre")),
+		mp4Atom("covr", data("COVER")),
+	)
+	for _, quickTime := range []bool{false, true} {
+		meta := mp4Atom("meta", make([]byte, 4), mp4Atom("hdlr", make([]byte, 25)), ilst)
+		if quickTime {
+			// meta isn't a full box in QuickTime
+			meta = mp4Atom("meta", mp4Atom("hdlr", make([]byte, 25)), ilst)
+		}
+		// moov at the end as many files have it
+		file := bytes.Join([][]byte{
+			mp4Atom("ftyp", []byte("isom")),
+			mp4Atom("mdat", make([]byte, 100)),
+			mp4Atom("moov", mp4Atom("mvhd", make([]byte, 100)), mp4Atom("udta", meta)),
+		}, nil)
+
+		got, err := readTags(bytes.NewReader(file), int64(len(file)))
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "Title", got.title)
+		assert.Equal(t, "Artist", got.artist)
+		assert.Equal(t, "", got.album)
+		assert.Equal(t, "Genre", got.genre)
+		assert.Equal(t, "COVER", string(got.picture))
+	}
+
+	// No tags
+	file := bytes.Join([][]byte{
+		mp4Atom("ftyp", []byte("isom")),
+		mp4Atom("moov", mp4Atom("mvhd", make([]byte, 100))),
+	}, nil)
+	got, err := readTags(bytes.NewReader(file), int64(len(file)))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestMP4BoxesBadSize(t *testing.T) {
+	for _, size := range []uint64{
+		1 << 63,      // negative
+		1<<63 - 1,    // overflows the offset
+		16 + 100 + 1, // past the end
+		15,           // smaller than the header
+	} {
+		ftyp := mp4Atom("ftyp", []byte("isom"))
+		large := make([]byte, 16)
+		binary.BigEndian.PutUint32(large, 1)
+		copy(large[4:], "mdat")
+		binary.BigEndian.PutUint64(large[8:], size)
+		file := bytes.Join([][]byte{ftyp, large, make([]byte, 100)}, nil)
+		read := func(off, n int64) ([]byte, error) {
+			return file[off : off+n], nil
+		}
+		_, err := mp4Boxes(read, 0, int64(len(file)))
+		assert.Equal(t, io.ErrUnexpectedEOF, err, size)
+	}
+}
+
+func TestReadTagsUnknown(t *testing.T) {
+	for _, file := range []string{"", "short", "neither an mp3 nor a flac"} {
+		got, err := readTags(bytes.NewReader([]byte(file)), int64(len(file)))
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	}
+}
@@ -0,0 +1,255 @@
+package dlna
+
+import (
+	"fmt"
+	"strings"
+)
+
+// searchProperties are the properties which can be searched on
+var searchProperties = []string{
+	"upnp:class",
+	"dc:title",
+	"dc:creator",
+	"upnp:artist",
+	"upnp:album",
+	"upnp:genre",
+	"dc:date",
+}
+
+// searchMatcher returns whether the item with the properties given
+// matches a search
+type searchMatcher func(props map[string]string) bool
+
+// parseSearch parses UPnP ContentDirectory SearchCriteria, such as
+//
+//	upnp:class derivedfrom "object.item.audioItem" and dc:title contains "love"
+//
+// Comparisons are case insensitive. Properties which aren't known
+// don't exist so only match "exists false".
+func parseSearch(criteria string) (searchMatcher, error) {
+	criteria = strings.TrimSpace(criteria)
+	if criteria == "" || criteria == "*" {
+		return func(map[string]string) bool { return true }, nil
+	}
+	tokens, err := searchTokens(criteria)
+	if err != nil {
+		return nil, err
+	}
+	p := searchParser{tokens: tokens}
+	match, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.more() {
+		return nil, fmt.Errorf("unexpected %q in search criteria", p.peek().text)
+	}
+	return match, nil
+}
+
+// searchToken is a lexical token of the search criteria
+type searchToken struct {
+	text   string
+	quoted bool // text was a quoted string
+}
+
+// searchOperatorChars are the characters the relational operators
+// are made of which don't need spaces around them
+const searchOperatorChars = "=!<>"
+
+// searchTokens splits criteria into tokens
+func searchTokens(criteria string) (tokens []searchToken, err error) {
+	for i := 0; i < len(criteria); {
+		c := criteria[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, searchToken{text: string(c)})
+			i++
+		case c == '"':
+			var value strings.Builder
+			closed := false
+			for i++; i < len(criteria); i++ {
+				c = criteria[i]
+				if c == '\\' && i+1 < len(criteria) {
+					i++
+					c = criteria[i]
+				} else if c == '"' {
+					closed = true
+					i++
+					break
+				}
+				value.WriteByte(c)
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string in search criteria")
+			}
+			tokens = append(tokens, searchToken{text: value.String(), quoted: true})
+		case strings.IndexByte(searchOperatorChars, c) >= 0:
+			start := i
+			for i < len(criteria) && strings.IndexByte(searchOperatorChars, criteria[i]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, searchToken{text: criteria[start:i]})
+		default:
+			start := i
+			for i < len(criteria) && !strings.ContainsRune(" \t\r\n()\""+searchOperatorChars, rune(criteria[i])) {
+				i++
+			}
+			tokens = append(tokens, searchToken{text: criteria[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+// searchParser is a recursive descent parser for search criteria
+type searchParser struct {
+	tokens []searchToken
+	pos    int
+}
+
+// more returns whether there are tokens left
+func (p *searchParser) more() bool {
+	return p.pos < len(p.tokens)
+}
+
+// peek returns the next token without using it
+func (p *searchParser) peek() searchToken {
+	if !p.more() {
+		return searchToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+// next uses the next token returning an error if there isn't one
+func (p *searchParser) next() (searchToken, error) {
+	if !p.more() {
+		return searchToken{}, fmt.Errorf("unexpected end of search criteria")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+// isWord returns whether the next token is the unquoted word given
+func (p *searchParser) isWord(word string) bool {
+	t := p.peek()
+	return !t.quoted && strings.EqualFold(t.text, word)
+}
+
+// or parses expressions joined by "or"
+func (p *searchParser) or() (searchMatcher, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.isWord("or") {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(props map[string]string) bool {
+			return l(props) || right(props)
+		}
+	}
+	return left, nil
+}
+
+// and parses expressions joined by "and" which binds tighter than
+// "or"
+func (p *searchParser) and() (searchMatcher, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.isWord("and") {
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(props map[string]string) bool {
+			return l(props) && right(props)
+		}
+	}
+	return left, nil
+}
+
+// term parses a bracketed expression or a comparison
+func (p *searchParser) term() (searchMatcher, error) {
+	if p.isWord("(") {
+		p.pos++
+		match, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isWord(")") {
+			return nil, fmt.Errorf("missing ) in search criteria")
+		}
+		p.pos++
+		return match, nil
+	}
+	property, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if property.quoted || operator.quoted {
+		return nil, fmt.Errorf("expecting property and operator in search criteria but got %q %q", property.text, operator.text)
+	}
+	prop := property.text
+	op := strings.ToLower(operator.text)
+	if op == "exists" {
+		want := strings.ToLower(value.text)
+		if value.quoted || (want != "true" && want != "false") {
+			return nil, fmt.Errorf("expecting true or false after exists but got %q", value.text)
+		}
+		return func(props map[string]string) bool {
+			return (props[prop] != "") == (want == "true")
+		}, nil
+	}
+	if !value.quoted {
+		return nil, fmt.Errorf("expecting a quoted value after %s but got %q", operator.text, value.text)
+	}
+	want := strings.ToLower(value.text)
+	var compare func(have string) bool
+	switch op {
+	case "=":
+		compare = func(have string) bool { return have == want }
+	case "!=":
+		compare = func(have string) bool { return have != want }
+	case "<":
+		compare = func(have string) bool { return have < want }
+	case "<=":
+		compare = func(have string) bool { return have <= want }
+	case ">":
+		compare = func(have string) bool { return have > want }
+	case ">=":
+		compare = func(have string) bool { return have >= want }
+	case "contains":
+		compare = func(have string) bool { return strings.Contains(have, want) }
+	case "doesnotcontain":
+		compare = func(have string) bool { return !strings.Contains(have, want) }
+	case "derivedfrom":
+		base := strings.TrimSuffix(want, ".")
+		compare = func(have string) bool { return have == base || strings.HasPrefix(have, base+".") }
+	default:
+		return nil, fmt.Errorf("unknown operator %q in search criteria", operator.text)
+	}
+	return func(props map[string]string) bool {
+		have, ok := props[prop]
+		if !ok {
+			return false
+		}
+		return compare(strings.ToLower(have))
+	}, nil
+}
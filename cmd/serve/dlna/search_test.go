@@ -0,0 +1,75 @@
+package dlna
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearch(t *testing.T) {
+	song := map[string]string{
+		"upnp:class":  "object.item.audioItem",
+		"dc:title":    "Love Song",
+		"upnp:artist": "The Band",
+		"upnp:album":  "",
+		"dc:date":     "2020-05-17",
+	}
+	video := map[string]string{
+		"upnp:class": "object.item.videoItem",
+		"dc:title":   "holiday.mp4",
+		"dc:date":    "2021-08-01",
+	}
+	for _, test := range []struct {
+		criteria string
+		song     bool
+		video    bool
+	}{
+		{"*", true, true},
+		{"", true, true},
+		{`upnp:class derivedfrom "object.item.audioItem"`, true, false},
+		{`upnp:class derivedfrom "object.item"`, true, true},
+		{`upnp:class derivedfrom "object.item."`, true, true},
+		{`upnp:class derivedfrom "object.item.audio"`, false, false},
+		{`upnp:class = "OBJECT.ITEM.VIDEOITEM"`, false, true},
+		{`upnp:class="object.item.videoItem"`, false, true},
+		{`dc:title contains "love"`, true, false},
+		{`dc:title doesNotContain "love"`, false, true},
+		{`dc:title != "love song"`, false, true},
+		{`dc:date >= "2021-01-01"`, false, true},
+		{`dc:date < "2021-01-01"`, true, false},
+		{`upnp:artist exists true`, true, false},
+		{`upnp:album exists true`, false, false},
+		{`@refID exists false`, true, true},
+		{`@refID = "x"`, false, false},
+		{`dc:title contains "love" or dc:title contains "holiday"`, true, true},
+		{`dc:title contains "love" and dc:title contains "holiday"`, false, false},
+		// and binds tighter than or
+		{`dc:title contains "holiday" or dc:title contains "love" and upnp:artist exists false`, false, true},
+		{`(dc:title contains "holiday" or dc:title contains "love") and upnp:artist exists true`, true, false},
+		{`dc:title contains "\"" or dc:title contains "band"`, false, false},
+		{`(upnp:class derivedfrom "object.item.audioItem" AND (dc:title contains "song"))`, true, false},
+	} {
+		match, err := parseSearch(test.criteria)
+		require.NoError(t, err, test.criteria)
+		assert.Equal(t, test.song, match(song), "song: "+test.criteria)
+		assert.Equal(t, test.video, match(video), "video: "+test.criteria)
+	}
+
+	for _, criteria := range []string{
+		`dc:title`,
+		`dc:title contains`,
+		`dc:title contains love`,
+		`dc:title contains "love`,
+		`dc:title potato "love"`,
+		`dc:title exists "true"`,
+		`dc:title exists maybe`,
+		`(dc:title contains "love"`,
+		`dc:title contains "love")`,
+		`dc:title contains "love" and`,
+		`dc:title contains "love" dc:title contains "song"`,
+	} {
+		_, err := parseSearch(criteria)
+		assert.Error(t, err, criteria)
+	}
+}
@@ -0,0 +1,83 @@
+package dlna
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeThumbnail(t *testing.T) {
+	// A wide image which is red on the left and blue on the right
+	src := image.NewRGBA(image.Rect(0, 0, 640, 320))
+	for y := 0; y < 320; y++ {
+		for x := 0; x < 640; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 320 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	thumb, err := makeThumbnail(buf.Bytes())
+	require.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(thumb))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, thumbSize, thumbSize/2), img.Bounds())
+	r, _, b, _ := img.At(10, 10).RGBA()
+	assert.Greater(t, r, b)
+	r, _, b, _ = img.At(thumbSize-10, 10).RGBA()
+	assert.Less(t, r, b)
+
+	_, err = makeThumbnail([]byte("not an image"))
+	assert.Error(t, err)
+}
+
+func TestMakeThumbnailTooLarge(t *testing.T) {
+	// Only the header of a PNG is needed to find its size
+	pngChunk := func(kind string, data []byte) []byte {
+		chunk := make([]byte, 8+len(data)+4)
+		binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+		copy(chunk[4:], kind)
+		copy(chunk[8:], data)
+		binary.BigEndian.PutUint32(chunk[8+len(data):], crc32.ChecksumIEEE(chunk[4:8+len(data)]))
+		return chunk
+	}
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:], 100000)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 0 // grayscale
+	data := append([]byte("\x89PNG\r\n\x1a\n"), pngChunk("IHDR", ihdr)...)
+
+	_, err := makeThumbnail(data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "image too large: 100000x100000")
+}
+
+func TestScaleImage(t *testing.T) {
+	for _, test := range []struct {
+		w, h  int
+		wantW int
+		wantH int
+	}{
+		{100, 50, 100, 50},
+		{160, 160, 160, 160},
+		{320, 320, 160, 160},
+		{400, 800, 80, 160},
+		{10000, 10, 160, 1},
+	} {
+		img := scaleImage(image.NewGray(image.Rect(0, 0, test.w, test.h)), thumbSize)
+		assert.Equal(t, image.Rect(0, 0, test.wantW, test.wantH), img.Bounds(), "%dx%d", test.w, test.h)
+	}
+}
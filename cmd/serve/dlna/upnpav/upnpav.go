@@ -9,6 +9,8 @@ import (
 const (
 	// NoSuchObjectErrorCode : The specified ObjectID is invalid.
 	NoSuchObjectErrorCode = 701
+	// InvalidSearchCriteriaErrorCode : The search criteria are unsupported or invalid.
+	InvalidSearchCriteriaErrorCode = 708
 )
 
 // Resource description
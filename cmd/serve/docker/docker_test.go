@@ -22,6 +22,7 @@ import (
 	"github.com/rclone/rclone/cmd/serve/docker"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fstest"
 	"github.com/rclone/rclone/fstest/testy"
 	"github.com/rclone/rclone/lib/file"
@@ -204,6 +205,157 @@ func TestDockerPluginLogic(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDockerPluginStatus(t *testing.T) {
+	ctx := context.Background()
+	oldCacheDir := config.GetCacheDir()
+	testDir, testFs := initialise(ctx, t)
+	err := config.SetCacheDir(testDir)
+	require.NoError(t, err)
+	defer func() {
+		_ = config.SetCacheDir(oldCacheDir)
+		if !t.Failed() {
+			fstest.Purge(testFs)
+			_ = os.RemoveAll(testDir)
+		}
+	}()
+
+	drv, err := docker.NewDriver(ctx, testDir, nil, nil, true, true)
+	require.NoError(t, err)
+
+	volReq := &docker.CreateRequest{
+		Name:    "vol1",
+		Options: docker.VolOpts{"remote": testDir, "quota": "potato"},
+	}
+	assertErrorContains(t, drv.Create(volReq), "cannot parse vfs option")
+
+	volReq.Options["quota"] = "1G"
+	require.NoError(t, drv.Create(volReq))
+
+	getRes, err := drv.Get(&docker.GetRequest{Name: "vol1"})
+	require.NoError(t, err)
+	status := getRes.Volume.Status
+	assert.Equal(t, "ok", status["Health"])
+	assert.Equal(t, false, status["Mounted"])
+	assert.Nil(t, status["Problems"])
+	assert.Nil(t, status["LastError"])
+	assert.Equal(t, rc.Params{"Total": int64(1 << 30)}, status["Quota"])
+
+	_, err = drv.Mount(&docker.MountRequest{Name: "vol1", ID: "id1"})
+	require.NoError(t, err)
+	getRes, err = drv.Get(&docker.GetRequest{Name: "vol1"})
+	require.NoError(t, err)
+	assert.Equal(t, true, getRes.Volume.Status["Mounted"])
+	assert.Equal(t, []string{"id1"}, getRes.Volume.Status["Mounts"])
+}
+
+func TestDockerPluginState(t *testing.T) {
+	ctx := context.Background()
+	oldCacheDir := config.GetCacheDir()
+	testDir, testFs := initialise(ctx, t)
+	err := config.SetCacheDir(testDir)
+	require.NoError(t, err)
+	defer func() {
+		_ = config.SetCacheDir(oldCacheDir)
+		if !t.Failed() {
+			fstest.Purge(testFs)
+			_ = os.RemoveAll(testDir)
+		}
+	}()
+	statePath := filepath.Join(testDir, "docker-plugin.state")
+
+	drv, err := docker.NewDriver(ctx, testDir, nil, nil, true, true)
+	require.NoError(t, err)
+	volReq := &docker.CreateRequest{
+		Name:    "vol1",
+		Options: docker.VolOpts{"remote": testDir, "quota": "1M"},
+	}
+	require.NoError(t, drv.Create(volReq))
+
+	// The state file is versioned
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	var state struct {
+		Version int               `json:"version"`
+		Volumes []json.RawMessage `json:"volumes"`
+	}
+	require.NoError(t, json.Unmarshal(data, &state))
+	assert.Equal(t, 2, state.Version)
+	assert.Len(t, state.Volumes, 1)
+
+	restart := func() *docker.Driver {
+		drv, err := docker.NewDriver(ctx, testDir, nil, nil, true, false)
+		require.NoError(t, err)
+		return drv
+	}
+	volumeNames := func(drv *docker.Driver) (names []string) {
+		listRes, err := drv.List()
+		require.NoError(t, err)
+		for _, vol := range listRes.Volumes {
+			names = append(names, vol.Name)
+		}
+		return names
+	}
+
+	// Options survive a restart
+	drv = restart()
+	assert.Equal(t, []string{"vol1"}, volumeNames(drv))
+	getRes, err := drv.Get(&docker.GetRequest{Name: "vol1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", getRes.Volume.Status["Health"])
+	assert.Equal(t, rc.Params{"Total": int64(1 << 20)}, getRes.Volume.Status["Quota"])
+
+	// Version 1 was a bare list of volumes
+	legacy := fmt.Sprintf(`[{"name":"old","mountpoint":%q,"fs":%q,"options":{},"mounts":[]}]`,
+		filepath.Join(testDir, "old"), testDir)
+	require.NoError(t, os.WriteFile(statePath, []byte(legacy), 0600))
+	drv = restart()
+	assert.Equal(t, []string{"old"}, volumeNames(drv))
+	require.NoError(t, drv.Create(&docker.CreateRequest{
+		Name:    "new",
+		Options: docker.VolOpts{"remote": testDir},
+	}))
+	data, err = os.ReadFile(statePath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &state))
+	assert.Equal(t, 2, state.Version)
+	assert.Len(t, state.Volumes, 2)
+
+	// Volumes which can't be set up are kept but reported as failed
+	broken := fmt.Sprintf(`{"version":2,"volumes":[{"name":"broken","mountpoint":%q,"type":"potato","options":{"quota":"1M"},"mounts":[]}]}`,
+		filepath.Join(testDir, "broken"))
+	require.NoError(t, os.WriteFile(statePath, []byte(broken), 0600))
+	drv = restart()
+	assert.Equal(t, []string{"broken"}, volumeNames(drv))
+	getRes, err = drv.Get(&docker.GetRequest{Name: "broken"})
+	require.NoError(t, err)
+	assert.Equal(t, "failed", getRes.Volume.Status["Health"])
+	assert.NotNil(t, getRes.Volume.Status["Problems"])
+	_, err = drv.Mount(&docker.MountRequest{Name: "broken", ID: "id1"})
+	assertErrorContains(t, err, "unknown filesystem type")
+	drv = restart()
+	assert.Equal(t, []string{"broken"}, volumeNames(drv))
+	require.NoError(t, drv.Remove(&docker.RemoveRequest{Name: "broken"}))
+	assert.Nil(t, volumeNames(drv))
+
+	// A newer version is read as far as possible and backed up
+	newer := fmt.Sprintf(`{"version":99,"volumes":[{"name":"future","mountpoint":%q,"fs":%q,"options":{},"mounts":[],"extra":true}]}`,
+		filepath.Join(testDir, "future"), testDir)
+	require.NoError(t, os.WriteFile(statePath, []byte(newer), 0600))
+	drv = restart()
+	assert.Equal(t, []string{"future"}, volumeNames(drv))
+	backup, err := os.ReadFile(statePath + ".v99")
+	require.NoError(t, err)
+	assert.Equal(t, newer, string(backup))
+
+	// A corrupt state file is kept rather than overwritten
+	require.NoError(t, os.WriteFile(statePath, []byte("potato"), 0600))
+	drv = restart()
+	assert.Nil(t, volumeNames(drv))
+	backup, err = os.ReadFile(statePath + ".corrupt")
+	require.NoError(t, err)
+	assert.Equal(t, "potato", string(backup))
+}
+
 const (
 	httpTimeout = 2 * time.Second
 	tempDelay   = 10 * time.Millisecond
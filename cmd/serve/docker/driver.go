@@ -1,8 +1,10 @@
 package docker
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -120,7 +122,7 @@ func (drv *Driver) monitor() {
 		}
 		hupChan := reflect.SelectCase{
 			Dir:  reflect.SelectRecv,
-			Chan: reflect.ValueOf(drv.monChan),
+			Chan: reflect.ValueOf(drv.hupChan),
 		}
 		sources := []reflect.SelectCase{monChan, hupChan}
 		volumes := []*Volume{nil, nil}
@@ -151,12 +153,15 @@ func (drv *Driver) monitor() {
 			drv.clearCache()
 		default:
 			vol := volumes[idx]
-			if err := val.Interface(); err != nil {
+			unmountErr := errors.New("unmounted externally")
+			if err, _ := val.Interface().(error); err != nil {
 				fs.Logf(nil, "Volume %q unmounted externally: %v", vol.Name, err)
+				unmountErr = fmt.Errorf("unmounted externally: %w", err)
 			} else {
 				fs.Infof(nil, "Volume %q unmounted externally", vol.Name)
 			}
 			drv.mu.Lock()
+			vol.setError(unmountErr)
 			reportErr(vol.unmountAll())
 			drv.mu.Unlock()
 		}
@@ -170,7 +175,9 @@ func (drv *Driver) clearCache() {
 	defer drv.mu.Unlock()
 
 	for _, vol := range drv.volumes {
-		reportErr(vol.clearCache())
+		err := vol.clearCache()
+		vol.setError(err)
+		reportErr(err)
 	}
 }
 
@@ -221,17 +228,23 @@ func (drv *Driver) Remove(req *RemoveRequest) error {
 // List volumes handled by the driver
 func (drv *Driver) List() (*ListResponse, error) {
 	drv.mu.Lock()
-	defer drv.mu.Unlock()
-
 	volumeList := drv.listVolumes()
 	fs.Debugf(nil, "List: %v", volumeList)
 
 	res := &ListResponse{
 		Volumes: []*VolInfo{},
 	}
+	var finishers []func()
 	for _, name := range volumeList {
 		vol := drv.volumes[name]
-		res.Volumes = append(res.Volumes, vol.getInfo())
+		info, finish := vol.getInfo()
+		res.Volumes = append(res.Volumes, info)
+		finishers = append(finishers, finish)
+	}
+	drv.mu.Unlock()
+
+	for _, finish := range finishers {
+		finish()
 	}
 	return res, nil
 }
@@ -239,12 +252,16 @@ func (drv *Driver) List() (*ListResponse, error) {
 // Get volume info
 func (drv *Driver) Get(req *GetRequest) (*GetResponse, error) {
 	drv.mu.Lock()
-	defer drv.mu.Unlock()
 	vol, err := drv.getVolume(req.Name)
 	if err != nil {
+		drv.mu.Unlock()
 		return nil, err
 	}
-	return &GetResponse{Volume: vol.getInfo()}, nil
+	info, finish := vol.getInfo()
+	drv.mu.Unlock()
+
+	finish()
+	return &GetResponse{Volume: info}, nil
 }
 
 // Path returns path of the requested volume
@@ -308,6 +325,74 @@ func (drv *Driver) listVolumes() []string {
 	return names
 }
 
+// stateVersion is the version of the state file written by saveState.
+//
+// Version 1 was a bare JSON list of volumes. Bump this if the format
+// changes in a way older plugins can't read and teach decodeState how
+// to read the old one.
+const stateVersion = 2
+
+// driverState is the contents of the state file
+type driverState struct {
+	Version int       `json:"version"`
+	Volumes []*Volume `json:"volumes"`
+}
+
+// decodeState decodes the state file written by any version
+func decodeState(data []byte) (*driverState, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		state := &driverState{Version: 1}
+		if err := json.Unmarshal(data, &state.Volumes); err != nil {
+			return nil, err
+		}
+		return state, nil
+	}
+	state := &driverState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Version < 2 {
+		return nil, fmt.Errorf("unknown state file version %d", state.Version)
+	}
+	return state, nil
+}
+
+// writeState writes data to path via a temporary file so that a
+// crash or full disk can't leave a half written state behind.
+func writeState(path string, data []byte) (err error) {
+	tmpPath := path + ".tmp"
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+	}
+	return err
+}
+
+// backupState keeps a copy of a state file which is about to be
+// overwritten but couldn't be fully understood
+func (drv *Driver) backupState(suffix string, data []byte) {
+	backupPath := drv.statePath + suffix
+	if err := writeState(backupPath, data); err != nil {
+		fs.Errorf(nil, "Failed to back up plugin state to %s: %v", backupPath, err)
+		return
+	}
+	fs.Logf(nil, "Saved a copy of the plugin state in %s", backupPath)
+}
+
 // saveState saves volumes handled by driver to persistent store
 func (drv *Driver) saveState() error {
 	volumeList := drv.listVolumes()
@@ -320,7 +405,10 @@ func (drv *Driver) saveState() error {
 		state = append(state, vol)
 	}
 
-	data, err := json.Marshal(state)
+	data, err := json.Marshal(&driverState{
+		Version: stateVersion,
+		Volumes: state,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
@@ -328,7 +416,7 @@ func (drv *Driver) saveState() error {
 	ctx := context.Background()
 	retries := fs.GetConfig(ctx).LowLevelRetries
 	for i := 0; i <= retries; i++ {
-		err = os.WriteFile(drv.statePath, data, 0600)
+		err = writeState(drv.statePath, data)
 		if err == nil {
 			return nil
 		}
@@ -346,19 +434,30 @@ func (drv *Driver) restoreState(ctx context.Context) error {
 		return nil
 	}
 
-	var state []*Volume
+	var state *driverState
 	if err == nil {
-		err = json.Unmarshal(data, &state)
+		state, err = decodeState(data)
+		if err != nil {
+			drv.backupState(".corrupt", data)
+		}
 	}
 	if err != nil {
 		fs.Logf(nil, "Failed to restore plugin state: %v", err)
 		return nil
 	}
+	if state.Version > stateVersion {
+		fs.Logf(nil, "Plugin state was saved by a newer version of the plugin (version %d > %d) - restoring what can be understood", state.Version, stateVersion)
+		drv.backupState(fmt.Sprintf(".v%d", state.Version), data)
+	}
 
-	for _, vol := range state {
+	for _, vol := range state.Volumes {
+		if vol == nil || vol.Name == "" {
+			fs.Logf(nil, "Ignoring volume without a name in plugin state")
+			continue
+		}
 		if err := vol.restoreState(ctx, drv); err != nil {
+			// keep the volume so it can be fixed or removed
 			fs.Logf(nil, "Failed to restore volume %q: %v", vol.Name, err)
-			continue
 		}
 		drv.volumes[vol.Name] = vol
 	}
@@ -234,6 +234,10 @@ func getVFSOption(vfsOpt *vfscommon.Options, opt rc.Params, key string) (ok bool
 		vfsOpt.CacheMaxAge, err = opt.GetDuration(key)
 	case "vfs-cache-max-size":
 		err = getFVarP(&vfsOpt.CacheMaxSize, opt, key)
+	case "vfs-cache-policy":
+		err = getFVarP(&vfsOpt.CachePolicy, opt, key)
+	case "vfs-cache-checksum":
+		vfsOpt.CacheChecksum, err = opt.GetBool(key)
 	case "vfs-read-chunk-size":
 		err = getFVarP(&vfsOpt.ChunkSize, opt, key)
 	case "vfs-read-chunk-size-limit":
@@ -250,6 +254,10 @@ func getVFSOption(vfsOpt *vfscommon.Options, opt rc.Params, key string) (ok bool
 		err = getFVarP(&vfsOpt.ReadAhead, opt, key)
 	case "vfs-used-is-size":
 		vfsOpt.UsedIsSize, err = opt.GetBool(key)
+	case "vfs-dir-cache-persist":
+		vfsOpt.DirCachePersist, err = opt.GetBool(key)
+	case "vfs-pin-from":
+		vfsOpt.PinFrom, err = opt.GetString(key)
 
 	// unprefixed vfs options
 	case "no-modtime":
@@ -268,6 +276,8 @@ func getVFSOption(vfsOpt *vfscommon.Options, opt rc.Params, key string) (ok bool
 	case "file-perms":
 		perms := &vfsflags.FileMode{Mode: &vfsOpt.FilePerms}
 		err = getFVarP(perms, opt, key)
+	case "quota":
+		err = getFVarP(&vfsOpt.Quota, opt, key)
 
 	// unprefixed unix-only vfs options
 	case "umask":
@@ -24,6 +24,13 @@ var (
 	ErrMountpointExists = errors.New("non-empty mountpoint already exists")
 )
 
+// Health of a volume as reported in its Status
+const (
+	healthOK       = "ok"       // working normally
+	healthDegraded = "degraded" // usable but something has gone wrong
+	healthFailed   = "failed"   // can't be mounted until fixed
+)
+
 // Volume keeps volume runtime state
 // Public members get persisted in saved state
 type Volume struct {
@@ -41,6 +48,9 @@ type Volume struct {
 	mountType  string
 	drv        *Driver
 	mnt        *mountlib.MountPoint
+	setupErr   error     // why the volume couldn't be set up on restore
+	lastErr    error     // the last thing which went wrong since mounting
+	lastErrAt  time.Time // when lastErr happened
 }
 
 // VolOpts keeps volume options
@@ -78,14 +88,103 @@ func newVolume(ctx context.Context, name string, volOpt VolOpts, drv *Driver) (*
 }
 
 // getInfo returns short digest about volume
-func (vol *Volume) getInfo() *VolInfo {
+//
+// It must be called with drv.mu held, and finish must be called after
+// it has been released to complete the Status - see status.
+func (vol *Volume) getInfo() (info *VolInfo, finish func()) {
 	vol.prepareState()
+	status, finish := vol.status()
 	return &VolInfo{
 		Name:       vol.Name,
 		CreatedAt:  vol.CreatedAt.Format(time.RFC3339),
 		Mountpoint: vol.MountPoint,
-		Status:     rc.Params{"Mounts": vol.Mounts},
+		Status:     status,
+	}, finish
+}
+
+// setError records that err went wrong with the volume
+func (vol *Volume) setError(err error) {
+	if err == nil {
+		return
 	}
+	vol.lastErr = err
+	vol.lastErrAt = time.Now()
+}
+
+// status returns the health of the volume along with its VFS cache
+// usage and quota for the Status of Get and List.
+//
+// The health is degraded if anything has gone wrong since it was
+// mounted, the cache is in trouble or the quota is full. The reasons
+// are listed in Problems.
+//
+// Reading the quota usage may need to list the remote, so it and the
+// health are only filled in by finish, which must be called without
+// drv.mu held so a slow remote doesn't block the other requests.
+func (vol *Volume) status() (status rc.Params, finish func()) {
+	status = rc.Params{
+		"Mounts":  vol.Mounts,
+		"Mounted": len(vol.mountReqs) > 0,
+	}
+	var problems []string
+	health := healthOK
+	degrade := func(format string, args ...interface{}) {
+		if health == healthOK {
+			health = healthDegraded
+		}
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if vol.setupErr != nil {
+		health = healthFailed
+		problems = append(problems, fmt.Sprintf("can't set up volume: %v", vol.setupErr))
+	}
+	if vol.lastErr != nil {
+		degrade("%v", vol.lastErr)
+		status["LastError"] = vol.lastErr.Error()
+		status["LastErrorTime"] = vol.lastErrAt.Format(time.RFC3339)
+	}
+
+	VFS := vol.mnt.VFS
+	if VFS != nil {
+		if stats, ok := VFS.Stats()["diskCache"].(rc.Params); ok {
+			status["Cache"] = rc.Params{
+				"Files":             stats["files"],
+				"BytesUsed":         stats["bytesUsed"],
+				"ErroredFiles":      stats["erroredFiles"],
+				"OutOfSpace":        stats["outOfSpace"],
+				"UploadsInProgress": stats["uploadsInProgress"],
+				"UploadsQueued":     stats["uploadsQueued"],
+			}
+			if outOfSpace, _ := stats["outOfSpace"].(bool); outOfSpace {
+				degrade("VFS cache is out of space")
+			}
+			if errored, _ := stats["erroredFiles"].(int); errored > 0 {
+				degrade("%d files have VFS cache errors", errored)
+			}
+		}
+	}
+
+	quota := vol.mnt.VFSOpt.Quota
+	finish = func() {
+		if quota > 0 {
+			quotaStatus := rc.Params{"Total": int64(quota)}
+			if VFS != nil {
+				_, used, free := VFS.Statfs()
+				quotaStatus["Used"] = used
+				quotaStatus["Free"] = free
+				if free <= 0 {
+					degrade("quota of %v is full", quota)
+				}
+			}
+			status["Quota"] = quotaStatus
+		}
+		status["Health"] = health
+		if len(problems) > 0 {
+			status["Problems"] = problems
+		}
+	}
+	return status, finish
 }
 
 // prepareState prepares volume for saving state
@@ -98,31 +197,55 @@ func (vol *Volume) prepareState() {
 }
 
 // restoreState updates volume from saved state
+//
+// If the volume can't be set up it is marked as failed rather than
+// dropped so it stays in the saved state. Mounting it tries again.
 func (vol *Volume) restoreState(ctx context.Context, drv *Driver) error {
 	vol.drv = drv
 	vol.mnt = &mountlib.MountPoint{
 		MountPoint: vol.MountPoint,
 	}
-	volOpt := vol.Options
-	volOpt["fs"] = vol.Fs
-	volOpt["type"] = vol.Type
-	if err := vol.applyOptions(volOpt); err != nil {
-		return err
-	}
-	if err := vol.validate(); err != nil {
-		return err
+	vol.mountReqs = make(map[string]interface{})
+	if vol.Options == nil {
+		vol.Options = VolOpts{}
 	}
-	if err := vol.setup(ctx); err != nil {
+	if err := vol.configure(ctx); err != nil {
+		vol.setupErr = err
 		return err
 	}
 	for _, id := range vol.Mounts {
 		if err := vol.mount(id); err != nil {
+			vol.setError(err)
 			return err
 		}
 	}
 	return nil
 }
 
+// configure sets up the volume from its saved options.
+//
+// If this fails the saved options are left as they were.
+func (vol *Volume) configure(ctx context.Context) error {
+	saved := vol.Options
+	volOpt := VolOpts{}
+	for key, val := range saved {
+		volOpt[key] = val
+	}
+	for key, val := range map[string]string{"fs": vol.Fs, "type": vol.Type, "path": vol.Path} {
+		if val != "" {
+			volOpt[key] = val
+		}
+	}
+	if err := vol.applyOptions(volOpt); err != nil {
+		vol.Options = saved
+		return err
+	}
+	if err := vol.validate(); err != nil {
+		return err
+	}
+	return vol.setup(ctx)
+}
+
 // validate volume
 func (vol *Volume) validate() error {
 	if vol.Name == "" {
@@ -218,7 +341,7 @@ func (vol *Volume) remove(ctx context.Context) error {
 		return errors.New("volume is in use")
 	}
 
-	if !vol.drv.dummy {
+	if !vol.drv.dummy && vol.mnt.Fs != nil {
 		shutdownFn := vol.mnt.Fs.Features().Shutdown
 		if shutdownFn != nil {
 			if err := shutdownFn(ctx); err != nil {
@@ -263,8 +386,17 @@ func (vol *Volume) mount(id string) error {
 		vol.mountReqs[id] = nil
 		return nil
 	}
+	if vol.setupErr != nil {
+		// try again in case what was wrong has been fixed
+		if err := vol.configure(context.Background()); err != nil {
+			vol.setupErr = err
+			return fmt.Errorf("volume can't be set up: %w", err)
+		}
+		vol.setupErr = nil
+	}
 	if drv.dummy {
 		vol.mountReqs[id] = nil
+		vol.lastErr = nil
 		return nil
 	}
 	if vol.mnt.Fs == nil {
@@ -272,9 +404,11 @@ func (vol *Volume) mount(id string) error {
 	}
 
 	if _, err := vol.mnt.Mount(); err != nil {
+		vol.setError(err)
 		return err
 	}
 	vol.mountReqs[id] = nil
+	vol.lastErr = nil        // a fresh start
 	vol.drv.monChan <- false // ask monitor to refresh channels
 	return nil
 }
@@ -304,6 +438,7 @@ func (vol *Volume) unmount(id string) error {
 	mnt := vol.mnt
 	if mnt.UnmountFn != nil {
 		if err := mnt.UnmountFn(); err != nil {
+			vol.setError(err)
 			return err
 		}
 	}
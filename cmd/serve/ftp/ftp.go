@@ -14,6 +14,7 @@ import (
 	"os/user"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -42,6 +43,9 @@ type Options struct {
 	BasicPass    string // password for BasicUser
 	TLSCert      string // TLS PEM key (concatenation of certificate and CA certificate)
 	TLSKey       string // TLS PEM Private key
+	ExplicitTLS  bool   // use AUTH TLS on ListenAddr rather than implicit TLS
+	RequireTLS   bool   // refuse commands before AUTH TLS
+	ImplicitAddr string // Port to also serve implicit FTPS on if set
 }
 
 // DefaultOpt is the default values used for Options
@@ -66,6 +70,9 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.StringVarP(flagSet, &Opt.BasicPass, "pass", "", Opt.BasicPass, "Password for authentication (empty value allow every password)")
 	flags.StringVarP(flagSet, &Opt.TLSCert, "cert", "", Opt.TLSCert, "TLS PEM key (concatenation of certificate and CA certificate)")
 	flags.StringVarP(flagSet, &Opt.TLSKey, "key", "", Opt.TLSKey, "TLS PEM Private key")
+	flags.BoolVarP(flagSet, &Opt.ExplicitTLS, "explicit-tls", "", Opt.ExplicitTLS, "Use explicit FTPS (AUTH TLS) on --addr instead of implicit FTPS")
+	flags.BoolVarP(flagSet, &Opt.RequireTLS, "require-tls", "", Opt.RequireTLS, "Refuse logins which haven't switched to TLS with AUTH TLS")
+	flags.StringVarP(flagSet, &Opt.ImplicitAddr, "implicit-addr", "", Opt.ImplicitAddr, "IPaddress:Port or :Port to also serve implicit FTPS on")
 }
 
 func init() {
@@ -78,7 +85,7 @@ func init() {
 var Command = &cobra.Command{
 	Use:   "ftp remote:path",
 	Short: `Serve remote:path over FTP.`,
-	Long: `
+	Long: strings.Replace(`
 Run a basic FTP server to serve a remote over FTP protocol.
 This can be viewed with a FTP client or you can make a remote of
 type FTP to read and write it.
@@ -98,7 +105,31 @@ then using Authentication is advised - see the next section for info.
 By default this will serve files without needing a login.
 
 You can set a single username and password with the --user and --pass flags.
-` + vfs.Help + proxy.Help,
+
+#### TLS
+
+If --cert and --key are set then the server uses TLS. By default this
+is implicit FTPS where the client starts TLS as soon as it connects,
+as for the ftp backend's |tls| option.
+
+Use --explicit-tls to serve explicit FTPS on --addr instead, where the
+client connects without TLS and upgrades the connection with the
+|AUTH TLS| command, as for the ftp backend's |explicit_tls| option.
+Clients may still log in without TLS unless --require-tls is set too,
+in which case the server refuses every command other than |AUTH TLS|
+until the client has upgraded.
+
+Some clients can only use one sort of FTPS, so set --implicit-addr,
+e.g. |--implicit-addr :990|, to serve implicit FTPS on that address as
+well as explicit FTPS on --addr.
+
+#### Limitations
+
+The FTP server library rclone uses doesn't let it add commands or
+follow client connections, so the server doesn't support setting
+modification times with |MFMT|, reading hashes with |HASH|, |XSHA1| or
+|XMD5|, or listing the sessions in progress with the remote control.
+`, "|", "`", -1) + vfs.Help + proxy.Help,
 	Run: func(command *cobra.Command, args []string) {
 		var f fs.Fs
 		if proxyflags.Opt.AuthProxy == "" {
@@ -119,26 +150,36 @@ You can set a single username and password with the --user and --pass flags.
 
 // server contains everything to run the server
 type server struct {
-	f      fs.Fs
-	srv    *ftp.Server
-	ctx    context.Context // for global config
-	opt    Options
-	vfs    *vfs.VFS
-	proxy  *proxy.Proxy
-	useTLS bool
+	f           fs.Fs
+	srv         *ftp.Server
+	implicitSrv *ftp.Server     // serving implicit FTPS on ImplicitAddr if set
+	ctx         context.Context // for global config
+	opt         Options
+	vfs         *vfs.VFS
+	proxy       *proxy.Proxy
+	useTLS      bool
 }
 
 var passivePortsRe = regexp.MustCompile(`^\s*\d+\s*-\s*\d+\s*$`)
 
-// Make a new FTP to serve the remote
-func newServer(ctx context.Context, f fs.Fs, opt *Options) (*server, error) {
-	host, port, err := net.SplitHostPort(opt.ListenAddr)
+// splitHostPort splits addr into a host and a port number
+func splitHostPort(addr string) (host string, port int, err error) {
+	host, portString, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, errors.New("failed to parse host:port")
+	}
+	port, err = strconv.Atoi(portString)
 	if err != nil {
-		return nil, errors.New("failed to parse host:port")
+		return "", 0, errors.New("failed to parse host:port")
 	}
-	portNum, err := strconv.Atoi(port)
+	return host, port, nil
+}
+
+// Make a new FTP to serve the remote
+func newServer(ctx context.Context, f fs.Fs, opt *Options) (*server, error) {
+	host, portNum, err := splitHostPort(opt.ListenAddr)
 	if err != nil {
-		return nil, errors.New("failed to parse host:port")
+		return nil, err
 	}
 
 	s := &server{
@@ -152,6 +193,9 @@ func newServer(ctx context.Context, f fs.Fs, opt *Options) (*server, error) {
 		s.vfs = vfs.New(f, &vfsflags.Opt)
 	}
 	s.useTLS = s.opt.TLSKey != ""
+	if !s.useTLS && (opt.ExplicitTLS || opt.RequireTLS || opt.ImplicitAddr != "") {
+		return nil, errors.New("--explicit-tls, --require-tls and --implicit-addr need --cert and --key")
+	}
 
 	// Check PassivePorts format since the server library doesn't!
 	if !passivePortsRe.MatchString(opt.PassivePorts) {
@@ -171,24 +215,66 @@ func newServer(ctx context.Context, f fs.Fs, opt *Options) (*server, error) {
 		TLS:            s.useTLS,
 		CertFile:       s.opt.TLSCert,
 		KeyFile:        s.opt.TLSKey,
+		ExplicitFTPS:   s.opt.ExplicitTLS,
+		ForceTLS:       s.opt.RequireTLS,
 		//TODO implement a maximum of https://godoc.org/goftp.io/server#ServerOpts
 	}
 	s.srv = ftp.NewServer(ftpopt)
+
+	if opt.ImplicitAddr != "" {
+		implicitOpt := *ftpopt
+		implicitOpt.Hostname, implicitOpt.Port, err = splitHostPort(opt.ImplicitAddr)
+		if err != nil {
+			return nil, fmt.Errorf("--implicit-addr: %w", err)
+		}
+		implicitOpt.ExplicitFTPS = false
+		implicitOpt.ForceTLS = false
+		s.implicitSrv = ftp.NewServer(&implicitOpt)
+	}
 	return s, nil
 }
 
-// serve runs the ftp server
+// servers returns the ftp servers in use
+func (s *server) servers() []*ftp.Server {
+	if s.implicitSrv == nil {
+		return []*ftp.Server{s.srv}
+	}
+	return []*ftp.Server{s.srv, s.implicitSrv}
+}
+
+// serve runs the ftp servers until they are closed or one of them
+// fails
 func (s *server) serve() error {
-	fs.Logf(s.f, "Serving FTP on %s", s.srv.Hostname+":"+strconv.Itoa(s.srv.Port))
-	return s.srv.ListenAndServe()
+	servers := s.servers()
+	errs := make(chan error, len(servers))
+	for _, srv := range servers {
+		fs.Logf(s.f, "Serving FTP on %s", srv.Hostname+":"+strconv.Itoa(srv.Port))
+		go func(srv *ftp.Server) {
+			errs <- srv.ListenAndServe()
+		}(srv)
+	}
+	err := <-errs
+	if err != ftp.ErrServerClosed {
+		// stop the others
+		_ = s.close()
+	}
+	for range servers[1:] {
+		<-errs
+	}
+	return err
 }
 
-// close stops the ftp server
+// close stops the ftp servers
 //
 //lint:ignore U1000 unused when not building linux
-func (s *server) close() error {
-	fs.Logf(s.f, "Stopping FTP on %s", s.srv.Hostname+":"+strconv.Itoa(s.srv.Port))
-	return s.srv.Shutdown()
+func (s *server) close() (err error) {
+	for _, srv := range s.servers() {
+		fs.Logf(s.f, "Stopping FTP on %s", srv.Hostname+":"+strconv.Itoa(srv.Port))
+		if shutdownErr := srv.Shutdown(); err == nil {
+			err = shutdownErr
+		}
+	}
+	return err
 }
 
 // Logger ftp logger output formatted message
@@ -18,6 +18,7 @@ import (
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	ftp "goftp.io/server/core"
 )
 
@@ -70,3 +71,34 @@ func TestFTP(t *testing.T) {
 
 	servetest.Run(t, "ftp", start)
 }
+
+func TestTLSOptions(t *testing.T) {
+	f, err := fs.NewFs(context.Background(), t.TempDir())
+	require.NoError(t, err)
+
+	opt := DefaultOpt
+	opt.ListenAddr = testHOST + ":0"
+	opt.ExplicitTLS = true
+	_, err = newServer(context.Background(), f, &opt)
+	assert.ErrorContains(t, err, "need --cert and --key")
+
+	opt = DefaultOpt
+	opt.ListenAddr = testHOST + ":0"
+	opt.TLSCert = "cert.pem"
+	opt.TLSKey = "key.pem"
+	opt.ImplicitAddr = "potato"
+	_, err = newServer(context.Background(), f, &opt)
+	assert.ErrorContains(t, err, "--implicit-addr")
+
+	opt.ImplicitAddr = testHOST + ":990"
+	opt.ExplicitTLS = true
+	opt.RequireTLS = true
+	s, err := newServer(context.Background(), f, &opt)
+	require.NoError(t, err)
+	assert.True(t, s.srv.ExplicitFTPS)
+	assert.True(t, s.srv.ForceTLS)
+	require.NotNil(t, s.implicitSrv)
+	assert.False(t, s.implicitSrv.ExplicitFTPS)
+	assert.Equal(t, 990, s.implicitSrv.Port)
+	assert.Len(t, s.servers(), 2)
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/vfs"
+)
+
+// archiveWriter writes the entries of an archive
+type archiveWriter interface {
+	// addDir adds a directory called name
+	addDir(name string, modTime time.Time) error
+	// addFile adds a file called name returning a writer for its data
+	addFile(name string, size int64, modTime time.Time) (io.Writer, error)
+	// Close finishes the archive
+	Close() error
+}
+
+// zipWriter writes a zip archive
+type zipWriter struct {
+	*zip.Writer
+}
+
+func (w zipWriter) addDir(name string, modTime time.Time) error {
+	_, err := w.CreateHeader(&zip.FileHeader{
+		Name:     name + "/",
+		Method:   zip.Store,
+		Modified: modTime,
+	})
+	return err
+}
+
+func (w zipWriter) addFile(name string, size int64, modTime time.Time) (io.Writer, error) {
+	return w.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	})
+}
+
+// tarWriter writes a tar archive
+type tarWriter struct {
+	*tar.Writer
+}
+
+func (w tarWriter) addDir(name string, modTime time.Time) error {
+	return w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     0755,
+		ModTime:  modTime,
+	})
+}
+
+func (w tarWriter) addFile(name string, size int64, modTime time.Time) (io.Writer, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("can't add %q of unknown size to a tar archive", name)
+	}
+	err := w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0644,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.Writer, nil
+}
+
+// serveArchive serves the directory dir as a zip or tar archive made
+// as it is sent.
+func (s *server) serveArchive(w http.ResponseWriter, r *http.Request, dir *vfs.Dir, opt *listOpt) {
+	name := path.Base(dir.Path())
+	if dir.Path() == "" {
+		name = "rclone"
+	}
+	name += "." + opt.format
+	if opt.format == formatZip {
+		w.Header().Set("Content-Type", "application/zip")
+	} else {
+		w.Header().Set("Content-Type", "application/x-tar")
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	if r.Method == "HEAD" {
+		return
+	}
+
+	fs.Infof(dir.Path(), "%s: Serving directory as %s", r.RemoteAddr, name)
+	var aw archiveWriter
+	if opt.format == formatZip {
+		aw = zipWriter{zip.NewWriter(w)}
+	} else {
+		aw = tarWriter{tar.NewWriter(w)}
+	}
+	ctx := r.Context()
+	err := opt.walk(ctx, dir, func(node vfs.Node, rel string) error {
+		if node.IsDir() {
+			return aw.addDir(rel, node.ModTime())
+		}
+		return archiveFile(ctx, aw, node, rel)
+	})
+	if err != nil {
+		// The headers have been sent so all we can do is stop
+		fs.Errorf(dir.Path(), "Failed to make %s: %v", name, err)
+		return
+	}
+	err = aw.Close()
+	if err != nil {
+		fs.Errorf(dir.Path(), "Failed to finish %s: %v", name, err)
+	}
+}
+
+// archiveFile adds the file node to the archive as rel
+func archiveFile(ctx context.Context, aw archiveWriter, node vfs.Node, rel string) (err error) {
+	obj, ok := node.DirEntry().(fs.Object)
+	if !ok {
+		fs.Logf(node.Path(), "Can't add file being written to archive")
+		return nil
+	}
+	file, ok := node.(*vfs.File)
+	if !ok {
+		return fmt.Errorf("expecting *vfs.File, got %T", node)
+	}
+	in, err := file.Open(os.O_RDONLY)
+	if err != nil {
+		return err
+	}
+	defer fs.CheckClose(in, &err)
+
+	// Account the transfer
+	tr := accounting.Stats(ctx).NewTransfer(obj)
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+
+	out, err := aw.addFile(rel, node.Size(), node.ModTime())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -21,14 +21,14 @@ var Assets = func() http.FileSystem {
 	fs := vfsgen۰FS{
 		"/": &vfsgen۰DirInfo{
 			name:    "/",
-			modTime: time.Date(2020, 5, 5, 16, 40, 6, 115915195, time.UTC),
+			modTime: time.Date(2026, 10, 16, 10, 12, 4, 503122717, time.UTC),
 		},
 		"/index.html": &vfsgen۰CompressedFileInfo{
 			name:             "index.html",
-			modTime:          time.Date(2020, 5, 5, 16, 40, 5, 919909715, time.UTC),
-			uncompressedSize: 15424,
+			modTime:          time.Date(2026, 10, 16, 10, 12, 4, 311270958, time.UTC),
+			uncompressedSize: 16017,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x7b\x6d\x73\xdb\x46\xf2\xe7\x6b\xe9\x53\x4c\x98\xcd\x8a\x4a\xc0\xe1\x3c\x3f\x48\xa4\xf6\x6c\xc6\x59\xbb\x56\x71\x52\xb1\x9d\xad\x6c\x2a\x2f\x20\x62\x48\xe2\x0c\x02\x0c\x00\xea\xc1\x3a\x55\xdd\x87\xb8\x4f\x78\x9f\xe4\xaa\x67\x00\x12\x90\x28\x27\x7b\xf5\xdf\xbf\xec\xa2\x80\x9e\x99\x9e\x7e\xf8\x75\x4f\x37\x08\x4d\xbe\x18\x8d\xd0\xf1\x78\x8c\x66\xc5\xe6\xae\x4c\x97\xab\x1a\x31\x42\x25\xfa\x3e\xae\xeb\x95\xbb\x41\xaf\x8b\xac\x46\x71\x9e\xa0\xf7\x2b\x87\x66\x71\x92\xdc\xa1\x17\xdb\x7a\x55\x94\xd5\xf1\x78\x0c\xeb\x2e\xd3\xb9\xcb\x2b\x97\xa0\x6d\x9e\xb8\x12\xd5\x2b\x87\x5e\x6c\xe2\xf9\xca\xb5\x23\x11\xfa\xd9\x95\x55\x5a\xe4\x88\x61\x82\x86\x30\x61\xd0\x0c\x0d\x4e\xcf\x81\xc5\x5d\xb1\x45\xeb\xf8\x0e\xe5\x45\x8d\xb6\x95\x43\xf5\x2a\xad\xd0\x22\xcd\x1c\x72\xb7\x73\xb7\xa9\x51\x9a\xa3\x79\xb1\xde\x64\x69\x9c\xcf\x1d\xba\x49\xeb\x95\xdf\xa7\xe1\x82\x81\xc7\x2f\x0d\x8f\xe2\xaa\x8e\xd3\x1c\xc5\x68\x5e\x6c\xee\x50\xb1\xe8\x4e\x44\x71\xdd\x08\x0d\x3f\xab\xba\xde\x9c\x8d\xc7\x37\x37\x37\x38\xf6\x02\xe3\xa2\x5c\x8e\xb3\x30\xb5\x1a\x5f\xbe\x99\xbd\x7a\xfb\xee\xd5\x88\x61\xd2\x2c\xfa\x90\x67\xae\xaa\x50\xe9\x7e\xdf\xa6\xa5\x4b\xd0\xd5\x1d\x8a\x37\x9b\x2c\x9d\xc7\x57\x99\x43\x59\x7c\x83\x8a\x12\xc5\xcb\xd2\xb9\x04\xd5\x05\x08\x7d\x53\xa6\x75\x9a\x2f\x23\x54\x15\x8b\xfa\x26\x2e\x1d\xb0\x49\xd2\xaa\x2e\xd3\xab\x6d\xdd\xb3\x59\x2b\x62\x5a\xf5\x26\x14\x39\x8a\x73\x34\x78\xf1\x0e\xbd\x79\x37\x40\x2f\x5f\xbc\x7b\xf3\x2e\x02\x26\xff\x7c\xf3\xfe\xf5\x0f\x1f\xde\xa3\x7f\xbe\xf8\xe9\xa7\x17\x6f\xdf\xbf\x79\xf5\x0e\xfd\xf0\x13\x9a\xfd\xf0\xf6\xdb\x37\xef\xdf\xfc\xf0\xf6\x1d\xfa\xe1\x3b\xf4\xe2\xed\x2f\xe8\x1f\x6f\xde\x7e\x1b\x21\x97\xd6\x2b\x57\x22\x77\xbb\x29\x41\x83\xa2\x44\x29\x58\xd3\x25\xde\x74\xef\x9c\xeb\x89\xb0\x28\x82\x48\xd5\xc6\xcd\xd3\x45\x3a\x47\x59\x9c\x2f\xb7\xf1\xd2\xa1\x65\x71\xed\xca\x3c\xcd\x97\x68\xe3\xca\x75\x5a\x81\x57\x2b\x40\x07\xb0\xc9\xd2\x75\x5a\xc7\xb5\x27\x3d\xd1\x0b\xa3\xe3\xef\x8b\x04\xb8\x85\x19\x67\x08\xbd\x48\xe2\x4d\x1d\x4c\x55\xce\xb3\x22\x77\x68\x1d\x97\x1f\xb7\x1b\x34\x1a\x5d\x1c\x1f\x4f\xbe\xf8\xf6\x87\xd9\xfb\x5f\x7e\x7c\x85\x56\xf5\x3a\xbb\x38\x9e\x84\x5f\x47\x93\x95\x8b\x93\x8b\xe3\xa3\xa3\x49\x9d\xd6\x99\xbb\xb8\xbf\x87\x01\x84\xdf\xc6\x6b\xf7\xf0\x30\x19\x07\x2a\x8c\xaf\x5d\x1d\xa3\xf9\x2a\x2e\x2b\x57\x4f\x07\xdb\x7a\x31\x32\x83\xfd\x40\x1e\xaf\xdd\x74\x70\x9d\xba\x9b\x4d\x51\xd6\x03\x34\x2f\xf2\xda\xe5\xf5\x74\x70\x93\x26\xf5\x6a\x9a\xb8\xeb\x74\xee\x46\xfe\x26\x42\x69\x9e\xd6\x69\x9c\x8d\xaa\x79\x9c\xb9\x29\xc5\xe4\x09\xa3\x65\x51\x2c\x33\xd7\x61\x93\x17\x75\x19\xe7\x55\x16\xd7\x6e\x70\x71\x3c\xa9\xea\x3b\x10\xeb\x6b\x74\x8f\x36\x71\x92\xa4\xf9\xf2\x0c\x91\x73\xd0\x78\x99\xe6\xfe\xf2\xe1\xf8\xaa\x48\xee\xd0\xfd\xf1\xd1\xa2\xc8\xeb\xd1\x22\x5e\xa7\xd9\xdd\x19\xaa\xe2\xbc\x1a\x55\xae\x4c\x17\xe7\xc7\x47\xb5\xbb\xad\x47\xa5\x03\xe3\x7a\x0e\xc5\xa6\x4e\xd7\xe9\x27\x57\x6d\x9c\x4b\xce\x8f\x8f\xae\xe2\xf9\xc7\x65\x59\x6c\xf3\x64\x34\x2f\xb2\xa2\x3c\x43\x5f\x2e\xfc\xcf\xf9\xf1\xc3\x71\x0c\xbc\x5b\x32\x21\xca\x25\xbc\x65\x99\xb8\x79\x51\x7a\xc7\x9c\xa1\xbc\xc8\x9d\x9f\x7e\xb6\x02\x6f\x47\xc7\x2b\x8a\x9a\xeb\x2e\x03\x4e\xed\x3c\xf0\x05\x87\xc0\xbc\x2f\xab\xed\x7a\x1d\x97\x5e\x85\x46\xc7\x51\xe6\x16\xf5\x19\x92\x5f\x9d\xef\x49\x3e\xc7\x04\xda\xc3\x71\xbd\x3a\x5b\xa4\x65\x55\x8f\xe6\xab\x34\x4b\xa2\xe3\x3a\xe9\xde\x03\x27\xef\x81\x33\x44\xbf\x3a\x47\xe3\xaf\x51\x0d\x8b\x5d\xe9\x21\xba\x2e\xae\x20\x45\x7c\x3d\x0e\x7c\xb2\xb8\xc7\x66\x7f\xfb\xe7\xb9\x04\x4d\xba\xf2\xd7\xc5\xe6\x0c\x31\xb9\xb9\xed\x28\x70\x55\xd4\x75\xb1\x3e\x43\x34\x90\x0f\xd9\x9c\xc1\x3f\x6f\x1b\xba\x73\x68\x95\x7e\x72\x67\x88\x11\xbf\xc8\x53\x6e\x5c\x30\x45\x5e\x94\xeb\x38\x3b\x3f\x3e\xba\x59\xa5\xb5\x1b\x55\x9b\x78\xee\x80\x7a\x53\xc6\x9b\xf3\xe3\x23\xb0\xfc\x22\x2b\x6e\x46\xb7\x67\x68\x95\x26\x89\xcb\x5b\xb7\xb5\x23\x67\xc8\x65\x59\xba\xa9\xd2\xea\x7c\xef\x20\x6b\x6d\x23\xc1\x23\xc7\x93\xf3\xe3\xa3\x1d\xee\x90\x00\x79\x1e\x1e\x39\xf9\x09\x28\x7c\x3c\x67\x69\x40\x86\x9f\xfb\xc8\x4d\x7b\x20\x1f\x3f\x1c\xaf\x21\x03\xdf\x1f\x1f\x25\x69\xb5\xc9\xe2\xbb\x33\x74\x95\x15\xf3\x8f\x30\x82\x7d\xc8\xf4\x4d\x42\xd9\xde\x24\x2d\xea\x7f\x76\x65\x12\xe7\x71\xd4\x87\xff\x55\x51\x26\xae\xdc\x3b\x60\x73\x8b\xaa\x22\x4b\x13\xf4\xa5\x9d\xc1\xbf\xf3\x47\x8e\xa3\xe4\xb0\xe3\x48\xd0\xd9\x0b\x33\x4a\x6b\xb7\xde\x6b\xd0\xc2\x93\xba\x35\x4c\xf9\x72\x91\x66\x75\x0f\x12\x67\xc1\x62\x8d\x2c\x3d\x21\x66\xb3\x99\xc7\xb4\x3f\x0e\x3a\xa0\x23\xe4\xab\xbd\xf0\xf3\x22\xcb\xe2\x4d\xe5\xce\x50\x7b\xe5\xd7\xf8\x2d\x0e\xe8\x97\xc4\xd5\xca\x25\xe8\xcb\x24\x86\x7f\x7e\xaa\x4f\x13\x75\xb9\xf7\xd6\x33\x51\xef\xe6\x21\xc2\x20\x1c\x76\x4e\x8d\xb3\x74\x99\x9f\x21\x88\xcb\xf3\x8e\x4e\x60\x12\x04\x7e\x80\xf0\x98\xad\xe2\x7c\xe9\x12\xb4\x28\x8b\x35\x22\x90\x9f\x59\x1b\x65\x4f\x62\xe3\xb3\x26\xee\x79\x59\x79\xca\x41\x88\x7b\xce\x5d\x94\x5e\x65\x71\xc0\x4b\xbd\x42\xd5\xf5\x12\x46\xae\x5d\x59\xa7\xf3\x38\x6b\x35\x58\xa7\x49\x92\x05\xdb\x85\x08\x3f\x18\x3b\x5d\x01\x1a\xa4\xd7\xc9\x59\x5e\xaf\x02\x72\x87\xec\xb4\xe3\x28\x43\xbe\x7a\x32\x81\x9f\xf6\x7c\x4f\x7c\x00\x37\xbf\x9a\x04\xb6\x9f\x2c\x4e\xa3\xfe\x6a\x71\xfa\xd8\xf0\x1e\x5e\x87\xc4\x68\xd4\xdc\x14\x55\x1a\x42\x2e\xbe\xaa\x8a\x6c\x5b\xb7\x2a\x62\x38\x67\xbc\x2b\xf1\xb2\xd8\x6e\x3a\x88\x0d\x39\x96\x62\x2d\x01\xb3\x47\x37\x45\x99\x8c\xae\x4a\x17\x7f\x3c\x43\xfe\xd7\x28\xce\xb2\x6e\x1a\x01\xd3\xb4\x43\x30\xf9\xb1\x57\x36\xa5\x1b\xb5\x7e\xc1\xe9\xbc\xc8\x9f\x46\x87\x6c\x02\x08\x46\x71\x55\x94\x75\x2f\xda\xd3\x1c\x32\xc5\xa8\x09\xfa\x5d\x18\x78\xe9\x56\xae\x13\x5f\x1d\x6d\x4b\x97\xc5\x75\x7a\xed\x20\xb5\x01\xae\x30\x0b\x01\xd8\xd9\x02\xd7\xc5\xe6\x39\x13\x1d\x05\x23\x90\x76\xf9\x88\x3e\x91\x10\x07\x6c\x3e\xcb\xa1\x85\x6e\x58\xba\x67\xf8\x70\xbc\x28\x8a\x27\x39\xc0\xc7\xcb\x53\x90\x87\x54\xd6\x75\xf8\xdc\xe5\xb5\x2b\x81\xcd\xff\x58\xbb\x24\x8d\xd1\x70\x1d\xdf\x8e\x1a\x9b\x28\x42\x36\xb7\x80\x91\xf1\xd7\x47\x78\x95\x26\xae\x4d\x1d\x7b\x63\x86\xe3\xf8\xe8\x01\x95\x6e\x5d\x5c\x87\x72\xe9\xa3\x73\x1b\x94\xc4\xb5\xab\xa0\x3e\xdc\x9f\x60\x47\x07\xb0\xdd\x9a\x3f\xde\xd6\x05\xf0\x39\x3e\xea\x41\x96\x9f\x46\x8f\x96\x05\xc4\x1f\x3a\xae\x8f\x0e\x21\x19\x38\x86\x53\xee\xd1\x11\x13\xe8\x3e\xaa\xbb\xa7\x03\xd0\x3b\x59\xf5\xa8\x63\x0d\x4a\x82\x41\x1f\x8e\x1f\x8e\x27\xe3\xa6\x62\x3a\x9a\x8c\x9b\x8a\x6f\xe2\x13\x5f\x91\x67\x45\x9c\x4c\x4f\x02\x8b\xe1\xe9\x79\x5d\x2c\x97\x99\x1b\x0e\x7c\xee\x1c\x9c\x9e\xcf\x7d\xf6\x7a\x97\x7e\x72\xc3\xd3\x13\x5f\xa6\x41\x68\x5d\x87\x16\x64\x3a\xa0\x98\x0e\xd0\xed\x3a\xcb\xab\xe9\xa0\xd3\x01\xdc\x70\x5f\xfd\x33\x42\xc8\xb8\xba\x5e\x36\x53\xce\x6e\xb3\x34\xff\x78\x68\x22\xb5\xd6\x8e\xfd\xe8\x00\x05\x4c\x4f\x07\x64\x80\x42\xf1\x08\x57\x5e\xfc\xe9\xe0\x00\xd4\x7c\xed\x78\x34\x49\xdc\xa2\xf2\x57\x47\xbe\x05\xfb\xae\xc8\xa0\xf6\x80\xda\xd7\xd3\x96\x28\x4d\xa6\x83\x85\xa7\x0e\xa0\x19\xca\x46\xe5\x16\x38\xe6\x45\xfe\xc9\x95\x45\xa0\xf9\x5b\x17\x38\x1e\x1d\x4d\x36\x71\xbd\x42\xc9\x74\xf0\x3d\x33\x12\x33\x86\xb8\xc6\x52\xae\x46\x54\x30\xac\x2e\x29\x25\xd8\x22\xf2\x9a\x53\xac\x67\x54\x60\x26\x11\x41\x04\x51\x05\xd4\x30\xf5\x5a\x4b\x4c\x57\x1c\x48\xec\x67\xb8\x9e\x93\x11\x23\x58\xc9\x11\xcc\x57\x23\x3f\x69\x04\x0c\xc2\xe5\xa7\x56\x8a\x2f\xbf\xfb\xee\x05\x21\x64\x30\x7e\x56\x12\xd5\xdd\x97\x2b\x44\x90\x24\x98\x19\x44\x90\xd2\x58\x8b\x6b\x2a\x0d\xd6\x73\x82\xa8\xc6\x42\x23\xbf\x1d\x82\x15\xd2\x7f\x86\xcb\xd7\x9e\xd9\x1c\xa6\x08\x10\x19\xe4\xa0\x02\xf3\x70\xe5\xa7\xfc\x0c\xdc\xe4\x9c\x8c\x3c\x9f\x56\x6c\x18\x19\xed\x27\x75\xc5\x9e\xbd\x60\xa6\x15\x7b\x32\x5e\x1e\xb0\xfe\xa8\x5a\x15\x65\x3d\xdf\xd6\xe0\xd4\xb2\xf8\xe8\x1a\xa3\x37\x77\xa3\xc6\xe7\xb4\xe7\x91\xae\xc7\xdc\xb5\xcb\x8b\x24\xd9\x79\xe9\x20\xf3\x11\x9c\xe0\x9b\x83\x9e\x6e\xd6\x3d\xb7\xb0\x5a\xc5\x9b\x1d\x04\x9e\x9a\x5e\x18\xad\x22\xf0\x96\x30\xca\x12\x86\x2e\x3d\x1a\x28\x13\xdc\xf4\xc9\x00\x0f\x46\xb4\x91\x11\x41\x97\x9c\x62\x65\xa9\x92\xcc\x46\x04\x79\xaf\x35\x4b\x08\x22\x11\x55\xd8\x58\x65\x29\x51\x88\xf4\x78\x90\x88\x52\x86\x95\x50\x44\x53\xe0\xa1\x70\xc3\xe3\x19\xb2\x96\x98\x58\xcd\x0d\x91\x68\xd6\x21\x4b\x81\x85\x90\x8a\x10\x83\x38\x61\x58\x49\xc9\x8c\xec\x6e\x74\x58\xb3\x7f\x0d\xbc\x7d\xde\x79\x7b\x3c\x02\xe6\xc5\x64\x0c\x76\xf9\x03\x2b\xa9\x9e\xe2\x5c\xf5\x34\x07\xd0\x46\x1e\xb4\xdc\x48\x65\x10\x89\x3c\x72\xa9\x25\xdc\x82\xea\x8c\x29\x2c\x24\x15\x4c\xa0\x19\x89\x98\xe0\xd8\x12\x2b\x34\x45\x1d\x1e\x4c\x1a\x4c\x2d\xe7\xcc\xa0\xce\x46\x1d\xea\x65\x47\x9c\x0e\x79\xd6\xb1\x43\x8f\xc7\xce\x66\x9d\xfd\xba\xd4\xbd\x4c\x5d\xbb\x77\x04\xef\xd9\x7d\xaf\x5c\xd7\xee\x0a\xf5\x6d\xf4\x8c\x9d\x7d\x24\x3d\xb2\xf3\x2e\xa2\xba\x16\xa7\x4c\x61\x2a\x05\xe5\x22\x62\x92\x60\x29\x2d\x35\x02\xcd\x80\x6c\x24\xb1\x1a\xc8\x14\x1b\xc3\x95\xe6\x88\x32\x8d\x39\x21\x52\x80\x99\x38\x26\x44\x51\xc6\x3c\x55\x6b\xa6\x08\x8b\x98\x14\x98\x06\xea\x8c\x32\x83\x85\xb2\x42\x00\x59\x62\xd6\x4e\x36\xd8\x52\x4b\x28\x98\x54\x61\x4a\x04\x31\x40\xb5\x58\x71\xc3\x39\x58\x54\x63\x42\x18\x11\x14\xcd\x28\xf7\x12\x59\xc5\xbc\xa1\x39\x53\x92\x53\x44\x21\x6f\x30\x63\x24\x4c\xb6\x88\x72\x8e\x29\x21\x44\x6a\x7f\x3b\xa3\x5c\x60\x61\xb9\xe6\xba\x19\x96\x58\x50\xc9\x95\xf0\x3c\xa4\xa4\x84\x21\xca\x15\xa6\x94\x31\x22\xfc\x7e\x4a\x4b\xe9\xb7\x53\xd8\x10\x4b\x84\xe8\x4a\x41\xb9\xc6\x4c\x1a\x45\xad\xd7\xc3\x1e\xa0\x0a\x2c\x75\xcb\xa2\x43\x06\x10\x04\xf5\xba\x54\x86\xcd\x9e\x4a\x38\x37\x9c\x79\x1b\x0b\xa9\xa9\xe0\x41\x0a\x6d\x94\x54\x2a\x62\xc2\x62\x4b\x0c\x55\xdc\x4b\x2c\x15\xd5\xda\x7a\x2a\xf1\xb6\xe8\x53\x0d\x96\xc1\x4d\x9e\x05\x31\x56\x33\x60\xc1\xb0\xa1\x82\x19\xe5\x2d\x61\x94\xb0\xdc\x46\x8c\x6b\xc8\x2f\x82\x98\x3e\x95\x63\xa6\xb9\x50\xde\x8a\x7b\x32\x93\x98\x04\xe1\xba\xb2\x51\x8d\x65\xcb\xd8\x60\x6a\x08\x13\x40\x25\xd8\x08\x65\xb9\x67\x61\xb1\xb6\x46\x53\x1e\x31\x22\x30\x6b\x0c\x27\x00\x4e\x96\x71\x11\x51\x6b\xb0\x82\xed\x04\xa2\x42\x60\xc1\x2c\x67\x26\xa2\x96\x63\xad\x40\x3f\x88\x78\x8d\x19\x55\xca\xd8\x88\x1a\x83\x8d\xb2\xdc\x18\x44\x25\xc1\x4a\x1b\x41\x69\x44\x8d\xc0\x26\x88\x4c\xa5\xc0\x86\x2b\xab\x79\x44\x0d\x6d\xc1\x32\x83\xb3\xcc\x5a\x29\xb9\x8c\xa8\x06\xa0\x5a\x69\x19\xa2\x8a\x63\xc5\x14\x15\x36\xa2\x5a\xec\xf0\xad\x0c\x16\x86\x4a\xc9\x22\xaa\x19\x56\x80\x58\x08\x06\xcd\x31\xe7\xca\x4a\x11\x51\x4d\xb0\xe0\x46\x6b\x85\xa8\xb6\x98\x52\x6e\x0d\x8f\x60\x9d\x52\x92\x13\x85\xa8\x91\x58\x1a\x6d\x80\x85\xd2\x98\x0b\x70\x1f\x9a\x51\xcb\x30\x51\x54\x33\x20\x2b\xcc\x28\x6c\x88\xc0\x00\x5a\x11\xae\x4d\x44\x95\xc4\x5c\x30\x23\x35\x62\x44\x7a\x29\xa8\x88\xa8\x12\x58\x05\xa5\x67\x8c\x32\xb0\x32\xd5\x9e\xca\x82\xf7\x18\xb5\x58\x5a\x43\x85\x8a\x40\x25\x6b\x21\x7e\x11\x63\x06\x53\xc5\xbc\xce\x7b\xea\x25\x13\x0a\x13\x09\x21\xfe\x2c\xd9\xca\xd6\xa9\xb3\x1e\x59\x63\x0d\x16\x92\x08\xa8\x5a\x32\xc1\x81\x6a\x31\x44\x13\x11\x08\xc0\xc7\x35\x31\xd6\x46\x8c\x50\x4c\x9a\x2c\x02\x19\x85\x51\x88\xbe\x88\x41\x0e\x0b\x50\x86\x10\x20\xda\x1a\xa3\x22\x46\x38\x96\x21\x31\x40\x14\x71\xcd\x34\x95\x5d\xea\x0c\x92\x84\x50\x9c\xf1\x47\x93\x0d\x96\x9c\x32\xad\x7b\x8c\x15\xc1\x60\x62\xc6\xbb\x52\x5c\x72\x48\x71\x84\x31\x00\x11\xd7\x58\x5b\x80\x00\x9a\x71\x48\x5b\x8c\x68\xa9\x23\x80\x35\x13\xc6\x1a\xc4\x99\xc1\x4a\x30\x6e\x44\xe4\xf3\x88\x8f\xea\x1e\x91\x61\x06\x8e\xa6\xc0\xa0\x43\x26\x98\x00\x95\xa1\x2e\x5b\x66\x30\x0b\x81\xd3\x95\x81\x29\xac\x83\xc0\x97\x1d\x89\x15\xc7\x42\xb4\xae\xf6\x89\x8a\x6b\xa9\x22\x45\xb1\xb1\x01\xb3\x1d\x53\x28\x1a\xec\x65\x25\xb5\x02\xee\x66\x1d\xa3\xfa\x41\x82\x19\x57\x8a\xb3\x1e\x03\xf0\x92\xe5\x5c\xeb\xfe\x6e\xe0\x52\x2d\x2c\x8d\x94\x68\x40\x21\xbc\x9f\x89\x36\x44\x47\x4a\x61\x0d\x33\xb5\xe9\x12\x21\xa8\x02\x88\x2f\xf7\x54\x4a\x48\x8b\x88\xcb\x2e\x06\xf7\xe4\x19\xa0\xdf\x28\x4e\xc0\x68\x7b\x32\xe4\x7f\xaa\x14\x33\x2c\xa2\x54\x63\xaa\x34\x87\xc2\x93\x4a\xcc\xb4\x10\x5c\x47\x10\xf2\xa2\x3d\x9b\x28\xc1\x4a\x29\x4e\x00\xc6\x14\x2a\x0e\x6b\x10\x25\x06\x73\x49\xac\xe5\x11\xd5\x12\xf3\x86\x6f\x87\x6a\x29\xd6\x21\xc0\x66\x1d\x32\x04\x1b\x6b\x72\x02\x85\x84\x1d\xa0\xc6\x38\xb6\x10\xfc\x12\x51\x26\x20\x46\x85\x14\x11\x13\xba\x0d\xfe\x19\x85\xa4\x48\xb4\x66\x3e\xf1\xd2\x76\xae\x84\x34\xce\xac\x08\x49\xba\x55\xee\xd0\x11\xfb\xcc\xc1\x0d\x3f\x03\xe4\x1f\x57\x2f\x8a\x72\x3d\x1d\xec\x9e\x5c\x0f\x19\x35\x58\x58\x9f\x0c\x11\x55\x04\x13\xff\x73\x8a\xfc\x83\xf0\xe1\x88\x46\x88\x9e\xa2\xfd\xf4\x51\x77\xfe\xa8\xbb\xe0\x51\x61\xb0\xaf\xb4\x77\x17\xbe\x09\x82\x46\xf6\x71\x0b\x94\x66\x6e\x5f\x79\x43\x73\xf9\xb8\xf2\x66\xb2\xab\xcc\xc1\xd2\xbb\x5d\x91\xa5\xb9\x9b\xc7\x9b\xe9\xc0\x3f\x2e\xeb\x91\xff\x67\x91\xe6\x2d\xfd\x49\x17\x43\x39\x62\x02\x53\x76\xcd\x34\xb8\x66\x4e\x90\xc2\x54\x21\x89\x0d\x40\x06\x53\x38\x59\x31\x6d\xae\x5f\x33\x6e\xe7\x1a\x73\x68\xae\x80\x3a\x12\xd8\xaa\xe6\xd2\x4f\xf8\xd9\xd7\x02\xf2\x1d\x44\x36\x0c\xf8\x0a\x85\x6b\x44\xf9\x6b\xf0\x9b\x9e\x51\xe3\x19\x73\xff\x5f\x87\xd5\x41\x80\x4f\x07\x5a\x2c\x40\xb2\x5f\x7d\x49\x99\x0d\x90\x82\x46\x8a\x60\x69\x90\x86\x3e\x8a\x5a\x4c\xa1\xcf\x63\xda\x5f\xbe\x66\xc2\x5e\xee\x16\x7d\x7a\xb6\xfb\x49\x33\xf7\x9f\xea\x7d\xba\xac\xdb\xce\xe7\x20\x00\x29\x6f\x20\x14\xa1\xdd\xe5\xe9\x93\x8e\xa8\xc7\x2e\xf4\x43\x3d\xc4\xfc\x21\x68\x3c\x6e\xfe\xbf\x30\xd2\x75\x04\xb4\x3f\x98\x32\x2a\x8c\x51\xbe\x23\x30\x00\x10\x23\xb4\xf6\x1d\x01\x9c\xc7\xdc\x5a\x26\x3c\x6e\x84\x35\xbe\xc8\xb7\x0a\x5b\x6b\xad\xe1\x01\x21\xcc\x70\xcd\xbb\xd4\x4b\xa8\x85\xac\xd5\x70\xe8\x77\xc8\x33\x5f\x39\x59\xe9\xcb\xe5\x3d\x99\x71\x8b\xa9\x26\x86\xed\xb6\x13\xac\x4b\xdc\x4b\x74\xb9\xa7\x52\xc6\x31\x15\x32\x64\xe6\x43\x54\x0a\x47\xbe\x91\x82\x46\x0c\x1b\xc1\xa8\x26\x56\xb8\x11\x15\x3e\x5d\x72\x65\x05\x1c\x7f\xfd\x91\xcb\x46\x1b\x09\x3a\xf6\x87\x66\x20\x83\x24\xc4\x12\x1d\x8d\x28\xd6\x54\x68\x6b\x0d\x73\x23\x22\x11\x89\x20\x58\x08\x63\xd6\xc2\x4d\xc7\x9e\x4d\xf2\x2a\xdd\xbc\xa6\x54\xd3\xcf\x74\x74\x94\x2a\xa8\x0c\x88\x6f\x64\x29\x55\x3e\xeb\x5b\x22\xac\xf2\x99\x5c\x45\x94\x52\x2c\x0c\x9c\x55\x08\x94\x64\xd2\x10\x62\x22\xca\x20\x5e\x19\x94\xa3\x70\x5c\xc1\xed\x25\x24\x66\x7f\xf1\x98\xe7\xee\xa6\x2b\x96\xb6\xe2\x4f\x35\x40\x42\x63\x43\x38\x05\x73\x5a\x81\x49\x38\xe8\x66\x02\x52\xa7\xb5\x86\x02\x59\x62\x1a\xca\x7b\x61\xb0\x15\x56\x4a\x19\xbc\x4f\x42\x01\x25\x2c\x16\x8c\x2a\xb2\xc3\x84\xa7\xce\x24\xc1\x94\x1a\x21\x0c\x60\x42\x63\x13\xc8\x70\x00\x28\x43\x18\xd3\x11\xf3\xe5\xaf\x2f\x1a\x24\xc5\x0c\xca\x58\x28\x7e\xac\xc5\x3c\xd4\x92\x33\xc9\x30\x23\xc6\x2a\x63\x22\x4e\x08\x16\xfe\xa4\x93\x1c\x73\xad\x7d\x33\xc1\x09\x45\x52\x60\x2d\x2c\x51\x5c\xf8\xdb\x19\x34\x55\x82\x69\xc1\x55\x18\xd6\x98\x28\xc1\x35\xb1\x9e\x85\x0a\x7d\x83\xd4\x58\x2b\xca\xbc\x76\x16\x3a\x4e\xce\x34\x9a\x49\x83\x85\x34\x44\x36\xe4\x46\x0a\xa8\x9f\x89\x56\x0c\x0e\x40\x0b\x2d\xdd\x53\xaa\xc6\x1c\xea\x19\xee\x59\xec\xc9\x0a\x9b\x46\xbd\x2e\x55\x62\xbb\xa3\x2a\x03\x3d\x2e\xf3\xa6\x37\x80\x4f\x1a\xa4\xe0\x52\x6a\x06\x93\x39\xb4\x37\x50\x84\x4b\x83\x19\x25\xbe\xae\x86\x60\x32\x8d\x2d\xfa\x54\xd1\x74\x61\xa0\x1e\x37\x9a\x43\x24\x18\x8d\x75\xa8\xc1\x24\x34\x2c\xdc\x0a\x49\x23\x66\x38\xd6\xa1\x8c\xeb\x52\xb5\xc5\xd6\xf7\x87\xb3\x1e\x95\x63\x16\x64\xeb\x8a\xa6\x74\xdb\x14\x49\x8b\x0d\xb3\x4c\x42\xb7\xa5\x28\x56\x4d\xf3\xaa\x28\x16\xc2\x17\x08\xe0\x92\x60\x35\xc5\xb1\xe4\x86\x09\xc2\x7d\xcb\xa7\x42\x81\xa0\x7c\xfd\xc4\x39\xb4\xd5\x42\x63\xc5\x84\xb0\x68\xa6\xa0\xdf\x91\xbe\xeb\x60\x02\xba\x15\x5f\xf0\x6b\x86\x39\xd3\x82\xfa\xc2\x83\x60\xee\xc5\xd5\x0a\x0b\x23\xad\xb6\xcc\x77\x76\xc1\x36\x33\x43\xb0\x12\x42\x0a\x0a\x54\x81\x65\x68\xcb\x0c\xd4\x3b\x92\x4a\x45\x23\xc6\x59\x8b\x6c\x4b\x30\xe5\x44\x4a\xf0\x05\x07\xb6\xa1\xd4\xb2\x02\x5b\x23\xad\x02\x79\x99\xc1\x32\x74\x33\x10\xc2\x5a\x31\x28\xf6\x99\xf6\x8d\x26\x1c\x8a\x44\x43\xc9\x69\x64\x78\xd0\x41\xda\xa7\x00\x94\x63\x4d\x89\x66\x26\xf4\x91\x06\xf6\x43\x94\x11\x2c\x20\xd6\x64\xc4\x18\xd4\xfd\x54\xc0\x69\xc9\xb4\x97\x82\xf9\xfa\xcb\x04\x85\x67\xd0\xdf\x1b\x66\x29\xd4\xfa\x8c\x63\x11\xdc\x06\x6d\x24\x13\x9a\x36\x93\x59\xd3\x19\x0a\x8b\x0d\xa5\x52\xf4\xa8\x97\xd0\x89\x69\x22\xa4\x35\xcf\x92\xa1\x5c\x6b\x1b\xf0\x0e\x59\x12\x48\x8f\x92\x86\xd6\x90\xd0\xf0\xdc\x88\xed\x1e\x45\x68\x82\x09\xb5\x96\x48\xdf\xee\xcb\x26\x7b\x50\x4d\xa1\xc8\xf5\xcf\x38\x04\x36\x01\xc1\xd0\x45\x6a\x66\x0c\x14\x9d\x52\x62\x19\xf2\x01\xd5\x0a\x13\xe6\x1b\xc3\x0e\x75\x46\x75\x53\x54\xf6\xc8\xd4\x10\xdf\x68\x43\x4a\xe9\x30\x36\x14\x1b\x46\x01\xec\x7b\x19\x2e\x01\x49\x5a\x52\x66\x95\x6f\x86\x4c\xe8\xb3\x67\xa0\x28\x34\xc9\x0a\x4a\x5f\xc8\x45\xbe\xd2\xf6\xfd\x82\xa5\xdc\x86\xae\x8e\x86\x84\xd0\xa3\x6a\xcc\x9a\xc6\xa9\x47\x96\x58\xb4\xcd\xc5\x8e\x31\x85\x44\x1a\x22\xa6\x23\x05\xb4\xc0\x3a\x48\x7c\xb9\x17\x19\xfc\xb8\x7b\xdc\x63\x08\x66\x84\x79\x16\xdc\x62\xdd\x3c\x1a\xd8\x9b\x82\x72\x1b\x0c\x26\x04\x83\xea\xdf\x3f\x65\xd8\x9b\x35\x0c\x53\x6c\x8c\x54\xdc\xf6\x79\x10\x4c\x9a\x56\xad\xbb\x21\x38\x95\x71\x0b\x3d\xb5\x60\x3b\x10\x81\xff\x99\x26\x70\xee\x08\x60\x1e\x9e\x93\x74\xa9\x12\xc2\x18\xfa\x80\xcb\x2e\x59\xef\x9e\x3a\x5c\x76\x80\xd8\x21\xcf\x8c\xc1\x92\x32\x62\xe1\x84\xdb\x93\x01\x64\x54\x32\xe8\xdd\xa8\x11\xd8\x86\x67\x54\x5c\x61\xcb\xb8\xef\x7e\x7c\xeb\xdf\x60\x8b\x33\xcc\xa9\xe4\x44\x43\xe8\x50\xcc\x82\x03\x39\xf1\xd1\x2c\x03\x43\xb8\x13\x12\xdb\x10\x56\x33\xb8\x85\x73\x20\x24\x00\x2e\xb1\x94\x60\x4e\x16\x31\xcd\xc0\x93\x86\x6b\x24\x14\x04\xa4\x50\x04\xce\x25\xda\x46\xfa\x4c\x28\xac\xa4\xd2\x4c\xa9\x50\xc3\x34\x93\x35\xa6\x44\x71\x42\x6c\x48\xc6\x61\xd7\x83\x27\x69\xb7\xcd\x19\xcd\x8a\xcd\xdd\xae\xd2\x6b\x4b\xc1\x43\x5f\xa7\x1c\x2e\x3f\x05\x81\x1a\x48\x59\x19\x21\xc6\xfe\xb8\xff\xe9\xce\x1f\x75\x17\xfc\xb9\xfe\xe7\xc3\x06\xc5\x65\x59\xdc\x3c\xee\x81\xb6\x9b\x91\xa7\x3f\x23\xe5\x08\x4e\x11\xc6\xd0\x88\x11\x83\x29\x3b\xed\xf7\x2f\x9d\x25\xeb\xb8\x2e\xd3\xdb\x21\x66\x4c\x50\xee\xbf\xfd\xc1\x14\x4e\x7b\xc4\xb9\xc4\x4a\x23\xaa\x04\xe6\xf2\xf4\x71\xad\x4c\x06\x50\xb6\xac\x47\x10\x64\x54\x23\x41\x19\x26\x74\x35\x62\x06\x1b\xa6\x9b\x5f\x19\x15\x58\x50\x31\x62\x50\xbf\x49\x74\xe8\x0e\x85\xbb\x03\x0d\x07\xe8\xfe\x6d\x71\x93\x1f\xd6\x3e\x29\x6e\xf2\xff\x94\xfe\xa3\xbe\x01\x00\xb3\x96\xff\x77\x1b\x60\x32\x6e\xbf\x0c\x9c\x8c\xab\x6b\x4f\x9b\x84\x77\x91\xc2\xf0\x8a\x86\xf9\xf7\xf7\x65\x9c\x2f\x1d\xfa\x4b\x1a\xa1\xbf\xcc\xcb\xed\xfa\x0a\x9d\x4d\x11\x7e\x59\xba\x38\xf1\xb7\x0f\x0f\x93\x18\xad\x4a\xb7\x98\x0e\x9a\xf7\xe2\xc2\x34\x7c\x99\xe6\x1f\x1f\x1e\x06\x17\x7d\xea\x7b\x77\x5b\x3f\x3c\x4c\xc6\xf1\xc5\xfd\x7d\xba\x40\x39\x70\x46\xe4\xe1\x61\x7c\x7f\xef\xf2\xe4\xe1\xa1\xf9\x15\x44\x0c\x42\x84\x6f\x63\x83\x60\x93\x75\x9c\xe6\xcd\x97\x99\xe9\x35\x9a\x67\x71\x55\x4d\x07\x6b\x57\xc7\x8d\x03\x3c\x19\x3c\xd8\xbc\x19\xb6\xf3\x4b\xb5\x89\xf3\xee\x7c\xff\x12\xce\xe0\x62\x92\xe6\x9b\x6d\x8d\xea\xbb\x8d\x9b\x0e\x6a\x77\x5b\x0f\xd0\x26\x8b\xe7\x6e\xe5\xbf\xf1\xf2\x7d\x5e\xed\xca\x41\xdb\xf3\xf9\xeb\x22\xff\xe8\xee\xb6\x9b\xfd\x17\xc2\x27\x17\x93\x31\xf0\x6f\x4d\x9c\xa4\xd7\xad\x91\xdb\xab\x8e\xb4\x59\x5a\xd5\x69\xbe\x6c\x05\x0e\xef\xee\xc4\x65\x1a\x8f\x12\x57\xcd\xcb\xf4\xca\x25\x57\x77\x4f\x15\xa8\xdb\xb7\x10\xfd\x4d\xb9\x2b\xf1\xeb\xd5\xc5\x64\xdc\xa9\xfe\xbb\xfd\x49\xeb\x99\xbf\x55\x45\x59\x4f\xf3\x78\xed\x92\xb4\xf4\xaf\x51\xfd\xd5\x7f\x77\x3d\x8d\xab\xf9\xa0\x95\xcb\xbf\x77\xe1\xdf\x5b\x08\xdf\x6b\x5f\xf8\x6f\xb1\x9b\xc1\xba\xd8\xec\xbe\x6a\xa6\x6e\xbd\xff\x06\x1a\x4b\xb8\xeb\x7f\xd7\x7d\x9d\xba\x9b\x97\xc5\xed\x74\xe0\xbf\xec\x65\xd8\x32\x46\xad\x40\x0a\x13\x2e\x8d\xb1\x76\x70\x31\xd9\x56\x0e\xf9\xef\xb2\xcf\x82\x84\x5f\xee\xf2\xcd\xc5\x64\xbc\xad\xdc\x45\x80\x65\x57\x84\xf0\xb6\xc4\x7f\x56\x8a\x4e\xdc\xf7\xe5\x18\xc7\x3b\xab\x7e\xc6\xba\x07\xac\xda\xd8\xf2\x6d\xbc\x76\x1d\x26\x7f\xd2\x63\x55\xfa\xe9\x33\x3c\xdf\xa5\x9f\x3e\xc3\xb3\x9d\xdc\xbe\xe3\x31\x78\x6e\x93\x3a\xfd\x9c\xe0\xe1\x15\x5a\x97\xfc\x3b\x1b\x75\x26\x4c\xc6\x3b\xa8\x02\xb5\x0b\xe1\xab\x22\xb9\x3b\x84\xe7\x04\xd6\x27\xdd\xfb\x27\x82\x63\xbc\xd7\xa6\x1f\xda\xcb\x62\xbb\x19\x5c\xfc\xbd\x40\xdb\x4d\x37\x26\xfd\xf6\x5d\x05\x7a\xfc\xff\xba\x4e\xe2\x6a\x75\xfe\x88\xfc\x54\xaf\x3f\x3b\xaf\x33\xa1\xa3\xff\xfd\xfd\x08\x85\x64\x8a\x5f\xe5\x75\x99\xba\x2a\xe4\x39\xaf\x7e\xcb\xc4\x3f\x7a\x3c\xa0\xfb\x73\x26\x01\xa6\xe9\x02\xe1\x37\xd5\xb7\x69\xd9\xf2\x6b\x5e\x40\x69\x03\x25\x04\x47\x1b\x2a\xf4\x0f\x22\x85\x53\x38\x93\x0e\x46\x47\xf3\x6a\x48\x2f\x32\xba\x82\xb8\xac\x72\xff\x25\x32\x30\x25\x11\x67\xfc\xa0\x0c\x69\xb0\xf0\x33\x12\xb4\x87\xc7\x13\x60\x40\x78\x0e\x2e\x1e\x9f\x55\xf8\xc3\x4f\x97\x9d\x43\x0a\x5f\xba\x78\x11\x8e\xa7\x3e\x7a\xba\xe6\x3f\x6c\x72\x00\x42\x12\xd7\xf1\x28\x44\xd2\x60\x44\x0f\xe2\xe5\x89\x99\x1e\xaf\xbb\xbf\xc7\x10\xd7\x20\xd4\x04\xc2\xff\x62\x47\x98\x8c\xfd\xfd\x13\x6e\x1d\x95\x5b\xd1\xbe\x2f\x92\xf7\xe9\xda\xa1\xff\x85\xe2\x45\xed\xca\x57\x9b\x62\xbe\x42\xbd\x2d\x9f\x62\x16\xd2\x80\x7f\xc3\x0b\x2e\xbc\x1c\x2d\x97\x60\xa0\xce\xed\x64\x0c\x73\x9e\x4a\xf2\x58\xaf\x27\x9b\xfc\xdf\xff\xfd\x7f\x3e\x27\xfe\xbf\x19\x4c\x9d\xa5\x93\x71\x27\x9d\x4c\xc6\xfe\x4c\xed\x1f\xc1\x93\x71\x5b\x3a\x4c\xe0\x90\xdd\xd4\x7e\xf8\x3a\x2e\x51\x38\xc5\x5f\x65\x68\x8a\x92\x62\xbe\x5d\xbb\xbc\xc6\x4b\x57\xbf\xca\x1c\x5c\xbe\xbc\x7b\x93\x0c\x9b\x93\xfe\xe4\xf4\x1c\x16\xb5\x0b\xf0\xa2\x98\x6f\xab\x61\x43\xdc\xe6\xf3\x3a\x2d\x72\xd4\x16\x05\xfe\x55\xb3\xb0\xc3\xef\x68\xba\xdb\x05\x5f\xc7\xd9\xd6\xe1\xba\x4c\xd7\xc3\x53\x5c\x17\x97\xc5\x8d\x2b\x67\x71\xe5\x1a\x3e\x7e\x81\xcb\xdc\xba\xea\xca\xf3\xfb\xd6\x95\x77\xef\x5c\xe6\xe6\x75\x51\xbe\xc8\xb2\xe1\x49\x5d\x62\x08\x85\x46\xa4\x23\xbf\x02\x2f\x8a\xf2\x55\x3c\x5f\x0d\x5b\x61\x86\x2e\x6b\xe5\x38\x4a\x17\x68\xf8\xc5\xef\xbb\xdb\x23\x97\x61\xff\xc2\x18\x6e\xde\xfb\x43\x53\x74\x72\x72\xde\x0c\x96\xae\xde\x96\x79\x73\xd7\xd8\x18\x04\x83\x28\xf2\x96\x72\x59\x5f\xa6\xe1\x89\x7f\x5b\xb4\x15\x67\x37\xf9\xe7\x18\x66\x87\x65\x18\xea\xab\x59\xf8\x6b\x85\xcf\x18\xc0\x4b\xda\xac\xc5\x69\x9e\xb8\xdb\x1f\x16\xc3\xdf\x4f\xd1\x17\xd3\x29\x1a\xd1\x3f\xa7\xc0\x83\x07\xe3\x67\xa7\xe6\x45\xee\x4e\x7a\x1a\x3e\x04\x01\x1e\x7a\xee\xcc\x8a\x79\x9c\xa5\x9f\xdc\xb7\x4d\x64\x0c\x5d\x84\xbc\x50\x11\x8a\xcb\x56\x18\x90\xd8\x75\xd5\x43\xd3\xe9\xd4\xbf\xc1\xbe\x48\x73\x97\xec\x64\xee\x9a\xf5\x61\xe7\xed\x04\x2c\xe4\x6e\x10\x6c\x31\x74\x80\xbd\x17\x75\xf3\xd7\x38\xc3\x93\x36\x22\x4f\x4e\x1b\xf3\xc0\x5e\x69\xf5\x36\x7e\x3b\x4c\x4e\x77\x8c\x1f\xb1\xe8\x48\xd2\x35\xea\x93\x65\x87\xfc\x1c\x3e\x1f\x69\x83\x12\xef\x29\x68\x41\xdf\xd5\x65\x9a\x2f\x87\xbf\xfe\x16\xa1\xfb\x24\xbe\x3b\x43\x03\x36\x4a\xd2\x65\x5a\x0f\x22\xb4\x2e\xf2\x7a\xd5\xa3\xdc\xb9\xb8\x3c\x43\x83\x7c\xbb\x76\x65\x3a\x1f\x44\x68\x55\x6c\xcb\xfe\x9a\x34\xdf\xd6\xae\x47\xaa\xdc\xbc\xc8\x93\x0e\xa9\xeb\x19\xb0\x18\x18\xe4\x32\xad\x40\xb0\x17\x65\x19\xdf\xe1\x4d\x59\xd4\x05\x14\xf1\xb8\xca\xd2\xb9\xc3\xf3\x38\xcb\x86\x07\xa2\xb9\x7a\x79\xf7\x3e\x5e\x42\x31\x36\x1c\x00\x93\x41\x63\xd5\x96\xe1\x2e\x82\x1e\xbb\xfd\xf4\xfc\xb8\xdd\x7c\xe9\xea\x0f\x65\xf6\x63\x5c\xc6\x6b\x57\xbb\x12\x62\xbb\x05\xcb\xa3\xa1\x61\xe5\x2f\xbb\xa9\xa0\xfa\x31\x5e\xba\x0f\x3f\x5d\xa2\x29\xba\x49\xf3\xa4\xb8\xc1\xb0\x13\x2c\xc6\x95\x8b\xcb\xf9\x0a\x57\xdb\xab\x2a\x98\x98\x9e\x46\x7e\x5d\xf5\xe1\xa7\xcb\x9f\xa1\x41\xb8\xca\x1c\x64\x85\x96\x07\xae\x36\x59\x5a\x0f\x4f\xfe\x7a\xd2\x4e\xdc\xed\xfc\xd6\xbf\xb9\xed\xfd\x1e\x04\x3f\x5a\x14\x25\x1a\xa6\x68\x8a\xc8\x39\x4a\xd1\x04\xf5\x98\xe2\xcc\xe5\xcb\x7a\x75\x8e\xd2\x6f\xbe\xd9\x81\xa3\xcf\x0d\xf6\xed\x2e\xf9\x35\xfd\xad\xdd\x7f\x7a\xd2\x58\x27\xa0\xac\xbf\xee\x57\xf2\x9b\x0f\x86\xbe\x29\x5a\xe4\xa1\x47\x93\xe9\x6f\xfd\xc8\x41\x7f\x43\x75\xb9\x75\xe8\x0c\x25\x6e\x5e\x24\xee\xc3\x4f\x6f\x66\xc5\x7a\x53\xe4\x2e\xaf\x1f\x6f\x44\x7f\x3b\x7d\x0a\xe4\x87\x7e\x72\x6e\xde\xdc\xf5\x87\x0c\x2c\x3a\xdd\x7b\xc6\x9f\xbf\x68\xfa\xc4\x87\x27\x7e\xe0\xe4\x51\x76\x06\x2c\x1d\x3e\x30\xaa\x97\x77\xb3\x96\x7d\x67\xa3\xf3\xd6\x0b\x43\x60\xe1\x1d\x11\xa1\x60\x76\x9f\x4e\xc3\xda\xbd\x23\xd0\x04\x1d\x72\x0a\x2c\x9e\x6f\xcb\xf2\x75\xe9\x16\x9d\x75\xe0\x0d\x28\x6c\x76\xc1\x3e\x0c\xe5\xc4\xf4\x04\x7a\xca\x93\xd3\x7b\xd4\x98\xdd\xaf\x5f\x2d\xd1\x74\xc7\x05\x97\xce\x77\xbc\xc3\x30\x35\x42\x27\x31\xac\x38\xdf\xa5\xce\xfe\x0e\xb0\x72\xb5\xec\x9f\x0c\x9d\xed\xe2\x3f\xbd\x5b\x1c\x36\x0b\xf2\xfd\x1b\xbb\x1d\x72\x6b\xe9\xe2\x04\x50\xf9\x5d\x9a\x85\xd7\xb0\xa1\x52\xea\x86\xdd\x36\x4f\xbd\xbf\x7e\x3d\x79\x09\x9b\xfe\xc3\x7f\x7e\xef\x3f\xff\xee\x3f\xdf\xfb\xcf\x1f\xfd\xe7\x2b\xff\xf9\x2f\xff\xf9\xcb\xcb\x93\xdf\xf6\x9e\x0f\xf1\xe3\x6f\x6f\x56\x69\x16\xf6\x41\x17\x53\x44\x09\x13\xfb\xc0\x01\xe2\x38\x10\x1b\xd1\xbf\xf9\x26\xed\x66\xfd\x06\xfc\x9b\xb8\xac\xdc\x77\x59\x11\xd7\x41\x60\x5c\x17\xdf\xa5\xb7\xce\xbf\x47\xff\x0d\x3a\x41\x27\xe8\x9b\x20\xf9\xaf\xe9\x6f\x4d\x02\xec\xa9\xdd\x7d\xef\xbc\x9b\x63\xd2\x4f\xee\x79\x70\xee\xf2\x1f\x4c\x1b\x9c\x76\xd3\xc3\x5e\xc5\x90\x22\x80\xcf\xc1\xd4\xb0\xda\xae\xe3\x1c\xf6\x45\xd3\xc3\xb6\xf7\x0e\x4c\xf3\xdc\x95\xaf\xdf\x7f\x7f\xd9\xba\xf7\xe9\x08\x9a\xa2\x1d\xaf\x8e\x77\xc3\x63\xa9\xb6\x4c\x9b\x8c\x43\x6d\x37\x19\x87\x3f\xc8\xfc\x7f\x01\x00\x00\xff\xff\xd1\xe7\x7f\xef\x40\x3c\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xbd\x5b\xdb\x72\xe3\x46\x92\x7d\x96\xbe\xa2\x4c\x8f\x2d\xca\x26\x40\x14\xee\x90\xa8\x9e\x95\xe9\xf6\x74\xc7\xd0\x6d\x87\xbb\xed\x09\x8f\xc3\x0f\x10\x59\x12\xb1\x0d\x12\x34\x00\xea\x62\xad\x22\xf6\x23\xf6\x0b\xf7\x4b\xf6\x64\x16\x40\x16\x48\xaa\xdd\xb3\x3b\xb3\x6a\x87\x09\x24\xaa\xb2\xf2\x72\x32\x2b\x13\x2c\x8e\x3e\xb1\x2c\x71\x3c\x1c\x8a\x71\xb1\x7a\x28\xb3\x9b\x79\x2d\x5c\x47\x06\xe2\xdb\xb4\xae\xe7\xea\x4e\xbc\x2a\xf2\x5a\xa4\xcb\x99\x78\x37\x57\x62\x9c\xce\x66\x0f\xe2\x72\x5d\xcf\x8b\xb2\xc2\x24\x9a\x37\xc9\xa6\x6a\x59\xa9\x99\x58\x2f\x67\xaa\x14\x98\x24\x2e\x57\xe9\x14\x1f\xcd\x93\x81\xf8\x49\x95\x55\x56\x2c\x85\x6b\x3b\xa2\x4f\x03\x7a\xcd\xa3\xde\xe9\x39\xb1\x78\x28\xd6\x62\x91\x3e\x88\x65\x51\x8b\x75\xa5\xc0\x23\xab\xc4\x75\x96\x2b\xa1\xee\xa7\x6a\x55\x8b\x6c\x29\xa6\xc5\x62\x95\x67\xe9\x72\xaa\xc4\x5d\x56\xcf\x79\x9d\x86\x8b\x4d\x3c\x7e\x6e\x78\x14\x57\x75\x8a\xe1\x29\x26\xac\x70\x77\x6d\x0e\x14\x69\xdd\x08\x4d\x7f\xf3\xba\x5e\x9d\x0d\x87\x77\x77\x77\x76\xca\x02\xdb\x45\x79\x33\xcc\xf5\xd0\x6a\x38\x79\x3d\x7e\xf9\xe6\xed\x4b\x0b\x42\x37\x93\x7e\x5c\xe6\xaa\xaa\x44\xa9\x7e\x5b\x67\x25\x14\xbe\x7a\x10\xe9\x0a\x42\x4d\xd3\x2b\x88\x9a\xa7\x77\xa2\x28\x45\x7a\x53\x2a\x3c\xab\x0b\x12\xfa\xae\xcc\xea\x6c\x79\x33\x10\x55\x71\x5d\xdf\xa5\xa5\x22\x36\xb3\xac\xaa\xcb\xec\x6a\x5d\x77\x6c\xd6\x8a\x08\xcd\xcd\x01\xb0\x5a\xba\x14\xbd\xcb\xb7\xe2\xf5\xdb\x9e\xf8\xea\xf2\xed\xeb\xb7\x03\x62\xf2\xb7\xd7\xef\x5e\x7d\xf7\xe3\x3b\xf1\xb7\xcb\x1f\x7e\xb8\x7c\xf3\xee\xf5\xcb\xb7\xe2\xbb\x1f\xc4\xf8\xbb\x37\x5f\xbf\x7e\xf7\xfa\xbb\x37\xb8\xfb\x46\x5c\xbe\xf9\x59\xfc\xf5\xf5\x9b\xaf\x07\x42\xc1\x62\x58\x47\xdd\xaf\x4a\xd2\x00\x62\x66\x64\x4d\x35\x63\xd3\xbd\x55\xaa\x23\xc2\x75\xa1\x45\xaa\x56\x6a\x9a\x5d\x67\x53\xa8\xb6\xbc\x59\xa7\x37\x4a\xdc\x14\xb7\xaa\x5c\x42\x23\xb1\x52\xe5\x22\xab\xc8\xab\x15\xa1\x83\xd8\xe4\xd9\x22\xab\xd3\x9a\x49\x7b\x7a\xd9\xe2\xf8\xdb\x62\x46\xdc\xf4\x88\x33\x21\x2e\x67\xe9\xaa\xd6\xa6\x2a\xa7\x79\xb1\x54\xf0\x5f\xf9\x7e\xbd\x12\x96\xf5\xe2\xf8\x78\xf4\xc9\xd7\xdf\x8d\xdf\xfd\xfc\xfd\x4b\xf8\x69\x91\xbf\x38\x1e\xe9\x8f\xa3\xd1\x5c\xa5\x33\x7c\x1e\x8d\xea\xac\xce\xd5\x8b\xc7\x47\x7a\x20\xec\x37\xe9\x42\x3d\x3d\x8d\x86\x9a\x4a\xcf\x17\xaa\x06\x0a\xe6\x69\x59\xa9\xfa\xa2\xb7\xae\xaf\xad\xb8\xb7\x7d\xb0\xc4\xf8\x8b\xde\x6d\xa6\xee\x56\x45\x59\xf7\x00\x97\x65\xad\x96\x18\x78\x97\xcd\xea\xf9\xc5\x4c\xdd\x42\x70\x8b\x6f\x06\x70\x25\xfc\x98\xe6\x56\x35\x4d\x73\x75\x21\x6d\x67\x8f\xd1\x4d\x51\xdc\xe4\xca\x60\x03\x2c\x97\xe9\xb2\xca\xd3\x5a\x61\xf0\xa8\xaa\x1f\x48\xac\x2f\xc4\xa3\x58\x21\x88\x60\xc2\x33\xe1\x9c\x93\xc6\x37\xd9\x92\x2f\x9f\x8e\xaf\x0a\x04\xd7\xe3\xf1\xd1\x35\x78\x58\xd7\xe9\x22\xcb\x1f\xce\x44\x05\x26\x56\xa5\xca\xec\xfa\xfc\xf8\xa8\x56\xf7\xb5\x55\x2a\x32\x2e\x73\x28\x56\x35\x8c\xfe\xbb\x82\xa7\xd4\x0c\xcf\xaf\xd2\xe9\xfb\x9b\xb2\x80\xf5\xad\x69\x91\x17\xe5\x99\xf8\xf4\x9a\xff\xce\x8f\x9f\x8e\x53\xe2\xdd\x92\x1d\x27\x54\x33\xaf\x65\x39\x53\xd3\xa2\x64\xc7\x9c\x21\x08\x97\x8a\x87\x9f\xcd\xc9\xdb\x83\xe3\xb9\x14\xcd\xb5\xc9\xc0\x93\xc9\x54\xf3\x25\x87\xd0\xb8\x4f\xab\xf5\x02\xfa\xb0\x0a\x8d\x8e\x56\xae\xae\xeb\x33\x11\x7c\x76\xbe\x25\x71\x8e\xd1\xb4\xa7\xe3\x7a\x7e\x76\x9d\x95\x55\x6d\x4d\xe7\x59\x3e\x1b\x1c\xd7\x33\xf3\x9e\x38\xb1\x07\xce\x84\xfc\xec\x5c\x0c\xbf\x10\x35\x4d\x86\x20\x04\xd1\x45\x71\x45\x29\xe2\x8b\xa1\xe6\x93\xa7\x1d\x36\xdb\xdb\x8f\xe7\xa2\x35\x31\xe5\xaf\x8b\xd5\x99\x70\x83\xd5\xbd\xa1\xc0\x55\x51\xd7\xc5\x02\xcc\x34\xf9\x90\xcd\x5d\xfa\xc7\xb6\x91\x1b\x87\x56\xf0\x13\x78\x39\x3c\x89\x29\x77\x4a\x9b\x62\x59\x94\x8b\x34\x07\xf5\x6e\x9e\xd5\xca\xaa\x90\x8c\x14\x51\xef\xca\x74\x05\x2a\x59\xfe\x3a\x2f\xee\xac\xfb\x33\x31\xcf\x66\x33\xb5\x6c\xdd\xd6\x3e\x39\x13\x2a\xcf\xb3\x55\x95\x55\xe7\x5b\x07\x25\x49\xd2\x48\xb0\xe3\x78\x07\x83\x36\xb8\x13\x3e\xc9\xf3\xb4\xe3\xe4\x3d\x50\x70\x3c\xe7\x99\x46\x06\x8f\xdd\x71\xd3\x16\xc8\x18\xb0\xa0\x0c\x0c\x22\x12\xd9\x2a\x4f\x01\xe2\xab\xbc\x98\xbe\xa7\x27\x36\x87\x4c\xd7\x24\xd2\xdd\x9a\xa4\x45\x3d\x76\x8c\x59\xba\x4c\x07\x5d\xf8\x5f\x15\x25\xc4\xd8\x3a\x60\x75\x8f\xc4\x9a\x67\x33\x28\x3b\xa6\x7f\xe7\x3b\x8e\x93\xce\x61\xc7\x39\x5a\x67\x16\xc6\x82\xc9\x17\x5b\x0d\x5a\x78\x4a\xb5\xa0\x21\x9f\x62\x17\xaa\x3b\x90\x38\xd3\x16\x6b\x64\xe9\x08\x31\x1e\x8f\x99\xed\x7a\x95\x17\xe9\xec\xff\xaa\xa5\xd6\xe0\x79\x15\xb5\x1e\x4d\x1c\xb5\x6b\x02\xd1\x8b\x8e\xe5\xb3\x25\x79\xcd\x6a\x1c\xb0\xa3\xa4\xab\x95\xac\x79\xff\x32\xa2\xc4\x71\x3e\xdb\xca\x01\xe0\xe4\xe9\xaa\x82\x0a\xed\x15\xcf\x61\x9b\x1c\x70\xc8\x2c\xad\xe6\x48\xea\x9f\xce\x52\xfa\xc7\x43\x39\xaf\xd5\xe5\x16\x5e\xcf\xa4\x29\x35\xd5\x29\x81\xe2\x77\x83\xc2\x34\xcf\x6e\x80\x2b\x4a\x24\x7b\xba\x93\x49\x29\x9e\xc7\x73\x6c\x50\x58\xf4\xba\x2c\x16\x80\x34\x36\x14\xb7\x4d\x0b\x7b\xc1\xfc\x41\x4c\x74\x1c\x16\x32\xe5\x60\x4c\x32\x67\x33\xac\xae\xf2\x54\x03\x1c\xf4\xea\xf6\x86\x9e\x40\xd7\x1a\xbb\x5d\xde\x6a\xb0\x40\xe8\xe6\xda\x76\x3a\x25\x1d\x0c\x76\x53\x80\x26\x34\x91\xcc\x96\xf5\x5c\x87\x5a\xdf\x3d\x35\x1c\x15\x3b\x9f\xed\x0d\xf0\x4e\x3b\x60\x75\x38\xe3\x34\x1f\x4d\xc6\xdd\x0e\xf6\x4f\x07\xdd\xd9\xfe\xe9\xae\xe1\x19\x2a\x87\xc4\x68\xd4\x5c\x15\x55\xa6\x73\x44\x7a\x05\xa4\xa2\x68\x69\x54\xb4\x69\x63\x64\x57\xda\x37\x05\x76\xf5\x6d\x88\xe9\x4d\x41\xda\x51\x40\xf8\x3b\xba\x03\x88\xac\xab\x52\xa5\xef\x61\x47\xfa\xc0\xd2\xb9\x99\xf7\xc8\x34\xed\x23\x1a\xbc\xeb\x15\x14\x35\x56\xeb\x17\x3b\xc3\x0e\xbc\x1f\xce\x41\x13\xf1\xf4\xd4\xae\xb0\xe1\x7f\x28\x48\xda\x30\x60\xe9\xe6\xca\x48\x08\x86\xb6\xa5\xc2\xd6\x9e\xdd\x2a\xca\xc5\x84\x2b\xbb\x09\x26\x63\x09\x1b\x0f\x9e\x33\xd1\x91\x36\x82\xd3\x4e\xb7\xe4\x9e\x84\xb6\xc6\xe6\xb3\x1c\x5a\xe8\xea\xa9\x5b\x86\x4f\xc7\xd7\x45\xb1\x97\xb4\x38\x5e\xf6\x41\xae\xb3\x92\xe9\x70\x94\x6b\x98\x4c\x6c\xfe\x6d\xa1\x66\x59\x2a\xfa\x8b\xf4\xde\x6a\x6c\x12\x3a\x60\x41\x18\x19\x7e\x71\x64\x63\x33\x52\x6d\xea\xd8\x1a\x53\xd7\x0f\x47\x4f\xb0\xd0\x02\x2e\xe4\xfa\xee\xbd\x52\x2b\x64\x86\x5a\x55\x54\xd0\x6e\xb7\xdc\xa3\x03\xd8\x6e\xcd\x9f\xae\xeb\x82\xf8\x60\xd0\xbc\x83\xef\xc1\xce\x34\x8d\xf8\x43\xf5\xc5\xd1\x21\x24\x13\x47\xbd\x2d\xef\xec\x89\x9a\xce\x51\x6d\x6e\x67\x44\x37\xb6\x81\x23\xc3\x1a\xd2\xd1\x06\x7d\x82\xb1\x46\xc3\xa6\xc4\x3b\x1a\x0d\x9b\x12\x75\xc4\x89\xaf\x58\x52\x66\xbe\x38\xd1\x2c\xfa\xa7\xe7\x75\x71\x83\x52\xb1\xdf\xe3\xdc\x89\x0e\x68\xca\xd9\xeb\x2d\xfc\xd1\x3f\x3d\xe1\xba\x92\x42\xeb\x56\xf7\x4c\x17\x3d\x69\xcb\x9e\xb8\x5f\xe4\xcb\xea\xa2\x67\xb4\x2c\x77\x1e\xb7\x2b\x2e\x64\x1f\x62\x7c\x33\xe4\xec\x1e\x48\x7e\x7f\x68\xa0\x44\x3d\x30\xe4\xa7\x3d\xa1\x31\x7d\xd1\x73\x7a\x42\x57\xbb\x74\xc5\xe2\x5f\xf4\x0e\x40\x8d\x8b\xdd\xa3\xd1\x4c\x5d\x57\x7c\x75\x34\xa2\x9e\xf1\x9b\x22\xa7\x62\x89\x8a\x75\xa6\xdd\x88\x6c\x76\xd1\xbb\x66\x6a\x8f\xba\xb7\xdc\x2a\xd7\xc4\x11\x80\xf8\x5d\x95\x85\xa6\xf1\xad\xd2\x1c\x31\x69\x95\x22\x61\x62\xda\xb7\x6e\x1c\xd8\xae\x2b\xbc\xc8\x0e\x82\xb9\x25\x7d\xd7\x0e\x27\x52\x3a\x76\x22\x9c\x57\x1e\x52\xc5\x58\xfa\xb6\x1b\x20\x8f\x39\x48\xcc\x44\xd5\x43\x6f\xa3\xc0\x96\x73\x8f\x48\xee\x4f\x74\x3d\x75\x2c\xd7\xb1\xc3\xc0\xa2\xf1\xa1\xc5\x83\x2c\x62\xa0\x2f\x7f\x6f\xa5\xf8\xf4\x9b\x6f\x2e\x61\xba\xde\xf0\x59\x49\x42\x73\x5d\x2f\xc4\x8a\x81\x63\xbb\x31\x3e\xc3\xc8\x8e\xfc\x5b\x19\xc4\x76\x34\x85\x38\x91\xed\x47\x82\x97\x13\x34\x23\xe0\xff\xeb\xcb\x57\xcc\x6c\x4a\x43\x7c\x12\x99\xe4\xc0\x48\x4f\x5f\xf1\x90\x9f\x88\x5b\x00\xb1\x99\x4f\x2b\x36\x3d\xb1\xb6\x83\x4c\xb1\xc7\x97\x6e\xdc\x8a\x3d\x1a\xde\x1c\xb0\xbe\x55\xa1\x3d\xaf\xa7\xeb\x9a\x9c\x5a\x16\xef\x55\x63\xf4\xe6\xce\x6a\x7c\x2e\x3b\x1e\x31\x3d\xa6\x6e\xd5\xb2\x98\xcd\x36\x5e\x3a\xc8\xdc\xa2\x1d\x7c\x75\xd0\xd3\xcd\xbc\xe7\x26\x56\xf3\x74\xb5\x81\xc0\xbe\xe9\xfd\x38\x0a\x07\xe4\x2d\x3f\x0e\x13\xc7\x15\x13\x46\x83\x74\x7d\x2f\xee\x92\x09\x1e\xae\x13\xc5\xc1\xc0\x11\x13\xd8\x29\x4c\x64\x18\xb8\x09\xee\xd8\x6b\xcd\x14\x40\x66\x00\x7c\xc4\x09\x1e\x3b\x70\x63\x87\x07\x1e\x49\x30\xf7\x43\x27\x92\xc4\x03\x38\xd2\x3c\x9e\x21\x03\x62\x4e\x12\x79\xb1\x13\x88\xb1\x41\x0e\x7c\x38\x38\x40\x72\x8c\x85\xe7\x60\x62\x10\x40\x15\x73\xa1\xc3\x9a\xfd\xbd\xc7\xf6\x79\xcb\xf6\xd8\x01\xe6\x8b\xd1\x90\xec\xf2\x07\x56\x0a\x3b\x8a\xe3\xd6\xd4\x9c\x40\x3b\x60\xd0\x7a\x71\x10\x02\xb9\x03\x46\xae\x4c\x1c\x2f\x21\xd5\x5d\x37\xb4\xfd\x00\xd6\xf5\xc5\x18\x77\xbe\x67\x27\x4e\xe2\x43\x63\x83\x87\x0b\x94\xcb\xc4\xf3\x00\x7c\x63\x21\x83\x3a\x31\xc4\x31\xc8\x63\xc3\x0e\x1d\x1e\x1b\x9b\x19\xeb\x99\xd4\xad\x4c\xa6\xdd\x0d\xc1\x3b\x76\xdf\x2a\x67\xda\x3d\x14\x5d\x1b\x3d\x63\x67\x8e\xa4\x1d\x3b\x6f\x22\xca\xb4\xb8\x84\x50\x32\xf0\xa5\xe7\x43\x17\x07\x69\x24\x91\x31\x6c\x46\xe4\x38\x00\x1e\x88\x2c\xed\x38\xf6\xc2\xc8\xc3\x96\x1a\xd9\x9e\xe3\x04\x3e\x99\xc9\xb3\xd1\x68\x4b\xa4\x13\xa2\x46\x91\x1b\x3a\x2e\xa8\xbe\x2d\x35\x15\x2c\x62\xdb\x0f\x13\xdf\x27\x32\x44\x6e\x07\xc7\xd0\x30\x71\x24\x99\x14\x4b\x3b\xbe\x13\x13\x35\xb1\x43\x2f\xf6\x3c\xb2\x68\x04\xc6\xae\xe3\x4b\xb0\xf0\x58\xa2\x24\x74\xd9\xd0\x9e\x1b\x06\x1e\x5c\x48\x79\xc3\x8d\xe3\x80\x06\x27\xb8\xf5\xc0\x06\x52\x45\x7c\x8b\x49\x40\x6c\xe2\x45\x5e\xd4\x3c\x0e\x6c\x5f\x06\x5e\xe8\x33\x8f\x20\x90\x40\xa7\xf4\xb0\x34\xc4\x71\x7c\x5e\x2f\x8c\x40\xa6\x99\x50\xda\x49\x1c\xdf\x37\xa5\x90\x40\x35\x66\x86\x32\x61\x3d\x92\x03\x54\xdf\x0e\xa2\x96\x85\x41\x26\x10\x68\xf5\x4c\xaa\x8b\x35\x36\x54\xc7\x83\xd6\x2e\xdb\xd8\x0f\x22\xe9\x7b\x5a\x8a\x28\x0e\x83\x10\x16\xf2\x13\xb0\x88\x65\xe8\xb1\xc4\x41\x28\xa3\x28\x61\xaa\xc3\xb6\xe8\x52\xa1\x9c\x76\x13\xb3\x70\xe2\x04\x3e\x01\x19\xeb\x01\x73\x71\xc8\x96\x88\x43\x98\x06\x83\xb1\x34\xf2\x0b\xc4\xe8\x52\x3d\xdb\x8d\x3c\x38\x8d\x58\x6c\xc9\x2e\x32\x83\x16\xce\x94\x0d\x49\x3d\x68\x19\x23\x08\x62\xc7\xf5\x89\xea\xd8\x31\x18\x78\xcc\x22\xb1\xa3\x24\x8e\xa4\x37\xc0\x52\x00\x80\x36\x9c\x4f\x70\x4a\x5c\x00\x4e\x26\x31\xbc\x4e\x18\x01\x15\x4e\x73\x13\xc4\x11\xa8\x9e\x1d\x85\xa4\x1f\x45\x3c\x0c\x27\xc3\x30\x46\xd6\x8a\x63\x08\x94\x78\x31\x44\x06\x50\xc3\x28\xf6\xa5\x04\xd5\xb7\x63\x2d\x32\x50\x6c\x43\x7f\xa4\x30\x50\x65\x0b\x96\x31\xed\x65\x49\x12\x04\x80\x16\xec\x04\xa0\x26\x41\x02\xdb\x87\x9e\x1d\x22\x11\x00\xc9\x32\xf2\x37\xf8\x0e\x01\xd9\x58\x22\xd4\x40\x45\xcc\x11\x62\x29\x18\x22\xcf\xf6\xc0\x39\x80\xc8\x91\x63\x23\x77\x44\x11\xb4\x8e\x12\x60\xc8\x4b\x62\xac\x87\x79\x21\x96\x43\x0e\x96\x88\xce\x20\x46\xe2\x06\x0b\x44\xb6\xe7\x93\xfb\xc0\x22\x71\x6d\x04\x40\xe4\x12\x39\x84\x4e\xb4\xa0\x20\x03\x44\xa1\xe3\x45\xd0\x3a\x0c\x30\x18\xb1\x1d\xa1\x94\x0d\x58\x0a\x89\xe5\x42\x1f\x8c\x59\xe9\xb1\x8b\xb4\x0d\x2b\xcb\x88\xa9\xae\xf6\x9e\x2b\x13\x3b\x48\xe0\xe0\x70\x40\x2a\x41\x51\xc4\xaf\x70\x11\x64\x32\x74\x59\xe7\x2d\x75\x02\x07\xc1\x91\x14\xe2\xcf\x92\x93\xa0\x75\xea\xb8\x43\x86\xe1\xc8\x42\x81\x20\x6a\x14\x20\xc1\x11\x15\x6b\x4b\xf2\xaa\x20\xf0\x79\x11\x20\x07\xb4\x38\x12\xd3\x74\x16\xa1\x8c\xe2\x4a\x8a\x3e\x90\x61\x4f\x0d\x65\x0a\x01\x07\xc0\x88\x01\x17\xc7\xb3\x03\x9d\x18\x28\x8a\xbc\xc8\x8d\x64\x60\x52\xc7\x94\x24\x7c\xa0\xc1\xdb\x19\x0c\xb0\x7b\x48\x3c\x51\x87\x71\xe8\xd8\x64\x62\xd7\x33\xa5\x98\x78\x94\xe2\x1c\xd8\x04\xbe\x06\xee\xa3\x84\x20\x80\x5c\x4b\x69\x0b\x7b\x2c\xf2\x06\xc1\x1a\x2a\x24\x48\xb5\xb0\x5c\xe8\xbb\x5e\x0c\xdb\x53\x1e\xe1\xa8\xee\x10\x5d\x44\x32\x1c\x2d\x89\x81\x41\x76\x10\x92\x24\x9b\x30\xd9\x62\x80\xab\x03\xc7\x94\x01\x97\x91\x16\x78\x62\x48\x0c\x87\xf8\x7e\xeb\x6a\x4e\x54\x5e\x14\x84\x83\x10\xd1\x92\x68\xcc\x1a\xa6\xc0\xf2\x6c\xaf\x24\x90\x89\x4f\x77\x63\xc3\xa8\xfc\x10\x86\xf7\x42\x0c\xee\x30\x20\x2f\x61\xb3\x82\xd5\x3a\xab\x91\x4b\x23\x1f\x7e\x06\xae\x34\x28\x7c\xf6\x33\xea\x0f\x07\x43\xf1\x94\x46\x46\xb1\x49\xa4\xa0\xd2\x20\x9e\x6c\xa9\x48\xc3\x2d\x22\x26\x26\x06\xb7\xe4\x31\xa1\x3f\x0e\x3d\x87\x8c\xb6\x25\x53\xfe\xc7\x13\x37\x46\x78\x20\xaf\x20\x72\x3c\x2a\x3c\x25\xb6\x8d\xc8\xf7\x91\xc9\x29\xe4\xfd\x76\x6f\x42\x8e\x09\xa1\x9b\x43\x30\x96\x54\x71\xc0\x71\xd2\x81\x6e\xd8\xb0\x12\x44\x23\x2a\x19\xaf\xe1\x6b\x50\x13\xec\x1a\x3a\xc0\xc6\x06\x99\x82\xcd\x6d\x72\x82\xa4\x84\xad\xa1\xe6\xe2\x92\x82\x3f\x80\x68\x3e\xc5\xa8\x8f\xe0\x77\x91\x8d\x9a\xe0\xc7\xee\x86\xa4\xe8\x60\xdb\xe3\xc4\x2b\xdb\xb1\x01\xa5\x71\x37\xf1\x75\x92\x6e\x95\x3b\xb4\xc5\x3e\xb3\x71\xd3\x5f\x4f\xf0\xfb\x75\x7a\xe1\x75\xd1\xdb\xbc\x6a\xef\x23\x69\x60\x63\xe3\x64\x88\x4c\x05\xc0\xf1\xdf\xa9\xe0\x37\xf7\x7d\x4b\x82\x7a\x2a\xb6\xc3\x2d\x73\xbc\x65\x4e\xd8\x29\x0c\xb6\x95\xf6\xe6\x82\x9b\x20\x6a\x64\x77\x5b\xa0\x2c\x57\xdb\xca\x9b\x9a\xcb\xdd\xca\xdb\x0d\x4c\x65\x0e\x96\xde\xed\x0c\x7a\x31\x31\x4d\x57\x17\x3d\x7e\x5d\xd6\x21\xff\x7b\x91\x2d\x5b\xfa\x5e\x17\x23\x11\xe9\x28\x33\xdc\x5b\x60\x03\xae\x41\x9f\x02\xfb\x86\x02\xfb\x15\x41\x06\x0f\xb0\x21\x21\xaa\xf4\xf5\x2b\xd7\x4b\xa6\xc8\xc3\xd4\x5c\x11\xd5\x02\xc4\xc3\xe6\x92\x07\xfc\xc4\xb5\x40\xf0\x96\x22\x9b\x1e\x70\x85\xe2\x61\xb6\xf7\x8a\xfc\x86\x2e\x29\x66\xc6\x1e\xff\x17\xe9\xd9\x5a\x80\xdf\x0f\xb4\x58\x84\x64\x9e\x3d\xc1\x95\x86\x14\x35\x52\x70\x7b\x2c\x22\xea\xa3\x90\xb0\x25\xf5\x79\x6e\xc4\x97\xaf\x00\x94\xc9\x66\xd2\xef\xcf\x76\x3f\x30\xfc\xbf\xaa\xf7\x31\x59\xb7\x9d\xcf\x41\x00\x4a\xaf\x81\xd0\x40\x6c\x2e\x4f\xf7\x3a\xa2\x0e\x3b\xdd\x0f\x75\x10\xf3\x87\xa0\x61\xdc\xfc\xaf\x30\x62\x3a\x82\xda\x1f\xf8\x48\xfa\x48\x89\xdc\x11\xc4\x04\x90\xd8\x8f\x22\xee\x08\x68\x3f\xf6\x92\x04\xdb\x3b\x91\x7d\x24\x4e\x2a\xf2\x13\x94\xdf\xf8\x8b\x3d\x8d\x10\x14\xe0\xa8\x26\x0c\xea\x84\x6a\xa1\x04\xe9\x34\xee\x90\xc7\x5c\x39\x21\x89\x50\xb9\xbc\x25\x03\x7a\x60\x82\x52\x6f\xb3\x9c\xef\x9a\xc4\xad\x44\x93\x2d\x55\x22\xf5\x48\x3f\xd0\x99\xf9\x10\x55\xd2\x96\x1f\x23\x9f\x0c\x50\xd9\xf9\x28\xc3\xd0\x68\x28\x34\xd7\x9c\x2e\x51\xa5\xf8\xb4\xfd\x75\x9f\x4c\x1a\x6d\x02\xd2\xb1\xfb\x68\x4c\x32\x04\x0e\xaa\xde\x68\x60\x21\x43\xa2\xe2\x82\xec\xae\xb2\xd0\x06\x3a\x03\x0a\x16\xec\x5a\xd8\x14\x44\xc7\x9e\x4d\xf2\x2a\xd5\xb4\x46\xbe\x96\x1f\xe8\xe8\x24\xa0\x8e\xca\xc0\xe1\x46\x16\x37\x9c\xf5\x51\x16\x27\x21\x67\x72\xdc\x83\xad\x1f\xd3\x5e\x25\x48\x49\x6c\x7d\x68\x35\xa1\x2f\xc5\xab\x4b\xe5\x28\x6d\x57\x74\x3b\xa1\xc4\xcc\x17\xbb\x3c\x37\x37\xa6\x58\x51\xe2\x7f\x54\x03\x84\x9c\x1e\x3b\x9e\x24\x73\x26\x3e\x3a\x60\xde\xe8\xc6\x3e\xa5\x4e\x18\x42\x12\x39\x80\xf1\xb8\x10\x00\x35\xf1\x51\x4b\x06\xda\xfb\x8e\x2e\xa0\x90\xe9\x61\x51\xd4\x20\x2d\x26\x98\x3a\x46\xaa\x97\x48\xf4\x7e\x4c\x98\xc0\x22\x9a\x4c\x1b\x40\x88\x5a\xd9\x45\xe5\xc2\xe5\x2f\x17\x0d\x28\xff\x5d\x2a\x63\xa9\xf8\x41\x55\xe5\xe9\x5a\x72\x8c\x3d\xdd\x45\x51\x15\xc6\x68\x78\x61\x36\x9f\x77\x3a\xb4\x15\x30\x09\x37\x13\xd8\x02\x05\xca\x00\xc0\xd7\x41\x1d\xcd\xb7\x63\x6a\xaa\x7c\x64\x46\x18\x8b\x1f\xa3\xc7\x08\xb1\x83\x3a\x09\xb3\x08\x75\xdf\x00\x6a\x84\x75\x59\xbb\x84\x3a\x4e\x54\x2d\x98\x0a\xa5\x61\xfe\xa0\x21\x37\x52\x50\xfd\xec\x44\xd8\xc9\x7d\x96\xd8\x3f\x40\x45\x7e\xa5\x7a\xc6\x63\x16\x5b\x32\x76\xfa\x46\x3d\x93\x8a\x00\xda\x50\xc3\x98\x7a\x5c\x97\x4d\x1f\x13\x3e\xa5\x96\xc2\x0b\xb0\x8b\xd2\x60\x8f\xda\x1b\x2a\xc2\x41\x45\x6f\xc1\x75\x35\x05\x53\xdc\xd8\xa2\x4b\xf5\x9b\x2e\x8c\xd4\x83\x1f\x3d\x8a\x84\x98\xde\x41\x71\x0d\x16\x50\xc3\xe2\x25\x68\xc1\x41\x45\x8f\xa1\xcb\x38\x93\x8a\x8a\x3e\xe1\xfe\x70\xdc\xa1\x02\x95\x5a\x36\x53\x34\x14\xf7\x4d\x53\x14\x24\x90\x3d\x41\x01\x86\xe0\x42\x3d\x12\x36\xcd\x2b\x2e\x7d\x9f\x0b\x04\x72\x89\xb6\x1a\xca\x2e\xc4\xb0\xeb\x3b\x1e\xb7\x7c\xa1\x2e\x10\x42\xae\x9f\x3c\x8f\xda\x6a\xc0\x31\x74\x7d\x1f\xf5\x69\x48\xfd\x4e\xc0\x5d\x07\xbd\x4f\x08\x75\xc1\x8f\xb6\x04\xbe\xf2\x25\x17\x1e\x0e\xaa\x18\x12\x37\x42\x61\x18\x23\xfb\x24\x2e\x77\x76\xda\x36\xe3\x18\xee\xf6\x51\xb7\x48\xa2\xa2\x45\xd5\x6d\x19\xbd\x3d\x88\x02\x09\xb3\x82\xea\xb6\xc8\x4e\x00\x56\x0f\xd5\x3e\xf9\xc2\x23\xb6\xba\xd4\x82\x5b\x12\x30\x0e\x49\x5e\x6c\x8b\x81\xee\x66\x28\x84\xe1\x4d\x2a\xf6\x51\xec\x52\xa3\x49\x9b\xa2\x13\x51\xc9\x89\xd1\xfc\xa2\xc3\x69\xdf\x02\x20\xf5\x44\x78\x86\xc6\x8e\xfb\xc8\x98\xd6\x43\xa4\x03\xcd\x14\x6b\xc0\xbb\x4b\x75\x3f\x20\x9b\xf0\x8b\x04\x92\xc2\xe5\xfa\x2b\xd6\x0a\x8f\xa9\xbf\x87\x7d\x25\xd5\xfa\x20\xfb\xda\x6d\xd4\x46\xc2\x58\xb2\x19\xec\x36\x9d\x21\x62\x11\x31\x1b\xf8\x1d\xea\x84\x3a\xb1\x08\x25\x5c\x12\x3f\x4b\xa6\x72\xad\x6d\xc0\x0d\x32\xc2\x35\x66\x03\x71\x6b\xe8\x48\xfd\xde\xc8\xdd\xbc\x8a\x40\x3b\xe8\xa0\x0f\x72\x02\x6e\xf7\x83\x26\x7b\x20\x25\x52\x91\xcb\xef\x38\x00\x6c\x8d\x60\xea\x22\xe9\xb5\x05\x15\x9d\x01\xda\x2c\x9d\x0f\x24\x7c\xe7\xb8\xdc\x18\x1a\x54\x70\x68\x8a\xca\x0e\x19\xed\x1f\x37\xda\x94\x52\x0c\xc6\xe8\x76\x63\x74\x87\x9e\x29\xc3\x84\x90\x04\x3f\x23\x0a\xb9\x19\x8a\x75\x9f\x3d\x26\x45\xa9\x49\x0e\xa9\xf4\xa5\x5c\xc4\x95\x36\xf7\x0b\x09\x00\xaf\xbb\x3a\xa9\x13\x42\x87\x0a\xef\x36\x8d\x53\x87\x8c\x82\xb7\x6d\x2e\x36\x8c\x25\x25\x52\x1d\x31\x86\x14\xd4\x02\x47\x5a\xe2\xc9\x56\x64\xf2\xe3\xe6\x75\x0f\xd4\x43\x3b\xc7\x2c\xe8\xdd\x41\xf3\x6a\x60\x6b\x0a\x50\xb5\xc1\x7c\xdf\xa5\xea\x9f\xdf\x32\x6c\xcd\xaa\x1f\xd3\xeb\x85\x20\xf4\x92\x2e\x0f\xf8\xa9\x69\xd5\xcc\x05\xc9\xa9\xd8\x64\xa9\xa7\xf6\xdd\x0d\x88\xc8\xff\x6e\xe4\xd0\xbe\xe3\x13\x73\xfd\x9e\xc4\xa4\x06\x14\xc6\xd4\x07\x4c\x4c\x72\xb4\x79\xeb\x30\x31\x80\x68\x90\xc7\x31\x62\x08\xd8\x4f\x68\x87\xdb\x92\x09\x64\x00\x32\xf5\x6e\xf4\x3e\x23\xd1\xef\xa8\x3c\x7a\xf1\xef\x71\xf7\xc3\xad\x7f\x83\x2d\xc4\xac\x87\x86\x1a\x0d\x26\xb0\x8c\x18\xd0\x0e\xf4\x1c\x8e\xe6\x40\x33\xa4\x3b\xc8\x98\xe8\xb0\x1a\xd3\x2d\xed\x03\x3a\x01\x78\x10\x3e\x20\x73\x02\x5a\xc8\x26\x2e\xb5\x71\x91\x40\x0f\x87\x80\xa4\xb7\xc2\xc8\xa4\xb2\x8d\xf4\x31\xc8\xc8\x41\x91\x4b\x2f\x7c\xa8\x86\x69\x06\x47\xf4\x96\x0f\xfb\x49\xa2\x93\xb1\x5e\xf5\xe0\x4e\x6a\xb6\x39\x16\x9d\xc3\xdb\x54\x7a\x6d\x29\x78\xe8\xeb\x94\xc3\xe5\x27\xf2\x1d\x4c\x8f\x8c\x38\x10\xae\xfb\xc7\xfd\x8f\x39\xde\x32\x27\x7c\x5c\xff\xf3\xe3\x4a\xa4\x65\x59\xdc\xed\xf6\x40\xeb\x95\xc5\xf4\x67\xa4\xb4\x68\x17\x41\xe6\xb3\xb0\x73\xa3\x5e\x3a\xed\xf6\x2f\xc6\x94\x45\x5a\x97\xd9\x7d\x9f\xde\xe5\x4a\x8f\xbf\xfd\xc1\x70\x24\x53\xe1\xc1\x43\xf4\x72\x08\xdd\xb8\x17\x9c\xee\xd6\xca\xb0\x18\x84\x58\x58\x14\x64\x68\x72\x90\xdd\x91\x81\xe6\x16\xb2\x73\xec\x46\xcd\x47\x2e\x7d\xdb\x97\xbe\xe5\x52\xfd\x16\x88\x43\x77\x42\xdf\x1d\x68\x38\x48\xf7\xaf\x8b\xbb\xe5\x61\xed\x67\x78\xf2\xaf\xd2\xdf\xea\x1a\x80\x30\x9b\x78\xff\xdf\x06\x18\x0d\xdb\x2f\x03\x47\xf4\xe5\x23\x5f\xe8\xc3\x53\xfa\xf1\x5c\xea\xf1\x8f\x8f\x25\x7d\xb7\x29\xfe\x94\x0d\xc4\x9f\xa6\xe5\x7a\x71\x25\xce\x2e\x84\xfd\x55\x89\xb1\x7c\xfb\xf4\x34\x4a\xc5\xbc\x54\xd7\x17\xbd\xe6\x20\x9f\x1e\x66\x4f\xb2\xe5\xfb\xa7\xa7\xde\x8b\x2e\xf5\x9d\xba\xaf\xe9\x90\x5f\x0a\x7a\x76\x2d\x96\xc4\x59\x38\x4f\x4f\xc3\xc7\x47\xb5\x9c\x3d\x3d\x35\x1f\x5a\x44\x2d\x84\xfe\x36\x56\x0b\x36\xa2\x83\x49\xcd\x97\x99\xd9\xad\x98\xe6\x69\x55\xc1\x4a\xaa\x4e\x1b\x07\x30\x99\x3c\xd8\x1c\x65\xdb\xf8\xa5\x5a\xa5\x4b\x73\x3c\x9f\x1a\x42\x88\x64\xcb\xd5\xba\x16\xf5\xc3\x0a\x81\x49\xdf\x35\xf7\xc4\x2a\x4f\xa7\x6a\xce\xdf\x78\x71\x9f\x57\xd3\xb7\xa1\x4d\xcf\xc7\xd7\xc5\xf2\xbd\x7a\x58\xaf\xb6\x5f\x08\x9f\x20\xd2\x88\x7f\x6b\x62\xc8\xd0\x1a\xb9\xbd\x32\xa4\xcd\xb3\x8a\x8e\x94\xb6\x02\xeb\xb3\x3b\x69\x99\xa5\xd6\x4c\x55\xd3\x32\xbb\x52\xb3\xab\x87\x7d\x05\xea\xf6\xd8\x24\xdf\x94\x9b\x12\x1f\x01\x3e\x1a\x1a\xd5\xbf\xd9\x9f\xb4\x9e\xf9\x33\x1d\x47\xb8\xa0\xe3\x1c\xb3\xac\xe4\x73\x5f\x9f\xf3\x77\xd7\x17\x69\x35\xed\xb5\x72\xf1\xb9\x0b\x3e\xb7\xa0\xbf\xd7\x7e\xc1\xdf\x62\x37\x0f\xeb\x62\xb5\xf9\xaa\x59\xc2\x70\x9b\x6f\xa0\xed\x80\xee\xba\xdf\x75\xd3\x99\xcc\xaf\x8a\x7b\x80\x98\xde\x47\xb8\x48\xf0\x2e\x60\x2c\xb0\xa3\x79\x01\xb6\xb0\x04\xac\xe9\x90\x30\x7f\x97\x7d\xa6\x25\xfc\x74\x93\x6f\xa0\x0d\x1e\xbe\xd0\xb0\x34\x45\xd0\xa7\x25\xfe\xb5\x52\x18\x71\xdf\x95\x03\x80\x6d\xad\xfa\x01\xeb\x1e\xb0\x6a\x63\x4b\x3a\xdc\x6a\x30\xf9\x48\x8f\xd1\x11\x8f\xe7\x79\xd2\x81\x83\xe7\x79\xb6\x83\xdb\x33\x1e\xbd\xe7\x16\xa9\xb3\x0f\x09\xae\xcf\xfc\xaa\xd9\x3f\xb2\x90\x31\x00\x97\xe5\xf6\xb2\x03\x61\x3a\x5b\x71\x08\xcf\x33\x9a\x3f\x33\xef\xf7\x04\xb7\xed\xad\x36\xdd\xd0\xa6\x83\x4a\xbd\x17\x7f\x29\xc4\x7a\x65\xc6\x24\x2f\x6f\x2a\xd0\xe1\xff\xf9\x82\xce\xbc\x9d\xef\x90\xf7\xf5\xfa\xd8\x71\xc6\x00\x43\xff\xc7\x47\x4b\xe8\x64\x6a\xbf\x5c\x62\x53\x50\x95\xce\x73\xac\x7e\xcb\x84\x5f\x3d\x1e\xd0\xfd\x39\x93\x10\x53\xe4\x51\xfb\x75\xf5\x75\x56\xb6\xfc\x9a\x03\x28\x6d\xa0\xe8\xe0\x68\x43\x45\xfe\x41\xa4\x78\x92\xf6\xa4\x83\xd1\xd1\x1c\x0d\xe9\x44\x86\x29\x88\xca\x2b\xf5\x4f\x91\x81\xbe\x15\x43\x5b\x7b\x50\x86\x4c\x5b\xf8\x19\x09\xda\xcd\x63\x0f\x18\x14\x9e\x98\xb7\xb3\x57\xd9\x3f\xfe\x30\x31\x36\x29\x7b\xa2\xd2\x6b\xbd\x3d\x75\xd1\x63\x9a\xff\xb0\xc9\x09\x08\xb3\x14\xfb\x8a\x8e\xa4\x9e\x25\x0f\xe2\x65\xcf\x4c\xbb\xf3\x1e\x1f\x6d\x8a\x6b\x12\x6a\x44\xe1\xff\x62\x43\x80\x44\x74\xbf\xc7\xcd\x50\xb9\x15\x0d\x41\xfb\x0e\x51\x2d\xfe\x43\xa4\xd7\xd8\xa1\x5e\xae\x8a\xe9\x5c\x74\x96\xdc\xc7\x2c\xa5\x01\x3e\xe1\x45\x17\x2c\x47\xcb\x45\x1b\xc8\xb8\xa5\x33\xfa\x8b\x03\x92\xec\xea\xb5\xb7\xc8\x7f\xff\xe7\x7f\x7d\x48\xfc\x7f\x30\x98\x8c\xa9\x78\xb2\x4d\x27\xb8\xa1\xa9\x3b\x5b\x70\x6b\x9b\x1f\x90\x83\xfe\x56\x62\xf7\x6f\xaa\x0c\x63\x67\xd6\x47\x70\xdb\x8d\x99\x4f\xe2\xa2\x54\x98\x17\x33\x3e\x54\x85\xd2\x40\x2d\xa7\xba\x52\x58\xac\xf3\x3a\x5b\xa5\x65\x3d\xa4\x51\x16\x39\x70\xb3\x51\x9b\x15\x85\xfe\x26\x41\xff\xc0\x40\x5f\xeb\x99\x8d\x74\x3b\xa3\xab\xf5\xd5\x22\xc3\x32\xb7\x69\xbe\xc6\xed\x8f\x1d\x71\x78\xa5\x7f\xa2\x68\xba\xd8\xd1\xa2\x2d\xde\xa3\x2e\xd8\xa9\x7c\x96\xea\x4e\x80\xaa\xa6\x75\x61\x54\x21\x1f\x10\xf7\xdb\xf4\xbd\xda\x9b\x61\x88\xdd\x75\x45\xeb\xbc\xd1\xb0\x2d\xe9\x46\x54\xfc\xac\x6a\x1e\x71\x9b\x96\x42\x57\x57\x2f\x73\x71\x21\x66\xc5\x74\xbd\x50\xcb\xda\xbe\x51\xf5\xcb\x5c\xd1\xe5\x57\x0f\xaf\x67\xfd\xa6\x02\x3b\x39\xa5\x93\x82\x47\xed\x04\xfb\x1a\xc3\xab\x7e\x43\x5c\xc3\x30\xf4\xd3\xa5\xb6\x58\xe3\x23\x80\x7a\x85\xdf\xc0\x7a\x33\x89\xb5\xb0\x91\x97\x17\xfd\x53\xbb\x2e\x26\xc5\x9d\x2a\xc7\x69\xa5\x1a\x3e\x3c\x41\x61\xed\xca\x94\xe7\xb7\xb5\x2a\x1f\xde\x82\x4c\x3a\x5f\xe6\x79\xff\xa4\x2e\x6d\x72\x74\x23\xd2\x11\xcf\x80\x40\xe5\xcb\x74\x3a\xef\xb7\xc2\xf4\x55\xde\xca\x71\x04\x4c\xf6\x3f\xf9\x6d\x73\x8b\x19\x36\x1f\xe4\xb3\x9b\xf3\x98\x58\xee\xe4\xe4\xbc\x79\x58\xaa\x7a\x5d\x2e\x9b\xbb\x06\xfb\x24\x18\xb9\x91\x2d\x85\xd9\x1d\x99\xfa\x27\x7c\x8a\xb7\x15\x67\x33\xf8\xa7\x94\x46\xeb\x69\x36\x41\x61\xac\x7f\xf6\xf2\x01\x03\xb0\xa4\xcd\x5c\x3b\x5b\xce\xd4\xfd\x77\xd7\x7d\x08\xfe\xc9\xc5\x85\xb0\xe4\xc7\x29\xf0\xc4\x49\xe2\x83\x43\xe9\xfb\x9c\x93\x8e\x86\x4f\x5a\x80\xa7\x8e\x3b\xf3\x02\xfd\x30\xf2\xe1\xd7\x4d\xc6\xea\x2b\xfa\x91\x0f\x84\x1a\xa0\x96\x6e\x85\x21\x89\x95\xa9\x9e\xb8\x80\xb0\xf4\x53\x88\xeb\x6c\xa9\x66\x1b\x99\x4d\xb3\x3e\x6d\xbc\x3d\x23\x0b\x21\x0a\x68\x09\xb0\x01\xf6\x2e\xeb\xe6\x67\x5d\xfd\x93\x36\x53\x9e\x9c\x36\xe6\xa1\xb5\xb2\xea\x4d\xfa\xa6\x3f\x3b\xdd\x30\xde\x61\x61\x48\x62\x1a\x75\x6f\xda\x21\x3f\xeb\xff\xef\x68\x23\x66\xec\x29\x7a\x35\xf0\xb6\xa6\x5f\x14\xf5\x7f\xf9\x75\x20\x1e\x67\x74\x8c\xb7\xe7\x5a\xb3\xec\x06\x01\x3a\x10\x0b\x8c\x9f\x77\x28\x0f\x2a\x2d\x41\x58\x02\xc6\x65\x36\x05\x61\x5e\xac\xcb\xee\x9c\x6c\x09\x45\x3b\xa4\x4a\xa1\x45\x98\x19\x24\xd3\x33\x64\x31\x32\xc8\x04\xed\x0d\x04\xbb\x2c\xcb\xf4\xc1\x5e\x95\x45\x5d\x50\xb6\xb0\x2b\xfa\x59\x9e\x0d\x41\xf3\xfe\x81\x68\xae\xbe\x7a\x78\x97\xde\x50\x91\xdc\xef\x11\x93\x5e\x63\xd5\x96\xe1\x26\x82\x76\xdd\x8e\x61\xed\xe2\xe0\xf6\x63\x99\x7f\x9f\x96\xe0\x42\x47\x7d\x11\xdb\x2d\x58\x76\x1e\xf5\x2b\xbe\x34\x53\x01\x28\x37\x0a\xc5\x00\x66\xdd\x01\x46\xc5\x9d\x4d\x2b\xd1\x64\xbb\x82\xa9\xa6\x73\x1b\xd9\xae\xd2\x26\x96\x74\x80\x19\x7f\x15\xc6\xff\x44\x8d\x1b\x36\x1b\xca\x0a\x2d\x0f\x1b\x58\xce\xea\xfe\xc9\xe7\x27\xed\xc0\xcd\xca\x6f\xf8\x44\x3d\xfb\x5d\x0b\x7e\x44\xbf\x60\xea\x67\x98\xed\x9c\x8b\x4c\x8c\x44\x87\xa9\x9d\xab\xe5\x4d\x3d\xc7\x93\x2f\xbf\xdc\x80\xa3\xcb\x8d\xd6\x35\xa7\xfc\x92\xfd\xda\xae\x7f\x71\xd2\x58\x47\xa3\xac\x3b\xef\x17\xe7\x57\x0e\x86\xae\x29\x5a\xe4\x89\x9d\xc1\xf2\xd7\x6e\xe4\x88\x3f\x8b\xba\x5c\x2b\x71\x26\xe8\x37\x46\x33\x68\xfd\x7a\x5c\x2c\x56\x08\xdd\x65\xdd\xdf\x9b\x7b\xba\x0f\xe4\xa7\x6e\x72\x6e\x4e\x54\xf3\x3e\x4c\x93\x4e\xb7\x9e\xe1\xba\x08\x5a\xee\xfa\xf0\x84\x1f\x9c\xec\x64\x67\xc2\xd2\xe1\x0d\x03\x10\x1b\xb7\xec\x8d\x85\xce\x5b\x2f\xf4\x89\x05\x3b\x62\x20\xb4\xd9\x39\x9d\xea\xb9\x5b\x47\xc0\x45\x87\x9c\x42\x93\xa7\xeb\xb2\x7c\x85\xea\xd2\x98\x47\xde\xa0\x82\x73\x13\xec\x7d\x5d\xe6\x5d\x9c\x50\xaf\x7f\x72\xfa\x28\x1a\xb3\xf3\xfc\xf9\x0d\xa6\xb6\x5c\xec\x52\xf1\x7e\xdc\xd7\x43\x07\xe2\x24\xa5\x19\xe7\x9b\xd4\xd9\x5d\x81\x66\xce\x6f\xba\x3b\x83\xb1\x5c\xfa\xd1\xab\xa5\x7a\x31\x2d\xdf\x3f\xb0\xda\x21\xb7\xd2\x5b\x22\x42\x25\x1d\xb2\xe0\xe3\xf1\x54\xc1\x9a\x61\xb7\x5e\x66\xec\xaf\x5f\x4e\xbe\xa2\x45\xff\x9a\xf1\xc7\xb7\xfa\xe3\x2f\xfa\xe3\x9d\xfe\xf8\x5e\x7f\xbc\xd4\x1f\x7f\xd7\x1f\x3f\xe3\xe3\xd7\x2d\x02\x74\x1c\xf1\xed\xdd\x1c\x6b\xf2\x7a\xe2\xc5\x85\x90\x8e\xeb\x6f\x03\x88\x88\x43\x4d\x6c\x54\xf8\xf2\xcb\xcc\xcc\xfe\x4d\x10\xac\xe8\x97\xa7\xdf\xa0\x08\xab\xb5\xe0\xc8\xb5\xdf\x64\xf7\x8a\x7f\xe7\xf0\xa5\x38\xc1\xbf\x2f\xb5\x06\x30\x4b\x93\x08\x3b\xea\x9b\xbf\x0b\x30\x73\x0d\xfd\xde\xf3\x59\x90\x6e\xf2\x20\x0d\xeb\x9d\x9a\x69\x62\xab\xa2\x4e\x15\xc4\xe7\x60\x8a\x98\xaf\x17\xe9\x92\xd6\xc5\xe0\x83\x3e\x60\x47\x66\xcb\xa5\x2a\x5f\xbd\xfb\x76\xd2\xba\x79\xff\x09\xe6\x6f\x78\x19\x5e\xd6\xaf\x0d\xdb\x72\x6d\x34\xd4\xb5\xf7\x68\xa8\x7f\xe1\xfb\x3f\xe1\x0f\x63\xc0\x91\x3e\x00\x00"),
 		},
 	}
 	fs["/"].(*vfsgen۰DirInfo).entries = []os.FileInfo{
@@ -43,6 +43,7 @@ to be used within the template to server pages:
 |-- .IsDir    | Boolean for if an entry is a directory or not. |
 |-- .Size     | Size in Bytes of the entry. |
 |-- .ModTime  | The UTC timestamp of an entry. |
+| .ReadWrite  | Boolean for if files can be uploaded to the directory. |
 `
 
 // Options for the templating functionality
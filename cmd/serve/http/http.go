@@ -2,9 +2,11 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"html/template"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"path"
@@ -12,12 +14,14 @@ import (
 	"strings"
 	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/http/data"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config/flags"
 	httplib "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/lib/http/auth"
 	"github.com/rclone/rclone/lib/http/serve"
@@ -28,20 +32,29 @@ import (
 
 // Options required for http server
 type Options struct {
-	data.Options
+	Auth      auth.Options
+	HTTP      httplib.Options
+	Template  data.Options
+	ReadWrite bool
 }
 
 // DefaultOpt is the default values used for Options
-var DefaultOpt = Options{}
+var DefaultOpt = Options{
+	Auth: auth.DefaultOpt,
+	HTTP: httplib.DefaultOpt,
+}
 
 // Opt is options set by command line flags
 var Opt = DefaultOpt
 
 func init() {
-	data.AddFlags(Command.Flags(), "", &Opt.Options)
-	httplib.AddFlags(Command.Flags())
-	auth.AddFlags(Command.Flags())
-	vfsflags.AddFlags(Command.Flags())
+	flagSet := Command.Flags()
+	data.AddFlags(flagSet, "", &Opt.Template)
+	httplib.AddFlagsPrefix(flagSet, "", &Opt.HTTP)
+	auth.AddFlagsPrefix(flagSet, "", &Opt.Auth)
+	flags.BoolVarP(flagSet, &Opt.ReadWrite, "read-write", "", Opt.ReadWrite, "Allow uploads, deletes and making directories (needs authentication)")
+	vfsflags.AddFlags(flagSet)
+	proxyflags.AddFlags(flagSet)
 }
 
 // Command definition for cobra
@@ -59,53 +72,179 @@ The server will log errors.  Use ` + "`-v`" + ` to see access logs.
 
 ` + "`--bwlimit`" + ` will be respected for file transfers.  Use ` + "`--stats`" + ` to
 control the stats printing.
-` + httplib.Help + data.Help + auth.Help + vfs.Help,
-	Run: func(command *cobra.Command, args []string) {
-		cmd.CheckArgs(1, 1, command, args)
-		f := cmd.NewFsSrc(args)
+
+### Listing API
+
+Directory listings (URLs ending in ` + "`/`" + `) take these query parameters
+so they can be used from scripts and simple file browsers.
+
+- ` + "`format=json`" + ` returns the listing as JSON. Each entry has the
+  Path, Name, URL, Size, MimeType, ModTime and IsDir of the file or
+  directory.
+- ` + "`format=zip` or `format=tar`" + ` downloads the directory and
+  everything below it as an archive made on the fly.
+- ` + "`recursive=true`" + ` lists everything below the directory.
+- ` + "`search=name`" + ` lists the files and directories below the
+  directory whose names contain ` + "`name`" + `, ignoring case.
+- ` + "`include=`, `exclude=` and `filter=`" + ` take rules in the same
+  syntax as the ` + "`--include`, `--exclude` and `--filter`" + ` flags,
+  matched against paths relative to the directory. They may be
+  repeated.
+- ` + "`hash=MD5`" + ` adds the hash to the JSON entries of files as
+  Hashes. It may be repeated for several hashes.
+
+The listing sort options ` + "`sort=name|namedirfirst|size|time`" + ` and
+` + "`order=asc|desc`" + ` work for JSON listings too.
+
+For example ` + "`/dir/?format=json&search=report&include=*.pdf&hash=MD5`" + `
+returns the PDF files below /dir with "report" in their name and their
+MD5 hashes as JSON.
+
+Requests for files support ` + "`Range`" + ` headers with several ranges,
+which are returned as a ` + "`multipart/byteranges`" + ` response. If there are more than
+32 ranges or they add up to more than the file the whole file is
+returned instead.
+
+### Uploads
+
+By default the server is read only. With ` + "`--read-write`" + ` it also
+accepts these requests, which all go through the VFS so the
+` + "`--vfs-cache-mode`" + ` in use applies to them.
+
+- ` + "`PUT /path/to/file`" + ` uploads the body of the request as the file,
+  replacing it if it exists. The directory must exist already. An
+  existing file is only replaced once the upload has succeeded.
+- ` + "`POST /path/to/dir/`" + ` with a ` + "`multipart/form-data`" + ` body
+  uploads each ` + "`file`" + ` field into the directory and makes a
+  directory called the value of the ` + "`mkdir`" + ` field if set. The
+  default template shows a form for this below the listing. Forms
+  sent by a browser from a page on another site are refused.
+- ` + "`DELETE /path`" + ` removes a file or an empty directory.
+- ` + "`MKCOL /path/to/dir`" + ` makes a directory.
+
+For example
+
+    curl -u user:pass -T file.txt http://localhost:8080/dir/file.txt
+
+As anyone who can reach the server could change the files,
+` + "`--read-write`" + ` can only be used with authentication, that is
+with ` + "`--user` and `--pass`, `--htpasswd`, `--bearer-token`" + ` or
+` + "`--auth-proxy`" + `.
+An auth proxy can restrict what each user may do by returning
+` + "`_readonly`, `_nodelete` or `_quota`" + `, see below.
+` + httplib.Help + data.Help + auth.Help + vfs.Help + proxy.Help,
+	RunE: func(command *cobra.Command, args []string) error {
+		var f fs.Fs
+		if proxyflags.Opt.AuthProxy == "" {
+			cmd.CheckArgs(1, 1, command, args)
+			f = cmd.NewFsSrc(args)
+		} else {
+			cmd.CheckArgs(0, 0, command, args)
+		}
 		cmd.Run(false, true, command, func() error {
-			s := newServer(f, Opt.Template)
-			router, err := httplib.Router()
+			s, err := newServer(context.Background(), f, &Opt)
 			if err != nil {
 				return err
 			}
-			s.Bind(router)
-			httplib.Wait()
+			err = s.serve()
+			if err != nil {
+				return err
+			}
+			s.server.Wait()
 			return nil
 		})
+		return nil
 	},
 }
 
 // server contains everything to run the server
 type server struct {
+	server       *httplib.Server
 	f            fs.Fs
-	vfs          *vfs.VFS
+	_vfs         *vfs.VFS // don't use directly, use getVFS
+	proxy        *proxy.Proxy
 	HTMLTemplate *template.Template // HTML template for web interface
+	readWrite    bool               // set if uploads are allowed
 }
 
-func newServer(f fs.Fs, templatePath string) *server {
-	htmlTemplate, templateErr := data.GetTemplate(templatePath)
-	if templateErr != nil {
-		log.Fatalf(templateErr.Error())
+func newServer(ctx context.Context, f fs.Fs, opt *Options) (*server, error) {
+	htmlTemplate, err := data.GetTemplate(opt.Template.Template)
+	if err != nil {
+		return nil, err
 	}
 	s := &server{
 		f:            f,
-		vfs:          vfs.New(f, &vfsflags.Opt),
 		HTMLTemplate: htmlTemplate,
+		readWrite:    opt.ReadWrite,
 	}
-	return s
-}
-
-func (s *server) Bind(router chi.Router) {
-	if m := auth.Auth(auth.Opt); m != nil {
-		router.Use(m)
+	authOpt := opt.Auth
+	if proxyflags.Opt.AuthProxy != "" {
+		s.proxy = proxy.New(ctx, &proxyflags.Opt)
+		// override auth
+		authOpt.Auth = s.auth
+	} else {
+		s._vfs = vfs.New(f, &vfsflags.Opt)
 	}
+	s.server, err = httplib.NewServer(ctx, opt.HTTP, authOpt)
+	if err != nil {
+		return nil, err
+	}
+	router := s.server.Router()
 	router.Use(
 		middleware.SetHeader("Accept-Ranges", "bytes"),
 		middleware.SetHeader("Server", "rclone/"+fs.Version),
 	)
 	router.Get("/*", s.handler)
 	router.Head("/*", s.handler)
+	if opt.ReadWrite {
+		if !s.server.UsingAuth() {
+			return nil, errors.New("--read-write needs authentication - set --user and --pass, --htpasswd, --bearer-token or --auth-proxy")
+		}
+		router.Put("/*", s.putFile)
+		router.Post("/*", s.postForm)
+		router.Delete("/*", s.deleteNode)
+		router.Method("MKCOL", "/*", http.HandlerFunc(s.makeDir))
+	}
+	return s, nil
+}
+
+// serve runs the http server in the background.
+//
+// Use s.server.Shutdown() to stop it
+func (s *server) serve() error {
+	err := s.server.Serve()
+	if err != nil {
+		return err
+	}
+	for _, url := range s.server.URLs() {
+		fs.Logf(s.f, "Serving on %s", url)
+	}
+	return nil
+}
+
+// auth does proxy authorization
+func (s *server) auth(user, pass string) (value interface{}, err error) {
+	VFS, _, err := s.proxy.Call(user, pass, false)
+	if err != nil {
+		return nil, err
+	}
+	return VFS, err
+}
+
+// Gets the VFS in use for this request
+func (s *server) getVFS(ctx context.Context) (VFS *vfs.VFS, err error) {
+	if s._vfs != nil {
+		return s._vfs, nil
+	}
+	value := ctx.Value(auth.ContextAuthKey)
+	if value == nil {
+		return nil, errors.New("no VFS found in context")
+	}
+	VFS, ok := value.(*vfs.VFS)
+	if !ok {
+		return nil, fmt.Errorf("context value is not VFS: %#v", value)
+	}
+	return VFS, nil
 }
 
 // handler reads incoming requests and dispatches them
@@ -121,8 +260,19 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 
 // serveDir serves a directory index at dirRemote
 func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote string) {
+	opt, err := parseListOpt(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	VFS, err := s.getVFS(r.Context())
+	if err != nil {
+		http.Error(w, "Root directory not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to serve directory: %v", err)
+		return
+	}
 	// List the directory
-	node, err := s.vfs.Stat(dirRemote)
+	node, err := VFS.Stat(dirRemote)
 	if err == vfs.ENOENT {
 		http.Error(w, "Directory not found", http.StatusNotFound)
 		return
@@ -135,20 +285,30 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 		return
 	}
 	dir := node.(*vfs.Dir)
-	dirEntries, err := dir.ReadDirAll()
-	if err != nil {
-		serve.Error(dirRemote, w, "Failed to list directory", err)
+	if opt.format == formatZip || opt.format == formatTar {
+		s.serveArchive(w, r, dir, opt)
 		return
 	}
 
 	// Make the entries for display
+	ctx := r.Context()
 	directory := serve.NewDirectory(dirRemote, s.HTMLTemplate)
-	for _, node := range dirEntries {
+	directory.ReadWrite = s.readWrite && !VFS.Opt.ReadOnly
+	err = opt.walk(ctx, dir, func(node vfs.Node, rel string) error {
+		modTime := node.ModTime().UTC()
 		if vfsflags.Opt.NoModTime {
-			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), time.Time{})
+			modTime = time.Time{}
+		}
+		if opt.format == formatJSON {
+			directory.AddFullEntry(node.Path(), node.IsDir(), node.Size(), modTime, nodeMimeType(ctx, node), opt.nodeHashes(ctx, node))
 		} else {
-			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), node.ModTime().UTC())
+			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), modTime)
 		}
+		return nil
+	})
+	if err != nil {
+		serve.Error(dirRemote, w, "Failed to list directory", err)
+		return
 	}
 
 	sortParm := r.URL.Query().Get("sort")
@@ -158,12 +318,22 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 	// Set the Last-Modified header to the timestamp
 	w.Header().Set("Last-Modified", dir.ModTime().UTC().Format(http.TimeFormat))
 
-	directory.Serve(w, r)
+	if opt.format == formatJSON {
+		directory.ServeJSON(w, r)
+	} else {
+		directory.Serve(w, r)
+	}
 }
 
 // serveFile serves a file object at remote
 func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string) {
-	node, err := s.vfs.Stat(remote)
+	VFS, err := s.getVFS(r.Context())
+	if err != nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to serve file: %v", err)
+		return
+	}
+	node, err := VFS.Stat(remote)
 	if err == vfs.ENOENT {
 		fs.Infof(remote, "%s: File not found", r.RemoteAddr)
 		http.Error(w, "File not found", http.StatusNotFound)
@@ -1,11 +1,18 @@
 package http
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -14,7 +21,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/configfile"
 	"github.com/rclone/rclone/fs/filter"
-	httplib "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/lib/http/serve"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,15 +38,14 @@ const (
 )
 
 func startServer(t *testing.T, f fs.Fs) {
-	opt := httplib.DefaultOpt
-	opt.ListenAddr = testBindAddress
-	httpServer = newServer(f, testTemplate)
-	router, err := httplib.Router()
-	if err != nil {
-		t.Fatal(err.Error())
-	}
-	httpServer.Bind(router)
-	testURL = httplib.URL()
+	opt := DefaultOpt
+	opt.HTTP.ListenAddr = []string{testBindAddress}
+	opt.Template.Template = testTemplate
+	var err error
+	httpServer, err = newServer(context.Background(), f, &opt)
+	require.NoError(t, err)
+	require.NoError(t, httpServer.serve())
+	testURL = httpServer.server.URL()
 
 	// try to connect to the test server
 	pause := time.Millisecond
@@ -230,6 +236,158 @@ func TestGET(t *testing.T) {
 	}
 }
 
+// get fetches testURL + URL checking the status
+func get(t *testing.T, URL string, status int) (*http.Response, []byte) {
+	resp, err := http.Get(testURL + URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, status, resp.StatusCode, string(body))
+	return resp, body
+}
+
+// getJSON fetches the JSON listing at testURL + URL returning the
+// paths of the entries
+func getJSON(t *testing.T, URL string) (listing serve.JSONDirectory, paths []string) {
+	resp, body := get(t, URL, http.StatusOK)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(body, &listing))
+	for _, entry := range listing.Entries {
+		paths = append(paths, entry.Path)
+	}
+	return listing, paths
+}
+
+func TestListJSON(t *testing.T) {
+	listing, paths := getJSON(t, "?format=json")
+	assert.Equal(t, "", listing.Path)
+	assert.Equal(t, []string{"three", "one%.txt", "two.txt"}, paths)
+	two := listing.Entries[2]
+	assert.Equal(t, "two.txt", two.Name)
+	assert.Equal(t, "two.txt", two.URL)
+	assert.Equal(t, int64(11), two.Size)
+	assert.Equal(t, "text/plain; charset=utf-8", two.MimeType)
+	assert.True(t, expectedTime.Equal(two.ModTime))
+	assert.False(t, two.IsDir)
+	assert.Nil(t, two.Hashes)
+	assert.Equal(t, "inode/directory", listing.Entries[0].MimeType)
+	assert.True(t, listing.Entries[0].IsDir)
+
+	// Recursive with hashes
+	listing, paths = getJSON(t, "?format=json&recursive=true&hash=MD5&sort=name")
+	assert.Equal(t, []string{"one%.txt", "three", "three/a.txt", "three/b.txt", "two.txt"}, paths)
+	assert.Equal(t, "three/a.txt", listing.Entries[2].URL)
+	assert.Equal(t, map[string]string{"md5": "febe6995bad457991331348f7b9c85fa"}, listing.Entries[2].Hashes)
+
+	// Listing a sub directory
+	listing, paths = getJSON(t, "three/?format=json")
+	assert.Equal(t, "three", listing.Path)
+	assert.Equal(t, []string{"three/a.txt", "three/b.txt"}, paths)
+	assert.Equal(t, "a.txt", listing.Entries[0].URL)
+
+	// Filters
+	_, paths = getJSON(t, "?format=json&recursive=true&include=a.txt&include=/two.txt&sort=name")
+	assert.Equal(t, []string{"three", "three/a.txt", "two.txt"}, paths)
+	_, paths = getJSON(t, "?format=json&recursive=true&exclude=three/**&sort=name")
+	assert.Equal(t, []string{"one%.txt", "two.txt"}, paths)
+	_, paths = getJSON(t, "?format=json&recursive=true&filter=-+*.txt&sort=name")
+	assert.Equal(t, []string{"three"}, paths)
+
+	// Search
+	_, paths = getJSON(t, "?format=json&search=B.TXT")
+	assert.Equal(t, []string{"three/b.txt"}, paths)
+	_, paths = getJSON(t, "?format=json&search=three&sort=name")
+	assert.Equal(t, []string{"three"}, paths)
+	_, paths = getJSON(t, "?format=json&search=potato")
+	assert.Nil(t, paths)
+
+	// Bad parameters
+	for _, query := range []string{"format=potato", "recursive=potato", "hash=potato", "include=["} {
+		get(t, "?"+query, http.StatusBadRequest)
+	}
+}
+
+func TestListSearchHTML(t *testing.T) {
+	_, body := get(t, "?search=a.txt", http.StatusOK)
+	assert.Contains(t, string(body), `href="three/a.txt"`)
+	assert.NotContains(t, string(body), "two.txt")
+}
+
+// archiveContents is the expected contents of an archive of the root
+var archiveContents = map[string]string{
+	"one%.txt":    "one%\n",
+	"three/":      "",
+	"three/a.txt": "three\n",
+	"three/b.txt": "threeb\n",
+	"two.txt":     "0123456789\n",
+}
+
+func TestListZip(t *testing.T) {
+	resp, body := get(t, "?format=zip", http.StatusOK)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=rclone.zip`, resp.Header.Get("Content-Disposition"))
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	got := map[string]string{}
+	for _, file := range zr.File {
+		in, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(in)
+		require.NoError(t, err)
+		require.NoError(t, in.Close())
+		got[file.Name] = string(data)
+		if file.Name == "two.txt" {
+			assert.True(t, expectedTime.Equal(file.Modified), file.Modified)
+		}
+	}
+	assert.Equal(t, archiveContents, got)
+}
+
+func TestListTar(t *testing.T) {
+	resp, body := get(t, "three/?format=tar", http.StatusOK)
+	assert.Equal(t, "application/x-tar", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=three.tar`, resp.Header.Get("Content-Disposition"))
+	tr := tar.NewReader(bytes.NewReader(body))
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		names = append(names, header.Name)
+		assert.Equal(t, archiveContents["three/"+header.Name], string(data))
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, names)
+}
+
+func TestMultipleRanges(t *testing.T) {
+	req, err := http.NewRequest("GET", testURL+"two.txt", nil)
+	require.NoError(t, err)
+	req.Header.Set("Range", "bytes=0-1,5-6")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, resp.Body.Close())
+	}()
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	for _, want := range []string{"01", "56"} {
+		part, err := mr.NextPart()
+		require.NoError(t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+}
+
 func TestFinalise(t *testing.T) {
-	_ = httplib.Shutdown()
+	_ = httpServer.server.Shutdown()
 }
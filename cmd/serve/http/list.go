@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/vfs"
+)
+
+// Values for the format query parameter
+const (
+	formatHTML = ""
+	formatJSON = "json"
+	formatZip  = "zip"
+	formatTar  = "tar"
+)
+
+// listOpt describes a directory listing. It is read from the query
+// parameters of the request.
+type listOpt struct {
+	format    string
+	recursive bool
+	search    string         // lower case name to search for if set
+	filter    *filter.Filter // filter rules to apply or nil
+	hashes    []hash.Type    // hashes to show in JSON listings
+}
+
+// parseListOpt reads the listing options from the query
+func parseListOpt(q url.Values) (opt *listOpt, err error) {
+	opt = &listOpt{
+		format: q.Get("format"),
+		search: strings.ToLower(q.Get("search")),
+	}
+	switch opt.format {
+	case formatHTML, formatJSON:
+	case formatZip, formatTar:
+		// archives always contain everything below the directory
+		opt.recursive = true
+	default:
+		return nil, fmt.Errorf("unknown format %q - expecting %q, %q or %q", opt.format, formatJSON, formatZip, formatTar)
+	}
+	if recursive := q.Get("recursive"); recursive != "" {
+		r, err := strconv.ParseBool(recursive)
+		if err != nil {
+			return nil, fmt.Errorf("bad recursive parameter: %w", err)
+		}
+		opt.recursive = opt.recursive || r
+	}
+	if opt.search != "" {
+		opt.recursive = true
+	}
+	if len(q["include"])+len(q["exclude"])+len(q["filter"]) > 0 {
+		filterOpt := filter.DefaultOpt
+		filterOpt.IncludeRule = q["include"]
+		filterOpt.ExcludeRule = q["exclude"]
+		filterOpt.FilterRule = q["filter"]
+		opt.filter, err = filter.NewFilter(&filterOpt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range q["hash"] {
+		var ht hash.Type
+		if err := ht.Set(name); err != nil {
+			return nil, err
+		}
+		opt.hashes = append(opt.hashes, ht)
+	}
+	return opt, nil
+}
+
+// includeDir returns whether the directory rel should be listed
+func (opt *listOpt) includeDir(ctx context.Context, rel string) (bool, error) {
+	if opt.filter == nil {
+		return true, nil
+	}
+	return opt.filter.IncludeDirectory(ctx, nil)(rel)
+}
+
+// includeFile returns whether the file node at rel should be listed
+func (opt *listOpt) includeFile(node vfs.Node, rel string) bool {
+	if opt.filter == nil {
+		return true
+	}
+	return opt.filter.Include(rel, node.Size(), node.ModTime())
+}
+
+// matches returns whether the node should be shown for the search
+func (opt *listOpt) matches(node vfs.Node) bool {
+	return opt.search == "" || strings.Contains(strings.ToLower(node.Name()), opt.search)
+}
+
+// walk calls fn for each node in dir to be listed, with the path of
+// the node relative to dir.
+func (opt *listOpt) walk(ctx context.Context, dir *vfs.Dir, fn func(node vfs.Node, rel string) error) error {
+	return opt.walkDir(ctx, dir, "", fn)
+}
+
+func (opt *listOpt) walkDir(ctx context.Context, dir *vfs.Dir, relDir string, fn func(node vfs.Node, rel string) error) error {
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rel := path.Join(relDir, node.Name())
+		if !node.IsDir() {
+			if opt.includeFile(node, rel) && opt.matches(node) {
+				if err := fn(node, rel); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		include, err := opt.includeDir(ctx, rel)
+		if err != nil {
+			return err
+		}
+		if !include {
+			continue
+		}
+		if opt.matches(node) {
+			if err := fn(node, rel); err != nil {
+				return err
+			}
+		}
+		if subDir, ok := node.(*vfs.Dir); ok && opt.recursive {
+			if err := opt.walkDir(ctx, subDir, rel, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nodeMimeType returns the mime type of node
+func nodeMimeType(ctx context.Context, node vfs.Node) string {
+	if entry := node.DirEntry(); entry != nil {
+		return fs.MimeTypeDirEntry(ctx, entry)
+	}
+	if node.IsDir() {
+		return "inode/directory"
+	}
+	return fs.MimeTypeFromName(node.Name())
+}
+
+// nodeHashes returns the hashes asked for of node
+func (opt *listOpt) nodeHashes(ctx context.Context, node vfs.Node) map[string]string {
+	o, ok := node.DirEntry().(fs.Object)
+	if len(opt.hashes) == 0 || !ok {
+		return nil
+	}
+	hashes := make(map[string]string, len(opt.hashes))
+	for _, ht := range opt.hashes {
+		sum, err := o.Hash(ctx, ht)
+		if err != nil {
+			fs.Errorf(o, "Failed to read hash: %v", err)
+		} else if sum != "" {
+			hashes[ht.String()] = sum
+		}
+	}
+	return hashes
+}
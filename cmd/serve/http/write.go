@@ -0,0 +1,290 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/http/serve"
+	"github.com/rclone/rclone/lib/random"
+	"github.com/rclone/rclone/vfs"
+)
+
+func init() {
+	// chi only routes the standard methods unless told otherwise
+	chi.RegisterMethod("MKCOL")
+}
+
+// maxFormValue is the largest value read from the non file fields of
+// an upload form
+const maxFormValue = 4096
+
+// writeVFS returns the VFS for a request which changes it, or writes
+// an error and returns nil if the user isn't allowed to.
+func (s *server) writeVFS(w http.ResponseWriter, r *http.Request) *vfs.VFS {
+	VFS, err := s.getVFS(r.Context())
+	if err != nil {
+		http.Error(w, "Root directory not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to find VFS: %v", err)
+		return nil
+	}
+	if VFS.Opt.ReadOnly {
+		http.Error(w, "Read only", http.StatusForbidden)
+		return nil
+	}
+	return VFS
+}
+
+// writeError writes the error from changing remote to the client.
+//
+// missing is the status to use if the file or its directory wasn't
+// found.
+func writeError(remote string, w http.ResponseWriter, text string, err error, missing int) {
+	switch {
+	case errors.Is(err, vfs.EROFS), errors.Is(err, vfs.EPERM):
+		http.Error(w, text+": permission denied", http.StatusForbidden)
+	case errors.Is(err, vfs.ENOENT):
+		http.Error(w, text+": not found", missing)
+	case errors.Is(err, vfs.EEXIST):
+		http.Error(w, text+": already exists", http.StatusConflict)
+	case errors.Is(err, vfs.ENOTEMPTY):
+		http.Error(w, text+": directory not empty", http.StatusConflict)
+	case errors.Is(err, vfs.ENOSPC):
+		http.Error(w, text+": quota exceeded", http.StatusInsufficientStorage)
+	default:
+		serve.Error(remote, w, text, err)
+	}
+}
+
+// write copies in to the file remote, creating or truncating it
+func write(VFS *vfs.VFS, remote string, in io.Reader) error {
+	handle, err := VFS.OpenFile(remote, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(handle, in)
+	closeErr := handle.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// upload writes in to the file remote.
+//
+// An existing file is written to a temporary name first and only
+// replaced once the upload has succeeded, so a failed upload doesn't
+// lose it. Whatever was written is removed if it fails.
+func upload(VFS *vfs.VFS, remote string, in io.Reader) (err error) {
+	target := remote
+	if _, err := VFS.Stat(remote); err == nil {
+		dir, leaf := path.Split(remote)
+		target = path.Join(dir, "."+leaf+"."+random.String(8)+".partial")
+	}
+	err = write(VFS, target, in)
+	if err == nil && target != remote {
+		err = VFS.Rename(target, remote)
+	}
+	if err != nil {
+		if removeErr := VFS.Remove(target); removeErr != nil && !errors.Is(removeErr, vfs.ENOENT) {
+			fs.Debugf(target, "Failed to remove partial upload: %v", removeErr)
+		}
+	}
+	return err
+}
+
+// putFile uploads the body of a PUT request to the file at the path
+func (s *server) putFile(w http.ResponseWriter, r *http.Request) {
+	remote := strings.Trim(r.URL.Path, "/")
+	if remote == "" || strings.HasSuffix(r.URL.Path, "/") {
+		http.Error(w, "Can't PUT a directory", http.StatusMethodNotAllowed)
+		return
+	}
+	VFS := s.writeVFS(w, r)
+	if VFS == nil {
+		return
+	}
+	node, err := VFS.Stat(remote)
+	exists := err == nil
+	if exists && node.IsDir() {
+		http.Error(w, "Can't PUT a directory", http.StatusMethodNotAllowed)
+		return
+	}
+	err = upload(VFS, remote, r.Body)
+	if err != nil {
+		writeError(remote, w, "Failed to upload file", err, http.StatusConflict)
+		return
+	}
+	fs.Infof(remote, "%s: Uploaded file", r.RemoteAddr)
+	if exists {
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// uploadName returns the leaf name to save an upload called fileName
+// as, or an error if it isn't usable.
+func uploadName(fileName string) (string, error) {
+	// Some browsers send the full path of the file
+	leaf := path.Base(strings.ReplaceAll(fileName, `\`, "/"))
+	switch leaf {
+	case "", ".", "..", "/":
+		return "", fmt.Errorf("bad file name %q", fileName)
+	}
+	return leaf, nil
+}
+
+// sameOrigin returns false if the request was sent by a page from
+// another site, as a cross site request forgery would be.
+//
+// Browsers send the Origin header with a form POST, or only the
+// Referer if they are old. Requests with neither aren't from a
+// browser so are let through.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
+
+// postForm handles the upload form of a directory listing.
+//
+// The form is read as a stream so large uploads aren't held in
+// memory. Each "file" field is uploaded into the directory and the
+// "mkdir" field makes a directory in it.
+func (s *server) postForm(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		http.Error(w, "Can only POST to a directory", http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "Form sent from another site", http.StatusForbidden)
+		fs.Errorf(nil, "%s: Rejected upload form from another site: Origin=%q Referer=%q", r.RemoteAddr, r.Header.Get("Origin"), r.Header.Get("Referer"))
+		return
+	}
+	dirRemote := strings.Trim(r.URL.Path, "/")
+	VFS := s.writeVFS(w, r)
+	if VFS == nil {
+		return
+	}
+	node, err := VFS.Stat(dirRemote)
+	if err != nil || !node.IsDir() {
+		http.Error(w, "Directory not found", http.StatusNotFound)
+		return
+	}
+	form, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad upload form: %v", err), http.StatusBadRequest)
+		return
+	}
+	for {
+		part, err := form.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Bad upload form: %v", err), http.StatusBadRequest)
+			return
+		}
+		switch part.FormName() {
+		case "file":
+			if part.FileName() == "" {
+				// no file chosen
+				continue
+			}
+			leaf, err := uploadName(part.FileName())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			remote := path.Join(dirRemote, leaf)
+			if err := upload(VFS, remote, part); err != nil {
+				writeError(remote, w, "Failed to upload file", err, http.StatusConflict)
+				return
+			}
+			fs.Infof(remote, "%s: Uploaded file", r.RemoteAddr)
+		case "mkdir":
+			value, err := io.ReadAll(io.LimitReader(part, maxFormValue))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Bad upload form: %v", err), http.StatusBadRequest)
+				return
+			}
+			if len(value) == 0 {
+				continue
+			}
+			leaf, err := uploadName(string(value))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			remote := path.Join(dirRemote, leaf)
+			if err := VFS.Mkdir(remote, 0777); err != nil {
+				writeError(remote, w, "Failed to make directory", err, http.StatusConflict)
+				return
+			}
+			fs.Infof(remote, "%s: Made directory", r.RemoteAddr)
+		}
+	}
+	// Back to the listing
+	http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+}
+
+// deleteNode removes the file or empty directory at the path
+func (s *server) deleteNode(w http.ResponseWriter, r *http.Request) {
+	remote := strings.Trim(r.URL.Path, "/")
+	if remote == "" {
+		http.Error(w, "Can't delete the root", http.StatusForbidden)
+		return
+	}
+	VFS := s.writeVFS(w, r)
+	if VFS == nil {
+		return
+	}
+	node, err := VFS.Stat(remote)
+	if err != nil {
+		writeError(remote, w, "Failed to delete", err, http.StatusNotFound)
+		return
+	}
+	err = node.Remove()
+	if err != nil {
+		writeError(remote, w, "Failed to delete", err, http.StatusNotFound)
+		return
+	}
+	fs.Infof(remote, "%s: Deleted", r.RemoteAddr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// makeDir handles MKCOL requests which make the directory at the path
+func (s *server) makeDir(w http.ResponseWriter, r *http.Request) {
+	remote := strings.Trim(r.URL.Path, "/")
+	VFS := s.writeVFS(w, r)
+	if VFS == nil {
+		return
+	}
+	if _, err := VFS.Stat(remote); err == nil {
+		http.Error(w, "Already exists", http.StatusMethodNotAllowed)
+		return
+	}
+	err := VFS.Mkdir(remote, 0777)
+	if err != nil {
+		writeError(remote, w, "Failed to make directory", err, http.StatusConflict)
+		return
+	}
+	fs.Infof(remote, "%s: Made directory", r.RemoteAddr)
+	w.WriteHeader(http.StatusCreated)
+}
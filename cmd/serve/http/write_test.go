@@ -0,0 +1,247 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUser = "user"
+	testPass = "pass"
+)
+
+// startWriteServer starts a --read-write server on a temporary
+// directory returning the server, its URL and the directory.
+func startWriteServer(t *testing.T) (*server, string, string) {
+	dir := t.TempDir()
+	f, err := fs.NewFs(context.Background(), dir)
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.HTTP.ListenAddr = []string{testBindAddress}
+	opt.Auth.BasicUser = testUser
+	opt.Auth.BasicPass = testPass
+	opt.ReadWrite = true
+	s, err := newServer(context.Background(), f, &opt)
+	require.NoError(t, err)
+	require.NoError(t, s.serve())
+	t.Cleanup(func() {
+		_ = s.server.Shutdown()
+	})
+	return s, s.server.URL(), dir
+}
+
+// do makes an authenticated request returning the status and body
+func do(t *testing.T, method, URL string, body io.Reader, contentType string) (int, string) {
+	req, err := http.NewRequest(method, URL, body)
+	require.NoError(t, err)
+	req.SetBasicAuth(testUser, testPass)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	client := &http.Client{
+		// Don't follow the redirect after a form upload
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, resp.Body.Close())
+	}()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestReadWriteNeedsAuth(t *testing.T) {
+	f, err := fs.NewFs(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.HTTP.ListenAddr = []string{testBindAddress}
+	opt.ReadWrite = true
+	_, err = newServer(context.Background(), f, &opt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--read-write needs authentication")
+}
+
+func TestReadWrite(t *testing.T) {
+	s, URL, dir := startWriteServer(t)
+
+	// Authentication is required
+	resp, err := http.Get(URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// The listing has the upload form
+	status, body := do(t, "GET", URL, nil, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `name="mkdir"`)
+
+	// PUT
+	status, _ = do(t, "PUT", URL+"file.txt", strings.NewReader("hello"), "")
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = do(t, "PUT", URL+"file.txt", strings.NewReader("hello again"), "")
+	assert.Equal(t, http.StatusNoContent, status)
+	status, body = do(t, "GET", URL+"file.txt", nil, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "hello again", body)
+	status, _ = do(t, "PUT", URL+"missing/file.txt", strings.NewReader("hello"), "")
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = do(t, "PUT", URL+"dir/", strings.NewReader("hello"), "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+
+	// MKCOL
+	status, _ = do(t, "MKCOL", URL+"dir", nil, "")
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = do(t, "MKCOL", URL+"dir", nil, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+	status, _ = do(t, "MKCOL", URL+"missing/dir", nil, "")
+	assert.Equal(t, http.StatusConflict, status)
+
+	// POST the upload form
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, err := mw.CreateFormFile("file", `C:\Users\me\upload.txt`)
+	require.NoError(t, err)
+	_, err = part.Write([]byte("uploaded"))
+	require.NoError(t, err)
+	require.NoError(t, mw.WriteField("mkdir", "sub"))
+	require.NoError(t, mw.Close())
+	status, _ = do(t, "POST", URL+"dir/", &form, mw.FormDataContentType())
+	assert.Equal(t, http.StatusSeeOther, status)
+	data, err := os.ReadFile(filepath.Join(dir, "dir", "upload.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "uploaded", string(data))
+	fi, err := os.Stat(filepath.Join(dir, "dir", "sub"))
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+	status, _ = do(t, "POST", URL+"dir/", strings.NewReader("potato"), "text/plain")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = do(t, "POST", URL+"missing/", strings.NewReader(""), mw.FormDataContentType())
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// DELETE
+	status, _ = do(t, "DELETE", URL+"dir", nil, "")
+	assert.Equal(t, http.StatusConflict, status)
+	for _, name := range []string{"dir/upload.txt", "dir/sub", "dir", "file.txt"} {
+		status, _ = do(t, "DELETE", URL+name, nil, "")
+		assert.Equal(t, http.StatusNoContent, status, name)
+	}
+	status, _ = do(t, "DELETE", URL+"dir", nil, "")
+	assert.Equal(t, http.StatusNotFound, status)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// A read only VFS, as the auth proxy gives with _readonly, refuses
+	// changes and doesn't show the form
+	opt := vfsflags.Opt
+	opt.ReadOnly = true
+	s._vfs = vfs.New(s.f, &opt)
+	status, _ = do(t, "PUT", URL+"file.txt", strings.NewReader("hello"), "")
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = do(t, "MKCOL", URL+"dir", nil, "")
+	assert.Equal(t, http.StatusForbidden, status)
+	status, body = do(t, "GET", URL, nil, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotContains(t, body, `name="mkdir"`)
+}
+
+func TestUploadFailure(t *testing.T) {
+	dir := t.TempDir()
+	f, err := fs.NewFs(context.Background(), dir)
+	require.NoError(t, err)
+	opt := vfsflags.Opt
+	VFS := vfs.New(f, &opt)
+	defer VFS.Shutdown()
+	failing := func() io.Reader {
+		return io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("upload failed")))
+	}
+
+	// A failed upload of a new file leaves nothing behind
+	err = upload(VFS, "new.txt", failing())
+	assert.EqualError(t, err, "upload failed")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// A failed upload over an existing file leaves it as it was
+	require.NoError(t, upload(VFS, "file.txt", strings.NewReader("hello")))
+	err = upload(VFS, "file.txt", failing())
+	assert.EqualError(t, err, "upload failed")
+	data, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// A successful one replaces it
+	require.NoError(t, upload(VFS, "file.txt", strings.NewReader("hello again")))
+	data, err = os.ReadFile(filepath.Join(dir, "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello again", string(data))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestPostFormOrigin(t *testing.T) {
+	_, URL, dir := startWriteServer(t)
+	u, err := url.Parse(URL)
+	require.NoError(t, err)
+
+	post := func(mkdir, header, value string) int {
+		var form bytes.Buffer
+		mw := multipart.NewWriter(&form)
+		require.NoError(t, mw.WriteField("mkdir", mkdir))
+		require.NoError(t, mw.Close())
+		req, err := http.NewRequest("POST", URL, &form)
+		require.NoError(t, err)
+		req.SetBasicAuth(testUser, testPass)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		client := &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	// Forms from other sites are refused
+	assert.Equal(t, http.StatusForbidden, post("sub", "Origin", "http://evil.example.com"))
+	assert.Equal(t, http.StatusForbidden, post("sub", "Origin", "null"))
+	assert.Equal(t, http.StatusForbidden, post("sub", "Referer", "http://evil.example.com/page.html"))
+	_, err = os.Stat(filepath.Join(dir, "sub"))
+	assert.True(t, os.IsNotExist(err))
+
+	// But not from the server's own pages or from clients which
+	// aren't browsers
+	assert.Equal(t, http.StatusSeeOther, post("sub1", "Origin", "http://"+u.Host))
+	assert.Equal(t, http.StatusSeeOther, post("sub2", "Referer", URL))
+	assert.Equal(t, http.StatusSeeOther, post("sub3", "", ""))
+}
@@ -0,0 +1,224 @@
+package nfs
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// openFileTimeout is how long a file is kept open after its last use.
+//
+// NFS has no open or close so files are opened on the first READ or
+// WRITE and kept open for the following ones. Files being written are
+// closed by COMMIT, otherwise they are closed after this long.
+const openFileTimeout = time.Minute
+
+// openFile is a VFS handle kept open between NFS calls
+type openFile struct {
+	mu       sync.RWMutex // read locked while in use, write locked to close
+	handle   vfs.Handle
+	write    bool      // set if opened for writing
+	closed   bool      // set when the handle has been closed
+	inUse    int       // number of users - protected by openFiles.mu
+	lastUsed time.Time // protected by openFiles.mu
+}
+
+// openFiles is the set of files kept open by path
+type openFiles struct {
+	vfs   *vfs.VFS
+	mu    sync.Mutex
+	files map[string]*openFile
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// newOpenFiles makes a new openFiles and starts the goroutine which
+// closes idle files
+func newOpenFiles(VFS *vfs.VFS) *openFiles {
+	o := &openFiles{
+		vfs:   VFS,
+		files: make(map[string]*openFile),
+		quit:  make(chan struct{}),
+	}
+	o.wg.Add(1)
+	go o.closeIdle()
+	return o
+}
+
+// canReadWhileWriting returns true if handles opened for writing can
+// also be read from
+func (o *openFiles) canReadWhileWriting() bool {
+	return o.vfs.Opt.CacheMode >= vfscommon.CacheModeWrites
+}
+
+// writeFlags returns the flags to open a file for writing at offset
+func (o *openFiles) writeFlags(offset int64) int {
+	if o.canReadWhileWriting() {
+		return os.O_RDWR
+	}
+	if offset == 0 {
+		// Without the cache existing files can only be
+		// overwritten from the start
+		return os.O_WRONLY | os.O_TRUNC
+	}
+	return os.O_WRONLY
+}
+
+// get returns the open file for path, opening it with flags if it
+// isn't open in a suitable mode. If flags has O_CREATE then the file
+// is always opened afresh.
+//
+// The file returned must be passed to release when finished with.
+func (o *openFiles) get(path string, write bool, flags int) (*openFile, error) {
+	o.mu.Lock()
+	f := o.files[path]
+	if f != nil && flags&os.O_CREATE == 0 && (f.write == write || (f.write && o.canReadWhileWriting())) {
+		f.inUse++
+		o.mu.Unlock()
+		f.mu.RLock()
+		if !f.closed {
+			return f, nil
+		}
+		// closed while waiting for the lock so try again
+		o.release(f)
+		return o.get(path, write, flags)
+	}
+	if f != nil {
+		delete(o.files, path)
+	}
+	o.mu.Unlock()
+
+	// Close the file if it was open in the wrong mode
+	if f != nil {
+		if err := f.close(); err != nil {
+			return nil, err
+		}
+	}
+
+	handle, err := o.vfs.OpenFile(path, flags, 0666)
+	if err != nil {
+		return nil, err
+	}
+	f = &openFile{
+		handle: handle,
+		write:  write,
+		inUse:  1,
+	}
+	f.mu.RLock()
+	o.mu.Lock()
+	old := o.files[path]
+	o.files[path] = f
+	o.mu.Unlock()
+	if old != nil {
+		// Lost a race with another opener so close theirs
+		if err := old.close(); err != nil {
+			fs.Errorf(path, "NFS: failed to close file: %v", err)
+		}
+	}
+	return f, nil
+}
+
+// getRead returns path open for reading
+func (o *openFiles) getRead(path string) (*openFile, error) {
+	return o.get(path, false, os.O_RDONLY)
+}
+
+// getWrite returns path open for writing at offset
+func (o *openFiles) getWrite(path string, offset int64) (*openFile, error) {
+	return o.get(path, true, o.writeFlags(offset))
+}
+
+// create creates path and leaves it open for writing
+func (o *openFiles) create(path string) error {
+	f, err := o.get(path, true, o.writeFlags(0)|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	o.release(f)
+	return nil
+}
+
+// release a file returned by get
+func (o *openFiles) release(f *openFile) {
+	f.mu.RUnlock()
+	o.mu.Lock()
+	f.inUse--
+	f.lastUsed = time.Now()
+	o.mu.Unlock()
+}
+
+// close closes path if it is open returning any error from the close
+func (o *openFiles) close(path string) error {
+	o.mu.Lock()
+	f := o.files[path]
+	delete(o.files, path)
+	o.mu.Unlock()
+	if f == nil {
+		return nil
+	}
+	return f.close()
+}
+
+// close the file waiting for any users to finish
+func (f *openFile) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	err := f.handle.Close()
+	if err == vfs.ECLOSED {
+		err = nil
+	}
+	return err
+}
+
+// closeIdle closes files which haven't been used recently until
+// closeAll is called
+func (o *openFiles) closeIdle() {
+	defer o.wg.Done()
+	ticker := time.NewTicker(openFileTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.quit:
+			return
+		case <-ticker.C:
+		}
+		idle := make(map[string]*openFile)
+		o.mu.Lock()
+		for path, f := range o.files {
+			if f.inUse == 0 && time.Since(f.lastUsed) > openFileTimeout {
+				idle[path] = f
+				delete(o.files, path)
+			}
+		}
+		o.mu.Unlock()
+		for path, f := range idle {
+			fs.Debugf(path, "NFS: closing idle file")
+			if err := f.close(); err != nil {
+				fs.Errorf(path, "NFS: failed to close file: %v", err)
+			}
+		}
+	}
+}
+
+// closeAll stops the idle closer and closes all the open files
+func (o *openFiles) closeAll() {
+	close(o.quit)
+	o.wg.Wait()
+	o.mu.Lock()
+	files := o.files
+	o.files = make(map[string]*openFile)
+	o.mu.Unlock()
+	for path, f := range files {
+		if err := f.close(); err != nil {
+			fs.Errorf(path, "NFS: failed to close file: %v", err)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package nfs
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+)
+
+// Values for --nfs-cache-type
+const (
+	cacheTypeMemory = "memory"
+	cacheTypeDisk   = "disk"
+)
+
+// handleSize is the size of the file handles made
+const handleSize = sha256.Size
+
+// errStale is returned for handles which aren't known
+var errStale = errors.New("stale file handle")
+
+// handleCache maps NFS file handles to paths in the VFS and back.
+//
+// The handle of a path is a hash of the path and the remote being
+// served, so it is the same every time the server is run. The reverse
+// mapping is kept in memory and, if enabled, in a key value database
+// so handles given out will still work after a restart.
+type handleCache struct {
+	seed  string // hashed with the path to make the handle
+	limit int    // max number of handles kept in memory
+	db    *kv.DB // persistent store or nil if not persisting
+	mu    sync.Mutex
+	lru   *list.List               // of *handleEntry, most recently used first
+	items map[string]*list.Element // by string(handle)
+}
+
+// handleEntry is an entry in the handleCache
+type handleEntry struct {
+	handle string
+	path   string
+}
+
+// newHandleCache makes a handleCache for the remote f
+func newHandleCache(ctx context.Context, f fs.Fs, opt *Options) (*handleCache, error) {
+	c := &handleCache{
+		seed:  fs.ConfigString(f),
+		limit: opt.HandleLimit,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+	}
+	switch opt.HandleCacheType {
+	case cacheTypeMemory:
+	case cacheTypeDisk:
+		if !kv.Supported() {
+			fs.Logf(f, "Persistent NFS handles are not supported on this OS - using --nfs-cache-type %s", cacheTypeMemory)
+			break
+		}
+		db, err := kv.Start(ctx, "serve-nfs", f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open NFS handle database: %w", err)
+		}
+		c.db = db
+	default:
+		return nil, fmt.Errorf("unknown --nfs-cache-type %q - expecting %q or %q", opt.HandleCacheType, cacheTypeMemory, cacheTypeDisk)
+	}
+	return c, nil
+}
+
+// hash returns the handle for path without registering it
+func (c *handleCache) hash(path string) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte(c.seed))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(path))
+	return h.Sum(nil)
+}
+
+// fileID returns the NFS fileid for a handle
+func fileID(handle []byte) uint64 {
+	return binary.BigEndian.Uint64(handle)
+}
+
+// add puts the entry in the memory cache returning true if it wasn't
+// there already.
+//
+// Call with c.mu held.
+func (c *handleCache) add(handle, path string) bool {
+	if el, ok := c.items[handle]; ok {
+		c.lru.MoveToFront(el)
+		entry := el.Value.(*handleEntry)
+		if entry.path == path {
+			return false
+		}
+		entry.path = path
+		return true
+	}
+	c.items[handle] = c.lru.PushFront(&handleEntry{handle: handle, path: path})
+	for c.limit > 0 && c.lru.Len() > c.limit {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.items, el.Value.(*handleEntry).handle)
+	}
+	return true
+}
+
+// toHandles returns the handles for paths, remembering them so they
+// can be turned back into paths.
+func (c *handleCache) toHandles(paths ...string) [][]byte {
+	handles := make([][]byte, len(paths))
+	put := kvPutHandles{}
+	c.mu.Lock()
+	for i, path := range paths {
+		handles[i] = c.hash(path)
+		if c.add(string(handles[i]), path) {
+			put[string(handles[i])] = path
+		}
+	}
+	c.mu.Unlock()
+	if c.db != nil && len(put) > 0 {
+		err := c.db.Do(true, put)
+		if err != nil {
+			fs.Errorf(nil, "Failed to save NFS handles: %v", err)
+		}
+	}
+	return handles
+}
+
+// toHandle returns the handle for path, remembering it so it can be
+// turned back into a path.
+func (c *handleCache) toHandle(path string) []byte {
+	return c.toHandles(path)[0]
+}
+
+// fromHandle returns the path for a handle or errStale if the handle
+// isn't known.
+func (c *handleCache) fromHandle(handle []byte) (path string, err error) {
+	if len(handle) != handleSize {
+		return "", errStale
+	}
+	c.mu.Lock()
+	if el, ok := c.items[string(handle)]; ok {
+		c.lru.MoveToFront(el)
+		path = el.Value.(*handleEntry).path
+		c.mu.Unlock()
+		return path, nil
+	}
+	c.mu.Unlock()
+	if c.db == nil {
+		return "", errStale
+	}
+	get := &kvGetHandle{handle: handle}
+	err = c.db.Do(false, get)
+	if errors.Is(err, kv.ErrEmpty) {
+		return "", errStale
+	} else if err != nil {
+		return "", err
+	}
+	if !get.found {
+		return "", errStale
+	}
+	c.mu.Lock()
+	c.add(string(handle), get.path)
+	c.mu.Unlock()
+	return get.path, nil
+}
+
+// rename points the handle of oldPath at newPath so it carries on
+// working after a rename. The handle of newPath is registered too.
+func (c *handleCache) rename(oldPath, newPath string) {
+	oldHandle, newHandle := string(c.hash(oldPath)), string(c.hash(newPath))
+	c.mu.Lock()
+	c.add(oldHandle, newPath)
+	c.add(newHandle, newPath)
+	c.mu.Unlock()
+	if c.db != nil {
+		err := c.db.Do(true, kvPutHandles{oldHandle: newPath, newHandle: newPath})
+		if err != nil {
+			fs.Errorf(nil, "Failed to save NFS handles: %v", err)
+		}
+	}
+}
+
+// forget removes the handle for path, so it will be stale if used
+func (c *handleCache) forget(path string) {
+	handle := string(c.hash(path))
+	c.mu.Lock()
+	if el, ok := c.items[handle]; ok {
+		c.lru.Remove(el)
+		delete(c.items, handle)
+	}
+	c.mu.Unlock()
+	if c.db != nil {
+		err := c.db.Do(true, kvDeleteHandle(handle))
+		if err != nil {
+			fs.Errorf(nil, "Failed to remove NFS handle: %v", err)
+		}
+	}
+}
+
+// close the handle database
+func (c *handleCache) close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Stop(false)
+}
+
+// kvPutHandles saves paths by handle in the database
+type kvPutHandles map[string]string
+
+func (op kvPutHandles) Do(ctx context.Context, b kv.Bucket) error {
+	for handle, path := range op {
+		err := b.Put([]byte(handle), []byte(path))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kvGetHandle reads the path for a handle from the database
+type kvGetHandle struct {
+	handle []byte
+	path   string
+	found  bool
+}
+
+func (op *kvGetHandle) Do(ctx context.Context, b kv.Bucket) error {
+	data := b.Get(op.handle)
+	if data == nil {
+		return nil
+	}
+	op.path, op.found = string(data), true
+	return nil
+}
+
+// kvDeleteHandle removes a handle from the database
+type kvDeleteHandle string
+
+func (op kvDeleteHandle) Do(ctx context.Context, b kv.Bucket) error {
+	return b.Delete([]byte(op))
+}
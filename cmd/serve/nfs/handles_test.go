@@ -0,0 +1,112 @@
+package nfs
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHandleCache makes a handleCache for a local temporary
+// directory
+func newTestHandleCache(t *testing.T, cacheType string, limit int) *handleCache {
+	f, err := fs.NewFs(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.HandleCacheType = cacheType
+	opt.HandleLimit = limit
+	c, err := newHandleCache(context.Background(), f, &opt)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.close())
+	})
+	return c
+}
+
+func TestHandleCacheMemory(t *testing.T) {
+	c := newTestHandleCache(t, cacheTypeMemory, 3)
+
+	a := c.toHandle("a")
+	assert.Equal(t, handleSize, len(a))
+	assert.Equal(t, a, c.toHandle("a"))
+	assert.Equal(t, a, c.hash("a"))
+	assert.NotEqual(t, a, c.toHandle("b"))
+
+	path, err := c.fromHandle(a)
+	require.NoError(t, err)
+	assert.Equal(t, "a", path)
+
+	_, err = c.fromHandle([]byte("short"))
+	assert.Equal(t, errStale, err)
+	_, err = c.fromHandle(c.hash("unknown"))
+	assert.Equal(t, errStale, err)
+
+	// "a" was used most recently so "b" is dropped
+	handles := c.toHandles("c", "d")
+	assert.Equal(t, 2, len(handles))
+	_, err = c.fromHandle(c.hash("b"))
+	assert.Equal(t, errStale, err)
+	path, err = c.fromHandle(a)
+	require.NoError(t, err)
+	assert.Equal(t, "a", path)
+
+	c.rename("c", "e")
+	path, err = c.fromHandle(handles[0])
+	require.NoError(t, err)
+	assert.Equal(t, "e", path)
+	path, err = c.fromHandle(c.hash("e"))
+	require.NoError(t, err)
+	assert.Equal(t, "e", path)
+
+	c.forget("a")
+	_, err = c.fromHandle(a)
+	assert.Equal(t, errStale, err)
+}
+
+func TestHandleCacheDisk(t *testing.T) {
+	if !kv.Supported() {
+		t.Skip("kv not supported on this OS")
+	}
+	c := newTestHandleCache(t, cacheTypeDisk, 1)
+	require.NotNil(t, c.db)
+
+	a := c.toHandle("dir/a")
+	b := c.toHandle("dir/b")
+
+	// "dir/a" is no longer in memory so is read from disk
+	path, err := c.fromHandle(a)
+	require.NoError(t, err)
+	assert.Equal(t, "dir/a", path)
+	path, err = c.fromHandle(b)
+	require.NoError(t, err)
+	assert.Equal(t, "dir/b", path)
+
+	c.rename("dir/a", "dir/c")
+	c.toHandle("dir/b")
+	path, err = c.fromHandle(a)
+	require.NoError(t, err)
+	assert.Equal(t, "dir/c", path)
+
+	c.forget("dir/b")
+	_, err = c.fromHandle(b)
+	assert.Equal(t, errStale, err)
+}
+
+func TestHandleCacheSeed(t *testing.T) {
+	c1 := newTestHandleCache(t, cacheTypeMemory, 0)
+	c2 := newTestHandleCache(t, cacheTypeMemory, 0)
+	assert.NotEqual(t, c1.hash("a"), c2.hash("a"), "different remotes should have different handles")
+}
+
+func TestHandleCacheBadType(t *testing.T) {
+	f, err := fs.NewFs(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.HandleCacheType = "potato"
+	_, err = newHandleCache(context.Background(), f, &opt)
+	assert.ErrorContains(t, err, "potato")
+}
@@ -0,0 +1,92 @@
+package nfs
+
+import (
+	"path"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// MOUNT protocol version 3 (RFC 1813 appendix I) constants
+const (
+	mountProgram = 100005
+	mountVersion = 3
+
+	mountPathLen = 1024
+
+	mnt3OK          = 0
+	mnt3ErrNoEnt    = 2
+	mnt3ErrNotDir   = 20
+	mnt3ErrServFail = 10006
+)
+
+// mountProcs are the procedures of the MOUNT program by number
+var mountProcs = []procedure{
+	{"MOUNTPROC3_NULL", (*server).mountNull},
+	{"MOUNTPROC3_MNT", (*server).mountMnt},
+	{"MOUNTPROC3_DUMP", (*server).mountDump},
+	{"MOUNTPROC3_UMNT", (*server).mountUmnt},
+	{"MOUNTPROC3_UMNTALL", (*server).mountNull},
+	{"MOUNTPROC3_EXPORT", (*server).mountExport},
+}
+
+// mountNull does nothing
+func (s *server) mountNull(args *xdrReader, res *xdrWriter) error {
+	return nil
+}
+
+// mountMnt returns the handle of the directory to be mounted.
+//
+// Any directory in the remote can be mounted.
+func (s *server) mountMnt(args *xdrReader, res *xdrWriter) error {
+	dirPath := args.string(mountPathLen)
+	if args.err != nil {
+		return errGarbage
+	}
+	dirPath = strings.Trim(path.Clean("/"+dirPath), "/")
+	fs.Infof(nil, "NFS: mount request for %q", "/"+dirPath)
+	node, err := s.vfs.Stat(dirPath)
+	if err != nil {
+		if nfsStatus(err) == nfs3ErrNoEnt {
+			res.uint32(mnt3ErrNoEnt)
+		} else {
+			res.uint32(mnt3ErrServFail)
+		}
+		return nil
+	}
+	if !node.IsDir() {
+		res.uint32(mnt3ErrNotDir)
+		return nil
+	}
+	res.uint32(mnt3OK)
+	res.opaque(s.handles.toHandle(dirPath))
+	res.uint32(1) // one auth flavor
+	res.uint32(authSys)
+	return nil
+}
+
+// mountDump returns the list of mounts, which isn't tracked so is
+// always empty
+func (s *server) mountDump(args *xdrReader, res *xdrWriter) error {
+	res.bool(false)
+	return nil
+}
+
+// mountUmnt unmounts a directory which needs no action
+func (s *server) mountUmnt(args *xdrReader, res *xdrWriter) error {
+	_ = args.string(mountPathLen)
+	if args.err != nil {
+		return errGarbage
+	}
+	return nil
+}
+
+// mountExport returns the list of exported directories which is just
+// the root available to everyone
+func (s *server) mountExport(args *xdrReader, res *xdrWriter) error {
+	res.bool(true)
+	res.string("/")
+	res.bool(false) // no groups
+	res.bool(false) // no more exports
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package nfs implements an NFSv3 server to serve an rclone VFS
+package nfs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Options contains options for the NFS Server
+type Options struct {
+	ListenAddr      string // Port to listen on
+	HandleCacheType string // where to keep the file handles - memory or disk
+	HandleLimit     int    // max number of file handles kept in memory
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	ListenAddr:      "localhost:2049",
+	HandleCacheType: cacheTypeDisk,
+	HandleLimit:     1000000,
+}
+
+// Opt is options set by command line flags
+var Opt = DefaultOpt
+
+// AddFlags adds flags for the nfs server
+func AddFlags(flagSet *pflag.FlagSet, Opt *Options) {
+	flags.StringVarP(flagSet, &Opt.ListenAddr, "addr", "", Opt.ListenAddr, "IPaddress:Port or :Port to bind server to")
+	flags.StringVarP(flagSet, &Opt.HandleCacheType, "nfs-cache-type", "", Opt.HandleCacheType, "Where to keep the file handles: memory or disk")
+	flags.IntVarP(flagSet, &Opt.HandleLimit, "nfs-cache-handle-limit", "", Opt.HandleLimit, "Max number of file handles kept in memory")
+}
+
+func init() {
+	rc.AddOption("nfs", &Opt)
+	vfsflags.AddFlags(Command.Flags())
+	AddFlags(Command.Flags(), &Opt)
+}
+
+// Command definition for cobra
+var Command = &cobra.Command{
+	Use:   "nfs remote:path",
+	Short: `Serve remote:path as NFS.`,
+	Long: strings.ReplaceAll(`
+Run an NFSv3 server to serve a remote over NFS. This can be used to
+mount the remote with the NFS client built into most operating
+systems, which is useful where FUSE is not available, for example in
+containers.
+
+The MOUNT protocol is served on the same port as NFS and no
+portmapper is used, so the client needs to be told the port and that
+locking isn't available. On Linux, with the default |--addr|, use
+
+    mount -t nfs -o port=2049,mountport=2049,tcp,nolock,vers=3 localhost:/ /mnt/point
+
+Any directory in the remote can be mounted by using its path instead
+of |/|.
+
+By default the server binds to localhost:2049 - if you want it to be
+reachable externally then supply |--addr :2049| for example. NFSv3
+has no real authentication so only do this on a trusted network. All
+files are owned by the |--uid| and |--gid| and have the permissions
+set by |--file-perms| and |--dir-perms|.
+
+### File handles
+
+NFS clients refer to files by handles which must carry on working as
+long as the file exists. The handle of a file is made from a hash of
+its path and the remote being served, so it is the same every time
+the server is run. The server needs to be able to turn handles back
+into paths, so it remembers the handles it has given out.
+
+With |--nfs-cache-type disk|, the default, the handles are saved in
+rclone's cache directory (see |rclone help flags cache-dir|) in the
+"serve-nfs" directory. This means clients can carry on using a mount
+after the server has been restarted.
+
+With |--nfs-cache-type memory| the handles are only kept in memory so
+clients will get "stale file handle" errors after a restart and will
+need to remount.
+
+In both cases at most |--nfs-cache-handle-limit| handles are kept in
+memory. Handles which don't fit are read from disk when needed, or
+become stale with the memory cache.
+
+Renaming a directory makes the handles of the files and directories
+inside it stale. Clients look them up again when this happens.
+
+### Caching
+
+NFS has no open or close calls, so files are opened when they are
+first read or written and kept open until the client commits the data
+or they have been idle for a minute. The file is uploaded when it is
+closed.
+
+|--vfs-cache-mode writes| or higher is recommended. NFS clients often
+write out of order and update parts of existing files which needs the
+VFS cache. Without it only whole files can be written sequentially.
+
+`, "|", "`") + vfs.Help,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		f := cmd.NewFsSrc(args)
+		cmd.Run(false, true, command, func() error {
+			s, err := newServer(context.Background(), f, &Opt)
+			if err != nil {
+				return err
+			}
+			err = s.Serve()
+			if err != nil {
+				return err
+			}
+			s.Wait()
+			return nil
+		})
+	},
+}
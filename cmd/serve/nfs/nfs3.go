@@ -0,0 +1,959 @@
+package nfs
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// NFS protocol version 3 (RFC 1813) constants
+const (
+	nfsProgram = 100003
+	nfsVersion = 3
+
+	nfsFhSize   = 64   // max size of a file handle
+	nfsPathMax  = 1024 // max size of a path or name read
+	nfsNameMax  = 255  // max size of a name accepted
+	nfsMaxIO    = 1024 * 1024
+	nfsDirPref  = 64 * 1024
+	nfsFileMult = 4096
+
+	nfs3OK             = 0
+	nfs3ErrPerm        = 1
+	nfs3ErrNoEnt       = 2
+	nfs3ErrIO          = 5
+	nfs3ErrExist       = 17
+	nfs3ErrNotDir      = 20
+	nfs3ErrIsDir       = 21
+	nfs3ErrInval       = 22
+	nfs3ErrNoSpc       = 28
+	nfs3ErrROFS        = 30
+	nfs3ErrNameTooLong = 63
+	nfs3ErrNotEmpty    = 66
+	nfs3ErrStale       = 70
+	nfs3ErrNotSupp     = 10004
+	nfs3ErrTooSmall    = 10005
+
+	nf3Reg = 1
+	nf3Dir = 2
+
+	access3Modify = 0x0004
+	access3Extend = 0x0008
+	access3Delete = 0x0010
+
+	unstable = 0
+	fileSync = 2
+
+	createUnchecked = 0
+	createGuarded   = 1
+	createExclusive = 2
+
+	timeDontChange = 0
+	timeSetServer  = 1
+	timeSetClient  = 2
+
+	fsf3Homogeneous = 0x0008
+	fsf3CanSetTime  = 0x0010
+)
+
+// Sizes of XDR structures used to limit the size of directory listings
+const (
+	fattr3Size       = 84
+	postOpAttrSize   = 4 + fattr3Size
+	postOpFhSize     = 4 + 4 + handleSize
+	readdirResOKSize = 4 + postOpAttrSize + 8 + 4 + 4 // status, dir attrs, cookieverf, end of list, eof
+)
+
+// nfsProcs are the procedures of the NFS program by number
+var nfsProcs = []procedure{
+	{"NFSPROC3_NULL", (*server).nfsNull},
+	{"NFSPROC3_GETATTR", (*server).nfsGetattr},
+	{"NFSPROC3_SETATTR", (*server).nfsSetattr},
+	{"NFSPROC3_LOOKUP", (*server).nfsLookup},
+	{"NFSPROC3_ACCESS", (*server).nfsAccess},
+	{"NFSPROC3_READLINK", (*server).nfsReadlink},
+	{"NFSPROC3_READ", (*server).nfsRead},
+	{"NFSPROC3_WRITE", (*server).nfsWrite},
+	{"NFSPROC3_CREATE", (*server).nfsCreate},
+	{"NFSPROC3_MKDIR", (*server).nfsMkdir},
+	{"NFSPROC3_SYMLINK", (*server).nfsNotSupported},
+	{"NFSPROC3_MKNOD", (*server).nfsNotSupported},
+	{"NFSPROC3_REMOVE", (*server).nfsRemove},
+	{"NFSPROC3_RMDIR", (*server).nfsRmdir},
+	{"NFSPROC3_RENAME", (*server).nfsRename},
+	{"NFSPROC3_LINK", (*server).nfsLink},
+	{"NFSPROC3_READDIR", (*server).nfsReaddir},
+	{"NFSPROC3_READDIRPLUS", (*server).nfsReaddirplus},
+	{"NFSPROC3_FSSTAT", (*server).nfsFsstat},
+	{"NFSPROC3_FSINFO", (*server).nfsFsinfo},
+	{"NFSPROC3_PATHCONF", (*server).nfsPathconf},
+	{"NFSPROC3_COMMIT", (*server).nfsCommit},
+}
+
+// nfsStatus converts an error into an NFS status
+func nfsStatus(err error) uint32 {
+	var nfsErr nfsError
+	switch {
+	case err == nil:
+		return nfs3OK
+	case errors.As(err, &nfsErr):
+		return uint32(nfsErr)
+	case errors.Is(err, errStale):
+		return nfs3ErrStale
+	case errors.Is(err, vfs.ENOENT):
+		return nfs3ErrNoEnt
+	case errors.Is(err, vfs.EEXIST):
+		return nfs3ErrExist
+	case errors.Is(err, vfs.EPERM):
+		return nfs3ErrPerm
+	case errors.Is(err, vfs.EINVAL):
+		return nfs3ErrInval
+	case errors.Is(err, vfs.ENOTEMPTY):
+		return nfs3ErrNotEmpty
+	case errors.Is(err, vfs.EROFS):
+		return nfs3ErrROFS
+	case errors.Is(err, vfs.ENOSPC):
+		return nfs3ErrNoSpc
+	case errors.Is(err, vfs.ENOSYS):
+		return nfs3ErrNotSupp
+	}
+	return nfs3ErrIO
+}
+
+// nfsError is an error carrying an NFS status which has no VFS
+// equivalent
+type nfsError uint32
+
+// Error renders the NFS status as a string
+func (e nfsError) Error() string {
+	switch e {
+	case nfs3ErrNotDir:
+		return "not a directory"
+	case nfs3ErrIsDir:
+		return "is a directory"
+	case nfs3ErrNameTooLong:
+		return "name too long"
+	case nfs3ErrTooSmall:
+		return "buffer too small"
+	}
+	return "NFS error"
+}
+
+// readHandle reads a file handle from args
+func readHandle(args *xdrReader) []byte {
+	return args.opaque(nfsFhSize)
+}
+
+// readDirOp reads the directory handle and name of a diropargs3
+func readDirOp(args *xdrReader) (dirHandle []byte, name string) {
+	dirHandle = readHandle(args)
+	name = args.string(nfsPathMax)
+	return dirHandle, name
+}
+
+// checkName returns an error if name isn't valid to create or remove
+// in a directory
+func checkName(name string) error {
+	switch {
+	case len(name) > nfsNameMax:
+		return nfsError(nfs3ErrNameTooLong)
+	case name == "" || name == "." || name == ".." || strings.ContainsRune(name, '/'):
+		return vfs.EINVAL
+	}
+	return nil
+}
+
+// sattr3 is the decoded attributes to set on a node
+type sattr3 struct {
+	setSize  bool
+	size     uint64
+	setMtime bool
+	mtime    time.Time
+}
+
+// readTime reads an nfstime3
+func readTime(args *xdrReader) time.Time {
+	seconds := args.uint32()
+	nanoseconds := args.uint32()
+	return time.Unix(int64(seconds), int64(nanoseconds))
+}
+
+// readSattr reads a sattr3 from args ignoring the mode, uid, gid and
+// atime as the VFS can't set them
+func readSattr(args *xdrReader) (attr sattr3) {
+	if args.bool() {
+		_ = args.uint32() // mode
+	}
+	if args.bool() {
+		_ = args.uint32() // uid
+	}
+	if args.bool() {
+		_ = args.uint32() // gid
+	}
+	if attr.setSize = args.bool(); attr.setSize {
+		attr.size = args.uint64()
+	}
+	if args.uint32() == timeSetClient {
+		_ = readTime(args) // atime
+	}
+	switch args.uint32() {
+	case timeDontChange:
+	case timeSetServer:
+		attr.setMtime, attr.mtime = true, time.Now()
+	case timeSetClient:
+		attr.setMtime, attr.mtime = true, readTime(args)
+	}
+	return attr
+}
+
+// setAttr sets the attributes in attr on node
+func setAttr(node vfs.Node, attr sattr3) error {
+	if attr.setSize {
+		if node.IsDir() {
+			return nfsError(nfs3ErrIsDir)
+		}
+		err := node.Truncate(int64(attr.size))
+		if err != nil {
+			return err
+		}
+	}
+	if attr.setMtime {
+		err := node.SetModTime(attr.mtime)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve finds the path and node a file handle refers to
+func (s *server) resolve(handle []byte) (string, vfs.Node, error) {
+	nodePath, err := s.handles.fromHandle(handle)
+	if err != nil {
+		return "", nil, err
+	}
+	node, err := s.vfs.Stat(nodePath)
+	if errors.Is(err, vfs.ENOENT) {
+		// The handle refers to something deleted
+		return "", nil, errStale
+	} else if err != nil {
+		return "", nil, err
+	}
+	return nodePath, node, nil
+}
+
+// resolveDir finds the path and directory a file handle refers to
+func (s *server) resolveDir(handle []byte) (string, *vfs.Dir, error) {
+	dirPath, node, err := s.resolve(handle)
+	if err != nil {
+		return "", nil, err
+	}
+	dir, ok := node.(*vfs.Dir)
+	if !ok {
+		return "", nil, nfsError(nfs3ErrNotDir)
+	}
+	return dirPath, dir, nil
+}
+
+// writeTime writes t as an nfstime3
+func writeTime(res *xdrWriter, t time.Time) {
+	res.uint32(uint32(t.Unix()))
+	res.uint32(uint32(t.Nanosecond()))
+}
+
+// writeAttr writes the fattr3 of node which has handle
+func (s *server) writeAttr(res *xdrWriter, handle []byte, node vfs.Node) {
+	var (
+		ftype uint32 = nf3Reg
+		nlink uint32 = 1
+		size         = uint64(node.Size())
+	)
+	if node.IsDir() {
+		ftype, nlink = nf3Dir, 2
+	}
+	modTime := node.ModTime()
+	res.uint32(ftype)
+	res.uint32(uint32(node.Mode().Perm()))
+	res.uint32(nlink)
+	res.uint32(s.vfs.Opt.UID)
+	res.uint32(s.vfs.Opt.GID)
+	res.uint64(size) // size
+	res.uint64(size) // used
+	res.uint32(0)    // rdev
+	res.uint32(0)
+	res.uint64(s.fsid)
+	res.uint64(fileID(handle))
+	writeTime(res, modTime) // atime
+	writeTime(res, modTime) // mtime
+	writeTime(res, modTime) // ctime
+}
+
+// writePostOpAttr writes the post_op_attr of node, which may be nil
+func (s *server) writePostOpAttr(res *xdrWriter, handle []byte, node vfs.Node) {
+	if node == nil {
+		res.bool(false)
+		return
+	}
+	res.bool(true)
+	s.writeAttr(res, handle, node)
+}
+
+// writeWcc writes the wcc_data of node, which may be nil. The before
+// attributes aren't tracked so are never sent.
+func (s *server) writeWcc(res *xdrWriter, handle []byte, node vfs.Node) {
+	res.bool(false)
+	s.writePostOpAttr(res, handle, node)
+}
+
+// nodeOrNil returns dir as a Node, or nil if it is nil, so a nil
+// directory is written as no attributes
+func nodeOrNil(dir *vfs.Dir) vfs.Node {
+	if dir == nil {
+		return nil
+	}
+	return dir
+}
+
+// nfsNull does nothing
+func (s *server) nfsNull(args *xdrReader, res *xdrWriter) error {
+	return nil
+}
+
+// nfsNotSupported is used for the procedures which make things the VFS
+// doesn't have
+func (s *server) nfsNotSupported(args *xdrReader, res *xdrWriter) error {
+	res.uint32(nfs3ErrNotSupp)
+	s.writeWcc(res, nil, nil)
+	return nil
+}
+
+// nfsGetattr returns the attributes of a file or directory
+func (s *server) nfsGetattr(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	_, node, err := s.resolve(handle)
+	res.uint32(nfsStatus(err))
+	if err != nil {
+		return nil
+	}
+	s.writeAttr(res, handle, node)
+	return nil
+}
+
+// nfsSetattr sets the size and modification time of a file or
+// directory
+func (s *server) nfsSetattr(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	attr := readSattr(args)
+	if args.bool() {
+		_ = readTime(args) // guard ctime
+	}
+	if args.err != nil {
+		return errGarbage
+	}
+	nodePath, node, err := s.resolve(handle)
+	if err == nil {
+		err = setAttr(node, attr)
+		if err != nil {
+			fs.Debugf(nodePath, "NFS: failed to set attributes: %v", err)
+		}
+	}
+	res.uint32(nfsStatus(err))
+	s.writeWcc(res, handle, node)
+	return nil
+}
+
+// nfsLookup finds a name in a directory
+func (s *server) nfsLookup(args *xdrReader, res *xdrWriter) error {
+	dirHandle, name := readDirOp(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	dirPath, dir, err := s.resolveDir(dirHandle)
+	if err != nil {
+		res.uint32(nfsStatus(err))
+		s.writePostOpAttr(res, dirHandle, nodeOrNil(dir))
+		return nil
+	}
+	var (
+		nodePath string
+		node     vfs.Node
+	)
+	switch name {
+	case ".":
+		nodePath, node = dirPath, dir
+	case "..":
+		nodePath = path.Dir(dirPath)
+		if nodePath == "." {
+			nodePath = ""
+		}
+		node, err = s.vfs.Stat(nodePath)
+	default:
+		nodePath = path.Join(dirPath, name)
+		node, err = dir.Stat(name)
+	}
+	res.uint32(nfsStatus(err))
+	if err != nil {
+		s.writePostOpAttr(res, dirHandle, dir)
+		return nil
+	}
+	handle := s.handles.toHandle(nodePath)
+	res.opaque(handle)
+	s.writePostOpAttr(res, handle, node)
+	s.writePostOpAttr(res, dirHandle, dir)
+	return nil
+}
+
+// nfsAccess returns which of the accesses asked for are allowed.
+//
+// Everything is allowed apart from modifications on a read only VFS.
+func (s *server) nfsAccess(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	access := args.uint32()
+	if args.err != nil {
+		return errGarbage
+	}
+	_, node, err := s.resolve(handle)
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, node)
+	if err != nil {
+		return nil
+	}
+	if s.vfs.Opt.ReadOnly {
+		access &^= access3Modify | access3Extend | access3Delete
+	}
+	res.uint32(access)
+	return nil
+}
+
+// nfsReadlink fails as the VFS has no symlinks
+func (s *server) nfsReadlink(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	_, node, err := s.resolve(handle)
+	if err == nil {
+		err = vfs.EINVAL
+	}
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, node)
+	return nil
+}
+
+// nfsRead reads data from a file
+func (s *server) nfsRead(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	offset := args.uint64()
+	count := args.uint32()
+	if args.err != nil {
+		return errGarbage
+	}
+	if count > nfsMaxIO {
+		count = nfsMaxIO
+	}
+	nodePath, node, err := s.resolve(handle)
+	if err == nil && node.IsDir() {
+		err = nfsError(nfs3ErrIsDir)
+	}
+	var (
+		data []byte
+		eof  bool
+	)
+	if err == nil {
+		data, eof, err = s.read(nodePath, int64(offset), int(count))
+	}
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, node)
+	if err != nil {
+		return nil
+	}
+	res.uint32(uint32(len(data)))
+	res.bool(eof || int64(offset)+int64(len(data)) >= node.Size())
+	res.opaque(data)
+	return nil
+}
+
+// read reads up to count bytes from nodePath at offset returning the
+// data and whether the end of the file was reached
+func (s *server) read(nodePath string, offset int64, count int) (data []byte, eof bool, err error) {
+	f, err := s.files.getRead(nodePath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer s.files.release(f)
+	data = make([]byte, count)
+	n, err := f.handle.ReadAt(data, offset)
+	if err == io.EOF {
+		eof, err = true, nil
+	}
+	if err != nil {
+		fs.Errorf(nodePath, "NFS: read failed: %v", err)
+		return nil, false, err
+	}
+	return data[:n], eof, nil
+}
+
+// nfsWrite writes data to a file.
+//
+// If the client asks for the data to be stable the file is closed so
+// the data is uploaded before replying, otherwise it is left open for
+// more writes until COMMIT is called.
+func (s *server) nfsWrite(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	offset := args.uint64()
+	_ = args.uint32() // count - the same as the length of the data
+	stable := args.uint32()
+	data := args.opaque(nfsMaxIO)
+	if args.err != nil {
+		return errGarbage
+	}
+	nodePath, node, err := s.resolve(handle)
+	if err == nil && node.IsDir() {
+		err = nfsError(nfs3ErrIsDir)
+	}
+	var committed uint32 = unstable
+	if err == nil {
+		err = s.write(nodePath, int64(offset), data)
+	}
+	if err == nil && stable != unstable {
+		committed = fileSync
+		err = s.files.close(nodePath)
+	}
+	if err != nil {
+		fs.Errorf(nodePath, "NFS: write failed: %v", err)
+	}
+	res.uint32(nfsStatus(err))
+	s.writeWcc(res, handle, node)
+	if err != nil {
+		return nil
+	}
+	res.uint32(uint32(len(data)))
+	res.uint32(committed)
+	res.fixed(s.verifier[:])
+	return nil
+}
+
+// write writes data to nodePath at offset
+func (s *server) write(nodePath string, offset int64, data []byte) error {
+	f, err := s.files.getWrite(nodePath, offset)
+	if err != nil {
+		return err
+	}
+	defer s.files.release(f)
+	_, err = f.handle.WriteAt(data, offset)
+	return err
+}
+
+// nfsCreate creates a file leaving it open for writing
+func (s *server) nfsCreate(args *xdrReader, res *xdrWriter) error {
+	dirHandle, name := readDirOp(args)
+	mode := args.uint32()
+	var attr sattr3
+	switch mode {
+	case createUnchecked, createGuarded:
+		attr = readSattr(args)
+	case createExclusive:
+		_ = args.fixed(8) // verifier
+	default:
+		return errGarbage
+	}
+	if args.err != nil {
+		return errGarbage
+	}
+	dirPath, dir, err := s.resolveDir(dirHandle)
+	if err == nil {
+		err = checkName(name)
+	}
+	var (
+		nodePath = path.Join(dirPath, name)
+		node     vfs.Node
+	)
+	if err == nil {
+		node, err = dir.Stat(name)
+		switch {
+		case err == nil && mode != createUnchecked:
+			err = vfs.EEXIST
+		case err == nil:
+			err = setAttr(node, attr)
+		case errors.Is(err, vfs.ENOENT):
+			err = s.files.create(nodePath)
+			if err == nil {
+				node, err = dir.Stat(name)
+			}
+		}
+	}
+	if err != nil {
+		fs.Debugf(nodePath, "NFS: create failed: %v", err)
+		res.uint32(nfsStatus(err))
+		s.writeWcc(res, dirHandle, nodeOrNil(dir))
+		return nil
+	}
+	handle := s.handles.toHandle(nodePath)
+	res.uint32(nfs3OK)
+	res.bool(true)
+	res.opaque(handle)
+	s.writePostOpAttr(res, handle, node)
+	s.writeWcc(res, dirHandle, dir)
+	return nil
+}
+
+// nfsMkdir makes a directory
+func (s *server) nfsMkdir(args *xdrReader, res *xdrWriter) error {
+	dirHandle, name := readDirOp(args)
+	_ = readSattr(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	dirPath, dir, err := s.resolveDir(dirHandle)
+	if err == nil {
+		err = checkName(name)
+	}
+	var newDir *vfs.Dir
+	if err == nil {
+		newDir, err = dir.Mkdir(name)
+	}
+	if err != nil {
+		fs.Debugf(path.Join(dirPath, name), "NFS: mkdir failed: %v", err)
+		res.uint32(nfsStatus(err))
+		s.writeWcc(res, dirHandle, nodeOrNil(dir))
+		return nil
+	}
+	handle := s.handles.toHandle(path.Join(dirPath, name))
+	res.uint32(nfs3OK)
+	res.bool(true)
+	res.opaque(handle)
+	s.writePostOpAttr(res, handle, newDir)
+	s.writeWcc(res, dirHandle, dir)
+	return nil
+}
+
+// remove removes name from the directory with dirHandle. If isDir is
+// set it must be a directory, otherwise it must be a file.
+func (s *server) remove(args *xdrReader, res *xdrWriter, isDir bool) error {
+	dirHandle, name := readDirOp(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	dirPath, dir, err := s.resolveDir(dirHandle)
+	if err == nil {
+		err = checkName(name)
+	}
+	nodePath := path.Join(dirPath, name)
+	var node vfs.Node
+	if err == nil {
+		node, err = dir.Stat(name)
+	}
+	if err == nil {
+		switch {
+		case isDir && !node.IsDir():
+			err = nfsError(nfs3ErrNotDir)
+		case !isDir && node.IsDir():
+			err = nfsError(nfs3ErrIsDir)
+		}
+	}
+	if err == nil {
+		if closeErr := s.files.close(nodePath); closeErr != nil {
+			fs.Errorf(nodePath, "NFS: failed to close file before removing it: %v", closeErr)
+		}
+		err = node.Remove()
+	}
+	if err == nil {
+		s.handles.forget(nodePath)
+	} else {
+		fs.Debugf(nodePath, "NFS: remove failed: %v", err)
+	}
+	res.uint32(nfsStatus(err))
+	s.writeWcc(res, dirHandle, nodeOrNil(dir))
+	return nil
+}
+
+// nfsRemove removes a file
+func (s *server) nfsRemove(args *xdrReader, res *xdrWriter) error {
+	return s.remove(args, res, false)
+}
+
+// nfsRmdir removes an empty directory
+func (s *server) nfsRmdir(args *xdrReader, res *xdrWriter) error {
+	return s.remove(args, res, true)
+}
+
+// nfsRename renames a file or directory, replacing the destination
+// if it exists
+func (s *server) nfsRename(args *xdrReader, res *xdrWriter) error {
+	fromHandle, fromName := readDirOp(args)
+	toHandle, toName := readDirOp(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	fromDirPath, fromDir, err := s.resolveDir(fromHandle)
+	var (
+		toDirPath string
+		toDir     *vfs.Dir
+	)
+	if err == nil {
+		toDirPath, toDir, err = s.resolveDir(toHandle)
+	}
+	if err == nil {
+		err = checkName(fromName)
+	}
+	if err == nil {
+		err = checkName(toName)
+	}
+	fromPath, toPath := path.Join(fromDirPath, fromName), path.Join(toDirPath, toName)
+	if err == nil {
+		for _, p := range []string{fromPath, toPath} {
+			if closeErr := s.files.close(p); closeErr != nil {
+				fs.Errorf(p, "NFS: failed to close file before renaming it: %v", closeErr)
+			}
+		}
+		err = fromDir.Rename(fromName, toName, toDir)
+	}
+	if err == nil {
+		s.handles.rename(fromPath, toPath)
+	} else {
+		fs.Debugf(fromPath, "NFS: rename to %q failed: %v", toPath, err)
+	}
+	res.uint32(nfsStatus(err))
+	s.writeWcc(res, fromHandle, nodeOrNil(fromDir))
+	s.writeWcc(res, toHandle, nodeOrNil(toDir))
+	return nil
+}
+
+// nfsLink fails as the VFS has no hard links
+func (s *server) nfsLink(args *xdrReader, res *xdrWriter) error {
+	res.uint32(nfs3ErrNotSupp)
+	s.writePostOpAttr(res, nil, nil)
+	s.writeWcc(res, nil, nil)
+	return nil
+}
+
+// dirEntry is an entry in a directory listing reply
+type dirEntry struct {
+	path   string
+	name   string
+	node   vfs.Node
+	cookie uint64
+}
+
+// listDir reads the directory with handle starting after cookie,
+// returning the entries which fit in the reply and whether the end of
+// the directory was reached.
+//
+// fits is called with the name of each entry and should return false
+// once the reply is full.
+func (s *server) listDir(handle []byte, cookie uint64, fits func(name string) bool) (dir *vfs.Dir, entries []dirEntry, eof bool, err error) {
+	dirPath, dir, err := s.resolveDir(handle)
+	if err != nil {
+		return dir, nil, false, err
+	}
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return dir, nil, false, err
+	}
+	if cookie > uint64(len(nodes)) {
+		cookie = uint64(len(nodes))
+	}
+	for i, node := range nodes[cookie:] {
+		if !fits(node.Name()) {
+			if len(entries) == 0 {
+				return dir, nil, false, nfsError(nfs3ErrTooSmall)
+			}
+			return dir, entries, false, nil
+		}
+		entries = append(entries, dirEntry{
+			path:   path.Join(dirPath, node.Name()),
+			name:   node.Name(),
+			node:   node,
+			cookie: cookie + uint64(i) + 1,
+		})
+	}
+	return dir, entries, true, nil
+}
+
+// direntSize returns the size of the XDR encoding of an entry3 name
+// in a directory listing
+func direntSize(name string) int {
+	return 4 + 8 + 4 + len(name) + pad(len(name)) + 8
+}
+
+// nfsReaddir lists a directory.
+//
+// The cookie of each entry is its position in the directory plus one.
+func (s *server) nfsReaddir(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	cookie := args.uint64()
+	_ = args.fixed(8) // cookieverf
+	count := int(args.uint32())
+	if args.err != nil {
+		return errGarbage
+	}
+	size := readdirResOKSize
+	dir, entries, eof, err := s.listDir(handle, cookie, func(name string) bool {
+		size += direntSize(name)
+		return size <= count
+	})
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, nodeOrNil(dir))
+	if err != nil {
+		return nil
+	}
+	res.fixed(make([]byte, 8)) // cookieverf
+	for _, entry := range entries {
+		res.bool(true)
+		res.uint64(fileID(s.handles.hash(entry.path)))
+		res.string(entry.name)
+		res.uint64(entry.cookie)
+	}
+	res.bool(false)
+	res.bool(eof)
+	return nil
+}
+
+// nfsReaddirplus lists a directory returning the handles and
+// attributes of the entries as well
+func (s *server) nfsReaddirplus(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	cookie := args.uint64()
+	_ = args.fixed(8) // cookieverf
+	dirCount := int(args.uint32())
+	maxCount := int(args.uint32())
+	if args.err != nil {
+		return errGarbage
+	}
+	dirSize, size := 0, readdirResOKSize
+	dir, entries, eof, err := s.listDir(handle, cookie, func(name string) bool {
+		dirSize += direntSize(name)
+		size += direntSize(name) + postOpAttrSize + postOpFhSize
+		return dirSize <= dirCount && size <= maxCount
+	})
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, nodeOrNil(dir))
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.path
+	}
+	handles := s.handles.toHandles(paths...)
+	res.fixed(make([]byte, 8)) // cookieverf
+	for i, entry := range entries {
+		res.bool(true)
+		res.uint64(fileID(handles[i]))
+		res.string(entry.name)
+		res.uint64(entry.cookie)
+		s.writePostOpAttr(res, handles[i], entry.node)
+		res.bool(true)
+		res.opaque(handles[i])
+	}
+	res.bool(false)
+	res.bool(eof)
+	return nil
+}
+
+// nfsFsstat returns the space used and free
+func (s *server) nfsFsstat(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	_, node, err := s.resolve(handle)
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, node)
+	if err != nil {
+		return nil
+	}
+	total, _, free := s.vfs.Statfs()
+	res.uint64(uint64(total))
+	res.uint64(uint64(free))
+	res.uint64(uint64(free))
+	res.uint64(1e9) // total files
+	res.uint64(1e9) // free files
+	res.uint64(1e9) // available files
+	res.uint32(0)   // invarsec
+	return nil
+}
+
+// nfsFsinfo returns the static properties of the file system
+func (s *server) nfsFsinfo(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	_, node, err := s.resolve(handle)
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, node)
+	if err != nil {
+		return nil
+	}
+	delta := s.f.Precision()
+	if delta <= 0 || delta > time.Second {
+		delta = time.Second
+	}
+	res.uint32(nfsMaxIO)    // rtmax
+	res.uint32(nfsMaxIO)    // rtpref
+	res.uint32(nfsFileMult) // rtmult
+	res.uint32(nfsMaxIO)    // wtmax
+	res.uint32(nfsMaxIO)    // wtpref
+	res.uint32(nfsFileMult) // wtmult
+	res.uint32(nfsDirPref)  // dtpref
+	res.uint64(1<<63 - 1)   // maxfilesize
+	res.uint32(uint32(delta / time.Second))
+	res.uint32(uint32(delta % time.Second))
+	res.uint32(fsf3Homogeneous | fsf3CanSetTime)
+	return nil
+}
+
+// nfsPathconf returns the limits on names
+func (s *server) nfsPathconf(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	if args.err != nil {
+		return errGarbage
+	}
+	_, node, err := s.resolve(handle)
+	res.uint32(nfsStatus(err))
+	s.writePostOpAttr(res, handle, node)
+	if err != nil {
+		return nil
+	}
+	res.uint32(1)          // linkmax
+	res.uint32(nfsNameMax) // name_max
+	res.bool(true)         // no_trunc
+	res.bool(true)         // chown_restricted
+	res.bool(s.vfs.Opt.CaseInsensitive)
+	res.bool(true) // case_preserving
+	return nil
+}
+
+// nfsCommit closes the file if it is open so any data written is
+// uploaded
+func (s *server) nfsCommit(args *xdrReader, res *xdrWriter) error {
+	handle := readHandle(args)
+	_ = args.uint64() // offset
+	_ = args.uint32() // count
+	if args.err != nil {
+		return errGarbage
+	}
+	nodePath, node, err := s.resolve(handle)
+	if err == nil {
+		err = s.files.close(nodePath)
+		if err != nil {
+			fs.Errorf(nodePath, "NFS: commit failed: %v", err)
+		}
+	}
+	res.uint32(nfsStatus(err))
+	s.writeWcc(res, handle, node)
+	if err != nil {
+		return nil
+	}
+	res.fixed(s.verifier[:])
+	return nil
+}
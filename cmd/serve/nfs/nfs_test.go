@@ -0,0 +1,409 @@
+package nfs
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClient is a minimal NFS client talking to a test server
+type testClient struct {
+	t    *testing.T
+	s    *server
+	conn net.Conn
+	in   *bufio.Reader
+	xid  uint32
+}
+
+// newTestClient starts a server serving a temporary directory and
+// connects to it
+func newTestClient(t *testing.T) *testClient {
+	ctx := context.Background()
+	f, err := fs.NewFs(ctx, t.TempDir())
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.ListenAddr = "localhost:0"
+	opt.HandleCacheType = cacheTypeMemory
+	s, err := newServer(ctx, f, &opt)
+	require.NoError(t, err)
+	require.NoError(t, s.Serve())
+	conn, err := net.Dial("tcp", s.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+		s.Close()
+		s.Wait()
+	})
+	return &testClient{
+		t:    t,
+		s:    s,
+		conn: conn,
+		in:   bufio.NewReader(conn),
+	}
+}
+
+// call calls a procedure returning the accept status and the results
+func (c *testClient) call(prog, vers, proc uint32, args *xdrWriter) (uint32, *xdrReader) {
+	c.xid++
+	w := makeCall(c.xid, rpcVersion, prog, vers, proc)
+	if args != nil {
+		_, _ = w.Write(args.Bytes())
+	}
+	require.NoError(c.t, sendRecord(c.conn, w))
+	record, err := readRecord(c.in)
+	require.NoError(c.t, err)
+	r := newXDRReader(record)
+	require.Equal(c.t, c.xid, r.uint32())
+	require.Equal(c.t, uint32(msgReply), r.uint32())
+	require.Equal(c.t, uint32(replyAccepted), r.uint32())
+	_ = r.uint32() // verifier
+	_ = r.opaque(maxAuthSize)
+	return r.uint32(), r
+}
+
+// nfs calls an NFS procedure returning the NFS status and the rest of
+// the results
+func (c *testClient) nfs(proc uint32, args *xdrWriter) (uint32, *xdrReader) {
+	accept, r := c.call(nfsProgram, nfsVersion, proc, args)
+	require.Equal(c.t, uint32(acceptSuccess), accept)
+	return r.uint32(), r
+}
+
+// testAttr is the part of a fattr3 checked by the tests
+type testAttr struct {
+	ftype  uint32
+	size   uint64
+	fileID uint64
+}
+
+// readAttr reads a fattr3
+func readAttr(r *xdrReader) (attr testAttr) {
+	attr.ftype = r.uint32()
+	_ = r.fixed(4 * 4) // mode, nlink, uid, gid
+	attr.size = r.uint64()
+	_ = r.fixed(8 + 8 + 8) // used, rdev, fsid
+	attr.fileID = r.uint64()
+	_ = r.fixed(3 * 8) // times
+	return attr
+}
+
+// readPostOpAttr reads a post_op_attr returning nil if there aren't
+// any attributes
+func readPostOpAttr(r *xdrReader) *testAttr {
+	if !r.bool() {
+		return nil
+	}
+	attr := readAttr(r)
+	return &attr
+}
+
+// readWcc reads a wcc_data returning the after attributes
+func readWcc(r *xdrReader) *testAttr {
+	if r.bool() {
+		_ = r.fixed(8 + 8 + 8) // size, mtime, ctime
+	}
+	return readPostOpAttr(r)
+}
+
+// handleArgs makes the arguments for calls which take just a handle
+func handleArgs(handle []byte) *xdrWriter {
+	w := new(xdrWriter)
+	w.opaque(handle)
+	return w
+}
+
+// dirOpArgs makes a diropargs3
+func dirOpArgs(dirHandle []byte, name string) *xdrWriter {
+	w := handleArgs(dirHandle)
+	w.string(name)
+	return w
+}
+
+// writeEmptySattr writes a sattr3 which sets nothing
+func writeEmptySattr(w *xdrWriter) {
+	for i := 0; i < 6; i++ {
+		w.uint32(0)
+	}
+}
+
+// mount mounts the root returning its handle
+func (c *testClient) mount() []byte {
+	w := new(xdrWriter)
+	w.string("/")
+	accept, r := c.call(mountProgram, mountVersion, 1, w)
+	require.Equal(c.t, uint32(acceptSuccess), accept)
+	require.Equal(c.t, uint32(mnt3OK), r.uint32())
+	root := r.opaque(nfsFhSize)
+	require.NoError(c.t, r.err)
+	return root
+}
+
+// getattr returns the status and attributes of handle
+func (c *testClient) getattr(handle []byte) (uint32, testAttr) {
+	status, r := c.nfs(1, handleArgs(handle))
+	if status != nfs3OK {
+		return status, testAttr{}
+	}
+	attr := readAttr(r)
+	require.NoError(c.t, r.err)
+	return status, attr
+}
+
+// lookup returns the status and handle of name in dir
+func (c *testClient) lookup(dir []byte, name string) (uint32, []byte) {
+	status, r := c.nfs(3, dirOpArgs(dir, name))
+	if status != nfs3OK {
+		return status, nil
+	}
+	handle := r.opaque(nfsFhSize)
+	require.NoError(c.t, r.err)
+	return status, handle
+}
+
+// create creates name in dir returning its handle
+func (c *testClient) create(dir []byte, name string) []byte {
+	w := dirOpArgs(dir, name)
+	w.uint32(createGuarded)
+	writeEmptySattr(w)
+	status, r := c.nfs(8, w)
+	require.Equal(c.t, uint32(nfs3OK), status)
+	require.True(c.t, r.bool())
+	handle := r.opaque(nfsFhSize)
+	attr := readPostOpAttr(r)
+	require.NotNil(c.t, attr)
+	assert.Equal(c.t, uint32(nf3Reg), attr.ftype)
+	readWcc(r)
+	require.NoError(c.t, r.err)
+	return handle
+}
+
+// mkdir makes name in dir returning its handle
+func (c *testClient) mkdir(dir []byte, name string) []byte {
+	w := dirOpArgs(dir, name)
+	writeEmptySattr(w)
+	status, r := c.nfs(9, w)
+	require.Equal(c.t, uint32(nfs3OK), status)
+	require.True(c.t, r.bool())
+	handle := r.opaque(nfsFhSize)
+	attr := readPostOpAttr(r)
+	require.NotNil(c.t, attr)
+	assert.Equal(c.t, uint32(nf3Dir), attr.ftype)
+	return handle
+}
+
+// write writes data to handle at offset returning the stability
+// committed
+func (c *testClient) write(handle []byte, offset uint64, data string, stable uint32) uint32 {
+	w := handleArgs(handle)
+	w.uint64(offset)
+	w.uint32(uint32(len(data)))
+	w.uint32(stable)
+	w.string(data)
+	status, r := c.nfs(7, w)
+	require.Equal(c.t, uint32(nfs3OK), status)
+	readWcc(r)
+	assert.Equal(c.t, uint32(len(data)), r.uint32())
+	committed := r.uint32()
+	assert.Equal(c.t, c.s.verifier[:], r.fixed(8))
+	require.NoError(c.t, r.err)
+	return committed
+}
+
+// commit commits the data written to handle
+func (c *testClient) commit(handle []byte) {
+	w := handleArgs(handle)
+	w.uint64(0)
+	w.uint32(0)
+	status, r := c.nfs(21, w)
+	require.Equal(c.t, uint32(nfs3OK), status)
+	readWcc(r)
+	assert.Equal(c.t, c.s.verifier[:], r.fixed(8))
+	require.NoError(c.t, r.err)
+}
+
+// read reads count bytes from handle at offset
+func (c *testClient) read(handle []byte, offset uint64, count uint32) (data string, eof bool) {
+	w := handleArgs(handle)
+	w.uint64(offset)
+	w.uint32(count)
+	status, r := c.nfs(6, w)
+	require.Equal(c.t, uint32(nfs3OK), status)
+	readPostOpAttr(r)
+	n := r.uint32()
+	eof = r.bool()
+	data = r.string(nfsMaxIO)
+	require.NoError(c.t, r.err)
+	assert.Equal(c.t, int(n), len(data))
+	return data, eof
+}
+
+// testEntry is an entry read from a directory listing
+type testEntry struct {
+	name   string
+	cookie uint64
+	handle []byte
+	attr   *testAttr
+}
+
+// readdirplus lists dir from cookie returning the entries and eof
+func (c *testClient) readdirplus(dir []byte, cookie uint64, maxCount uint32) (status uint32, entries []testEntry, eof bool) {
+	w := handleArgs(dir)
+	w.uint64(cookie)
+	w.fixed(make([]byte, 8))
+	w.uint32(maxCount) // dircount
+	w.uint32(maxCount)
+	status, r := c.nfs(17, w)
+	readPostOpAttr(r)
+	if status != nfs3OK {
+		return status, nil, false
+	}
+	_ = r.fixed(8) // cookieverf
+	for r.bool() {
+		var entry testEntry
+		id := r.uint64()
+		entry.name = r.string(nfsPathMax)
+		entry.cookie = r.uint64()
+		entry.attr = readPostOpAttr(r)
+		require.True(c.t, r.bool())
+		entry.handle = r.opaque(nfsFhSize)
+		require.NotNil(c.t, entry.attr)
+		assert.Equal(c.t, id, entry.attr.fileID)
+		entries = append(entries, entry)
+	}
+	eof = r.bool()
+	require.NoError(c.t, r.err)
+	return status, entries, eof
+}
+
+// removeName removes name from dir with proc returning the status
+func (c *testClient) removeName(proc uint32, dir []byte, name string) uint32 {
+	status, r := c.nfs(proc, dirOpArgs(dir, name))
+	readWcc(r)
+	require.NoError(c.t, r.err)
+	return status
+}
+
+func TestNFS(t *testing.T) {
+	c := newTestClient(t)
+
+	// Unknown programs, versions and procedures
+	accept, _ := c.call(1, 1, 0, nil)
+	assert.Equal(t, uint32(acceptProgUnavail), accept)
+	accept, _ = c.call(nfsProgram, 2, 0, nil)
+	assert.Equal(t, uint32(acceptProgMismatch), accept)
+	accept, _ = c.call(nfsProgram, nfsVersion, 99, nil)
+	assert.Equal(t, uint32(acceptProcUnavail), accept)
+	accept, _ = c.call(nfsProgram, nfsVersion, 1, nil)
+	assert.Equal(t, uint32(acceptGarbageArgs), accept)
+	accept, _ = c.call(nfsProgram, nfsVersion, 0, nil)
+	assert.Equal(t, uint32(acceptSuccess), accept)
+
+	root := c.mount()
+	assert.Equal(t, c.s.handles.hash(""), root)
+	status, attr := c.getattr(root)
+	require.Equal(t, uint32(nfs3OK), status)
+	assert.Equal(t, uint32(nf3Dir), attr.ftype)
+	assert.Equal(t, fileID(root), attr.fileID)
+
+	status, _ = c.lookup(root, "file.txt")
+	assert.Equal(t, uint32(nfs3ErrNoEnt), status)
+
+	// Create a file and write to it
+	file := c.create(root, "file.txt")
+	assert.Equal(t, uint32(unstable), c.write(file, 0, "hello ", unstable))
+	assert.Equal(t, uint32(unstable), c.write(file, 6, "world", unstable))
+	c.commit(file)
+
+	status, handle := c.lookup(root, "file.txt")
+	require.Equal(t, uint32(nfs3OK), status)
+	assert.Equal(t, file, handle)
+	status, attr = c.getattr(file)
+	require.Equal(t, uint32(nfs3OK), status)
+	assert.Equal(t, uint32(nf3Reg), attr.ftype)
+	assert.Equal(t, uint64(11), attr.size)
+
+	data, eof := c.read(file, 0, 100)
+	assert.Equal(t, "hello world", data)
+	assert.True(t, eof)
+	data, eof = c.read(file, 6, 2)
+	assert.Equal(t, "wo", data)
+	assert.False(t, eof)
+
+	// Creating it again should fail
+	w := dirOpArgs(root, "file.txt")
+	w.uint32(createGuarded)
+	writeEmptySattr(w)
+	status, _ = c.nfs(8, w)
+	assert.Equal(t, uint32(nfs3ErrExist), status)
+
+	// A stable write is committed straight away
+	file2 := c.create(root, "file2.txt")
+	assert.Equal(t, uint32(fileSync), c.write(file2, 0, "potato", fileSync))
+	data, _ = c.read(file2, 0, 100)
+	assert.Equal(t, "potato", data)
+
+	// Directories
+	dir := c.mkdir(root, "dir")
+	status, entries, eof := c.readdirplus(root, 0, 64*1024)
+	require.Equal(t, uint32(nfs3OK), status)
+	assert.True(t, eof)
+	require.Equal(t, 3, len(entries))
+	for i, want := range []string{"dir", "file.txt", "file2.txt"} {
+		assert.Equal(t, want, entries[i].name)
+		assert.Equal(t, uint64(i+1), entries[i].cookie)
+	}
+	assert.Equal(t, dir, entries[0].handle)
+	assert.Equal(t, file, entries[1].handle)
+	assert.Equal(t, uint64(11), entries[1].attr.size)
+
+	// Continue from a cookie
+	status, entries, eof = c.readdirplus(root, 2, 64*1024)
+	require.Equal(t, uint32(nfs3OK), status)
+	assert.True(t, eof)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "file2.txt", entries[0].name)
+
+	// Listing which is too small
+	status, _, _ = c.readdirplus(root, 0, 100)
+	assert.Equal(t, uint32(nfs3ErrTooSmall), status)
+
+	// Rename the file into the directory - the old handle should
+	// carry on working
+	w = dirOpArgs(root, "file.txt")
+	_, _ = w.Write(dirOpArgs(dir, "moved.txt").Bytes())
+	status, r := c.nfs(14, w)
+	require.Equal(t, uint32(nfs3OK), status)
+	readWcc(r)
+	readWcc(r)
+	require.NoError(t, r.err)
+	status, _ = c.lookup(root, "file.txt")
+	assert.Equal(t, uint32(nfs3ErrNoEnt), status)
+	status, moved := c.lookup(dir, "moved.txt")
+	require.Equal(t, uint32(nfs3OK), status)
+	data, _ = c.read(file, 0, 100)
+	assert.Equal(t, "hello world", data)
+	data, _ = c.read(moved, 0, 100)
+	assert.Equal(t, "hello world", data)
+
+	// Remove things
+	assert.Equal(t, uint32(nfs3ErrNotEmpty), c.removeName(13, root, "dir"))
+	assert.Equal(t, uint32(nfs3ErrIsDir), c.removeName(12, root, "dir"))
+	assert.Equal(t, uint32(nfs3ErrNotDir), c.removeName(13, dir, "moved.txt"))
+	assert.Equal(t, uint32(nfs3OK), c.removeName(12, dir, "moved.txt"))
+	assert.Equal(t, uint32(nfs3OK), c.removeName(13, root, "dir"))
+	assert.Equal(t, uint32(nfs3ErrNoEnt), c.removeName(12, root, "missing"))
+	status, _ = c.getattr(moved)
+	assert.Equal(t, uint32(nfs3ErrStale), status)
+	status, _ = c.getattr(dir)
+	assert.Equal(t, uint32(nfs3ErrStale), status)
+
+	// Unknown handles are stale
+	status, _ = c.getattr(make([]byte, handleSize))
+	assert.Equal(t, uint32(nfs3ErrStale), status)
+}
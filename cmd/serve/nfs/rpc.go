@@ -0,0 +1,151 @@
+package nfs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ONC RPC (RFC 5531) constants
+const (
+	rpcVersion = 2
+
+	msgCall  = 0
+	msgReply = 1
+
+	replyAccepted = 0
+	replyDenied   = 1
+
+	acceptSuccess      = 0
+	acceptProgUnavail  = 1
+	acceptProgMismatch = 2
+	acceptProcUnavail  = 3
+	acceptGarbageArgs  = 4
+	acceptSystemErr    = 5
+
+	rejectRPCMismatch = 0
+
+	authNone = 0
+	authSys  = 1
+
+	maxAuthSize = 400
+)
+
+// Record marking (RFC 5531 section 11) constants
+const (
+	lastFragment  = 0x80000000
+	maxRecordSize = 4 * 1024 * 1024
+)
+
+// rpcCall is a decoded RPC call message
+type rpcCall struct {
+	xid     uint32
+	rpcvers uint32
+	prog    uint32
+	vers    uint32
+	proc    uint32
+	args    *xdrReader // the procedure arguments
+}
+
+// readRecord reads a record, which holds one RPC message, from in
+func readRecord(in io.Reader) ([]byte, error) {
+	var (
+		header [4]byte
+		record []byte
+	)
+	for {
+		_, err := io.ReadFull(in, header[:])
+		if err != nil {
+			return nil, err
+		}
+		mark := binary.BigEndian.Uint32(header[:])
+		size := int(mark &^ lastFragment)
+		if len(record)+size > maxRecordSize {
+			return nil, fmt.Errorf("RPC record too big: %d bytes", len(record)+size)
+		}
+		start := len(record)
+		record = append(record, make([]byte, size)...)
+		_, err = io.ReadFull(in, record[start:])
+		if err != nil {
+			return nil, err
+		}
+		if mark&lastFragment != 0 {
+			return record, nil
+		}
+	}
+}
+
+// parseCall decodes the header of an RPC call message
+func parseCall(msg []byte) (*rpcCall, error) {
+	r := newXDRReader(msg)
+	call := &rpcCall{
+		xid: r.uint32(),
+	}
+	if msgType := r.uint32(); r.err == nil && msgType != msgCall {
+		return nil, fmt.Errorf("expecting RPC call but got message type %d", msgType)
+	}
+	call.rpcvers = r.uint32()
+	call.prog = r.uint32()
+	call.vers = r.uint32()
+	call.proc = r.uint32()
+	// The credentials and verifier aren't used - NFS has no real
+	// authentication and the VFS has its own uid and gid.
+	_ = r.uint32()
+	_ = r.opaque(maxAuthSize)
+	_ = r.uint32()
+	_ = r.opaque(maxAuthSize)
+	if r.err != nil {
+		return nil, errors.New("truncated RPC call header")
+	}
+	call.args = r
+	return call, nil
+}
+
+// newReply starts a reply to the call with the accept status passed
+// in.
+//
+// The results of the procedure should be written to the writer
+// returned and it sent with sendRecord. Space is left at the start for
+// the record mark.
+func (call *rpcCall) newReply(acceptStat uint32) *xdrWriter {
+	w := new(xdrWriter)
+	w.uint32(0) // record mark filled in by sendRecord
+	w.uint32(call.xid)
+	w.uint32(msgReply)
+	w.uint32(replyAccepted)
+	w.uint32(authNone) // verifier
+	w.opaque(nil)
+	w.uint32(acceptStat)
+	return w
+}
+
+// progMismatch makes a reply saying only version vers of the program
+// is supported
+func (call *rpcCall) progMismatch(vers uint32) *xdrWriter {
+	w := call.newReply(acceptProgMismatch)
+	w.uint32(vers)
+	w.uint32(vers)
+	return w
+}
+
+// rpcMismatch makes a reply rejecting a call with the wrong RPC version
+func (call *rpcCall) rpcMismatch() *xdrWriter {
+	w := new(xdrWriter)
+	w.uint32(0) // record mark filled in by sendRecord
+	w.uint32(call.xid)
+	w.uint32(msgReply)
+	w.uint32(replyDenied)
+	w.uint32(rejectRPCMismatch)
+	w.uint32(rpcVersion)
+	w.uint32(rpcVersion)
+	return w
+}
+
+// sendRecord writes the reply in w to out as a single fragment record
+func sendRecord(out io.Writer, w *xdrWriter) error {
+	buf := w.Bytes()
+	binary.BigEndian.PutUint32(buf, lastFragment|uint32(len(buf)-4))
+	_, err := out.Write(buf)
+	return err
+}
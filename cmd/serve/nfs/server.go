@@ -0,0 +1,225 @@
+package nfs
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+)
+
+// maxConnCalls is the maximum number of calls processed at once on
+// one connection. NFS clients send many calls without waiting for the
+// replies, and out of order writes need to run concurrently.
+const maxConnCalls = 64
+
+// server contains everything to run the server
+type server struct {
+	f        fs.Fs
+	opt      Options
+	vfs      *vfs.VFS
+	ctx      context.Context // for global config
+	handles  *handleCache
+	files    *openFiles
+	fsid     uint64  // identifies the file system to clients
+	verifier [8]byte // write verifier - changes when the server restarts
+	listener net.Listener
+	waitChan chan struct{} // for waiting on the listener to close
+	connsMu  sync.Mutex
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup // for the connections
+}
+
+// newServer makes a new NFS server for f
+func newServer(ctx context.Context, f fs.Fs, opt *Options) (*server, error) {
+	handles, err := newHandleCache(ctx, f, opt)
+	if err != nil {
+		return nil, err
+	}
+	s := &server{
+		f:        f,
+		ctx:      ctx,
+		opt:      *opt,
+		vfs:      vfs.New(f, &vfsflags.Opt),
+		handles:  handles,
+		waitChan: make(chan struct{}),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.files = newOpenFiles(s.vfs)
+	fsid := sha256.Sum256([]byte(fs.ConfigString(f)))
+	s.fsid = binary.BigEndian.Uint64(fsid[:])
+	_, err = rand.Read(s.verifier[:])
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Serve starts the server listening in the background.
+//
+// Use s.Close() and s.Wait() to shutdown server
+func (s *server) Serve() (err error) {
+	s.listener, err = net.Listen("tcp", s.opt.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for NFS connections: %w", err)
+	}
+	fs.Logf(nil, "NFS Server listening on %s", s.Addr())
+	go s.acceptConnections()
+	return nil
+}
+
+// Addr returns the address the server is listening on
+func (s *server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Wait blocks while the listener is open.
+func (s *server) Wait() {
+	<-s.waitChan
+}
+
+// Close shuts the running server down, closing any open files
+func (s *server) Close() {
+	err := s.listener.Close()
+	if err != nil {
+		fs.Errorf(nil, "Error on closing NFS server: %v", err)
+	}
+	s.connsMu.Lock()
+	for nConn := range s.conns {
+		_ = nConn.Close()
+	}
+	s.connsMu.Unlock()
+	s.wg.Wait()
+	s.files.closeAll()
+	err = s.handles.close()
+	if err != nil {
+		fs.Errorf(nil, "Error closing NFS handle database: %v", err)
+	}
+	close(s.waitChan)
+}
+
+// Accept connections and serve them in a go routine
+func (s *server) acceptConnections() {
+	for {
+		nConn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			fs.Errorf(nil, "Failed to accept incoming NFS connection: %v", err)
+			continue
+		}
+		s.connsMu.Lock()
+		s.conns[nConn] = struct{}{}
+		s.connsMu.Unlock()
+		s.wg.Add(1)
+		go s.serveConn(nConn)
+	}
+}
+
+// serveConn reads RPC calls from the connection, processing them
+// concurrently and sending the replies
+func (s *server) serveConn(nConn net.Conn) {
+	what := nConn.RemoteAddr().String()
+	fs.Debugf(what, "NFS connection opened")
+	var (
+		in      = bufio.NewReader(nConn)
+		writeMu sync.Mutex
+		calls   sync.WaitGroup
+		limit   = make(chan struct{}, maxConnCalls)
+	)
+	defer func() {
+		calls.Wait()
+		_ = nConn.Close()
+		s.connsMu.Lock()
+		delete(s.conns, nConn)
+		s.connsMu.Unlock()
+		fs.Debugf(what, "NFS connection closed")
+		s.wg.Done()
+	}()
+	for {
+		msg, err := readRecord(in)
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) && !strings.Contains(err.Error(), "connection reset") {
+				fs.Errorf(what, "NFS: failed to read call: %v", err)
+			}
+			return
+		}
+		call, err := parseCall(msg)
+		if err != nil {
+			fs.Errorf(what, "NFS: %v", err)
+			return
+		}
+		limit <- struct{}{}
+		calls.Add(1)
+		go func() {
+			defer func() {
+				<-limit
+				calls.Done()
+			}()
+			reply := s.handleCall(call)
+			writeMu.Lock()
+			err := sendRecord(nConn, reply)
+			writeMu.Unlock()
+			if err != nil {
+				fs.Debugf(what, "NFS: failed to send reply: %v", err)
+			}
+		}()
+	}
+}
+
+// handleCall dispatches the call to the right program returning the
+// reply
+func (s *server) handleCall(call *rpcCall) *xdrWriter {
+	if call.rpcvers != rpcVersion {
+		return call.rpcMismatch()
+	}
+	var procs []procedure
+	switch call.prog {
+	case mountProgram:
+		if call.vers != mountVersion {
+			return call.progMismatch(mountVersion)
+		}
+		procs = mountProcs
+	case nfsProgram:
+		if call.vers != nfsVersion {
+			return call.progMismatch(nfsVersion)
+		}
+		procs = nfsProcs
+	default:
+		return call.newReply(acceptProgUnavail)
+	}
+	if int(call.proc) >= len(procs) {
+		return call.newReply(acceptProcUnavail)
+	}
+	proc := procs[call.proc]
+	fs.Debugf(nil, "NFS: %s", proc.name)
+	reply := call.newReply(acceptSuccess)
+	err := proc.fn(s, call.args, reply)
+	if err == errGarbage {
+		return call.newReply(acceptGarbageArgs)
+	} else if err != nil {
+		fs.Errorf(nil, "NFS: %s failed: %v", proc.name, err)
+		return call.newReply(acceptSystemErr)
+	}
+	return reply
+}
+
+// procedure is an RPC procedure
+//
+// fn should decode the arguments from args, returning errGarbage if
+// they are invalid, and write the results to res.
+type procedure struct {
+	name string
+	fn   func(s *server, args *xdrReader, res *xdrWriter) error
+}
@@ -0,0 +1,134 @@
+package nfs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// errGarbage is returned when the arguments of a call can't be decoded
+var errGarbage = errors.New("garbage arguments")
+
+// xdrReader decodes XDR (RFC 4506) data from a buffer.
+//
+// The first error is sticky and after it all reads return zero
+// values so callers only need to check err at the end.
+type xdrReader struct {
+	buf []byte
+	err error
+}
+
+// newXDRReader makes a reader decoding buf
+func newXDRReader(buf []byte) *xdrReader {
+	return &xdrReader{buf: buf}
+}
+
+// next returns the next n bytes of the buffer or nil if there
+// aren't enough
+func (r *xdrReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf) {
+		r.err = errGarbage
+		return nil
+	}
+	p := r.buf[:n]
+	r.buf = r.buf[n:]
+	return p
+}
+
+// uint32 reads an unsigned int
+func (r *xdrReader) uint32() uint32 {
+	p := r.next(4)
+	if p == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(p)
+}
+
+// uint64 reads an unsigned hyper
+func (r *xdrReader) uint64() uint64 {
+	p := r.next(8)
+	if p == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(p)
+}
+
+// bool reads a bool
+func (r *xdrReader) bool() bool {
+	return r.uint32() != 0
+}
+
+// fixed reads fixed length opaque data of n bytes
+func (r *xdrReader) fixed(n int) []byte {
+	p := r.next(n)
+	r.next(pad(n))
+	return p
+}
+
+// opaque reads variable length opaque data of at most max bytes
+func (r *xdrReader) opaque(max int) []byte {
+	n := r.uint32()
+	if r.err == nil && n > uint32(max) {
+		r.err = errGarbage
+	}
+	return r.fixed(int(n))
+}
+
+// string reads a string of at most max bytes
+func (r *xdrReader) string(max int) string {
+	return string(r.opaque(max))
+}
+
+// xdrWriter encodes XDR data into a buffer
+type xdrWriter struct {
+	bytes.Buffer
+}
+
+// uint32 writes an unsigned int
+func (w *xdrWriter) uint32(v uint32) {
+	var p [4]byte
+	binary.BigEndian.PutUint32(p[:], v)
+	_, _ = w.Write(p[:])
+}
+
+// uint64 writes an unsigned hyper
+func (w *xdrWriter) uint64(v uint64) {
+	var p [8]byte
+	binary.BigEndian.PutUint64(p[:], v)
+	_, _ = w.Write(p[:])
+}
+
+// bool writes a bool
+func (w *xdrWriter) bool(v bool) {
+	if v {
+		w.uint32(1)
+	} else {
+		w.uint32(0)
+	}
+}
+
+// fixed writes fixed length opaque data
+func (w *xdrWriter) fixed(p []byte) {
+	_, _ = w.Write(p)
+	var zeros [3]byte
+	_, _ = w.Write(zeros[:pad(len(p))])
+}
+
+// opaque writes variable length opaque data
+func (w *xdrWriter) opaque(p []byte) {
+	w.uint32(uint32(len(p)))
+	w.fixed(p)
+}
+
+// string writes a string
+func (w *xdrWriter) string(s string) {
+	w.opaque([]byte(s))
+}
+
+// pad returns the number of bytes needed to pad n to a multiple of 4
+func pad(n int) int {
+	return (4 - n%4) % 4
+}
@@ -0,0 +1,144 @@
+package nfs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXDRRoundTrip(t *testing.T) {
+	w := new(xdrWriter)
+	w.uint32(0x01020304)
+	w.uint64(0x0102030405060708)
+	w.bool(true)
+	w.bool(false)
+	w.opaque([]byte("abcde"))
+	w.string("")
+	w.string("hello")
+	w.fixed([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	assert.Equal(t, 4+8+4+4+(4+8)+4+(4+8)+8, w.Len())
+
+	r := newXDRReader(w.Bytes())
+	assert.Equal(t, uint32(0x01020304), r.uint32())
+	assert.Equal(t, uint64(0x0102030405060708), r.uint64())
+	assert.True(t, r.bool())
+	assert.False(t, r.bool())
+	assert.Equal(t, []byte("abcde"), r.opaque(5))
+	assert.Equal(t, "", r.string(10))
+	assert.Equal(t, "hello", r.string(10))
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, r.fixed(8))
+	require.NoError(t, r.err)
+
+	// reading past the end is sticky
+	assert.Equal(t, uint32(0), r.uint32())
+	assert.Equal(t, errGarbage, r.err)
+	assert.Equal(t, uint64(0), r.uint64())
+	assert.Equal(t, "", r.string(10))
+}
+
+func TestXDRTooLong(t *testing.T) {
+	w := new(xdrWriter)
+	w.string("too long")
+	r := newXDRReader(w.Bytes())
+	assert.Equal(t, "", r.string(4))
+	assert.Equal(t, errGarbage, r.err)
+
+	// length bigger than the buffer
+	r = newXDRReader([]byte{0, 0, 0, 8, 'a'})
+	assert.Nil(t, r.opaque(100))
+	assert.Equal(t, errGarbage, r.err)
+}
+
+func TestPad(t *testing.T) {
+	for n, want := range []int{0, 3, 2, 1, 0, 3} {
+		assert.Equal(t, want, pad(n), n)
+	}
+}
+
+// makeRecord makes a record from the fragments passed in
+func makeRecord(fragments ...string) []byte {
+	w := new(xdrWriter)
+	for i, fragment := range fragments {
+		mark := uint32(len(fragment))
+		if i == len(fragments)-1 {
+			mark |= lastFragment
+		}
+		w.uint32(mark)
+		_, _ = w.WriteString(fragment)
+	}
+	return w.Bytes()
+}
+
+func TestReadRecord(t *testing.T) {
+	in := bytes.NewReader(append(makeRecord("hello", " ", "world"), makeRecord("again")...))
+	record, err := readRecord(in)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(record))
+	record, err = readRecord(in)
+	require.NoError(t, err)
+	assert.Equal(t, "again", string(record))
+	_, err = readRecord(in)
+	assert.Error(t, err)
+
+	// truncated
+	_, err = readRecord(bytes.NewReader(makeRecord("hello")[:6]))
+	assert.Error(t, err)
+
+	// too big
+	w := new(xdrWriter)
+	w.uint32(lastFragment | (maxRecordSize + 1))
+	_, err = readRecord(bytes.NewReader(w.Bytes()))
+	assert.Error(t, err)
+}
+
+// makeCall makes the header of an RPC call message
+func makeCall(xid, rpcvers, prog, vers, proc uint32) *xdrWriter {
+	w := new(xdrWriter)
+	w.uint32(0) // record mark filled in by sendRecord
+	w.uint32(xid)
+	w.uint32(msgCall)
+	w.uint32(rpcvers)
+	w.uint32(prog)
+	w.uint32(vers)
+	w.uint32(proc)
+	w.uint32(authSys) // credentials
+	w.opaque([]byte("ignored"))
+	w.uint32(authNone) // verifier
+	w.opaque(nil)
+	return w
+}
+
+func TestParseCall(t *testing.T) {
+	w := makeCall(42, rpcVersion, nfsProgram, nfsVersion, 1)
+	w.uint32(99)
+	call, err := parseCall(w.Bytes()[4:])
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), call.xid)
+	assert.Equal(t, uint32(rpcVersion), call.rpcvers)
+	assert.Equal(t, uint32(nfsProgram), call.prog)
+	assert.Equal(t, uint32(nfsVersion), call.vers)
+	assert.Equal(t, uint32(1), call.proc)
+	assert.Equal(t, uint32(99), call.args.uint32())
+
+	_, err = parseCall(w.Bytes()[4:20])
+	assert.Error(t, err)
+
+	reply := call.newReply(acceptSuccess)
+	reply.uint32(7)
+	var out bytes.Buffer
+	require.NoError(t, sendRecord(&out, reply))
+	record, err := readRecord(&out)
+	require.NoError(t, err)
+	r := newXDRReader(record)
+	assert.Equal(t, uint32(42), r.uint32())
+	assert.Equal(t, uint32(msgReply), r.uint32())
+	assert.Equal(t, uint32(replyAccepted), r.uint32())
+	assert.Equal(t, uint32(authNone), r.uint32())
+	assert.Equal(t, []byte{}, r.opaque(maxAuthSize))
+	assert.Equal(t, uint32(acceptSuccess), r.uint32())
+	assert.Equal(t, uint32(7), r.uint32())
+	require.NoError(t, r.err)
+	assert.Equal(t, 0, len(r.buf))
+}
@@ -35,6 +35,10 @@ func main() {
 	if out["_root"] == "" {
 		out["_root"] = ""
 	}
+	// If only the user was passed in make up a secret for it
+	if len(in) == 1 && in["user"] != "" {
+		out["_secret"] = in["user"] + "-secret"
+	}
 	json.NewEncoder(os.Stdout).Encode(&out)
 	if err != nil {
 		log.Fatal(err)
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/stretchr/testify/assert"
@@ -232,4 +233,72 @@ func TestRun(t *testing.T) {
 		// check cache is at the same level
 		assert.Equal(t, 1, p.vfsCache.Entries())
 	})
+	t.Run("CallSecret", func(t *testing.T) {
+		// check cache empty
+		assert.Equal(t, 0, p.vfsCache.Entries())
+		defer p.vfsCache.Clear()
+
+		vfs, secret, err := p.CallSecret(testUser)
+		require.NoError(t, err)
+		require.NotNil(t, vfs)
+		assert.Equal(t, "proxy-"+testUser, vfs.Fs().Name())
+		assert.Equal(t, testUser+"-secret", secret)
+
+		// check it is in the cache
+		assert.Equal(t, 1, p.vfsCache.Entries())
+
+		// now try again from the cache
+		vfs2, secret, err := p.CallSecret(testUser)
+		require.NoError(t, err)
+		assert.Equal(t, vfs, vfs2)
+		assert.Equal(t, testUser+"-secret", secret)
+
+		// check cache is at the same level
+		assert.Equal(t, 1, p.vfsCache.Entries())
+	})
+	t.Run("Permissions", func(t *testing.T) {
+		// check cache empty
+		assert.Equal(t, 0, p.vfsCache.Entries())
+		defer p.vfsCache.Clear()
+
+		value, err := p.makeEntry(testUser, testPass, configmap.Simple{
+			"type":      "local",
+			"_root":     "",
+			"_readonly": "true",
+			"_nodelete": "true",
+			"_quota":    "10M",
+		})
+		require.NoError(t, err)
+		entry, ok := value.(cacheEntry)
+		require.True(t, ok)
+		assert.True(t, entry.vfs.Opt.ReadOnly)
+		assert.True(t, entry.vfs.Opt.NoDelete)
+		assert.Equal(t, 10*fs.Mebi, entry.vfs.Opt.Quota)
+
+		for _, test := range []struct {
+			key   string
+			value string
+		}{
+			{"_readonly", "potato"},
+			{"_nodelete", "potato"},
+			{"_quota", "potato"},
+			{"_root", "../other"},
+			{"_root", "dir/../../other"},
+		} {
+			_, err = p.makeEntry(testUser+"2", testPass, configmap.Simple{
+				"type":   "local",
+				"_root":  "",
+				test.key: test.value,
+			})
+			require.Error(t, err, test.key)
+			assert.Contains(t, err.Error(), test.key)
+		}
+
+		// _root must be set
+		_, err = p.makeEntry(testUser+"2", testPass, configmap.Simple{
+			"type": "local",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "_root not set")
+	})
 }
@@ -0,0 +1,148 @@
+package restic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/walk"
+)
+
+// errQuotaExceeded is returned when an upload would take a repository
+// over --max-repo-size
+var errQuotaExceeded = errors.New("repository size limit exceeded")
+
+// repoEntries are the files and directories at the root of a restic
+// repository
+var repoEntries = map[string]bool{
+	"config":    true,
+	"data":      true,
+	"index":     true,
+	"keys":      true,
+	"locks":     true,
+	"snapshots": true,
+}
+
+// repoRoot returns the root of the repository which remote is in.
+//
+// This looks from the end of the path so it works if the path to the
+// repository has a directory called "data" say in it.
+func repoRoot(remote string) string {
+	parts := strings.Split(remote, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if repoEntries[parts[i]] {
+			return strings.Join(parts[:i], "/")
+		}
+	}
+	return remote
+}
+
+// quota keeps track of how big each repository is so it can be
+// limited to --max-repo-size
+type quota struct {
+	f    fs.Fs
+	max  int64
+	mu   sync.Mutex       // protects used
+	used map[string]int64 // size of each repository once read
+}
+
+// newQuota makes a quota limiting repositories to max bytes
+//
+// It returns nil if max isn't set which makes all the methods no-ops.
+func newQuota(f fs.Fs, max fs.SizeSuffix) *quota {
+	if max <= 0 {
+		return nil
+	}
+	return &quota{
+		f:    f,
+		max:  int64(max),
+		used: map[string]int64{},
+	}
+}
+
+// size returns the size of the repository at repo, reading it from
+// the remote the first time it is needed.
+func (q *quota) size(ctx context.Context, repo string) (int64, error) {
+	q.mu.Lock()
+	used, found := q.used[repo]
+	q.mu.Unlock()
+	if found {
+		return used, nil
+	}
+	err := walk.ListR(ctx, q.f, repo, true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+		entries.ForObject(func(o fs.Object) {
+			used += o.Size()
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrorDirNotFound) {
+		return 0, err
+	}
+	fs.Debugf(repo, "Repository size is %v", fs.SizeSuffix(used))
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// Another request may have read it while we were
+	if current, found := q.used[repo]; found {
+		return current, nil
+	}
+	q.used[repo] = used
+	return used, nil
+}
+
+// reserve n bytes in repo returning errQuotaExceeded if there isn't
+// room for them
+func (q *quota) reserve(ctx context.Context, repo string, n int64) error {
+	if q == nil || n <= 0 {
+		return nil
+	}
+	if _, err := q.size(ctx, repo); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.used[repo]+n > q.max {
+		return errQuotaExceeded
+	}
+	q.used[repo] += n
+	return nil
+}
+
+// release n bytes in repo which are no longer used
+func (q *quota) release(repo string, n int64) {
+	if q == nil || n <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, found := q.used[repo]; found {
+		q.used[repo] -= n
+	}
+}
+
+// quotaReader reserves room in repo for the data as it is read
+type quotaReader struct {
+	ctx      context.Context
+	q        *quota
+	repo     string
+	in       io.Reader
+	reserved int64 // bytes reserved so far
+	err      error // error from reserving, returned from then on
+}
+
+// Read implements io.Reader
+func (r *quotaReader) Read(p []byte) (n int, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err = r.in.Read(p)
+	if n > 0 {
+		if r.err = r.q.reserve(r.ctx, r.repo, int64(n)); r.err != nil {
+			return 0, r.err
+		}
+		r.reserved += int64(n)
+	}
+	return n, err
+}
@@ -0,0 +1,156 @@
+package restic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoRoot(t *testing.T) {
+	for _, test := range []struct {
+		remote string
+		want   string
+	}{
+		{"config", ""},
+		{"data/21/2159dd48", ""},
+		{"locks/2159dd48", ""},
+		{"user/repo/config", "user/repo"},
+		{"user/repo/snapshots/2159dd48", "user/repo"},
+		{"data/repo/data/21/2159dd48", "data/repo"},
+		{"data/config", "data"},
+		{"other", "other"},
+	} {
+		assert.Equal(t, test.want, repoRoot(test.remote), test.remote)
+	}
+}
+
+func TestResticMaxRepoSize(t *testing.T) {
+	prev := maxRepoSize
+	maxRepoSize = 20
+	defer func() {
+		maxRepoSize = prev
+	}()
+
+	f := cmd.NewFsSrc([]string{t.TempDir()})
+	opt := DefaultOpt
+	srv, err := NewServer(context.Background(), f, &opt)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/repo1/", "/repo2/"} {
+		checkRequest(t, srv.ServeHTTP, newRequest(t, "POST", path+"?create=true", nil), []wantFunc{wantCode(http.StatusOK)})
+	}
+
+	// unknownSize makes the request look like a chunked upload
+	unknownSize := func(req *http.Request) *http.Request {
+		req.ContentLength = -1
+		return req
+	}
+
+	for i, test := range []struct {
+		req  *http.Request
+		want int
+	}{
+		{newRequest(t, "POST", "/repo1/data/aa01", strings.NewReader("0123456789")), http.StatusOK},
+		{newRequest(t, "POST", "/repo1/data/aa02", strings.NewReader("01234567890")), http.StatusInsufficientStorage},
+		{unknownSize(newRequest(t, "POST", "/repo1/data/aa02", io.NopCloser(strings.NewReader("01234567890")))), http.StatusInsufficientStorage},
+		{newRequest(t, "GET", "/repo1/data/aa02", nil), http.StatusNotFound},
+		// other repositories have their own limit
+		{newRequest(t, "POST", "/repo2/data/aa01", strings.NewReader("01234567890")), http.StatusOK},
+		// replacing a file only counts the difference
+		{newRequest(t, "POST", "/repo1/data/aa01", strings.NewReader("0123456789")), http.StatusOK},
+		{unknownSize(newRequest(t, "POST", "/repo1/data/aa02", io.NopCloser(strings.NewReader("0123456789")))), http.StatusOK},
+		{newRequest(t, "POST", "/repo1/data/aa03", strings.NewReader("0")), http.StatusInsufficientStorage},
+		// deleting makes room
+		{newRequest(t, "DELETE", "/repo1/data/aa01", nil), http.StatusOK},
+		{newRequest(t, "POST", "/repo1/data/aa03", strings.NewReader("0")), http.StatusOK},
+	} {
+		checkRequest(t, srv.ServeHTTP, test.req, []wantFunc{wantCode(test.want)})
+		if t.Failed() {
+			t.Fatalf("request %d: %s %s failed", i, test.req.Method, test.req.URL.Path)
+		}
+	}
+
+	// A new server reads the size from the remote
+	srv, err = NewServer(context.Background(), f, &opt)
+	require.NoError(t, err)
+	checkRequest(t, srv.ServeHTTP, newRequest(t, "POST", "/repo1/data/aa04", strings.NewReader("0123456789")), []wantFunc{wantCode(http.StatusInsufficientStorage)})
+	checkRequest(t, srv.ServeHTTP, newRequest(t, "POST", "/repo1/data/aa04", strings.NewReader("012345678")), []wantFunc{wantCode(http.StatusOK)})
+}
+
+func TestResticRetention(t *testing.T) {
+	prev := retention
+	retention = fs.Duration(time.Hour)
+	defer func() {
+		retention = prev
+	}()
+
+	ctx := writeCtx(context.Background(), "repo/data/21/2159dd48")
+	ci := fs.GetConfig(ctx)
+	assert.True(t, ci.Metadata)
+	assert.Equal(t, "GOVERNANCE", ci.MetadataSet["object-lock-mode"])
+	retainUntil, err := time.Parse(time.RFC3339, ci.MetadataSet["object-lock-retain-until-date"])
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), retainUntil, time.Minute)
+
+	// Lock files can't be made immutable as restic removes them
+	ctx = writeCtx(context.Background(), "repo/locks/2159dd48")
+	assert.Nil(t, fs.GetConfig(ctx).MetadataSet)
+
+	// The mode is checked
+	prevMode := retentionMode
+	retentionMode = "potato"
+	defer func() {
+		retentionMode = prevMode
+	}()
+	opt := DefaultOpt
+	_, err = NewServer(context.Background(), cmd.NewFsSrc([]string{t.TempDir()}), &opt)
+	assert.ErrorContains(t, err, "unknown --retention-mode")
+}
+
+// objectLockFs pretends to be a remote with an object-lock command
+type objectLockFs struct {
+	fs.Fs
+	status string
+}
+
+func (f *objectLockFs) Features() *fs.Features {
+	features := *f.Fs.Features()
+	features.Command = func(ctx context.Context, name string, arg []string, opt map[string]string) (interface{}, error) {
+		if name != "object-lock" {
+			return nil, fs.ErrorCommandNotFound
+		}
+		return f.status, nil
+	}
+	return &features
+}
+
+func TestResticRetentionCheck(t *testing.T) {
+	prev := retention
+	retention = fs.Duration(time.Hour)
+	defer func() {
+		retention = prev
+	}()
+	opt := DefaultOpt
+	opt.HTTP.ListenAddr = []string{testBindAddress}
+	f := cmd.NewFsSrc([]string{t.TempDir()})
+
+	// Remotes which can't lock objects are refused
+	_, err := NewServer(context.Background(), f, &opt)
+	assert.ErrorContains(t, err, "--retention isn't supported")
+
+	// As are buckets without Object Lock
+	_, err = NewServer(context.Background(), &objectLockFs{Fs: f, status: "Disabled"}, &opt)
+	assert.ErrorContains(t, err, "needs Object Lock enabled")
+
+	srv, err := NewServer(context.Background(), &objectLockFs{Fs: f, status: "Enabled"}, &opt)
+	require.NoError(t, err)
+	require.NoError(t, srv.Shutdown())
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path"
@@ -13,33 +15,55 @@ import (
 	"time"
 
 	"github.com/rclone/rclone/cmd"
-	"github.com/rclone/rclone/cmd/serve/httplib"
-	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/walk"
+	httplib "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/lib/http/auth"
 	"github.com/rclone/rclone/lib/http/serve"
 	"github.com/rclone/rclone/lib/terminal"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/http2"
 )
 
+// Options required for restic server
+type Options struct {
+	Auth auth.Options
+	HTTP httplib.Options
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	Auth: auth.DefaultOpt,
+	HTTP: httplib.DefaultOpt,
+}
+
+// Opt is options set by command line flags
+var Opt = DefaultOpt
+
 var (
-	stdio        bool
-	appendOnly   bool
-	privateRepos bool
-	cacheObjects bool
+	stdio         bool
+	appendOnly    bool
+	privateRepos  bool
+	cacheObjects  bool
+	maxRepoSize   fs.SizeSuffix = -1
+	retention     fs.Duration
+	retentionMode = "GOVERNANCE"
 )
 
 func init() {
-	httpflags.AddFlags(Command.Flags())
 	flagSet := Command.Flags()
+	httplib.AddFlagsPrefix(flagSet, "", &Opt.HTTP)
+	auth.AddFlagsPrefix(flagSet, "", &Opt.Auth)
 	flags.BoolVarP(flagSet, &stdio, "stdio", "", false, "Run an HTTP2 server on stdin/stdout")
 	flags.BoolVarP(flagSet, &appendOnly, "append-only", "", false, "Disallow deletion of repository data")
 	flags.BoolVarP(flagSet, &privateRepos, "private-repos", "", false, "Users can only access their private repo")
 	flags.BoolVarP(flagSet, &cacheObjects, "cache-objects", "", true, "Cache listed objects")
+	flags.FVarP(flagSet, &maxRepoSize, "max-repo-size", "", "Maximum size of each repository")
+	flags.FVarP(flagSet, &retention, "retention", "", "Make repository files immutable for this long where the remote supports it")
+	flags.StringVarP(flagSet, &retentionMode, "retention-mode", "", retentionMode, "Object lock mode to use with --retention: GOVERNANCE or COMPLIANCE")
 }
 
 // Command definition for cobra
@@ -127,12 +151,43 @@ these **must** end with /.  Eg
 
 The` + "`--private-repos`" + ` flag can be used to limit users to repositories starting
 with a path of ` + "`/<username>/`" + `.
-` + httplib.Help,
+
+#### Repository size limit ####
+
+Use ` + "`--max-repo-size`" + ` to limit how big each repository can get,
+e.g. ` + "`--max-repo-size 100G`" + `. Uploads which would take a
+repository over the limit are refused with "507 Insufficient Storage".
+The size of a repository is read from the remote the first time it is
+written to, then kept up to date as files are added and removed.
+
+#### Immutable repositories ####
+
+To protect backups from being deleted or overwritten, even by someone
+who has the credentials for the remote, use ` + "`--retention`" + ` to ask
+the remote to keep each file written for that long, e.g.
+` + "`--retention 90d`" + `. This is done with the
+` + "`object-lock-mode`" + ` and ` + "`object-lock-retain-until-date`" + `
+metadata so needs a remote which supports them - at the moment that is
+s3 with a bucket which has Object Lock enabled. The server checks this
+when it starts and refuses to run on any other remote.
+
+` + "`--retention-mode`" + ` sets the lock mode to use. With GOVERNANCE
+(the default) users with special permissions can still remove the
+files. With COMPLIANCE nobody can until the retention period is over.
+
+Lock files aren't made immutable as restic needs to remove them. Other
+files, including those which ` + "`restic prune`" + ` would remove, can't be
+deleted until their retention has expired, so use this with
+` + "`--append-only`" + ` and only prune from a server which can delete.
+` + httplib.Help + auth.Help,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		f := cmd.NewFsSrc(args)
 		cmd.Run(false, true, command, func() error {
-			s := NewServer(f, &httpflags.Opt)
+			s, err := NewServer(context.Background(), f, &Opt)
+			if err != nil {
+				return err
+			}
 			if stdio {
 				if terminal.IsTerminal(int(os.Stdout.Fd())) {
 					return errors.New("refusing to run HTTP2 server directly on a terminal, please let restic start rclone")
@@ -150,7 +205,7 @@ with a path of ` + "`/<username>/`" + `.
 				httpSrv.ServeConn(conn, opts)
 				return nil
 			}
-			err := s.Serve()
+			err = s.Serve()
 			if err != nil {
 				return err
 			}
@@ -169,29 +224,45 @@ type Server struct {
 	*httplib.Server
 	f     fs.Fs
 	cache *cache
+	quota *quota
 }
 
 // NewServer returns an HTTP server that speaks the rest protocol
-func NewServer(f fs.Fs, opt *httplib.Options) *Server {
-	mux := http.NewServeMux()
+func NewServer(ctx context.Context, f fs.Fs, opt *Options) (*Server, error) {
+	if retention > 0 && retentionMode != "GOVERNANCE" && retentionMode != "COMPLIANCE" {
+		return nil, fmt.Errorf("unknown --retention-mode %q - expecting GOVERNANCE or COMPLIANCE", retentionMode)
+	}
+	if retention > 0 {
+		err := checkRetention(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+	}
 	s := &Server{
-		Server: httplib.NewServer(mux, opt),
-		f:      f,
-		cache:  newCache(),
+		f:     f,
+		cache: newCache(),
+		quota: newQuota(f, maxRepoSize),
+	}
+	var err error
+	s.Server, err = httplib.NewServer(ctx, opt.HTTP, opt.Auth)
+	if err != nil {
+		return nil, err
 	}
-	mux.HandleFunc(s.Opt.BaseURL+"/", s.ServeHTTP)
-	return s
+	s.Router().Handle("/*", s)
+	return s, nil
 }
 
 // Serve runs the http server in the background.
 //
-// Use s.Close() and s.Wait() to shutdown server
+// Use s.Shutdown() to stop the server
 func (s *Server) Serve() error {
 	err := s.Server.Serve()
 	if err != nil {
 		return err
 	}
-	fs.Logf(s.f, "Serving restic REST API on %s", s.URL())
+	for _, url := range s.URLs() {
+		fs.Logf(s.f, "Serving restic REST API on %s", url)
+	}
 	return nil
 }
 
@@ -219,14 +290,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Accept-Ranges", "bytes")
 	w.Header().Set("Server", "rclone/"+fs.Version)
 
-	path, ok := s.Path(w, r)
-	if !ok {
-		return
-	}
+	path := r.URL.Path
 	remote := makeRemote(path)
 	fs.Debugf(s.f, "%s %s", r.Method, path)
 
-	v := r.Context().Value(httplib.ContextUserKey)
+	v := r.Context().Value(auth.ContextUserKey)
 	if privateRepos && (v == nil || !strings.HasPrefix(path, "/"+v.(string)+"/")) {
 		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
@@ -282,27 +350,106 @@ func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, remote stri
 	serve.Object(w, r, o)
 }
 
+// checkRetention checks that f can make the files written immutable
+//
+// Remotes which don't understand the object-lock metadata would
+// otherwise store the files without protecting them.
+func checkRetention(ctx context.Context, f fs.Fs) error {
+	do := f.Features().Command
+	if do == nil {
+		return fmt.Errorf("--retention isn't supported by %v - it needs an s3 bucket with Object Lock enabled", f)
+	}
+	status, err := do(ctx, "object-lock", nil, nil)
+	if err == fs.ErrorCommandNotFound {
+		return fmt.Errorf("--retention isn't supported by %v - it needs an s3 bucket with Object Lock enabled", f)
+	} else if err != nil {
+		return fmt.Errorf("--retention: failed to read Object Lock status: %w", err)
+	}
+	if status != "Enabled" {
+		return fmt.Errorf("--retention needs Object Lock enabled on the bucket of %v", f)
+	}
+	return nil
+}
+
+// isLock returns whether remote is a lock file
+func isLock(remote string) bool {
+	return path.Base(path.Dir(remote)) == "locks"
+}
+
+// writeCtx returns the context to upload remote with.
+//
+// If --retention is set this asks the remote to make the object
+// immutable with metadata.
+func writeCtx(ctx context.Context, remote string) context.Context {
+	if retention <= 0 || isLock(remote) {
+		return ctx
+	}
+	ctx, ci := fs.AddConfig(ctx)
+	metadata := fs.Metadata{}
+	metadata.Merge(ci.MetadataSet)
+	metadata["object-lock-mode"] = retentionMode
+	metadata["object-lock-retain-until-date"] = time.Now().Add(time.Duration(retention)).UTC().Format(time.RFC3339)
+	ci.Metadata = true
+	ci.MetadataSet = metadata
+	return ctx
+}
+
+// postError writes the error from posting remote to the client
+func postError(w http.ResponseWriter, remote string, err error) {
+	if errors.Is(err, errQuotaExceeded) {
+		fs.Errorf(remote, "Post request: %v", err)
+		http.Error(w, http.StatusText(http.StatusInsufficientStorage), http.StatusInsufficientStorage)
+		return
+	}
+	fs.Errorf(remote, "Post request rcat error: %v", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
 // postObject posts an object to the repository
 func (s *Server) postObject(w http.ResponseWriter, r *http.Request, remote string) {
-	if appendOnly {
-		// make sure the file does not exist yet
-		_, err := s.newObject(r.Context(), remote)
+	var oldSize int64
+	if appendOnly || s.quota != nil {
+		o, err := s.newObject(r.Context(), remote)
 		if err == nil {
-			fs.Errorf(remote, "Post request: file already exists, refusing to overwrite in append-only mode")
-			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			// make sure the file does not exist yet
+			if appendOnly {
+				fs.Errorf(remote, "Post request: file already exists, refusing to overwrite in append-only mode")
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 
+				return
+			}
+			oldSize = o.Size()
+		}
+	}
+
+	// Reserve the space for the upload, as it is read if the size
+	// isn't known
+	repo := repoRoot(remote)
+	var in io.Reader = r.Body
+	reserved := r.ContentLength
+	qr := &quotaReader{ctx: r.Context(), q: s.quota, repo: repo, in: r.Body}
+	if r.ContentLength >= 0 {
+		err := s.quota.reserve(r.Context(), repo, r.ContentLength)
+		if err != nil {
+			postError(w, remote, err)
 			return
 		}
+	} else if s.quota != nil {
+		in = qr
 	}
 
-	o, err := operations.RcatSize(r.Context(), s.f, remote, r.Body, r.ContentLength, time.Now())
+	o, err := operations.RcatSize(writeCtx(r.Context(), remote), s.f, remote, io.NopCloser(in), r.ContentLength, time.Now())
+	if reserved < 0 {
+		reserved = qr.reserved
+	}
 	if err != nil {
+		s.quota.release(repo, reserved)
 		err = accounting.Stats(r.Context()).Error(err)
-		fs.Errorf(remote, "Post request rcat error: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		postError(w, remote, err)
 
 		return
 	}
+	s.quota.release(repo, oldSize)
 
 	// if successfully uploaded add to cache
 	s.cache.add(remote, o)
@@ -336,6 +483,7 @@ func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, remote str
 		}
 		return
 	}
+	s.quota.release(repoRoot(remote), o.Size())
 
 	// remove object from cache
 	s.cache.remove(remote)
@@ -8,17 +8,15 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/rclone/rclone/cmd/serve/httplib"
-
 	"github.com/rclone/rclone/cmd"
-	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
+	"github.com/rclone/rclone/lib/http/auth"
 	"github.com/stretchr/testify/require"
 )
 
 // newAuthenticatedRequest returns a new HTTP request with the given params.
 func newAuthenticatedRequest(t testing.TB, method, path string, body io.Reader) *http.Request {
 	req := newRequest(t, method, path, body)
-	req = req.WithContext(context.WithValue(req.Context(), httplib.ContextUserKey, "test"))
+	req = req.WithContext(context.WithValue(req.Context(), auth.ContextUserKey, "test"))
 	req.Header.Add("Accept", resticAPIV2)
 	return req
 }
@@ -34,21 +32,19 @@ func TestResticPrivateRepositories(t *testing.T) {
 
 	// globally set private-repos mode & test user
 	prev := privateRepos
-	prevUser := httpflags.Opt.BasicUser
-	prevPassword := httpflags.Opt.BasicPass
 	privateRepos = true
-	httpflags.Opt.BasicUser = "test"
-	httpflags.Opt.BasicPass = "password"
+	opt := DefaultOpt
+	opt.Auth.BasicUser = "test"
+	opt.Auth.BasicPass = "password"
 	// reset when done
 	defer func() {
 		privateRepos = prev
-		httpflags.Opt.BasicUser = prevUser
-		httpflags.Opt.BasicPass = prevPassword
 	}()
 
 	// make a new file system in the temp dir
 	f := cmd.NewFsSrc([]string{tempdir})
-	srv := NewServer(f, &httpflags.Opt)
+	srv, err := NewServer(context.Background(), f, &opt)
+	require.NoError(t, err)
 
 	// Requesting /test/ should allow access
 	reqs := []*http.Request{
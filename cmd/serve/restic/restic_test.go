@@ -10,9 +10,9 @@ import (
 	"testing"
 
 	_ "github.com/rclone/rclone/backend/all"
-	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/fstest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -28,8 +28,8 @@ func TestRestic(t *testing.T) {
 		t.Skipf("Skipping test as restic source not found: %v", err)
 	}
 
-	opt := httplib.DefaultOpt
-	opt.ListenAddr = testBindAddress
+	opt := DefaultOpt
+	opt.HTTP.ListenAddr = []string{testBindAddress}
 
 	fstest.Initialise()
 
@@ -41,11 +41,11 @@ func TestRestic(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Start the server
-	w := NewServer(fremote, &opt)
+	w, err := NewServer(context.Background(), fremote, &opt)
+	require.NoError(t, err)
 	assert.NoError(t, w.Serve())
 	defer func() {
-		w.Close()
-		w.Wait()
+		assert.NoError(t, w.Shutdown())
 	}()
 
 	// Change directory to run the tests
@@ -0,0 +1,491 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constants for AWS signature version 4
+const (
+	signV4Algorithm    = "AWS4-HMAC-SHA256"
+	signV4ChunkPrefix  = "AWS4-HMAC-SHA256-PAYLOAD"
+	scopeTerminator    = "aws4_request"
+	unsignedPayload    = "UNSIGNED-PAYLOAD"
+	streamingPayload   = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	amzDateFormat      = "20060102T150405Z"
+	maxClockSkew       = 15 * time.Minute
+	maxPresignedExpiry = 7 * 24 * time.Hour
+	emptySHA256        = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signature is the parsed AWS v4 signature of a request
+type signature struct {
+	accessKey     string        // access key ID of the signer
+	date          string        // date of the credential scope YYYYMMDD
+	region        string        // region of the credential scope
+	service       string        // service of the credential scope
+	signedHeaders []string      // lower case names of the signed headers
+	signature     string        // the signature itself in hex
+	amzDate       time.Time     // time the request was signed
+	presigned     bool          // set if the signature is in the query
+	expires       time.Duration // expiry of presigned requests
+}
+
+// scope returns the credential scope of the signature
+func (sig *signature) scope() string {
+	return strings.Join([]string{sig.date, sig.region, sig.service, scopeTerminator}, "/")
+}
+
+// parseCredential parses a credential of the form
+// AKID/YYYYMMDD/region/service/aws4_request
+func (sig *signature) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) < 5 || parts[len(parts)-1] != scopeTerminator {
+		return errInvalidArgument
+	}
+	n := len(parts)
+	sig.accessKey = strings.Join(parts[:n-4], "/")
+	sig.date, sig.region, sig.service = parts[n-4], parts[n-3], parts[n-2]
+	return nil
+}
+
+// parseSignature reads the AWS v4 signature from the Authorization
+// header or the query parameters of the request.
+//
+// It returns a nil signature if the request isn't signed.
+func parseSignature(r *http.Request) (sig *signature, err error) {
+	authHeader := r.Header.Get("Authorization")
+	query := r.URL.Query()
+	switch {
+	case strings.HasPrefix(authHeader, signV4Algorithm+" "):
+		return parseHeaderSignature(r, authHeader[len(signV4Algorithm)+1:])
+	case query.Get("X-Amz-Algorithm") == signV4Algorithm:
+		return parsePresignedSignature(query)
+	case authHeader != "", query.Get("AWSAccessKeyId") != "", query.Get("X-Amz-Algorithm") != "":
+		return nil, errUnsupportedSignature
+	}
+	return nil, nil
+}
+
+// parseHeaderSignature parses the fields of the Authorization header
+//
+//	Credential=AKID/20130524/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=abcd...
+func parseHeaderSignature(r *http.Request, fields string) (sig *signature, err error) {
+	sig = new(signature)
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		equals := strings.IndexByte(field, '=')
+		if equals < 0 {
+			return nil, errInvalidArgument
+		}
+		key, value := field[:equals], field[equals+1:]
+		switch key {
+		case "Credential":
+			err = sig.parseCredential(value)
+			if err != nil {
+				return nil, err
+			}
+		case "SignedHeaders":
+			sig.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			sig.signature = value
+		}
+	}
+	if sig.accessKey == "" || len(sig.signedHeaders) == 0 || sig.signature == "" {
+		return nil, errInvalidArgument
+	}
+	if amzDate := r.Header.Get("X-Amz-Date"); amzDate != "" {
+		sig.amzDate, err = time.Parse(amzDateFormat, amzDate)
+	} else if date := r.Header.Get("Date"); date != "" {
+		sig.amzDate, err = http.ParseTime(date)
+	} else {
+		err = errors.New("no date")
+	}
+	if err != nil {
+		return nil, errInvalidArgument
+	}
+	return sig, nil
+}
+
+// parsePresignedSignature parses the signature from the query
+// parameters of a presigned request
+func parsePresignedSignature(query url.Values) (sig *signature, err error) {
+	sig = &signature{
+		presigned:     true,
+		signedHeaders: strings.Split(query.Get("X-Amz-SignedHeaders"), ";"),
+		signature:     query.Get("X-Amz-Signature"),
+	}
+	err = sig.parseCredential(query.Get("X-Amz-Credential"))
+	if err != nil {
+		return nil, err
+	}
+	sig.amzDate, err = time.Parse(amzDateFormat, query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, errInvalidArgument
+	}
+	expires, err := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires < 0 {
+		return nil, errInvalidArgument
+	}
+	sig.expires = time.Duration(expires) * time.Second
+	if sig.expires > maxPresignedExpiry || sig.signature == "" {
+		return nil, errInvalidArgument
+	}
+	return sig, nil
+}
+
+// checkTime checks the time of the signature is acceptable
+func (sig *signature) checkTime(now time.Time) error {
+	if sig.amzDate.Sub(now) > maxClockSkew {
+		return errRequestTimeTooSkewed
+	}
+	if sig.presigned {
+		if now.After(sig.amzDate.Add(sig.expires)) {
+			return errExpiredRequest
+		}
+	} else if now.Sub(sig.amzDate) > maxClockSkew {
+		return errRequestTimeTooSkewed
+	}
+	return nil
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// sha256Hex returns the hex encoded SHA256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// signingKey derives the signing key for the signature from the secret
+func (sig *signature) signingKey(secret string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), sig.date)
+	key = hmacSHA256(key, sig.region)
+	key = hmacSHA256(key, sig.service)
+	return hmacSHA256(key, scopeTerminator)
+}
+
+// uriEncode encodes s as described in the AWS v4 signature docs.
+//
+// Everything except the unreserved characters is percent encoded, and
+// the "/" character is left alone unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			out.WriteByte(c)
+		default:
+			out.WriteByte('%')
+			out.WriteByte(hexDigits[c>>4])
+			out.WriteByte(hexDigits[c&15])
+		}
+	}
+	return out.String()
+}
+
+// canonicalURI returns the canonical URI of the request.
+//
+// This uses the RequestURI as the URL may have had a --baseurl
+// stripped from it.
+func canonicalURI(r *http.Request) string {
+	rawPath := r.RequestURI
+	if u, err := url.ParseRequestURI(rawPath); err == nil {
+		rawPath = u.EscapedPath()
+	} else {
+		rawPath = r.URL.EscapedPath()
+	}
+	decodedPath, err := url.PathUnescape(rawPath)
+	if err != nil {
+		decodedPath = rawPath
+	}
+	if decodedPath == "" {
+		decodedPath = "/"
+	}
+	return uriEncode(decodedPath, false)
+}
+
+// canonicalQuery returns the canonical query string of the request
+//
+// The parameters are sorted by encoded key then encoded value.
+func (sig *signature) canonicalQuery(r *http.Request) string {
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if sig.presigned && key == "X-Amz-Signature" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return uriEncode(keys[i], true) < uriEncode(keys[j], true)
+	})
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		encodedKey := uriEncode(key, true)
+		values := make([]string, len(query[key]))
+		for i, value := range query[key] {
+			values[i] = uriEncode(value, true)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, encodedKey+"="+value)
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaderValue returns the canonical value of the header
+// called name
+func canonicalHeaderValue(r *http.Request, name string) string {
+	var values []string
+	switch name {
+	case "host":
+		values = []string{r.Host}
+	case "content-length":
+		values = r.Header.Values(name)
+		if len(values) == 0 && r.ContentLength >= 0 {
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		}
+	case "transfer-encoding":
+		values = r.TransferEncoding
+	default:
+		values = r.Header.Values(name)
+	}
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.Join(strings.Fields(value), " ")
+	}
+	return strings.Join(trimmed, ",")
+}
+
+// payloadHash returns the hash of the payload the client signed
+func (sig *signature) payloadHash(r *http.Request) string {
+	if sig.presigned {
+		if hash := r.URL.Query().Get("X-Amz-Content-Sha256"); hash != "" {
+			return hash
+		}
+		return unsignedPayload
+	}
+	if hash := r.Header.Get("X-Amz-Content-Sha256"); hash != "" {
+		return hash
+	}
+	return emptySHA256
+}
+
+// stringToSign makes the string to sign for the request
+func (sig *signature) stringToSign(r *http.Request) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range sig.signedHeaders {
+		canonicalHeaders.WriteString(name)
+		canonicalHeaders.WriteByte(':')
+		canonicalHeaders.WriteString(canonicalHeaderValue(r, name))
+		canonicalHeaders.WriteByte('\n')
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI(r),
+		sig.canonicalQuery(r),
+		canonicalHeaders.String(),
+		strings.Join(sig.signedHeaders, ";"),
+		sig.payloadHash(r),
+	}, "\n")
+	return strings.Join([]string{
+		signV4Algorithm,
+		sig.amzDate.UTC().Format(amzDateFormat),
+		sig.scope(),
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+}
+
+// verify checks the signature of the request using secret.
+//
+// If the request has a body which is hashed or signed then the body
+// is replaced with one that checks it as it is read.
+func (sig *signature) verify(r *http.Request, secret string) error {
+	err := sig.checkTime(time.Now())
+	if err != nil {
+		return err
+	}
+	key := sig.signingKey(secret)
+	calculated := hex.EncodeToString(hmacSHA256(key, sig.stringToSign(r)))
+	if !hmac.Equal([]byte(calculated), []byte(sig.signature)) {
+		return errSignatureDoesNotMatch
+	}
+	return sig.wrapBody(r, key)
+}
+
+// wrapBody replaces the body of the request with one which checks
+// the hash of the payload or decodes the aws-chunked encoding.
+//
+// If key is nil then chunk signatures won't be checked.
+func (sig *signature) wrapBody(r *http.Request, key []byte) error {
+	if sig.presigned || r.Body == nil {
+		return nil
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	switch {
+	case payloadHash == streamingPayload:
+		decodedLength, err := strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil {
+			return errInvalidArgument
+		}
+		r.Body = &chunkedReader{
+			in:      bufio.NewReader(r.Body),
+			closer:  r.Body,
+			sig:     sig,
+			key:     key,
+			prevSig: sig.signature,
+		}
+		r.ContentLength = decodedLength
+	case len(payloadHash) == sha256.Size*2 && key != nil:
+		want, err := hex.DecodeString(payloadHash)
+		if err != nil {
+			return errInvalidArgument
+		}
+		r.Body = &hashCheckReader{
+			in:     r.Body,
+			hasher: sha256.New(),
+			want:   want,
+		}
+	}
+	return nil
+}
+
+// errContentSHA256Mismatch is returned when the body didn't match the
+// hash the client signed
+var errContentSHA256Mismatch = &apiError{http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed."}
+
+// hashCheckReader checks the SHA256 of what is read matches want
+type hashCheckReader struct {
+	in     io.ReadCloser
+	hasher hash.Hash
+	want   []byte
+}
+
+// Read bytes checking the hash at EOF
+func (h *hashCheckReader) Read(p []byte) (n int, err error) {
+	n, err = h.in.Read(p)
+	_, _ = h.hasher.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(h.hasher.Sum(nil), h.want) {
+		err = errContentSHA256Mismatch
+	}
+	return n, err
+}
+
+// Close the underlying reader
+func (h *hashCheckReader) Close() error {
+	return h.in.Close()
+}
+
+// chunkedReader decodes a body in aws-chunked encoding checking the
+// signatures of each chunk.
+//
+// Each chunk looks like
+//
+//	hex(size);chunk-signature=signature\r\n
+//	data\r\n
+//
+// and the body is terminated with a zero sized chunk.
+type chunkedReader struct {
+	in      *bufio.Reader
+	closer  io.Closer
+	sig     *signature
+	key     []byte // signing key - if nil don't check signatures
+	prevSig string // signature of the previous chunk
+	chunk   []byte // remaining data of the current chunk
+	done    bool   // set when the final chunk has been read
+	err     error  // sticky error
+}
+
+// readChunk reads the next chunk into c.chunk
+func (c *chunkedReader) readChunk() error {
+	line, err := c.in.ReadString('\n')
+	if err != nil {
+		return errIncompleteBody
+	}
+	line = strings.TrimRight(line, "\r\n")
+	semicolon := strings.IndexByte(line, ';')
+	if semicolon < 0 {
+		return errMalformedChunk
+	}
+	size, err := strconv.ParseInt(line[:semicolon], 16, 64)
+	if err != nil || size < 0 || size > 64*1024*1024 {
+		return errMalformedChunk
+	}
+	chunkSignature := strings.TrimPrefix(line[semicolon+1:], "chunk-signature=")
+	data := make([]byte, size)
+	_, err = io.ReadFull(c.in, data)
+	if err != nil {
+		return errIncompleteBody
+	}
+	crlf := make([]byte, 2)
+	_, err = io.ReadFull(c.in, crlf)
+	if err != nil || string(crlf) != "\r\n" {
+		return errMalformedChunk
+	}
+	if c.key != nil {
+		stringToSign := strings.Join([]string{
+			signV4ChunkPrefix,
+			c.sig.amzDate.UTC().Format(amzDateFormat),
+			c.sig.scope(),
+			c.prevSig,
+			emptySHA256,
+			sha256Hex(data),
+		}, "\n")
+		calculated := hex.EncodeToString(hmacSHA256(c.key, stringToSign))
+		if !hmac.Equal([]byte(calculated), []byte(chunkSignature)) {
+			return errSignatureDoesNotMatch
+		}
+	}
+	c.prevSig = chunkSignature
+	c.chunk = data
+	if size == 0 {
+		c.done = true
+	}
+	return nil
+}
+
+// Read decoded bytes from the body
+func (c *chunkedReader) Read(p []byte) (n int, err error) {
+	for len(c.chunk) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		if c.done {
+			return 0, io.EOF
+		}
+		c.err = c.readChunk()
+	}
+	n = copy(p, c.chunk)
+	c.chunk = c.chunk[n:]
+	return n, nil
+}
+
+// Close the underlying body
+func (c *chunkedReader) Close() error {
+	return c.closer.Close()
+}
+
+// errMalformedChunk is returned if the aws-chunked encoding is bad
+var errMalformedChunk = &apiError{http.StatusBadRequest, "IncompleteBody", "The request body is not valid aws-chunked encoding."}
@@ -0,0 +1,362 @@
+package s3
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// maxListKeys is the maximum number of keys returned in one listing
+const maxListKeys = 1000
+
+// listBuckets lists the top level directories as buckets
+func (s *server) listBuckets(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS) error {
+	root, err := VFS.Root()
+	if err != nil {
+		return err
+	}
+	nodes, err := root.ReadDirAll()
+	if err != nil {
+		return err
+	}
+	result := listAllMyBucketsResult{
+		Owner:   defaultOwner,
+		Buckets: []bucketInfo{},
+	}
+	for _, node := range nodes {
+		if node.IsDir() {
+			result.Buckets = append(result.Buckets, bucketInfo{
+				Name:         node.Name(),
+				CreationDate: formatTime(node.ModTime()),
+			})
+		}
+	}
+	writeXML(w, r, result)
+	return nil
+}
+
+// createBucket makes a directory for the bucket
+func (s *server) createBucket(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string) error {
+	// Drain the CreateBucketConfiguration - we don't use it
+	_, _ = io.Copy(io.Discard, r.Body)
+	_, err := bucketDir(VFS, bucket)
+	if err == nil {
+		return errBucketAlreadyOwned
+	} else if err != errNoSuchBucket {
+		return err
+	}
+	err = VFS.Mkdir(bucket, 0777)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/"+bucket)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// deleteBucket removes the directory for the bucket if it is empty
+func (s *server) deleteBucket(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string) error {
+	dir, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	err = dir.Remove()
+	if errors.Is(err, vfs.ENOTEMPTY) {
+		return errBucketNotEmpty
+	} else if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// getBucketLocation returns the region of the bucket
+func (s *server) getBucketLocation(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string) error {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	location := s.opt.Region
+	if location == "us-east-1" {
+		// us-east-1 is represented by an empty location
+		location = ""
+	}
+	writeXML(w, r, locationConstraint{Location: location})
+	return nil
+}
+
+// getBucketVersioning returns an empty versioning configuration as
+// versioning is never enabled
+func (s *server) getBucketVersioning(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string) error {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	writeXML(w, r, versioningConfiguration{})
+	return nil
+}
+
+// errSkipDir is returned from a walkFn to skip the contents of a
+// directory and errStopWalk to stop the walk altogether
+var (
+	errSkipDir  = errors.New("skip directory")
+	errStopWalk = errors.New("stop walk")
+)
+
+// walkFn is called for each file and directory found by walk.
+//
+// The key of a directory ends in "/".
+type walkFn func(key string, node vfs.Node) error
+
+// walk calls fn for each file and directory in dir, whose key is
+// dirKey, and its subdirectories in key order.
+//
+// Only items whose key starts with prefix and directories which
+// could contain such items are visited. Subtrees whose keys all sort
+// before after are skipped.
+func walk(dir *vfs.Dir, dirKey, prefix, after string, fn walkFn) error {
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return err
+	}
+	type item struct {
+		key  string
+		node vfs.Node
+	}
+	items := make([]item, 0, len(nodes))
+	for _, node := range nodes {
+		key := dirKey + node.Name()
+		if node.IsDir() {
+			key += "/"
+		}
+		if !strings.HasPrefix(key, prefix) && !(node.IsDir() && strings.HasPrefix(prefix, key)) {
+			continue
+		}
+		if key < after && !strings.HasPrefix(after, key) {
+			continue
+		}
+		items = append(items, item{key: key, node: node})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].key < items[j].key })
+	for _, item := range items {
+		if strings.HasPrefix(item.key, prefix) {
+			err = fn(item.key, item.node)
+			if err == errSkipDir {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+		if subDir, ok := item.node.(*vfs.Dir); ok {
+			err = walk(subDir, item.key, prefix, after, fn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// listing is the result of listBucket
+type listing struct {
+	objects     []objectInfo
+	prefixes    []string
+	isTruncated bool
+	nextMarker  string // last key or prefix returned
+}
+
+// listBucket lists up to maxKeys objects and common prefixes in the
+// bucket in key order after the marker.
+func (s *server) listBucket(r *http.Request, VFS *vfs.VFS, bucket, prefix, delimiter, marker string, maxKeys int) (out listing, err error) {
+	root, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return out, err
+	}
+	// Start from the deepest directory in the prefix
+	dirKey := prefix[:strings.LastIndexByte(prefix, '/')+1]
+	startDir := root
+	if dirKey != "" {
+		node, err := VFS.Stat(path.Join(bucket, dirKey))
+		if err != nil || !node.IsDir() {
+			// nothing to list
+			return out, nil
+		}
+		startDir = node.(*vfs.Dir)
+	}
+	count := 0
+	lastPrefix := ""
+	err = walk(startDir, dirKey, prefix, marker, func(key string, node vfs.Node) error {
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				commonPrefix := key[:len(prefix)+i+len(delimiter)]
+				// everything in a directory has the same common prefix
+				result := error(nil)
+				if node.IsDir() {
+					result = errSkipDir
+				}
+				if commonPrefix == lastPrefix || commonPrefix <= marker {
+					return result
+				}
+				if count >= maxKeys {
+					out.isTruncated = true
+					return errStopWalk
+				}
+				out.prefixes = append(out.prefixes, commonPrefix)
+				out.nextMarker = commonPrefix
+				lastPrefix = commonPrefix
+				count++
+				return result
+			}
+		}
+		file, ok := node.(*vfs.File)
+		if !ok || key <= marker {
+			return nil
+		}
+		if count >= maxKeys {
+			out.isTruncated = true
+			return errStopWalk
+		}
+		out.objects = append(out.objects, objectInfo{
+			Key:          key,
+			LastModified: formatTime(file.ModTime()),
+			ETag:         s.etag(r.Context(), VFS, file),
+			Size:         file.Size(),
+			StorageClass: "STANDARD",
+		})
+		out.nextMarker = key
+		count++
+		return nil
+	})
+	if err == errStopWalk {
+		err = nil
+	}
+	return out, err
+}
+
+// listObjects implements ListObjects and ListObjectsV2
+func (s *server) listObjects(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string) error {
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	encodingType := query.Get("encoding-type")
+	if encodingType != "" && encodingType != "url" {
+		return errInvalidArgument
+	}
+	maxKeys := maxListKeys
+	if value := query.Get("max-keys"); value != "" {
+		var err error
+		maxKeys, err = strconv.Atoi(value)
+		if err != nil || maxKeys < 0 {
+			return errInvalidArgument
+		}
+		if maxKeys > maxListKeys {
+			maxKeys = maxListKeys
+		}
+	}
+	v2 := query.Get("list-type") == "2"
+	marker := query.Get("marker")
+	if v2 {
+		marker = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, err := base64.StdEncoding.DecodeString(token)
+			if err != nil {
+				return errInvalidArgument
+			}
+			marker = string(decoded)
+		}
+	}
+
+	list, err := s.listBucket(r, VFS, bucket, prefix, delimiter, marker, maxKeys)
+	if err != nil {
+		return err
+	}
+
+	// encode returns s URL encoded if required
+	encode := func(s string) string {
+		if encodingType == "url" {
+			return uriEncode(s, false)
+		}
+		return s
+	}
+	result := listBucketResult{
+		Name:         bucket,
+		Prefix:       encode(prefix),
+		Delimiter:    encode(delimiter),
+		EncodingType: encodingType,
+		MaxKeys:      maxKeys,
+		IsTruncated:  list.isTruncated,
+		Contents:     list.objects,
+	}
+	for i := range result.Contents {
+		result.Contents[i].Key = encode(result.Contents[i].Key)
+	}
+	for _, p := range list.prefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: encode(p)})
+	}
+	if v2 {
+		keyCount := len(list.objects) + len(list.prefixes)
+		result.KeyCount = &keyCount
+		if token := query.Get("continuation-token"); token != "" {
+			result.ContinuationToken = &token
+		}
+		if startAfter := query.Get("start-after"); startAfter != "" {
+			startAfter = encode(startAfter)
+			result.StartAfter = &startAfter
+		}
+		if list.isTruncated {
+			token := base64.StdEncoding.EncodeToString([]byte(list.nextMarker))
+			result.NextContinuationToken = &token
+		}
+	} else {
+		encodedMarker := encode(marker)
+		result.Marker = &encodedMarker
+		if list.isTruncated {
+			nextMarker := encode(list.nextMarker)
+			result.NextMarker = &nextMarker
+		}
+	}
+	writeXML(w, r, result)
+	return nil
+}
+
+// deleteObjects implements DeleteObjects
+func (s *server) deleteObjects(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string) error {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	var req deleteRequest
+	err = xml.NewDecoder(io.LimitReader(r.Body, 2*1024*1024)).Decode(&req)
+	if err != nil || len(req.Objects) > maxListKeys {
+		return errMalformedXML
+	}
+	result := deleteResult{}
+	for _, object := range req.Objects {
+		err := checkKey(object.Key)
+		if err == nil {
+			err = s.removeObject(VFS, bucket, object.Key)
+		}
+		if err != nil {
+			apiErr := translateError(err)
+			fs.Debugf(path.Join(bucket, object.Key), "Failed to delete: %v", err)
+			result.Errors = append(result.Errors, deleteError{
+				Key:     object.Key,
+				Code:    apiErr.code,
+				Message: apiErr.message,
+			})
+		} else if !req.Quiet {
+			result.Deleted = append(result.Deleted, object)
+		}
+	}
+	writeXML(w, r, result)
+	return nil
+}
@@ -0,0 +1,98 @@
+package s3
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// apiError is an error returned to the client in S3 format
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+// Error satisfies the error interface
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+// S3 errors returned by the server
+var (
+	errAccessDenied          = &apiError{http.StatusForbidden, "AccessDenied", "Access Denied"}
+	errBadDigest             = &apiError{http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what we received."}
+	errBucketAlreadyOwned    = &apiError{http.StatusConflict, "BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it."}
+	errBucketNotEmpty        = &apiError{http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty."}
+	errEntityTooSmall        = &apiError{http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size."}
+	errExpiredRequest        = &apiError{http.StatusForbidden, "AccessDenied", "Request has expired."}
+	errIncompleteBody        = &apiError{http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header."}
+	errInternalError         = &apiError{http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again."}
+	errInvalidAccessKeyID    = &apiError{http.StatusForbidden, "InvalidAccessKeyId", "The AWS access key ID you provided does not exist in our records."}
+	errInvalidArgument       = &apiError{http.StatusBadRequest, "InvalidArgument", "Invalid Argument."}
+	errInvalidBucketName     = &apiError{http.StatusBadRequest, "InvalidBucketName", "The specified bucket is not valid."}
+	errInvalidDigest         = &apiError{http.StatusBadRequest, "InvalidDigest", "The Content-MD5 you specified is not valid."}
+	errInvalidPart           = &apiError{http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found."}
+	errInvalidPartOrder      = &apiError{http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order."}
+	errInvalidRange          = &apiError{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable."}
+	errInvalidRequest        = &apiError{http.StatusBadRequest, "InvalidRequest", "Invalid Request."}
+	errKeyTooLong            = &apiError{http.StatusBadRequest, "KeyTooLongError", "Your key is too long."}
+	errMalformedXML          = &apiError{http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema."}
+	errMethodNotAllowed      = &apiError{http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource."}
+	errMissingAuth           = &apiError{http.StatusForbidden, "AccessDenied", "Request is missing an Authorization header."}
+	errNoSuchBucket          = &apiError{http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist."}
+	errNoSuchKey             = &apiError{http.StatusNotFound, "NoSuchKey", "The specified key does not exist."}
+	errNoSuchUpload          = &apiError{http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist."}
+	errNotImplemented        = &apiError{http.StatusNotImplemented, "NotImplemented", "A header or query you provided implies functionality that is not implemented."}
+	errPreconditionFailed    = &apiError{http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the preconditions you specified did not hold."}
+	errRequestTimeTooSkewed  = &apiError{http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large."}
+	errSignatureDoesNotMatch = &apiError{http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."}
+	errUnsupportedSignature  = &apiError{http.StatusBadRequest, "InvalidRequest", "Only AWS signature version 4 is supported."}
+)
+
+// errorResponse is the XML body sent with errors
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource,omitempty"`
+}
+
+// translateError converts err into an apiError
+func translateError(err error) *apiError {
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, vfs.ENOENT):
+		return errNoSuchKey
+	case errors.Is(err, vfs.ENOTEMPTY):
+		return errBucketNotEmpty
+	case errors.Is(err, vfs.EPERM), errors.Is(err, vfs.EROFS):
+		return errAccessDenied
+	}
+	return errInternalError
+}
+
+// writeError writes err to the client as an S3 error response
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := translateError(err)
+	if apiErr == errInternalError {
+		fs.Errorf(r.URL.Path, "%s failed: %v", r.Method, err)
+	} else {
+		fs.Debugf(r.URL.Path, "%s failed: %v", r.Method, err)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(apiErr.status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	writeXMLBody(w, r, errorResponse{
+		Code:     apiErr.code,
+		Message:  apiErr.message,
+		Resource: r.URL.Path,
+	})
+}
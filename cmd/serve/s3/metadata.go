@@ -0,0 +1,156 @@
+package s3
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/vfs"
+)
+
+// metaMtime is the header the modification time is stored in. This
+// is the same header that rclone's s3 backend uses.
+const metaMtime = "X-Amz-Meta-Mtime"
+
+// storedHeaders are the standard headers which are stored as metadata
+var storedHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Type",
+	"Expires",
+}
+
+// metaStore holds the metadata of objects which can't be stored in
+// the VFS.
+//
+// The modification time isn't stored here as that is read from and
+// written to the VFS.
+type metaStore struct {
+	mu   sync.Mutex
+	meta map[*vfs.VFS]map[string]http.Header // metadata by VFS then path
+}
+
+// newMetaStore makes a new empty metaStore
+func newMetaStore() *metaStore {
+	return &metaStore{
+		meta: make(map[*vfs.VFS]map[string]http.Header),
+	}
+}
+
+// get returns the metadata stored for remote or nil if there isn't any
+func (m *metaStore) get(VFS *vfs.VFS, remote string) http.Header {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.meta[VFS][remote].Clone()
+}
+
+// set stores meta as the metadata for remote
+func (m *metaStore) set(VFS *vfs.VFS, remote string, meta http.Header) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(meta) == 0 {
+		delete(m.meta[VFS], remote)
+		return
+	}
+	vfsMeta := m.meta[VFS]
+	if vfsMeta == nil {
+		vfsMeta = make(map[string]http.Header)
+		m.meta[VFS] = vfsMeta
+	}
+	vfsMeta[remote] = meta
+}
+
+// delete removes any metadata stored for remote
+func (m *metaStore) delete(VFS *vfs.VFS, remote string) {
+	m.set(VFS, remote, nil)
+}
+
+// extractMetadata returns the metadata from the headers passed in
+// which should be stored with the object
+func extractMetadata(header http.Header) http.Header {
+	meta := make(http.Header)
+	for _, name := range storedHeaders {
+		value := header.Get(name)
+		if name == "Content-Encoding" {
+			value = removeAWSChunked(value)
+		}
+		if value != "" {
+			meta.Set(name, value)
+		}
+	}
+	for name, values := range header {
+		if strings.HasPrefix(name, "X-Amz-Meta-") && name != metaMtime {
+			meta[name] = values
+		}
+	}
+	return meta
+}
+
+// removeAWSChunked removes the aws-chunked encoding from a
+// Content-Encoding header as it only applies to the transfer
+func removeAWSChunked(contentEncoding string) string {
+	var encodings []string
+	for _, encoding := range strings.Split(contentEncoding, ",") {
+		encoding = strings.TrimSpace(encoding)
+		if encoding != "" && encoding != "aws-chunked" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return strings.Join(encodings, ",")
+}
+
+// parseMtime parses the modification time from the metadata headers.
+//
+// The time is stored as fractional seconds since the epoch. It
+// returns a zero time if it isn't set or is invalid.
+func parseMtime(header http.Header) time.Time {
+	value := header.Get(metaMtime)
+	if value == "" {
+		return time.Time{}
+	}
+	secs, frac := value, ""
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		secs, frac = value[:dot], value[dot+1:]
+	}
+	s, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	var ns int64
+	if frac != "" {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		frac += strings.Repeat("0", 9-len(frac))
+		ns, err = strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return time.Time{}
+		}
+	}
+	if strings.HasPrefix(secs, "-") {
+		ns = -ns
+	}
+	return time.Unix(s, ns)
+}
+
+// formatMtime formats t in the format used by parseMtime
+func formatMtime(t time.Time) string {
+	secs, ns := t.Unix(), int64(t.Nanosecond())
+	sign := ""
+	if secs < 0 && ns != 0 {
+		// t.Unix() rounds down so adjust for a negative fraction
+		secs, ns = secs+1, 1000000000-ns
+		if secs == 0 {
+			sign = "-"
+		}
+	}
+	out := sign + strconv.FormatInt(secs, 10)
+	if ns == 0 {
+		return out
+	}
+	return out + "." + strings.TrimRight(strconv.FormatInt(1000000000+ns, 10)[1:], "0")
+}
@@ -0,0 +1,532 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// Limits on multipart uploads
+const (
+	maxPartNumber = 10000
+	minPartSize   = 5 * 1024 * 1024
+	maxListParts  = 1000
+)
+
+// part is an uploaded part of a multipart upload
+type part struct {
+	number  int
+	md5sum  []byte
+	size    int64
+	modTime time.Time
+}
+
+// upload is a multipart upload in progress.
+//
+// The parts are stored in files in a temporary directory until the
+// upload is completed.
+type upload struct {
+	id        string
+	vfs       *vfs.VFS
+	bucket    string
+	key       string
+	dir       string // temporary directory holding the parts
+	initiated time.Time
+	meta      http.Header
+	modTime   time.Time
+	mu        sync.Mutex
+	parts     map[int]*part
+}
+
+// partPath returns the path of the file holding part number
+func (u *upload) partPath(number int) string {
+	return filepath.Join(u.dir, strconv.Itoa(number))
+}
+
+// uploads holds all the multipart uploads in progress
+type uploads struct {
+	mu      sync.Mutex
+	dir     string // temporary directory, created when first needed
+	uploads map[string]*upload
+}
+
+// newUploads makes a new empty uploads
+func newUploads() *uploads {
+	return &uploads{
+		uploads: make(map[string]*upload),
+	}
+}
+
+// create starts a new upload
+func (us *uploads) create(VFS *vfs.VFS, bucket, key string, meta http.Header, modTime time.Time) (*upload, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	if us.dir == "" {
+		dir, err := os.MkdirTemp("", "rclone-serve-s3-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to make temporary directory for multipart uploads: %w", err)
+		}
+		us.dir = dir
+	}
+	var id [16]byte
+	_, err := rand.Read(id[:])
+	if err != nil {
+		return nil, err
+	}
+	u := &upload{
+		id:        hex.EncodeToString(id[:]),
+		vfs:       VFS,
+		bucket:    bucket,
+		key:       key,
+		initiated: time.Now(),
+		meta:      meta,
+		modTime:   modTime,
+		parts:     make(map[int]*part),
+	}
+	u.dir = filepath.Join(us.dir, u.id)
+	err = os.Mkdir(u.dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make temporary directory for multipart upload: %w", err)
+	}
+	us.uploads[u.id] = u
+	return u, nil
+}
+
+// get finds the upload with id for the object
+func (us *uploads) get(VFS *vfs.VFS, bucket, key, id string) (*upload, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	u, ok := us.uploads[id]
+	if !ok || u.vfs != VFS || u.bucket != bucket || u.key != key {
+		return nil, errNoSuchUpload
+	}
+	return u, nil
+}
+
+// remove removes the upload and its parts
+func (us *uploads) remove(u *upload) {
+	us.mu.Lock()
+	delete(us.uploads, u.id)
+	us.mu.Unlock()
+	err := os.RemoveAll(u.dir)
+	if err != nil {
+		fs.Errorf(nil, "Failed to remove multipart upload parts: %v", err)
+	}
+}
+
+// list returns the uploads in progress in the bucket sorted by key
+// then initiation time
+func (us *uploads) list(VFS *vfs.VFS, bucket string) []*upload {
+	us.mu.Lock()
+	var out []*upload
+	for _, u := range us.uploads {
+		if u.vfs == VFS && u.bucket == bucket {
+			out = append(out, u)
+		}
+	}
+	us.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].key != out[j].key {
+			return out[i].key < out[j].key
+		}
+		if !out[i].initiated.Equal(out[j].initiated) {
+			return out[i].initiated.Before(out[j].initiated)
+		}
+		return out[i].id < out[j].id
+	})
+	return out
+}
+
+// shutdown abandons all the uploads in progress and removes the
+// temporary directory
+func (us *uploads) shutdown() error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	us.uploads = make(map[string]*upload)
+	if us.dir == "" {
+		return nil
+	}
+	err := os.RemoveAll(us.dir)
+	us.dir = ""
+	if err != nil {
+		return fmt.Errorf("failed to remove multipart upload directory: %w", err)
+	}
+	return nil
+}
+
+// createMultipartUpload implements CreateMultipartUpload
+func (s *server) createMultipartUpload(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(key, "/") {
+		return errInvalidArgument
+	}
+	u, err := s.uploads.create(VFS, bucket, key, extractMetadata(r.Header), requestModTime(r.Header))
+	if err != nil {
+		return err
+	}
+	writeXML(w, r, initiateMultipartUploadResult{
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: u.id,
+	})
+	return nil
+}
+
+// parsePartNumber reads the partNumber query parameter
+func parsePartNumber(r *http.Request) (int, error) {
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number < 1 || number > maxPartNumber {
+		return 0, errInvalidArgument
+	}
+	return number, nil
+}
+
+// parseCopySourceRange parses the x-amz-copy-source-range header
+// returning the offset and length to copy from a source of size.
+//
+// If it isn't set the whole source is copied.
+func parseCopySourceRange(value string, size int64) (offset, length int64, err error) {
+	if value == "" {
+		return 0, size, nil
+	}
+	value = strings.TrimPrefix(value, "bytes=")
+	dash := strings.IndexByte(value, '-')
+	if dash < 0 {
+		return 0, 0, errInvalidArgument
+	}
+	start, err := strconv.ParseInt(value[:dash], 10, 64)
+	if err != nil {
+		return 0, 0, errInvalidArgument
+	}
+	end, err := strconv.ParseInt(value[dash+1:], 10, 64)
+	if err != nil {
+		return 0, 0, errInvalidArgument
+	}
+	if start < 0 || end < start || end >= size {
+		return 0, 0, errInvalidRange
+	}
+	return start, end - start + 1, nil
+}
+
+// writePart writes in to the file for part number of the upload
+// checking it against wantMD5 if set
+func (u *upload) writePart(number int, in io.Reader, size int64, wantMD5 []byte) (*part, error) {
+	// Write to a temporary name so a failed upload doesn't replace an
+	// existing part
+	partPath := u.partPath(number)
+	tmpPath := partPath + ".tmp" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	hasher := md5.New()
+	n, err := io.Copy(out, io.TeeReader(in, hasher))
+	if err == nil && size >= 0 && n != size {
+		err = errIncompleteBody
+	}
+	md5sum := hasher.Sum(nil)
+	if err == nil && wantMD5 != nil && !bytes.Equal(wantMD5, md5sum) {
+		err = errBadDigest
+	}
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+	p := &part{
+		number:  number,
+		md5sum:  md5sum,
+		size:    n,
+		modTime: time.Now(),
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	err = os.Rename(tmpPath, partPath)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+	u.parts[number] = p
+	return p, nil
+}
+
+// uploadPart implements UploadPart and UploadPartCopy
+func (s *server) uploadPart(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	u, err := s.uploads.get(VFS, bucket, key, r.URL.Query().Get("uploadId"))
+	if err != nil {
+		return err
+	}
+	number, err := parsePartNumber(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		in      io.Reader = r.Body
+		size              = r.ContentLength
+		wantMD5 []byte
+		isCopy  = r.Header.Get("X-Amz-Copy-Source") != ""
+	)
+	if isCopy {
+		srcBucket, srcKey, err := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
+		if err != nil {
+			return err
+		}
+		srcFile, err := getFile(VFS, srcBucket, srcKey)
+		if err != nil {
+			return err
+		}
+		err = s.checkCopyPreconditions(r, VFS, srcFile)
+		if err != nil {
+			return err
+		}
+		offset, length, err := parseCopySourceRange(r.Header.Get("X-Amz-Copy-Source-Range"), srcFile.Size())
+		if err != nil {
+			return err
+		}
+		handle, err := srcFile.Open(os.O_RDONLY)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = handle.Close()
+		}()
+		in = io.NewSectionReader(handle, offset, length)
+		size = length
+	} else {
+		wantMD5, err = contentMD5(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	p, err := u.writePart(number, in, size, wantMD5)
+	if err != nil {
+		return err
+	}
+
+	if isCopy {
+		writeXML(w, r, copyPartResult{
+			LastModified: formatTime(p.modTime),
+			ETag:         quoteMD5(p.md5sum),
+		})
+		return nil
+	}
+	w.Header().Set("ETag", quoteMD5(p.md5sum))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// completeMultipartUpload implements CompleteMultipartUpload
+func (s *server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	u, err := s.uploads.get(VFS, bucket, key, r.URL.Query().Get("uploadId"))
+	if err != nil {
+		return err
+	}
+	var req completeMultipartUpload
+	err = xml.NewDecoder(io.LimitReader(r.Body, 2*1024*1024)).Decode(&req)
+	if err != nil || len(req.Parts) == 0 {
+		return errMalformedXML
+	}
+
+	// Check the parts requested against the parts uploaded
+	u.mu.Lock()
+	parts := make([]*part, 0, len(req.Parts))
+	var size int64
+	etags := md5.New()
+	for i, reqPart := range req.Parts {
+		if i > 0 && reqPart.PartNumber <= req.Parts[i-1].PartNumber {
+			u.mu.Unlock()
+			return errInvalidPartOrder
+		}
+		p, ok := u.parts[reqPart.PartNumber]
+		if !ok || strings.Trim(reqPart.ETag, `"`) != hex.EncodeToString(p.md5sum) {
+			u.mu.Unlock()
+			return errInvalidPart
+		}
+		if i < len(req.Parts)-1 && p.size < minPartSize {
+			u.mu.Unlock()
+			return errEntityTooSmall
+		}
+		parts = append(parts, p)
+		size += p.size
+		_, _ = etags.Write(p.md5sum)
+	}
+	u.mu.Unlock()
+
+	// Join the parts together into the object
+	readers := make([]io.Reader, 0, len(parts))
+	for _, p := range parts {
+		in, err := os.Open(u.partPath(p.number))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = in.Close()
+		}()
+		readers = append(readers, in)
+	}
+	remote := path.Join(bucket, key)
+	_, err = writeFile(VFS, remote, io.MultiReader(readers...), size, u.modTime)
+	if err != nil {
+		return err
+	}
+	s.meta.set(VFS, remote, u.meta)
+	s.uploads.remove(u)
+
+	writeXML(w, r, completeMultipartUploadResult{
+		Location: "/" + remote,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     fmt.Sprintf(`"%x-%d"`, etags.Sum(nil), len(parts)),
+	})
+	return nil
+}
+
+// abortMultipartUpload implements AbortMultipartUpload
+func (s *server) abortMultipartUpload(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	u, err := s.uploads.get(VFS, bucket, key, r.URL.Query().Get("uploadId"))
+	if err != nil {
+		return err
+	}
+	s.uploads.remove(u)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// listParts implements ListParts
+func (s *server) listParts(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	query := r.URL.Query()
+	u, err := s.uploads.get(VFS, bucket, key, query.Get("uploadId"))
+	if err != nil {
+		return err
+	}
+	maxParts := maxListParts
+	if value := query.Get("max-parts"); value != "" {
+		maxParts, err = strconv.Atoi(value)
+		if err != nil || maxParts < 0 {
+			return errInvalidArgument
+		}
+		if maxParts > maxListParts {
+			maxParts = maxListParts
+		}
+	}
+	marker := 0
+	if value := query.Get("part-number-marker"); value != "" {
+		marker, err = strconv.Atoi(value)
+		if err != nil || marker < 0 {
+			return errInvalidArgument
+		}
+	}
+
+	u.mu.Lock()
+	numbers := make([]int, 0, len(u.parts))
+	for number := range u.parts {
+		if number > marker {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+	result := listPartsResult{
+		Bucket:           bucket,
+		Key:              key,
+		UploadID:         u.id,
+		StorageClass:     "STANDARD",
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+	for _, number := range numbers {
+		if len(result.Parts) >= maxParts {
+			result.IsTruncated = true
+			break
+		}
+		p := u.parts[number]
+		result.Parts = append(result.Parts, partInfo{
+			PartNumber:   p.number,
+			LastModified: formatTime(p.modTime),
+			ETag:         quoteMD5(p.md5sum),
+			Size:         p.size,
+		})
+		result.NextPartNumberMarker = p.number
+	}
+	u.mu.Unlock()
+	writeXML(w, r, result)
+	return nil
+}
+
+// listMultipartUploads implements ListMultipartUploads
+func (s *server) listMultipartUploads(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string) error {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	keyMarker := query.Get("key-marker")
+	uploadIDMarker := query.Get("upload-id-marker")
+	maxUploads := maxListKeys
+	if value := query.Get("max-uploads"); value != "" {
+		maxUploads, err = strconv.Atoi(value)
+		if err != nil || maxUploads < 0 {
+			return errInvalidArgument
+		}
+		if maxUploads > maxListKeys {
+			maxUploads = maxListKeys
+		}
+	}
+	result := listMultipartUploadsResult{
+		Bucket:         bucket,
+		KeyMarker:      keyMarker,
+		UploadIDMarker: uploadIDMarker,
+		Prefix:         prefix,
+		MaxUploads:     maxUploads,
+	}
+	// Uploads for the key marker are skipped unless they come after
+	// the upload id marker
+	passedIDMarker := false
+	for _, u := range s.uploads.list(VFS, bucket) {
+		if !strings.HasPrefix(u.key, prefix) || u.key < keyMarker {
+			continue
+		}
+		if u.key == keyMarker && (uploadIDMarker == "" || !passedIDMarker) {
+			passedIDMarker = u.id == uploadIDMarker
+			continue
+		}
+		if len(result.Uploads) >= maxUploads {
+			result.IsTruncated = true
+			break
+		}
+		result.Uploads = append(result.Uploads, uploadInfo{
+			Key:          u.key,
+			UploadID:     u.id,
+			Initiated:    formatTime(u.initiated),
+			StorageClass: "STANDARD",
+		})
+		result.NextKeyMarker = u.key
+		result.NextUploadIDMarker = u.id
+	}
+	writeXML(w, r, result)
+	return nil
+}
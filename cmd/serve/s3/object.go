@@ -0,0 +1,324 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/vfs"
+)
+
+// responseOverrides are the query parameters which can override the
+// response headers of GetObject
+var responseOverrides = map[string]string{
+	"response-cache-control":       "Cache-Control",
+	"response-content-disposition": "Content-Disposition",
+	"response-content-encoding":    "Content-Encoding",
+	"response-content-language":    "Content-Language",
+	"response-content-type":        "Content-Type",
+	"response-expires":             "Expires",
+}
+
+// setObjectHeaders sets the headers describing file on the response
+func (s *server) setObjectHeaders(ctx context.Context, w http.ResponseWriter, VFS *vfs.VFS, remote string, file *vfs.File) {
+	header := w.Header()
+	for name, values := range s.meta.get(VFS, remote) {
+		header[name] = values
+	}
+	if header.Get("Content-Type") == "" {
+		if o, ok := file.DirEntry().(fs.Object); ok {
+			header.Set("Content-Type", fs.MimeType(ctx, o))
+		} else {
+			header.Set("Content-Type", fs.MimeTypeFromName(remote))
+		}
+	}
+	modTime := file.ModTime()
+	header.Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	header.Set(metaMtime, formatMtime(modTime))
+	header.Set("ETag", s.etag(ctx, VFS, file))
+}
+
+// headObject implements HeadObject
+func (s *server) headObject(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	file, err := getFile(VFS, bucket, key)
+	if err != nil {
+		return err
+	}
+	s.setObjectHeaders(r.Context(), w, VFS, path.Join(bucket, key), file)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size(), 10))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// getObject implements GetObject
+func (s *server) getObject(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	file, err := getFile(VFS, bucket, key)
+	if err != nil {
+		return err
+	}
+	remote := path.Join(bucket, key)
+	s.setObjectHeaders(r.Context(), w, VFS, remote, file)
+	query := r.URL.Query()
+	for param, name := range responseOverrides {
+		if value := query.Get(param); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+
+	in, err := file.Open(os.O_RDONLY)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := in.Close()
+		if err != nil {
+			fs.Errorf(remote, "Failed to close file: %v", err)
+		}
+	}()
+
+	// Account the transfer
+	if o, ok := file.DirEntry().(fs.Object); ok {
+		tr := accounting.Stats(r.Context()).NewTransfer(o)
+		defer tr.Done(r.Context(), nil)
+	}
+
+	http.ServeContent(w, r, "", file.ModTime(), in)
+	return nil
+}
+
+// putObject implements PutObject
+func (s *server) putObject(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	wantMD5, err := contentMD5(r)
+	if err != nil {
+		return err
+	}
+	remote := path.Join(bucket, key)
+	if strings.HasSuffix(key, "/") {
+		// A directory marker - make the directory
+		_, _ = io.Copy(io.Discard, r.Body)
+		err = mkdirAll(VFS, remote)
+		if err != nil {
+			return err
+		}
+		emptyMD5 := md5.Sum(nil)
+		w.Header().Set("ETag", quoteMD5(emptyMD5[:]))
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	md5sum, err := writeFile(VFS, remote, r.Body, r.ContentLength, requestModTime(r.Header))
+	if err != nil {
+		return err
+	}
+	if wantMD5 != nil && !bytes.Equal(wantMD5, md5sum) {
+		if removeErr := VFS.Remove(remote); removeErr != nil {
+			fs.Errorf(remote, "Failed to remove corrupted upload: %v", removeErr)
+		}
+		return errBadDigest
+	}
+	s.meta.set(VFS, remote, extractMetadata(r.Header))
+	s.setWriteETag(r.Context(), w, VFS, remote, md5sum)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// setWriteETag sets the ETag header for a newly written object.
+//
+// If the ETag is the MD5 then the sum calculated while writing is
+// used as the object may not have been uploaded yet.
+func (s *server) setWriteETag(ctx context.Context, w http.ResponseWriter, VFS *vfs.VFS, remote string, md5sum []byte) {
+	if s.getHashType(VFS) == hash.MD5 {
+		w.Header().Set("ETag", quoteMD5(md5sum))
+		return
+	}
+	node, err := VFS.Stat(remote)
+	if err != nil {
+		return
+	}
+	if file, ok := node.(*vfs.File); ok {
+		w.Header().Set("ETag", s.etag(ctx, VFS, file))
+	}
+}
+
+// parseCopySource parses the X-Amz-Copy-Source header
+func parseCopySource(copySource string) (bucket, key string, err error) {
+	if i := strings.IndexByte(copySource, '?'); i >= 0 {
+		query, err := url.ParseQuery(copySource[i+1:])
+		if err != nil {
+			return "", "", errInvalidArgument
+		}
+		if versionID := query.Get("versionId"); versionID != "" && versionID != "null" {
+			return "", "", errNotImplemented
+		}
+		copySource = copySource[:i]
+	}
+	copySource, err = url.PathUnescape(copySource)
+	if err != nil {
+		return "", "", errInvalidArgument
+	}
+	bucket, key = splitPath(copySource)
+	if bucket == "" || key == "" {
+		return "", "", errInvalidArgument
+	}
+	if err = checkBucketName(bucket); err != nil {
+		return "", "", err
+	}
+	if err = checkKey(key); err != nil {
+		return "", "", err
+	}
+	return bucket, key, nil
+}
+
+// checkCopyPreconditions checks the x-amz-copy-source-if-* headers
+// against the source file
+func (s *server) checkCopyPreconditions(r *http.Request, VFS *vfs.VFS, file *vfs.File) error {
+	etag := s.etag(r.Context(), VFS, file)
+	modTime := file.ModTime()
+	if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); match != "" && strings.Trim(match, `"`) != strings.Trim(etag, `"`) {
+		return errPreconditionFailed
+	}
+	if noneMatch := r.Header.Get("X-Amz-Copy-Source-If-None-Match"); noneMatch != "" && strings.Trim(noneMatch, `"`) == strings.Trim(etag, `"`) {
+		return errPreconditionFailed
+	}
+	if since, err := http.ParseTime(r.Header.Get("X-Amz-Copy-Source-If-Modified-Since")); err == nil && !modTime.After(since) {
+		return errPreconditionFailed
+	}
+	if since, err := http.ParseTime(r.Header.Get("X-Amz-Copy-Source-If-Unmodified-Since")); err == nil && modTime.After(since) {
+		return errPreconditionFailed
+	}
+	return nil
+}
+
+// copyObject implements CopyObject
+func (s *server) copyObject(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	srcBucket, srcKey, err := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		return err
+	}
+	srcFile, err := getFile(VFS, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	_, err = bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	err = s.checkCopyPreconditions(r, VFS, srcFile)
+	if err != nil {
+		return err
+	}
+	srcRemote := path.Join(srcBucket, srcKey)
+	dstRemote := path.Join(bucket, key)
+
+	// Work out the metadata for the destination
+	replace := false
+	switch directive := r.Header.Get("X-Amz-Metadata-Directive"); directive {
+	case "", "COPY":
+	case "REPLACE":
+		replace = true
+	default:
+		return errInvalidArgument
+	}
+	meta := s.meta.get(VFS, srcRemote)
+	modTime := srcFile.ModTime()
+	if replace {
+		meta = extractMetadata(r.Header)
+		modTime = requestModTime(r.Header)
+	}
+
+	if srcRemote == dstRemote {
+		if !replace {
+			return errInvalidRequest
+		}
+		// Just update the metadata
+		err = VFS.Chtimes(dstRemote, modTime, modTime)
+		if err != nil {
+			return err
+		}
+	} else {
+		in, err := srcFile.Open(os.O_RDONLY)
+		if err != nil {
+			return err
+		}
+		_, err = writeFile(VFS, dstRemote, in, srcFile.Size(), modTime)
+		closeErr := in.Close()
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+	}
+	s.meta.set(VFS, dstRemote, meta)
+
+	dstFile, err := getFile(VFS, bucket, key)
+	if err != nil {
+		return err
+	}
+	writeXML(w, r, copyObjectResult{
+		LastModified: formatTime(dstFile.ModTime()),
+		ETag:         s.etag(r.Context(), VFS, dstFile),
+	})
+	return nil
+}
+
+// removeObject removes the object from the VFS. It isn't an error if
+// the object doesn't exist.
+func (s *server) removeObject(VFS *vfs.VFS, bucket, key string) error {
+	remote := path.Join(bucket, key)
+	node, err := VFS.Stat(remote)
+	if errors.Is(err, vfs.ENOENT) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if node.IsDir() {
+		if !strings.HasSuffix(key, "/") {
+			return nil
+		}
+		// Removing a directory marker so remove the directory if empty
+		err = node.Remove()
+		if errors.Is(err, vfs.ENOTEMPTY) {
+			return nil
+		}
+	} else {
+		if strings.HasSuffix(key, "/") {
+			return nil
+		}
+		err = node.Remove()
+		s.meta.delete(VFS, remote)
+	}
+	if err != nil {
+		return err
+	}
+	removeEmptyDirs(VFS, bucket, path.Dir(remote))
+	return nil
+}
+
+// deleteObject implements DeleteObject
+func (s *server) deleteObject(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket, key string) error {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return err
+	}
+	err = s.removeObject(VFS, bucket, key)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package s3 implements an S3 server for rclone
+package s3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/rc"
+	httplib "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Options contains options for the S3 server
+type Options struct {
+	AuthKeys []string // access_key_id,secret_access_key pairs
+	HashName string   // hash to use for the ETag
+	Region   string   // region to report to clients
+	HTTP     httplib.Options
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	HashName: "MD5",
+	Region:   "us-east-1",
+	HTTP:     httplib.DefaultOpt,
+}
+
+// Opt is options set by command line flags
+var Opt = DefaultOpt
+
+// AddFlags adds flags for the s3 server
+func AddFlags(flagSet *pflag.FlagSet, Opt *Options) {
+	flags.StringArrayVarP(flagSet, &Opt.AuthKeys, "auth-key", "", Opt.AuthKeys, "Set key pair for v4 authorization: access_key_id,secret_access_key")
+	flags.StringVarP(flagSet, &Opt.HashName, "etag-hash", "", Opt.HashName, "Which hash to use for the ETag, or auto or blank for off")
+	flags.StringVarP(flagSet, &Opt.Region, "region", "", Opt.Region, "Region to report to clients")
+	httplib.AddFlagsPrefix(flagSet, "", &Opt.HTTP)
+}
+
+func init() {
+	rc.AddOption("s3", &Opt)
+	flagSet := Command.Flags()
+	vfsflags.AddFlags(flagSet)
+	proxyflags.AddFlags(flagSet)
+	AddFlags(flagSet, &Opt)
+}
+
+// Command definition for cobra
+var Command = &cobra.Command{
+	Use:   "s3 remote:path",
+	Short: `Serve remote:path over s3.`,
+	Long: strings.ReplaceAll(`
+Run a basic S3 server to serve a remote over the S3 protocol. This
+can be used by any S3 compatible client, including rclone's own
+[s3](/s3) backend, to read and write it.
+
+The top level directories of the remote are presented as buckets and
+the files below them as objects, so serving |remote:path| will make
+|remote:path/bucket/dir/file.txt| available as the object
+|dir/file.txt| in the bucket |bucket|. Files in the root of the remote
+are not visible as they are not in a bucket.
+
+The server supports the common S3 operations: listing buckets,
+creating and removing buckets, ListObjects (v1 and v2), GetObject,
+HeadObject, PutObject, CopyObject, DeleteObject, DeleteObjects and
+multipart uploads (including UploadPartCopy).
+
+Only path style requests are supported, so clients must be configured
+to use them, e.g. with |force_path_style = true| for rclone's s3
+backend, which is the default for |provider = Other|.
+
+### Authentication
+
+Use |--auth-key access_key_id,secret_access_key| to set the keys
+clients must use to sign their requests with AWS signature version 4.
+This flag can be repeated to allow more than one key. Requests can be
+signed in the headers or presigned in the query string.
+
+If neither |--auth-key| nor |--auth-proxy| is supplied then the server
+will accept anonymous requests and won't check signatures.
+
+If |--auth-proxy| is used then the access key ID in the request is
+passed to the proxy as the |user| and the proxy must return the secret
+access key as |_secret| along with the backend config. Each access key
+then gets its own backend.
+
+### Object metadata
+
+The modification time of objects is read from and stored in the
+remote. Other metadata, for example |Content-Type| or |X-Amz-Meta-|
+headers, is kept in memory only and will be lost when the server is
+restarted.
+
+The ETag of objects is the |--etag-hash| of the object, which is MD5
+by default, as S3 clients expect. If the remote doesn't support that
+hash then a made up ETag based on the size and modification time is
+returned instead. Use |--etag-hash auto| to use the first hash the
+remote supports.
+
+Parts of multipart uploads are stored in a temporary directory until
+the upload is completed or aborted.
+
+Use |--region| to set the region reported to clients. Requests signed
+for any region are accepted.
+
+### Example
+
+    rclone serve s3 --auth-key ACCESS_KEY_ID,SECRET_ACCESS_KEY remote:path
+
+Configure an rclone remote to use it with
+
+    [serves3]
+    type = s3
+    provider = Other
+    access_key_id = ACCESS_KEY_ID
+    secret_access_key = SECRET_ACCESS_KEY
+    endpoint = http://127.0.0.1:8080/
+    list_version = 2
+    list_url_encode = true
+
+`, "|", "`") + httplib.Help + vfs.Help + proxy.Help,
+	RunE: func(command *cobra.Command, args []string) error {
+		var f fs.Fs
+		if proxyflags.Opt.AuthProxy == "" {
+			cmd.CheckArgs(1, 1, command, args)
+			f = cmd.NewFsSrc(args)
+		} else {
+			cmd.CheckArgs(0, 0, command, args)
+		}
+		cmd.Run(false, false, command, func() error {
+			s, err := newServer(context.Background(), f, &Opt)
+			if err != nil {
+				return err
+			}
+			err = s.serve()
+			if err != nil {
+				return err
+			}
+			s.server.Wait()
+			return s.Shutdown()
+		})
+		return nil
+	},
+}
@@ -0,0 +1,63 @@
+// Serve s3 tests set up a server and run the integration tests
+// for the s3 remote against it.
+
+package s3
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servetest"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBindAddress = "localhost:0"
+	testAccessKey   = "testaccesskey"
+	testSecretKey   = "testsecretkey"
+)
+
+// TestS3 runs the s3 server then runs the unit tests for the
+// s3 remote against it.
+func TestS3(t *testing.T) {
+	// Configure and start the server
+	start := func(f fs.Fs) (configmap.Simple, func()) {
+		opt := DefaultOpt
+		opt.HTTP.ListenAddr = []string{testBindAddress}
+		accessKey, secretKey := testAccessKey, testSecretKey
+		if proxyflags.Opt.AuthProxy != "" {
+			// The test auth proxy returns the access key as the secret
+			secretKey = accessKey
+		} else {
+			opt.AuthKeys = []string{accessKey + "," + secretKey}
+		}
+
+		s, err := newServer(context.Background(), f, &opt)
+		require.NoError(t, err)
+		require.NoError(t, s.serve())
+
+		// Config for the backend we'll use to connect to the server
+		config := configmap.Simple{
+			"type":              "s3",
+			"provider":          "Other",
+			"endpoint":          s.server.URL(),
+			"access_key_id":     accessKey,
+			"secret_access_key": secretKey,
+			"list_version":      "2",
+			"list_url_encode":   "true",
+			"force_path_style":  "true",
+		}
+
+		// return a stop function
+		return config, func() {
+			assert.NoError(t, s.Shutdown())
+		}
+	}
+
+	servetest.Run(t, "s3", start)
+}
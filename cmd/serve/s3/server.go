@@ -0,0 +1,494 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	httplib "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/lib/http/auth"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+)
+
+// maxKeyLength is the longest key S3 allows
+const maxKeyLength = 1024
+
+// subresources are query parameters which select an S3 API we don't
+// implement
+var subresources = []string{
+	"accelerate", "acl", "analytics", "cors", "encryption",
+	"intelligent-tiering", "inventory", "legal-hold", "lifecycle",
+	"logging", "metrics", "notification", "object-lock",
+	"ownershipControls", "policy", "policyStatus", "publicAccessBlock",
+	"replication", "requestPayment", "restore", "retention", "select",
+	"tagging", "torrent", "versionId", "versions", "website",
+}
+
+// server contains everything to run the server
+type server struct {
+	server   *httplib.Server
+	f        fs.Fs
+	ctx      context.Context // for global config
+	opt      Options
+	vfs      *vfs.VFS // the VFS if not using the auth proxy
+	proxy    *proxy.Proxy
+	keys     map[string]string // secret access keys by access key ID
+	hashType hash.Type         // hash to use for the ETag
+	hashAuto bool              // if set use the first hash the backend supports
+	meta     *metaStore
+	uploads  *uploads
+}
+
+// Make a new S3 server to serve the remote
+func newServer(ctx context.Context, f fs.Fs, opt *Options) (*server, error) {
+	s := &server{
+		f:       f,
+		ctx:     ctx,
+		opt:     *opt,
+		keys:    make(map[string]string, len(opt.AuthKeys)),
+		meta:    newMetaStore(),
+		uploads: newUploads(),
+	}
+	for i, pair := range opt.AuthKeys {
+		parts := strings.SplitN(pair, ",", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid --auth-key number %d: expecting access_key_id,secret_access_key", i+1)
+		}
+		s.keys[parts[0]] = parts[1]
+	}
+	switch opt.HashName {
+	case "":
+	case "auto":
+		s.hashAuto = true
+	default:
+		err := s.hashType.Set(opt.HashName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if proxyflags.Opt.AuthProxy != "" {
+		s.proxy = proxy.New(ctx, &proxyflags.Opt)
+	} else {
+		s.vfs = vfs.New(f, &vfsflags.Opt)
+	}
+	// Requests are authenticated with their S3 signatures so no
+	// http auth is configured here
+	var err error
+	s.server, err = httplib.NewServer(ctx, opt.HTTP, auth.Options{})
+	if err != nil {
+		return nil, err
+	}
+	s.Bind(s.server.Router())
+	return s, nil
+}
+
+// serve runs the http server in the background.
+//
+// Use s.Shutdown() to stop it
+func (s *server) serve() error {
+	err := s.server.Serve()
+	if err != nil {
+		return err
+	}
+	for _, url := range s.server.URLs() {
+		fs.Logf(s.f, "Serving S3 on %s", url)
+	}
+	return nil
+}
+
+// Bind the server handlers to router
+func (s *server) Bind(router chi.Router) {
+	router.Use(
+		middleware.SetHeader("Accept-Ranges", "bytes"),
+		middleware.SetHeader("Server", "rclone/"+fs.Version),
+	)
+	router.HandleFunc("/*", s.handler)
+}
+
+// Shutdown stops the http server and releases the resources used by
+// the server
+func (s *server) Shutdown() error {
+	err := s.server.Shutdown()
+	if uploadsErr := s.uploads.shutdown(); err == nil {
+		err = uploadsErr
+	}
+	return err
+}
+
+// authenticate checks the signature of the request returning the VFS
+// to use for it
+func (s *server) authenticate(r *http.Request) (VFS *vfs.VFS, err error) {
+	sig, err := parseSignature(r)
+	if err != nil {
+		return nil, err
+	}
+	if s.proxy == nil && len(s.keys) == 0 {
+		// anonymous access - decode the body if necessary
+		if sig != nil {
+			err = sig.wrapBody(r, nil)
+		}
+		return s.vfs, err
+	}
+	if sig == nil {
+		return nil, errMissingAuth
+	}
+	var secret string
+	if s.proxy != nil {
+		VFS, secret, err = s.proxy.CallSecret(sig.accessKey)
+		if err != nil {
+			fs.Infof(nil, "Auth proxy failed for %q: %v", sig.accessKey, err)
+			return nil, errInvalidAccessKeyID
+		}
+	} else {
+		var ok bool
+		secret, ok = s.keys[sig.accessKey]
+		if !ok {
+			return nil, errInvalidAccessKeyID
+		}
+		VFS = s.vfs
+	}
+	err = sig.verify(r, secret)
+	if err != nil {
+		return nil, err
+	}
+	return VFS, nil
+}
+
+// splitPath splits the URL path into bucket and key
+func splitPath(urlPath string) (bucket, key string) {
+	urlPath = strings.TrimPrefix(urlPath, "/")
+	slash := strings.IndexByte(urlPath, '/')
+	if slash < 0 {
+		return urlPath, ""
+	}
+	return urlPath[:slash], urlPath[slash+1:]
+}
+
+// checkBucketName checks the bucket name can be used as a directory
+func checkBucketName(bucket string) error {
+	if bucket == "." || bucket == ".." || strings.ContainsAny(bucket, "/\\") {
+		return errInvalidBucketName
+	}
+	return nil
+}
+
+// checkKey checks the key can be turned into a path in the VFS
+func checkKey(key string) error {
+	if len(key) > maxKeyLength {
+		return errKeyTooLong
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(key, "/"), "/") {
+		if part == "" || part == "." || part == ".." {
+			return errInvalidArgument
+		}
+	}
+	return nil
+}
+
+// hasSubresource returns true if the query selects an API we don't
+// implement
+func hasSubresource(query url.Values) bool {
+	for _, name := range subresources {
+		if _, found := query[name]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// handler reads incoming requests and dispatches them
+func (s *server) handler(w http.ResponseWriter, r *http.Request) {
+	fs.Infof(r.URL.Path, "%s from %s", r.Method, r.RemoteAddr)
+	VFS, err := s.authenticate(r)
+	if err == nil {
+		err = s.dispatch(w, r, VFS)
+	}
+	if err != nil {
+		writeError(w, r, err)
+	}
+}
+
+// dispatch calls the correct handler for the request
+func (s *server) dispatch(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS) error {
+	bucket, key := splitPath(r.URL.Path)
+	query := r.URL.Query()
+	if bucket == "" {
+		if r.Method == http.MethodGet {
+			return s.listBuckets(w, r, VFS)
+		}
+		return errMethodNotAllowed
+	}
+	if err := checkBucketName(bucket); err != nil {
+		return err
+	}
+	if key == "" {
+		return s.dispatchBucket(w, r, VFS, bucket, query)
+	}
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if hasSubresource(query) {
+		return errNotImplemented
+	}
+	_, isUploadID := query["uploadId"]
+	switch r.Method {
+	case http.MethodGet:
+		if isUploadID {
+			return s.listParts(w, r, VFS, bucket, key)
+		}
+		return s.getObject(w, r, VFS, bucket, key)
+	case http.MethodHead:
+		return s.headObject(w, r, VFS, bucket, key)
+	case http.MethodPut:
+		if isUploadID {
+			return s.uploadPart(w, r, VFS, bucket, key)
+		}
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			return s.copyObject(w, r, VFS, bucket, key)
+		}
+		return s.putObject(w, r, VFS, bucket, key)
+	case http.MethodDelete:
+		if isUploadID {
+			return s.abortMultipartUpload(w, r, VFS, bucket, key)
+		}
+		return s.deleteObject(w, r, VFS, bucket, key)
+	case http.MethodPost:
+		if _, found := query["uploads"]; found {
+			return s.createMultipartUpload(w, r, VFS, bucket, key)
+		}
+		if isUploadID {
+			return s.completeMultipartUpload(w, r, VFS, bucket, key)
+		}
+	}
+	return errMethodNotAllowed
+}
+
+// dispatchBucket calls the correct handler for a request on a bucket
+func (s *server) dispatchBucket(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, bucket string, query url.Values) error {
+	if _, found := query["location"]; found && r.Method == http.MethodGet {
+		return s.getBucketLocation(w, r, VFS, bucket)
+	}
+	if _, found := query["versioning"]; found && r.Method == http.MethodGet {
+		return s.getBucketVersioning(w, r, VFS, bucket)
+	}
+	if hasSubresource(query) {
+		return errNotImplemented
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if _, found := query["uploads"]; found {
+			return s.listMultipartUploads(w, r, VFS, bucket)
+		}
+		return s.listObjects(w, r, VFS, bucket)
+	case http.MethodHead:
+		_, err := bucketDir(VFS, bucket)
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	case http.MethodPut:
+		return s.createBucket(w, r, VFS, bucket)
+	case http.MethodDelete:
+		return s.deleteBucket(w, r, VFS, bucket)
+	case http.MethodPost:
+		if _, found := query["delete"]; found {
+			return s.deleteObjects(w, r, VFS, bucket)
+		}
+	}
+	return errMethodNotAllowed
+}
+
+// bucketDir returns the directory for the bucket
+func bucketDir(VFS *vfs.VFS, bucket string) (*vfs.Dir, error) {
+	node, err := VFS.Stat(bucket)
+	if errors.Is(err, vfs.ENOENT) {
+		return nil, errNoSuchBucket
+	} else if err != nil {
+		return nil, err
+	}
+	dir, ok := node.(*vfs.Dir)
+	if !ok {
+		return nil, errNoSuchBucket
+	}
+	return dir, nil
+}
+
+// getFile returns the file for the object
+func getFile(VFS *vfs.VFS, bucket, key string) (*vfs.File, error) {
+	_, err := bucketDir(VFS, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(key, "/") {
+		return nil, errNoSuchKey
+	}
+	node, err := VFS.Stat(path.Join(bucket, key))
+	if errors.Is(err, vfs.ENOENT) {
+		return nil, errNoSuchKey
+	} else if err != nil {
+		return nil, err
+	}
+	file, ok := node.(*vfs.File)
+	if !ok {
+		return nil, errNoSuchKey
+	}
+	return file, nil
+}
+
+// mkdirAll makes the directory dirPath and all its parents
+func mkdirAll(VFS *vfs.VFS, dirPath string) error {
+	dirPath = strings.Trim(dirPath, "/")
+	if dirPath == "" {
+		return nil
+	}
+	node, err := VFS.Stat(dirPath)
+	if err == nil {
+		if !node.IsDir() {
+			return errInvalidArgument
+		}
+		return nil
+	} else if !errors.Is(err, vfs.ENOENT) {
+		return err
+	}
+	err = mkdirAll(VFS, path.Dir(dirPath))
+	if err != nil {
+		return err
+	}
+	err = VFS.Mkdir(dirPath, 0777)
+	if errors.Is(err, vfs.EEXIST) {
+		err = nil
+	}
+	return err
+}
+
+// removeEmptyDirs removes dirPath and its parents if they are empty
+// stopping at the bucket.
+//
+// S3 has no directories so they shouldn't be left behind when the
+// last object in them is removed.
+func removeEmptyDirs(VFS *vfs.VFS, bucket, dirPath string) {
+	for strings.HasPrefix(dirPath, bucket+"/") {
+		node, err := VFS.Stat(dirPath)
+		if err != nil || !node.IsDir() {
+			return
+		}
+		entries, err := node.(*vfs.Dir).ReadDirAll()
+		if err != nil || len(entries) != 0 {
+			return
+		}
+		err = node.Remove()
+		if err != nil {
+			fs.Debugf(dirPath, "Failed to remove empty directory: %v", err)
+			return
+		}
+		dirPath = path.Dir(dirPath)
+	}
+}
+
+// getHashType returns the hash to use for the ETag on VFS
+func (s *server) getHashType(VFS *vfs.VFS) hash.Type {
+	if s.hashAuto {
+		return VFS.Fs().Hashes().GetOne()
+	}
+	return s.hashType
+}
+
+// etag returns the quoted ETag for the file.
+//
+// If the hash isn't available then one is made from the size and
+// modification time which won't be mistaken for an MD5.
+func (s *server) etag(ctx context.Context, VFS *vfs.VFS, file *vfs.File) string {
+	hashType := s.getHashType(VFS)
+	if hashType != hash.None {
+		if o, ok := file.DirEntry().(fs.Object); ok {
+			sum, err := o.Hash(ctx, hashType)
+			if err == nil && sum != "" {
+				return `"` + sum + `"`
+			}
+		}
+	}
+	return fmt.Sprintf(`"%x-%x"`, file.ModTime().UnixNano(), file.Size())
+}
+
+// writeFile writes in to remote in the VFS, making any parent
+// directories needed, and sets the modification time.
+//
+// If size is >= 0 then it checks that size bytes were written. It
+// returns the MD5 of the data written.
+func writeFile(VFS *vfs.VFS, remote string, in io.Reader, size int64, modTime time.Time) (md5sum []byte, err error) {
+	err = mkdirAll(VFS, path.Dir(remote))
+	if err != nil {
+		return nil, err
+	}
+	handle, err := VFS.OpenFile(remote, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, err
+	}
+	hasher := md5.New()
+	n, err := io.Copy(handle, io.TeeReader(in, hasher))
+	if err == nil && size >= 0 && n != size {
+		err = errIncompleteBody
+	}
+	closeErr := handle.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Don't leave partially written files behind
+		if removeErr := VFS.Remove(remote); removeErr != nil {
+			fs.Debugf(remote, "Failed to remove partial upload: %v", removeErr)
+		}
+		return nil, err
+	}
+	err = VFS.Chtimes(remote, modTime, modTime)
+	if err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
+
+// contentMD5 reads the Content-MD5 header returning nil if not set
+func contentMD5(r *http.Request) (md5sum []byte, err error) {
+	value := r.Header.Get("Content-MD5")
+	if value == "" {
+		return nil, nil
+	}
+	md5sum, err = base64.StdEncoding.DecodeString(value)
+	if err != nil || len(md5sum) != md5.Size {
+		return nil, errInvalidDigest
+	}
+	return md5sum, nil
+}
+
+// quoteMD5 returns the md5sum in hex with quotes as used in an ETag
+func quoteMD5(md5sum []byte) string {
+	return `"` + hex.EncodeToString(md5sum) + `"`
+}
+
+// requestModTime returns the modification time for the object from
+// the metadata in the headers or now if not set.
+func requestModTime(header http.Header) time.Time {
+	modTime := parseMtime(header)
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	return modTime
+}
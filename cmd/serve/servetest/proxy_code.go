@@ -29,6 +29,10 @@ func main() {
 		"_root":    root,
 		"_obscure": "pass",
 	}
+	// For key based auth (e.g. serve s3) the secret is the user
+	if _, found := in["pass"]; !found && in["public_key"] == "" {
+		out["_secret"] = in["user"]
+	}
 	json.NewEncoder(os.Stdout).Encode(&out)
 	if err != nil {
 		log.Fatal(err)
@@ -238,7 +238,7 @@ func (c *conn) handleChannel(newChannel ssh.NewChannel) {
 
 	// Wait for either subsystem "sftp" or "exec" request
 	if <-isSFTP {
-		if err := serveChannel(channel, c.handlers, c.what); err != nil {
+		if err := serveChannel(newExtensionConn(channel, c.vfs, c.what), c.handlers, c.what); err != nil {
 			fs.Errorf(c.what, "Failed to serve SFTP: %v", err)
 		}
 	} else {
@@ -291,8 +291,8 @@ func serveStdio(f fs.Fs) error {
 		stdin:  os.Stdin,
 		stdout: os.Stdout,
 	}
-	handlers := newVFSHandler(vfs.New(f, &vfsflags.Opt))
-	return serveChannel(sshChannel, handlers, "stdio")
+	VFS := vfs.New(f, &vfsflags.Opt)
+	return serveChannel(newExtensionConn(sshChannel, VFS, "stdio"), newVFSHandler(VFS), "stdio")
 }
 
 type stdioChannel struct {
@@ -0,0 +1,418 @@
+//go:build !plan9
+// +build !plan9
+
+package sftp
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/vfs"
+)
+
+// SFTP packet types and status codes used here
+const (
+	fxpVersion       = 2
+	fxpOpen          = 3
+	fxpClose         = 4
+	fxpStatus        = 101
+	fxpHandle        = 102
+	fxpExtended      = 200
+	fxpExtendedReply = 201
+
+	fxNoSuchFile    = 2
+	fxFailure       = 4
+	fxOpUnsupported = 8
+
+	maxPacketLength = 1 << 20 // refuse packets bigger than this
+	quickCheckSize  = 2048    // bytes hashed for the md5-hash quick-check-hash
+)
+
+// checkFileAlgorithms are the check-file hash algorithms which rclone
+// hashes may be
+var checkFileAlgorithms = []string{"md5", "sha1", "sha224", "sha256", "sha384", "sha512", "crc32"}
+
+// extensionConn sits between the client and the pkg/sftp server and
+// answers the check-file and md5-hash extension requests itself, as
+// pkg/sftp refuses extended requests it doesn't know about before they
+// get to the handlers.
+//
+// Everything else is passed through unchanged apart from the VERSION
+// packet which has the extensions added to it.
+type extensionConn struct {
+	rwc  io.ReadWriteCloser // connection to the client
+	vfs  *vfs.VFS
+	what string
+	in   *io.PipeReader // packets for the server
+
+	writeMu sync.Mutex // held while writing a packet to the client
+	out     []byte     // part of a packet written by the server
+
+	mu      sync.Mutex        // protects the following
+	opens   map[uint32]string // path being opened by request id
+	handles map[string]string // path open by handle
+}
+
+// newExtensionConn wraps rwc so the check-file and md5-hash
+// extensions are answered from VFS
+func newExtensionConn(rwc io.ReadWriteCloser, VFS *vfs.VFS, what string) *extensionConn {
+	pr, pw := io.Pipe()
+	c := &extensionConn{
+		rwc:     rwc,
+		vfs:     VFS,
+		what:    what,
+		in:      pr,
+		opens:   make(map[uint32]string),
+		handles: make(map[string]string),
+	}
+	go c.readPackets(pw)
+	return c
+}
+
+// Read reads the packets from the client for the server
+func (c *extensionConn) Read(p []byte) (int, error) {
+	return c.in.Read(p)
+}
+
+// Write writes the packets from the server to the client
+//
+// The server may write a packet in several pieces so they are put
+// back together, as the extension replies must go in between packets.
+func (c *extensionConn) Write(p []byte) (int, error) {
+	c.out = append(c.out, p...)
+	for len(c.out) >= 4 {
+		n := 4 + int(binary.BigEndian.Uint32(c.out))
+		if len(c.out) < n {
+			break
+		}
+		pkt := c.out[4:n]
+		if len(pkt) > 0 {
+			switch pkt[0] {
+			case fxpVersion:
+				pkt = append(pkt[:len(pkt):len(pkt)], marshalString(marshalString(nil, "check-file"), "")...)
+				pkt = append(pkt, marshalString(marshalString(nil, "md5-hash"), "")...)
+			case fxpHandle, fxpStatus:
+				c.replied(pkt)
+			}
+		}
+		if err := c.writePacket(pkt); err != nil {
+			return 0, err
+		}
+		c.out = c.out[n:]
+	}
+	if len(c.out) == 0 {
+		c.out = nil
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the client
+func (c *extensionConn) Close() error {
+	_ = c.in.Close()
+	return c.rwc.Close()
+}
+
+// writePacket writes the packet body pkt to the client
+func (c *extensionConn) writePacket(pkt []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(pkt)))
+	if _, err := c.rwc.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := c.rwc.Write(pkt)
+	return err
+}
+
+// readPackets reads the packets from the client, answering the
+// extension requests and passing the rest to the server
+func (c *extensionConn) readPackets(pw *io.PipeWriter) {
+	var length [4]byte
+	for {
+		_, err := io.ReadFull(c.rwc, length[:])
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			_ = pw.CloseWithError(err)
+			return
+		}
+		n := binary.BigEndian.Uint32(length[:])
+		if n > maxPacketLength {
+			_ = pw.CloseWithError(fmt.Errorf("packet too long: %d bytes", n))
+			return
+		}
+		pkt := make([]byte, 4+n)
+		copy(pkt, length[:])
+		if _, err = io.ReadFull(c.rwc, pkt[4:]); err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+		if c.extended(pkt[4:]) {
+			continue
+		}
+		if _, err = pw.Write(pkt); err != nil {
+			return
+		}
+	}
+}
+
+// extended looks at the packet body pkt from the client and starts
+// answering it if it is one of our extensions, returning true if so
+func (c *extensionConn) extended(pkt []byte) bool {
+	if len(pkt) < 5 {
+		return false
+	}
+	id := binary.BigEndian.Uint32(pkt[1:])
+	switch pkt[0] {
+	case fxpOpen:
+		if name, _, ok := unmarshalString(pkt[5:]); ok {
+			c.mu.Lock()
+			c.opens[id] = cleanPath(name)
+			c.mu.Unlock()
+		}
+	case fxpClose:
+		if handle, _, ok := unmarshalString(pkt[5:]); ok {
+			c.mu.Lock()
+			delete(c.handles, handle)
+			c.mu.Unlock()
+		}
+	case fxpExtended:
+		request, data, ok := unmarshalString(pkt[5:])
+		if !ok {
+			return false
+		}
+		switch request {
+		case "check-file-name", "check-file-handle", "md5-hash", "md5-hash-handle":
+		default:
+			return false
+		}
+		go func() {
+			reply := c.answer(id, request, data)
+			if err := c.writePacket(reply); err != nil {
+				fs.Debugf(c.what, "Failed to reply to %s: %v", request, err)
+			}
+		}()
+		return true
+	}
+	return false
+}
+
+// replied notes the handle the server replied to an open with
+func (c *extensionConn) replied(pkt []byte) {
+	if len(pkt) < 5 {
+		return
+	}
+	id := binary.BigEndian.Uint32(pkt[1:])
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, found := c.opens[id]
+	if !found {
+		return
+	}
+	delete(c.opens, id)
+	if pkt[0] != fxpHandle {
+		return
+	}
+	if handle, _, ok := unmarshalString(pkt[5:]); ok {
+		c.handles[handle] = name
+	}
+}
+
+// answer the extension request and return the reply packet body
+func (c *extensionConn) answer(id uint32, request string, data []byte) []byte {
+	ctx := context.Background()
+	name, data, ok := unmarshalString(data)
+	if !ok {
+		return statusPacket(id, fxFailure, "bad request")
+	}
+	if strings.HasSuffix(request, "-handle") {
+		c.mu.Lock()
+		name, ok = c.handles[name]
+		c.mu.Unlock()
+		if !ok {
+			return statusPacket(id, fxFailure, "invalid handle")
+		}
+	} else {
+		name = cleanPath(name)
+	}
+	var algorithms string
+	if strings.HasPrefix(request, "check-file") {
+		if algorithms, data, ok = unmarshalString(data); !ok {
+			return statusPacket(id, fxFailure, "bad request")
+		}
+	}
+	var offset, length uint64
+	if offset, data, ok = unmarshalUint64(data); ok {
+		length, data, ok = unmarshalUint64(data)
+	}
+	if !ok {
+		return statusPacket(id, fxFailure, "bad request")
+	}
+	o, err := c.object(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return statusPacket(id, fxNoSuchFile, err.Error())
+	} else if err != nil {
+		return statusPacket(id, fxFailure, err.Error())
+	}
+	// Only the hashes of whole files are known
+	if offset != 0 || (length != 0 && int64(length) != o.Size()) {
+		return statusPacket(id, fxOpUnsupported, "only the hash of the whole file is supported")
+	}
+
+	if strings.HasPrefix(request, "check-file") {
+		blockSize, _, ok := unmarshalUint32(data)
+		if !ok {
+			return statusPacket(id, fxFailure, "bad request")
+		}
+		if blockSize != 0 && int64(blockSize) < o.Size() {
+			return statusPacket(id, fxOpUnsupported, "only the hash of the whole file is supported")
+		}
+		name, sum, err := c.checkFile(ctx, o, algorithms)
+		if err != nil {
+			return statusPacket(id, fxOpUnsupported, err.Error())
+		}
+		reply := marshalString(marshalString(replyHeader(id), "check-file"), name)
+		return append(reply, sum...)
+	}
+
+	quickCheck, _, ok := unmarshalString(data)
+	if !ok {
+		return statusPacket(id, fxFailure, "bad request")
+	}
+	sum, err := hashBytes(ctx, o, hash.MD5)
+	if err != nil {
+		return statusPacket(id, fxOpUnsupported, err.Error())
+	}
+	if quickCheck != "" {
+		// No hash is returned if the start of the file doesn't match
+		quickSum, err := c.quickCheckSum(name)
+		if err != nil {
+			return statusPacket(id, fxFailure, err.Error())
+		}
+		if quickCheck != string(quickSum) {
+			sum = nil
+		}
+	}
+	return marshalString(marshalString(replyHeader(id), "md5-hash"), string(sum))
+}
+
+// object returns the object at name in the VFS
+func (c *extensionConn) object(name string) (fs.Object, error) {
+	node, err := c.vfs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if node.IsDir() {
+		return nil, errors.New("can't hash directory")
+	}
+	o, ok := node.DirEntry().(fs.Object)
+	if !ok {
+		return nil, errors.New("file not uploaded yet")
+	}
+	return o, nil
+}
+
+// checkFile returns the first hash algorithm in the comma separated
+// list algorithms which the remote supports, and the hash of o with
+// it
+func (c *extensionConn) checkFile(ctx context.Context, o fs.Object, algorithms string) (name string, sum []byte, err error) {
+	hashes := c.vfs.Fs().Hashes()
+	for _, algorithm := range strings.Split(algorithms, ",") {
+		var ht hash.Type
+		if ht.Set(algorithm) != nil || !hashes.Contains(ht) {
+			continue
+		}
+		for _, checkFileAlgorithm := range checkFileAlgorithms {
+			if algorithm == checkFileAlgorithm {
+				sum, err = hashBytes(ctx, o, ht)
+				return algorithm, sum, err
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("none of the hashes %q are supported", algorithms)
+}
+
+// quickCheckSum returns the MD5 of the start of the file at name
+func (c *extensionConn) quickCheckSum(name string) (sum []byte, err error) {
+	fd, err := c.vfs.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(fd, &err)
+	hasher := md5.New()
+	if _, err = io.Copy(hasher, io.LimitReader(fd, quickCheckSize)); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
+
+// hashBytes returns the ht hash of o as bytes
+func hashBytes(ctx context.Context, o fs.Object, ht hash.Type) ([]byte, error) {
+	sum, err := o.Hash(ctx, ht)
+	if err != nil {
+		return nil, err
+	}
+	if sum == "" {
+		return nil, fmt.Errorf("%v hash not available", ht)
+	}
+	return hex.DecodeString(sum)
+}
+
+// cleanPath makes name absolute and clean in the same way as pkg/sftp
+func cleanPath(name string) string {
+	return path.Clean("/" + name)
+}
+
+// replyHeader returns the start of an extended reply to id
+func replyHeader(id uint32) []byte {
+	return marshalUint32([]byte{fxpExtendedReply}, id)
+}
+
+// statusPacket returns a status packet body replying to id
+func statusPacket(id uint32, code uint32, message string) []byte {
+	pkt := marshalUint32(marshalUint32([]byte{fxpStatus}, id), code)
+	return marshalString(marshalString(pkt, message), "")
+}
+
+func marshalUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func marshalString(b []byte, s string) []byte {
+	return append(marshalUint32(b, uint32(len(s))), s...)
+}
+
+func unmarshalUint32(b []byte) (uint32, []byte, bool) {
+	if len(b) < 4 {
+		return 0, b, false
+	}
+	return binary.BigEndian.Uint32(b), b[4:], true
+}
+
+func unmarshalUint64(b []byte) (uint64, []byte, bool) {
+	if len(b) < 8 {
+		return 0, b, false
+	}
+	return binary.BigEndian.Uint64(b), b[8:], true
+}
+
+func unmarshalString(b []byte) (string, []byte, bool) {
+	n, b, ok := unmarshalUint32(b)
+	if !ok || uint32(len(b)) < n {
+		return "", b, false
+	}
+	return string(b[:n]), b[n:], true
+}
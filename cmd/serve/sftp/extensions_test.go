@@ -0,0 +1,243 @@
+//go:build !windows && !darwin && !plan9
+// +build !windows,!darwin,!plan9
+
+package sftp
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// rawSFTP speaks SFTP packets over an ssh session
+type rawSFTP struct {
+	t   *testing.T
+	in  io.Writer
+	out io.Reader
+	id  uint32
+}
+
+// send the packet body pkt
+func (r *rawSFTP) send(pkt []byte) {
+	require.NoError(r.t, binary.Write(r.in, binary.BigEndian, uint32(len(pkt))))
+	_, err := r.in.Write(pkt)
+	require.NoError(r.t, err)
+}
+
+// recv a packet body
+func (r *rawSFTP) recv() []byte {
+	var length uint32
+	require.NoError(r.t, binary.Read(r.out, binary.BigEndian, &length))
+	pkt := make([]byte, length)
+	_, err := io.ReadFull(r.out, pkt)
+	require.NoError(r.t, err)
+	return pkt
+}
+
+// request sends a packet of type typ with a new id and data,
+// returning the type and data of the reply
+func (r *rawSFTP) request(typ byte, data []byte) (byte, []byte) {
+	r.id++
+	r.send(append(marshalUint32([]byte{typ}, r.id), data...))
+	pkt := r.recv()
+	require.True(r.t, len(pkt) >= 5)
+	assert.Equal(r.t, r.id, binary.BigEndian.Uint32(pkt[1:]))
+	return pkt[0], pkt[5:]
+}
+
+// status checks the reply is a status and returns its code
+func (r *rawSFTP) status(typ byte, data []byte) uint32 {
+	require.Equal(r.t, byte(fxpStatus), typ)
+	code, _, ok := unmarshalUint32(data)
+	require.True(r.t, ok)
+	return code
+}
+
+// extendedReply checks the reply is an extended reply called name
+// and returns the rest of it
+func (r *rawSFTP) extendedReply(typ byte, data []byte, name string) []byte {
+	require.Equal(r.t, byte(fxpExtendedReply), typ, "reply %q", data)
+	got, data, ok := unmarshalString(data)
+	require.True(r.t, ok)
+	require.Equal(r.t, name, got)
+	return data
+}
+
+func marshalUint64(b []byte, v uint64) []byte {
+	return marshalUint32(marshalUint32(b, uint32(v>>32)), uint32(v))
+}
+
+func checkFileRequest(request, name, algorithms string, offset, length uint64, blockSize uint32) []byte {
+	data := marshalString(marshalString(nil, request), name)
+	data = marshalString(data, algorithms)
+	data = marshalUint64(data, offset)
+	data = marshalUint64(data, length)
+	return marshalUint32(data, blockSize)
+}
+
+func md5HashRequest(request, name string, offset, length uint64, quickCheck []byte) []byte {
+	data := marshalString(marshalString(nil, request), name)
+	data = marshalUint64(data, offset)
+	data = marshalUint64(data, length)
+	return marshalString(data, string(quickCheck))
+}
+
+func TestExtensions(t *testing.T) {
+	dir := t.TempDir()
+	contents := []byte("hello")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), contents, 0666))
+	md5Sum := md5.Sum(contents)
+	sha256Sum := sha256.Sum256(contents)
+
+	f, err := fs.NewFs(context.Background(), dir)
+	require.NoError(t, err)
+	opt := DefaultOpt
+	opt.ListenAddr = testBindAddress
+	opt.User = testUser
+	opt.Pass = testPass
+	w := newServer(context.Background(), f, &opt)
+	require.NoError(t, w.serve())
+	defer func() {
+		w.Close()
+		w.Wait()
+	}()
+
+	client, err := ssh.Dial("tcp", w.Addr(), &ssh.ClientConfig{
+		User:            testUser,
+		Auth:            []ssh.AuthMethod{ssh.Password(testPass)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	// Ordinary SFTP goes through as before
+	sftpClient, err := sftp.NewClient(client)
+	require.NoError(t, err)
+	in, err := sftpClient.Open("file")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, contents, data)
+	require.NoError(t, sftpClient.Close())
+
+	// Speak SFTP ourselves to use the extensions
+	session, err := client.NewSession()
+	require.NoError(t, err)
+	defer func() {
+		_ = session.Close()
+	}()
+	r := &rawSFTP{t: t}
+	r.in, err = session.StdinPipe()
+	require.NoError(t, err)
+	r.out, err = session.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, session.RequestSubsystem("sftp"))
+
+	// The extensions are advertised
+	r.send(marshalUint32([]byte{1}, 3))
+	pkt := r.recv()
+	require.Equal(t, byte(fxpVersion), pkt[0])
+	extensions := map[string]bool{}
+	for rest := pkt[5:]; len(rest) > 0; {
+		var name string
+		var ok bool
+		name, rest, ok = unmarshalString(rest)
+		require.True(t, ok)
+		_, rest, ok = unmarshalString(rest)
+		require.True(t, ok)
+		extensions[name] = true
+	}
+	assert.True(t, extensions["check-file"])
+	assert.True(t, extensions["md5-hash"])
+	assert.True(t, extensions["posix-rename@openssh.com"])
+
+	t.Run("CheckFileName", func(t *testing.T) {
+		typ, data := r.request(fxpExtended, checkFileRequest("check-file-name", "/file", "sha256,md5", 0, 0, 0))
+		data = r.extendedReply(typ, data, "check-file")
+		algorithm, data, ok := unmarshalString(data)
+		require.True(t, ok)
+		assert.Equal(t, "sha256", algorithm)
+		assert.Equal(t, sha256Sum[:], data)
+
+		// Algorithms the remote doesn't have are skipped
+		typ, data = r.request(fxpExtended, checkFileRequest("check-file-name", "file", "sha384,md5", 0, 5, 5))
+		data = r.extendedReply(typ, data, "check-file")
+		algorithm, data, ok = unmarshalString(data)
+		require.True(t, ok)
+		assert.Equal(t, "md5", algorithm)
+		assert.Equal(t, md5Sum[:], data)
+	})
+
+	t.Run("CheckFileHandle", func(t *testing.T) {
+		open := marshalUint32(marshalUint32(marshalString(nil, "/file"), 1), 0) // read, no attributes
+		typ, data := r.request(fxpOpen, open)
+		require.Equal(t, byte(fxpHandle), typ)
+		handle, _, ok := unmarshalString(data)
+		require.True(t, ok)
+
+		typ, data = r.request(fxpExtended, checkFileRequest("check-file-handle", handle, "md5", 0, 0, 0))
+		data = r.extendedReply(typ, data, "check-file")
+		algorithm, data, ok := unmarshalString(data)
+		require.True(t, ok)
+		assert.Equal(t, "md5", algorithm)
+		assert.Equal(t, md5Sum[:], data)
+
+		assert.Equal(t, uint32(0), r.status(r.request(fxpClose, marshalString(nil, handle))))
+		assert.Equal(t, uint32(fxFailure), r.status(r.request(fxpExtended, checkFileRequest("check-file-handle", handle, "md5", 0, 0, 0))))
+	})
+
+	t.Run("CheckFileUnsupported", func(t *testing.T) {
+		// Only whole files
+		assert.Equal(t, uint32(fxOpUnsupported), r.status(r.request(fxpExtended, checkFileRequest("check-file-name", "/file", "md5", 1, 0, 0))))
+		assert.Equal(t, uint32(fxOpUnsupported), r.status(r.request(fxpExtended, checkFileRequest("check-file-name", "/file", "md5", 0, 4, 0))))
+		assert.Equal(t, uint32(fxOpUnsupported), r.status(r.request(fxpExtended, checkFileRequest("check-file-name", "/file", "md5", 0, 0, 2))))
+		// Only known hashes
+		assert.Equal(t, uint32(fxOpUnsupported), r.status(r.request(fxpExtended, checkFileRequest("check-file-name", "/file", "potato", 0, 0, 0))))
+		// Only files which exist
+		assert.Equal(t, uint32(fxNoSuchFile), r.status(r.request(fxpExtended, checkFileRequest("check-file-name", "/missing", "md5", 0, 0, 0))))
+	})
+
+	t.Run("MD5Hash", func(t *testing.T) {
+		typ, data := r.request(fxpExtended, md5HashRequest("md5-hash", "/file", 0, 0, nil))
+		data = r.extendedReply(typ, data, "md5-hash")
+		sum, _, ok := unmarshalString(data)
+		require.True(t, ok)
+		assert.Equal(t, string(md5Sum[:]), sum)
+
+		// The quick check hash is of the whole file as it is short
+		typ, data = r.request(fxpExtended, md5HashRequest("md5-hash", "/file", 0, 0, md5Sum[:]))
+		data = r.extendedReply(typ, data, "md5-hash")
+		sum, _, ok = unmarshalString(data)
+		require.True(t, ok)
+		assert.Equal(t, string(md5Sum[:]), sum)
+
+		// Nothing is returned if the quick check hash doesn't match
+		typ, data = r.request(fxpExtended, md5HashRequest("md5-hash", "/file", 0, 0, []byte("0123456789abcdef")))
+		data = r.extendedReply(typ, data, "md5-hash")
+		sum, _, ok = unmarshalString(data)
+		require.True(t, ok)
+		assert.Equal(t, "", sum)
+
+		assert.Equal(t, uint32(fxOpUnsupported), r.status(r.request(fxpExtended, md5HashRequest("md5-hash", "/file", 2, 0, nil))))
+	})
+
+	// Other extended requests still go to pkg/sftp
+	typ, data := r.request(fxpExtended, marshalString(nil, "potato@example.com"))
+	assert.Equal(t, uint32(fxOpUnsupported), r.status(typ, data))
+}
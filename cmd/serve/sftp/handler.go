@@ -14,6 +14,14 @@ import (
 	"github.com/rclone/rclone/vfs"
 )
 
+func init() {
+	// The VFS can't make hard links so don't advertise that we can
+	err := sftp.SetSFTPExtensions("posix-rename@openssh.com", "statvfs@openssh.com")
+	if err != nil {
+		fs.Errorf(nil, "Failed to set SFTP extensions: %v", err)
+	}
+}
+
 // vfsHandler converts the VFS to be served by SFTP
 type vfsHandler struct {
 	*vfs.VFS
@@ -73,6 +81,8 @@ func (v vfsHandler) Filecmd(r *sftp.Request) error {
 		if err != nil {
 			return err
 		}
+	case "Link":
+		return sftp.ErrSshFxOpUnsupported
 	case "Symlink":
 		// FIXME
 		// _, err := v.fetch(r.Filepath)
@@ -87,6 +97,34 @@ func (v vfsHandler) Filecmd(r *sftp.Request) error {
 	return nil
 }
 
+// PosixRename renames the file replacing the target if it exists.
+//
+// This is called for the posix-rename@openssh.com extension. The
+// VFS always renames this way so it is the same as Rename.
+func (v vfsHandler) PosixRename(r *sftp.Request) error {
+	return v.Rename(r.Filepath, r.Target)
+}
+
+// StatVFS returns the disk usage of the VFS for the
+// statvfs@openssh.com extension
+func (v vfsHandler) StatVFS(r *sftp.Request) (*sftp.StatVFS, error) {
+	const blockSize = 4096
+	total, _, free := v.Statfs()
+	blocks := uint64(total) / blockSize
+	freeBlocks := uint64(free) / blockSize
+	return &sftp.StatVFS{
+		Bsize:   blockSize,
+		Frsize:  blockSize,
+		Blocks:  blocks,
+		Bfree:   freeBlocks,
+		Bavail:  freeBlocks,
+		Files:   1e9,
+		Ffree:   1e9,
+		Favail:  1e9,
+		Namemax: 255,
+	}, nil
+}
+
 type listerat []os.FileInfo
 
 // Modeled after strings.Reader's ReadAt() implementation
@@ -0,0 +1,63 @@
+//go:build !plan9
+// +build !plan9
+
+package sftp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHandler makes a vfsHandler on a temporary directory
+func newTestHandler(t *testing.T, quota fs.SizeSuffix) (vfsHandler, string) {
+	dir := t.TempDir()
+	f, err := fs.NewFs(context.Background(), dir)
+	require.NoError(t, err)
+	opt := vfsflags.Opt
+	opt.Quota = quota
+	VFS := vfs.New(f, &opt)
+	t.Cleanup(VFS.Shutdown)
+	return vfsHandler{VFS: VFS}, dir
+}
+
+func TestHandlerPosixRename(t *testing.T) {
+	v, dir := newTestHandler(t, 0)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b"), []byte("b"), 0666))
+
+	r := sftp.NewRequest("PosixRename", "/a")
+	r.Target = "/b"
+	require.NoError(t, v.PosixRename(r))
+
+	_, err := os.Stat(filepath.Join(dir, "a"))
+	assert.True(t, os.IsNotExist(err))
+	data, err := os.ReadFile(filepath.Join(dir, "b"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+}
+
+func TestHandlerStatVFS(t *testing.T) {
+	v, _ := newTestHandler(t, 1<<30)
+	stat, err := v.StatVFS(sftp.NewRequest("StatVFS", "/"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1<<30), stat.TotalSpace())
+	assert.Equal(t, uint64(1<<30), stat.FreeSpace())
+}
+
+func TestHandlerLink(t *testing.T) {
+	v, dir := newTestHandler(t, 0)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0666))
+	r := sftp.NewRequest("Link", "/a")
+	r.Target = "/b"
+	assert.Equal(t, sftp.ErrSshFxOpUnsupported, v.Filecmd(r))
+}
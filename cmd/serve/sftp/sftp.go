@@ -71,6 +71,17 @@ The server will respond to a small number of shell commands, mainly
 md5sum, sha1sum and df, which enable it to provide support for checksums
 and the about feature when accessed from an sftp remote.
 
+It also supports the posix-rename@openssh.com and statvfs@openssh.com
+SFTP extensions, so OpenSSH clients and sshfs can rename over existing
+files and see how much free space the remote has. Hard links can't be
+made.
+
+The check-file and md5-hash SFTP extensions are supported too, so
+clients such as WinSCP and lftp can read the checksums of files
+without downloading them. These return the hashes the remote already
+has, so only work for hashes the remote supports and only for whole
+files.
+
 Note that this server uses standard 32 KiB packet payload size, which
 means you must not configure the client to expect anything else, e.g.
 with the [chunk_size](/sftp/#sftp-chunk-size) option on an sftp remote.
@@ -34,6 +34,9 @@ var (
 	_ sftp.FileWriter = vfsHandler{}
 	_ sftp.FileCmder  = vfsHandler{}
 	_ sftp.FileLister = vfsHandler{}
+
+	_ sftp.PosixRenameFileCmder = vfsHandler{}
+	_ sftp.StatVFSFileCmder     = vfsHandler{}
 )
 
 // TestSftp runs the sftp server then runs the unit tests for the
@@ -0,0 +1,320 @@
+package webdav
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+	"golang.org/x/net/webdav"
+)
+
+// lockSystem is a webdav.LockSystem which saves its locks in a
+// stateStore so clients such as Office and Finder keep them over a
+// restart of the server.
+//
+// All the locks are exclusive write locks as that is all the webdav
+// handler asks for.
+type lockSystem struct {
+	store  *kv.Store
+	prefix string // for the keys of the locks in the store
+	mu     sync.Mutex
+	locks  map[string]*lock // by token
+}
+
+// lock is a single lock as saved in the store
+type lock struct {
+	Token     string
+	Root      string
+	Expiry    time.Time // zero if the lock never expires
+	OwnerXML  string
+	ZeroDepth bool
+	temporary bool // not saved - see isTemporary
+	held      bool // set while a request is using the lock
+}
+
+// check interface
+var _ webdav.LockSystem = (*lockSystem)(nil)
+
+// newLockSystem makes a lockSystem reading any saved locks for ns
+// from store
+func newLockSystem(store *kv.Store, ns string) (*lockSystem, error) {
+	ls := &lockSystem{
+		store:  store,
+		prefix: "lock\x00" + ns + "\x00",
+		locks:  map[string]*lock{},
+	}
+	list := kvList{prefix: ls.prefix}
+	if err := store.Do(false, &list); err != nil {
+		return nil, fmt.Errorf("failed to read WebDAV locks: %w", err)
+	}
+	for key, value := range list.values {
+		var l lock
+		if err := json.Unmarshal(value, &l); err != nil {
+			fs.Errorf(nil, "Ignoring corrupted WebDAV lock %q: %v", key, err)
+			continue
+		}
+		ls.locks[l.Token] = &l
+	}
+	ls.mu.Lock()
+	ls.collectExpired(time.Now())
+	ls.mu.Unlock()
+	return ls, nil
+}
+
+// isTemporary returns true for the locks the webdav handler makes
+// for the length of a request from a client which doesn't use locks.
+// These have no owner and never expire and aren't worth saving.
+func isTemporary(details webdav.LockDetails) bool {
+	return details.Duration < 0 && details.OwnerXML == ""
+}
+
+// covers returns true if l applies to name
+func (l *lock) covers(name string) bool {
+	if l.Root == name {
+		return true
+	}
+	if l.ZeroDepth {
+		return false
+	}
+	return l.Root == "/" || strings.HasPrefix(name, l.Root+"/")
+}
+
+// details returns the LockDetails of l at time now
+func (l *lock) details(now time.Time) webdav.LockDetails {
+	duration := time.Duration(-1)
+	if !l.Expiry.IsZero() {
+		duration = l.Expiry.Sub(now)
+	}
+	return webdav.LockDetails{
+		Root:      l.Root,
+		Duration:  duration,
+		OwnerXML:  l.OwnerXML,
+		ZeroDepth: l.ZeroDepth,
+	}
+}
+
+// save writes l to the store
+func (ls *lockSystem) save(l *lock) error {
+	if l.temporary {
+		return nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return ls.store.Put(kv.Item{Key: ls.prefix + l.Token, Value: data})
+}
+
+// remove deletes l
+//
+// Call with ls.mu held.
+func (ls *lockSystem) remove(l *lock) {
+	delete(ls.locks, l.Token)
+	if l.temporary {
+		return
+	}
+	if err := ls.store.Delete(ls.prefix + l.Token); err != nil {
+		fs.Errorf(nil, "Failed to remove WebDAV lock %q: %v", l.Token, err)
+	}
+}
+
+// collectExpired removes the locks which have expired by now
+//
+// Call with ls.mu held.
+func (ls *lockSystem) collectExpired(now time.Time) {
+	for _, l := range ls.locks {
+		if !l.Expiry.IsZero() && now.After(l.Expiry) {
+			ls.remove(l)
+		}
+	}
+}
+
+// lookup returns the lock which covers name, provided that it
+// matches at least one of the conditions and isn't held already.
+//
+// Call with ls.mu held.
+func (ls *lockSystem) lookup(name string, conditions ...webdav.Condition) *lock {
+	for _, c := range conditions {
+		l := ls.locks[c.Token]
+		if l != nil && !l.held && l.covers(name) {
+			return l
+		}
+	}
+	return nil
+}
+
+// Confirm confirms that the caller can claim all of the locks
+// specified by the given conditions, and that holding the union of
+// all of those locks gives exclusive access to all of the named
+// resources.
+func (ls *lockSystem) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (release func(), err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.collectExpired(now)
+
+	var l0, l1 *lock
+	if name0 != "" {
+		if l0 = ls.lookup(path.Clean(name0), conditions...); l0 == nil {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+	if name1 != "" {
+		if l1 = ls.lookup(path.Clean(name1), conditions...); l1 == nil {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+	if l1 == l0 {
+		l1 = nil
+	}
+	for _, l := range []*lock{l0, l1} {
+		if l != nil {
+			l.held = true
+		}
+	}
+	return func() {
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		for _, l := range []*lock{l0, l1} {
+			if l != nil {
+				l.held = false
+			}
+		}
+	}, nil
+}
+
+// Create creates a lock with the given depth, duration, owner and
+// root (name).
+func (ls *lockSystem) Create(now time.Time, details webdav.LockDetails) (token string, err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.collectExpired(now)
+
+	newLock := &lock{
+		Token:     "urn:uuid:" + uuid.New().String(),
+		Root:      path.Clean(details.Root),
+		OwnerXML:  details.OwnerXML,
+		ZeroDepth: details.ZeroDepth,
+		temporary: isTemporary(details),
+	}
+	if details.Duration >= 0 {
+		newLock.Expiry = now.Add(details.Duration)
+	}
+	for _, l := range ls.locks {
+		if l.covers(newLock.Root) || newLock.covers(l.Root) {
+			return "", webdav.ErrLocked
+		}
+	}
+	if err := ls.save(newLock); err != nil {
+		return "", fmt.Errorf("failed to save WebDAV lock: %w", err)
+	}
+	ls.locks[newLock.Token] = newLock
+	return newLock.Token, nil
+}
+
+// Refresh refreshes the lock with the given token.
+func (ls *lockSystem) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.collectExpired(now)
+
+	l := ls.locks[token]
+	if l == nil {
+		return webdav.LockDetails{}, webdav.ErrNoSuchLock
+	}
+	if l.held {
+		return webdav.LockDetails{}, webdav.ErrLocked
+	}
+	l.Expiry = time.Time{}
+	if duration >= 0 {
+		l.Expiry = now.Add(duration)
+	}
+	if err := ls.save(l); err != nil {
+		return webdav.LockDetails{}, fmt.Errorf("failed to save WebDAV lock: %w", err)
+	}
+	return l.details(now), nil
+}
+
+// Unlock unlocks the lock with the given token.
+func (ls *lockSystem) Unlock(now time.Time, token string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.collectExpired(now)
+
+	l := ls.locks[token]
+	if l == nil {
+		return webdav.ErrNoSuchLock
+	}
+	if l.held {
+		return webdav.ErrLocked
+	}
+	ls.remove(l)
+	return nil
+}
+
+// lockDiscoveryName is the name of the property listing the locks
+var lockDiscoveryName = xml.Name{Space: "DAV:", Local: "lockdiscovery"}
+
+// discovery returns the DAV:lockdiscovery property for name, or false
+// if there are no locks on it.
+//
+// The webdav handler doesn't provide this property itself as it can't
+// list the locks of a LockSystem.
+func (ls *lockSystem) discovery(name string) (prop webdav.Property, ok bool) {
+	name = path.Clean(name)
+	now := time.Now()
+	ls.mu.Lock()
+	var locks []*lock
+	for _, l := range ls.locks {
+		if !l.temporary && l.covers(name) {
+			locks = append(locks, l)
+		}
+	}
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Root < locks[j].Root
+	})
+	var out strings.Builder
+	for _, l := range locks {
+		details := l.details(now)
+		depth := "infinity"
+		if details.ZeroDepth {
+			depth = "0"
+		}
+		timeout := "Infinite"
+		if details.Duration >= 0 {
+			timeout = fmt.Sprintf("Second-%d", details.Duration/time.Second)
+		}
+		fmt.Fprintf(&out, `<D:activelock xmlns:D="DAV:">`+
+			`<D:locktype><D:write/></D:locktype>`+
+			`<D:lockscope><D:exclusive/></D:lockscope>`+
+			`<D:depth>%s</D:depth>`+
+			`<D:owner>%s</D:owner>`+
+			`<D:timeout>%s</D:timeout>`+
+			`<D:locktoken><D:href>%s</D:href></D:locktoken>`+
+			`<D:lockroot><D:href>%s</D:href></D:lockroot>`+
+			`</D:activelock>`,
+			depth, details.OwnerXML, timeout, xmlEscape(l.Token), xmlEscape(details.Root))
+	}
+	ls.mu.Unlock()
+	if len(locks) == 0 {
+		return prop, false
+	}
+	return webdav.Property{
+		XMLName:  lockDiscoveryName,
+		InnerXML: []byte(out.String()),
+	}, true
+}
+
+// xmlEscape escapes s for use in XML text
+func xmlEscape(s string) string {
+	var out strings.Builder
+	_ = xml.EscapeText(&out, []byte(s))
+	return out.String()
+}
@@ -0,0 +1,210 @@
+package webdav
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+	"github.com/rclone/rclone/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/webdav"
+)
+
+// newTestLockSystem makes a lockSystem using a store of storeType
+func newTestLockSystem(t *testing.T, storeType string) (*lockSystem, *kv.Store) {
+	store, err := newStateStore(context.Background(), nil, storeType)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, store.Close())
+	})
+	ls, err := newLockSystem(store, "test")
+	require.NoError(t, err)
+	return ls, store
+}
+
+func TestLockSystem(t *testing.T) {
+	ls, _ := newTestLockSystem(t, kv.StoreMemory)
+	now := time.Now()
+
+	token, err := ls.Create(now, webdav.LockDetails{
+		Root:     "/dir",
+		Duration: time.Minute,
+		OwnerXML: "<D:href>me</D:href>",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, "", token)
+
+	// Conflicting locks
+	for _, details := range []webdav.LockDetails{
+		{Root: "/dir", Duration: -1, ZeroDepth: true},
+		{Root: "/dir/file", Duration: -1, ZeroDepth: true},
+		{Root: "/", Duration: time.Minute},
+	} {
+		_, err = ls.Create(now, details)
+		assert.Equal(t, webdav.ErrLocked, err, details.Root)
+	}
+
+	// Non conflicting locks
+	for _, details := range []webdav.LockDetails{
+		{Root: "/", Duration: -1, ZeroDepth: true},
+		{Root: "/dir2", Duration: -1, ZeroDepth: true},
+	} {
+		token2, err := ls.Create(now, details)
+		require.NoError(t, err, details.Root)
+		require.NoError(t, ls.Unlock(now, token2))
+	}
+
+	// Confirm needs the token
+	_, err = ls.Confirm(now, "/dir/file", "")
+	assert.Equal(t, webdav.ErrConfirmationFailed, err)
+	release, err := ls.Confirm(now, "/dir/file", "", webdav.Condition{Token: token})
+	require.NoError(t, err)
+
+	// Held locks can't be used again until released
+	_, err = ls.Confirm(now, "/dir", "", webdav.Condition{Token: token})
+	assert.Equal(t, webdav.ErrConfirmationFailed, err)
+	assert.Equal(t, webdav.ErrLocked, ls.Unlock(now, token))
+	release()
+
+	// Refresh
+	details, err := ls.Refresh(now, token, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "/dir", details.Root)
+	assert.Equal(t, time.Hour, details.Duration)
+	_, err = ls.Refresh(now, "unknown", time.Hour)
+	assert.Equal(t, webdav.ErrNoSuchLock, err)
+
+	// Lock discovery
+	prop, ok := ls.discovery("/dir/file")
+	require.True(t, ok)
+	assert.Equal(t, xml.Name{Space: "DAV:", Local: "lockdiscovery"}, prop.XMLName)
+	assert.Contains(t, string(prop.InnerXML), token)
+	assert.Contains(t, string(prop.InnerXML), "<D:href>me</D:href>")
+	assert.Contains(t, string(prop.InnerXML), "<D:depth>infinity</D:depth>")
+	_, ok = ls.discovery("/dir2")
+	assert.False(t, ok)
+
+	// Unlock
+	require.NoError(t, ls.Unlock(now, token))
+	assert.Equal(t, webdav.ErrNoSuchLock, ls.Unlock(now, token))
+	_, ok = ls.discovery("/dir/file")
+	assert.False(t, ok)
+}
+
+func TestLockSystemExpiry(t *testing.T) {
+	ls, _ := newTestLockSystem(t, kv.StoreMemory)
+	now := time.Now()
+
+	token, err := ls.Create(now, webdav.LockDetails{Root: "/file", Duration: time.Second})
+	require.NoError(t, err)
+	_, err = ls.Create(now, webdav.LockDetails{Root: "/file", Duration: time.Second})
+	assert.Equal(t, webdav.ErrLocked, err)
+
+	later := now.Add(2 * time.Second)
+	assert.Equal(t, webdav.ErrNoSuchLock, ls.Unlock(later, token))
+	_, err = ls.Create(later, webdav.LockDetails{Root: "/file", Duration: time.Second})
+	assert.NoError(t, err)
+}
+
+func TestLockSystemDisk(t *testing.T) {
+	if !kv.Supported() {
+		t.Skip("kv not supported on this OS")
+	}
+	ls, store := newTestLockSystem(t, kv.StoreDisk)
+	require.True(t, store.Persistent())
+	now := time.Now()
+
+	token, err := ls.Create(now, webdav.LockDetails{Root: "/file", Duration: time.Hour, OwnerXML: "owner"})
+	require.NoError(t, err)
+	tempToken, err := ls.Create(now, webdav.LockDetails{Root: "/other", Duration: -1, ZeroDepth: true})
+	require.NoError(t, err)
+
+	// A new lock system reads the saved locks but not the temporary ones
+	ls2, err := newLockSystem(store, "test")
+	require.NoError(t, err)
+	require.Contains(t, ls2.locks, token)
+	assert.NotContains(t, ls2.locks, tempToken)
+	assert.Equal(t, "/file", ls2.locks[token].Root)
+	assert.Equal(t, "owner", ls2.locks[token].OwnerXML)
+	_, err = ls2.Create(now, webdav.LockDetails{Root: "/file", Duration: -1, ZeroDepth: true})
+	assert.Equal(t, webdav.ErrLocked, err)
+
+	// Locks of another namespace aren't read
+	ls3, err := newLockSystem(store, "other")
+	require.NoError(t, err)
+	assert.Empty(t, ls3.locks)
+
+	// Unlocking removes the lock from the store
+	require.NoError(t, ls2.Unlock(now, token))
+	ls4, err := newLockSystem(store, "test")
+	require.NoError(t, err)
+	assert.Empty(t, ls4.locks)
+}
+
+func TestWebDAVLockSystemPerVFS(t *testing.T) {
+	ctx := context.Background()
+	store, err := newStateStore(ctx, nil, kv.StoreMemory)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, store.Close())
+	}()
+	w := &WebDAV{store: store, locks: map[string]*lockSystem{}}
+	newVFS := func(root string) *vfs.VFS {
+		f, err := fs.NewFs(ctx, root)
+		require.NoError(t, err)
+		VFS := vfs.New(f, nil)
+		t.Cleanup(VFS.Shutdown)
+		return VFS
+	}
+
+	ls1, err := w.lockSystem(newVFS(":memory:user1"))
+	require.NoError(t, err)
+	ls1again, err := w.lockSystem(newVFS(":memory:user1"))
+	require.NoError(t, err)
+	ls2, err := w.lockSystem(newVFS(":memory:user2"))
+	require.NoError(t, err)
+	assert.Same(t, ls1, ls1again)
+	assert.NotSame(t, ls1, ls2)
+
+	// A lock taken by one user doesn't block another
+	now := time.Now()
+	details := webdav.LockDetails{Root: "/file", Duration: time.Minute, ZeroDepth: true}
+	_, err = ls1.Create(now, details)
+	require.NoError(t, err)
+	_, err = ls1again.Create(now, details)
+	assert.Equal(t, webdav.ErrLocked, err)
+	_, err = ls2.Create(now, details)
+	assert.NoError(t, err)
+}
+
+func TestStateStoreMoveTree(t *testing.T) {
+	store, err := newStateStore(context.Background(), nil, kv.StoreMemory)
+	require.NoError(t, err)
+	for _, key := range []string{"a", "a/b", "a/b/c", "ab", "b"} {
+		require.NoError(t, store.Put(kv.Item{Key: key, Value: []byte(key)}))
+	}
+	require.NoError(t, store.Do(true, &kvMoveTree{key: "a", newKey: "z"}))
+	list := kvList{}
+	require.NoError(t, store.Do(false, &list))
+	assert.Equal(t, map[string][]byte{
+		"ab":    []byte("ab"),
+		"b":     []byte("b"),
+		"z":     []byte("a"),
+		"z/b":   []byte("a/b"),
+		"z/b/c": []byte("a/b/c"),
+	}, list.values)
+
+	require.NoError(t, store.Do(true, &kvMoveTree{key: "z"}))
+	require.NoError(t, store.Do(false, &list))
+	assert.Equal(t, map[string][]byte{
+		"ab": []byte("ab"),
+		"b":  []byte("b"),
+	}, list.values)
+
+	_, err = newStateStore(context.Background(), nil, "potato")
+	assert.ErrorContains(t, err, "unknown --state-store")
+}
@@ -0,0 +1,152 @@
+package webdav
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+	"golang.org/x/net/webdav"
+)
+
+// deadProps gives access to the dead properties of the files of a
+// VFS, that is the properties set by clients with PROPPATCH.
+type deadProps struct {
+	store *kv.Store
+	ns    string // for the keys of the properties in the store
+}
+
+// key returns the store key for the properties of name
+func (p deadProps) key(name string) string {
+	return "prop\x00" + p.ns + "\x00" + path.Clean("/"+name)
+}
+
+// get returns the dead properties of name
+func (p deadProps) get(name string) (map[xml.Name]webdav.Property, error) {
+	data, err := p.store.Get(p.key(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WebDAV properties: %w", err)
+	}
+	props, err := decodeProps(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode WebDAV properties: %w", err)
+	}
+	return props, nil
+}
+
+// patch applies patches to the dead properties of name
+func (p deadProps) patch(name string, patches []webdav.Proppatch) error {
+	return p.store.Do(true, &kvPatchProps{key: p.key(name), patches: patches})
+}
+
+// rename moves the properties of oldName and anything below it to
+// newName
+func (p deadProps) rename(oldName, newName string) {
+	err := p.store.Do(true, &kvMoveTree{key: p.key(oldName), newKey: p.key(newName)})
+	if err != nil {
+		fs.Errorf(oldName, "Failed to rename WebDAV properties: %v", err)
+	}
+}
+
+// remove deletes the properties of name and anything below it
+func (p deadProps) remove(name string) {
+	err := p.store.Do(true, &kvMoveTree{key: p.key(name)})
+	if err != nil {
+		fs.Errorf(name, "Failed to remove WebDAV properties: %v", err)
+	}
+}
+
+// decodeProps decodes properties saved with encodeProps
+func decodeProps(data []byte) (map[xml.Name]webdav.Property, error) {
+	props := map[xml.Name]webdav.Property{}
+	if len(data) == 0 {
+		return props, nil
+	}
+	var list []webdav.Property
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	for _, prop := range list {
+		props[prop.XMLName] = prop
+	}
+	return props, nil
+}
+
+// encodeProps encodes properties for saving
+func encodeProps(props map[xml.Name]webdav.Property) ([]byte, error) {
+	list := make([]webdav.Property, 0, len(props))
+	for _, prop := range props {
+		list = append(list, prop)
+	}
+	return json.Marshal(list)
+}
+
+// kvPatchProps applies patches to the properties saved under key
+type kvPatchProps struct {
+	key     string
+	patches []webdav.Proppatch
+}
+
+func (op *kvPatchProps) Do(ctx context.Context, b kv.Bucket) error {
+	props, err := decodeProps(b.Get([]byte(op.key)))
+	if err != nil {
+		return err
+	}
+	for _, patch := range op.patches {
+		for _, prop := range patch.Props {
+			// The lock discovery property is made from the
+			// locks. It only arrives here when COPY copies the
+			// properties of a locked file, PROPPATCH refuses it
+			// before then.
+			if prop.XMLName == lockDiscoveryName {
+				continue
+			}
+			if patch.Remove {
+				delete(props, prop.XMLName)
+			} else {
+				props[prop.XMLName] = prop
+			}
+		}
+	}
+	if len(props) == 0 {
+		return b.Delete([]byte(op.key))
+	}
+	data, err := encodeProps(props)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(op.key), data)
+}
+
+// DeadProps returns a copy of the dead properties held, including the
+// locks on the file.
+func (h Handle) DeadProps() (map[xml.Name]webdav.Property, error) {
+	props, err := h.props.get(h.name)
+	if err != nil {
+		return nil, err
+	}
+	if prop, ok := h.locks.discovery(h.name); ok {
+		props[lockDiscoveryName] = prop
+	}
+	return props, nil
+}
+
+// Patch patches the dead properties held.
+//
+// The patches are applied all together or not at all.
+func (h Handle) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	if err := h.props.patch(h.name, patches); err != nil {
+		return nil, err
+	}
+	pstat := webdav.Propstat{Status: http.StatusOK}
+	for _, patch := range patches {
+		for _, prop := range patch.Props {
+			pstat.Props = append(pstat.Props, webdav.Property{XMLName: prop.XMLName})
+		}
+	}
+	return []webdav.Propstat{pstat}, nil
+}
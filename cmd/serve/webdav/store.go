@@ -0,0 +1,63 @@
+package webdav
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+)
+
+// newStateStore makes the store for the locks and dead properties of
+// the server for the remote f of the type given.
+//
+// They survive a restart of the server if it is kept on disk.
+func newStateStore(ctx context.Context, f fs.Fs, storeType string) (*kv.Store, error) {
+	return kv.NewStore(ctx, "serve-webdav", f, storeType, "--state-store", "WebDAV locks and properties")
+}
+
+// kvList reads all the values with keys starting with prefix
+type kvList struct {
+	prefix string
+	values map[string][]byte
+}
+
+func (op *kvList) Do(ctx context.Context, b kv.Bucket) error {
+	op.values = map[string][]byte{}
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(op.prefix)); k != nil && strings.HasPrefix(string(k), op.prefix); k, v = c.Next() {
+		op.values[string(k)] = append([]byte(nil), v...)
+	}
+	return nil
+}
+
+// kvMoveTree moves the value of key and of any key below it, that is
+// starting with key + "/", to newKey. If newKey is empty they are
+// deleted instead.
+type kvMoveTree struct {
+	key    string
+	newKey string
+}
+
+func (op *kvMoveTree) Do(ctx context.Context, b kv.Bucket) error {
+	list := kvList{prefix: op.key}
+	if err := list.Do(ctx, b); err != nil {
+		return err
+	}
+	for k, v := range list.values {
+		tail := k[len(op.key):]
+		if tail != "" && tail[0] != '/' {
+			continue
+		}
+		if err := b.Delete([]byte(k)); err != nil {
+			return err
+		}
+		if op.newKey == "" {
+			continue
+		}
+		if err := b.Put([]byte(op.newKey+tail), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
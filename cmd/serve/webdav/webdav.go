@@ -5,39 +5,74 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rclone/rclone/cmd"
-	"github.com/rclone/rclone/cmd/serve/httplib"
-	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
+	"github.com/rclone/rclone/cmd/serve/http/data"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/hash"
+	httplib "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/lib/http/auth"
 	"github.com/rclone/rclone/lib/http/serve"
+	"github.com/rclone/rclone/lib/kv"
 	"github.com/rclone/rclone/vfs"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/webdav"
 )
 
+// Options required for webdav server
+type Options struct {
+	Auth       auth.Options
+	HTTP       httplib.Options
+	Template   data.Options
+	StateStore string // where to keep locks and dead properties - memory or disk
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	Auth:       auth.DefaultOpt,
+	HTTP:       httplib.DefaultOpt,
+	StateStore: kv.StoreDisk,
+}
+
+// Opt is options set by command line flags
+var Opt = DefaultOpt
+
 var (
 	hashName      string
 	hashType      = hash.None
 	disableGETDir = false
 )
 
+// WebDAV methods which need registering with the router
+var webdavMethods = []string{
+	"COPY", "LOCK", "MKCOL", "MOVE", "PROPFIND", "PROPPATCH", "UNLOCK",
+}
+
 func init() {
+	for _, method := range webdavMethods {
+		chi.RegisterMethod(method)
+	}
 	flagSet := Command.Flags()
-	httpflags.AddFlags(flagSet)
+	data.AddFlags(flagSet, "", &Opt.Template)
+	httplib.AddFlagsPrefix(flagSet, "", &Opt.HTTP)
+	auth.AddFlagsPrefix(flagSet, "", &Opt.Auth)
 	vfsflags.AddFlags(flagSet)
 	proxyflags.AddFlags(flagSet)
 	flags.StringVarP(flagSet, &hashName, "etag-hash", "", "", "Which hash to use for the ETag, or auto or blank for off")
 	flags.BoolVarP(flagSet, &disableGETDir, "disable-dir-list", "", false, "Disable HTML directory list on GET request for a directory")
+	flags.StringVarP(flagSet, &Opt.StateStore, "state-store", "", Opt.StateStore, "Where to keep locks and dead properties: memory or disk")
 }
 
 // Command definition for cobra
@@ -60,7 +95,24 @@ supported hash on the backend or you can use a named hash such as
 "MD5" or "SHA-1". Use the [hashsum](/commands/rclone_hashsum/) command
 to see the full list.
 
-` + httplib.Help + vfs.Help + proxy.Help,
+#### --state-store
+
+This controls where the locks taken by clients with LOCK and the
+properties they set with PROPPATCH (dead properties) are kept.
+
+If set to "disk" (the default) they are kept in a database in the
+rclone cache directory so they survive a restart of the server.
+Clients such as Microsoft Office and macOS Finder rely on their locks
+and properties still being there when they reconnect.
+
+If set to "memory" they are kept in memory and lost when the server
+stops.
+
+Locks and properties are returned from PROPFIND, the locks in the
+DAV:lockdiscovery property. Properties follow their file when it is
+moved and are deleted with it.
+
+` + httplib.Help + data.Help + auth.Help + vfs.Help + proxy.Help,
 	RunE: func(command *cobra.Command, args []string) error {
 		var f fs.Fs
 		if proxyflags.Opt.AuthProxy == "" {
@@ -82,8 +134,11 @@ to see the full list.
 			fs.Debugf(f, "Using hash %v for ETag", hashType)
 		}
 		cmd.Run(false, false, command, func() error {
-			s := newWebDAV(context.Background(), f, &httpflags.Opt)
-			err := s.serve()
+			s, err := newWebDAV(context.Background(), f, &Opt)
+			if err != nil {
+				return err
+			}
+			err = s.serve()
 			if err != nil {
 				return err
 			}
@@ -108,40 +163,64 @@ to see the full list.
 // overwriting another existing file or directory is an error is OS-dependent.
 type WebDAV struct {
 	*httplib.Server
-	f             fs.Fs
-	_vfs          *vfs.VFS // don't use directly, use getVFS
-	webdavhandler *webdav.Handler
-	proxy         *proxy.Proxy
-	ctx           context.Context // for global config
+	f            fs.Fs
+	_vfs         *vfs.VFS  // don't use directly, use getVFS
+	store        *kv.Store // for locks and dead properties
+	locksMu      sync.Mutex
+	locks        map[string]*lockSystem // by namespace - use lockSystem
+	proxy        *proxy.Proxy
+	ctx          context.Context    // for global config
+	HTMLTemplate *template.Template // HTML template for web interface
 }
 
 // check interface
 var _ webdav.FileSystem = (*WebDAV)(nil)
 
 // Make a new WebDAV to serve the remote
-func newWebDAV(ctx context.Context, f fs.Fs, opt *httplib.Options) *WebDAV {
-	w := &WebDAV{
-		f:   f,
-		ctx: ctx,
+func newWebDAV(ctx context.Context, f fs.Fs, opt *Options) (w *WebDAV, err error) {
+	w = &WebDAV{
+		f:     f,
+		ctx:   ctx,
+		locks: map[string]*lockSystem{},
+	}
+	w.HTMLTemplate, err = data.GetTemplate(opt.Template.Template)
+	if err != nil {
+		return nil, err
 	}
+	authOpt := opt.Auth
 	if proxyflags.Opt.AuthProxy != "" {
 		w.proxy = proxy.New(ctx, &proxyflags.Opt)
 		// override auth
-		copyOpt := *opt
-		copyOpt.Auth = w.auth
-		opt = &copyOpt
+		authOpt.Auth = w.auth
 	} else {
 		w._vfs = vfs.New(f, &vfsflags.Opt)
 	}
-	w.Server = httplib.NewServer(http.HandlerFunc(w.handler), opt)
-	webdavHandler := &webdav.Handler{
-		Prefix:     w.Server.Opt.BaseURL,
-		FileSystem: w,
-		LockSystem: webdav.NewMemLS(),
-		Logger:     w.logRequest, // FIXME
+	w.store, err = newStateStore(ctx, f, opt.StateStore)
+	if err != nil {
+		return nil, err
 	}
-	w.webdavhandler = webdavHandler
-	return w
+	// Read the saved locks now if there is only one VFS so any
+	// error is reported at startup
+	if w._vfs != nil {
+		_, err = w.lockSystem(w._vfs)
+		if err != nil {
+			_ = w.store.Close()
+			return nil, err
+		}
+	}
+	w.Server, err = httplib.NewServer(ctx, opt.HTTP, authOpt)
+	if err != nil {
+		_ = w.store.Close()
+		return nil, err
+	}
+
+	router := w.Router()
+	router.Use(
+		middleware.SetHeader("Accept-Ranges", "bytes"),
+		middleware.SetHeader("Server", "rclone/"+fs.Version),
+	)
+	router.Handle("/*", http.HandlerFunc(w.handler))
+	return w, nil
 }
 
 // Gets the VFS in use for this request
@@ -149,7 +228,7 @@ func (w *WebDAV) getVFS(ctx context.Context) (VFS *vfs.VFS, err error) {
 	if w._vfs != nil {
 		return w._vfs, nil
 	}
-	value := ctx.Value(httplib.ContextAuthKey)
+	value := ctx.Value(auth.ContextAuthKey)
 	if value == nil {
 		return nil, errors.New("no VFS found in context")
 	}
@@ -170,17 +249,40 @@ func (w *WebDAV) auth(user, pass string) (value interface{}, err error) {
 }
 
 func (w *WebDAV) handler(rw http.ResponseWriter, r *http.Request) {
-	urlPath, ok := w.Path(rw, r)
-	if !ok {
-		return
-	}
+	urlPath := r.URL.Path
 	isDir := strings.HasSuffix(urlPath, "/")
 	remote := strings.Trim(urlPath, "/")
 	if !disableGETDir && (r.Method == "GET" || r.Method == "HEAD") && isDir {
 		w.serveDir(rw, r, remote)
 		return
 	}
-	w.webdavhandler.ServeHTTP(rw, r)
+	// The webdav handler needs the full path as it strips the
+	// prefix itself and uses it to make the hrefs it returns
+	if prefix := w.BaseURL(); prefix != "" {
+		r.URL.Path = prefix + r.URL.Path
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = prefix + r.URL.RawPath
+		}
+	}
+	// The locks are kept per VFS, so per user with the auth proxy
+	VFS, err := w.getVFS(r.Context())
+	if err != nil {
+		http.Error(rw, "Root directory not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to serve WebDAV request: %v", err)
+		return
+	}
+	locks, err := w.lockSystem(VFS)
+	if err != nil {
+		serve.Error(remote, rw, "Failed to read WebDAV locks", err)
+		return
+	}
+	webdavHandler := &webdav.Handler{
+		Prefix:     w.BaseURL(),
+		FileSystem: w,
+		LockSystem: locks,
+		Logger:     w.logRequest, // FIXME
+	}
+	webdavHandler.ServeHTTP(rw, r)
 }
 
 // serveDir serves a directory index at dirRemote
@@ -232,16 +334,51 @@ func (w *WebDAV) serveDir(rw http.ResponseWriter, r *http.Request, dirRemote str
 
 // serve runs the http server in the background.
 //
-// Use s.Close() and s.Wait() to shutdown server
+// Use w.Shutdown() to stop the server
 func (w *WebDAV) serve() error {
 	err := w.Serve()
 	if err != nil {
 		return err
 	}
-	fs.Logf(w.f, "WebDav Server started on %s", w.URL())
+	for _, url := range w.URLs() {
+		fs.Logf(w.f, "WebDav Server started on %s", url)
+	}
 	return nil
 }
 
+// Shutdown stops the server and closes the store of locks and
+// properties
+func (w *WebDAV) Shutdown() error {
+	err := w.Server.Shutdown()
+	if closeErr := w.store.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// lockSystem returns the locks of the files in VFS, reading any saved
+// ones the first time it is used
+func (w *WebDAV) lockSystem(VFS *vfs.VFS) (*lockSystem, error) {
+	ns := fs.ConfigString(VFS.Fs())
+	w.locksMu.Lock()
+	defer w.locksMu.Unlock()
+	ls := w.locks[ns]
+	if ls == nil {
+		var err error
+		ls, err = newLockSystem(w.store, ns)
+		if err != nil {
+			return nil, err
+		}
+		w.locks[ns] = ls
+	}
+	return ls, nil
+}
+
+// deadProps returns the dead properties of the files in VFS
+func (w *WebDAV) deadProps(VFS *vfs.VFS) deadProps {
+	return deadProps{store: w.store, ns: fs.ConfigString(VFS.Fs())}
+}
+
 // logRequest is called by the webdav module on every request
 func (w *WebDAV) logRequest(r *http.Request, err error) {
 	fs.Infof(r.URL.Path, "%s from %s", r.Method, r.RemoteAddr)
@@ -269,11 +406,27 @@ func (w *WebDAV) OpenFile(ctx context.Context, name string, flags int, perm os.F
 	if err != nil {
 		return nil, err
 	}
+	// PROPPATCH opens with just O_RDWR but only changes the
+	// properties, which aren't stored in the file. The VFS won't
+	// open directories for writing and might truncate files.
+	if flags == os.O_RDWR && !VFS.Opt.ReadOnly {
+		flags = os.O_RDONLY
+	}
 	f, err := VFS.OpenFile(name, flags, perm)
 	if err != nil {
 		return nil, err
 	}
-	return Handle{f}, nil
+	locks, err := w.lockSystem(VFS)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return Handle{
+		Handle: f,
+		props:  w.deadProps(VFS),
+		locks:  locks,
+		name:   name,
+	}, nil
 }
 
 // RemoveAll removes a file or a directory and its contents
@@ -291,6 +444,7 @@ func (w *WebDAV) RemoveAll(ctx context.Context, name string) (err error) {
 	if err != nil {
 		return err
 	}
+	w.deadProps(VFS).remove(name)
 	return nil
 }
 
@@ -301,7 +455,12 @@ func (w *WebDAV) Rename(ctx context.Context, oldName, newName string) (err error
 	if err != nil {
 		return err
 	}
-	return VFS.Rename(oldName, newName)
+	err = VFS.Rename(oldName, newName)
+	if err != nil {
+		return err
+	}
+	w.deadProps(VFS).rename(oldName, newName)
+	return nil
 }
 
 // Stat returns info about the file or directory
@@ -321,6 +480,9 @@ func (w *WebDAV) Stat(ctx context.Context, name string) (fi os.FileInfo, err err
 // Handle represents an open file
 type Handle struct {
 	vfs.Handle
+	props deadProps
+	locks *lockSystem
+	name  string
 }
 
 // Readdir reads directory entries from the handle
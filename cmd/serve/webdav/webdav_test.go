@@ -19,7 +19,7 @@ import (
 	"time"
 
 	_ "github.com/rclone/rclone/backend/local"
-	"github.com/rclone/rclone/cmd/serve/httplib"
+	_ "github.com/rclone/rclone/backend/memory"
 	"github.com/rclone/rclone/cmd/serve/servetest"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/configmap"
@@ -40,9 +40,10 @@ const (
 
 // check interfaces
 var (
-	_ os.FileInfo         = FileInfo{nil}
-	_ webdav.ETager       = FileInfo{nil}
-	_ webdav.ContentTyper = FileInfo{nil}
+	_ os.FileInfo            = FileInfo{nil}
+	_ webdav.ETager          = FileInfo{nil}
+	_ webdav.ContentTyper    = FileInfo{nil}
+	_ webdav.DeadPropsHolder = Handle{}
 )
 
 // TestWebDav runs the webdav server then runs the unit tests for the
@@ -50,15 +51,16 @@ var (
 func TestWebDav(t *testing.T) {
 	// Configure and start the server
 	start := func(f fs.Fs) (configmap.Simple, func()) {
-		opt := httplib.DefaultOpt
-		opt.ListenAddr = testBindAddress
-		opt.BasicUser = testUser
-		opt.BasicPass = testPass
-		opt.Template = testTemplate
+		opt := DefaultOpt
+		opt.HTTP.ListenAddr = []string{testBindAddress}
+		opt.Auth.BasicUser = testUser
+		opt.Auth.BasicPass = testPass
+		opt.Template.Template = testTemplate
 		hashType = hash.MD5
 
 		// Start the server
-		w := newWebDAV(context.Background(), f, &opt)
+		w, err := newWebDAV(context.Background(), f, &opt)
+		require.NoError(t, err)
 		assert.NoError(t, w.serve())
 
 		// Config for the backend we'll use to connect to the server
@@ -71,14 +73,140 @@ func TestWebDav(t *testing.T) {
 		}
 
 		return config, func() {
-			w.Close()
-			w.Wait()
+			assert.NoError(t, w.Shutdown())
 		}
 	}
 
 	servetest.Run(t, "webdav", start)
 }
 
+// davRequest does a WebDAV request returning the status and body
+func davRequest(t *testing.T, method, URL string, headers map[string]string, body string) (int, string) {
+	req, err := http.NewRequest(method, URL, strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	if method == "LOCK" {
+		return resp.StatusCode, resp.Header.Get("Lock-Token")
+	}
+	return resp.StatusCode, string(data)
+}
+
+const (
+	testPropPatch = `<?xml version="1.0" encoding="utf-8" ?>
+<D:propertyupdate xmlns:D="DAV:" xmlns:Z="http://example.com/ns">
+<D:set><D:prop><Z:colour>blue</Z:colour></D:prop></D:set>
+</D:propertyupdate>`
+	testPropFind = `<?xml version="1.0" encoding="utf-8" ?>
+<D:propfind xmlns:D="DAV:" xmlns:Z="http://example.com/ns">
+<D:prop><Z:colour/><D:lockdiscovery/></D:prop>
+</D:propfind>`
+	testLockInfo = `<?xml version="1.0" encoding="utf-8" ?>
+<D:lockinfo xmlns:D="DAV:">
+<D:lockscope><D:exclusive/></D:lockscope>
+<D:locktype><D:write/></D:locktype>
+<D:owner><D:href>litmus</D:href></D:owner>
+</D:lockinfo>`
+)
+
+// TestLocksAndProps runs a litmus style sequence of requests checking
+// locks and dead properties are kept, including over a restart of the
+// server.
+func TestLocksAndProps(t *testing.T) {
+	ctx := context.Background()
+	f, err := fs.NewFs(ctx, ":memory:webdavprops")
+	require.NoError(t, err)
+
+	opt := DefaultOpt
+	opt.HTTP.ListenAddr = []string{testBindAddress}
+	opt.Template.Template = testTemplate
+	start := func() *WebDAV {
+		w, err := newWebDAV(ctx, f, &opt)
+		require.NoError(t, err)
+		require.NoError(t, w.serve())
+		return w
+	}
+	w := start()
+	URL := w.Server.URL()
+	do := func(method, path string, headers map[string]string, body string) (int, string) {
+		return davRequest(t, method, URL+path, headers, body)
+	}
+	depth0 := map[string]string{"Depth": "0"}
+
+	// Dead properties on files and directories
+	status, _ := do("PUT", "file.txt", nil, "hello")
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = do("MKCOL", "dir", nil, "")
+	assert.Equal(t, http.StatusCreated, status)
+	for _, path := range []string{"file.txt", "dir"} {
+		status, body := do("PROPPATCH", path, nil, testPropPatch)
+		assert.Equal(t, http.StatusMultiStatus, status, path)
+		assert.Contains(t, body, "200 OK", path)
+		status, body = do("PROPFIND", path, depth0, testPropFind)
+		assert.Equal(t, http.StatusMultiStatus, status, path)
+		assert.Contains(t, body, "blue", path)
+	}
+
+	// Properties follow a MOVE
+	status, _ = do("MOVE", "file.txt", map[string]string{"Destination": URL + "dir/moved.txt"}, "")
+	assert.Equal(t, http.StatusCreated, status)
+	_, body := do("PROPFIND", "dir/moved.txt", depth0, testPropFind)
+	assert.Contains(t, body, "blue")
+
+	// Lock the file
+	status, token := do("LOCK", "dir/moved.txt", map[string]string{"Timeout": "Second-3600"}, testLockInfo)
+	assert.Equal(t, http.StatusOK, status)
+	require.NotEqual(t, "", token)
+	token = strings.Trim(token, "<>")
+	status, _ = do("PUT", "dir/moved.txt", nil, "changed")
+	assert.Equal(t, http.StatusLocked, status)
+	_, body = do("PROPFIND", "dir/moved.txt", depth0, testPropFind)
+	assert.Contains(t, body, token)
+	assert.Contains(t, body, "litmus")
+
+	// Restart the server. The new server is started before the
+	// old one is stopped so they share the database, as unit
+	// tests start with a fresh one.
+	w2 := start()
+	require.NoError(t, w.Shutdown())
+	w = w2
+	URL = w.Server.URL()
+	defer func() {
+		assert.NoError(t, w.Shutdown())
+	}()
+
+	// The lock and properties are still there
+	status, _ = do("PUT", "dir/moved.txt", nil, "changed")
+	assert.Equal(t, http.StatusLocked, status)
+	_, body = do("PROPFIND", "dir/moved.txt", depth0, testPropFind)
+	assert.Contains(t, body, "blue")
+	assert.Contains(t, body, token)
+	status, _ = do("PUT", "dir/moved.txt", map[string]string{"If": "(<" + token + ">)"}, "changed")
+	assert.Equal(t, http.StatusCreated, status)
+
+	// Unlock
+	status, _ = do("UNLOCK", "dir/moved.txt", map[string]string{"Lock-Token": "<" + token + ">"}, "")
+	assert.Equal(t, http.StatusNoContent, status)
+	status, _ = do("PUT", "dir/moved.txt", nil, "changed again")
+	assert.Equal(t, http.StatusCreated, status)
+	_, body = do("PROPFIND", "dir/moved.txt", depth0, testPropFind)
+	assert.NotContains(t, body, token)
+
+	// Properties are deleted with their files
+	status, _ = do("DELETE", "dir", nil, "")
+	assert.Equal(t, http.StatusNoContent, status)
+	status, _ = do("MKCOL", "dir", nil, "")
+	assert.Equal(t, http.StatusCreated, status)
+	_, body = do("PROPFIND", "dir", depth0, testPropFind)
+	assert.NotContains(t, body, "blue")
+}
+
 // Test serve http functionality in serve webdav
 // While similar to http serve, there are some inconsistencies
 // in the handling of some requests such as POST requests
@@ -98,16 +226,16 @@ func TestHTTPFunction(t *testing.T) {
 	f, err := fs.NewFs(context.Background(), "../http/testdata/files")
 	assert.NoError(t, err)
 
-	opt := httplib.DefaultOpt
-	opt.ListenAddr = testBindAddress
-	opt.Template = testTemplate
+	opt := DefaultOpt
+	opt.HTTP.ListenAddr = []string{testBindAddress}
+	opt.Template.Template = testTemplate
 
 	// Start the server
-	w := newWebDAV(context.Background(), f, &opt)
+	w, err := newWebDAV(context.Background(), f, &opt)
+	require.NoError(t, err)
 	assert.NoError(t, w.serve())
 	defer func() {
-		w.Close()
-		w.Wait()
+		assert.NoError(t, w.Shutdown())
 	}()
 	testURL := w.Server.URL()
 	pause := time.Millisecond
@@ -126,11 +126,13 @@ type ConfigInfo struct {
 	MultiThreadCutoff       SizeSuffix
 	MultiThreadStreams      int
 	MultiThreadSet          bool   // whether MultiThreadStreams was set (set in fs/config/configflags)
+	MultiThreadResume       bool   // whether to persist multi-thread uploads so they can be resumed
 	OrderBy                 string // instructions on how to order the transfer
 	UploadHeaders           []*HTTPOption
 	DownloadHeaders         []*HTTPOption
 	Headers                 []*HTTPOption
-	MetadataSet             Metadata // extra metadata to write when uploading
+	MetadataSet             Metadata     // extra metadata to write when uploading
+	MetadataMapper          SpaceSepList // program to transform metadata when uploading
 	RefreshTimes            bool
 	NoConsole               bool
 	TrafficClass            uint8
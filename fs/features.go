@@ -147,6 +147,22 @@ type Features struct {
 	// It truncates any existing object
 	OpenWriterAt func(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
 
+	// OpenChunkWriter starts an upload of an object in chunks
+	// which can be written concurrently.
+	//
+	// Pass in the remote desired and the src object it is being
+	// made from, which must have a known size. It returns how the
+	// backend would like the chunks written.
+	OpenChunkWriter func(ctx context.Context, remote string, src ObjectInfo, options ...OpenOption) (info ChunkWriterInfo, writer ChunkWriter, err error)
+
+	// ResumeChunkWriter carries on with an upload to remote started
+	// by OpenChunkWriter, possibly by a different process.
+	//
+	// Pass in the state returned by the ResumableChunkWriter. It
+	// should return an error if the upload no longer exists.
+	// Chunks may be written again after resuming.
+	ResumeChunkWriter func(ctx context.Context, remote string, state []byte) (info ChunkWriterInfo, writer ChunkWriter, err error)
+
 	// UserInfo returns info about the connected user
 	UserInfo func(ctx context.Context) (map[string]string, error)
 
@@ -282,6 +298,12 @@ func (ft *Features) Fill(ctx context.Context, f Fs) *Features {
 	if do, ok := f.(OpenWriterAter); ok {
 		ft.OpenWriterAt = do.OpenWriterAt
 	}
+	if do, ok := f.(OpenChunkWriter); ok {
+		ft.OpenChunkWriter = do.OpenChunkWriter
+	}
+	if do, ok := f.(ResumeChunkWriter); ok {
+		ft.ResumeChunkWriter = do.ResumeChunkWriter
+	}
 	if do, ok := f.(UserInfoer); ok {
 		ft.UserInfo = do.UserInfo
 	}
@@ -371,6 +393,12 @@ func (ft *Features) Mask(ctx context.Context, f Fs) *Features {
 	if mask.OpenWriterAt == nil {
 		ft.OpenWriterAt = nil
 	}
+	if mask.OpenChunkWriter == nil {
+		ft.OpenChunkWriter = nil
+	}
+	if mask.ResumeChunkWriter == nil {
+		ft.ResumeChunkWriter = nil
+	}
 	if mask.UserInfo == nil {
 		ft.UserInfo = nil
 	}
@@ -601,6 +629,28 @@ type OpenWriterAter interface {
 	OpenWriterAt(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
 }
 
+// OpenChunkWriter is an optional interface for Fs
+type OpenChunkWriter interface {
+	// OpenChunkWriter starts an upload of an object in chunks
+	// which can be written concurrently.
+	//
+	// Pass in the remote desired and the src object it is being
+	// made from, which must have a known size. It returns how the
+	// backend would like the chunks written.
+	OpenChunkWriter(ctx context.Context, remote string, src ObjectInfo, options ...OpenOption) (info ChunkWriterInfo, writer ChunkWriter, err error)
+}
+
+// ResumeChunkWriter is an optional interface for Fs
+type ResumeChunkWriter interface {
+	// ResumeChunkWriter carries on with an upload to remote started
+	// by OpenChunkWriter, possibly by a different process.
+	//
+	// Pass in the state returned by the ResumableChunkWriter. It
+	// should return an error if the upload no longer exists.
+	// Chunks may be written again after resuming.
+	ResumeChunkWriter(ctx context.Context, remote string, state []byte) (info ChunkWriterInfo, writer ChunkWriter, err error)
+}
+
 // UserInfoer is an optional interface for Fs
 type UserInfoer interface {
 	// UserInfo returns info about the connected user
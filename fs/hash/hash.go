@@ -12,9 +12,12 @@ import (
 	"hash"
 	"hash/crc32"
 	"io"
+	"math/bits"
 	"strings"
 
 	"github.com/jzelinskie/whirlpool"
+	"github.com/zeebo/blake3"
+	"github.com/zeebo/xxh3"
 )
 
 // Type indicates a standard hashing algorithm
@@ -28,6 +31,10 @@ type hashDefinition struct {
 	hashType Type
 }
 
+// maxTypes is the number of hash types which fit in the bitmask of a
+// Type or Set without using the sign bit
+const maxTypes = bits.UintSize - 1
+
 var (
 	type2hash  = map[Type]*hashDefinition{}
 	name2hash  = map[string]*hashDefinition{}
@@ -36,7 +43,12 @@ var (
 )
 
 // RegisterHash adds a new Hash to the list and returns it Type
+//
+// This will panic if there are too many hashes to fit in the bitmask.
 func RegisterHash(name, alias string, width int, newFunc func() hash.Hash) Type {
+	if len(supported) >= maxTypes {
+		panic(fmt.Sprintf("internal error: can't register hash %q: too many hash types", name))
+	}
 	hashType := Type(1 << len(supported))
 	supported = append(supported, hashType)
 
@@ -77,14 +89,40 @@ var (
 
 	// SHA256 indicates SHA-256 support
 	SHA256 Type
+
+	// BLAKE3 indicates BLAKE3 support
+	BLAKE3 Type
+
+	// XXH3 indicates XXH3-64 support
+	XXH3 Type
+
+	// XXH128 indicates XXH3-128 support
+	XXH128 Type
 )
 
+// xxh128 adapts the XXH3 hasher to return the 128 bit hash
+type xxh128 struct {
+	*xxh3.Hasher
+}
+
+// Size returns the number of bytes Sum will return
+func (h xxh128) Size() int { return 16 }
+
+// Sum appends the 128 bit hash to b
+func (h xxh128) Sum(b []byte) []byte {
+	sum := h.Sum128().Bytes()
+	return append(b, sum[:]...)
+}
+
 func init() {
 	MD5 = RegisterHash("md5", "MD5", 32, md5.New)
 	SHA1 = RegisterHash("sha1", "SHA-1", 40, sha1.New)
 	Whirlpool = RegisterHash("whirlpool", "Whirlpool", 128, whirlpool.New)
 	CRC32 = RegisterHash("crc32", "CRC-32", 8, func() hash.Hash { return crc32.NewIEEE() })
 	SHA256 = RegisterHash("sha256", "SHA-256", 64, sha256.New)
+	BLAKE3 = RegisterHash("blake3", "BLAKE3", 64, func() hash.Hash { return blake3.New() })
+	XXH3 = RegisterHash("xxh3", "XXH3", 16, func() hash.Hash { return xxh3.New() })
+	XXH128 = RegisterHash("xxh128", "XXH128", 32, func() hash.Hash { return xxh128{xxh3.New()} })
 }
 
 // Supported returns a set of all the supported hashes by
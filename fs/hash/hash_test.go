@@ -76,6 +76,9 @@ var hashTestSet = []hashTest{
 			hash.Whirlpool: "eddf52133d4566d763f716e853d6e4efbabd29e2c2e63f56747b1596172851d34c2df9944beb6640dbdbe3d9b4eb61180720a79e3d15baff31c91e43d63869a4",
 			hash.CRC32:     "a6041d7e",
 			hash.SHA256:    "c839e57675862af5c21bd0a15413c3ec579e0d5522dab600bc6c3489b05b8f54",
+			hash.BLAKE3:    "0a7276a407a3be1b4d31488318ee05a335aad5a3b82c4420e592a8178c9e86bb",
+			hash.XXH3:      "4b83b0c51c543525",
+			hash.XXH128:    "438de241a57d684214f67657f7aad93b",
 		},
 	},
 	// Empty data set
@@ -87,6 +90,9 @@ var hashTestSet = []hashTest{
 			hash.Whirlpool: "19fa61d75522a4669b44e39c1d2e1726c530232130d407f89afee0964997f7a73e83be698b288febcf88e3e03c4f0757ea8964e59b63d93708b138cc42a66eb3",
 			hash.CRC32:     "00000000",
 			hash.SHA256:    "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			hash.BLAKE3:    "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",
+			hash.XXH3:      "2d06800538d394c2",
+			hash.XXH128:    "99aa06d3014798d86001c324468d497f",
 		},
 	},
 }
@@ -197,6 +203,13 @@ func TestHashSetter(t *testing.T) {
 	assert.NoError(t, ht.Set("Sha1"))
 	assert.Equal(t, hash.SHA1, ht)
 	assert.Error(t, ht.Set("Sha-1"))
+
+	assert.NoError(t, ht.Set("blake3"))
+	assert.Equal(t, hash.BLAKE3, ht)
+	assert.NoError(t, ht.Set("XXH3"))
+	assert.Equal(t, hash.XXH3, ht)
+	assert.NoError(t, ht.Set("xxh128"))
+	assert.Equal(t, hash.XXH128, ht)
 }
 
 func TestHashTypeStability(t *testing.T) {
@@ -1,6 +1,15 @@
 package fs
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
 
 // Metadata represents Object metadata in a standardised form
 //
@@ -83,3 +92,85 @@ func GetMetadataOptions(ctx context.Context, o ObjectInfo, options []OpenOption)
 	metadata.MergeOptions(options)
 	return metadata, nil
 }
+
+// metadataMapperIn is the JSON sent to the --metadata-mapper program
+type metadataMapperIn struct {
+	SrcFs     string    // config string of the source, e.g. "s3:bucket"
+	DstFs     string    // config string of the destination
+	Remote    string    // path of the source object relative to SrcFs
+	DstRemote string    // path the object will be written to relative to DstFs
+	Size      int64     // size of the object or -1 if unknown
+	MimeType  string    // MIME type of the object
+	ModTime   time.Time // modification time of the object
+	ID        string    `json:",omitempty"` // ID of the object if known
+	Metadata  Metadata  // metadata to be written to the destination
+}
+
+// metadataMapperOut is the JSON read back from the --metadata-mapper program
+type metadataMapperOut struct {
+	Metadata Metadata // metadata to write instead
+}
+
+// infoString returns the config string for f
+func infoString(f Info) string {
+	if do, ok := f.(Fs); ok {
+		return ConfigString(do)
+	}
+	return f.Name() + ":" + f.Root()
+}
+
+// MapMetadata runs the --metadata-mapper program, if configured, on
+// the metadata of src which is about to be written to remote on dst,
+// and returns the metadata it outputs.
+//
+// If no mapper is configured the metadata is returned unchanged.
+func MapMetadata(ctx context.Context, dst Info, remote string, src ObjectInfo, metadata Metadata) (Metadata, error) {
+	ci := GetConfig(ctx)
+	if len(ci.MetadataMapper) == 0 {
+		return metadata, nil
+	}
+	in := metadataMapperIn{
+		SrcFs:     infoString(src.Fs()),
+		DstFs:     infoString(dst),
+		Remote:    src.Remote(),
+		DstRemote: remote,
+		Size:      src.Size(),
+		MimeType:  MimeType(ctx, src),
+		ModTime:   src.ModTime(ctx),
+		Metadata:  metadata,
+	}
+	if do, ok := src.(IDer); ok {
+		in.ID = do.ID()
+	}
+	if in.Metadata == nil {
+		in.Metadata = Metadata{}
+	}
+	inBytes, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("metadata mapper: failed to encode input: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ci.MetadataMapper[0], ci.MetadataMapper[1:]...)
+	cmd.Stdin = bytes.NewReader(inBytes)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err = cmd.Run()
+	Debugf(src, "Calling metadata mapper %v took %v", ci.MetadataMapper, time.Since(start))
+	if err != nil {
+		if ers := strings.TrimSpace(stderr.String()); ers != "" {
+			Errorf(src, "--metadata-mapper stderr: %s", ers)
+		}
+		return nil, fmt.Errorf("metadata mapper failed: %w", err)
+	}
+
+	var out metadataMapperOut
+	if err = json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("metadata mapper: failed to decode output: %w", err)
+	}
+	if out.Metadata == nil {
+		return nil, errors.New("metadata mapper: no Metadata in output")
+	}
+	return out.Metadata, nil
+}
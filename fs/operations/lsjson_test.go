@@ -210,6 +210,9 @@ func TestListJSON(t *testing.T) {
 					if len(test.opt.HashTypes) > 0 && len(hashes) > 0 {
 						assert.Equal(t, 1, len(hashes))
 					}
+					if hashes["blake3"] != "" {
+						assert.Equal(t, "ebfeae2a90df171912869c78dc767137bfafbcab6d54ca0fedaca4d2ba824010", hashes["blake3"])
+					}
 					if hashes["crc32"] != "" {
 						assert.Equal(t, "9ee760e5", hashes["crc32"])
 					}
@@ -234,6 +237,12 @@ func TestListJSON(t *testing.T) {
 					if hashes["whirlpool"] != "" {
 						assert.Equal(t, "02fa11755b6470bfc5aab6d94cde5cf2939474fb5b0ebbf8ddf3d32bf06aa438eb92eac097047c02017dc1c317ee83fa8a2717ca4d544b4ee75b3231d1c466b0", hashes["whirlpool"])
 					}
+					if hashes["xxh128"] != "" {
+						assert.Equal(t, "9419b36d385775c69776ce7a76859fbe", hashes["xxh128"])
+					}
+					if hashes["xxh3"] != "" {
+						assert.Equal(t, "eec4a2d35a12efe8", hashes["xxh3"])
+					}
 				} else {
 					assert.Nil(t, got[i].Hashes)
 				}
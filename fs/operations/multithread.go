@@ -1,13 +1,18 @@
 package operations
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/lib/atexit"
+	"github.com/rclone/rclone/lib/pacer"
+	"github.com/rclone/rclone/lib/pool"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -15,6 +20,10 @@ const (
 	multithreadChunkSize     = 64 << 10
 	multithreadChunkSizeMask = multithreadChunkSize - 1
 	multithreadBufferSize    = 32 * 1024
+	multithreadPoolFlushTime = 10 * time.Second
+
+	// chunk size used with an OpenChunkWriter which doesn't ask for one
+	multithreadDefaultChunkSize = 64 * 1024 * 1024
 )
 
 // Return a boolean as to whether we should use multi thread copy for
@@ -32,9 +41,9 @@ func doMultiThreadCopy(ctx context.Context, f fs.Fs, src fs.Object) bool {
 	if src.Size() < int64(ci.MultiThreadCutoff) {
 		return false
 	}
-	// ...source doesn't support it
+	// ...destination doesn't support it
 	dstFeatures := f.Features()
-	if dstFeatures.OpenWriterAt == nil {
+	if dstFeatures.OpenChunkWriter == nil && dstFeatures.OpenWriterAt == nil {
 		return false
 	}
 	// ...if --multi-thread-streams not in use and source and
@@ -47,78 +56,75 @@ func doMultiThreadCopy(ctx context.Context, f fs.Fs, src fs.Object) bool {
 
 // state for a multi-thread copy
 type multiThreadCopyState struct {
-	ctx      context.Context
 	partSize int64
 	size     int64
-	wc       fs.WriterAtCloser
+	writer   fs.ChunkWriter
 	src      fs.Object
 	acc      *accounting.Account
-	streams  int
+	streams  int            // number of chunks to copy
+	pool     *pool.Pool     // buffers to hold chunks or nil to stream them
+	resumer  *uploadResumer // persists progress of the upload if set
+}
+
+// Return the offsets of the start and end of chunk in the file
+func (mc *multiThreadCopyState) chunkRange(chunk int) (start, end int64) {
+	start = int64(chunk) * mc.partSize
+	end = start + mc.partSize
+	if end > mc.size {
+		end = mc.size
+	}
+	return start, end
 }
 
-// Copy a single stream into place
-func (mc *multiThreadCopyState) copyStream(ctx context.Context, stream int) (err error) {
+// Copy a single chunk into place
+func (mc *multiThreadCopyState) copyChunk(ctx context.Context, chunk int) (err error) {
 	ci := fs.GetConfig(ctx)
 	defer func() {
 		if err != nil {
-			fs.Debugf(mc.src, "multi-thread copy: stream %d/%d failed: %v", stream+1, mc.streams, err)
+			fs.Debugf(mc.src, "multi-thread copy: chunk %d/%d failed: %v", chunk+1, mc.streams, err)
 		}
 	}()
-	start := int64(stream) * mc.partSize
+	start, end := mc.chunkRange(chunk)
 	if start >= mc.size {
 		return nil
 	}
-	end := start + mc.partSize
-	if end > mc.size {
-		end = mc.size
-	}
+	size := end - start
 
-	fs.Debugf(mc.src, "multi-thread copy: stream %d/%d (%d-%d) size %v starting", stream+1, mc.streams, start, end, fs.SizeSuffix(end-start))
+	fs.Debugf(mc.src, "multi-thread copy: chunk %d/%d (%d-%d) size %v starting", chunk+1, mc.streams, start, end, fs.SizeSuffix(size))
 
 	rc, err := NewReOpen(ctx, mc.src, ci.LowLevelRetries, &fs.RangeOption{Start: start, End: end - 1})
 	if err != nil {
-		return fmt.Errorf("multipart copy: failed to open source: %w", err)
+		return fmt.Errorf("multi-thread copy: failed to open source: %w", err)
 	}
 	defer fs.CheckClose(rc, &err)
 
-	// Copy the data
-	buf := make([]byte, multithreadBufferSize)
-	offset := start
-	for {
-		// Check if context cancelled and exit if so
-		if mc.ctx.Err() != nil {
-			return mc.ctx.Err()
-		}
-		nr, er := rc.Read(buf)
-		if nr > 0 {
-			err = mc.acc.AccountRead(nr)
-			if err != nil {
-				return fmt.Errorf("multipart copy: accounting failed: %w", err)
-			}
-			nw, ew := mc.wc.WriteAt(buf[0:nr], offset)
-			if nw > 0 {
-				offset += int64(nw)
-			}
-			if ew != nil {
-				return fmt.Errorf("multipart copy: write failed: %w", ew)
-			}
-			if nr != nw {
-				return fmt.Errorf("multipart copy: %w", io.ErrShortWrite)
-			}
-		}
-		if er != nil {
-			if er != io.EOF {
-				return fmt.Errorf("multipart copy: read failed: %w", er)
-			}
-			break
+	// Read the chunk into memory if the writer might need to
+	// seek it to retry, otherwise stream it
+	var in io.ReadSeeker
+	if mc.pool != nil {
+		buf := mc.pool.Get()
+		defer mc.pool.Put(buf)
+		_, err = io.ReadFull(mc.acc.WrapStream(rc), buf[:size])
+		if err != nil {
+			return fmt.Errorf("multi-thread copy: failed to read chunk: %w", err)
 		}
+		in = bytes.NewReader(buf[:size])
+	} else {
+		in = noSeeker{mc.acc.WrapStream(rc)}
 	}
 
-	if offset != end {
-		return fmt.Errorf("multipart copy: wrote %d bytes but expected to write %d", offset-start, end-start)
+	n, err := mc.writer.WriteChunk(ctx, chunk, in)
+	if err != nil {
+		return fmt.Errorf("multi-thread copy: failed to write chunk: %w", err)
+	}
+	if n != size {
+		return fmt.Errorf("multi-thread copy: wrote %d bytes but expected to write %d", n, size)
+	}
+	if mc.resumer != nil {
+		mc.resumer.save(chunk)
 	}
 
-	fs.Debugf(mc.src, "multi-thread copy: stream %d/%d (%d-%d) size %v finished", stream+1, mc.streams, start, end, fs.SizeSuffix(end-start))
+	fs.Debugf(mc.src, "multi-thread copy: chunk %d/%d (%d-%d) size %v finished", chunk+1, mc.streams, start, end, fs.SizeSuffix(size))
 	return nil
 }
 
@@ -139,11 +145,28 @@ func (mc *multiThreadCopyState) calculateChunks() {
 	}
 }
 
-// Copy src to (f, remote) using streams download threads and the OpenWriterAt feature
-func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object, streams int, tr *accounting.Transfer) (newDst fs.Object, err error) {
+// chunkWriterPartSize returns the size of the chunks to copy a file of
+// size in with a ChunkWriter which asked for chunkSize. The chunks are
+// buffered in memory so a default is used if it didn't ask for one
+// rather than the whole file.
+func chunkWriterPartSize(chunkSize, size int64) int64 {
+	if chunkSize <= 0 {
+		chunkSize = multithreadDefaultChunkSize
+	}
+	if chunkSize > size {
+		chunkSize = size
+	}
+	return chunkSize
+}
+
+// Copy src to (f, remote) using streams download threads. It uses the
+// OpenChunkWriter feature if available, otherwise OpenWriterAt.
+func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object, streams int, tr *accounting.Transfer, options ...fs.OpenOption) (newDst fs.Object, err error) {
+	ci := fs.GetConfig(ctx)
+	openChunkWriter := f.Features().OpenChunkWriter
 	openWriterAt := f.Features().OpenWriterAt
-	if openWriterAt == nil {
-		return nil, errors.New("multi-thread copy: OpenWriterAt not supported")
+	if openChunkWriter == nil && openWriterAt == nil {
+		return nil, errors.New("multi-thread copy: neither OpenChunkWriter nor OpenWriterAt supported")
 	}
 	if src.Size() < 0 {
 		return nil, errors.New("multi-thread copy: can't copy unknown sized file")
@@ -152,38 +175,112 @@ func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object,
 		return nil, errors.New("multi-thread copy: can't copy zero sized file")
 	}
 
-	g, gCtx := errgroup.WithContext(ctx)
 	mc := &multiThreadCopyState{
-		ctx:     gCtx,
 		size:    src.Size(),
 		src:     src,
 		streams: streams,
 	}
-	mc.calculateChunks()
+	var (
+		info        fs.ChunkWriterInfo
+		concurrency int
+	)
+	if openChunkWriter != nil {
+		var wrappedSrc fs.ObjectInfo = src
+		if src.Remote() != remote {
+			wrappedSrc = NewOverrideRemote(src, remote)
+		}
+		info, mc.writer, mc.resumer, err = openResumableChunkWriter(ctx, f, remote, wrappedSrc, options...)
+		if err != nil {
+			return nil, fmt.Errorf("multi-thread copy: failed to open chunk writer: %w", err)
+		}
+		if mc.resumer != nil {
+			defer mc.resumer.close()
+		}
+		mc.partSize = chunkWriterPartSize(info.ChunkSize, mc.size)
+		mc.streams = int((mc.size + mc.partSize - 1) / mc.partSize)
+		// Use the backend's concurrency if it wants more
+		concurrency = streams
+		if info.Concurrency > concurrency {
+			concurrency = info.Concurrency
+		}
+		if concurrency > mc.streams {
+			concurrency = mc.streams
+		}
+		// The chunks may be seeked to retry them so need buffering
+		mc.pool = pool.New(multithreadPoolFlushTime, int(mc.partSize), concurrency, ci.UseMmap)
+		defer mc.pool.Flush()
+	} else {
+		mc.calculateChunks()
+		concurrency = mc.streams
+		var writerAt fs.WriterAtCloser
+		writerAt, err = openWriterAt(ctx, remote, mc.size)
+		if err != nil {
+			return nil, fmt.Errorf("multi-thread copy: failed to open destination: %w", err)
+		}
+		mc.writer = &writerAtChunkWriter{
+			f:         f,
+			remote:    remote,
+			chunkSize: mc.partSize,
+			writerAt:  writerAt,
+		}
+	}
+
+	// Remove what has been written so far on error or if rclone
+	// is interrupted, unless it is being kept to resume
+	uploaded := false
+	defer atexit.OnError(&err, func() {
+		if uploaded || info.LeavePartsOnError {
+			return
+		}
+		if mc.resumer != nil {
+			fs.Infof(src, "multi-thread copy: leaving upload to be resumed")
+			return
+		}
+		fs.Debugf(src, "multi-thread copy: cancelling transfer")
+		abortErr := mc.writer.Abort(context.Background())
+		if abortErr != nil {
+			fs.Debugf(src, "multi-thread copy: failed to cancel transfer: %v", abortErr)
+		}
+	})()
 
 	// Make accounting
 	mc.acc = tr.Account(ctx, nil)
 
-	// create write file handle
-	mc.wc, err = openWriterAt(gCtx, remote, mc.size)
-	if err != nil {
-		return nil, fmt.Errorf("multipart copy: failed to open destination: %w", err)
-	}
-
-	fs.Debugf(src, "Starting multi-thread copy with %d parts of size %v", mc.streams, fs.SizeSuffix(mc.partSize))
-	for stream := 0; stream < mc.streams; stream++ {
-		stream := stream
-		g.Go(func() (err error) {
-			return mc.copyStream(gCtx, stream)
+	g, gCtx := errgroup.WithContext(ctx)
+	tokens := pacer.NewTokenDispenser(concurrency)
+	fs.Debugf(src, "Starting multi-thread copy with %d chunks of size %v using %d streams", mc.streams, fs.SizeSuffix(mc.partSize), concurrency)
+	for chunk := 0; chunk < mc.streams; chunk++ {
+		if mc.resumer != nil && mc.resumer.written(chunk) {
+			start, end := mc.chunkRange(chunk)
+			mc.acc.ServerSideCopyEnd(end - start)
+			continue
+		}
+		tokens.Get()
+		// Fail fast - no point copying more chunks if one has failed
+		if gCtx.Err() != nil {
+			tokens.Put()
+			break
+		}
+		chunk := chunk
+		g.Go(func() error {
+			defer tokens.Put()
+			return mc.copyChunk(gCtx, chunk)
 		})
 	}
 	err = g.Wait()
-	closeErr := mc.wc.Close()
+	if err == nil {
+		err = ctx.Err()
+	}
 	if err != nil {
 		return nil, err
 	}
-	if closeErr != nil {
-		return nil, fmt.Errorf("multi-thread copy: failed to close object after copy: %w", closeErr)
+	err = mc.writer.Close(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("multi-thread copy: failed to close object after copy: %w", err)
+	}
+	uploaded = true
+	if mc.resumer != nil {
+		mc.resumer.remove()
 	}
 
 	obj, err := f.NewObject(ctx, remote)
@@ -191,13 +288,83 @@ func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object,
 		return nil, fmt.Errorf("multi-thread copy: failed to find object after copy: %w", err)
 	}
 
-	err = obj.SetModTime(ctx, src.ModTime(ctx))
-	switch err {
-	case nil, fs.ErrorCantSetModTime, fs.ErrorCantSetModTimeWithoutDelete:
-	default:
-		return nil, fmt.Errorf("multi-thread copy: failed to set modification time: %w", err)
+	// Backends implementing OpenChunkWriter set the modification
+	// time from src when the upload starts
+	if openChunkWriter == nil {
+		err = obj.SetModTime(ctx, src.ModTime(ctx))
+		switch err {
+		case nil, fs.ErrorCantSetModTime, fs.ErrorCantSetModTimeWithoutDelete:
+		default:
+			return nil, fmt.Errorf("multi-thread copy: failed to set modification time: %w", err)
+		}
 	}
 
-	fs.Debugf(src, "Finished multi-thread copy with %d parts of size %v", mc.streams, fs.SizeSuffix(mc.partSize))
+	fs.Debugf(src, "Finished multi-thread copy with %d chunks of size %v", mc.streams, fs.SizeSuffix(mc.partSize))
 	return obj, nil
 }
+
+// noSeeker is an io.ReadSeeker which can't seek, used for chunks which
+// are streamed from the source
+type noSeeker struct {
+	io.Reader
+}
+
+// Seek always fails
+func (noSeeker) Seek(offset int64, whence int) (int64, error) {
+	return 0, errors.New("multi-thread copy: can't seek streamed chunk")
+}
+
+// writerAtChunkWriter adapts an fs.WriterAtCloser to an fs.ChunkWriter
+// for backends which implement OpenWriterAt
+type writerAtChunkWriter struct {
+	f         fs.Fs
+	remote    string
+	chunkSize int64
+	writerAt  fs.WriterAtCloser
+}
+
+// WriteChunk writes the chunk at its offset in the file
+func (w *writerAtChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (written int64, err error) {
+	offset := int64(chunkNumber) * w.chunkSize
+	buf := make([]byte, multithreadBufferSize)
+	for {
+		// Check if context cancelled and exit if so
+		if ctx.Err() != nil {
+			return written, ctx.Err()
+		}
+		nr, er := reader.Read(buf)
+		if nr > 0 {
+			nw, ew := w.writerAt.WriteAt(buf[0:nr], offset+written)
+			if nw > 0 {
+				written += int64(nw)
+			}
+			if ew != nil {
+				return written, fmt.Errorf("write failed: %w", ew)
+			}
+			if nr != nw {
+				return written, io.ErrShortWrite
+			}
+		}
+		if er != nil {
+			if er != io.EOF {
+				return written, fmt.Errorf("read failed: %w", er)
+			}
+			return written, nil
+		}
+	}
+}
+
+// Close closes the file
+func (w *writerAtChunkWriter) Close(ctx context.Context) error {
+	return w.writerAt.Close()
+}
+
+// Abort closes and removes the partially written file
+func (w *writerAtChunkWriter) Abort(ctx context.Context) error {
+	_ = w.writerAt.Close()
+	obj, err := w.f.NewObject(ctx, w.remote)
+	if err != nil {
+		return fmt.Errorf("failed to find partially written file: %w", err)
+	}
+	return obj.Remove(ctx)
+}
@@ -1,13 +1,18 @@
 package operations
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"testing"
 
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fstest/mockfs"
 	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/rclone/rclone/lib/kv"
 	"github.com/rclone/rclone/lib/random"
 
 	"github.com/rclone/rclone/fs"
@@ -104,6 +109,22 @@ func TestMultithreadCalculateChunks(t *testing.T) {
 	}
 }
 
+func TestMultithreadChunkWriterPartSize(t *testing.T) {
+	for _, test := range []struct {
+		chunkSize, size, want int64
+	}{
+		{chunkSize: 1000, size: 10000, want: 1000},
+		{chunkSize: 1000, size: 10, want: 10},
+		{chunkSize: 0, size: 10, want: 10},
+		{chunkSize: 0, size: 1 << 40, want: multithreadDefaultChunkSize},
+		{chunkSize: -1, size: 1 << 40, want: multithreadDefaultChunkSize},
+	} {
+		t.Run(fmt.Sprintf("%+v", test), func(t *testing.T) {
+			assert.Equal(t, test.want, chunkWriterPartSize(test.chunkSize, test.size))
+		})
+	}
+}
+
 func TestMultithreadCopy(t *testing.T) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
@@ -147,3 +168,260 @@ func TestMultithreadCopy(t *testing.T) {
 	}
 
 }
+
+// testChunkWriter is an fs.ChunkWriter which assembles the chunks in
+// memory and uploads them to f on Close
+type testChunkWriter struct {
+	f         fs.Fs
+	src       fs.ObjectInfo
+	chunkSize int64
+	failChunk int // chunk number to fail or -1
+
+	mu      sync.Mutex
+	chunks  map[int][]byte
+	writes  int // number of chunks written
+	closed  bool
+	aborted bool
+}
+
+func (w *testChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	if chunkNumber == w.failChunk {
+		return 0, errors.New("chunk failed")
+	}
+	// Read the chunk twice to check it can be seeked to retry
+	_, err := io.Copy(io.Discard, reader)
+	if err != nil {
+		return 0, err
+	}
+	_, err = reader.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	w.chunks[chunkNumber] = data
+	w.writes++
+	w.mu.Unlock()
+	return int64(len(data)), nil
+}
+
+func (w *testChunkWriter) Close(ctx context.Context) error {
+	var buf bytes.Buffer
+	for i := 0; i < len(w.chunks); i++ {
+		chunk, ok := w.chunks[i]
+		if !ok {
+			return fmt.Errorf("missing chunk %d", i)
+		}
+		if i < len(w.chunks)-1 && int64(len(chunk)) != w.chunkSize {
+			return fmt.Errorf("chunk %d is %d bytes long", i, len(chunk))
+		}
+		buf.Write(chunk)
+	}
+	w.closed = true
+	_, err := w.f.Put(ctx, &buf, w.src)
+	return err
+}
+
+func (w *testChunkWriter) Abort(ctx context.Context) error {
+	w.aborted = true
+	return nil
+}
+
+func TestMultithreadCopyChunkWriter(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	const chunkSize = 1000
+	for _, test := range []struct {
+		size      int
+		failChunk int
+	}{
+		{size: 1, failChunk: -1},
+		{size: chunkSize, failChunk: -1},
+		{size: chunkSize*10 + 1, failChunk: -1},
+		{size: chunkSize*10 + 1, failChunk: 3},
+	} {
+		t.Run(fmt.Sprintf("%+v", test), func(t *testing.T) {
+			var err error
+			contents := random.String(test.size)
+			t1 := fstest.Time("2001-02-03T04:05:06.499999999Z")
+			file1 := r.WriteObject(ctx, "file1", contents, t1)
+			src, err := r.Fremote.NewObject(ctx, "file1")
+			require.NoError(t, err)
+
+			var writer *testChunkWriter
+			features := r.Flocal.Features()
+			oldOpenChunkWriter := features.OpenChunkWriter
+			features.OpenChunkWriter = func(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (fs.ChunkWriterInfo, fs.ChunkWriter, error) {
+				assert.Equal(t, "file2", remote)
+				assert.Equal(t, "file2", src.Remote())
+				writer = &testChunkWriter{
+					f:         r.Flocal,
+					src:       src,
+					chunkSize: chunkSize,
+					failChunk: test.failChunk,
+					chunks:    make(map[int][]byte),
+				}
+				return fs.ChunkWriterInfo{ChunkSize: chunkSize, Concurrency: 3}, writer, nil
+			}
+			defer func() {
+				features.OpenChunkWriter = oldOpenChunkWriter
+			}()
+
+			accounting.GlobalStats().ResetCounters()
+			tr := accounting.GlobalStats().NewTransfer(src)
+			defer func() {
+				tr.Done(ctx, err)
+			}()
+			dst, err := multiThreadCopy(ctx, r.Flocal, "file2", src, 2, tr)
+			require.NotNil(t, writer)
+			if test.failChunk >= 0 {
+				assert.ErrorContains(t, err, "chunk failed")
+				assert.False(t, writer.closed)
+				assert.True(t, writer.aborted)
+				r.CheckLocalItems(t)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, writer.closed)
+			assert.False(t, writer.aborted)
+			assert.Equal(t, src.Size(), dst.Size())
+			assert.Equal(t, "file2", dst.Remote())
+			file2 := file1
+			file2.Path = "file2"
+			fstest.CheckListingWithPrecision(t, r.Flocal, []fstest.Item{file2}, nil, fs.GetModifyWindow(ctx, r.Flocal, r.Fremote))
+			require.NoError(t, dst.Remove(ctx))
+		})
+	}
+}
+
+// resumableTestChunkWriter is a testChunkWriter whose state is its id
+type resumableTestChunkWriter struct {
+	*testChunkWriter
+	id string
+}
+
+func (w *resumableTestChunkWriter) ResumeState() ([]byte, error) {
+	return []byte(w.id), nil
+}
+
+func TestMultithreadCopyResume(t *testing.T) {
+	if !kv.Supported() {
+		t.Skip("kv not supported on this OS")
+	}
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.MultiThreadResume = true
+
+	// Hold the database open so it isn't reset between copies
+	db, err := kv.Start(ctx, resumeFacility, r.Flocal)
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Stop(false)
+	}()
+
+	const chunkSize = 1000
+	contents := random.String(chunkSize*10 + 1)
+	t1 := fstest.Time("2001-02-03T04:05:06.499999999Z")
+	file1 := r.WriteObject(ctx, "file1", contents, t1)
+	src, err := r.Fremote.NewObject(ctx, "file1")
+	require.NoError(t, err)
+
+	info := fs.ChunkWriterInfo{ChunkSize: chunkSize, Concurrency: 1}
+	failChunk := -1
+	writers := map[string]*resumableTestChunkWriter{}
+	features := r.Flocal.Features()
+	oldOpenChunkWriter, oldResumeChunkWriter := features.OpenChunkWriter, features.ResumeChunkWriter
+	features.OpenChunkWriter = func(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (fs.ChunkWriterInfo, fs.ChunkWriter, error) {
+		w := &resumableTestChunkWriter{
+			testChunkWriter: &testChunkWriter{
+				f:         r.Flocal,
+				src:       src,
+				chunkSize: chunkSize,
+				failChunk: failChunk,
+				chunks:    make(map[int][]byte),
+			},
+			id: fmt.Sprint(len(writers)),
+		}
+		writers[w.id] = w
+		return info, w, nil
+	}
+	features.ResumeChunkWriter = func(ctx context.Context, remote string, state []byte) (fs.ChunkWriterInfo, fs.ChunkWriter, error) {
+		assert.Equal(t, "file2", remote)
+		w, ok := writers[string(state)]
+		if !ok {
+			return info, nil, errors.New("upload not found")
+		}
+		w.failChunk = failChunk
+		return info, w, nil
+	}
+	defer func() {
+		features.OpenChunkWriter, features.ResumeChunkWriter = oldOpenChunkWriter, oldResumeChunkWriter
+	}()
+
+	doCopy := func() (dst fs.Object, err error) {
+		tr := accounting.GlobalStats().NewTransfer(src)
+		defer func() {
+			tr.Done(ctx, err)
+		}()
+		return multiThreadCopy(ctx, r.Flocal, "file2", src, 1, tr)
+	}
+
+	// The first copy fails and is left to be resumed
+	failChunk = 3
+	_, err = doCopy()
+	assert.ErrorContains(t, err, "chunk failed")
+	require.Equal(t, 1, len(writers))
+	w := writers["0"]
+	assert.False(t, w.aborted)
+	assert.False(t, w.closed)
+	for i := 0; i < failChunk; i++ {
+		assert.Contains(t, w.chunks, i)
+	}
+
+	// The second copy carries on only writing the missing chunks
+	failChunk = -1
+	dst, err := doCopy()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(writers))
+	assert.True(t, w.closed)
+	assert.Equal(t, 11, w.writes)
+	file2 := file1
+	file2.Path = "file2"
+	fstest.CheckListingWithPrecision(t, r.Flocal, []fstest.Item{file2}, nil, fs.GetModifyWindow(ctx, r.Flocal, r.Fremote))
+	require.NoError(t, dst.Remove(ctx))
+
+	// The next copy starts again as the upload is finished
+	failChunk = 3
+	_, err = doCopy()
+	assert.ErrorContains(t, err, "chunk failed")
+	require.Equal(t, 2, len(writers))
+	w = writers["1"]
+
+	// If the source changes the upload is aborted and started again
+	file1 = r.WriteObject(ctx, "file1", contents, fstest.Time("2002-02-03T04:05:06.499999999Z"))
+	src, err = r.Fremote.NewObject(ctx, "file1")
+	require.NoError(t, err)
+	_, err = doCopy()
+	assert.ErrorContains(t, err, "chunk failed")
+	require.Equal(t, 3, len(writers))
+	assert.True(t, w.aborted)
+	w = writers["2"]
+
+	// Cleanup aborts the interrupted upload
+	require.NoError(t, abortResumableUploads(ctx, r.Flocal))
+	assert.True(t, w.aborted)
+	failChunk = -1
+	_, err = doCopy()
+	require.NoError(t, err)
+	require.Equal(t, 4, len(writers))
+	assert.Equal(t, 11, writers["3"].writes)
+	file2 = file1
+	file2.Path = "file2"
+	fstest.CheckListingWithPrecision(t, r.Flocal, []fstest.Item{file2}, nil, fs.GetModifyWindow(ctx, r.Flocal, r.Fremote))
+}
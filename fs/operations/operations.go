@@ -365,6 +365,78 @@ func (o *OverrideRemote) Metadata(ctx context.Context) (fs.Metadata, error) {
 // Check all optional interfaces satisfied
 var _ fs.FullObjectInfo = (*OverrideRemote)(nil)
 
+// overrideMetadata is a wrapper to override the Metadata of an
+// fs.Object with the output of --metadata-mapper
+type overrideMetadata struct {
+	fs.Object
+	metadata fs.Metadata
+}
+
+// Metadata returns the overridden metadata
+func (o *overrideMetadata) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return o.metadata, nil
+}
+
+// MimeType returns the mime type of the underlying object or "" if it
+// can't be worked out
+func (o *overrideMetadata) MimeType(ctx context.Context) string {
+	if do, ok := o.Object.(fs.MimeTyper); ok {
+		return do.MimeType(ctx)
+	}
+	return ""
+}
+
+// ID returns the ID of the Object if known, or "" if not
+func (o *overrideMetadata) ID() string {
+	if do, ok := o.Object.(fs.IDer); ok {
+		return do.ID()
+	}
+	return ""
+}
+
+// UnWrap returns the Object that this Object is wrapping
+func (o *overrideMetadata) UnWrap() fs.Object {
+	return o.Object
+}
+
+// GetTier returns storage tier or class of the Object
+func (o *overrideMetadata) GetTier() string {
+	if do, ok := o.Object.(fs.GetTierer); ok {
+		return do.GetTier()
+	}
+	return ""
+}
+
+// Check all optional interfaces satisfied
+var _ fs.FullObjectInfo = (*overrideMetadata)(nil)
+
+// mapMetadata runs --metadata-mapper on the metadata of src, with
+// any --metadata-set merged in, and returns src wrapped to give the
+// mapped metadata.
+//
+// changed is set if the mapper altered the metadata, in which case a
+// server-side copy can't be used as it would keep the original.
+func mapMetadata(ctx context.Context, f fs.Info, remote string, src fs.Object) (mapped fs.Object, changed bool, err error) {
+	ci := fs.GetConfig(ctx)
+	metadata, err := fs.GetMetadata(ctx, src)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	metadata.Merge(ci.MetadataSet)
+	newMetadata, err := fs.MapMetadata(ctx, f, remote, src, metadata)
+	if err != nil {
+		return nil, false, err
+	}
+	changed = len(newMetadata) != len(metadata)
+	for k, v := range newMetadata {
+		if old, ok := metadata[k]; !ok || old != v {
+			changed = true
+			break
+		}
+	}
+	return &overrideMetadata{Object: src, metadata: newMetadata}, changed, nil
+}
+
 // CommonHash returns a single hash.Type and a HashOption with that
 // type which is in common between the two fs.Fs.
 func CommonHash(ctx context.Context, fa, fb fs.Info) (hash.Type, *fs.HashesOption) {
@@ -404,6 +476,24 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 	doUpdate := dst != nil
 	hashType, hashOption := CommonHash(ctx, f, src.Fs())
 
+	// With --metadata-mapper upload from a source giving the mapped
+	// metadata, which already includes --metadata-set
+	uploadSrc := src
+	metadataSet := ci.MetadataSet
+	metadataChanged := false
+	if ci.Metadata && len(ci.MetadataMapper) != 0 {
+		uploadSrc, metadataChanged, err = mapMetadata(ctx, f, remote, src)
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(src, "Failed to copy: %v", err)
+			return newDst, err
+		}
+		metadataSet = nil
+		if metadataChanged {
+			fs.Debugf(src, "Metadata changed by --metadata-mapper so not using server-side copy")
+		}
+	}
+
 	var actionTaken string
 	for {
 		// Try server-side copy first - if has optional interface and
@@ -423,7 +513,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 				return nil, accounting.ErrorMaxTransferLimitReachedGraceful
 			}
 		}
-		if doCopy := f.Features().Copy; doCopy != nil && (SameConfig(src.Fs(), f) || (SameRemoteType(src.Fs(), f) && (f.Features().ServerSideAcrossConfigs || ci.ServerSideAcrossConfigs))) {
+		if doCopy := f.Features().Copy; doCopy != nil && !metadataChanged && (SameConfig(src.Fs(), f) || (SameRemoteType(src.Fs(), f) && (f.Features().ServerSideAcrossConfigs || ci.ServerSideAcrossConfigs))) {
 			in := tr.Account(ctx, nil) // account the transfer
 			in.ServerSideCopyStart()
 			newDst, err = doCopy(ctx, src, remote)
@@ -452,7 +542,14 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 				if streams < 2 {
 					streams = 2
 				}
-				dst, err = multiThreadCopy(ctx, f, remote, src, int(streams), tr)
+				options := []fs.OpenOption{hashOption}
+				for _, option := range ci.UploadHeaders {
+					options = append(options, option)
+				}
+				if metadataSet != nil {
+					options = append(options, fs.MetadataOption(metadataSet))
+				}
+				dst, err = multiThreadCopy(ctx, f, remote, uploadSrc, int(streams), tr, options...)
 				if doUpdate {
 					actionTaken = "Multi-thread Copied (replaced existing)"
 				} else {
@@ -480,17 +577,17 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 						newDst = dst
 					} else {
 						in := tr.Account(ctx, in0).WithBuffer() // account and buffer the transfer
-						var wrappedSrc fs.ObjectInfo = src
+						var wrappedSrc fs.ObjectInfo = uploadSrc
 						// We try to pass the original object if possible
 						if src.Remote() != remote {
-							wrappedSrc = NewOverrideRemote(src, remote)
+							wrappedSrc = NewOverrideRemote(uploadSrc, remote)
 						}
 						options := []fs.OpenOption{hashOption}
 						for _, option := range ci.UploadHeaders {
 							options = append(options, option)
 						}
-						if ci.MetadataSet != nil {
-							options = append(options, fs.MetadataOption(ci.MetadataSet))
+						if metadataSet != nil {
+							options = append(options, fs.MetadataOption(metadataSet))
 						}
 						if doUpdate {
 							actionTaken = "Copied (replaced existing)"
@@ -618,7 +715,23 @@ func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.
 		return newDst, nil
 	}
 	// See if we have Move available
-	if doMove := fdst.Features().Move; doMove != nil && (SameConfig(src.Fs(), fdst) || (SameRemoteType(src.Fs(), fdst) && (fdst.Features().ServerSideAcrossConfigs || ci.ServerSideAcrossConfigs))) {
+	doMove := fdst.Features().Move
+	canMove := doMove != nil && (SameConfig(src.Fs(), fdst) || (SameRemoteType(src.Fs(), fdst) && (fdst.Features().ServerSideAcrossConfigs || ci.ServerSideAcrossConfigs)))
+	// A server-side move keeps the metadata as it is, so if
+	// --metadata-mapper changes it copy and delete instead
+	if canMove && ci.Metadata && len(ci.MetadataMapper) != 0 {
+		_, metadataChanged, err := mapMetadata(ctx, fdst, remote, src)
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(src, "Failed to move: %v", err)
+			return newDst, err
+		}
+		if metadataChanged {
+			fs.Debugf(src, "Metadata changed by --metadata-mapper so not using server-side move")
+			canMove = false
+		}
+	}
+	if canMove {
 		// Delete destination if it exists and is not the same file as src (could be same file while seemingly different if the remote is case insensitive)
 		if dst != nil && !SameObject(src, dst) {
 			err = DeleteFile(ctx, dst)
@@ -1275,15 +1388,23 @@ func listToChan(ctx context.Context, f fs.Fs, dir string) fs.ObjectsChan {
 	return o
 }
 
-// CleanUp removes the trash for the Fs
+// CleanUp removes the trash for the Fs and aborts any uploads
+// interrupted while using --multi-thread-resume
 func CleanUp(ctx context.Context, f fs.Fs) error {
 	doCleanUp := f.Features().CleanUp
-	if doCleanUp == nil {
+	if doCleanUp == nil && f.Features().ResumeChunkWriter == nil {
 		return fmt.Errorf("%v doesn't support cleanup", f)
 	}
 	if SkipDestructive(ctx, f, "clean up old files") {
 		return nil
 	}
+	err := abortResumableUploads(ctx, f)
+	if err != nil {
+		return err
+	}
+	if doCleanUp == nil {
+		return nil
+	}
 	return doCleanUp(ctx)
 }
 
@@ -1398,10 +1519,13 @@ func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser,
 
 	// check if file small enough for direct upload
 	buf := make([]byte, ci.StreamingUploadCutoff)
-	if n, err := io.ReadFull(trackingIn, buf); err == io.EOF || err == io.ErrUnexpectedEOF {
+	n, err := io.ReadFull(trackingIn, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
 		fs.Debugf(fdst, "File to upload is small (%d bytes), uploading instead of streaming", n)
 		src := object.NewMemoryObject(dstFileName, modTime, buf[:n])
 		return Copy(ctx, fdst, nil, dstFileName, src)
+	} else if err != nil {
+		return nil, err
 	}
 
 	// Make a new ReadCloser with the bits we've already read
@@ -1743,8 +1867,12 @@ func RcatSize(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadClo
 			return nil, err
 		}
 
+		var options []fs.OpenOption
+		if ci := fs.GetConfig(ctx); ci.MetadataSet != nil {
+			options = append(options, fs.MetadataOption(ci.MetadataSet))
+		}
 		info := object.NewStaticObjectInfo(dstFileName, modTime, size, true, nil, fdst)
-		obj, err = fdst.Put(ctx, in, info)
+		obj, err = fdst.Put(ctx, in, info, options...)
 		if err != nil {
 			fs.Errorf(dstFileName, "Post request put error: %v", err)
 
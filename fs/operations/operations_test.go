@@ -23,6 +23,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +33,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	_ "github.com/rclone/rclone/backend/all" // import all backends
@@ -59,9 +61,36 @@ var (
 
 // TestMain drives the tests
 func TestMain(m *testing.M) {
+	if os.Getenv(metadataMapperEnv) != "" {
+		metadataMapper()
+		return
+	}
 	fstest.TestMain(m)
 }
 
+// Set to make the test binary act as a --metadata-mapper program
+const metadataMapperEnv = "RCLONE_TEST_METADATA_MAPPER"
+
+// metadataMapper drops the "drop" key and records the source and
+// destination in the metadata read from stdin
+func metadataMapper() {
+	var in struct {
+		Remote    string
+		DstRemote string
+		Metadata  fs.Metadata
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+		fmt.Fprintf(os.Stderr, "bad input: %v\n", err)
+		os.Exit(1)
+	}
+	delete(in.Metadata, "drop")
+	in.Metadata["mapped-from"] = in.Remote
+	in.Metadata["mapped-to"] = in.DstRemote
+	if err := json.NewEncoder(os.Stdout).Encode(map[string]fs.Metadata{"Metadata": in.Metadata}); err != nil {
+		os.Exit(1)
+	}
+}
+
 func TestMkdir(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRun(t)
@@ -862,6 +891,46 @@ func TestMoveFile(t *testing.T) {
 	r.CheckRemoteItems(t, file2)
 }
 
+func TestMoveFileMetadataMapper(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	features := r.Fremote.Features()
+	if !features.UserMetadata || !features.ReadMetadata {
+		t.Skip("Skipping test as remote does not support user metadata")
+	}
+
+	t.Setenv(metadataMapperEnv, "1")
+	ci.Metadata = true
+	ci.MetadataMapper = fs.SpaceSepList{os.Args[0]}
+
+	file1 := r.WriteObject(ctx, "file1", "file1 contents", t1)
+	r.CheckRemoteItems(t, file1)
+	file2 := file1
+	file2.Path = "sub/file2"
+
+	// The mapped metadata is applied even where a server-side
+	// move would have been used
+	err := operations.MoveFile(ctx, r.Fremote, r.Fremote, file2.Path, file1.Path)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file2)
+
+	o, err := r.Fremote.NewObject(ctx, file2.Path)
+	require.NoError(t, err)
+	metadata, err := fs.GetMetadata(ctx, o)
+	require.NoError(t, err)
+	assert.Equal(t, "file1", metadata["mapped-from"])
+	assert.Equal(t, "sub/file2", metadata["mapped-to"])
+
+	// A failing mapper fails the move and leaves the source
+	ci.MetadataMapper = fs.SpaceSepList{"/non/existent/mapper"}
+	err = operations.MoveFile(ctx, r.Fremote, r.Fremote, "file3", file2.Path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "metadata mapper failed")
+	r.CheckRemoteItems(t, file2)
+}
+
 func TestMoveFileWithIgnoreExisting(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -975,6 +1044,45 @@ func TestCopyFile(t *testing.T) {
 	r.CheckRemoteItems(t, file2)
 }
 
+func TestCopyFileMetadataMapper(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	features := r.Fremote.Features()
+	if !features.UserMetadata || !features.ReadMetadata {
+		t.Skip("Skipping test as remote does not support user metadata")
+	}
+
+	t.Setenv(metadataMapperEnv, "1")
+	ci.Metadata = true
+	ci.MetadataMapper = fs.SpaceSepList{os.Args[0]}
+	ci.MetadataSet = fs.Metadata{"keep": "yes", "drop": "me"}
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	file2 := file1
+	file2.Path = "sub/file2"
+
+	err := operations.CopyFile(ctx, r.Fremote, r.Flocal, file2.Path, file1.Path)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file2)
+
+	o, err := r.Fremote.NewObject(ctx, file2.Path)
+	require.NoError(t, err)
+	metadata, err := fs.GetMetadata(ctx, o)
+	require.NoError(t, err)
+	assert.Equal(t, "yes", metadata["keep"])
+	assert.Equal(t, "file1", metadata["mapped-from"])
+	assert.Equal(t, "sub/file2", metadata["mapped-to"])
+	assert.NotContains(t, metadata, "drop")
+
+	// A failing mapper fails the copy
+	ci.MetadataMapper = fs.SpaceSepList{"/non/existent/mapper"}
+	err = operations.CopyFile(ctx, r.Fremote, r.Flocal, "file3", file1.Path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "metadata mapper failed")
+}
+
 func TestCopyFileBackupDir(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -1638,6 +1746,44 @@ func TestRcatSize(t *testing.T) {
 	r.CheckRemoteItems(t, file1, file2)
 }
 
+func TestRcatReadError(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	readErr := errors.New("read failed")
+	in := io.NopCloser(io.MultiReader(strings.NewReader("some data"), iotest.ErrReader(readErr)))
+	_, err := operations.Rcat(ctx, r.Fremote, "file1", in, t1)
+	assert.ErrorIs(t, err, readErr)
+
+	r.CheckRemoteItems(t)
+}
+
+func TestRcatSizeMetadata(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	features := r.Fremote.Features()
+	if !features.UserMetadata || !features.ReadMetadata {
+		t.Skip("Skipping test as remote does not support user metadata")
+	}
+
+	ci.Metadata = true
+	ci.MetadataSet = fs.Metadata{"potato": "jersey"}
+
+	const body = "------------------------------------------------------------"
+	file1 := fstest.NewItem("potato1", body, t1)
+	in := io.NopCloser(strings.NewReader(body))
+	obj, err := operations.RcatSize(ctx, r.Fremote, file1.Path, in, int64(len(body)), file1.ModTime)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1)
+
+	metadata, err := fs.GetMetadata(ctx, obj)
+	require.NoError(t, err)
+	assert.Equal(t, "jersey", metadata["potato"])
+}
+
 func TestCopyFileMaxTransfer(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
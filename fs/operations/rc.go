@@ -435,6 +435,8 @@ This returns info about the remote passed in;
                 "MergeDirs": false,
                 "MetadataInfo": true,
                 "Move": true,
+                "OpenChunkWriter": false,
+                "ResumeChunkWriter": false,
                 "OpenWriterAt": true,
                 "PublicLink": false,
                 "Purge": true,
@@ -462,6 +464,9 @@ This returns info about the remote passed in;
                 "whirlpool",
                 "crc32",
                 "sha256",
+                "blake3",
+                "xxh3",
+                "xxh128",
                 "dropbox",
                 "mailru",
                 "quickxor"
@@ -0,0 +1,277 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/kv"
+)
+
+// resumeFacility is the name of the database the state of resumable
+// uploads is kept in
+const resumeFacility = "resume"
+
+// resumeRecord is the persisted state of a resumable upload
+type resumeRecord struct {
+	Size      int64     `json:"size"`      // size of the source
+	ModTime   time.Time `json:"modTime"`   // modification time of the source
+	ChunkSize int64     `json:"chunkSize"` // chunk size the backend asked for
+	Chunks    []int     `json:"chunks"`    // chunks written so far
+	State     []byte    `json:"state"`     // state from the ResumableChunkWriter
+	Updated   time.Time `json:"updated"`   // when the record was last written
+}
+
+// resumeKey returns the database key for an upload of remote to f
+func resumeKey(f fs.Fs, remote string) string {
+	return path.Join(f.Root(), remote)
+}
+
+// opResumeGet reads the record for key
+type opResumeGet struct {
+	key    string
+	record *resumeRecord
+}
+
+func (op *opResumeGet) Do(ctx context.Context, b kv.Bucket) error {
+	data := b.Get([]byte(op.key))
+	if data == nil {
+		return nil
+	}
+	op.record = new(resumeRecord)
+	return json.Unmarshal(data, op.record)
+}
+
+// opResumePut writes the record for key or deletes it if record is nil
+type opResumePut struct {
+	key    string
+	record *resumeRecord
+}
+
+func (op *opResumePut) Do(ctx context.Context, b kv.Bucket) error {
+	if op.record == nil {
+		return b.Delete([]byte(op.key))
+	}
+	data, err := json.Marshal(op.record)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(op.key), data)
+}
+
+// opResumeList reads the records for the remotes under root
+type opResumeList struct {
+	root    string
+	records map[string]*resumeRecord
+}
+
+func (op *opResumeList) Do(ctx context.Context, b kv.Bucket) error {
+	op.records = make(map[string]*resumeRecord)
+	return b.ForEach(func(bkey, data []byte) error {
+		key := string(bkey)
+		remote := key
+		if op.root != "" {
+			if !strings.HasPrefix(key, op.root+"/") {
+				return nil
+			}
+			remote = key[len(op.root)+1:]
+		}
+		record := new(resumeRecord)
+		if err := json.Unmarshal(data, record); err != nil {
+			fs.Errorf(nil, "%s: ignoring invalid resume record: %v", key, err)
+			return nil
+		}
+		op.records[remote] = record
+		return nil
+	})
+}
+
+// uploadResumer persists the state of a multi-thread upload so it
+// can be resumed if rclone is interrupted
+type uploadResumer struct {
+	db     *kv.DB
+	key    string
+	src    fs.ObjectInfo
+	writer fs.ResumableChunkWriter
+	done   map[int]struct{} // chunks written before resuming - read only
+	mu     sync.Mutex       // protects record
+	record resumeRecord
+}
+
+// openResumableChunkWriter opens a chunk writer to upload src to
+// remote on f.
+//
+// If --multi-thread-resume is set and the backend supports it, this
+// carries on with an interrupted upload of src if there is one, and
+// returns an uploadResumer to persist the progress of the upload,
+// otherwise the uploadResumer is nil.
+func openResumableChunkWriter(ctx context.Context, f fs.Fs, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, r *uploadResumer, err error) {
+	ci := fs.GetConfig(ctx)
+	features := f.Features()
+	if !ci.MultiThreadResume || features.ResumeChunkWriter == nil || !kv.Supported() {
+		info, writer, err = features.OpenChunkWriter(ctx, remote, src, options...)
+		return info, writer, nil, err
+	}
+	db, err := kv.Start(ctx, resumeFacility, f)
+	if err != nil {
+		fs.Errorf(src, "multi-thread copy: can't resume uploads: %v", err)
+		info, writer, err = features.OpenChunkWriter(ctx, remote, src, options...)
+		return info, writer, nil, err
+	}
+	r = &uploadResumer{
+		db:  db,
+		key: resumeKey(f, remote),
+		src: src,
+	}
+	info, writer = r.resume(ctx, f, remote)
+	if writer == nil {
+		info, writer, err = features.OpenChunkWriter(ctx, remote, src, options...)
+		if err != nil {
+			r.close()
+			return info, nil, nil, err
+		}
+		r.record = resumeRecord{
+			Size:      src.Size(),
+			ModTime:   src.ModTime(ctx),
+			ChunkSize: info.ChunkSize,
+		}
+	}
+	var ok bool
+	r.writer, ok = writer.(fs.ResumableChunkWriter)
+	if !ok {
+		r.close()
+		return info, writer, nil, nil
+	}
+	r.save(-1)
+	return info, writer, r, nil
+}
+
+// resume carries on with a previous upload of r.src if possible,
+// returning a nil writer if it can't
+func (r *uploadResumer) resume(ctx context.Context, f fs.Fs, remote string) (info fs.ChunkWriterInfo, writer fs.ChunkWriter) {
+	op := &opResumeGet{key: r.key}
+	err := r.db.Do(false, op)
+	if err != nil && !errors.Is(err, kv.ErrEmpty) {
+		fs.Errorf(r.src, "multi-thread copy: failed to read resume state: %v", err)
+		return info, nil
+	}
+	record := op.record
+	if record == nil {
+		return info, nil
+	}
+	info, writer, err = f.Features().ResumeChunkWriter(ctx, remote, record.State)
+	if err != nil {
+		fs.Infof(r.src, "multi-thread copy: can't resume interrupted upload - starting again: %v", err)
+		r.remove()
+		return info, nil
+	}
+	if record.Size != r.src.Size() || !record.ModTime.Equal(r.src.ModTime(ctx)) || record.ChunkSize != info.ChunkSize {
+		fs.Infof(r.src, "multi-thread copy: source has changed since upload was interrupted - starting again")
+		err = writer.Abort(ctx)
+		if err != nil {
+			fs.Debugf(r.src, "multi-thread copy: failed to cancel interrupted upload: %v", err)
+		}
+		r.remove()
+		return info, nil
+	}
+	r.record = *record
+	r.done = make(map[int]struct{}, len(record.Chunks))
+	for _, chunk := range record.Chunks {
+		r.done[chunk] = struct{}{}
+	}
+	fs.Infof(r.src, "multi-thread copy: resuming interrupted upload with %d chunks already written", len(r.done))
+	return info, writer
+}
+
+// written returns true if chunk was written before the upload was resumed
+func (r *uploadResumer) written(chunk int) bool {
+	_, ok := r.done[chunk]
+	return ok
+}
+
+// save persists the state of the upload, adding chunk to the written
+// chunks if it is >= 0
+func (r *uploadResumer) save(chunk int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if chunk >= 0 {
+		r.record.Chunks = append(r.record.Chunks, chunk)
+	}
+	state, err := r.writer.ResumeState()
+	if err != nil {
+		fs.Errorf(r.src, "multi-thread copy: failed to read resume state: %v", err)
+		return
+	}
+	r.record.State = state
+	r.record.Updated = time.Now()
+	record := r.record
+	err = r.db.Do(true, &opResumePut{key: r.key, record: &record})
+	if err != nil {
+		fs.Errorf(r.src, "multi-thread copy: failed to save resume state: %v", err)
+	}
+}
+
+// remove deletes the persisted state of the upload
+func (r *uploadResumer) remove() {
+	err := r.db.Do(true, &opResumePut{key: r.key})
+	if err != nil {
+		fs.Errorf(r.src, "multi-thread copy: failed to remove resume state: %v", err)
+	}
+}
+
+// close releases the database
+func (r *uploadResumer) close() {
+	_ = r.db.Stop(false)
+}
+
+// abortResumableUploads aborts the interrupted uploads persisted by
+// --multi-thread-resume under the root of f
+func abortResumableUploads(ctx context.Context, f fs.Fs) error {
+	resumeChunkWriter := f.Features().ResumeChunkWriter
+	if resumeChunkWriter == nil || !kv.Supported() {
+		return nil
+	}
+	db, err := kv.Start(ctx, resumeFacility, f)
+	if err != nil {
+		return fmt.Errorf("failed to open resumable uploads: %w", err)
+	}
+	defer func() {
+		_ = db.Stop(false)
+	}()
+	op := &opResumeList{root: resumeKey(f, "")}
+	err = db.Do(false, op)
+	if errors.Is(err, kv.ErrEmpty) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read resumable uploads: %w", err)
+	}
+	var errCount int
+	for remote, record := range op.records {
+		_, writer, err := resumeChunkWriter(ctx, remote, record.State)
+		if err != nil {
+			fs.Debugf(remote, "Removing state of interrupted upload which can't be resumed: %v", err)
+		} else {
+			err = writer.Abort(ctx)
+			if err != nil {
+				fs.Errorf(remote, "Failed to abort interrupted upload: %v", err)
+				errCount++
+				continue
+			}
+			fs.Infof(remote, "Aborted interrupted upload from %v", record.Updated.Format(time.RFC3339))
+		}
+		err = db.Do(true, &opResumePut{key: resumeKey(f, remote)})
+		if err != nil {
+			return fmt.Errorf("failed to remove resume state: %w", err)
+		}
+	}
+	if errCount > 0 {
+		return fmt.Errorf("failed to abort %d interrupted uploads", errCount)
+	}
+	return nil
+}
@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/fs"
+	libhttp "github.com/rclone/rclone/lib/http"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -79,7 +79,7 @@ func TestOptionsGetMarshal(t *testing.T) {
 	ci := fs.GetConfig(ctx)
 
 	// Add some real options
-	AddOption("http", &httplib.DefaultOpt)
+	AddOption("http", &libhttp.DefaultOpt)
 	AddOption("main", ci)
 	AddOption("rc", &DefaultOpt)
 
@@ -13,12 +13,15 @@ import (
 	_ "net/http/pprof" // install the pprof http handlers
 	"time"
 
-	"github.com/rclone/rclone/cmd/serve/httplib"
+	libhttp "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/lib/http/auth"
 )
 
 // Options contains options for the remote control server
 type Options struct {
-	HTTPOptions              httplib.Options
+	HTTPOptions              libhttp.Options
+	AuthOptions              auth.Options
+	Template                 string // user specified template for directory listings
 	Enabled                  bool   // set to enable the server
 	Serve                    bool   // set to serve files from remotes
 	Files                    string // set to enable serving files locally
@@ -36,14 +39,15 @@ type Options struct {
 
 // DefaultOpt is the default values used for Options
 var DefaultOpt = Options{
-	HTTPOptions:       httplib.DefaultOpt,
+	HTTPOptions:       libhttp.DefaultOpt,
+	AuthOptions:       auth.DefaultOpt,
 	Enabled:           false,
 	JobExpireDuration: 60 * time.Second,
 	JobExpireInterval: 10 * time.Second,
 }
 
 func init() {
-	DefaultOpt.HTTPOptions.ListenAddr = "localhost:5572"
+	DefaultOpt.HTTPOptions.ListenAddr = []string{"localhost:5572"}
 }
 
 // WriteJSON writes JSON in out to w
@@ -2,9 +2,10 @@
 package rcflags
 
 import (
-	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/rc"
+	libhttp "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/lib/http/auth"
 	"github.com/spf13/pflag"
 )
 
@@ -29,5 +30,7 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &Opt.EnableMetrics, "rc-enable-metrics", "", false, "Enable prometheus metrics on /metrics")
 	flags.DurationVarP(flagSet, &Opt.JobExpireDuration, "rc-job-expire-duration", "", Opt.JobExpireDuration, "Expire finished async jobs older than this value")
 	flags.DurationVarP(flagSet, &Opt.JobExpireInterval, "rc-job-expire-interval", "", Opt.JobExpireInterval, "Interval to check for expired async jobs")
-	httpflags.AddFlagsPrefix(flagSet, "rc-", &Opt.HTTPOptions)
+	flags.StringVarP(flagSet, &Opt.Template, "rc-template", "", Opt.Template, "User-specified template")
+	libhttp.AddFlagsPrefix(flagSet, "rc-", &Opt.HTTPOptions)
+	auth.AddFlagsPrefix(flagSet, "rc-", &Opt.AuthOptions)
 }
@@ -7,7 +7,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
+	"html/template"
 	"mime"
 	"net/http"
 	"net/url"
@@ -20,7 +20,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/rclone/rclone/cmd/serve/httplib"
+	"github.com/rclone/rclone/cmd/serve/http/data"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/cache"
@@ -31,6 +31,7 @@ import (
 	"github.com/rclone/rclone/fs/rc/jobs"
 	"github.com/rclone/rclone/fs/rc/rcflags"
 	"github.com/rclone/rclone/fs/rc/webgui"
+	libhttp "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/lib/http/serve"
 	"github.com/rclone/rclone/lib/random"
 	"github.com/skratchdot/open-golang/open"
@@ -59,7 +60,10 @@ func Start(ctx context.Context, opt *rc.Options) (*Server, error) {
 	jobs.SetOpt(opt) // set the defaults for jobs
 	if opt.Enabled {
 		// Serve on the DefaultServeMux so can have global registrations appear
-		s := newServer(ctx, opt, http.DefaultServeMux)
+		s, err := newServer(ctx, opt, http.DefaultServeMux)
+		if err != nil {
+			return nil, err
+		}
 		return s, s.Serve()
 	}
 	return nil, nil
@@ -67,14 +71,15 @@ func Start(ctx context.Context, opt *rc.Options) (*Server, error) {
 
 // Server contains everything to run the rc server
 type Server struct {
-	*httplib.Server
+	*libhttp.Server
 	ctx            context.Context // for global config
 	files          http.Handler
 	pluginsHandler http.Handler
 	opt            *rc.Options
+	HTMLTemplate   *template.Template // HTML template for web interface
 }
 
-func newServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) *Server {
+func newServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) (*Server, error) {
 	fileHandler := http.Handler(nil)
 	pluginsHandler := http.Handler(nil)
 	// Add some more mime types which are often missing
@@ -97,16 +102,16 @@ func newServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) *Server
 		if opt.NoAuth {
 			fs.Logf(nil, "It is recommended to use web gui with auth.")
 		} else {
-			if opt.HTTPOptions.BasicUser == "" {
-				opt.HTTPOptions.BasicUser = "gui"
-				fs.Infof(nil, "No username specified. Using default username: %s \n", rcflags.Opt.HTTPOptions.BasicUser)
+			if opt.AuthOptions.BasicUser == "" {
+				opt.AuthOptions.BasicUser = "gui"
+				fs.Infof(nil, "No username specified. Using default username: %s \n", opt.AuthOptions.BasicUser)
 			}
-			if opt.HTTPOptions.BasicPass == "" {
+			if opt.AuthOptions.BasicPass == "" {
 				randomPass, err := random.Password(128)
 				if err != nil {
-					log.Fatalf("Failed to make password: %v", err)
+					return nil, fmt.Errorf("failed to make password: %w", err)
 				}
-				opt.HTTPOptions.BasicPass = randomPass
+				opt.AuthOptions.BasicPass = randomPass
 				fs.Infof(nil, "No password specified. Using random password: %s \n", randomPass)
 			}
 		}
@@ -118,35 +123,50 @@ func newServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) *Server
 		pluginsHandler = http.FileServer(http.Dir(webgui.PluginsPath))
 	}
 
+	htmlTemplate, err := data.GetTemplate(opt.Template)
+	if err != nil {
+		return nil, err
+	}
 	s := &Server{
-		Server:         httplib.NewServer(mux, &opt.HTTPOptions),
 		ctx:            ctx,
 		opt:            opt,
 		files:          fileHandler,
 		pluginsHandler: pluginsHandler,
+		HTMLTemplate:   htmlTemplate,
+	}
+	s.Server, err = libhttp.NewServer(ctx, opt.HTTPOptions, opt.AuthOptions)
+	if err != nil {
+		return nil, err
 	}
 	mux.HandleFunc("/", s.handler)
+	s.Router().Handle("/*", mux)
 
-	return s
+	return s, nil
 }
 
 // Serve runs the http server in the background.
 //
-// Use s.Close() and s.Wait() to shutdown server
+// Use s.Shutdown() to stop the server
 func (s *Server) Serve() error {
 	err := s.Server.Serve()
 	if err != nil {
 		return err
 	}
-	fs.Logf(nil, "Serving remote control on %s", s.URL())
+	var browserURL string
+	for _, u := range s.URLs() {
+		fs.Logf(nil, "Serving remote control on %s", u)
+		if browserURL == "" && strings.HasPrefix(u, "http") {
+			browserURL = u
+		}
+	}
 	// Open the files in the browser if set
-	if s.files != nil {
-		openURL, err := url.Parse(s.URL())
+	if s.files != nil && browserURL != "" {
+		openURL, err := url.Parse(browserURL)
 		if err != nil {
 			return fmt.Errorf("invalid serving URL: %w", err)
 		}
 		// Add username, password into the URL if they are set
-		user, pass := s.opt.HTTPOptions.BasicUser, s.opt.HTTPOptions.BasicPass
+		user, pass := s.opt.AuthOptions.BasicUser, s.opt.AuthOptions.BasicPass
 		if user != "" && pass != "" {
 			openURL.User = url.UserPassword(user, pass)
 
@@ -185,11 +205,7 @@ func writeError(path string, in rc.Params, w http.ResponseWriter, err error, sta
 
 // handler reads incoming requests and dispatches them
 func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
-	urlPath, ok := s.Path(w, r)
-	if !ok {
-		return
-	}
-	path := strings.TrimLeft(urlPath, "/")
+	path := strings.TrimLeft(r.URL.Path, "/")
 
 	allowOrigin := rcflags.Opt.AccessControlAllowOrigin
 	if allowOrigin != "" {
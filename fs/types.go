@@ -281,3 +281,42 @@ type WriterAtCloser interface {
 	io.WriterAt
 	io.Closer
 }
+
+// ChunkWriterInfo describes how a backend would like ChunkWriter called
+type ChunkWriterInfo struct {
+	ChunkSize         int64 // preferred chunk size
+	Concurrency       int   // how many chunks to write at once
+	LeavePartsOnError bool  // if set don't delete parts uploaded so far on error
+}
+
+// ChunkWriter is returned by OpenChunkWriter to upload an object in
+// chunks which may be written concurrently
+type ChunkWriter interface {
+	// WriteChunk writes chunk number chunkNumber, counting from 0,
+	// with the data read from reader.
+	//
+	// All chunks apart from the last must be ChunkSize long. The
+	// reader may be seeked to the start to retry the chunk.
+	WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (bytesWritten int64, err error)
+
+	// Close finalises the object once all the chunks have been
+	// written
+	Close(ctx context.Context) error
+
+	// Abort cancels the upload, removing the chunks written.
+	//
+	// It should be called instead of Close on failure.
+	Abort(ctx context.Context) error
+}
+
+// ResumableChunkWriter is an optional interface for a ChunkWriter
+// whose upload can be carried on later with ResumeChunkWriter
+type ResumableChunkWriter interface {
+	ChunkWriter
+
+	// ResumeState returns an opaque description of the upload,
+	// including the chunks written so far, which can be passed to
+	// ResumeChunkWriter. It may be called concurrently with
+	// WriteChunk.
+	ResumeState() (state []byte, err error)
+}
@@ -805,6 +805,46 @@ func Run(t *testing.T, opt *Opt) {
 			assert.NoError(t, f.Rmdir(ctx, "writer-at-subdir"))
 		})
 
+		t.Run("FsOpenChunkWriter", func(t *testing.T) {
+			skipIfNotOk(t)
+			openChunkWriter := f.Features().OpenChunkWriter
+			if openChunkWriter == nil {
+				t.Skip("FS has no OpenChunkWriter interface")
+			}
+			size := int64(2*5*1024*1024 + 1)
+			if *fstest.SizeLimit > 0 && size > *fstest.SizeLimit {
+				t.Skipf("exceeded file size limit %d > %d", size, *fstest.SizeLimit)
+			}
+			contents := random.String(int(size))
+			path := "chunk-writer-subdir/chunk-writer-file"
+			modTime := fstest.Time("2001-02-03T04:05:06.499999999Z")
+			obji := object.NewStaticObjectInfo(path, modTime, size, true, nil, nil)
+			info, out, err := openChunkWriter(ctx, path, obji)
+			require.NoError(t, err)
+			require.Greater(t, info.ChunkSize, int64(0))
+
+			// Write the chunks in reverse order
+			numChunks := int((size + info.ChunkSize - 1) / info.ChunkSize)
+			for chunk := numChunks - 1; chunk >= 0; chunk-- {
+				start := int64(chunk) * info.ChunkSize
+				end := start + info.ChunkSize
+				if end > size {
+					end = size
+				}
+				n, err := out.WriteChunk(ctx, chunk, strings.NewReader(contents[start:end]))
+				require.NoError(t, err)
+				assert.Equal(t, end-start, n)
+			}
+			require.NoError(t, out.Close(ctx))
+
+			obj := findObject(ctx, t, f, path)
+			assert.Equal(t, size, obj.Size())
+			assert.True(t, contents == ReadObject(ctx, t, obj, -1), "contents of file differ")
+
+			assert.NoError(t, obj.Remove(ctx))
+			_ = f.Rmdir(ctx, "chunk-writer-subdir")
+		})
+
 		// TestFsChangeNotify tests that changes are properly
 		// propagated
 		//
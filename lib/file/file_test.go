@@ -168,3 +168,25 @@ func TestIsReserved(t *testing.T) {
 	require.Error(t, IsReserved("test."))
 	require.Error(t, IsReserved("test "))
 }
+
+func TestPunchHole(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "file")
+	f, err := os.Create(fileName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, f.Close())
+	}()
+	_, err = f.WriteString("hello world")
+	require.NoError(t, err)
+
+	err = PunchHole(f, 0, 5)
+	if err == ErrPunchHoleUnsupported {
+		t.Skip("punch hole not supported")
+	}
+	require.NoError(t, err)
+	require.True(t, PunchHoleImplemented)
+
+	data, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "\x00\x00\x00\x00\x00 world", string(data))
+}
@@ -0,0 +1,32 @@
+//go:build linux
+// +build linux
+
+package file
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// PunchHoleImplemented is a constant indicating whether the
+// implementation of PunchHole actually does anything.
+const PunchHoleImplemented = true
+
+// PunchHole frees the disk space used by size bytes of out starting
+// at offset. The file stays the same size and the hole reads as zeros.
+func PunchHole(out *os.File, offset, size int64) (err error) {
+	if size <= 0 {
+		return nil
+	}
+	for {
+		err = unix.Fallocate(int(out.Fd()), unix.FALLOC_FL_KEEP_SIZE|unix.FALLOC_FL_PUNCH_HOLE, offset, size)
+		if err == unix.ENOTSUP {
+			return ErrPunchHoleUnsupported
+		}
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
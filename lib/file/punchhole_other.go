@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package file
+
+import "os"
+
+// PunchHoleImplemented is a constant indicating whether the
+// implementation of PunchHole actually does anything.
+const PunchHoleImplemented = false
+
+// PunchHole frees the disk space used by size bytes of out starting
+// at offset. The file stays the same size and the hole reads as zeros.
+func PunchHole(out *os.File, offset, size int64) error {
+	return ErrPunchHoleUnsupported
+}
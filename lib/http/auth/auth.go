@@ -2,8 +2,9 @@
 package auth
 
 import (
+	"net/http"
+
 	"github.com/rclone/rclone/fs/config/flags"
-	"github.com/rclone/rclone/lib/http"
 	"github.com/spf13/pflag"
 )
 
@@ -32,6 +33,11 @@ The password file can be updated while rclone is running.
 Use ` + "`--realm`" + ` to set the authentication realm.
 
 Use ` + "`--salt`" + ` to change the password hashing salt from the default.
+
+Use ` + "`--bearer-token`" + ` to require clients to send the token with
+an ` + "`Authorization: Bearer <token>`" + ` header.  This can be used
+alone or alongside the user based authentication above, in which case
+either is accepted.
 `
 
 // CustomAuthFn if used will be used to authenticate user, pass. If an error
@@ -42,43 +48,44 @@ type CustomAuthFn func(user, pass string) (value interface{}, err error)
 
 // Options contains options for the http authentication
 type Options struct {
-	HtPasswd  string       // htpasswd file - if not provided no authentication is done
-	Realm     string       // realm for authentication
-	BasicUser string       // single username for basic auth if not using Htpasswd
-	BasicPass string       // password for BasicUser
-	Salt      string       // password hashing salt
-	Auth      CustomAuthFn `json:"-"` // custom Auth (not set by command line flags)
+	HtPasswd    string       // htpasswd file - if not provided no authentication is done
+	Realm       string       // realm for authentication
+	BasicUser   string       // single username for basic auth if not using Htpasswd
+	BasicPass   string       // password for BasicUser
+	Salt        string       // password hashing salt
+	BearerToken string       // token to accept in an Authorization: Bearer header
+	Auth        CustomAuthFn `json:"-"` // custom Auth (not set by command line flags)
 }
 
-// Auth instantiates middleware that authenticates users based on the configuration
-func Auth(opt Options) http.Middleware {
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	Realm: "rclone",
+	Salt:  "dlPL2MqE",
+}
+
+// Auth instantiates middleware that authenticates users based on the
+// configuration. It returns nil if no authentication is configured.
+func Auth(opt Options) func(http.Handler) http.Handler {
+	var basic func(http.Handler) http.Handler
 	if opt.Auth != nil {
-		return CustomAuth(opt.Auth, opt.Realm)
+		basic = CustomAuth(opt.Auth, opt.Realm)
 	} else if opt.HtPasswd != "" {
-		return HtPasswdAuth(opt.HtPasswd, opt.Realm)
+		basic = HtPasswdAuth(opt.HtPasswd, opt.Realm)
 	} else if opt.BasicUser != "" {
-		return SingleAuth(opt.BasicUser, opt.BasicPass, opt.Realm, opt.Salt)
+		basic = SingleAuth(opt.BasicUser, opt.BasicPass, opt.Realm, opt.Salt)
 	}
-	return nil
-}
-
-// Options set by command line flags
-var (
-	Opt = Options{
-		Salt: "dlPL2MqE",
+	if opt.BearerToken != "" {
+		return BearerAuth(opt.BearerToken, opt.Realm, basic)
 	}
-)
+	return basic
+}
 
 // AddFlagsPrefix adds flags for http/auth
 func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *Options) {
-	flags.StringVarP(flagSet, &Opt.HtPasswd, prefix+"htpasswd", "", Opt.HtPasswd, "A htpasswd file - if not provided no authentication is done")
+	flags.StringVarP(flagSet, &Opt.HtPasswd, prefix+"htpasswd", "", Opt.HtPasswd, "htpasswd file - if not provided no authentication is done")
 	flags.StringVarP(flagSet, &Opt.Realm, prefix+"realm", "", Opt.Realm, "Realm for authentication")
 	flags.StringVarP(flagSet, &Opt.BasicUser, prefix+"user", "", Opt.BasicUser, "User name for authentication")
 	flags.StringVarP(flagSet, &Opt.BasicPass, prefix+"pass", "", Opt.BasicPass, "Password for authentication")
 	flags.StringVarP(flagSet, &Opt.Salt, prefix+"salt", "", Opt.Salt, "Password hashing salt")
-}
-
-// AddFlags adds flags for the http/auth
-func AddFlags(flagSet *pflag.FlagSet) {
-	AddFlagsPrefix(flagSet, "", &Opt)
+	flags.StringVarP(flagSet, &Opt.BearerToken, prefix+"bearer-token", "", Opt.BearerToken, "Token to accept in an Authorization: Bearer header")
 }
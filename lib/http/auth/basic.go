@@ -2,13 +2,13 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/base64"
 	"net/http"
 	"strings"
 
 	auth "github.com/abbot/go-http-auth"
 	"github.com/rclone/rclone/fs"
-	httplib "github.com/rclone/rclone/lib/http"
 )
 
 // parseAuthorization parses the Authorization header into user, pass
@@ -63,7 +63,7 @@ func NewLoggedBasicAuthenticator(realm string, secrets auth.SecretProvider) *Log
 }
 
 // Helper to generate required interface for middleware
-func basicAuth(authenticator *LoggedBasicAuth) httplib.Middleware {
+func basicAuth(authenticator *LoggedBasicAuth) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if username := authenticator.CheckAuth(r); username == "" {
@@ -77,7 +77,7 @@ func basicAuth(authenticator *LoggedBasicAuth) httplib.Middleware {
 }
 
 // HtPasswdAuth instantiates middleware that authenticates against the passed htpasswd file
-func HtPasswdAuth(path, realm string) httplib.Middleware {
+func HtPasswdAuth(path, realm string) func(http.Handler) http.Handler {
 	fs.Infof(nil, "Using %q as htpasswd storage", path)
 	secretProvider := auth.HtpasswdFileProvider(path)
 	authenticator := NewLoggedBasicAuthenticator(realm, secretProvider)
@@ -85,7 +85,7 @@ func HtPasswdAuth(path, realm string) httplib.Middleware {
 }
 
 // SingleAuth instantiates middleware that authenticates for a single user
-func SingleAuth(user, pass, realm, salt string) httplib.Middleware {
+func SingleAuth(user, pass, realm, salt string) func(http.Handler) http.Handler {
 	fs.Infof(nil, "Using --user %s --pass XXXX as authenticated user", user)
 	pass = string(auth.MD5Crypt([]byte(pass), []byte(salt), []byte("$1$")))
 	secretProvider := func(u, r string) string {
@@ -99,22 +99,58 @@ func SingleAuth(user, pass, realm, salt string) httplib.Middleware {
 }
 
 // CustomAuth instantiates middleware that authenticates using a custom function
-func CustomAuth(fn CustomAuthFn, realm string) httplib.Middleware {
+func CustomAuth(fn CustomAuthFn, realm string) func(http.Handler) http.Handler {
+	// Reuse BasicAuth error reporting
+	authenticator := auth.NewBasicAuthenticator(realm, func(user, realm string) string { return "" })
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			user, pass, ok := parseAuthorization(r)
-			if ok {
-				value, err := fn(user, pass)
-				if err != nil {
-					fs.Infof(r.URL.Path, "%s: Auth failed from %s: %v", r.RemoteAddr, user, err)
-					auth.NewBasicAuthenticator(realm, func(user, realm string) string { return "" }).RequireAuth(w, r) //Reuse BasicAuth error reporting
-					return
-				}
-				if value != nil {
-					r = r.WithContext(context.WithValue(r.Context(), ContextAuthKey, value))
-				}
-				next.ServeHTTP(w, r)
+			if !ok {
+				authenticator.RequireAuth(w, r)
+				return
+			}
+			value, err := fn(user, pass)
+			if err != nil {
+				fs.Infof(r.URL.Path, "%s: Auth failed from %s: %v", r.RemoteAddr, user, err)
+				authenticator.RequireAuth(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), ContextUserKey, user)
+			if value != nil {
+				ctx = context.WithValue(ctx, ContextAuthKey, value)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BearerAuth instantiates middleware that authenticates requests
+// carrying an "Authorization: Bearer" header against token.
+//
+// Requests without a bearer token are passed to fallback if it is
+// set, otherwise they are refused.
+func BearerAuth(token, realm string, fallback func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	fs.Infof(nil, "Using --bearer-token XXXX for authentication")
+	return func(next http.Handler) http.Handler {
+		var fallbackHandler http.Handler
+		if fallback != nil {
+			fallbackHandler = fallback(next)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			hasBearer := strings.HasPrefix(authHeader, "Bearer ")
+			if !hasBearer && fallbackHandler != nil {
+				fallbackHandler.ServeHTTP(w, r)
+				return
+			}
+			got := strings.TrimPrefix(authHeader, "Bearer ")
+			if !hasBearer || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				fs.Infof(r.URL.Path, "%s: Unauthorized request with missing or bad bearer token", r.RemoteAddr)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+realm+`"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
 			}
+			next.ServeHTTP(w, r)
 		})
 	}
 }
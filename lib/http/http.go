@@ -7,16 +7,19 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/lib/http/auth"
 	"github.com/spf13/pflag"
 )
 
@@ -33,6 +36,12 @@ IPs.  By default it only listens on localhost.  You can use port
 If you set ` + "`--addr`" + ` to listen on a public or LAN accessible IP address
 then using Authentication is advised - see the next section for info.
 
+` + "`--addr`" + ` may be repeated to listen on several addresses at once,
+eg ` + "`--addr 127.0.0.1:8080 --addr [::1]:8080`" + `.  An address of the
+form ` + "`unix:///path/to/socket`" + ` or an absolute path will listen on a
+unix domain socket instead.  Unix sockets are always served over plain
+HTTP.
+
 ` + "`--server-read-timeout` and `--server-write-timeout`" + ` can be used to
 control the timeouts on the server.  Note that this is the total time
 for a transfer.
@@ -70,7 +79,7 @@ type Middleware func(http.Handler) http.Handler
 
 // Options contains options for the http Server
 type Options struct {
-	ListenAddr         string        // Port to listen on
+	ListenAddr         []string      // Addresses to listen on - host:port or unix:///path
 	BaseURL            string        // prefix to strip from URLs
 	ServerReadTimeout  time.Duration // Timeout for server reading data
 	ServerWriteTimeout time.Duration // Timeout for server writing data
@@ -85,121 +94,110 @@ type Options struct {
 
 // DefaultOpt is the default values used for Options
 var DefaultOpt = Options{
-	ListenAddr:         "127.0.0.1:8080",
+	ListenAddr:         []string{"127.0.0.1:8080"},
 	ServerReadTimeout:  1 * time.Hour,
 	ServerWriteTimeout: 1 * time.Hour,
 	MaxHeaderBytes:     4096,
 	MinTLSVersion:      "tls1.0",
 }
 
-// Server interface of http server
-type Server interface {
-	Router() chi.Router
-	Route(pattern string, fn func(r chi.Router)) chi.Router
-	Mount(pattern string, h http.Handler)
-	Shutdown() error
+// Server is an http server listening on one or more addresses
+type Server struct {
+	ctx       context.Context
+	opt       Options
+	router    chi.Router
+	handler   http.Handler
+	tlsConfig *tls.Config
+	usingAuth bool
+
+	mu        sync.Mutex
+	instances []instance
+	wg        sync.WaitGroup
 }
 
-type server struct {
-	addrs        []net.Addr
-	tlsAddrs     []net.Addr
-	listeners    []net.Listener
-	tlsListeners []net.Listener
-	httpServer   *http.Server
-	baseRouter   chi.Router
-	closing      *sync.WaitGroup
-	useSSL       bool
+// instance is a single listener being served by the Server
+type instance struct {
+	url        string
+	listener   net.Listener
+	httpServer *http.Server
 }
 
-var (
-	defaultServer        *server
-	defaultServerOptions = DefaultOpt
-	defaultServerMutex   sync.Mutex
-)
-
 func useSSL(opt Options) bool {
 	return opt.SslKey != "" || len(opt.SslKeyBody) > 0
 }
 
-// NewServer instantiates a new http server using provided listeners and options
-// This function is provided if the default http server does not meet a services requirements and should not generally be used
-// A http server can listen using multiple listeners. For example, a listener for port 80, and a listener for port 443.
-// tlsListeners are ignored if opt.SslKey is not provided
-func NewServer(listeners, tlsListeners []net.Listener, opt Options) (Server, error) {
-	// Validate input
-	if len(listeners) == 0 && len(tlsListeners) == 0 {
-		return nil, errors.New("can't create server without listeners")
-	}
-
-	// Prepare TLS config
-	var tlsConfig *tls.Config
-
-	useSSL := useSSL(opt)
+// newTLSConfig makes the TLS config from opt or returns nil if SSL
+// isn't configured
+func newTLSConfig(opt Options) (*tls.Config, error) {
 	if (len(opt.SslCertBody) > 0) != (len(opt.SslKeyBody) > 0) {
-		err := errors.New("need both SslCertBody and SslKeyBody to use SSL")
-		log.Fatalf(err.Error())
-		return nil, err
+		return nil, errors.New("need both SslCertBody and SslKeyBody to use SSL")
 	}
 	if (opt.SslCert != "") != (opt.SslKey != "") {
-		err := errors.New("need both -cert and -key to use SSL")
-		log.Fatalf(err.Error())
-		return nil, err
+		return nil, errors.New("need both --cert and --key to use SSL")
 	}
-
-	if useSSL {
-		var cert tls.Certificate
-		var err error
-		if len(opt.SslCertBody) > 0 {
-			cert, err = tls.X509KeyPair(opt.SslCertBody, opt.SslKeyBody)
-		} else {
-			cert, err = tls.LoadX509KeyPair(opt.SslCert, opt.SslKey)
-		}
-		if err != nil {
-			log.Fatal(err)
-		}
-		var minTLSVersion uint16
-		switch opt.MinTLSVersion {
-		case "tls1.0":
-			minTLSVersion = tls.VersionTLS10
-		case "tls1.1":
-			minTLSVersion = tls.VersionTLS11
-		case "tls1.2":
-			minTLSVersion = tls.VersionTLS12
-		case "tls1.3":
-			minTLSVersion = tls.VersionTLS13
-		default:
-			err = errors.New("Invalid value for --min-tls-version")
-			log.Fatalf(err.Error())
-			return nil, err
+	if !useSSL(opt) {
+		if opt.ClientCA != "" {
+			return nil, errors.New("can't use --client-ca without --cert and --key")
 		}
-		tlsConfig = &tls.Config{
-			MinVersion:   minTLSVersion,
-			Certificates: []tls.Certificate{cert},
-		}
-	} else if len(listeners) == 0 && len(tlsListeners) != 0 {
-		return nil, errors.New("no SslKey or non-tlsListeners")
+		return nil, nil
+	}
+
+	var cert tls.Certificate
+	var err error
+	if len(opt.SslCertBody) > 0 {
+		cert, err = tls.X509KeyPair(opt.SslCertBody, opt.SslKeyBody)
+	} else {
+		cert, err = tls.LoadX509KeyPair(opt.SslCert, opt.SslKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSL key pair: %w", err)
+	}
+	var minTLSVersion uint16
+	switch opt.MinTLSVersion {
+	case "tls1.0":
+		minTLSVersion = tls.VersionTLS10
+	case "tls1.1":
+		minTLSVersion = tls.VersionTLS11
+	case "tls1.2":
+		minTLSVersion = tls.VersionTLS12
+	case "tls1.3":
+		minTLSVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("invalid value %q for --min-tls-version", opt.MinTLSVersion)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:   minTLSVersion,
+		Certificates: []tls.Certificate{cert},
 	}
 
 	if opt.ClientCA != "" {
-		if !useSSL {
-			err := errors.New("can't use --client-ca without --cert and --key")
-			log.Fatalf(err.Error())
-			return nil, err
-		}
 		certpool := x509.NewCertPool()
 		pem, err := os.ReadFile(opt.ClientCA)
 		if err != nil {
-			log.Fatalf("Failed to read client certificate authority: %v", err)
-			return nil, err
+			return nil, fmt.Errorf("failed to read client certificate authority: %w", err)
 		}
 		if !certpool.AppendCertsFromPEM(pem) {
-			err := errors.New("can't parse client certificate authority")
-			log.Fatalf(err.Error())
-			return nil, err
+			return nil, errors.New("can't parse client certificate authority")
 		}
 		tlsConfig.ClientCAs = certpool
 		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
 	}
+	return tlsConfig, nil
+}
+
+// NewServer makes a new http server from opt using authOpt to
+// authenticate requests.
+//
+// Handlers should be added to Router() before calling Serve to start
+// listening. The ctx is used as the base context for all requests.
+func NewServer(ctx context.Context, opt Options, authOpt auth.Options) (*Server, error) {
+	if len(opt.ListenAddr) == 0 {
+		return nil, errors.New("can't create server without listen addresses")
+	}
+	tlsConfig, err := newTLSConfig(opt)
+	if err != nil {
+		return nil, err
+	}
 
 	// Ignore passing "/" for BaseURL
 	opt.BaseURL = strings.Trim(opt.BaseURL, "/")
@@ -208,7 +206,7 @@ func NewServer(listeners, tlsListeners []net.Listener, opt Options) (Server, err
 	}
 
 	// Build base router
-	var router chi.Router = chi.NewRouter()
+	router := chi.NewRouter()
 	router.MethodNotAllowed(func(w http.ResponseWriter, _ *http.Request) {
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	})
@@ -216,214 +214,201 @@ func NewServer(listeners, tlsListeners []net.Listener, opt Options) (Server, err
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 	})
 
-	handler := router.(http.Handler)
-	if opt.BaseURL != "" {
-		handler = http.StripPrefix(opt.BaseURL, handler)
+	s := &Server{
+		ctx:       ctx,
+		opt:       opt,
+		router:    router,
+		tlsConfig: tlsConfig,
 	}
-
-	// Serve on listeners
-	httpServer := &http.Server{
-		Handler:           handler,
-		ReadTimeout:       opt.ServerReadTimeout,
-		WriteTimeout:      opt.ServerWriteTimeout,
-		MaxHeaderBytes:    opt.MaxHeaderBytes,
-		ReadHeaderTimeout: 10 * time.Second, // time to send the headers
-		IdleTimeout:       60 * time.Second, // time to keep idle connections open
-		TLSConfig:         tlsConfig,
+	if m := auth.Auth(authOpt); m != nil {
+		s.usingAuth = true
+		router.Use(skipOptions(m))
 	}
-
-	addrs, tlsAddrs := make([]net.Addr, len(listeners)), make([]net.Addr, len(tlsListeners))
-
-	wg := &sync.WaitGroup{}
-
-	for i, l := range listeners {
-		addrs[i] = l.Addr()
+	s.handler = router
+	if opt.BaseURL != "" {
+		s.handler = stripPrefix(opt.BaseURL, router)
 	}
+	return s, nil
+}
 
-	if useSSL {
-		for i, l := range tlsListeners {
-			tlsAddrs[i] = l.Addr()
-		}
+// skipOptions applies the middleware m to all requests except those
+// with the OPTIONS method which need no auth for CORS preflight
+func skipOptions(m func(http.Handler) http.Handler) Middleware {
+	return func(next http.Handler) http.Handler {
+		authed := m(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authed.ServeHTTP(w, r)
+		})
 	}
-
-	return &server{addrs, tlsAddrs, listeners, tlsListeners, httpServer, router, wg, useSSL}, nil
 }
 
-func (s *server) Serve() {
-	serve := func(l net.Listener, tls bool) {
-		defer s.closing.Done()
-		var err error
-		if tls {
-			err = s.httpServer.ServeTLS(l, "", "")
-		} else {
-			err = s.httpServer.Serve(l)
+// stripPrefix removes prefix from the request path before passing it
+// to next. Requests for exactly prefix are redirected to prefix+"/"
+// and requests outside prefix get a 404.
+func stripPrefix(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		urlPath := r.URL.Path
+		if !strings.HasPrefix(urlPath, prefix+"/") {
+			// Send a redirect if the BaseURL was requested without a /
+			if urlPath == prefix {
+				http.Redirect(w, r, prefix+"/", http.StatusPermanentRedirect)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
 		}
-		if err != http.ErrServerClosed && err != nil {
-			log.Fatalf(err.Error())
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = urlPath[len(prefix):]
+		r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// listen opens a listener for addr returning it and the URL it
+// serves on
+func (s *Server) listen(addr string) (l net.Listener, u string, err error) {
+	if strings.HasPrefix(addr, "unix://") || filepath.IsAbs(addr) {
+		path := strings.TrimPrefix(addr, "unix://")
+		l, err = net.Listen("unix", path)
+		if err != nil {
+			return nil, "", err
 		}
+		return l, "unix://" + path + s.opt.BaseURL + "/", nil
 	}
-
-	s.closing.Add(len(s.listeners))
-	for _, l := range s.listeners {
-		go serve(l, false)
+	l, err = net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", err
 	}
-
-	if s.useSSL {
-		s.closing.Add(len(s.tlsListeners))
-		for _, l := range s.tlsListeners {
-			go serve(l, true)
-		}
+	proto := "http"
+	if s.tlsConfig != nil {
+		l = tls.NewListener(l, s.tlsConfig)
+		proto = "https"
 	}
-}
-
-// Wait blocks while the server is serving requests
-func (s *server) Wait() {
-	s.closing.Wait()
-}
-
-// Router returns the server base router
-func (s *server) Router() chi.Router {
-	return s.baseRouter
-}
-
-// Route mounts a sub-Router along a `pattern` string.
-func (s *server) Route(pattern string, fn func(r chi.Router)) chi.Router {
-	return s.baseRouter.Route(pattern, fn)
-}
-
-// Mount attaches another http.Handler along ./pattern/*
-func (s *server) Mount(pattern string, h http.Handler) {
-	s.baseRouter.Mount(pattern, h)
-}
-
-// Shutdown gracefully shuts down the server
-func (s *server) Shutdown() error {
-	if err := s.httpServer.Shutdown(context.Background()); err != nil {
-		return err
+	// prefer actual listener address if using ":port" or "addr:0"
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || port == "0" {
+		addr = l.Addr().String()
 	}
-	s.closing.Wait()
-	return nil
+	return l, fmt.Sprintf("%s://%s%s/", proto, addr, s.opt.BaseURL), nil
 }
 
-//---- Default HTTP server convenience functions ----
-
-// Router returns the server base router
-func Router() (chi.Router, error) {
-	if err := start(); err != nil {
-		return nil, err
+// Serve starts serving on all the listen addresses in the background.
+//
+// Use Shutdown to stop the server and Wait to wait for it to stop.
+func (s *Server) Serve() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.instances) != 0 {
+		return errors.New("server already started")
 	}
-	return defaultServer.baseRouter, nil
-}
-
-// Route mounts a sub-Router along a `pattern` string.
-func Route(pattern string, fn func(r chi.Router)) (chi.Router, error) {
-	if err := start(); err != nil {
-		return nil, err
+	for _, addr := range s.opt.ListenAddr {
+		l, u, err := s.listen(addr)
+		if err != nil {
+			for _, ii := range s.instances {
+				_ = ii.listener.Close()
+			}
+			s.instances = nil
+			return fmt.Errorf("failed to listen on %q: %w", addr, err)
+		}
+		s.instances = append(s.instances, instance{
+			url:      u,
+			listener: l,
+			httpServer: &http.Server{
+				Handler:           s.handler,
+				ReadTimeout:       s.opt.ServerReadTimeout,
+				WriteTimeout:      s.opt.ServerWriteTimeout,
+				MaxHeaderBytes:    s.opt.MaxHeaderBytes,
+				ReadHeaderTimeout: 10 * time.Second, // time to send the headers
+				IdleTimeout:       60 * time.Second, // time to keep idle connections open
+				TLSConfig:         s.tlsConfig,
+				BaseContext: func(net.Listener) context.Context {
+					return s.ctx
+				},
+			},
+		})
 	}
-	return defaultServer.Route(pattern, fn), nil
-}
-
-// Mount attaches another http.Handler along ./pattern/*
-func Mount(pattern string, h http.Handler) error {
-	if err := start(); err != nil {
-		return err
+	s.wg.Add(len(s.instances))
+	for _, ii := range s.instances {
+		go func(ii instance) {
+			defer s.wg.Done()
+			err := ii.httpServer.Serve(ii.listener)
+			if err != nil && err != http.ErrServerClosed {
+				fs.Errorf(nil, "Error serving %s: %v", ii.url, err)
+			}
+		}(ii)
 	}
-	defaultServer.Mount(pattern, h)
 	return nil
 }
 
-// Restart or start the default http server using the default options and no handlers
-func Restart() error {
-	if e := Shutdown(); e != nil {
-		return e
-	}
-
-	return start()
+// Wait blocks while the server is serving requests
+func (s *Server) Wait() {
+	s.wg.Wait()
 }
 
-// Wait blocks while the default http server is serving requests
-func Wait() {
-	defaultServer.Wait()
+// Router returns the server base router
+func (s *Server) Router() chi.Router {
+	return s.router
 }
 
-// Start the default server
-func start() error {
-	defaultServerMutex.Lock()
-	defer defaultServerMutex.Unlock()
-
-	if defaultServer != nil {
-		// Server already started, do nothing
-		return nil
-	}
-
-	var err error
-	var l net.Listener
-	l, err = net.Listen("tcp", defaultServerOptions.ListenAddr)
-	if err != nil {
-		return err
-	}
-
-	var s Server
-	if useSSL(defaultServerOptions) {
-		s, err = NewServer([]net.Listener{}, []net.Listener{l}, defaultServerOptions)
-	} else {
-		s, err = NewServer([]net.Listener{l}, []net.Listener{}, defaultServerOptions)
-	}
-	if err != nil {
-		return err
+// Shutdown gracefully shuts down the server
+func (s *Server) Shutdown() error {
+	s.mu.Lock()
+	instances := s.instances
+	s.mu.Unlock()
+	var firstErr error
+	for _, ii := range instances {
+		if err := ii.httpServer.Shutdown(context.Background()); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	defaultServer = s.(*server)
-	defaultServer.Serve()
-	return nil
+	s.wg.Wait()
+	return firstErr
 }
 
-// Shutdown gracefully shuts down the default http server
-func Shutdown() error {
-	defaultServerMutex.Lock()
-	defer defaultServerMutex.Unlock()
-	if defaultServer != nil {
-		s := defaultServer
-		defaultServer = nil
-		return s.Shutdown()
+// URLs returns the URLs the server is serving on. These are only
+// known once Serve has been called.
+func (s *Server) URLs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls := make([]string, 0, len(s.instances))
+	for _, ii := range s.instances {
+		urls = append(urls, ii.url)
 	}
-	return nil
+	return urls
 }
 
-// GetOptions thread safe getter for the default server options
-func GetOptions() Options {
-	defaultServerMutex.Lock()
-	defer defaultServerMutex.Unlock()
-	return defaultServerOptions
+// URL returns the URL of the first address the server is serving on
+func (s *Server) URL() string {
+	urls := s.URLs()
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[0]
 }
 
-// SetOptions thread safe setter for the default server options
-func SetOptions(opt Options) {
-	defaultServerMutex.Lock()
-	defer defaultServerMutex.Unlock()
-	defaultServerOptions = opt
+// UsingAuth returns true if authentication is required
+func (s *Server) UsingAuth() bool {
+	return s.usingAuth
 }
 
-//---- Utility functions ----
-
-// URL of default http server
-func URL() string {
-	if defaultServer == nil {
-		panic("Server not running")
-	}
-	for _, a := range defaultServer.addrs {
-		return fmt.Sprintf("http://%s%s/", a.String(), defaultServerOptions.BaseURL)
-	}
-	for _, a := range defaultServer.tlsAddrs {
-		return fmt.Sprintf("https://%s%s/", a.String(), defaultServerOptions.BaseURL)
-	}
-	panic("Server is running with no listener")
+// BaseURL returns the normalised URL prefix the server strips from
+// requests, either "" or "/prefix"
+func (s *Server) BaseURL() string {
+	return s.opt.BaseURL
 }
 
 //---- Command line flags ----
 
 // AddFlagsPrefix adds flags for the httplib
 func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *Options) {
-	flags.StringVarP(flagSet, &Opt.ListenAddr, prefix+"addr", "", Opt.ListenAddr, "IPaddress:Port or :Port to bind server to")
+	flags.StringArrayVarP(flagSet, &Opt.ListenAddr, prefix+"addr", "", Opt.ListenAddr, "IPaddress:Port, :Port or unix:///path to bind server to (can be repeated)")
 	flags.DurationVarP(flagSet, &Opt.ServerReadTimeout, prefix+"server-read-timeout", "", Opt.ServerReadTimeout, "Timeout for server reading data")
 	flags.DurationVarP(flagSet, &Opt.ServerWriteTimeout, prefix+"server-write-timeout", "", Opt.ServerWriteTimeout, "Timeout for server writing data")
 	flags.IntVarP(flagSet, &Opt.MaxHeaderBytes, prefix+"max-header-bytes", "", Opt.MaxHeaderBytes, "Maximum size of request header")
@@ -432,10 +417,4 @@ func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *Options) {
 	flags.StringVarP(flagSet, &Opt.ClientCA, prefix+"client-ca", "", Opt.ClientCA, "Client certificate authority to verify clients with")
 	flags.StringVarP(flagSet, &Opt.BaseURL, prefix+"baseurl", "", Opt.BaseURL, "Prefix for URLs - leave blank for root")
 	flags.StringVarP(flagSet, &Opt.MinTLSVersion, prefix+"min-tls-version", "", Opt.MinTLSVersion, "Minimum TLS version that is acceptable")
-
-}
-
-// AddFlags adds flags for the httplib
-func AddFlags(flagSet *pflag.FlagSet) {
-	AddFlagsPrefix(flagSet, "", &defaultServerOptions)
 }
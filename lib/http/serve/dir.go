@@ -2,6 +2,7 @@ package serve
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -18,12 +19,14 @@ import (
 
 // DirEntry is a directory entry
 type DirEntry struct {
-	remote  string
-	URL     string
-	Leaf    string
-	IsDir   bool
-	Size    int64
-	ModTime time.Time
+	remote   string
+	URL      string
+	Leaf     string
+	IsDir    bool
+	Size     int64
+	ModTime  time.Time
+	MimeType string
+	Hashes   map[string]string
 }
 
 // Directory represents a directory
@@ -37,6 +40,7 @@ type Directory struct {
 	Breadcrumb   []Crumb
 	Sort         string
 	Order        string
+	ReadWrite    bool // set if the directory can be changed
 }
 
 // Crumb is a breadcrumb entry
@@ -84,24 +88,49 @@ func (d *Directory) SetQuery(queryParams url.Values) *Directory {
 	return d
 }
 
-// AddHTMLEntry adds an entry to that directory
-func (d *Directory) AddHTMLEntry(remote string, isDir bool, size int64, modTime time.Time) {
+// relative returns the path of remote relative to the directory.
+//
+// This is the leaf for the entries of the directory itself and a path
+// for entries of a recursive listing.
+func (d *Directory) relative(remote string) string {
+	if d.DirRemote == "" && remote != "" {
+		return remote
+	}
+	if strings.HasPrefix(remote, d.DirRemote+"/") {
+		return remote[len(d.DirRemote)+1:]
+	}
 	leaf := path.Base(remote)
 	if leaf == "." {
 		leaf = ""
 	}
+	return leaf
+}
+
+// AddHTMLEntry adds an entry to that directory
+func (d *Directory) AddHTMLEntry(remote string, isDir bool, size int64, modTime time.Time) {
+	d.AddFullEntry(remote, isDir, size, modTime, "", nil)
+}
+
+// AddFullEntry adds an entry to that directory with the mime type
+// and hashes shown in JSON listings.
+//
+// The remote may be below the directory for recursive listings.
+func (d *Directory) AddFullEntry(remote string, isDir bool, size int64, modTime time.Time, mimeType string, hashes map[string]string) {
+	leaf := d.relative(remote)
 	urlRemote := leaf
 	if isDir {
 		leaf += "/"
 		urlRemote += "/"
 	}
 	d.Entries = append(d.Entries, DirEntry{
-		remote:  remote,
-		URL:     rest.URLPathEscape(urlRemote) + d.Query,
-		Leaf:    leaf,
-		IsDir:   isDir,
-		Size:    size,
-		ModTime: modTime,
+		remote:   remote,
+		URL:      rest.URLPathEscape(urlRemote) + d.Query,
+		Leaf:     leaf,
+		IsDir:    isDir,
+		Size:     size,
+		ModTime:  modTime,
+		MimeType: mimeType,
+		Hashes:   hashes,
 	})
 }
 
@@ -240,3 +269,61 @@ func (d *Directory) Serve(w http.ResponseWriter, r *http.Request) {
 		Error(d.DirRemote, nil, "Failed to drain template buffer", err)
 	}
 }
+
+// JSONEntry is an entry in a JSON directory listing
+type JSONEntry struct {
+	Path     string
+	Name     string
+	URL      string
+	Size     int64
+	MimeType string `json:",omitempty"`
+	ModTime  time.Time
+	IsDir    bool
+	Hashes   map[string]string `json:",omitempty"`
+}
+
+// JSONDirectory is a JSON directory listing
+type JSONDirectory struct {
+	Path    string
+	Entries []JSONEntry
+}
+
+// ServeJSON serves a directory as JSON
+func (d *Directory) ServeJSON(w http.ResponseWriter, r *http.Request) {
+	// Account the transfer
+	tr := accounting.Stats(r.Context()).NewTransferRemoteSize(d.DirRemote, -1)
+	defer tr.Done(r.Context(), nil)
+
+	fs.Infof(d.DirRemote, "%s: Serving directory as JSON", r.RemoteAddr)
+
+	out := JSONDirectory{
+		Path:    d.DirRemote,
+		Entries: make([]JSONEntry, 0, len(d.Entries)),
+	}
+	for _, entry := range d.Entries {
+		name := path.Base(entry.remote)
+		if entry.remote == "" {
+			name = ""
+		}
+		out.Entries = append(out.Entries, JSONEntry{
+			Path:     entry.remote,
+			Name:     name,
+			URL:      entry.URL,
+			Size:     entry.Size,
+			MimeType: entry.MimeType,
+			ModTime:  entry.ModTime,
+			IsDir:    entry.IsDir,
+			Hashes:   entry.Hashes,
+		})
+	}
+	buf, err := json.Marshal(out)
+	if err != nil {
+		Error(d.DirRemote, w, "Failed to make JSON", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(buf)
+	if err != nil {
+		Error(d.DirRemote, nil, "Failed to write JSON", err)
+	}
+}
@@ -123,3 +123,41 @@ func TestServe(t *testing.T) {
 </html>
 `, string(body))
 }
+
+func TestAddFullEntry(t *testing.T) {
+	var modtime = time.Now()
+	var d = NewDirectory("z", GetTemplate(t))
+	d.AddFullEntry("z/file.txt", false, 64, modtime, "text/plain", map[string]string{"md5": "abc"})
+	d.AddFullEntry("z/dir/sub dir", true, 0, modtime, "inode/directory", nil)
+	assert.Equal(t, []DirEntry{
+		{remote: "z/file.txt", URL: "file.txt", Leaf: "file.txt", Size: 64, ModTime: modtime, MimeType: "text/plain", Hashes: map[string]string{"md5": "abc"}},
+		{remote: "z/dir/sub dir", URL: "dir/sub%20dir/", Leaf: "dir/sub dir/", IsDir: true, ModTime: modtime, MimeType: "inode/directory"},
+	}, d.Entries)
+
+	// Everything is below the root
+	d = NewDirectory("", GetTemplate(t))
+	d.AddFullEntry("a/b.txt", false, 1, modtime, "", nil)
+	assert.Equal(t, "a/b.txt", d.Entries[0].Leaf)
+}
+
+func TestServeJSON(t *testing.T) {
+	modtime := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := NewDirectory("aDirectory", GetTemplate(t))
+	d.AddFullEntry("aDirectory/file", false, 5, modtime, "text/plain", map[string]string{"md5": "abc"})
+	d.AddHTMLEntry("aDirectory/dir", true, 0, modtime)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/aDirectory/?format=json", nil)
+	d.ServeJSON(w, r)
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{
+	"Path": "aDirectory",
+	"Entries": [
+		{"Path": "aDirectory/file", "Name": "file", "URL": "file", "Size": 5, "MimeType": "text/plain", "ModTime": "2000-01-02T03:04:05Z", "IsDir": false, "Hashes": {"md5": "abc"}},
+		{"Path": "aDirectory/dir", "Name": "dir", "URL": "dir/", "Size": 0, "ModTime": "2000-01-02T03:04:05Z", "IsDir": true}
+	]
+}`, string(body))
+}
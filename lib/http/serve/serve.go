@@ -2,11 +2,15 @@
 package serve
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
@@ -43,7 +47,12 @@ func Object(w http.ResponseWriter, r *http.Request, o fs.Object) {
 	code := http.StatusOK
 	size := o.Size()
 	var options []fs.OpenOption
-	if rangeRequest := r.Header.Get("Range"); rangeRequest != "" {
+	if rangeRequest := r.Header.Get("Range"); strings.ContainsRune(rangeRequest, ',') {
+		if objectRanges(w, r, o, rangeRequest, w.Header().Get("Content-Type")) {
+			return
+		}
+		// Otherwise serve the whole object
+	} else if rangeRequest != "" {
 		//fs.Debugf(nil, "Range: request %q", rangeRequest)
 		option, err := fs.ParseRangeOption(rangeRequest)
 		if err != nil {
@@ -89,3 +98,108 @@ func Object(w http.ResponseWriter, r *http.Request, o fs.Object) {
 		return
 	}
 }
+
+// parseRanges parses a Range header with several ranges in
+func parseRanges(rangeRequest string) (options []*fs.RangeOption, err error) {
+	const preamble = "bytes="
+	if !strings.HasPrefix(rangeRequest, preamble) {
+		return nil, errors.New("range: header invalid: doesn't start with " + preamble)
+	}
+	for _, part := range strings.Split(rangeRequest[len(preamble):], ",") {
+		option, err := fs.ParseRangeOption(preamble + strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// maxRanges is the most ranges served as a multipart/byteranges
+// response - any more and the whole object is served instead
+const maxRanges = 32
+
+// objectRanges serves several ranges of o as a multipart/byteranges
+// response.
+//
+// It returns false without writing a response if the whole object
+// should be served instead. This is done when there are more than
+// maxRanges ranges or, as net/http does, when they add up to more than
+// the object, so overlapping ranges can't be used to make the server
+// send many times the size of the object.
+func objectRanges(w http.ResponseWriter, r *http.Request, o fs.Object, rangeRequest string, mimeType string) (served bool) {
+	w.Header().Del("Content-Length")
+	options, err := parseRanges(rangeRequest)
+	if err != nil {
+		fs.Debugf(o, "Get request parse range request error: %v", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return true
+	}
+	size := o.Size()
+	if size < 0 {
+		http.Error(w, "Can't use multiple ranges on files of unknown length", http.StatusRequestedRangeNotSatisfiable)
+		return true
+	}
+	var total int64
+	for _, option := range options {
+		offset, limit := option.Decode(size)
+		if offset >= size {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			http.Error(w, http.StatusText(http.StatusRequestedRangeNotSatisfiable), http.StatusRequestedRangeNotSatisfiable)
+			return true
+		}
+		if limit < 0 || offset+limit > size {
+			limit = size - offset
+		}
+		total += limit
+	}
+	if len(options) > maxRanges || total > size {
+		fs.Debugf(o, "Serving whole object instead of %d ranges of %d bytes in total", len(options), total)
+		return false
+	}
+
+	tr := accounting.Stats(r.Context()).NewTransfer(o)
+	defer func() {
+		tr.Done(r.Context(), err)
+	}()
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+	w.WriteHeader(http.StatusPartialContent)
+	for _, option := range options {
+		offset, limit := option.Decode(size)
+		end := size // exclusive
+		if limit >= 0 && offset+limit < size {
+			end = offset + limit
+		}
+		header := textproto.MIMEHeader{
+			"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", offset, end-1, size)},
+		}
+		if mimeType != "" {
+			header.Set("Content-Type", mimeType)
+		}
+		var part io.Writer
+		part, err = mw.CreatePart(header)
+		if err != nil {
+			fs.Errorf(o, "Didn't finish writing GET request: %v", err)
+			return true
+		}
+		var file io.ReadCloser
+		file, err = o.Open(r.Context(), option)
+		if err != nil {
+			fs.Errorf(o, "Get request open error: %v", err)
+			return true
+		}
+		var n int64
+		n, err = io.Copy(part, tr.Account(r.Context(), file))
+		_ = file.Close()
+		if err != nil {
+			fs.Errorf(o, "Didn't finish writing GET request (wrote %d/%d bytes of range): %v", n, end-offset, err)
+			return true
+		}
+	}
+	err = mw.Close()
+	if err != nil {
+		fs.Errorf(o, "Didn't finish writing GET request: %v", err)
+	}
+	return true
+}
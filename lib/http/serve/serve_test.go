@@ -2,12 +2,16 @@ package serve
 
 import (
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/rclone/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestObjectBadMethod(t *testing.T) {
@@ -74,3 +78,70 @@ func TestObjectBadRange(t *testing.T) {
 	body, _ := io.ReadAll(resp.Body)
 	assert.Equal(t, "Bad Request\n", string(body))
 }
+
+func TestObjectMultipleRanges(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/aFile.txt", nil)
+	r.Header.Add("Range", "bytes=1-2, 5-, -2")
+	o := mockobject.New("aFile.txt").WithContent([]byte("0123456789"), mockobject.SeekModeNone)
+	Object(w, r, o)
+	resp := w.Result()
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "", resp.Header.Get("Content-Length"))
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	for _, want := range []struct {
+		contentRange string
+		body         string
+	}{
+		{"bytes 1-2/10", "12"},
+		{"bytes 5-9/10", "56789"},
+		{"bytes 8-9/10", "89"},
+	} {
+		part, err := mr.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, want.contentRange, part.Header.Get("Content-Range"))
+		assert.Equal(t, "text/plain; charset=utf-8", part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, want.body, string(body))
+	}
+	_, err = mr.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestObjectMultipleRangesUnsatisfiable(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/aFile", nil)
+	r.Header.Add("Range", "bytes=1-2,20-30")
+	o := mockobject.New("aFile").WithContent([]byte("0123456789"), mockobject.SeekModeNone)
+	Object(w, r, o)
+	resp := w.Result()
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+	assert.Equal(t, "bytes */10", resp.Header.Get("Content-Range"))
+}
+
+func TestObjectMultipleRangesWholeObject(t *testing.T) {
+	for _, rangeRequest := range []string{
+		// overlapping ranges adding up to more than the object
+		"bytes=0-9,0-9",
+		"bytes=0-,1-,2-",
+		// too many ranges
+		"bytes=0-0" + strings.Repeat(",1-1", maxRanges),
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "http://example.com/aFile", nil)
+		r.Header.Add("Range", rangeRequest)
+		o := mockobject.New("aFile").WithContent([]byte("0123456789"), mockobject.SeekModeNone)
+		Object(w, r, o)
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, rangeRequest)
+		assert.Equal(t, "10", resp.Header.Get("Content-Length"), rangeRequest)
+		assert.Equal(t, "", resp.Header.Get("Content-Range"), rangeRequest)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "0123456789", string(body), rangeRequest)
+	}
+}
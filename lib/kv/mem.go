@@ -0,0 +1,77 @@
+package kv
+
+import "sort"
+
+// MemBucket is an in memory Bucket for use when a database isn't
+// wanted or isn't supported.
+//
+// It isn't safe for concurrent use.
+type MemBucket map[string][]byte
+
+// Get the value of key or nil if not found
+func (b MemBucket) Get(key []byte) []byte {
+	return b[string(key)]
+}
+
+// Put a copy of value as key
+func (b MemBucket) Put(key, value []byte) error {
+	b[string(key)] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete key
+func (b MemBucket) Delete(key []byte) error {
+	delete(b, string(key))
+	return nil
+}
+
+// ForEach calls fn for each key and value in key order
+func (b MemBucket) ForEach(fn func(key, value []byte) error) error {
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cursor returns a Cursor over a snapshot of the keys in order
+func (b MemBucket) Cursor() Cursor {
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return &memCursor{b: b, keys: keys}
+}
+
+// memCursor iterates a snapshot of the keys of a MemBucket in order
+type memCursor struct {
+	b    MemBucket
+	keys []string
+	i    int
+}
+
+func (c *memCursor) item() ([]byte, []byte) {
+	if c.i >= len(c.keys) {
+		return nil, nil
+	}
+	k := c.keys[c.i]
+	return []byte(k), c.b[k]
+}
+
+func (c *memCursor) First() ([]byte, []byte) {
+	c.i = 0
+	return c.item()
+}
+
+func (c *memCursor) Next() ([]byte, []byte) {
+	c.i++
+	return c.item()
+}
+
+func (c *memCursor) Seek(seek []byte) ([]byte, []byte) {
+	c.i = sort.SearchStrings(c.keys, string(seek))
+	return c.item()
+}
@@ -0,0 +1,134 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// Types of Store
+const (
+	StoreMemory = "memory"
+	StoreDisk   = "disk"
+)
+
+// Store runs Ops against a database so the values kept in it survive
+// a restart, or against memory if that isn't wanted or possible. The
+// same Ops are used for either.
+type Store struct {
+	db  *DB // persistent store or nil if in memory
+	mu  sync.Mutex
+	mem MemBucket // used if db is nil
+}
+
+// NewStore makes a Store of storeType for facility on the remote f.
+//
+// flag is the option which set storeType and what describes what is
+// kept in the Store. They are used in the messages.
+func NewStore(ctx context.Context, facility string, f fs.Fs, storeType, flag, what string) (*Store, error) {
+	s := &Store{}
+	switch storeType {
+	case StoreMemory:
+	case StoreDisk:
+		if !Supported() {
+			fs.Logf(f, "Persistent %s not supported on this OS - using %s %s", what, flag, StoreMemory)
+			break
+		}
+		db, err := Start(ctx, facility, f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database for %s: %w", what, err)
+		}
+		s.db = db
+	default:
+		return nil, fmt.Errorf("unknown %s %q - expecting %q or %q", flag, storeType, StoreMemory, StoreDisk)
+	}
+	if s.db == nil {
+		s.mem = MemBucket{}
+	}
+	return s, nil
+}
+
+// Persistent returns true if the Store is backed by a database
+func (s *Store) Persistent() bool {
+	return s.db != nil
+}
+
+// Do runs op against the Store
+func (s *Store) Do(write bool, op Op) error {
+	if s.db != nil {
+		err := s.db.Do(write, op)
+		if errors.Is(err, ErrEmpty) {
+			// nothing has been written yet
+			return nil
+		}
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return op.Do(context.Background(), s.mem)
+}
+
+// Close the Store
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Stop(false)
+}
+
+// Get returns the value of key or nil if it isn't set
+func (s *Store) Get(key string) ([]byte, error) {
+	op := opGet{key: key}
+	err := s.Do(false, &op)
+	return op.value, err
+}
+
+// Item is a key and its value
+type Item struct {
+	Key   string
+	Value []byte
+}
+
+// Put sets the values of the keys in one go, removing those with nil
+// values
+func (s *Store) Put(items ...Item) error {
+	return s.Do(true, opPut(items))
+}
+
+// Delete removes key
+func (s *Store) Delete(key string) error {
+	return s.Put(Item{Key: key})
+}
+
+// opGet reads the value of key
+type opGet struct {
+	key   string
+	value []byte
+}
+
+func (op *opGet) Do(ctx context.Context, b Bucket) error {
+	if data := b.Get([]byte(op.key)); data != nil {
+		op.value = append([]byte(nil), data...)
+	}
+	return nil
+}
+
+// opPut sets the values of the keys, removing those with nil values
+type opPut []Item
+
+func (op opPut) Do(ctx context.Context, b Bucket) (err error) {
+	for _, item := range op {
+		if item.Value == nil {
+			err = b.Delete([]byte(item.Key))
+		} else {
+			err = b.Put([]byte(item.Key), item.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, storeType string) {
+	s, err := NewStore(context.Background(), "test-store", nil, storeType, "--store", "test values")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+	assert.Equal(t, storeType == StoreDisk, s.Persistent())
+
+	// Nothing written yet
+	value, err := s.Get("a")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, s.Put(Item{Key: "a", Value: []byte("A")}, Item{Key: "b", Value: []byte("B")}))
+	value, err = s.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "A", string(value))
+
+	// A nil value removes the key
+	require.NoError(t, s.Put(Item{Key: "a", Value: []byte("AA")}, Item{Key: "b"}))
+	value, err = s.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "AA", string(value))
+	value, err = s.Get("b")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, s.Delete("a"))
+	value, err = s.Get("a")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestStoreMemory(t *testing.T) {
+	testStore(t, StoreMemory)
+}
+
+func TestStoreDisk(t *testing.T) {
+	if !Supported() {
+		t.Skip("kv not supported on this OS")
+	}
+	testStore(t, StoreDisk)
+}
+
+func TestStoreUnknown(t *testing.T) {
+	_, err := NewStore(context.Background(), "test-store", nil, "potato", "--store", "test values")
+	assert.EqualError(t, err, `unknown --store "potato" - expecting "memory" or "disk"`)
+}
@@ -141,6 +141,28 @@ func (rs *Ranges) Insert(r Range) {
 	rs.coalesce(i)
 }
 
+// Remove the Range r from a sorted and coalesced slice of Ranges. The
+// result will be sorted and coalesced.
+func (rs *Ranges) Remove(r Range) {
+	if r.IsEmpty() {
+		return
+	}
+	var newRs Ranges
+	for _, curr := range *rs {
+		if curr.End() <= r.Pos || curr.Pos >= r.End() {
+			newRs = append(newRs, curr)
+			continue
+		}
+		if curr.Pos < r.Pos {
+			newRs = append(newRs, Range{Pos: curr.Pos, Size: r.Pos - curr.Pos})
+		}
+		if curr.End() > r.End() {
+			newRs = append(newRs, Range{Pos: r.End(), Size: curr.End() - r.End()})
+		}
+	}
+	*rs = newRs
+}
+
 // Find searches for r in rs and returns the next present or absent
 // Range. It returns:
 //
@@ -296,6 +296,61 @@ func TestRangeInsertRandom(t *testing.T) {
 	}
 }
 
+func TestRangeRemove(t *testing.T) {
+	for _, test := range []struct {
+		r    Range
+		rs   Ranges
+		want Ranges
+	}{
+		{
+			r:    Range{Pos: 1, Size: 0},
+			rs:   Ranges{{Pos: 1, Size: 1}},
+			want: Ranges{{Pos: 1, Size: 1}},
+		},
+		{
+			r:    Range{Pos: 1, Size: 1}, // .X.......
+			rs:   Ranges{},               // .........
+			want: Ranges(nil),            // .........
+		},
+		{
+			r:    Range{Pos: 1, Size: 1},    // .X.......
+			rs:   Ranges{{Pos: 1, Size: 1}}, // .R.......
+			want: Ranges(nil),               // .........
+		},
+		{
+			r:    Range{Pos: 3, Size: 1},    // ...X.....
+			rs:   Ranges{{Pos: 1, Size: 5}}, // .RRRRR...
+			want: Ranges{{1, 2}, {4, 2}},    // .RR.RR...
+		},
+		{
+			r:    Range{Pos: 0, Size: 3},    // XXX......
+			rs:   Ranges{{Pos: 1, Size: 5}}, // .RRRRR...
+			want: Ranges{{3, 3}},            // ...RRR...
+		},
+		{
+			r:    Range{Pos: 4, Size: 5},    // ....XXXXX
+			rs:   Ranges{{Pos: 1, Size: 5}}, // .RRRRR...
+			want: Ranges{{1, 3}},            // .RRR.....
+		},
+		{
+			r:    Range{Pos: 2, Size: 5},         // ..XXXXX..
+			rs:   Ranges{{0, 3}, {4, 1}, {6, 3}}, // RRR.R.RRR
+			want: Ranges{{0, 2}, {7, 2}},         // RR.....RR
+		},
+		{
+			r:    Range{Pos: 3, Size: 1}, // ...X.....
+			rs:   Ranges{{0, 2}, {5, 2}}, // RR...RR..
+			want: Ranges{{0, 2}, {5, 2}}, // RR...RR..
+		},
+	} {
+		got := append(Ranges(nil), test.rs...)
+		got.Remove(test.r)
+		what := fmt.Sprintf("test r=%v, rs=%v", test.r, test.rs)
+		assert.Equal(t, test.want, got, what)
+		checkRanges(t, got, what)
+	}
+}
+
 func TestRangeFind(t *testing.T) {
 	for _, test := range []struct {
 		rs          Ranges
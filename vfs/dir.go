@@ -18,6 +18,7 @@ import (
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/walk"
 	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsdir"
 )
 
 // Dir represents a directory entry
@@ -179,6 +180,7 @@ func (d *Dir) ForgetAll() (hasVirtual bool) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	fs.Debugf(d.path, "forgetting directory cache")
+	d.vfs.forgetPersisted(d.path, true)
 	for _, node := range d.items {
 		if dir, ok := node.(*Dir); ok {
 			if dir.ForgetAll() {
@@ -217,6 +219,7 @@ func (d *Dir) forgetDirPath(relativePath string) {
 
 // invalidateDir invalidates the directory cache for absPath relative to the root
 func (d *Dir) invalidateDir(absPath string) {
+	d.vfs.forgetPersisted(absPath, false)
 	node := d.vfs.root.cachedNode(absPath)
 	if dir, ok := node.(*Dir); ok {
 		dir.mu.Lock()
@@ -258,6 +261,7 @@ func (d *Dir) ForgetPath(relativePath string, entryType fs.EntryType) {
 		d.invalidateDir(vfscommon.FindParent(absPath))
 	}
 	if entryType == fs.EntryDirectory {
+		d.vfs.forgetPersisted(absPath, true)
 		d.forgetDirPath(relativePath)
 	}
 }
@@ -382,6 +386,7 @@ func (d *Dir) addObject(node Node) {
 	}
 	d.virtual[leaf] = vAdd
 	fs.Debugf(d.path, "Added virtual directory entry %v: %q", vAdd, leaf)
+	d.vfs.forgetPersisted(d.path, false)
 	d.mu.Unlock()
 }
 
@@ -426,6 +431,8 @@ func (d *Dir) delObject(leaf string) {
 	}
 	d.virtual[leaf] = vDel
 	fs.Debugf(d.path, "Added virtual directory entry %v: %q", vDel, leaf)
+	d.vfs.forgetPersisted(d.path, false)
+	d.vfs.forgetPersisted(path.Join(d.path, leaf), true)
 	d.mu.Unlock()
 }
 
@@ -450,6 +457,9 @@ func (d *Dir) _readDir() error {
 	} else {
 		return nil
 	}
+	if d.read.IsZero() && d._readDirFromStore(when) {
+		return nil
+	}
 	entries, err := list.DirSorted(context.TODO(), d.f, false, d.path)
 	if err == fs.ErrorDirNotFound {
 		// We treat directory not found as empty because we
@@ -464,13 +474,56 @@ func (d *Dir) _readDir() error {
 	}
 
 	d.read = when
+	d._persist(entries, when)
 	return nil
 }
 
+// read the directory from the listing persisted by a previous run if
+// there is one which is younger than --dir-cache-time, returning true
+// if it was used - must be called with the lock held
+func (d *Dir) _readDirFromStore(when time.Time) bool {
+	store := d.vfs.dirCache
+	if store == nil {
+		return false
+	}
+	listing, err := store.Get(d.path)
+	if err != nil {
+		fs.Errorf(d.path, "Failed to read persisted directory listing: %v", err)
+		return false
+	}
+	if listing == nil {
+		return false
+	}
+	age := when.Sub(listing.Read)
+	if age > d.vfs.Opt.DirCacheTime {
+		return false
+	}
+	err = d._readDirFromEntries(listing.DirEntries(d.f, d.path), nil, time.Time{})
+	if err != nil {
+		return false
+	}
+	fs.Debugf(d.path, "Read directory from persisted listing (%v old)", age)
+	d.read = listing.Read
+	return true
+}
+
+// persist the entries read from the remote at when if directory
+// listings are being persisted - must be called with the lock held
+func (d *Dir) _persist(entries fs.DirEntries, when time.Time) {
+	if store := d.vfs.dirCache; store != nil {
+		store.Put(d.path, vfsdir.NewListing(context.TODO(), d.f, entries, when))
+	}
+}
+
 // update d.items for each dir in the DirTree below this one and
 // set the last read time - must be called with the lock held
 func (d *Dir) _readDirFromDirTree(dirTree dirtree.DirTree, when time.Time) error {
-	return d._readDirFromEntries(dirTree[d.path], dirTree, when)
+	entries := dirTree[d.path]
+	err := d._readDirFromEntries(entries, dirTree, when)
+	if err == nil {
+		d._persist(entries, when)
+	}
+	return err
 }
 
 // Remove the virtual directory entry leaf
@@ -687,6 +740,7 @@ func (d *Dir) readDirTree() error {
 func (d *Dir) readDir() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	d.vfs.forgetPersisted(d.path, false)
 	d.read = time.Time{}
 	return d._readDir()
 }
@@ -905,6 +959,9 @@ func (d *Dir) Remove() error {
 	if d.vfs.Opt.ReadOnly {
 		return EROFS
 	}
+	if d.vfs.Opt.NoDelete {
+		return EPERM
+	}
 	// Check directory is empty first
 	empty, err := d.isEmpty()
 	if err != nil {
@@ -933,6 +990,9 @@ func (d *Dir) RemoveAll() error {
 	if d.vfs.Opt.ReadOnly {
 		return EROFS
 	}
+	if d.vfs.Opt.NoDelete {
+		return EPERM
+	}
 	// Remove contents of the directory
 	nodes, err := d.ReadDirAll()
 	if err != nil {
@@ -984,6 +1044,13 @@ func (d *Dir) Rename(oldName, newName string, destDir *Dir) error {
 		fs.Errorf(oldPath, "Dir.Rename error: %v", err)
 		return err
 	}
+	if d.vfs.Opt.NoDelete {
+		// Renaming over something would delete it
+		if _, err := destDir.stat(newName); err == nil {
+			fs.Errorf(newPath, "Dir.Rename can't replace existing file with no delete set")
+			return EPERM
+		}
+	}
 	switch x := oldNode.DirEntry().(type) {
 	case nil:
 		if oldFile, ok := oldNode.(*File); ok {
@@ -9,8 +9,11 @@ import (
 	"time"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsdir"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -140,6 +143,74 @@ func TestDirForgetPath(t *testing.T) {
 	assert.Equal(t, 0, len(dir.items))
 }
 
+func TestDirPersist(t *testing.T) {
+	ctx := context.Background()
+	oldCacheDir := config.GetCacheDir()
+	require.NoError(t, config.SetCacheDir(t.TempDir()))
+	defer func() {
+		_ = config.SetCacheDir(oldCacheDir)
+	}()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.WriteObject(ctx, "dir/file1", "file1 contents", t1)
+
+	opt := vfscommon.DefaultOpt
+	opt.DirCachePersist = true
+	opt.DirCacheTime = time.Hour
+
+	// Read the listings and save them
+	vfs := New(r.Fremote, &opt)
+	require.NotNil(t, vfs.dirCache)
+	_, err := vfs.Stat("dir/file1")
+	require.NoError(t, err)
+	cleanupVFS(t, vfs)
+
+	// Change the remote behind the VFS's back
+	obj, err := r.Fremote.NewObject(ctx, "dir/file1")
+	require.NoError(t, err)
+	require.NoError(t, obj.Remove(ctx))
+	r.WriteObject(ctx, "dir/file2", "file2 contents", t2)
+
+	// The saved listings are read again when the VFS starts, so
+	// the changes are seen
+	vfs = New(r.Fremote, &opt)
+	_, err = vfs.Stat("dir/file1")
+	assert.Equal(t, ENOENT, err)
+	cleanupVFS(t, vfs)
+
+	// Once read again the saved listings are used
+	vfs = New(r.Fremote, &opt)
+	<-vfs.dirsDone
+	node, err := vfs.Stat("dir/file2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(14), node.Size())
+	_, isPersisted := node.(*File).getObject().(*vfsdir.Object)
+	assert.True(t, isPersisted)
+	_, err = vfs.Stat("dir/file1")
+	assert.Equal(t, ENOENT, err)
+
+	// Forgetting the directory forgets the saved listing too
+	root, err := vfs.Root()
+	require.NoError(t, err)
+	root.ForgetPath("dir", fs.EntryDirectory)
+	listing, err := vfs.dirCache.Get("dir")
+	require.NoError(t, err)
+	assert.Nil(t, listing)
+	_, err = vfs.Stat("dir/file2")
+	require.NoError(t, err)
+	cleanupVFS(t, vfs)
+
+	// Saved listings older than --dir-cache-time aren't used
+	obj, err = r.Fremote.NewObject(ctx, "dir/file2")
+	require.NoError(t, err)
+	require.NoError(t, obj.Remove(ctx))
+	opt.DirCacheTime = time.Nanosecond
+	vfs = New(r.Fremote, &opt)
+	defer cleanupVFS(t, vfs)
+	_, err = vfs.Stat("dir/file2")
+	assert.Equal(t, ENOENT, err)
+}
+
 func TestDirWalk(t *testing.T) {
 	r, vfs, _, file1, cleanup := dirCreate(t)
 	defer cleanup()
@@ -52,6 +52,8 @@ type File struct {
 	sys              atomic.Value                    // user defined info to be attached here
 	nwriters         int32                           // len(writers) which is read/updated with atomic
 	appendMode       bool                            // file was opened with O_APPEND
+
+	quotaHeld int64 // bytes reserved for writes not uploaded yet - protected by VFS.usageMu
 }
 
 // newFile creates a new File
@@ -415,6 +417,7 @@ func (f *File) _applyPendingModTime() error {
 	switch err {
 	case nil:
 		fs.Debugf(f.o, "Applied pending mod time %v OK", f.pendingModTime)
+		f.d.vfs.forgetPersisted(f.dPath, false)
 	case fs.ErrorCantSetModTime, fs.ErrorCantSetModTimeWithoutDelete:
 		// do nothing, in order to not break "touch somefile" if it exists already
 	default:
@@ -458,6 +461,7 @@ func (f *File) setObject(o fs.Object) {
 	d := f.d
 	f.mu.Unlock()
 
+	d.vfs.quotaUploaded(f)
 	// Release File.mu before calling Dir method
 	d.addObject(f)
 }
@@ -580,6 +584,10 @@ func (f *File) Remove() (err error) {
 	if d.vfs.Opt.ReadOnly {
 		return EROFS
 	}
+	if d.vfs.Opt.NoDelete {
+		return EPERM
+	}
+	size := f.Size()
 
 	// Remove the object from the cache
 	wasWriting := false
@@ -607,6 +615,9 @@ func (f *File) Remove() (err error) {
 			fs.Debugf(f._path(), "File.Remove file error: %v", err)
 		}
 	}
+	if err == nil {
+		d.vfs.releaseQuota(f, size)
+	}
 	return err
 }
 
@@ -702,6 +713,13 @@ func (f *File) Open(flags int) (fd Handle, err error) {
 	f.mu.RLock()
 	d := f.d
 	f.mu.RUnlock()
+
+	// Overwriting a stored file would lose its contents just like
+	// deleting it
+	if write && d.vfs.Opt.NoDelete && f.exists() {
+		fs.Debugf(f.Path(), "Can't open stored file for write with no delete set")
+		return nil, EPERM
+	}
 	CacheMode := d.vfs.Opt.CacheMode
 	if CacheMode >= vfscommon.CacheModeMinimal && (d.vfs.cache.InUse(f.Path()) || d.vfs.cache.Exists(f.Path())) {
 		fd, err = f.openRW(flags)
@@ -55,6 +55,48 @@ Or individual files or directories:
 
     rclone rc vfs/forget file=path/to/file dir=path/to/dir
 
+### VFS Persistent Directory Cache
+
+Normally the directory cache is only kept in memory, so every time
+rclone starts it has to list the remote again as directories are
+used. This can take a long time on remotes with lots of files.
+
+    --vfs-dir-cache-persist   Keep directory listings on disk and reuse them after a restart
+
+With !--vfs-dir-cache-persist! the directory listings read from the
+remote are also written to a database in the !vfsDir! directory
+next to the VFS cache in the rclone cache directory (see
+!--cache-dir!). The names, sizes, modification times and hashes of
+the entries are kept along with the time the listing was read. Hashes
+and modification times are only kept if the remote can supply them
+without an extra transaction per file.
+
+The remote may have changed while rclone wasn't running, so when
+rclone is started again the directories with persisted listings
+younger than !--dir-cache-time! are read from the remote again in the
+background, !--checkers! at a time, and their listings replaced. A
+persisted listing is only used once this has been done, so listings
+from before the restart are never used as they were. A directory
+which is needed before then is read from the remote as usual. Files
+from a persisted listing are only looked up on the remote when
+something which wasn't persisted is needed, for example when they are
+opened. To fill the persisted listings in one go, use !rclone rc
+vfs/refresh recursive=true!.
+
+Changes made through the VFS, changes picked up by polling and
+flushing the directory cache all update the persisted listings too.
+You can forget the persisted listings with:
+
+    rclone rc vfs/forget-persisted
+
+Or for individual directories and everything below them:
+
+    rclone rc vfs/forget-persisted dir=path/to/dir
+
+Only one rclone can use the persisted listings of a remote at once.
+If another is already using them a second one will carry on without
+persisting its directory listings.
+
 ### VFS File Buffering
 
 The !--buffer-size! flag determines the amount of memory,
@@ -88,6 +130,7 @@ find that you need one or the other or both.
     --vfs-cache-mode CacheMode           Cache mode off|minimal|writes|full (default off)
     --vfs-cache-max-age duration         Max age of objects in the cache (default 1h0m0s)
     --vfs-cache-max-size SizeSuffix      Max total size of objects in the cache (default off)
+    --vfs-cache-policy CachePolicy       Which data to remove first when over --vfs-cache-max-size lru|lfu (default lru)
     --vfs-cache-poll-interval duration   Interval to poll the cache for stale objects (default 1m0s)
     --vfs-write-back duration            Time to writeback files after last use when using cache (default 5s)
 
@@ -106,11 +149,32 @@ seconds. If rclone is quit or dies with files that haven't been
 uploaded, these will be uploaded next time rclone is run with the same
 flags.
 
+The files waiting to be uploaded can be listed with the !vfs/queue!
+remote control command. Use !vfs/queue-set-expiry! to upload one of
+them now or to put it off, and !vfs/queue-pause! to stop uploads
+being started, for example while on a metered connection, and to
+resume them again.
+
 If using !--vfs-cache-max-size! note that the cache may exceed this size
 for two reasons.  Firstly because it is only checked every
 !--vfs-cache-poll-interval!.  Secondly because open files cannot be
 evicted from the cache.
 
+When the cache is over !--vfs-cache-max-size! rclone removes the data
+which hasn't been used for the longest time first. With
+!--vfs-cache-policy lfu! it removes the data which has been read the
+fewest times first instead, which keeps files which are used often in
+the cache even if they haven't been used recently.
+
+On Linux rclone keeps track of the use of each 16 MiB chunk of the
+files in the cache and removes chunks rather than whole files, so a
+large file which has only been partly read only loses the parts which
+aren't being used. This includes files which are open but not being
+read at that moment, so a large open file won't stop other files being
+kept in the cache. On other platforms, or if the file system of the
+cache directory can't free parts of files, whole files which are not
+open are removed.
+
 You **should not** run two copies of rclone using the same VFS cache
 with the same or overlapping remotes if using !--vfs-cache-mode > off!.
 This can potentially cause data corruption if you do. You can work
@@ -185,6 +249,63 @@ FAT/exFAT do not. Rclone will perform very badly if the cache
 directory is on a filesystem which doesn't support sparse files and it
 will log an ERROR message if one is detected.
 
+#### Pinning files in the cache
+
+    --vfs-pin-from string   Keep the files and directories listed in this file in the cache
+
+With !--vfs-cache-mode full! files are normally only in the cache once
+they have been read. Files and directories may be pinned so that
+rclone downloads them into the cache in the background and keeps them
+there, for example so they can be used while offline.
+
+Paths to pin can be listed in a file, one per line, and passed with
+!--vfs-pin-from!. Blank lines and lines starting with !#! or !;! are
+ignored. Paths are relative to the root of the VFS.
+
+Paths can also be pinned and unpinned while rclone is running with
+the !vfs/pin! and !vfs/unpin! remote control commands. These pins last
+until rclone is stopped.
+
+Pinned directories are checked for new and changed files every
+!--dir-cache-time! (but not more often than once a minute) and the
+changes are downloaded. Files which have gone from the remote are
+removed from the cache.
+
+Pinned files are never removed from the cache by !--vfs-cache-max-age!
+or !--vfs-cache-max-size!, so the cache may grow beyond
+!--vfs-cache-max-size! if more is pinned than will fit in it. Files
+which have been unpinned are removed in the normal way.
+
+The status of the pins can be seen with !rclone rc vfs/stats!.
+
+#### Verifying the cache
+
+    --vfs-cache-checksum   Checksum the cached data to detect corruption when the cache is verified
+
+When rclone starts it checks each file in the cache against its
+metadata before using it, in case rclone was stopped or the computer
+crashed while the file was being written. Clean files whose size or
+cached data doesn't match what was recorded are removed from the
+cache and will be downloaded again when needed.
+
+Files with changes which hadn't been uploaded are queued for upload
+again. If one of these is corrupt it is moved, along with its
+metadata, out of the cache into the !vfsQuarantine! directory beside
+the !vfs! directory in the cache directory, so the changes can be
+recovered by hand, and an ERROR is logged.
+
+With !--vfs-cache-checksum! rclone records a CRC-32C checksum of each
+16 MiB chunk of a file once the chunk is completely in the cache and
+checks them too. This finds corruption which doesn't change the size
+of the file, but it means the whole cache is read when rclone starts
+and the chunks are read again when a file is closed.
+
+The cache can also be verified while rclone is running with the
+!vfs/verify-cache! remote control command, which can check that the
+cached files haven't changed on the remote as well. Files which are
+open are skipped. The result of the last verification can be seen
+with !rclone rc vfs/stats!.
+
 #### Fingerprinting
 
 Various parts of the VFS use fingerprinting to see if a local file
@@ -11,6 +11,8 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/cache"
 	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/vfs/vfscache"
+	"github.com/rclone/rclone/vfs/vfscache/writeback"
 )
 
 const getVFSHelp = ` 
@@ -227,6 +229,395 @@ func rcForget(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/forget-persisted",
+		Fn:    rcForgetPersisted,
+		Title: "Forget directory listings persisted on disk.",
+		Help: `
+This forgets the directory listings kept on disk by
+--vfs-dir-cache-persist so they won't be used when the VFS is next
+started. The directory cache in memory isn't changed - use vfs/forget
+for that.
+
+If no paths are passed in then it will forget all the persisted
+listings.
+
+    rclone rc vfs/forget-persisted
+
+Otherwise pass directories in as dir=path. Any parameter key
+starting with dir will forget the listings of that directory and
+all the directories below it, e.g.
+
+    rclone rc vfs/forget-persisted dir=home/junk dir2=data/misc
+` + getVFSHelp,
+	})
+}
+
+func rcForgetPersisted(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.dirCache == nil {
+		return nil, errors.New("directory listings are not being persisted - use --vfs-dir-cache-persist")
+	}
+
+	forgotten := []string{}
+	if len(in) == 0 {
+		vfs.forgetPersisted("", true)
+		forgotten = append(forgotten, "")
+	} else {
+		for k, v := range in {
+			path, ok := v.(string)
+			if !ok {
+				return out, fmt.Errorf("value must be string %q=%v", k, v)
+			}
+			if !strings.HasPrefix(k, "dir") {
+				return out, fmt.Errorf("unknown key %q", k)
+			}
+			path = strings.Trim(path, "/")
+			vfs.forgetPersisted(path, true)
+			forgotten = append(forgotten, path)
+		}
+	}
+	if err = vfs.dirCache.Flush(); err != nil {
+		return nil, err
+	}
+	out = rc.Params{
+		"forgotten": forgotten,
+	}
+	return out, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/pin",
+		Fn:    rcPin,
+		Title: "Pin files and directories in the VFS cache.",
+		Help: `
+This downloads the files and directories passed in into the VFS cache
+in the background and keeps them there until they are unpinned, for
+example so they can be used while offline. It needs
+--vfs-cache-mode full.
+
+Pass files in as file=path and directories as dir=path. Any parameter
+key starting with file or dir will pin that path, e.g.
+
+    rclone rc vfs/pin file=hello.txt dir=home/projects dir2=data/misc
+
+Pins made this way last until rclone is stopped - use --vfs-pin-from
+to pin paths every time rclone is started.
+
+The progress of the downloads can be seen in the "pinned" section of
+the "diskCache" returned by vfs/stats.
+` + getVFSHelp,
+	})
+}
+
+func rcPin(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := getPinPaths(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("pinning needs --vfs-cache-mode full")
+	}
+	for _, path := range paths {
+		if err = vfs.cache.Pin(path); err != nil {
+			return nil, err
+		}
+	}
+	out = rc.Params{
+		"pinned": paths,
+	}
+	return out, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/unpin",
+		Fn:    rcUnpin,
+		Title: "Unpin files and directories in the VFS cache.",
+		Help: `
+This unpins files and directories pinned with vfs/pin or
+--vfs-pin-from. They stay in the VFS cache until they are removed in
+the normal way by --vfs-cache-max-age or --vfs-cache-max-size.
+
+Pass the paths in the same way as they were pinned, e.g.
+
+    rclone rc vfs/unpin file=hello.txt dir=home/projects
+` + getVFSHelp,
+	})
+}
+
+func rcUnpin(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := getPinPaths(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("nothing is pinned as the VFS cache is off")
+	}
+	for _, path := range paths {
+		if err = vfs.cache.Unpin(path); err != nil {
+			return nil, err
+		}
+	}
+	out = rc.Params{
+		"unpinned": paths,
+	}
+	return out, nil
+}
+
+// getPinPaths reads the file= and dir= parameters for vfs/pin and
+// vfs/unpin
+func getPinPaths(in rc.Params) (paths []string, err error) {
+	for k, v := range in {
+		path, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("value must be string %q=%v", k, v)
+		}
+		if !strings.HasPrefix(k, "file") && !strings.HasPrefix(k, "dir") {
+			return nil, fmt.Errorf("unknown key %q", k)
+		}
+		paths = append(paths, strings.Trim(path, "/"))
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("need at least one file or dir parameter")
+	}
+	return paths, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/verify-cache",
+		Fn:    rcVerifyCache,
+		Title: "Verify the files in the VFS cache.",
+		Help: `
+This checks each file in the VFS cache which isn't open against its
+metadata in the same way as is done when rclone starts. Clean files
+which are corrupt are removed from the cache and files with changes
+which haven't been uploaded which are corrupt are moved to the
+quarantine directory.
+
+The following parameters can be given
+
+- checksum - if true read the files and check the checksums recorded
+  with --vfs-cache-checksum
+- remote - if true remove files which have been changed or deleted on
+  the remote
+
+    rclone rc vfs/verify-cache checksum=true remote=true
+
+This returns
+
+    {
+        "checked": 100,
+        "fixed": 0,
+        "problems": [
+            "videos/film.mkv: checksum mismatch in chunk 3"
+        ],
+        "quarantined": 0,
+        "removed": 1,
+        "requeued": 0,
+        "skipped": 2,
+        "started": "2022-06-01T10:00:00Z"
+    }
+
+The same is shown in the "lastVerify" section of the "diskCache"
+returned by vfs/stats.
+` + getVFSHelp,
+	})
+}
+
+func rcVerifyCache(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	var opt vfscache.VerifyOpt
+	opt.Checksums, err = in.GetBool("checksum")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	opt.Remote, err = in.GetBool("remote")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("there is no cache to verify as --vfs-cache-mode is off")
+	}
+	result := vfs.cache.Verify(ctx, opt)
+	return result.Params(), nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/queue",
+		Fn:    rcQueue,
+		Title: "Queue info for a VFS.",
+		Help: `
+This returns info about the files waiting to be uploaded to the remote
+and the files being uploaded, with --vfs-cache-mode writes or full.
+The files being uploaded are listed first, then the files in the order
+they will be uploaded.
+
+    rclone rc vfs/queue
+
+This returns
+
+    {
+        "paused": false,
+        "queue": [
+            {
+                "delay": 5,
+                "error": "",
+                "expiry": 0.9,
+                "id": 3,
+                "name": "dir/file.txt",
+                "size": 12345,
+                "tries": 0,
+                "uploading": true
+            }
+        ]
+    }
+
+Each item has
+
+- name - the name of the file
+- id - the id of the item for vfs/queue-set-expiry
+- size - the size of the file when it was queued
+- expiry - seconds from now when the file will be uploaded, negative if overdue
+- tries - the number of times the upload has been tried
+- delay - the seconds between upload attempts, which doubles each time one fails
+- uploading - true if the file is being uploaded
+- error - the error from the last attempt if it failed
+
+"paused" is true if the uploads have been paused with vfs/queue-pause.
+
+The queue is empty if --vfs-write-back is 0 as files are then uploaded
+as soon as they are closed.
+` + getVFSHelp,
+	})
+}
+
+func rcQueue(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("there is no upload queue as --vfs-cache-mode is off")
+	}
+	out = rc.Params{
+		"paused": vfs.cache.UploadsPaused(),
+		"queue":  vfs.cache.Queue(),
+	}
+	return out, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/queue-set-expiry",
+		Fn:    rcQueueSetExpiry,
+		Title: "Set the time an item in the upload queue will be uploaded.",
+		Help: `
+This sets when a file waiting to be uploaded will be uploaded, for
+example to upload it now, to retry a failed upload straight away or to
+put off uploading it.
+
+It takes the following parameters
+
+- id - the id of the item as returned by vfs/queue
+- expiry - the seconds from now when the file will be uploaded as a
+  floating point number. Use 0 to upload it now.
+
+    rclone rc vfs/queue-set-expiry id=3 expiry=0
+
+This has no effect on a file which is being uploaded. It returns an
+error if the id isn't in the queue.
+` + getVFSHelp,
+	})
+}
+
+func rcQueueSetExpiry(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	id, err := in.GetInt64("id")
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := in.GetFloat64("expiry")
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("there is no upload queue as --vfs-cache-mode is off")
+	}
+	when := time.Now().Add(time.Duration(expiry * float64(time.Second)))
+	return nil, vfs.cache.QueueSetExpiry(writeback.Handle(id), when)
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/queue-pause",
+		Fn:    rcQueuePause,
+		Title: "Pause or resume uploads from the VFS cache.",
+		Help: `
+This stops any new uploads from the VFS cache being started, for
+example while using a metered connection. Uploads which are in
+progress carry on. Files which are closed while paused are queued as
+normal and uploaded when the uploads are resumed.
+
+It takes the following parameter
+
+- paused - false to resume the uploads (default true)
+
+    rclone rc vfs/queue-pause
+    rclone rc vfs/queue-pause paused=false
+
+This returns the new state as "paused". This doesn't affect files
+uploaded when they are closed with --vfs-write-back 0.
+` + getVFSHelp,
+	})
+}
+
+func rcQueuePause(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	paused, err := in.GetBool("paused")
+	if rc.IsErrParamNotFound(err) {
+		paused = true
+	} else if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("there is no upload queue as --vfs-cache-mode is off")
+	}
+	if paused {
+		vfs.cache.PauseUploads()
+	} else {
+		vfs.cache.ResumeUploads()
+	}
+	out = rc.Params{
+		"paused": paused,
+	}
+	return out, nil
+}
+
 func getDuration(k string, v interface{}) (time.Duration, error) {
 	s, ok := v.(string)
 	if !ok {
@@ -404,12 +795,45 @@ This returns stats for the selected VFS.
             "erroredFiles": 0,
             "files": 0,
             "hashType": 1,
+            // Result of the last verification of the cache - see vfs/verify-cache
+            "lastVerify": {
+                "checked": 100,
+                "fixed": 0,
+                "problems": [],
+                "quarantined": 0,
+                "removed": 0,
+                "requeued": 1,
+                "skipped": 0,
+                "started": "2022-06-01T10:00:00Z"
+            },
             "outOfSpace": false,
             "path": "/home/user/.cache/rclone/vfs/local/mnt/a",
             "pathMeta": "/home/user/.cache/rclone/vfsMeta/local/mnt/a",
+            "pathQuarantine": "/home/user/.cache/rclone/vfsQuarantine/local/mnt/a",
+            // Status of the paths pinned with vfs/pin or --vfs-pin-from
+            "pinned": [
+                {
+                    "bytes": 123456,
+                    "cachedFiles": 10,
+                    "erroredFiles": 0,
+                    "files": 10,
+                    "lastError": "",
+                    "path": "home/projects",
+                    "synced": "2022-06-01T10:00:00Z",
+                    "syncing": false
+                }
+            ],
             "uploadsInProgress": 0,
+            // Set if the uploads have been paused with vfs/queue-pause
+            "uploadsPaused": false,
             "uploadsQueued": 0
         },
+        // Status of the persisted directory listings - only present if --vfs-dir-cache-persist
+        "dirCache": {
+            "dirs": 1234,
+            "path": "/home/user/.cache/rclone/vfsDir/local/mnt/a/dirs.db",
+            "pending": 0
+        },
         "fs": "/mnt/a",
         "inUse": 1,
         // Status of the in memory metadata cache
@@ -425,6 +849,35 @@ This returns stats for the selected VFS.
         }
     }
 
+If the parameter items=true is given then the "diskCache" section also
+has an "items" list showing each file in the cache and which parts of
+it are cached.
+
+    rclone rc vfs/stats items=true
+
+    "items": [
+        {
+            "atime": "2022-06-01T10:00:00Z",
+            "bytesUsed": 33554432,
+            "dirty": false,
+            "name": "videos/film.mkv",
+            "opens": 0,
+            "pinned": false,
+            // the parts of the file in the cache
+            "ranges": [
+                {
+                    "pos": 0,
+                    "size": 16777216
+                },
+                {
+                    "pos": 1073741824,
+                    "size": 16777216
+                }
+            ],
+            "size": 4294967296
+        }
+    ]
+
 ` + getVFSHelp,
 		Fn: rcStats,
 	})
@@ -435,5 +888,13 @@ func rcStats(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if err != nil {
 		return nil, err
 	}
-	return vfs.Stats(), nil
+	items, err := in.GetBool("items")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	out = vfs.Stats()
+	if items && vfs.cache != nil {
+		out["diskCache"].(rc.Params)["items"] = vfs.cache.ItemStats()
+	}
+	return out, nil
 }
@@ -2,11 +2,14 @@ package vfs
 
 import (
 	"context"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscache/writeback"
 	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -131,3 +134,92 @@ func TestRcStats(t *testing.T) {
 	assert.Equal(t, 1, out["metadataCache"].(rc.Params)["dirs"])
 	assert.Equal(t, vfs.Opt, out["opt"].(vfscommon.Options))
 }
+
+func TestRcPin(t *testing.T) {
+	r, vfs, cleanup, call := rcNewRun(t, "vfs/pin")
+	defer cleanup()
+	_ = vfs
+
+	// Need some paths
+	_, err := call.Fn(context.Background(), rc.Params{"fs": fs.ConfigString(r.Fremote)})
+	assert.ErrorContains(t, err, "need at least one")
+
+	// Need the cache
+	_, err = call.Fn(context.Background(), rc.Params{"fs": fs.ConfigString(r.Fremote), "dir": "potato"})
+	assert.ErrorContains(t, err, "--vfs-cache-mode full")
+
+	// Need valid keys
+	_, err = call.Fn(context.Background(), rc.Params{"fs": fs.ConfigString(r.Fremote), "potato": "potato"})
+	assert.ErrorContains(t, err, "unknown key")
+}
+
+func TestRcVerifyCache(t *testing.T) {
+	r, vfs, cleanup, call := rcNewRun(t, "vfs/verify-cache")
+	defer cleanup()
+
+	// Need the cache
+	_, err := call.Fn(context.Background(), rc.Params{"fs": fs.ConfigString(r.Fremote)})
+	assert.ErrorContains(t, err, "--vfs-cache-mode")
+
+	vfs.SetCacheMode(vfscommon.CacheModeFull)
+
+	out, err := call.Fn(context.Background(), rc.Params{"fs": fs.ConfigString(r.Fremote), "checksum": true, "remote": true})
+	require.NoError(t, err)
+	assert.Equal(t, 0, out["checked"])
+	assert.Equal(t, []string{}, out["problems"])
+
+	_, err = call.Fn(context.Background(), rc.Params{"fs": fs.ConfigString(r.Fremote), "checksum": "potato"})
+	assert.Error(t, err)
+}
+
+func TestRcQueue(t *testing.T) {
+	r, vfs, cleanup, call := rcNewRun(t, "vfs/queue")
+	defer cleanup()
+	pause := rc.Calls.Get("vfs/queue-pause")
+	setExpiry := rc.Calls.Get("vfs/queue-set-expiry")
+	ctx := context.Background()
+
+	// Need the cache
+	_, err := call.Fn(ctx, rc.Params{"fs": fs.ConfigString(r.Fremote)})
+	assert.ErrorContains(t, err, "--vfs-cache-mode")
+
+	vfs.Opt.WriteBack = time.Hour
+	vfs.SetCacheMode(vfscommon.CacheModeWrites)
+
+	_, err = pause.Fn(ctx, rc.Params{"fs": fs.ConfigString(r.Fremote)})
+	require.NoError(t, err)
+	assert.Equal(t, true, vfs.Stats()["diskCache"].(rc.Params)["uploadsPaused"])
+
+	// Write a file which is queued for upload
+	fd, err := vfs.OpenFile("file.txt", os.O_CREATE|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	out, err := call.Fn(ctx, rc.Params{"fs": fs.ConfigString(r.Fremote)})
+	require.NoError(t, err)
+	assert.Equal(t, true, out["paused"])
+	queue := out["queue"].([]writeback.QueueInfo)
+	require.Len(t, queue, 1)
+	assert.Equal(t, "file.txt", queue[0].Name)
+	assert.Equal(t, int64(5), queue[0].Size)
+	assert.Greater(t, queue[0].Expiry, 0.0)
+
+	// Need a valid id
+	_, err = setExpiry.Fn(ctx, rc.Params{"fs": fs.ConfigString(r.Fremote), "id": 999, "expiry": 0})
+	assert.ErrorIs(t, err, writeback.ErrorIDNotFound)
+
+	// Upload it now
+	_, err = setExpiry.Fn(ctx, rc.Params{"fs": fs.ConfigString(r.Fremote), "id": int64(queue[0].ID), "expiry": -1})
+	require.NoError(t, err)
+	out, err = pause.Fn(ctx, rc.Params{"fs": fs.ConfigString(r.Fremote), "paused": false})
+	require.NoError(t, err)
+	assert.Equal(t, false, out["paused"])
+	for i := 0; i < 100 && len(vfs.cache.Queue()) > 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Len(t, vfs.cache.Queue(), 0)
+	o, err := r.Fremote.NewObject(ctx, "file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), o.Size())
+}
@@ -334,6 +334,11 @@ func (fh *RWFileHandle) _writeAt(b []byte, off int64, release bool) (n int, err
 		fh.offset = size
 		off = fh.offset
 	}
+	// Only the bytes written past the end of the file count
+	// towards the quota
+	if err = fh.d.vfs.reserveQuota(fh.file, off+int64(len(b))-fh._size()); err != nil {
+		return n, err
+	}
 	fh.writeCalled = true
 	if release {
 		// Do the writing with fh.mu unlocked
@@ -39,6 +39,7 @@ import (
 	"github.com/rclone/rclone/fs/walk"
 	"github.com/rclone/rclone/vfs/vfscache"
 	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsdir"
 )
 
 // Node represents either a directory (*Dir) or a file (*File)
@@ -166,8 +167,13 @@ type VFS struct {
 	usageMu     sync.Mutex
 	usageTime   time.Time
 	usage       *fs.Usage
+	quotaUsed   int64 // bytes uploaded less bytes removed since usage was read when Opt.Quota is set
+	quotaHeld   int64 // bytes written but not uploaded yet when Opt.Quota is set - kept when usage is read
 	pollChan    chan time.Duration
-	inUse       int32 // count of number of opens accessed with atomic
+	inUse       int32         // count of number of opens accessed with atomic
+	dirCache    *vfsdir.Store // persisted directory listings - may be nil
+	cancelDirs  context.CancelFunc
+	dirsDone    chan struct{} // closed when the persisted listings have been revalidated
 }
 
 // Keep track of active VFS keyed on fs.ConfigString(f)
@@ -212,6 +218,17 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 	// Create root directory
 	vfs.root = newDir(vfs, f, nil, fsDir)
 
+	// Open the directory listings saved by the last run
+	if vfs.Opt.DirCachePersist {
+		dirCache, err := vfsdir.Open(f)
+		if err != nil {
+			fs.Errorf(f, "Failed to open directory cache - not persisting directory listings: %v", err)
+		} else {
+			vfs.dirCache = dirCache
+			vfs.revalidateDirCache()
+		}
+	}
+
 	// Start polling function
 	features := vfs.f.Features()
 	if do := features.ChangeNotify; do != nil {
@@ -260,6 +277,9 @@ func (vfs *VFS) Stats() (out rc.Params) {
 	if vfs.cache != nil {
 		out["diskCache"] = vfs.cache.Stats()
 	}
+	if vfs.dirCache != nil {
+		out["dirCache"] = vfs.dirCache.Stats()
+	}
 	return out
 }
 
@@ -288,6 +308,7 @@ func (vfs *VFS) SetCacheMode(cacheMode vfscommon.CacheMode) {
 	vfs.cache = nil
 	if cacheMode > vfscommon.CacheModeOff {
 		ctx, cancel := context.WithCancel(context.Background())
+		vfs.Opt.CacheMode = cacheMode
 		cache, err := vfscache.New(ctx, vfs.f, &vfs.Opt, vfs.AddVirtual) // FIXME pass on context or get from Opt?
 		if err != nil {
 			fs.Errorf(nil, "Failed to create vfs cache - disabling: %v", err)
@@ -295,7 +316,6 @@ func (vfs *VFS) SetCacheMode(cacheMode vfscommon.CacheMode) {
 			cancel()
 			return
 		}
-		vfs.Opt.CacheMode = cacheMode
 		vfs.cancelCache = cancel
 		vfs.cache = cache
 	}
@@ -330,6 +350,14 @@ func (vfs *VFS) Shutdown() {
 	activeMu.Unlock()
 
 	vfs.shutdownCache()
+
+	if vfs.dirCache != nil {
+		vfs.cancelDirs()
+		<-vfs.dirsDone
+		if err := vfs.dirCache.Close(); err != nil {
+			fs.Errorf(vfs.f, "Failed to close directory cache: %v", err)
+		}
+	}
 }
 
 // CleanUp deletes the contents of the on disk cache
@@ -345,6 +373,36 @@ func (vfs *VFS) FlushDirCache() {
 	vfs.root.ForgetAll()
 }
 
+// revalidateDirCache reads the directories with persisted listings
+// from the remote again in the background, as the remote may have
+// changed while rclone wasn't running. Until a directory has been
+// read its persisted listing isn't used.
+func (vfs *VFS) revalidateDirCache() {
+	var ctx context.Context
+	ctx, vfs.cancelDirs = context.WithCancel(context.Background())
+	vfs.dirsDone = make(chan struct{})
+	go func() {
+		defer close(vfs.dirsDone)
+		err := vfs.dirCache.Revalidate(ctx, vfs.f, vfs.Opt.DirCacheTime)
+		if err != nil && err != context.Canceled {
+			fs.Errorf(vfs.f, "Failed to revalidate persisted directory listings: %v", err)
+		}
+	}()
+}
+
+// forgetPersisted forgets the persisted listing of dirPath, and of
+// all the directories below it if tree is set, once it has changed.
+func (vfs *VFS) forgetPersisted(dirPath string, tree bool) {
+	if vfs.dirCache == nil {
+		return
+	}
+	if tree {
+		vfs.dirCache.ForgetTree(dirPath)
+	} else {
+		vfs.dirCache.Forget(dirPath)
+	}
+}
+
 // WaitForWriters sleeps until all writers have finished or
 // time.Duration has elapsed
 func (vfs *VFS) WaitForWriters(timeout time.Duration) {
@@ -576,9 +634,17 @@ func (vfs *VFS) Statfs() (total, used, free int64) {
 	// defer log.Trace("/", "")("total=%d, used=%d, free=%d", &total, &used, &free)
 	vfs.usageMu.Lock()
 	defer vfs.usageMu.Unlock()
+	return vfs._statfs()
+}
+
+// _statfs implements Statfs - call with usageMu held
+func (vfs *VFS) _statfs() (total, used, free int64) {
 	total, used, free = -1, -1, -1
 	doAbout := vfs.f.Features().About
-	if (doAbout != nil || vfs.Opt.UsedIsSize) && (vfs.usageTime.IsZero() || time.Since(vfs.usageTime) >= vfs.Opt.DirCacheTime) {
+	// The quota is for what is stored below the root so needs the
+	// size of that rather than of the whole remote
+	usedIsSize := vfs.Opt.UsedIsSize || vfs.Opt.Quota > 0
+	if (doAbout != nil || usedIsSize) && (vfs.usageTime.IsZero() || time.Since(vfs.usageTime) >= vfs.Opt.DirCacheTime) {
 		var err error
 		ctx := context.TODO()
 		if doAbout == nil {
@@ -586,7 +652,7 @@ func (vfs *VFS) Statfs() (total, used, free int64) {
 		} else {
 			vfs.usage, err = doAbout(ctx)
 		}
-		if vfs.Opt.UsedIsSize {
+		if usedIsSize {
 			var usedBySizeAlgorithm int64
 			// Algorithm from `rclone size`
 			err = walk.ListR(ctx, vfs.f, "", true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
@@ -598,6 +664,9 @@ func (vfs *VFS) Statfs() (total, used, free int64) {
 			vfs.usage.Used = &usedBySizeAlgorithm
 		}
 		vfs.usageTime = time.Now()
+		// The files written but not uploaded yet aren't in the
+		// usage just read so quotaHeld is kept
+		vfs.quotaUsed = 0
 		if err != nil {
 			fs.Errorf(vfs.f, "Statfs failed: %v", err)
 			return
@@ -620,10 +689,86 @@ func (vfs *VFS) Statfs() (total, used, free int64) {
 		total = int64(vfs.Opt.DiskSpaceTotalSize)
 	}
 
+	if vfs.Opt.Quota > 0 {
+		total = int64(vfs.Opt.Quota)
+		if used < 0 {
+			used = 0
+		}
+		used += vfs.quotaUsed + vfs.quotaHeld
+		free = total - used
+		if free < 0 {
+			free = 0
+		}
+	}
+
 	total, used, free = fillInMissingSizes(total, used, free, unknownFreeBytes)
 	return
 }
 
+// reserveQuota checks there is room under the quota for n more bytes
+// to be written to f and counts them as used until f is uploaded,
+// returning ENOSPC if there isn't.
+//
+// The check and the reservation are done under the same lock so
+// concurrent writers can't both take the last of the quota.
+func (vfs *VFS) reserveQuota(f *File, n int64) error {
+	if vfs.Opt.Quota <= 0 || n <= 0 {
+		return nil
+	}
+	vfs.usageMu.Lock()
+	defer vfs.usageMu.Unlock()
+	_, used, _ := vfs._statfs()
+	if used+n > int64(vfs.Opt.Quota) {
+		fs.Errorf(vfs.f, "Quota of %v exceeded", vfs.Opt.Quota)
+		return ENOSPC
+	}
+	vfs.quotaHeld += n
+	f.quotaHeld += n
+	return nil
+}
+
+// quotaUploaded is called when f has been uploaded to count the bytes
+// reserved for it as used until the usage is next read
+func (vfs *VFS) quotaUploaded(f *File) {
+	if vfs.Opt.Quota <= 0 {
+		return
+	}
+	vfs.usageMu.Lock()
+	vfs.quotaHeld -= f.quotaHeld
+	vfs.quotaUsed += f.quotaHeld
+	f.quotaHeld = 0
+	vfs.usageMu.Unlock()
+}
+
+// quotaAbandoned is called when the upload of f has failed for good to
+// give back the bytes reserved for it
+func (vfs *VFS) quotaAbandoned(f *File) {
+	if vfs.Opt.Quota <= 0 {
+		return
+	}
+	vfs.usageMu.Lock()
+	vfs.quotaHeld -= f.quotaHeld
+	f.quotaHeld = 0
+	vfs.usageMu.Unlock()
+}
+
+// releaseQuota gives back the size bytes of f to the quota when it is
+// removed, whether they were uploaded or not
+func (vfs *VFS) releaseQuota(f *File, size int64) {
+	if vfs.Opt.Quota <= 0 {
+		return
+	}
+	vfs.usageMu.Lock()
+	held := f.quotaHeld
+	if held > size {
+		held = size
+	}
+	vfs.quotaHeld -= f.quotaHeld
+	vfs.quotaUsed -= size - held
+	f.quotaHeld = 0
+	vfs.usageMu.Unlock()
+}
+
 // Remove removes the named file or (empty) directory.
 func (vfs *VFS) Remove(name string) error {
 	node, err := vfs.Stat(name)
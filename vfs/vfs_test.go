@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -372,6 +375,132 @@ func TestVFSStatfs(t *testing.T) {
 	assert.Equal(t, oldTime, vfs.usageTime)
 }
 
+func TestVFSNoDelete(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.NoDelete = true
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	file1 := r.WriteObject(context.Background(), "dir/file1", "file1 contents", t1)
+	file2 := r.WriteObject(context.Background(), "file2", "file2 contents", t2)
+	r.CheckRemoteItems(t, file1, file2)
+
+	assert.Equal(t, EPERM, vfs.Remove("dir/file1"))
+	assert.Equal(t, EPERM, vfs.Remove("dir"))
+	dir, err := vfs.Stat("dir")
+	require.NoError(t, err)
+	assert.Equal(t, EPERM, dir.RemoveAll())
+	_, err = vfs.OpenFile("file2", os.O_WRONLY|os.O_TRUNC, 0777)
+	assert.Equal(t, EPERM, err)
+	assert.Equal(t, EPERM, vfs.Rename("file2", "dir/file1"))
+	r.CheckRemoteItems(t, file1, file2)
+
+	// New files can be written
+	fd, err := vfs.OpenFile("file3", os.O_WRONLY|os.O_CREATE, 0777)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("file3 contents"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+}
+
+func TestVFSQuota(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.Quota = 100
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	file1 := r.WriteObject(context.Background(), "file1", strings.Repeat("1", 60), t1)
+	r.CheckRemoteItems(t, file1)
+
+	total, used, free := vfs.Statfs()
+	assert.Equal(t, int64(100), total)
+	assert.Equal(t, int64(60), used)
+	assert.Equal(t, int64(40), free)
+
+	fd, err := vfs.OpenFile("file2", os.O_WRONLY|os.O_CREATE, 0777)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte(strings.Repeat("2", 30)))
+	require.NoError(t, err)
+	_, err = fd.Write([]byte(strings.Repeat("2", 20)))
+	assert.Equal(t, ENOSPC, err)
+	require.NoError(t, fd.Close())
+
+	_, used, free = vfs.Statfs()
+	assert.Equal(t, int64(90), used)
+	assert.Equal(t, int64(10), free)
+
+	// Removing a file gives its space back
+	require.NoError(t, vfs.Remove("file1"))
+	_, used, free = vfs.Statfs()
+	assert.Equal(t, int64(30), used)
+	assert.Equal(t, int64(70), free)
+}
+
+func TestVFSQuotaHeldOverRefresh(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.Quota = 100
+	_, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+	refresh := func() {
+		vfs.usageMu.Lock()
+		vfs.usageTime = time.Time{}
+		vfs.usageMu.Unlock()
+	}
+
+	// Reading the usage again doesn't forget the bytes held for
+	// files which haven't been uploaded yet
+	f := &File{}
+	require.NoError(t, vfs.reserveQuota(f, 60))
+	refresh()
+	_, used, _ := vfs.Statfs()
+	assert.Equal(t, int64(60), used)
+	assert.Equal(t, ENOSPC, vfs.reserveQuota(f, 50))
+
+	// A failed upload gives them back
+	g := &File{}
+	require.NoError(t, vfs.reserveQuota(g, 30))
+	vfs.quotaAbandoned(g)
+	_, used, _ = vfs.Statfs()
+	assert.Equal(t, int64(60), used)
+
+	// Once uploaded they are counted until the usage is read,
+	// which here finds nothing as f wasn't really written
+	vfs.quotaUploaded(f)
+	_, used, _ = vfs.Statfs()
+	assert.Equal(t, int64(60), used)
+	refresh()
+	_, used, _ = vfs.Statfs()
+	assert.Equal(t, int64(0), used)
+}
+
+func TestVFSQuotaConcurrent(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.Quota = 100
+	_, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	// Only as many reservations as fit succeed however many are
+	// made at once
+	var (
+		wg sync.WaitGroup
+		ok int32
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if vfs.reserveQuota(&File{}, 10) == nil {
+				atomic.AddInt32(&ok, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(10), ok)
+	_, used, free := vfs.Statfs()
+	assert.Equal(t, int64(100), used)
+	assert.Equal(t, int64(0), free)
+}
+
 func TestFillInMissingSizes(t *testing.T) {
 	const unknownFree = 10
 	for _, test := range []struct {
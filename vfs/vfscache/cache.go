@@ -46,11 +46,14 @@ type Cache struct {
 	opt        *vfscommon.Options   // vfs Options
 	root       string               // root of the cache directory
 	metaRoot   string               // root of the cache metadata directory
+	quarantine string               // root of the directory for corrupt dirty items
 	hashType   hash.Type            // hash to use locally and remotely
 	hashOption *fs.HashesOption     // corresponding OpenOption
 	writeback  *writeback.WriteBack // holds Items for writeback
 	avFn       AddVirtualFn         // if set, can be called to add dir entries
 
+	noPunchHole int32 // set to 1 if the cache file system can't free parts of files - use atomic
+
 	mu            sync.Mutex       // protects the following variables
 	cond          sync.Cond        // cond lock for synchronous cache cleaning
 	item          map[string]*Item // files/directories in the cache
@@ -60,7 +63,11 @@ type Cache struct {
 	cleanerKicked bool             // some thread kicked the cleaner upon out of space
 	kickerMu      sync.Mutex       // mutex for cleanerKicked
 	kick          chan struct{}    // channel for kicking clear to start
+	pins          map[string]*pin  // paths pinned in the cache
+	lastVerify    VerifyResult     // result of the last verification of the cache
 
+	pinMu   sync.Mutex    // held while syncing the pins
+	pinKick chan struct{} // channel for kicking the pinner
 }
 
 // AddVirtualFn if registered by the WithAddVirtual method, can be
@@ -120,8 +127,11 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 		opt:        opt,
 		root:       dataOSPath,
 		metaRoot:   metaOSPath,
+		quarantine: file.UNCPath(filepath.Join(parentOSPath, "vfsQuarantine", relativeDirOSPath)),
 		item:       make(map[string]*Item),
 		errItems:   make(map[string]error),
+		pins:       make(map[string]*pin),
+		pinKick:    make(chan struct{}, 1),
 		hashType:   hashType,
 		hashOption: hashOption,
 		writeback:  writeback.New(ctx, opt),
@@ -143,6 +153,20 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 
 	go c.cleaner(ctx)
 
+	// Pin the paths asked for on the command line
+	if opt.PinFrom != "" {
+		names, err := readPinFile(opt.PinFrom)
+		if err != nil {
+			fs.Errorf(nil, "vfs cache: failed to read paths to pin: %v", err)
+		}
+		for _, name := range names {
+			if err := c.Pin(name); err != nil {
+				fs.Errorf(name, "vfs cache: failed to pin: %v", err)
+			}
+		}
+	}
+	go c.pinner(ctx)
+
 	return c, nil
 }
 
@@ -152,11 +176,13 @@ func (c *Cache) Stats() (out rc.Params) {
 	// read only - no locking needed to read these
 	out["path"] = c.root
 	out["pathMeta"] = c.metaRoot
+	out["pathQuarantine"] = c.quarantine
 	out["hashType"] = c.hashType
 
 	uploadsInProgress, uploadsQueued := c.writeback.Stats()
 	out["uploadsInProgress"] = uploadsInProgress
 	out["uploadsQueued"] = uploadsQueued
+	out["uploadsPaused"] = c.UploadsPaused()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -165,10 +191,40 @@ func (c *Cache) Stats() (out rc.Params) {
 	out["erroredFiles"] = len(c.errItems)
 	out["bytesUsed"] = c.used
 	out["outOfSpace"] = c.outOfSpace
+	out["pinned"] = c._pinStats()
+	out["lastVerify"] = c.lastVerify.Params()
 
 	return out
 }
 
+// Queue returns info about the files waiting to be uploaded and being
+// uploaded
+func (c *Cache) Queue() []writeback.QueueInfo {
+	return c.writeback.Queue()
+}
+
+// QueueSetExpiry sets the time the file with id in the upload queue
+// will be uploaded
+func (c *Cache) QueueSetExpiry(id writeback.Handle, expiry time.Time) error {
+	return c.writeback.SetExpiry(id, expiry)
+}
+
+// PauseUploads stops new uploads being started until ResumeUploads is
+// called
+func (c *Cache) PauseUploads() {
+	c.writeback.Pause()
+}
+
+// ResumeUploads starts uploading again after PauseUploads
+func (c *Cache) ResumeUploads() {
+	c.writeback.Resume()
+}
+
+// UploadsPaused returns true if the uploads have been paused
+func (c *Cache) UploadsPaused() bool {
+	return c.writeback.Paused()
+}
+
 // createDir creates a directory path, along with any necessary parents
 func createDir(dir string) error {
 	return file.MkdirAll(dir, 0700)
@@ -518,7 +574,12 @@ func (c *Cache) walk(dir string, fn func(osPath string, fi os.FileInfo, name str
 // It iterates the files first then metadata trees. It doesn't expect
 // to find any new items iterating the metadata but it will clear up
 // orphan files.
+//
+// Each item is verified before it is used in case rclone stopped
+// uncleanly while it was being written to.
 func (c *Cache) reload(ctx context.Context) error {
+	result := VerifyResult{Started: time.Now()}
+	opt := VerifyOpt{Checksums: c.opt.CacheChecksum}
 	for _, dir := range []string{c.root, c.metaRoot} {
 		err := c.walk(dir, func(osPath string, fi os.FileInfo, name string) error {
 			if fi.IsDir() {
@@ -526,6 +587,16 @@ func (c *Cache) reload(ctx context.Context) error {
 			}
 			item, found := c.get(name)
 			if !found {
+				switch c.verifyItem(ctx, item, opt, &result) {
+				case verifyRemoved, verifyQuarantined:
+					c.mu.Lock()
+					delete(c.item, name)
+					c.mu.Unlock()
+					return nil
+				}
+				if item.IsDirty() {
+					result.Requeued++
+				}
 				err := item.reload(ctx)
 				if err != nil {
 					fs.Errorf(name, "vfs cache: failed to reload item: %v", err)
@@ -537,6 +608,7 @@ func (c *Cache) reload(ctx context.Context) error {
 			return fmt.Errorf("failed to walk cache %q: %w", dir, err)
 		}
 	}
+	c.logVerify(&result)
 	return nil
 }
 
@@ -617,9 +689,9 @@ func (c *Cache) purgeClean(quota int64) {
 		return
 	}
 
-	// Make a slice of clean cache files
+	// Make a slice of clean cache files which aren't pinned
 	for _, item := range c.item {
-		if !item.IsDirty() {
+		if !item.IsDirty() && !c._isPinned(item.name) {
 			items = append(items, item)
 		}
 	}
@@ -659,6 +731,9 @@ func (c *Cache) purgeOld(maxAge time.Duration) {
 	defer c.mu.Unlock()
 	// cutoff := time.Now().Add(-maxAge)
 	for _, item := range c.item {
+		if c._isPinned(item.name) {
+			continue
+		}
 		c.removeNotInUse(item, maxAge, false)
 	}
 	if c.used < int64(c.opt.CacheMaxSize) {
@@ -693,8 +768,13 @@ func (c *Cache) updateUsed() (used int64) {
 	return newUsed
 }
 
-// Remove clean cache files that are not open until the total space
-// is reduced below quota starting from the oldest first
+// Remove clean cache data until the total space is reduced below
+// quota, choosing what to remove with the cache policy.
+//
+// If the OS can free parts of files then the chunks of each file are
+// removed separately, so a large file which has only been partly used
+// loses just its unused parts and open files can have data removed
+// too. Otherwise whole files which are not open are removed.
 func (c *Cache) purgeOverQuota(quota int64) {
 	c.updateUsed()
 
@@ -706,21 +786,47 @@ func (c *Cache) purgeOverQuota(quota int64) {
 		return
 	}
 
-	var items Items
-
-	// Make a slice of unused files
+	// Find the data which could be removed
+	var candidates []evictCandidate
 	for _, item := range c.item {
-		if !item.inUse() {
-			items = append(items, item)
+		if !c._isPinned(item.name) {
+			candidates = append(candidates, item.evictCandidates()...)
 		}
 	}
+	sortEvictCandidates(candidates, c.opt.CachePolicy)
+
+	// Choose data to remove until the quota is OK
+	var (
+		items  []*Item
+		chunks = make(map[*Item][]int64)
+		toFree = c.used - quota
+	)
+	for i := 0; i < len(candidates) && toFree > 0; i++ {
+		candidate := &candidates[i]
+		if _, found := chunks[candidate.item]; !found {
+			items = append(items, candidate.item)
+		}
+		chunks[candidate.item] = append(chunks[candidate.item], candidate.chunks...)
+		toFree -= candidate.size
+	}
 
-	sort.Sort(items)
-
-	// Remove items until the quota is OK
+	// Remove it
 	for _, item := range items {
-		c.removeNotInUse(item, 0, c.used <= quota)
+		removed, spaceFreed := item.evict(chunks[item])
+		c.used -= spaceFreed
+		if removed {
+			fs.Infof(nil, "vfs cache purgeOverQuota: item %s was removed, freed %d bytes", item.GetName(), spaceFreed)
+			delete(c.item, item.name)
+		}
 	}
+
+	// Remove any empty items not in use
+	for _, item := range c.item {
+		if !c._isPinned(item.name) {
+			c.removeNotInUse(item, 0, true)
+		}
+	}
+
 	if c.used < quota {
 		c.outOfSpace = false
 		c.cond.Broadcast()
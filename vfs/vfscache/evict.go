@@ -0,0 +1,246 @@
+package vfscache
+
+import (
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/lib/ranges"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// evictChunkSize is the size of the chunks whose use is recorded and
+// which are removed from cache files when the cache is over quota.
+const evictChunkSize = 16 * 1024 * 1024
+
+// Chunk records the use of one evictChunkSize chunk of a cache file
+type Chunk struct {
+	ATime time.Time // last time the chunk was read or downloaded
+	Hits  int64     // number of times the chunk was read
+	Sum   string    `json:",omitempty"` // CRC-32C of the chunk if complete and --vfs-cache-checksum
+}
+
+// _accessed records the use of the chunks of the file in offset,
+// size. If read is set it counts as a read of the chunks.
+//
+// call with lock held
+func (item *Item) _accessed(offset, size int64, read bool) {
+	if size <= 0 {
+		return
+	}
+	if item.info.Chunks == nil {
+		item.info.Chunks = make(map[int64]Chunk)
+	}
+	now := time.Now()
+	for n := offset / evictChunkSize; n*evictChunkSize < offset+size; n++ {
+		chunk := item.info.Chunks[n]
+		chunk.ATime = now
+		if read {
+			chunk.Hits++
+		} else {
+			// the data has changed so the checksum is out of date
+			chunk.Sum = ""
+		}
+		item.info.Chunks[n] = chunk
+	}
+}
+
+// _chunkSizes returns the number of bytes in the cache for each chunk
+// of the file which has some data present
+//
+// call with lock held
+func (item *Item) _chunkSizes() map[int64]int64 {
+	sizes := make(map[int64]int64)
+	for _, r := range item.info.Rs {
+		for n := r.Pos / evictChunkSize; n*evictChunkSize < r.End(); n++ {
+			chunkRange := ranges.Range{Pos: n * evictChunkSize, Size: evictChunkSize}
+			sizes[n] += chunkRange.Intersection(r).Size
+		}
+	}
+	return sizes
+}
+
+// evictCandidate is some data in the cache which could be removed to
+// make space
+type evictCandidate struct {
+	item   *Item
+	chunks []int64   // chunk numbers to remove - nil for the whole item
+	size   int64     // bytes this would free
+	atime  time.Time // last time the data was used
+	hits   int64     // number of times the data was read
+}
+
+// evictCandidates returns the data in the item which could be removed
+// to make space.
+//
+// If parts of cache files can be freed then each chunk is a candidate,
+// otherwise the item as a whole is, but only if it isn't open. Once
+// freeing part of a file has failed as unsupported only whole items
+// are candidates.
+func (item *Item) evictCandidates() (candidates []evictCandidate) {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	size := item.info.Rs.Size()
+	if item.info.Dirty || size == 0 {
+		return nil
+	}
+	// An item with reads or resets in progress mustn't have data
+	// removed from under it
+	canPunch := file.PunchHoleImplemented && atomic.LoadInt32(&item.c.noPunchHole) == 0 && item.pendingAccesses == 0 && !item.beingReset
+	if !canPunch {
+		if item.opens != 0 {
+			return nil
+		}
+		candidate := evictCandidate{
+			item:  item,
+			size:  size,
+			atime: item.info.ATime,
+		}
+		for _, chunk := range item.info.Chunks {
+			candidate.hits += chunk.Hits
+		}
+		return []evictCandidate{candidate}
+	}
+	for n, chunkSize := range item._chunkSizes() {
+		candidate := evictCandidate{
+			item:   item,
+			chunks: []int64{n},
+			size:   chunkSize,
+			atime:  item.info.ATime, // for chunks from before use was recorded
+		}
+		if chunk, found := item.info.Chunks[n]; found {
+			candidate.atime = chunk.ATime
+			candidate.hits = chunk.Hits
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// sortEvictCandidates sorts the candidates so the ones to remove first
+// according to policy come first
+func sortEvictCandidates(candidates []evictCandidate, policy vfscommon.CachePolicy) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := &candidates[i], &candidates[j]
+		if policy == vfscommon.CachePolicyLFU && a.hits != b.hits {
+			return a.hits < b.hits
+		}
+		return a.atime.Before(b.atime)
+	})
+}
+
+// evict removes the chunks passed in from the cache file, or the whole
+// item if chunks is nil or they are all the data it has and it isn't
+// open.
+//
+// It returns whether the item was removed and the space freed.
+func (item *Item) evict(chunks []int64) (removed bool, spaceFreed int64) {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	if item.info.Dirty {
+		return false, 0
+	}
+	all := chunks == nil || len(chunks) >= len(item._chunkSizes())
+	if all && item.opens == 0 {
+		spaceFreed = item.info.Rs.Size()
+		if item._remove("Removing cache file to make space") {
+			fs.Errorf(item.name, "item removed when it was writing/uploaded")
+		}
+		return true, spaceFreed
+	}
+	if chunks == nil || item.pendingAccesses > 0 || item.beingReset {
+		return false, 0
+	}
+
+	// Use open handle if available
+	fd := item.fd
+	if fd == nil {
+		var err error
+		osPath := item.c.toOSPath(item.name) // No locking in Cache
+		fd, err = file.OpenFile(osPath, os.O_WRONLY, 0600)
+		if err != nil {
+			fs.Errorf(item.name, "vfs cache: failed to open cache file to remove chunks: %v", err)
+			return false, 0
+		}
+		defer func() {
+			if err := fd.Close(); err != nil {
+				fs.Errorf(item.name, "vfs cache: failed to close cache file after removing chunks: %v", err)
+			}
+		}()
+	}
+
+	var err error
+outer:
+	for _, n := range chunks {
+		chunkRange := ranges.Range{Pos: n * evictChunkSize, Size: evictChunkSize}
+		for _, r := range item.info.Rs.Intersection(chunkRange) {
+			err = file.PunchHole(fd, r.Pos, r.Size)
+			if err != nil {
+				fs.Errorf(item.name, "vfs cache: failed to remove chunk from cache file: %v", err)
+				break outer
+			}
+			item.info.Rs.Remove(r)
+			spaceFreed += r.Size
+		}
+		delete(item.info.Chunks, n)
+	}
+	if err == file.ErrPunchHoleUnsupported && atomic.CompareAndSwapInt32(&item.c.noPunchHole, 0, 1) {
+		fs.Logf(nil, "vfs cache: the cache file system can't free parts of files so only whole files not in use will be removed to make space")
+	}
+	if err == file.ErrPunchHoleUnsupported && item.opens == 0 {
+		// The file system can't free parts of files so remove it all
+		spaceFreed += item.info.Rs.Size()
+		if item._remove("Removing cache file to make space") {
+			fs.Errorf(item.name, "item removed when it was writing/uploaded")
+		}
+		return true, spaceFreed
+	}
+	if spaceFreed > 0 {
+		fs.Infof(item.name, "vfs cache: removed chunks to make space, freed %d bytes", spaceFreed)
+		if err = item._save(); err != nil {
+			fs.Errorf(item.name, "vfs cache: failed to write metadata file: %v", err)
+		}
+	}
+	return false, spaceFreed
+}
+
+// ItemStats returns info about each item in the cache including which
+// parts of the file are cached
+func (c *Cache) ItemStats() (out []rc.Params) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.item))
+	for name := range c.item {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out = make([]rc.Params, 0, len(names))
+	for _, name := range names {
+		item := c.item[name]
+		pinned := c._isPinned(name)
+		item.mu.Lock()
+		cached := make([]rc.Params, 0, len(item.info.Rs))
+		for _, r := range item.info.Rs {
+			cached = append(cached, rc.Params{
+				"pos":  r.Pos,
+				"size": r.Size,
+			})
+		}
+		out = append(out, rc.Params{
+			"name":      name,
+			"size":      item.info.Size,
+			"bytesUsed": item.info.Rs.Size(),
+			"ranges":    cached,
+			"opens":     item.opens,
+			"dirty":     item.info.Dirty,
+			"pinned":    pinned,
+			"atime":     item.info.ATime.Format(time.RFC3339),
+		})
+		item.mu.Unlock()
+	}
+	return out
+}
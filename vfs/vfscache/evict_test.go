@@ -0,0 +1,177 @@
+package vfscache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/lib/ranges"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemAccessed(t *testing.T) {
+	item := &Item{}
+	item._accessed(0, 0, true)
+	assert.Nil(t, item.info.Chunks)
+
+	item._accessed(evictChunkSize-1, 2, true)
+	item._accessed(evictChunkSize, 1, false)
+	require.Len(t, item.info.Chunks, 2)
+	assert.Equal(t, int64(1), item.info.Chunks[0].Hits)
+	assert.Equal(t, int64(1), item.info.Chunks[1].Hits)
+	assert.False(t, item.info.Chunks[1].ATime.Before(item.info.Chunks[0].ATime))
+
+	item.info.Rs = ranges.Ranges{
+		ranges.Range{Pos: 10, Size: 10},
+		ranges.Range{Pos: evictChunkSize - 5, Size: 10},
+		ranges.Range{Pos: 3 * evictChunkSize, Size: 1},
+	}
+	assert.Equal(t, map[int64]int64{0: 15, 1: 5, 3: 1}, item._chunkSizes())
+}
+
+func TestSortEvictCandidates(t *testing.T) {
+	t0 := time.Now()
+	candidates := func() []evictCandidate {
+		return []evictCandidate{
+			{chunks: []int64{0}, atime: t0, hits: 1},
+			{chunks: []int64{1}, atime: t0.Add(-time.Hour), hits: 3},
+			{chunks: []int64{2}, atime: t0.Add(-time.Minute), hits: 1},
+		}
+	}
+	order := func(cs []evictCandidate) (out []int64) {
+		for _, c := range cs {
+			out = append(out, c.chunks[0])
+		}
+		return out
+	}
+
+	cs := candidates()
+	sortEvictCandidates(cs, vfscommon.CachePolicyLRU)
+	assert.Equal(t, []int64{1, 2, 0}, order(cs))
+
+	cs = candidates()
+	sortEvictCandidates(cs, vfscommon.CachePolicyLFU)
+	assert.Equal(t, []int64{2, 0, 1}, order(cs))
+}
+
+func TestItemEvictCandidatesNoPunchHole(t *testing.T) {
+	c := &Cache{noPunchHole: 1}
+	item := &Item{c: c, name: "potato"}
+	item.info.Rs = ranges.Ranges{ranges.Range{Pos: 0, Size: 2 * evictChunkSize}}
+
+	// Only the whole item is a candidate
+	candidates := item.evictCandidates()
+	require.Len(t, candidates, 1)
+	assert.Nil(t, candidates[0].chunks)
+	assert.Equal(t, int64(2*evictChunkSize), candidates[0].size)
+
+	// And not while it is open
+	item.opens = 1
+	assert.Nil(t, item.evictCandidates())
+}
+
+// skip the test if parts of files in dir can't be freed
+func skipIfNoPunchHole(t *testing.T, dir string) {
+	if !file.PunchHoleImplemented {
+		t.Skip("punch hole not implemented")
+	}
+	f, err := os.Create(filepath.Join(dir, "punch-hole-test"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, f.Close())
+		require.NoError(t, os.Remove(f.Name()))
+	}()
+	_, err = f.WriteString("hello")
+	require.NoError(t, err)
+	if file.PunchHole(f, 0, 1) == file.ErrPunchHoleUnsupported {
+		t.Skip("punch hole not supported by the cache file system")
+	}
+}
+
+func TestCachePurgeOverQuotaChunks(t *testing.T) {
+	_, c, cleanup := newTestCache(t)
+	defer cleanup()
+	skipIfNoPunchHole(t, c.root)
+
+	// A sparse file of 3 chunks
+	big := c.Item("big")
+	require.NoError(t, big.Open(nil))
+	require.NoError(t, big.Truncate(3*evictChunkSize))
+	require.NoError(t, big.Close(nil))
+
+	small := c.Item("small")
+	itemWrite(t, small, "hello")
+	require.NoError(t, small.Close(nil))
+
+	// Make the middle chunk of big the least recently used then small
+	now := time.Now()
+	big.info.Chunks[0] = Chunk{ATime: now, Hits: 5}
+	big.info.Chunks[1] = Chunk{ATime: now.Add(-2 * time.Hour), Hits: 3}
+	big.info.Chunks[2] = Chunk{ATime: now.Add(-time.Hour), Hits: 1}
+	small.info.Chunks[0] = Chunk{ATime: now.Add(-90 * time.Minute)}
+
+	// Only the middle chunk is removed with LRU
+	used := c.updateUsed()
+	assert.Equal(t, int64(3*evictChunkSize+5), used)
+	c.purgeOverQuota(used - 1)
+	assert.Equal(t, used-evictChunkSize, c.used)
+	assert.Equal(t, ranges.Ranges{
+		ranges.Range{Pos: 0, Size: evictChunkSize},
+		ranges.Range{Pos: 2 * evictChunkSize, Size: evictChunkSize},
+	}, big.info.Rs)
+	assert.Equal(t, []string{
+		`name="big" opens=0 size=50331648 space=33554432`,
+		`name="small" opens=0 size=5 space=5`,
+	}, itemSpaceAsString(c))
+
+	// The file is still the same size
+	fi, err := os.Stat(c.toOSPath("big"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3*evictChunkSize), fi.Size())
+
+	// The removed chunks were written to the metadata
+	reloaded := newItem(c, "big")
+	assert.Equal(t, big.info.Rs, reloaded.info.Rs)
+	assert.Len(t, reloaded.info.Chunks, 2)
+
+	// With LFU small goes first as it has never been read
+	c.opt.CachePolicy = vfscommon.CachePolicyLFU
+	used = c.used
+	c.purgeOverQuota(used - 1)
+	assert.Equal(t, used-5, c.used)
+	assert.Equal(t, []string{
+		`name="big" opens=0 size=50331648 space=33554432`,
+	}, itemSpaceAsString(c))
+
+	// Removing all the chunks of a file removes the item
+	c.purgeOverQuota(1)
+	assert.Equal(t, int64(0), c.used)
+	assert.Equal(t, []string(nil), itemSpaceAsString(c))
+}
+
+func TestCacheItemStats(t *testing.T) {
+	_, c, cleanup := newTestCache(t)
+	defer cleanup()
+
+	potato := c.Item("sub/potato")
+	itemWrite(t, potato, "hello")
+	_, err := potato.WriteAt([]byte("world"), 10)
+	require.NoError(t, err)
+
+	out := c.ItemStats()
+	require.Len(t, out, 1)
+	assert.Equal(t, "sub/potato", out[0]["name"])
+	assert.Equal(t, int64(15), out[0]["size"])
+	assert.Equal(t, int64(15), out[0]["bytesUsed"])
+	assert.Equal(t, []rc.Params{{"pos": int64(0), "size": int64(15)}}, out[0]["ranges"])
+	assert.Equal(t, 1, out[0]["opens"])
+	assert.Equal(t, true, out[0]["dirty"])
+	assert.Equal(t, false, out[0]["pinned"])
+
+	require.NoError(t, potato.Close(nil))
+}
@@ -72,12 +72,13 @@ type Item struct {
 
 // Info is persisted to backing store
 type Info struct {
-	ModTime     time.Time     // last time file was modified
-	ATime       time.Time     // last time file was accessed
-	Size        int64         // size of the file
-	Rs          ranges.Ranges // which parts of the file are present
-	Fingerprint string        // fingerprint of remote object
-	Dirty       bool          // set if the backing file has been modified
+	ModTime     time.Time       // last time file was modified
+	ATime       time.Time       // last time file was accessed
+	Size        int64           // size of the file
+	Rs          ranges.Ranges   // which parts of the file are present
+	Fingerprint string          // fingerprint of remote object
+	Dirty       bool            // set if the backing file has been modified
+	Chunks      map[int64]Chunk // use of each chunk of the file by chunk number
 }
 
 // Items are a slice of *Item ordered by ATime
@@ -257,6 +258,7 @@ func (item *Item) _truncate(size int64) (err error) {
 			fs.Errorf(item.name, "vfs cache: detected external removal of cache file")
 			item.info.Rs = nil      // show we have no blocks cached
 			item.info.Dirty = false // file can't be dirty if it doesn't exist
+			item.info.Chunks = nil
 			item._removeMeta("cache file externally deleted")
 			fd, err = file.OpenFile(osPath, os.O_CREATE|os.O_WRONLY, 0600)
 		}
@@ -346,6 +348,7 @@ func (item *Item) Truncate(size int64) (err error) {
 	} else if size < oldSize {
 		// Truncate shrinks the file so clip the downloaded ranges
 		item.info.Rs = item.info.Rs.Intersection(ranges.Range{Pos: 0, Size: size})
+		item._clearSums(size, oldSize-size)
 	} else {
 		changed = item.o == nil
 	}
@@ -677,6 +680,12 @@ func (item *Item) Close(storeFn StoreFn) (err error) {
 		item.mu.Lock()
 	}
 
+	// checksum the chunks which have been completed so corruption
+	// can be detected when the cache is verified
+	if item.c.opt.CacheChecksum && !item.info.Dirty && item.fd != nil {
+		checkErr(item._sumChunks(item.fd))
+	}
+
 	// close the file handle
 	if item.fd == nil {
 		checkErr(errors.New("vfs cache item: internal error: didn't Open file"))
@@ -708,9 +717,9 @@ func (item *Item) Close(storeFn StoreFn) (err error) {
 		} else {
 			// asynchronous writeback
 			item.c.writeback.SetID(&item.writeBackID)
-			id := item.writeBackID
+			id, size := item.writeBackID, item.info.Size
 			item.mu.Unlock()
-			item.c.writeback.Add(id, item.name, item.modified, func(ctx context.Context) error {
+			item.c.writeback.Add(id, item.name, size, item.modified, func(ctx context.Context) error {
 				return item.store(ctx, storeFn)
 			})
 			item.mu.Lock()
@@ -738,6 +747,11 @@ func (item *Item) reload(ctx context.Context) error {
 	}
 	// see if the object still exists
 	obj, _ := item.c.fremote.NewObject(ctx, item.name)
+	if obj == nil {
+		fs.Logf(item.name, "vfs cache: found changes which weren't uploaded before rclone stopped - queuing new file for upload")
+	} else {
+		fs.Logf(item.name, "vfs cache: found changes which weren't uploaded before rclone stopped - queuing for upload")
+	}
 	// open the file with the object (or nil)
 	err := item.Open(obj)
 	if err != nil {
@@ -760,6 +774,31 @@ func (item *Item) reload(ctx context.Context) error {
 	return nil
 }
 
+// fetch makes sure all of o is downloaded into the cache file.
+//
+// It is used to download pinned files in the background. If the item
+// is open already the download is shared with the other users.
+func (item *Item) fetch(o fs.Object) (err error) {
+	err = item.Open(o)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := item.Close(nil)
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	item.preAccess()
+	defer item.postAccess()
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	if item.info.Dirty || item._present() {
+		return nil
+	}
+	return item._ensure(0, item.info.Size)
+}
+
 // check the fingerprint of an object and update the item or delete
 // the cached file accordingly
 //
@@ -1160,6 +1199,7 @@ func (item *Item) _ensure(offset, size int64) (err error) {
 func (item *Item) _written(offset, size int64) {
 	// defer log.Trace(item.name, "offset=%d, size=%d", offset, size)("")
 	item.info.Rs.Insert(ranges.Range{Pos: offset, Size: size})
+	item._accessed(offset, size, false)
 }
 
 // update the fingerprint of the object if any
@@ -1261,6 +1301,7 @@ func (item *Item) readAt(b []byte, off int64) (n int, err error) {
 	}
 
 	item.info.ATime = time.Now()
+	item._accessed(off, int64(len(b)), true)
 	// Do the reading with Item.mu unlocked and cache protected by preAccess
 	n, err = item.fd.ReadAt(b, off)
 	return n, err
@@ -0,0 +1,275 @@
+package vfscache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/walk"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// minPinInterval is the shortest time between syncs of the pinned
+// paths with the remote
+const minPinInterval = time.Minute
+
+// pin is a path pinned in the cache along with the result of the last
+// time it was synced with the remote
+type pin struct {
+	files   int       // number of files found under the pin
+	bytes   int64     // total size of the files found
+	cached  int       // number of files which are completely in the cache
+	errored int       // number of files which couldn't be downloaded
+	lastErr error     // last error listing or downloading
+	synced  time.Time // when the last sync finished - zero if never
+	syncing bool      // set while a sync is running
+}
+
+// under returns true if name is the pinned path or is inside it
+func under(name, pinned string) bool {
+	return pinned == "" || name == pinned || strings.HasPrefix(name, pinned+"/")
+}
+
+// _isPinned returns true if name or any of its parents are pinned
+//
+// call with c.mu held
+func (c *Cache) _isPinned(name string) bool {
+	if len(c.pins) == 0 {
+		return false
+	}
+	for {
+		if _, found := c.pins[name]; found {
+			return true
+		}
+		if name == "" {
+			return false
+		}
+		name = vfscommon.FindParent(name)
+	}
+}
+
+// Pin name so it is downloaded into the cache in the background and
+// kept there until it is unpinned.
+//
+// name may be a file or a directory.
+func (c *Cache) Pin(name string) error {
+	if c.opt.CacheMode < vfscommon.CacheModeFull {
+		return errors.New("pinning needs --vfs-cache-mode full")
+	}
+	name = clean(name)
+	c.mu.Lock()
+	if _, found := c.pins[name]; !found {
+		c.pins[name] = &pin{}
+		fs.Infof(name, "vfs cache: pinned")
+	}
+	c.mu.Unlock()
+	c.kickPinner()
+	return nil
+}
+
+// Unpin name so it may be removed from the cache again.
+//
+// The files which were downloaded stay in the cache until they are
+// removed in the normal way.
+func (c *Cache) Unpin(name string) error {
+	name = clean(name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.pins[name]; !found {
+		return fmt.Errorf("%q is not pinned", name)
+	}
+	delete(c.pins, name)
+	fs.Infof(name, "vfs cache: unpinned")
+	return nil
+}
+
+// kickPinner makes the pinner sync the pins now
+func (c *Cache) kickPinner() {
+	select {
+	case c.pinKick <- struct{}{}:
+	default:
+	}
+}
+
+// pinner syncs the pinned paths with the remote when kicked and at
+// regular intervals so new and changed files are downloaded.
+//
+// doesn't return until context is cancelled
+func (c *Cache) pinner(ctx context.Context) {
+	if c.opt.CachePollInterval <= 0 {
+		fs.Debugf(nil, "vfs cache: pinning thread disabled because poll interval <= 0")
+		return
+	}
+	interval := c.opt.DirCacheTime
+	if interval < minPinInterval {
+		interval = minPinInterval
+	}
+	timer := time.NewTicker(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.pinKick:
+		case <-timer.C:
+		case <-ctx.Done():
+			fs.Debugf(nil, "vfs cache: pinner exiting")
+			return
+		}
+		c.syncPins(ctx)
+	}
+}
+
+// syncPins syncs all the pinned paths with the remote
+func (c *Cache) syncPins(ctx context.Context) {
+	c.pinMu.Lock()
+	defer c.pinMu.Unlock()
+	c.mu.Lock()
+	names := make([]string, 0, len(c.pins))
+	for name := range c.pins {
+		names = append(names, name)
+	}
+	c.mu.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		c.syncPin(ctx, name)
+	}
+}
+
+// syncPin downloads the files in the pinned path name which aren't in
+// the cache and removes files which have gone from the remote.
+func (c *Cache) syncPin(ctx context.Context, name string) {
+	c.mu.Lock()
+	p := c.pins[name]
+	if p == nil {
+		// unpinned since the sync started
+		c.mu.Unlock()
+		return
+	}
+	p.syncing = true
+	c.mu.Unlock()
+
+	var (
+		result = pin{}
+		seen   = make(map[string]struct{})
+	)
+	fetch := func(o fs.Object) {
+		remote := o.Remote()
+		seen[remote] = struct{}{}
+		result.files++
+		result.bytes += o.Size()
+		item := c.Item(remote)
+		if item.IsDirty() {
+			// local changes take precedence and are in the cache already
+			result.cached++
+			return
+		}
+		if err := item.fetch(o); err != nil {
+			fs.Errorf(remote, "vfs cache: failed to download pinned file: %v", err)
+			result.errored++
+			result.lastErr = err
+			return
+		}
+		result.cached++
+	}
+
+	// name may be a file rather than a directory
+	var (
+		o       fs.Object
+		listErr error
+	)
+	if name != "" {
+		o, _ = c.fremote.NewObject(ctx, name)
+	}
+	if o != nil {
+		fetch(o)
+	} else {
+		listErr = walk.ListR(ctx, c.fremote, name, true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+			entries.ForObject(fetch)
+			return ctx.Err()
+		})
+		if listErr != nil {
+			fs.Errorf(name, "vfs cache: failed to list pinned path: %v", listErr)
+			result.lastErr = listErr
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Remove the files which have gone from the remote, but only if
+	// the listing was complete
+	if listErr == nil {
+		for itemName, item := range c.item {
+			if _, found := seen[itemName]; !found && under(itemName, name) {
+				c.removeNotInUse(item, 0, false)
+			}
+		}
+	}
+
+	result.synced = time.Now()
+	if p = c.pins[name]; p != nil {
+		*p = result
+	}
+	fs.Infof(name, "vfs cache: synced pin: %d/%d files in cache, %d errors", result.cached, result.files, result.errored)
+}
+
+// _pinStats returns the status of each pinned path
+//
+// call with c.mu held
+func (c *Cache) _pinStats() []rc.Params {
+	names := make([]string, 0, len(c.pins))
+	for name := range c.pins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]rc.Params, 0, len(names))
+	for _, name := range names {
+		p := c.pins[name]
+		status := rc.Params{
+			"path":         name,
+			"files":        p.files,
+			"bytes":        p.bytes,
+			"cachedFiles":  p.cached,
+			"erroredFiles": p.errored,
+			"syncing":      p.syncing,
+			"lastError":    "",
+			"synced":       "",
+		}
+		if p.lastErr != nil {
+			status["lastError"] = p.lastErr.Error()
+		}
+		if !p.synced.IsZero() {
+			status["synced"] = p.synced.Format(time.RFC3339)
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+// readPinFile reads the paths to pin from the file at path, one per
+// line. Blank lines and lines starting with # or ; are ignored.
+func readPinFile(path string) (names []string, err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(in, &err)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		names = append(names, clean(line))
+	}
+	return names, scanner.Err()
+}
@@ -0,0 +1,125 @@
+package vfscache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachePin(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.CachePollInterval = 0 // pins are synced by the test
+	opt.WriteBack = 0
+	r, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	t1 := fstest.Time("2001-02-03T04:05:06.499999999Z")
+	r.WriteObject(ctx, "dir/file1", "hello", t1)
+	r.WriteObject(ctx, "dir/sub/file2", "hello world", t1)
+	r.WriteObject(ctx, "dir2/file3", "potato", t1)
+	r.WriteObject(ctx, "file4", "sausage", t1)
+
+	require.NoError(t, c.Pin("/dir/"))
+	require.NoError(t, c.Pin("file4"))
+	c.syncPins(ctx)
+
+	assert.Equal(t, []string{
+		`name="dir/file1" opens=0 size=5`,
+		`name="dir/sub/file2" opens=0 size=11`,
+		`name="file4" opens=0 size=7`,
+	}, itemAsString(c))
+	for _, name := range []string{"dir/file1", "dir/sub/file2", "file4"} {
+		assert.True(t, c.Item(name).present(), name)
+	}
+
+	pinned := c.Stats()["pinned"].([]rc.Params)
+	require.Len(t, pinned, 2)
+	assert.Equal(t, "dir", pinned[0]["path"])
+	assert.Equal(t, 2, pinned[0]["files"])
+	assert.Equal(t, int64(16), pinned[0]["bytes"])
+	assert.Equal(t, 2, pinned[0]["cachedFiles"])
+	assert.Equal(t, 0, pinned[0]["erroredFiles"])
+	assert.Equal(t, "", pinned[0]["lastError"])
+	assert.NotEqual(t, "", pinned[0]["synced"])
+	assert.Equal(t, "file4", pinned[1]["path"])
+	assert.Equal(t, 1, pinned[1]["files"])
+
+	// Pinned items aren't removed to make space or for age
+	c.purgeOld(-10 * time.Second)
+	c.purgeOverQuota(1)
+	c.purgeClean(1)
+	assert.Equal(t, 3, len(itemAsString(c)))
+
+	// Files removed from the remote are removed from the cache
+	require.NoError(t, operations.Purge(ctx, r.Fremote, "dir/sub"))
+	c.syncPins(ctx)
+	assert.Equal(t, []string{
+		`name="dir/file1" opens=0 size=5`,
+		`name="file4" opens=0 size=7`,
+	}, itemAsString(c))
+
+	// Files changed on the remote are downloaded again
+	t2 := t1.Add(time.Minute)
+	r.WriteObject(ctx, "dir/file1", "hello again", t2)
+	c.syncPins(ctx)
+	data, err := os.ReadFile(filepath.Join(c.root, "dir", "file1"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello again", string(data))
+
+	// Unpinned items are removed as normal
+	require.NoError(t, c.Unpin("dir"))
+	assert.Error(t, c.Unpin("dir"))
+	c.purgeOld(-10 * time.Second)
+	assert.Equal(t, []string{
+		`name="file4" opens=0 size=7`,
+	}, itemAsString(c))
+}
+
+func TestCachePinNeedsFull(t *testing.T) {
+	_, c, cleanup := newTestCache(t)
+	defer cleanup()
+
+	c.opt.CacheMode = vfscommon.CacheModeWrites
+	assert.ErrorContains(t, c.Pin("dir"), "--vfs-cache-mode full")
+	assert.Len(t, c.Stats()["pinned"], 0)
+}
+
+func TestCacheIsPinned(t *testing.T) {
+	c := &Cache{pins: map[string]*pin{}}
+	assert.False(t, c._isPinned("dir/file"))
+	c.pins["dir"] = &pin{}
+	assert.True(t, c._isPinned("dir"))
+	assert.True(t, c._isPinned("dir/file"))
+	assert.True(t, c._isPinned("dir/sub/file"))
+	assert.False(t, c._isPinned("dir2/file"))
+	assert.False(t, c._isPinned("file"))
+	c.pins[""] = &pin{}
+	assert.True(t, c._isPinned("file"))
+}
+
+func TestReadPinFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins")
+	require.NoError(t, os.WriteFile(path, []byte(`# comment
+; another comment
+
+/dir/
+  dir2/file.txt
+`), 0600))
+	names, err := readPinFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir", "dir2/file.txt"}, names)
+
+	_, err = readPinFile(path + "-not-found")
+	assert.Error(t, err)
+}
@@ -0,0 +1,375 @@
+package vfscache
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/ranges"
+)
+
+// crc32cTable is used for the checksums of the chunks of cache files
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// VerifyOpt controls what Verify checks
+type VerifyOpt struct {
+	Checksums bool // read the cache files and check the chunk checksums
+	Remote    bool // check clean items haven't changed on the remote
+}
+
+// VerifyResult is the outcome of verifying the cache
+type VerifyResult struct {
+	Started     time.Time // when the verification started
+	Checked     int       // number of items checked
+	Skipped     int       // number of items skipped as they were in use
+	Fixed       int       // number of items whose metadata was corrected
+	Removed     int       // number of clean items removed as corrupt or stale
+	Quarantined int       // number of dirty items moved out of the cache as corrupt
+	Requeued    int       // number of dirty items queued for upload
+	Problems    []string  // the problems found
+}
+
+// Params returns the result in a form suitable for rc
+func (r *VerifyResult) Params() rc.Params {
+	problems := r.Problems
+	if problems == nil {
+		problems = []string{}
+	}
+	return rc.Params{
+		"started":     r.Started.Format(time.RFC3339),
+		"checked":     r.Checked,
+		"skipped":     r.Skipped,
+		"fixed":       r.Fixed,
+		"removed":     r.Removed,
+		"quarantined": r.Quarantined,
+		"requeued":    r.Requeued,
+		"problems":    problems,
+	}
+}
+
+// verifyAction is what verifying an item did
+type verifyAction byte
+
+const (
+	verifyOK          verifyAction = iota // nothing wrong
+	verifySkipped                         // not checked as in use
+	verifyFixed                           // metadata corrected
+	verifyRemoved                         // clean item removed
+	verifyQuarantined                     // dirty item moved to the quarantine
+	verifyFailed                          // problem found which couldn't be dealt with
+)
+
+// Verify checks the items in the cache which aren't in use, fixing,
+// removing or quarantining them as necessary.
+//
+// Items removed are left in the cache empty rather than forgotten as
+// they may have been opened again in the meantime.
+func (c *Cache) Verify(ctx context.Context, opt VerifyOpt) VerifyResult {
+	result := VerifyResult{Started: time.Now()}
+	c.mu.Lock()
+	items := make([]*Item, 0, len(c.item))
+	for _, item := range c.item {
+		items = append(items, item)
+	}
+	c.mu.Unlock()
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		c.verifyItem(ctx, item, opt, &result)
+	}
+	c.updateUsed()
+	c.logVerify(&result)
+	return result
+}
+
+// verifyItem verifies a single item adding what happened to result
+func (c *Cache) verifyItem(ctx context.Context, item *Item, opt VerifyOpt, result *VerifyResult) (action verifyAction) {
+	action, problem := item.verify(ctx, opt)
+	if action == verifySkipped {
+		result.Skipped++
+		return action
+	}
+	result.Checked++
+	if problem == "" {
+		return action
+	}
+	result.Problems = append(result.Problems, item.name+": "+problem)
+	switch action {
+	case verifyFixed:
+		result.Fixed++
+		fs.Logf(item.name, "vfs cache: verify: fixed: %s", problem)
+	case verifyRemoved:
+		result.Removed++
+		fs.Logf(item.name, "vfs cache: verify: removed: %s", problem)
+	case verifyQuarantined:
+		result.Quarantined++
+	default:
+		fs.Errorf(item.name, "vfs cache: verify: %s", problem)
+	}
+	return action
+}
+
+// logVerify logs a summary of result and keeps it for the stats
+func (c *Cache) logVerify(result *VerifyResult) {
+	logf := fs.Infof
+	if len(result.Problems) > 0 {
+		logf = fs.Logf
+	}
+	logf(nil, "vfs cache: verified %d items in %v: %d skipped, %d fixed, %d removed, %d quarantined, %d queued for upload",
+		result.Checked, time.Since(result.Started).Truncate(time.Millisecond), result.Skipped, result.Fixed, result.Removed, result.Quarantined, result.Requeued)
+	c.mu.Lock()
+	c.lastVerify = *result
+	c.mu.Unlock()
+}
+
+// verify checks the cache file and metadata of the item agree with
+// each other and, if asked, with the chunk checksums and the remote.
+//
+// Metadata which can be corrected is, clean items which are corrupt
+// or stale are removed, and dirty items which are corrupt are
+// quarantined so the changes in them can be recovered by hand.
+func (item *Item) verify(ctx context.Context, opt VerifyOpt) (action verifyAction, problem string) {
+	item.mu.Lock()
+	action, problem = item._verify(opt)
+	fingerprint := item.info.Fingerprint
+	checkRemote := opt.Remote && action == verifyOK && !item.info.Dirty && fingerprint != ""
+	item.mu.Unlock()
+	if !checkRemote {
+		return action, problem
+	}
+
+	// Look the object up without the lock as it may take a while
+	reason, problem := "stale (remote deleted)", "deleted on the remote"
+	o, err := item.c.fremote.NewObject(ctx, item.name)
+	if err == nil {
+		if fs.Fingerprint(ctx, o, item.c.opt.FastFingerprint) == fingerprint {
+			return verifyOK, ""
+		}
+		reason, problem = "stale (remote is different)", "changed on the remote"
+	} else if !errors.Is(err, fs.ErrorObjectNotFound) {
+		return verifyFailed, fmt.Sprintf("failed to find object on the remote: %v", err)
+	}
+
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	// The item may have been opened or changed while it was unlocked
+	if item.opens != 0 || item.beingReset || item.info.Dirty || item.info.Fingerprint != fingerprint {
+		return verifySkipped, ""
+	}
+	item._remove(reason)
+	return verifyRemoved, problem
+}
+
+// _verify does the checks of verify which don't need the remote
+//
+// call with lock held
+func (item *Item) _verify(opt VerifyOpt) (action verifyAction, problem string) {
+	if item.opens != 0 || item.beingReset {
+		return verifySkipped, ""
+	}
+
+	corrupt := func(format string, a ...interface{}) (verifyAction, string) {
+		problem := fmt.Sprintf(format, a...)
+		if !item.info.Dirty {
+			if item._remove("corrupt: " + problem) {
+				fs.Errorf(item.name, "vfs cache: item removed when it was writing/uploaded")
+			}
+			return verifyRemoved, problem
+		}
+		if err := item._quarantine(problem); err != nil {
+			return verifyFailed, fmt.Sprintf("%s: failed to quarantine: %v", problem, err)
+		}
+		return verifyQuarantined, problem
+	}
+
+	osPath := item.c.toOSPath(item.name) // No locking in Cache
+	fi, err := os.Stat(osPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if item.info.Rs.Size() == 0 && !item.info.Dirty {
+			return verifyOK, ""
+		}
+		return corrupt("cache file is missing")
+	} else if err != nil {
+		return verifyFailed, fmt.Sprintf("failed to read cache file size: %v", err)
+	}
+	size := fi.Size()
+
+	// A clean cache file is always the size of the object
+	if !item.info.Dirty {
+		if remoteSize, ok := fingerprintSize(item.info.Fingerprint); ok && remoteSize != size {
+			return corrupt("cache file is %d bytes but the object is %d bytes", size, remoteSize)
+		}
+	}
+
+	// The data recorded as cached must be in the file
+	if n := len(item.info.Rs); n > 0 && item.info.Rs[n-1].End() > size {
+		end := item.info.Rs[n-1].End()
+		if !item.info.Dirty {
+			return corrupt("cached data recorded up to %d bytes but cache file is %d bytes", end, size)
+		}
+		// The cache file is the only copy of the changes so keep
+		// what there is of it
+		item.info.Rs = item.info.Rs.Intersection(ranges.Range{Pos: 0, Size: size})
+		item._clearSums(size, end-size)
+		action, problem = verifyFixed, fmt.Sprintf("cached data recorded beyond the end of the %d byte cache file", size)
+	}
+	item.info.Size = size
+
+	if opt.Checksums {
+		n, err := item._checkSums(osPath)
+		if err != nil {
+			return verifyFailed, fmt.Sprintf("failed to check checksums: %v", err)
+		}
+		if n >= 0 {
+			return corrupt("checksum mismatch in chunk %d", n)
+		}
+	}
+
+	if action == verifyFixed {
+		if err := item._save(); err != nil {
+			return verifyFailed, fmt.Sprintf("%s: failed to write metadata: %v", problem, err)
+		}
+	}
+	return action, problem
+}
+
+// fingerprintSize returns the object size recorded in fingerprint and
+// whether it was known
+func fingerprintSize(fingerprint string) (size int64, ok bool) {
+	if i := strings.IndexByte(fingerprint, ','); i >= 0 {
+		fingerprint = fingerprint[:i]
+	}
+	size, err := strconv.ParseInt(fingerprint, 10, 64)
+	return size, err == nil && size >= 0
+}
+
+// _quarantine moves the cache file and metadata of a dirty item out
+// of the cache so the changes in it aren't lost, then removes the item.
+//
+// call with lock held
+func (item *Item) _quarantine(reason string) error {
+	dst := filepath.Join(item.c.quarantine, toOSPath(item.name)) + "." + time.Now().Format("2006-01-02-150405")
+	err := createDir(filepath.Dir(dst))
+	if err != nil {
+		return err
+	}
+	err = os.Rename(item.c.toOSPath(item.name), dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	err = os.Rename(item.c.toOSPathMeta(item.name), dst+".meta")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	fs.Errorf(item.name, "vfs cache: changes which weren't uploaded are corrupt (%s) - moved them to %q", reason, dst)
+	item._remove("quarantined")
+	return nil
+}
+
+// _chunkRange returns the part of the file in chunk n
+//
+// call with lock held
+func (item *Item) _chunkRange(n int64) ranges.Range {
+	chunkRange := ranges.Range{Pos: n * evictChunkSize, Size: evictChunkSize}
+	return chunkRange.Intersection(ranges.Range{Pos: 0, Size: item.info.Size})
+}
+
+// chunkSum returns the checksum of r in the file
+func chunkSum(in io.ReaderAt, r ranges.Range) (string, error) {
+	h := crc32.New(crc32cTable)
+	_, err := io.Copy(h, io.NewSectionReader(in, r.Pos, r.Size))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// _sumChunks records the checksums of the chunks of the file which are
+// completely in the cache and don't have one already.
+//
+// call with lock held
+func (item *Item) _sumChunks(in io.ReaderAt) error {
+	for n, size := range item._chunkSizes() {
+		chunk := item.info.Chunks[n]
+		if chunk.Sum != "" {
+			continue
+		}
+		r := item._chunkRange(n)
+		if r.IsEmpty() || size != r.Size {
+			continue
+		}
+		sum, err := chunkSum(in, r)
+		if err != nil {
+			return fmt.Errorf("failed to checksum cache file: %w", err)
+		}
+		chunk.Sum = sum
+		if item.info.Chunks == nil {
+			item.info.Chunks = make(map[int64]Chunk)
+		}
+		item.info.Chunks[n] = chunk
+	}
+	return nil
+}
+
+// _checkSums reads the chunks of the cache file at osPath which have
+// checksums and returns the number of a chunk which doesn't match or
+// -1 if they all do.
+//
+// call with lock held
+func (item *Item) _checkSums(osPath string) (bad int64, err error) {
+	var in *os.File
+	defer func() {
+		if in != nil {
+			fs.CheckClose(in, &err)
+		}
+	}()
+	for n, chunk := range item.info.Chunks {
+		if chunk.Sum == "" {
+			continue
+		}
+		r := item._chunkRange(n)
+		if r.IsEmpty() || !item.info.Rs.Present(r) {
+			// the chunk isn't complete any more
+			continue
+		}
+		if in == nil {
+			in, err = os.Open(osPath)
+			if err != nil {
+				return -1, err
+			}
+		}
+		var sum string
+		sum, err = chunkSum(in, r)
+		if err != nil {
+			return -1, err
+		}
+		if sum != chunk.Sum {
+			return n, nil
+		}
+	}
+	return -1, nil
+}
+
+// _clearSums removes the checksums of the chunks overlapping offset,
+// size as the data in them has changed.
+//
+// call with lock held
+func (item *Item) _clearSums(offset, size int64) {
+	for n := offset / evictChunkSize; n*evictChunkSize < offset+size; n++ {
+		if chunk, found := item.info.Chunks[n]; found && chunk.Sum != "" {
+			chunk.Sum = ""
+			item.info.Chunks[n] = chunk
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package vfscache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/lib/ranges"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFetchedFile makes a file on the remote and downloads all of it
+// into the cache
+func newFetchedFile(t *testing.T, r *fstest.Run, c *Cache, remote string) (contents string, item *Item) {
+	contents, obj, item := newFile(t, r, c, remote)
+	require.NoError(t, item.fetch(obj))
+	require.True(t, item.present())
+	return contents, item
+}
+
+// removeQuarantine removes the quarantine directory after the test
+func removeQuarantine(t *testing.T, c *Cache) {
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(c.quarantine))
+	})
+}
+
+func TestCacheVerify(t *testing.T) {
+	r, c, cleanup := newTestCache(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, short := newFetchedFile(t, r, c, "short")
+	_, gone := newFetchedFile(t, r, c, "gone")
+	_, ok := newFetchedFile(t, r, c, "ok")
+
+	// Nothing wrong yet
+	result := c.Verify(ctx, VerifyOpt{Remote: true})
+	assert.Equal(t, 3, result.Checked)
+	assert.Equal(t, 0, result.Removed)
+	assert.Len(t, result.Problems, 0)
+
+	// Break short and remove gone from the remote
+	require.NoError(t, os.Truncate(c.toOSPath("short"), 10))
+	o, err := r.Fremote.NewObject(ctx, "gone")
+	require.NoError(t, err)
+	require.NoError(t, operations.DeleteFile(ctx, o))
+
+	// Open items aren't checked
+	o, err = r.Fremote.NewObject(ctx, "ok")
+	require.NoError(t, err)
+	require.NoError(t, ok.Open(o))
+	result = c.Verify(ctx, VerifyOpt{Remote: true})
+	require.NoError(t, ok.Close(nil))
+	assert.Equal(t, 2, result.Checked)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 2, result.Removed)
+	require.Len(t, result.Problems, 2)
+	for _, problem := range result.Problems {
+		if strings.HasPrefix(problem, "short: ") {
+			assert.Contains(t, problem, "cache file is 10 bytes but the object is 100 bytes")
+		} else {
+			assert.Equal(t, "gone: deleted on the remote", problem)
+		}
+	}
+	assert.False(t, short.present())
+	assert.False(t, gone.present())
+	assert.True(t, ok.present())
+	assertPathNotExist(t, c.toOSPath("short"))
+	assertPathNotExist(t, c.toOSPathMeta("short"))
+
+	// The result is in the stats
+	lastVerify := c.Stats()["lastVerify"].(rc.Params)
+	assert.Equal(t, 2, lastVerify["removed"])
+}
+
+func TestCacheVerifyChecksums(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	opt.WriteBack = 0
+	opt.CacheChecksum = true
+	r, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, item := newFetchedFile(t, r, c, "potato")
+	require.Len(t, item.info.Chunks, 1)
+	assert.Len(t, item.info.Chunks[0].Sum, 8)
+
+	// The checksum is kept in the metadata
+	reloaded := newItem(c, "potato")
+	assert.Equal(t, item.info.Chunks[0].Sum, reloaded.info.Chunks[0].Sum)
+
+	result := c.Verify(ctx, VerifyOpt{Checksums: true})
+	assert.Len(t, result.Problems, 0)
+
+	// Corrupt the data without changing the size
+	f, err := os.OpenFile(c.toOSPath("potato"), os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte("XXX"), 50)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The size check doesn't find it
+	result = c.Verify(ctx, VerifyOpt{})
+	assert.Len(t, result.Problems, 0)
+
+	// But the checksum does
+	result = c.Verify(ctx, VerifyOpt{Checksums: true})
+	assert.Equal(t, 1, result.Removed)
+	assert.Equal(t, []string{"potato: checksum mismatch in chunk 0"}, result.Problems)
+	assert.False(t, item.present())
+}
+
+func TestItemClearSums(t *testing.T) {
+	item := &Item{}
+	item.info.Chunks = map[int64]Chunk{
+		0: {Sum: "00000000"},
+		1: {Sum: "11111111"},
+		2: {Sum: "22222222"},
+	}
+
+	item._clearSums(evictChunkSize+1, 1)
+	assert.Equal(t, "00000000", item.info.Chunks[0].Sum)
+	assert.Equal(t, "", item.info.Chunks[1].Sum)
+	assert.Equal(t, "22222222", item.info.Chunks[2].Sum)
+
+	// Writing clears the checksum too
+	item._written(0, 1)
+	assert.Equal(t, "", item.info.Chunks[0].Sum)
+	assert.Equal(t, "22222222", item.info.Chunks[2].Sum)
+}
+
+func TestCacheVerifyDirty(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	opt.WriteBack = time.Hour // keep the items dirty
+	_, c, cleanup := newTestCacheOpt(t, opt)
+	defer cleanup()
+	removeQuarantine(t, c)
+	ctx := context.Background()
+
+	truncated := c.Item("truncated")
+	itemWrite(t, truncated, "hello world")
+	require.NoError(t, truncated.Close(nil))
+	missing := c.Item("missing")
+	itemWrite(t, missing, "potato")
+	require.NoError(t, missing.Close(nil))
+	_, uploadsQueued := c.writeback.Stats()
+	assert.Equal(t, 2, uploadsQueued)
+
+	// Lose the end of one file and all of the other
+	require.NoError(t, os.Truncate(c.toOSPath("truncated"), 5))
+	require.NoError(t, os.Remove(c.toOSPath("missing")))
+
+	result := c.Verify(ctx, VerifyOpt{})
+	assert.Equal(t, 1, result.Fixed)
+	assert.Equal(t, 1, result.Quarantined)
+
+	// What is left of the changes is kept
+	assert.True(t, truncated.IsDirty())
+	assert.Equal(t, ranges.Ranges{ranges.Range{Pos: 0, Size: 5}}, truncated.info.Rs)
+	assert.Equal(t, int64(5), truncated.info.Size)
+
+	// The missing file is moved to the quarantine and not uploaded
+	assert.False(t, missing.IsDirty())
+	assertPathNotExist(t, c.toOSPathMeta("missing"))
+	matches, err := filepath.Glob(filepath.Join(c.quarantine, "missing.*.meta"))
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+	_, uploadsQueued = c.writeback.Stats()
+	assert.Equal(t, 1, uploadsQueued)
+}
+
+func TestCacheReloadVerify(t *testing.T) {
+	r, c, cleanup := newTestCache(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, _ = newFetchedFile(t, r, c, "corrupt")
+	_, _ = newFetchedFile(t, r, c, "ok")
+	require.NoError(t, os.Truncate(c.toOSPath("corrupt"), 200))
+
+	// Forget the items and load them again as if rclone had restarted
+	c.mu.Lock()
+	c.item = make(map[string]*Item)
+	c.mu.Unlock()
+	require.NoError(t, c.reload(ctx))
+
+	assert.Equal(t, []string{
+		`name="ok" opens=0 size=100`,
+	}, itemAsString(c))
+	assertPathNotExist(t, c.toOSPath("corrupt"))
+	assert.Equal(t, 2, c.lastVerify.Checked)
+	assert.Equal(t, 1, c.lastVerify.Removed)
+}
+
+func TestFingerprintSize(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		size int64
+		ok   bool
+	}{
+		{"", 0, false},
+		{"100", 100, true},
+		{"100,2001-02-03 04:05:06 +0000 UTC", 100, true},
+		{"100,2001-02-03 04:05:06 +0000 UTC,5d41402abc4b2a76b9719d911017c592", 100, true},
+		{"-1,2001-02-03 04:05:06 +0000 UTC", -1, false},
+		{"potato", 0, false},
+	} {
+		size, ok := fingerprintSize(test.in)
+		assert.Equal(t, test.ok, ok, test.in)
+		if ok {
+			assert.Equal(t, test.size, size, test.in)
+		}
+	}
+}
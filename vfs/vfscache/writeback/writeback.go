@@ -6,6 +6,7 @@ import (
 	"container/heap"
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -34,6 +35,7 @@ type WriteBack struct {
 	timer   *time.Timer               // next scheduled time for the uploader
 	expiry  time.Time                 // time the next item expires or IsZero
 	uploads int                       // number of uploads in progress
+	paused  bool                      // set if no new uploads should be started
 
 	// read and written with atomic
 	id Handle // id of the last writeBackItem created
@@ -62,6 +64,7 @@ func New(ctx context.Context, opt *vfscommon.Options) *WriteBack {
 // writeBack.mu must be held to manipulate this
 type writeBackItem struct {
 	name      string             // name of the item so we don't have to read it from item
+	size      int64              // size of the item when it was queued
 	id        Handle             // id of the item
 	index     int                // index into the priority queue for update
 	expiry    time.Time          // When this expires we will write it back
@@ -72,6 +75,7 @@ type writeBackItem struct {
 	putFn     PutFn              // To write the object data
 	tries     int                // number of times we have tried to upload
 	delay     time.Duration      // delay between upload attempts
+	err       error              // error from the last upload attempt if it failed
 }
 
 // A writeBackItems implements a priority queue by implementing
@@ -135,10 +139,11 @@ func (wb *WriteBack) _newExpiry() time.Time {
 // make a new writeBackItem
 //
 // call with the lock held
-func (wb *WriteBack) _newItem(id Handle, name string) *writeBackItem {
+func (wb *WriteBack) _newItem(id Handle, name string, size int64) *writeBackItem {
 	wb.SetID(&id)
 	wbItem := &writeBackItem{
 		name:   name,
+		size:   size,
 		expiry: wb._newExpiry(),
 		delay:  wb.opt.WriteBack,
 		id:     id,
@@ -256,14 +261,17 @@ func (wb *WriteBack) SetID(pid *Handle) {
 //
 // If modified is false then it it doesn't cancel a pending upload if
 // there is one as there is no need.
-func (wb *WriteBack) Add(id Handle, name string, modified bool, putFn PutFn) Handle {
+//
+// size is the size of the item which is only used for reporting.
+func (wb *WriteBack) Add(id Handle, name string, size int64, modified bool, putFn PutFn) Handle {
 	wb.mu.Lock()
 	defer wb.mu.Unlock()
 
 	wbItem, ok := wb.lookup[id]
 	if !ok {
-		wbItem = wb._newItem(id, name)
+		wbItem = wb._newItem(id, name, size)
 	} else {
+		wbItem.size = size
 		if wbItem.uploading && modified {
 			// We are uploading already so cancel the upload
 			wb._cancelUpload(wbItem)
@@ -371,6 +379,7 @@ func (wb *WriteBack) upload(ctx context.Context, wbItem *writeBackItem) {
 			wbItem.delay = wb.opt.WriteBack
 		} else {
 			fs.Errorf(wbItem.name, "vfs cache: failed to upload try #%d, will retry in %v: %v", wbItem.tries, wbItem.delay, err)
+			wbItem.err = err
 		}
 		// push the item back on the queue for retry
 		wb._pushItem(wbItem)
@@ -431,6 +440,12 @@ func (wb *WriteBack) processItems(ctx context.Context) {
 		return
 	}
 
+	// Don't start any uploads while paused - Resume restarts the timer
+	if wb.paused {
+		wb._stopTimer()
+		return
+	}
+
 	resetTimer := true
 	for wbItem := wb._peekItem(); wbItem != nil && time.Until(wbItem.expiry) <= 0; wbItem = wb._peekItem() {
 		// If reached transfer limit don't restart the timer
@@ -463,3 +478,104 @@ func (wb *WriteBack) Stats() (uploadsInProgress, uploadsQueued int) {
 	defer wb.mu.Unlock()
 	return wb.uploads, len(wb.items)
 }
+
+// Pause stops any new uploads being started until Resume is called.
+//
+// Uploads which are in progress carry on.
+func (wb *WriteBack) Pause() {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	if !wb.paused {
+		fs.Logf(nil, "vfs cache: uploads paused")
+		wb.paused = true
+	}
+}
+
+// Resume starts uploading again after Pause
+func (wb *WriteBack) Resume() {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	if wb.paused {
+		fs.Logf(nil, "vfs cache: uploads resumed")
+		wb.paused = false
+		wb._resetTimer()
+	}
+}
+
+// Paused returns true if uploads are paused
+func (wb *WriteBack) Paused() bool {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return wb.paused
+}
+
+// QueueInfo is information about an item in the writeback queue as
+// returned by Queue
+type QueueInfo struct {
+	Name      string  `json:"name"`      // name of the file
+	ID        Handle  `json:"id"`        // id of the item for SetExpiry
+	Size      int64   `json:"size"`      // size of the file when it was queued
+	Expiry    float64 `json:"expiry"`    // seconds from now when the file will be uploaded
+	Tries     int     `json:"tries"`     // number of times the upload has been tried
+	Delay     float64 `json:"delay"`     // seconds between upload attempts
+	Uploading bool    `json:"uploading"` // set if the file is being uploaded
+	Error     string  `json:"error"`     // error from the last attempt if it failed
+}
+
+// Queue returns info about the items in the writeback queue, the ones
+// being uploaded first then in the order they will be uploaded.
+func (wb *WriteBack) Queue() []QueueInfo {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	now := time.Now()
+	items := make([]QueueInfo, 0, len(wb.lookup))
+	for _, wbItem := range wb.lookup {
+		info := QueueInfo{
+			Name:      wbItem.name,
+			ID:        wbItem.id,
+			Size:      wbItem.size,
+			Expiry:    wbItem.expiry.Sub(now).Seconds(),
+			Tries:     wbItem.tries,
+			Delay:     wbItem.delay.Seconds(),
+			Uploading: wbItem.uploading,
+		}
+		if wbItem.err != nil {
+			info.Error = wbItem.err.Error()
+		}
+		items = append(items, info)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := &items[i], &items[j]
+		if a.Uploading != b.Uploading {
+			return a.Uploading
+		}
+		if a.Expiry != b.Expiry {
+			return a.Expiry < b.Expiry
+		}
+		return a.ID < b.ID
+	})
+	return items
+}
+
+// ErrorIDNotFound is returned from SetExpiry when the item isn't in
+// the queue
+var ErrorIDNotFound = errors.New("id not found in queue")
+
+// SetExpiry sets the time the item with id will be uploaded, so it can
+// be uploaded now or delayed.
+//
+// This has no effect on an item which is being uploaded.
+func (wb *WriteBack) SetExpiry(id Handle, expiry time.Time) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	wbItem, ok := wb.lookup[id]
+	if !ok {
+		return ErrorIDNotFound
+	}
+	if !wbItem.onHeap {
+		return nil
+	}
+	wb.items._update(wbItem, expiry)
+	wb._resetTimer()
+	return nil
+}
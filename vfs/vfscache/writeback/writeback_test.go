@@ -13,6 +13,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newTestWriteBack(t *testing.T) (wb *WriteBack, cancel func()) {
@@ -122,15 +123,15 @@ func TestWriteBackItemCRUD(t *testing.T) {
 	// _peekItem empty
 	assert.Nil(t, wb._peekItem())
 
-	wbItem1 := wb._newItem(0, "one")
+	wbItem1 := wb._newItem(0, "one", 0)
 	checkOnHeap(t, wb, wbItem1)
 	checkInLookup(t, wb, wbItem1)
 
-	wbItem2 := wb._newItem(0, "two")
+	wbItem2 := wb._newItem(0, "two", 0)
 	checkOnHeap(t, wb, wbItem2)
 	checkInLookup(t, wb, wbItem2)
 
-	wbItem3 := wb._newItem(0, "three")
+	wbItem3 := wb._newItem(0, "three", 0)
 	checkOnHeap(t, wb, wbItem3)
 	checkInLookup(t, wb, wbItem3)
 
@@ -201,7 +202,7 @@ func TestWriteBackResetTimer(t *testing.T) {
 	// Check timer is stopped
 	assertTimerRunning(t, wb, false)
 
-	_ = wb._newItem(0, "three")
+	_ = wb._newItem(0, "three", 0)
 
 	// Reset the timer on an queue with stuff
 	wb._resetTimer()
@@ -297,7 +298,7 @@ func TestWriteBackAddOK(t *testing.T) {
 	wb.SetID(&inID)
 	assert.Equal(t, Handle(1), inID)
 
-	id := wb.Add(inID, "one", true, pi.put)
+	id := wb.Add(inID, "one", 0, true, pi.put)
 	assert.Equal(t, inID, id)
 	wbItem := wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
@@ -321,7 +322,7 @@ func TestWriteBackAddFailRetry(t *testing.T) {
 
 	pi := newPutItem(t)
 
-	id := wb.Add(0, "one", true, pi.put)
+	id := wb.Add(0, "one", 0, true, pi.put)
 	wbItem := wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
@@ -354,7 +355,7 @@ func TestWriteBackAddUpdate(t *testing.T) {
 
 	pi := newPutItem(t)
 
-	id := wb.Add(0, "one", true, pi.put)
+	id := wb.Add(0, "one", 0, true, pi.put)
 	wbItem := wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
@@ -367,7 +368,7 @@ func TestWriteBackAddUpdate(t *testing.T) {
 	// Now the upload has started add another one
 
 	pi2 := newPutItem(t)
-	id2 := wb.Add(id, "one", true, pi2.put)
+	id2 := wb.Add(id, "one", 0, true, pi2.put)
 	assert.Equal(t, id, id2)
 	checkOnHeap(t, wb, wbItem) // object awaiting writeback time
 	checkInLookup(t, wb, wbItem)
@@ -393,7 +394,7 @@ func TestWriteBackAddUpdateNotModified(t *testing.T) {
 
 	pi := newPutItem(t)
 
-	id := wb.Add(0, "one", false, pi.put)
+	id := wb.Add(0, "one", 0, false, pi.put)
 	wbItem := wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
@@ -406,7 +407,7 @@ func TestWriteBackAddUpdateNotModified(t *testing.T) {
 	// Now the upload has started add another one
 
 	pi2 := newPutItem(t)
-	id2 := wb.Add(id, "one", false, pi2.put)
+	id2 := wb.Add(id, "one", 0, false, pi2.put)
 	assert.Equal(t, id, id2)
 	checkNotOnHeap(t, wb, wbItem) // object still being transferred
 	checkInLookup(t, wb, wbItem)
@@ -432,7 +433,7 @@ func TestWriteBackAddUpdateNotStarted(t *testing.T) {
 
 	pi := newPutItem(t)
 
-	id := wb.Add(0, "one", true, pi.put)
+	id := wb.Add(0, "one", 0, true, pi.put)
 	wbItem := wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
@@ -441,7 +442,7 @@ func TestWriteBackAddUpdateNotStarted(t *testing.T) {
 	// Immediately add another upload before the first has started
 
 	pi2 := newPutItem(t)
-	id2 := wb.Add(id, "one", true, pi2.put)
+	id2 := wb.Add(id, "one", 0, true, pi2.put)
 	assert.Equal(t, id, id2)
 	checkOnHeap(t, wb, wbItem) // object still awaiting transfer
 	checkInLookup(t, wb, wbItem)
@@ -470,7 +471,7 @@ func TestWriteBackGetStats(t *testing.T) {
 
 	pi := newPutItem(t)
 
-	wb.Add(0, "one", true, pi.put)
+	wb.Add(0, "one", 0, true, pi.put)
 
 	inProgress, queued := wb.Stats()
 	assert.Equal(t, queued, 1)
@@ -506,7 +507,7 @@ func TestWriteBackMaxQueue(t *testing.T) {
 	for i := 0; i < toTransfer; i++ {
 		pi := newPutItem(t)
 		pis = append(pis, pi)
-		wb.Add(0, fmt.Sprintf("number%d", 1), true, pi.put)
+		wb.Add(0, fmt.Sprintf("number%d", 1), 0, true, pi.put)
 	}
 
 	inProgress, queued := wb.Stats()
@@ -551,7 +552,7 @@ func TestWriteBackRename(t *testing.T) {
 
 	// add item
 	pi1 := newPutItem(t)
-	id := wb.Add(0, "one", true, pi1.put)
+	id := wb.Add(0, "one", 0, true, pi1.put)
 	wbItem := wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
@@ -566,7 +567,7 @@ func TestWriteBackRename(t *testing.T) {
 
 	// add item
 	pi2 := newPutItem(t)
-	id = wb.Add(id, "two", true, pi2.put)
+	id = wb.Add(id, "two", 0, true, pi2.put)
 	wbItem = wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
@@ -593,7 +594,7 @@ func TestWriteBackRenameDuplicates(t *testing.T) {
 
 	// add item "one"
 	pi1 := newPutItem(t)
-	id1 := wb.Add(0, "one", true, pi1.put)
+	id1 := wb.Add(0, "one", 0, true, pi1.put)
 	wbItem1 := wb.lookup[id1]
 	checkOnHeap(t, wb, wbItem1)
 	checkInLookup(t, wb, wbItem1)
@@ -605,7 +606,7 @@ func TestWriteBackRenameDuplicates(t *testing.T) {
 
 	// add item "two"
 	pi2 := newPutItem(t)
-	id2 := wb.Add(0, "two", true, pi2.put)
+	id2 := wb.Add(0, "two", 0, true, pi2.put)
 	wbItem2 := wb.lookup[id2]
 	checkOnHeap(t, wb, wbItem2)
 	checkInLookup(t, wb, wbItem2)
@@ -641,7 +642,7 @@ func TestWriteBackCancelUpload(t *testing.T) {
 
 	// add item
 	pi := newPutItem(t)
-	id := wb.Add(0, "one", true, pi.put)
+	id := wb.Add(0, "one", 0, true, pi.put)
 	wbItem := wb.lookup[id]
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
@@ -662,3 +663,78 @@ func TestWriteBackCancelUpload(t *testing.T) {
 	checkInLookup(t, wb, wbItem)
 	assert.True(t, pi.cancelled)
 }
+
+func TestWriteBackQueue(t *testing.T) {
+	wb, cancel := newTestWriteBack(t)
+	defer cancel()
+
+	wb.Pause()
+	assert.True(t, wb.Paused())
+
+	pi1 := newPutItem(t)
+	pi2 := newPutItem(t)
+	id1 := wb.Add(0, "one", 10, true, pi1.put)
+	id2 := wb.Add(0, "two", 20, true, pi2.put)
+
+	names := func() (out []string) {
+		for _, info := range wb.Queue() {
+			out = append(out, info.Name)
+		}
+		return out
+	}
+
+	queue := wb.Queue()
+	require.Len(t, queue, 2)
+	assert.Equal(t, "one", queue[0].Name)
+	assert.Equal(t, id1, queue[0].ID)
+	assert.Equal(t, int64(10), queue[0].Size)
+	assert.Equal(t, 0, queue[0].Tries)
+	assert.False(t, queue[0].Uploading)
+	assert.Equal(t, "", queue[0].Error)
+	assert.Greater(t, queue[0].Expiry, 0.0)
+	assert.LessOrEqual(t, queue[0].Expiry, 0.1)
+	assert.Equal(t, "two", queue[1].Name)
+	assert.Equal(t, int64(20), queue[1].Size)
+
+	// Nothing is uploaded while paused
+	time.Sleep(200 * time.Millisecond)
+	pi1.mu.Lock()
+	assert.False(t, pi1.called)
+	pi1.mu.Unlock()
+	_, uploadsQueued := wb.Stats()
+	assert.Equal(t, 2, uploadsQueued)
+
+	// Change the order of the uploads
+	assert.NoError(t, wb.SetExpiry(id1, time.Now().Add(time.Hour)))
+	assert.NoError(t, wb.SetExpiry(id2, time.Now().Add(-time.Second)))
+	assert.Equal(t, ErrorIDNotFound, wb.SetExpiry(id2+100, time.Now()))
+	assert.Equal(t, []string{"two", "one"}, names())
+
+	// Resuming uploads the item which is due
+	wb.Resume()
+	assert.False(t, wb.Paused())
+	<-pi2.started
+	queue = wb.Queue()
+	require.Len(t, queue, 2)
+	assert.Equal(t, "two", queue[0].Name)
+	assert.True(t, queue[0].Uploading)
+
+	// A failed upload shows the error
+	pi2.finish(errors.New("transfer failed BOOM"))
+	waitUntilNoTransfers(t, wb)
+	queue = wb.Queue()
+	require.Len(t, queue, 2)
+	assert.Equal(t, "two", queue[0].Name)
+	assert.Equal(t, 1, queue[0].Tries)
+	assert.Equal(t, "transfer failed BOOM", queue[0].Error)
+
+	// And can be retried now
+	assert.NoError(t, wb.SetExpiry(id2, time.Now()))
+	<-pi2.started
+	pi2.finish(nil)
+	waitUntilNoTransfers(t, wb)
+	assert.Equal(t, []string{"one"}, names())
+
+	assert.True(t, wb.Remove(id1))
+	assert.Equal(t, []string(nil), names())
+}
@@ -0,0 +1,57 @@
+package vfscommon
+
+import (
+	"fmt"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// CachePolicy controls which data is removed first when the cache is
+// over its maximum size
+type CachePolicy byte
+
+// CachePolicy options
+const (
+	CachePolicyLRU CachePolicy = iota // remove the least recently used data first
+	CachePolicyLFU                    // remove the least frequently used data first
+)
+
+var cachePolicyToString = []string{
+	CachePolicyLRU: "lru",
+	CachePolicyLFU: "lfu",
+}
+
+// String turns a CachePolicy into a string
+func (l CachePolicy) String() string {
+	if l >= CachePolicy(len(cachePolicyToString)) {
+		return fmt.Sprintf("CachePolicy(%d)", l)
+	}
+	return cachePolicyToString[l]
+}
+
+// Set a CachePolicy
+func (l *CachePolicy) Set(s string) error {
+	for n, name := range cachePolicyToString {
+		if s != "" && name == s {
+			*l = CachePolicy(n)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown cache policy %q", s)
+}
+
+// Type of the value
+func (l *CachePolicy) Type() string {
+	return "CachePolicy"
+}
+
+// UnmarshalJSON makes sure the value can be parsed as a string or integer in JSON
+func (l *CachePolicy) UnmarshalJSON(in []byte) error {
+	return fs.UnmarshalJSONFlag(in, l, func(i int64) error {
+		if i < 0 || i >= int64(len(cachePolicyToString)) {
+			return fmt.Errorf("unknown cache policy %d", i)
+		}
+		*l = CachePolicy(i)
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+package vfscommon
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// Check CachePolicy it satisfies the pflag interface
+var _ pflag.Value = (*CachePolicy)(nil)
+
+// Check CachePolicy it satisfies the json.Unmarshaller interface
+var _ json.Unmarshaler = (*CachePolicy)(nil)
+
+func TestCachePolicyString(t *testing.T) {
+	assert.Equal(t, "lru", CachePolicyLRU.String())
+	assert.Equal(t, "lfu", CachePolicyLFU.String())
+	assert.Equal(t, "CachePolicy(17)", CachePolicy(17).String())
+}
+
+func TestCachePolicySet(t *testing.T) {
+	var m CachePolicy
+
+	err := m.Set("lfu")
+	assert.NoError(t, err)
+	assert.Equal(t, CachePolicyLFU, m)
+
+	err = m.Set("potato")
+	assert.Error(t, err)
+
+	err = m.Set("")
+	assert.Error(t, err)
+}
+
+func TestCachePolicyType(t *testing.T) {
+	var m CachePolicy
+	assert.Equal(t, "CachePolicy", m.Type())
+}
+
+func TestCachePolicyUnmarshalJSON(t *testing.T) {
+	var m CachePolicy
+
+	err := json.Unmarshal([]byte(`"lfu"`), &m)
+	assert.NoError(t, err)
+	assert.Equal(t, CachePolicyLFU, m)
+
+	err = json.Unmarshal([]byte(`"potato"`), &m)
+	assert.Error(t, err)
+
+	err = json.Unmarshal([]byte(strconv.Itoa(int(CachePolicyLRU))), &m)
+	assert.NoError(t, err)
+	assert.Equal(t, CachePolicyLRU, m)
+
+	err = json.Unmarshal([]byte("99"), &m)
+	assert.Error(t, err)
+}
@@ -26,6 +26,8 @@ type Options struct {
 	CacheMode          CacheMode
 	CacheMaxAge        time.Duration
 	CacheMaxSize       fs.SizeSuffix
+	CachePolicy        CachePolicy // which data to remove first when over CacheMaxSize
+	CacheChecksum      bool        // record checksums of the cached data to detect corruption
 	CachePollInterval  time.Duration
 	CaseInsensitive    bool
 	WriteWait          time.Duration // time to wait for in-sequence write
@@ -35,6 +37,10 @@ type Options struct {
 	UsedIsSize         bool          // if true, use the `rclone size` algorithm for Used size
 	FastFingerprint    bool          // if set use fast fingerprints
 	DiskSpaceTotalSize fs.SizeSuffix
+	NoDelete           bool          // if set files can't be deleted or overwritten
+	Quota              fs.SizeSuffix // if > 0 the most bytes which may be stored
+	DirCachePersist    bool          // if set keep directory listings on disk between runs
+	PinFrom            string        // file of paths to keep in the cache
 }
 
 // DefaultOpt is the default values uses for Opt
@@ -56,6 +62,7 @@ var DefaultOpt = Options{
 	ChunkSize:          128 * fs.Mebi,
 	ChunkSizeLimit:     -1,
 	CacheMaxSize:       -1,
+	CachePolicy:        CachePolicyLRU,
 	CaseInsensitive:    runtime.GOOS == "windows" || runtime.GOOS == "darwin", // default to true on Windows and Mac, false otherwise
 	WriteWait:          1000 * time.Millisecond,
 	ReadWait:           20 * time.Millisecond,
@@ -0,0 +1,96 @@
+package vfsdir
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// Listing is a directory listing as it was read from the remote
+type Listing struct {
+	Read    time.Time `json:"read"`    // when the listing was read
+	Entries []Entry   `json:"entries"` // what was in the directory
+}
+
+// Entry is a file or directory in a Listing
+type Entry struct {
+	Name    string            `json:"name"`
+	Dir     bool              `json:"dir,omitempty"`
+	Size    int64             `json:"size"`
+	ModTime time.Time         `json:"modTime"`          // zero if not known
+	Hashes  map[string]string `json:"hashes,omitempty"` // hash name to value
+}
+
+// NewListing makes a Listing from the entries read from f at when.
+//
+// Modification times and hashes are only recorded if they can be read
+// without another transaction with the remote.
+func NewListing(ctx context.Context, f fs.Fs, entries fs.DirEntries, when time.Time) *Listing {
+	features := f.Features()
+	listing := &Listing{
+		Read:    when,
+		Entries: make([]Entry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		e := Entry{
+			Name: path.Base(entry.Remote()),
+			Size: entry.Size(),
+		}
+		switch x := entry.(type) {
+		case fs.Directory:
+			e.Dir = true
+			e.ModTime = x.ModTime(ctx)
+		case fs.Object:
+			if !features.SlowModTime {
+				e.ModTime = x.ModTime(ctx)
+			}
+			if !features.SlowHash {
+				for _, ht := range f.Hashes().Array() {
+					sum, err := x.Hash(ctx, ht)
+					if err != nil || sum == "" {
+						continue
+					}
+					if e.Hashes == nil {
+						e.Hashes = make(map[string]string)
+					}
+					e.Hashes[ht.String()] = sum
+				}
+			}
+		default:
+			continue
+		}
+		listing.Entries = append(listing.Entries, e)
+	}
+	return listing
+}
+
+// DirEntries makes the entries of the listing of dir in f.
+//
+// Files are returned as an Object which only contacts the remote if
+// more than what was recorded in the listing is needed.
+func (l *Listing) DirEntries(f fs.Fs, dir string) (entries fs.DirEntries) {
+	entries = make(fs.DirEntries, 0, len(l.Entries))
+	for _, e := range l.Entries {
+		remote := path.Join(dir, e.Name)
+		if e.Dir {
+			entries = append(entries, fs.NewDir(remote, e.ModTime).SetSize(e.Size))
+			continue
+		}
+		var hashes map[hash.Type]string
+		for name, sum := range e.Hashes {
+			var ht hash.Type
+			if err := ht.Set(name); err != nil {
+				continue
+			}
+			if hashes == nil {
+				hashes = make(map[hash.Type]string, len(e.Hashes))
+			}
+			hashes[ht] = sum
+		}
+		entries = append(entries, newObject(f, remote, e.Size, e.ModTime, hashes))
+	}
+	return entries
+}
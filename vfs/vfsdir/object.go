@@ -0,0 +1,158 @@
+package vfsdir
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// Object is a file from a stored Listing.
+//
+// It answers what it can from the listing and finds the object on
+// the remote the first time anything else is needed. Once found, the
+// object on the remote is used for everything.
+type Object struct {
+	f       fs.Fs                // read only
+	remote  string               // read only
+	size    int64                // read only
+	modTime time.Time            // read only - zero if not known
+	hashes  map[hash.Type]string // read only - may be nil
+
+	mu  sync.Mutex // protects the following
+	obj fs.Object  // the object on the remote once found
+}
+
+// newObject makes an Object for remote in f
+func newObject(f fs.Fs, remote string, size int64, modTime time.Time, hashes map[hash.Type]string) *Object {
+	return &Object{
+		f:       f,
+		remote:  remote,
+		size:    size,
+		modTime: modTime,
+		hashes:  hashes,
+	}
+}
+
+// resolve finds the object on the remote
+func (o *Object) resolve(ctx context.Context) (fs.Object, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.obj != nil {
+		return o.obj, nil
+	}
+	obj, err := o.f.NewObject(ctx, o.remote)
+	if err != nil {
+		return nil, err
+	}
+	o.obj = obj
+	return obj, nil
+}
+
+// found returns the object on the remote if it has been found already
+func (o *Object) found() fs.Object {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.obj
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// String returns a description of the Object
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *Object) Remote() string {
+	return o.remote
+}
+
+// ModTime returns the modification date of the file
+func (o *Object) ModTime(ctx context.Context) time.Time {
+	if obj := o.found(); obj != nil {
+		return obj.ModTime(ctx)
+	}
+	if !o.modTime.IsZero() {
+		return o.modTime
+	}
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		fs.Debugf(o, "Failed to read modification time: %v", err)
+		return time.Now()
+	}
+	return obj.ModTime(ctx)
+}
+
+// Size returns the size of the file
+func (o *Object) Size() int64 {
+	if obj := o.found(); obj != nil {
+		return obj.Size()
+	}
+	return o.size
+}
+
+// Hash returns the selected checksum of the file
+func (o *Object) Hash(ctx context.Context, ty hash.Type) (string, error) {
+	if sum, ok := o.hashes[ty]; ok && o.found() == nil {
+		return sum, nil
+	}
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	return obj.Hash(ctx, ty)
+}
+
+// Storable says whether this object can be stored
+func (o *Object) Storable() bool {
+	return true
+}
+
+// SetModTime sets the modification time of the file
+func (o *Object) SetModTime(ctx context.Context, t time.Time) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.SetModTime(ctx, t)
+}
+
+// Open opens the file for read
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Open(ctx, options...)
+}
+
+// Update the object with the contents of in
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Update(ctx, in, src, options...)
+}
+
+// Remove the object
+func (o *Object) Remove(ctx context.Context) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Remove(ctx)
+}
+
+// Check the interfaces are satisfied
+var _ fs.Object = (*Object)(nil)
@@ -0,0 +1,455 @@
+// Package vfsdir keeps the directory listings read by the VFS on disk
+// so they can be reused when the VFS is next started.
+package vfsdir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/list"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/encoder"
+	"github.com/rclone/rclone/lib/file"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	dbName        = "dirs.db"
+	bucketName    = "dirs"
+	openTimeout   = time.Second       // how long to wait for another rclone using the store
+	flushInterval = time.Second       // how often to write pending changes
+	maxPending    = 1000              // write pending changes early if there are more than this
+	dbFileMode    = os.FileMode(0600) // mode for the database file
+)
+
+// Store keeps directory listings for a remote on disk.
+//
+// Changes are queued and written in batches in the background but a
+// Get always sees the changes made before it.
+//
+// The remote may have changed while the listings weren't in use, so
+// a listing from a previous run is only returned once it has been
+// read from the remote again by Put or Revalidate.
+type Store struct {
+	path string   // path of the database file
+	db   *bolt.DB // read only
+
+	flushMu  sync.Mutex // held while writing changes
+	mu       sync.Mutex // protects the following
+	pending  []op       // changes not yet written
+	closed   bool
+	fresh    map[string]struct{} // dirs whose listing was read since the store was opened
+	checking map[string]struct{} // dirs being revalidated which haven't changed since
+
+	kick chan struct{} // write pending changes now
+	done chan struct{} // closed to stop the writer
+	wg   sync.WaitGroup
+}
+
+// key returns the database key for dir.
+//
+// Keys start with "/" as the root directory is "" which isn't a
+// valid key.
+func key(dir string) []byte {
+	return []byte("/" + dir)
+}
+
+// op is a change to the store
+type op struct {
+	dir     string // directory the change applies to
+	listing []byte // encoded listing to put, nil to delete
+	tree    bool   // delete everything under dir too
+}
+
+// Open the store for the remote f in the rclone cache directory.
+//
+// The store lives alongside the VFS cache in a directory named after
+// the remote and its root. Only one rclone may use it at once.
+func Open(f fs.Fs) (*Store, error) {
+	relativeDirPath := f.Root()
+	if runtime.GOOS == "windows" && strings.HasPrefix(relativeDirPath, `//?/`) {
+		relativeDirPath = relativeDirPath[2:]
+	}
+	relativeDirPath = f.Name() + "/" + relativeDirPath
+	dir := file.UNCPath(filepath.Join(config.GetCacheDir(), "vfsDir", filepath.FromSlash(encoder.OS.FromStandardPath(relativeDirPath))))
+	if err := file.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create directory cache directory: %w", err)
+	}
+	s := &Store{
+		path:     filepath.Join(dir, dbName),
+		fresh:    make(map[string]struct{}),
+		checking: make(map[string]struct{}),
+		kick:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	var err error
+	s.db, err = bolt.Open(s.path, dbFileMode, &bolt.Options{Timeout: openTimeout})
+	if err == bolt.ErrTimeout {
+		return nil, fmt.Errorf("directory cache %q is in use by another rclone", s.path)
+	} else if err != nil {
+		// The database is only a cache so start again if it is broken
+		fs.Logf(nil, "vfs dir cache: removing unreadable %q: %v", s.path, err)
+		_ = os.Remove(s.path)
+		s.db, err = bolt.Open(s.path, dbFileMode, &bolt.Options{Timeout: openTimeout})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open directory cache %q: %w", s.path, err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		return err
+	})
+	if err != nil {
+		_ = s.db.Close()
+		return nil, fmt.Errorf("failed to initialise directory cache %q: %w", s.path, err)
+	}
+	fs.Debugf(nil, "vfs dir cache: database is %q", s.path)
+	s.wg.Add(1)
+	go s.writer()
+	return s, nil
+}
+
+// Path returns the path of the database file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get the listing for dir or nil if there isn't one which has been
+// read from the remote since the store was opened
+func (s *Store) Get(dir string) (*Listing, error) {
+	s.mu.Lock()
+	_, fresh := s.fresh[dir]
+	s.mu.Unlock()
+	if !fresh {
+		return nil, nil
+	}
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if value := tx.Bucket([]byte(bucketName)).Get(key(dir)); value != nil {
+			data = append([]byte(nil), value...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return nil, err
+	}
+	listing := new(Listing)
+	if err := json.Unmarshal(data, listing); err != nil {
+		// treat a listing which can't be read as missing
+		fs.Debugf(dir, "vfs dir cache: ignoring bad listing: %v", err)
+		return nil, nil
+	}
+	return listing, nil
+}
+
+// Put the listing for dir
+func (s *Store) Put(dir string, listing *Listing) {
+	data, err := json.Marshal(listing)
+	if err != nil {
+		fs.Errorf(dir, "vfs dir cache: failed to encode listing: %v", err)
+		s.add(op{dir: dir})
+		return
+	}
+	s.add(op{dir: dir, listing: data})
+}
+
+// Forget the listing for dir
+func (s *Store) Forget(dir string) {
+	s.add(op{dir: dir})
+}
+
+// ForgetTree forgets the listings for dir and all the directories
+// below it. An empty dir forgets everything.
+func (s *Store) ForgetTree(dir string) {
+	s.add(op{dir: dir, tree: true})
+}
+
+// add queues a change
+func (s *Store) add(o op) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s._add(o)
+}
+
+// _add queues a change, kicking the writer if there are lots of them
+// - call with the lock held
+func (s *Store) _add(o op) {
+	if s.closed {
+		return
+	}
+	s._changed(o)
+	s.pending = append(s.pending, o)
+	if len(s.pending) >= maxPending {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// _changed notes which listings are fresh after o - call with the
+// lock held
+func (s *Store) _changed(o op) {
+	delete(s.checking, o.dir)
+	switch {
+	case o.listing != nil:
+		s.fresh[o.dir] = struct{}{}
+	case o.tree:
+		for _, m := range []map[string]struct{}{s.fresh, s.checking} {
+			for dir := range m {
+				if o.dir == "" || dir == o.dir || strings.HasPrefix(dir, o.dir+"/") {
+					delete(m, dir)
+				}
+			}
+		}
+	default:
+		delete(s.fresh, o.dir)
+	}
+}
+
+// writer writes the pending changes every so often until the store
+// is closed
+func (s *Store) writer() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		case <-s.kick:
+		}
+		if err := s.Flush(); err != nil {
+			fs.Errorf(nil, "vfs dir cache: failed to write changes: %v", err)
+		}
+	}
+}
+
+// Flush writes the pending changes to disk
+func (s *Store) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		for _, o := range pending {
+			var err error
+			switch {
+			case o.listing != nil:
+				err = deleteRemovedDirs(b, o.dir, o.listing)
+				if err == nil {
+					err = b.Put(key(o.dir), o.listing)
+				}
+			case o.tree:
+				err = deleteTree(b, o.dir)
+			default:
+				err = b.Delete(key(o.dir))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteRemovedDirs deletes the listings below the directories which
+// were in the stored listing for dir but aren't in the new one, so a
+// directory which is removed and made again isn't given its old
+// listing.
+func deleteRemovedDirs(b *bolt.Bucket, dir string, data []byte) error {
+	oldData := b.Get(key(dir))
+	if oldData == nil {
+		return nil
+	}
+	var oldListing, newListing Listing
+	if json.Unmarshal(oldData, &oldListing) != nil || json.Unmarshal(data, &newListing) != nil {
+		return deleteTree(b, dir)
+	}
+	dirs := make(map[string]struct{}, len(newListing.Entries))
+	for _, e := range newListing.Entries {
+		if e.Dir {
+			dirs[e.Name] = struct{}{}
+		}
+	}
+	for _, e := range oldListing.Entries {
+		if _, found := dirs[e.Name]; e.Dir && !found {
+			if err := deleteTree(b, path.Join(dir, e.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteTree deletes dir and all the directories below it from b
+func deleteTree(b *bolt.Bucket, dir string) error {
+	if dir == "" {
+		return deleteWithPrefix(b, key(""))
+	}
+	if err := b.Delete(key(dir)); err != nil {
+		return err
+	}
+	return deleteWithPrefix(b, key(dir+"/"))
+}
+
+// deleteWithPrefix deletes all the keys starting with prefix from b
+func deleteWithPrefix(b *bolt.Bucket, prefix []byte) error {
+	var keys [][]byte
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revalidate reads the directories from f whose listings were
+// persisted by a previous run and are younger than maxAge, and
+// replaces their listings so Get can return them.
+//
+// Directories are read --checkers at a time. A directory which
+// changes while it is being read is left for the VFS to read when it
+// is needed, as is one which gives an error.
+func (s *Store) Revalidate(ctx context.Context, f fs.Fs, maxAge time.Duration) error {
+	dirs, err := s.unchecked(time.Now().Add(-maxAge))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+	fs.Debugf(nil, "vfs dir cache: revalidating %d persisted listings", len(dirs))
+	in := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < fs.GetConfig(ctx).Checkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dir := range in {
+				s.revalidate(ctx, f, dir)
+			}
+		}()
+	}
+loop:
+	for _, dir := range dirs {
+		select {
+		case in <- dir:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(in)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// unchecked returns the persisted dirs read since notBefore which
+// haven't been read since the store was opened
+func (s *Store) unchecked(notBefore time.Time) (dirs []string, err error) {
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucketName)).ForEach(func(k, v []byte) error {
+			dir := string(k[1:])
+			if _, fresh := s.fresh[dir]; fresh {
+				return nil
+			}
+			var listing struct {
+				Read time.Time `json:"read"`
+			}
+			if json.Unmarshal(v, &listing) == nil && listing.Read.After(notBefore) {
+				dirs = append(dirs, dir)
+				s.checking[dir] = struct{}{}
+			}
+			return nil
+		})
+	})
+	return dirs, err
+}
+
+// revalidate reads dir from f and puts its listing if dir hasn't
+// changed in the meantime
+func (s *Store) revalidate(ctx context.Context, f fs.Fs, dir string) {
+	when := time.Now()
+	entries, err := list.DirSorted(ctx, f, false, dir)
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(NewListing(ctx, f, entries, when))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, unchanged := s.checking[dir]; !unchanged {
+		return
+	}
+	switch {
+	case err == fs.ErrorDirNotFound:
+		s._add(op{dir: dir, tree: true})
+	case err != nil:
+		fs.Debugf(dir, "vfs dir cache: failed to revalidate listing: %v", err)
+		delete(s.checking, dir)
+	default:
+		s._add(op{dir: dir, listing: data})
+	}
+}
+
+// Stats returns info about the Store
+func (s *Store) Stats() (out rc.Params) {
+	out = make(rc.Params)
+	out["path"] = s.path
+	s.mu.Lock()
+	out["pending"] = len(s.pending)
+	s.mu.Unlock()
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		out["dirs"] = tx.Bucket([]byte(bucketName)).Stats().KeyN
+		return nil
+	})
+	return out
+}
+
+// Close writes any pending changes and closes the store
+func (s *Store) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("directory cache already closed")
+	}
+	s.closed = true
+	s.mu.Unlock()
+	close(s.done)
+	s.wg.Wait()
+	err := s.Flush()
+	if closeErr := s.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,206 @@
+package vfsdir
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setCacheDir points the cache directory at a temporary directory
+// for the duration of the test
+func setCacheDir(t *testing.T) {
+	oldCacheDir := config.GetCacheDir()
+	require.NoError(t, config.SetCacheDir(t.TempDir()))
+	t.Cleanup(func() {
+		_ = config.SetCacheDir(oldCacheDir)
+	})
+}
+
+func TestStore(t *testing.T) {
+	setCacheDir(t)
+	f := mockfs.NewFs(context.Background(), "mock", "root")
+	s, err := Open(f)
+	require.NoError(t, err)
+
+	when := time.Date(2020, 5, 17, 10, 0, 0, 0, time.UTC)
+	listing := func(dirs ...string) *Listing {
+		l := &Listing{Read: when}
+		for _, dir := range dirs {
+			l.Entries = append(l.Entries, Entry{Name: dir, Dir: true})
+		}
+		return l
+	}
+	has := func(dir string) bool {
+		l, err := s.Get(dir)
+		require.NoError(t, err)
+		return l != nil
+	}
+
+	s.Put("", listing("a", "ab"))
+	s.Put("a", listing("b"))
+	s.Put("a/b", listing())
+	s.Put("ab", listing())
+
+	// Get sees changes which haven't been written yet
+	got, err := s.Get("")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, got.Read.Equal(when))
+	assert.Equal(t, []Entry{{Name: "a", Dir: true}, {Name: "ab", Dir: true}}, got.Entries)
+	assert.False(t, has("missing"))
+	assert.Equal(t, 4, s.Stats()["dirs"])
+
+	s.Forget("a/b")
+	assert.False(t, has("a/b"))
+	assert.True(t, has("a"))
+
+	// ForgetTree doesn't forget directories which share a prefix
+	s.Put("a/b", listing())
+	s.ForgetTree("a")
+	assert.False(t, has("a"))
+	assert.False(t, has("a/b"))
+	assert.True(t, has("ab"))
+	assert.True(t, has(""))
+
+	// Directories missing from a new listing are forgotten
+	s.Put("a", listing())
+	s.Put("", listing("a"))
+	assert.True(t, has("a"))
+	assert.False(t, has("ab"))
+
+	// Only one user at once
+	_, err = Open(f)
+	assert.Error(t, err)
+
+	// Listings survive closing and opening but aren't used until
+	// they have been read from the remote again
+	require.NoError(t, s.Close())
+	assert.Error(t, s.Close())
+	s, err = Open(f)
+	require.NoError(t, err)
+	assert.False(t, has(""))
+	assert.False(t, has("a"))
+	assert.Equal(t, 2, s.Stats()["dirs"])
+
+	// Listings older than the max age aren't read
+	require.NoError(t, s.Revalidate(context.Background(), f, time.Hour))
+	assert.False(t, has(""))
+	assert.Equal(t, 2, s.Stats()["dirs"])
+
+	// The mock remote has an empty root and no "a"
+	require.NoError(t, s.Revalidate(context.Background(), f, time.Since(when)+time.Hour))
+	got, err = s.Get("")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, got.Read.After(when))
+	assert.Empty(t, got.Entries)
+	assert.False(t, has("a"))
+	assert.Equal(t, 1, s.Stats()["dirs"])
+
+	s.ForgetTree("")
+	assert.False(t, has(""))
+	assert.False(t, has("a"))
+	require.NoError(t, s.Close())
+}
+
+func TestStoreRevalidateChanged(t *testing.T) {
+	setCacheDir(t)
+	ctx := context.Background()
+	f := mockfs.NewFs(ctx, "mock", "root")
+	s, err := Open(f)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+	s.Put("", &Listing{Read: time.Now(), Entries: []Entry{{Name: "file"}}})
+	require.NoError(t, s.Flush())
+	s.fresh = make(map[string]struct{}) // as if just opened
+
+	// A listing which changes while the directory is read isn't
+	// replaced by what was read
+	dirs, err := s.unchecked(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, dirs)
+	s.Forget("")
+	s.revalidate(ctx, f, "")
+	l, err := s.Get("")
+	require.NoError(t, err)
+	assert.Nil(t, l)
+
+	// Otherwise it is
+	s.Put("", &Listing{Read: time.Now(), Entries: []Entry{{Name: "file"}}})
+	require.NoError(t, s.Flush())
+	s.fresh = make(map[string]struct{})
+	_, err = s.unchecked(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	s.revalidate(ctx, f, "")
+	l, err = s.Get("")
+	require.NoError(t, err)
+	require.NotNil(t, l)
+	assert.Empty(t, l.Entries)
+}
+
+func TestListing(t *testing.T) {
+	ctx := context.Background()
+	f := mockfs.NewFs(ctx, "mock", "root")
+	f.SetHashes(hash.NewHashSet(hash.MD5))
+	f.AddObject(mockobject.New("file").WithContent([]byte("hello"), mockobject.SeekModeNone))
+
+	modTime := time.Date(2020, 5, 17, 10, 0, 0, 0, time.UTC)
+	entries := fs.DirEntries{
+		fs.NewDir("dir", modTime).SetSize(3),
+		mockobject.New("file").WithContent([]byte("hello"), mockobject.SeekModeNone),
+		mockobject.New("gone").WithContent([]byte("bye"), mockobject.SeekModeNone),
+	}
+	when := time.Now()
+	listing := NewListing(ctx, f, entries, when)
+	require.Len(t, listing.Entries, 3)
+	assert.Equal(t, Entry{Name: "dir", Dir: true, Size: 3, ModTime: modTime}, listing.Entries[0])
+	assert.Equal(t, "file", listing.Entries[1].Name)
+	assert.Equal(t, int64(5), listing.Entries[1].Size)
+	assert.Equal(t, map[string]string{"md5": "5d41402abc4b2a76b9719d911017c592"}, listing.Entries[1].Hashes)
+
+	// Hashes aren't read if they are slow
+	f.Features().SlowHash = true
+	assert.Nil(t, NewListing(ctx, f, entries, when).Entries[1].Hashes)
+
+	got := listing.DirEntries(f, "")
+	require.Len(t, got, 3)
+	dir, ok := got[0].(fs.Directory)
+	require.True(t, ok)
+	assert.Equal(t, "dir", dir.Remote())
+	assert.Equal(t, int64(3), dir.Size())
+	assert.True(t, dir.ModTime(ctx).Equal(modTime))
+
+	// What was persisted is answered without finding the object
+	o, ok := got[1].(*Object)
+	require.True(t, ok)
+	assert.Equal(t, "file", o.Remote())
+	assert.Equal(t, int64(5), o.Size())
+	sum, err := o.Hash(ctx, hash.MD5)
+	require.NoError(t, err)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", sum)
+	assert.Nil(t, o.found())
+
+	// Anything else finds the object
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, "hello", string(data))
+	assert.NotNil(t, o.found())
+
+	// Objects which have gone from the remote give an error
+	_, err = got[2].(*Object).Open(ctx)
+	assert.ErrorIs(t, err, fs.ErrorObjectNotFound)
+}
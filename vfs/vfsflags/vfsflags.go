@@ -28,6 +28,8 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.DurationVarP(flagSet, &Opt.CachePollInterval, "vfs-cache-poll-interval", "", Opt.CachePollInterval, "Interval to poll the cache for stale objects")
 	flags.DurationVarP(flagSet, &Opt.CacheMaxAge, "vfs-cache-max-age", "", Opt.CacheMaxAge, "Max age of objects in the cache")
 	flags.FVarP(flagSet, &Opt.CacheMaxSize, "vfs-cache-max-size", "", "Max total size of objects in the cache")
+	flags.FVarP(flagSet, &Opt.CachePolicy, "vfs-cache-policy", "", "Which data to remove first when over --vfs-cache-max-size lru|lfu")
+	flags.BoolVarP(flagSet, &Opt.CacheChecksum, "vfs-cache-checksum", "", Opt.CacheChecksum, "Checksum the cached data to detect corruption when the cache is verified")
 	flags.FVarP(flagSet, &Opt.ChunkSize, "vfs-read-chunk-size", "", "Read the source objects in chunks")
 	flags.FVarP(flagSet, &Opt.ChunkSizeLimit, "vfs-read-chunk-size-limit", "", "If greater than --vfs-read-chunk-size, double the chunk size after each chunk read, until the limit is reached ('off' is unlimited)")
 	flags.FVarP(flagSet, DirPerms, "dir-perms", "", "Directory permissions")
@@ -40,5 +42,7 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &Opt.UsedIsSize, "vfs-used-is-size", "", Opt.UsedIsSize, "Use the `rclone size` algorithm for Used size")
 	flags.BoolVarP(flagSet, &Opt.FastFingerprint, "vfs-fast-fingerprint", "", Opt.FastFingerprint, "Use fast (less accurate) fingerprints for change detection")
 	flags.FVarP(flagSet, &Opt.DiskSpaceTotalSize, "vfs-disk-space-total-size", "", "Specify the total space of disk")
+	flags.BoolVarP(flagSet, &Opt.DirCachePersist, "vfs-dir-cache-persist", "", Opt.DirCachePersist, "Keep directory listings on disk and reuse them after a restart")
+	flags.StringVarP(flagSet, &Opt.PinFrom, "vfs-pin-from", "", Opt.PinFrom, "Keep the files and directories listed in this file in the cache")
 	platformFlags(flagSet)
 }
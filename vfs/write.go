@@ -139,6 +139,9 @@ func (fh *WriteFileHandle) writeAt(p []byte, off int64) (n int, err error) {
 	if err = fh.openPending(); err != nil {
 		return 0, err
 	}
+	if err = fh.file.VFS().reserveQuota(fh.file, int64(len(p))); err != nil {
+		return 0, err
+	}
 	fh.writeCalled = true
 	n, err = fh.pipeWriter.Write(p)
 	fh.offset += int64(n)
@@ -203,6 +206,8 @@ func (fh *WriteFileHandle) close() (err error) {
 	if err == nil {
 		fh.file.setObject(fh.o)
 		err = writeCloseErr
+	} else {
+		fh.file.VFS().quotaAbandoned(fh.file)
 	}
 	return err
 }